@@ -5,8 +5,8 @@ go 1.16
 require (
 	github.com/cloudflare/circl v1.1.0
 	github.com/lestrrat-go/jwx/v2 v2.0.8
-	github.com/stretchr/testify v1.8.2 // indirect
-	golang.org/x/crypto v0.7.0 // indirect
 )
 
 replace github.com/cloudflare/circl v1.0.0 => github.com/cloudflare/circl v1.0.1-0.20210104183656-96a0695de3c3
+
+replace github.com/lestrrat-go/jwx/v2 => ../
@@ -40,6 +40,7 @@ func ExampleJWT_SerializeJWEJWS() {
 	}
 
 	serialized, err := jwt.NewSerializer().
+		AllowEncryptThenSign(true).
 		Encrypt(jwt.WithKey(jwa.RSA_OAEP, enckey)).
 		Sign(jwt.WithKey(jwa.HS256, signkey)).
 		Serialize(tok)
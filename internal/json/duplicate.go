@@ -0,0 +1,68 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+type duplicateCheckFrame struct {
+	names     map[string]struct{} // nil for arrays
+	expectKey bool
+}
+
+// CheckDuplicateKeys returns an error if any of the objects in the JSON
+// document `data`, at any depth, contains duplicate member names.
+// Member names are compared as-is, after unescaping.
+func CheckDuplicateKeys(data []byte) error {
+	dec := NewDecoder(bytes.NewReader(data))
+	var stack []*duplicateCheckFrame
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF && len(stack) == 0 {
+				return nil
+			}
+			return fmt.Errorf(`failed to read JSON token: %w`, err)
+		}
+
+		if l := len(stack); l > 0 {
+			if top := stack[l-1]; top.names != nil && top.expectKey {
+				if tok == Delim('}') {
+					stack = stack[:l-1]
+					valueDone(stack)
+					continue
+				}
+				//nolint:forcetypeassert
+				name := tok.(string) // object keys are always strings
+				if _, ok := top.names[name]; ok {
+					return fmt.Errorf(`duplicate member name %q`, name)
+				}
+				top.names[name] = struct{}{}
+				top.expectKey = false
+				continue
+			}
+		}
+
+		switch tok {
+		case Delim('{'):
+			stack = append(stack, &duplicateCheckFrame{names: make(map[string]struct{}), expectKey: true})
+		case Delim('['):
+			stack = append(stack, &duplicateCheckFrame{})
+		case Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone(stack)
+		default:
+			valueDone(stack)
+		}
+	}
+}
+
+// valueDone marks the end of a value. If the value was a member of an
+// object, the next token is the name of the next member (or the end of
+// the object)
+func valueDone(stack []*duplicateCheckFrame) {
+	if l := len(stack); l > 0 && stack[l-1].names != nil {
+		stack[l-1].expectKey = true
+	}
+}
@@ -0,0 +1,40 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDuplicateKeys(t *testing.T) {
+	testcases := []struct {
+		Name  string
+		Src   string
+		Error bool
+	}{
+		{Name: `empty object`, Src: `{}`},
+		{Name: `flat`, Src: `{"iss":"a","sub":"b","aud":["c","d"]}`},
+		{Name: `same names at different levels`, Src: `{"a":{"a":{"a":1}},"b":[{"a":1},{"a":2}]}`},
+		{Name: `values equal to names`, Src: `{"a":"b","b":"a","c":["a","a"]}`},
+		{Name: `array`, Src: `[1,{"a":1},[{"a":2}]]`},
+		{Name: `duplicate`, Src: `{"sub":"alice","sub":"admin"}`, Error: true},
+		{Name: `duplicate after nested object`, Src: `{"a":{"b":1},"c":2,"a":3}`, Error: true},
+		{Name: `duplicate in nested object`, Src: `{"a":{"b":1,"b":2}}`, Error: true},
+		{Name: `duplicate in array`, Src: `{"a":[{"b":1},{"c":1,"c":2}]}`, Error: true},
+		{Name: `escaped duplicate`, Src: `{"sub":"alice","s\u0075b":"admin"}`, Error: true},
+		{Name: `truncated`, Src: `{"a":1`, Error: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			err := json.CheckDuplicateKeys([]byte(tc.Src))
+			if tc.Error {
+				require.Error(t, err, `json.CheckDuplicateKeys should fail`)
+				return
+			}
+			require.NoError(t, err, `json.CheckDuplicateKeys should succeed`)
+		})
+	}
+}
@@ -6,36 +6,70 @@ import (
 	"sync"
 )
 
+// CustomDecoder is the interface for objects that decode the JSON
+// representation of a field into an arbitrary Go value. Registering
+// a CustomDecoder instead of an object allows the user to control how
+// the field is decoded.
+type CustomDecoder interface {
+	Decode([]byte) (interface{}, error)
+}
+
+// CustomDecodeFunc is a function that implements CustomDecoder.
+type CustomDecodeFunc func([]byte) (interface{}, error)
+
+func (fn CustomDecodeFunc) Decode(data []byte) (interface{}, error) {
+	return fn(data)
+}
+
 type Registry struct {
-	mu   *sync.RWMutex
-	data map[string]reflect.Type
+	mu       *sync.RWMutex
+	data     map[string]reflect.Type
+	decoders map[string]CustomDecoder
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
-		mu:   &sync.RWMutex{},
-		data: make(map[string]reflect.Type),
+		mu:       &sync.RWMutex{},
+		data:     make(map[string]reflect.Type),
+		decoders: make(map[string]CustomDecoder),
 	}
 }
 
+// Register registers the type of `object` to be used when decoding
+// the field `name`. If `object` is a CustomDecoder, it is used to
+// decode the field instead. If `object` is nil, the registration is
+// removed.
 func (r *Registry) Register(name string, object interface{}) {
-	if object == nil {
-		r.mu.Lock()
-		defer r.mu.Unlock()
-		delete(r.data, name)
-		return
-	}
-
-	typ := reflect.TypeOf(object)
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.data[name] = typ
+
+	delete(r.data, name)
+	delete(r.decoders, name)
+	switch object := object.(type) {
+	case nil:
+	case CustomDecoder:
+		r.decoders[name] = object
+	default:
+		r.data[name] = reflect.TypeOf(object)
+	}
 }
 
 func (r *Registry) Decode(dec *Decoder, name string) (interface{}, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if cd, ok := r.decoders[name]; ok {
+		var raw RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf(`failed to decode field %s: %w`, name, err)
+		}
+		v, err := cd.Decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to decode field %s: %w`, name, err)
+		}
+		return v, nil
+	}
+
 	if typ, ok := r.data[name]; ok {
 		ptr := reflect.New(typ).Interface()
 		if err := dec.Decode(ptr); err != nil {
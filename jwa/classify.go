@@ -0,0 +1,140 @@
+package jwa
+
+import "crypto"
+
+// The methods in this file classify the algorithms that are known to
+// jwx. Values that were registered using `jwa.RegisterSignatureAlgorithm()`
+// and friends are not known to them, and are treated as such: predicates
+// return false, and lookups report that no value is available.
+
+// IsSymmetric returns true if the algorithm uses a shared secret key,
+// i.e. if it is one of the HMAC based algorithms.
+func (v SignatureAlgorithm) IsSymmetric() bool {
+	switch v {
+	case HS256, HS384, HS512:
+		return true
+	}
+	return false
+}
+
+// RequiresPrivateKey returns true if a private key is required to create
+// signatures using the algorithm. Such signatures are verified using the
+// corresponding public key.
+func (v SignatureAlgorithm) RequiresPrivateKey() bool {
+	switch v {
+	case RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384, ES512, ES256K, EdDSA:
+		return true
+	}
+	return false
+}
+
+// HashFor returns the hash function used by the algorithm. For EdDSA,
+// SHA-512 is returned, which is the hash function used by Ed25519.
+// The second return value is false if the algorithm does not use a hash
+// function, or if the algorithm is not known.
+func (v SignatureAlgorithm) HashFor() (crypto.Hash, bool) {
+	switch v {
+	case HS256, RS256, PS256, ES256, ES256K:
+		return crypto.SHA256, true
+	case HS384, RS384, PS384, ES384:
+		return crypto.SHA384, true
+	case HS512, RS512, PS512, ES512, EdDSA:
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// KeyTypeFor returns the types of keys that can be used with the algorithm.
+// It returns nil for `jwa.NoSignature`, and for algorithms that are not known.
+func (v SignatureAlgorithm) KeyTypeFor() []KeyType {
+	switch v {
+	case HS256, HS384, HS512:
+		return []KeyType{OctetSeq}
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		return []KeyType{RSA}
+	case ES256, ES384, ES512, ES256K:
+		return []KeyType{EC}
+	case EdDSA:
+		return []KeyType{OKP}
+	}
+	return nil
+}
+
+// RequiresPrivateKey returns true if a private key is required to decrypt
+// the content encryption key. Such content encryption keys are encrypted
+// using the corresponding public key.
+func (v KeyEncryptionAlgorithm) RequiresPrivateKey() bool {
+	switch v {
+	case RSA1_5, RSA_OAEP, RSA_OAEP_256, ECDH_ES, ECDH_ES_A128KW, ECDH_ES_A192KW, ECDH_ES_A256KW:
+		return true
+	}
+	return false
+}
+
+// HashFor returns the hash function used by the algorithm: the hash
+// function used by RSA-OAEP and PBES2, or the one used by the Concat KDF
+// of ECDH-ES. The second return value is false if the algorithm does not
+// use a hash function, or if the algorithm is not known.
+func (v KeyEncryptionAlgorithm) HashFor() (crypto.Hash, bool) {
+	switch v {
+	case RSA_OAEP:
+		return crypto.SHA1, true
+	case RSA_OAEP_256, PBES2_HS256_A128KW, ECDH_ES, ECDH_ES_A128KW, ECDH_ES_A192KW, ECDH_ES_A256KW:
+		return crypto.SHA256, true
+	case PBES2_HS384_A192KW:
+		return crypto.SHA384, true
+	case PBES2_HS512_A256KW:
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// KeyTypeFor returns the types of keys that can be used with the algorithm.
+// For the ECDH-ES family both EC and OKP (X25519 and X448) keys can be used.
+// It returns nil for algorithms that are not known.
+func (v KeyEncryptionAlgorithm) KeyTypeFor() []KeyType {
+	switch v {
+	case RSA1_5, RSA_OAEP, RSA_OAEP_256:
+		return []KeyType{RSA}
+	case A128KW, A192KW, A256KW, A128GCMKW, A192GCMKW, A256GCMKW, DIRECT, PBES2_HS256_A128KW, PBES2_HS384_A192KW, PBES2_HS512_A256KW:
+		return []KeyType{OctetSeq}
+	case ECDH_ES, ECDH_ES_A128KW, ECDH_ES_A192KW, ECDH_ES_A256KW:
+		return []KeyType{EC, OKP}
+	}
+	return nil
+}
+
+// IsSymmetric returns true for all content encryption algorithms that
+// are known, as they all use symmetric keys.
+func (v ContentEncryptionAlgorithm) IsSymmetric() bool {
+	switch v {
+	case A128CBC_HS256, A192CBC_HS384, A256CBC_HS512, A128GCM, A192GCM, A256GCM:
+		return true
+	}
+	return false
+}
+
+// HashFor returns the hash function used for the authentication tag of the
+// AES-CBC + HMAC-SHA2 algorithms. The second return value is false for
+// AES-GCM, and for algorithms that are not known.
+func (v ContentEncryptionAlgorithm) HashFor() (crypto.Hash, bool) {
+	switch v {
+	case A128CBC_HS256:
+		return crypto.SHA256, true
+	case A192CBC_HS384:
+		return crypto.SHA384, true
+	case A256CBC_HS512:
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// KeyTypeFor returns the types of keys that can be used with the algorithm,
+// which is always `jwa.OctetSeq` for algorithms that are known. It returns
+// nil for algorithms that are not known.
+func (v ContentEncryptionAlgorithm) KeyTypeFor() []KeyType {
+	if !v.IsSymmetric() {
+		return nil
+	}
+	return []KeyType{OctetSeq}
+}
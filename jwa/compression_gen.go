@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
 
 // CompressionAlgorithm represents the compression algorithms as described in https://tools.ietf.org/html/rfc7518#section-7.3
@@ -17,25 +19,56 @@ const (
 	NoCompress CompressionAlgorithm = ""    // No compression
 )
 
+var muCompressionAlgorithms sync.RWMutex
 var allCompressionAlgorithms = map[CompressionAlgorithm]struct{}{
 	Deflate:    {},
 	NoCompress: {},
 }
-
-var listCompressionAlgorithmOnce sync.Once
 var listCompressionAlgorithm []CompressionAlgorithm
 
+func init() {
+	rebuildCompressionAlgorithm()
+}
+
+// RegisterCompressionAlgorithm registers a new CompressionAlgorithm so that values
+// that are not known to jwx can be handled by Accept() and friends.
+// Registering a value that is already known is a no-op.
+func RegisterCompressionAlgorithm(v CompressionAlgorithm) {
+	muCompressionAlgorithms.Lock()
+	defer muCompressionAlgorithms.Unlock()
+	if _, ok := allCompressionAlgorithms[v]; !ok {
+		allCompressionAlgorithms[v] = struct{}{}
+		rebuildCompressionAlgorithm()
+	}
+}
+
+// UnregisterCompressionAlgorithm removes a CompressionAlgorithm from the list of known values.
+// Unregistering a value that is not known is a no-op.
+func UnregisterCompressionAlgorithm(v CompressionAlgorithm) {
+	muCompressionAlgorithms.Lock()
+	defer muCompressionAlgorithms.Unlock()
+	if _, ok := allCompressionAlgorithms[v]; ok {
+		delete(allCompressionAlgorithms, v)
+		rebuildCompressionAlgorithm()
+	}
+}
+
+// rebuildCompressionAlgorithm must be called with muCompressionAlgorithms held
+func rebuildCompressionAlgorithm() {
+	list := make([]CompressionAlgorithm, 0, len(allCompressionAlgorithms))
+	for v := range allCompressionAlgorithms {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i]) < string(list[j])
+	})
+	listCompressionAlgorithm = list
+}
+
 // CompressionAlgorithms returns a list of all available values for CompressionAlgorithm
 func CompressionAlgorithms() []CompressionAlgorithm {
-	listCompressionAlgorithmOnce.Do(func() {
-		listCompressionAlgorithm = make([]CompressionAlgorithm, 0, len(allCompressionAlgorithms))
-		for v := range allCompressionAlgorithms {
-			listCompressionAlgorithm = append(listCompressionAlgorithm, v)
-		}
-		sort.Slice(listCompressionAlgorithm, func(i, j int) bool {
-			return string(listCompressionAlgorithm[i]) < string(listCompressionAlgorithm[j])
-		})
-	})
+	muCompressionAlgorithms.RLock()
+	defer muCompressionAlgorithms.RUnlock()
 	return listCompressionAlgorithm
 }
 
@@ -57,14 +90,33 @@ func (v *CompressionAlgorithm) Accept(value interface{}) error {
 		}
 		tmp = CompressionAlgorithm(s)
 	}
-	if _, ok := allCompressionAlgorithms[tmp]; !ok {
-		return fmt.Errorf(`invalid jwa.CompressionAlgorithm value`)
+
+	muCompressionAlgorithms.RLock()
+	_, ok := allCompressionAlgorithms[tmp]
+	muCompressionAlgorithms.RUnlock()
+	if !ok {
+		return fmt.Errorf(`invalid jwa.CompressionAlgorithm value: unknown value %q`, tmp)
 	}
 
 	*v = tmp
 	return nil
 }
 
+// UnmarshalJSON decodes a JSON string into a CompressionAlgorithm.
+// If strict mode is enabled (see `jwa.Settings()`), the value is validated
+// using Accept(). Otherwise the value is assigned as is.
+func (v *CompressionAlgorithm) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(`failed to decode jwa.CompressionAlgorithm: %w`, err)
+	}
+	if IsStrict() {
+		return v.Accept(s)
+	}
+	*v = CompressionAlgorithm(s)
+	return nil
+}
+
 // String returns the string representation of a CompressionAlgorithm
 func (v CompressionAlgorithm) String() string {
 	return string(v)
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
 
 // ContentEncryptionAlgorithm represents the various encryption algorithms as described in https://tools.ietf.org/html/rfc7518#section-5
@@ -21,6 +23,7 @@ const (
 	A256GCM       ContentEncryptionAlgorithm = "A256GCM"       // AES-GCM (256)
 )
 
+var muContentEncryptionAlgorithms sync.RWMutex
 var allContentEncryptionAlgorithms = map[ContentEncryptionAlgorithm]struct{}{
 	A128CBC_HS256: {},
 	A128GCM:       {},
@@ -29,21 +32,51 @@ var allContentEncryptionAlgorithms = map[ContentEncryptionAlgorithm]struct{}{
 	A256CBC_HS512: {},
 	A256GCM:       {},
 }
-
-var listContentEncryptionAlgorithmOnce sync.Once
 var listContentEncryptionAlgorithm []ContentEncryptionAlgorithm
 
+func init() {
+	rebuildContentEncryptionAlgorithm()
+}
+
+// RegisterContentEncryptionAlgorithm registers a new ContentEncryptionAlgorithm so that values
+// that are not known to jwx can be handled by Accept() and friends.
+// Registering a value that is already known is a no-op.
+func RegisterContentEncryptionAlgorithm(v ContentEncryptionAlgorithm) {
+	muContentEncryptionAlgorithms.Lock()
+	defer muContentEncryptionAlgorithms.Unlock()
+	if _, ok := allContentEncryptionAlgorithms[v]; !ok {
+		allContentEncryptionAlgorithms[v] = struct{}{}
+		rebuildContentEncryptionAlgorithm()
+	}
+}
+
+// UnregisterContentEncryptionAlgorithm removes a ContentEncryptionAlgorithm from the list of known values.
+// Unregistering a value that is not known is a no-op.
+func UnregisterContentEncryptionAlgorithm(v ContentEncryptionAlgorithm) {
+	muContentEncryptionAlgorithms.Lock()
+	defer muContentEncryptionAlgorithms.Unlock()
+	if _, ok := allContentEncryptionAlgorithms[v]; ok {
+		delete(allContentEncryptionAlgorithms, v)
+		rebuildContentEncryptionAlgorithm()
+	}
+}
+
+// rebuildContentEncryptionAlgorithm must be called with muContentEncryptionAlgorithms held
+func rebuildContentEncryptionAlgorithm() {
+	list := make([]ContentEncryptionAlgorithm, 0, len(allContentEncryptionAlgorithms))
+	for v := range allContentEncryptionAlgorithms {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i]) < string(list[j])
+	})
+	listContentEncryptionAlgorithm = list
+}
+
 // ContentEncryptionAlgorithms returns a list of all available values for ContentEncryptionAlgorithm
 func ContentEncryptionAlgorithms() []ContentEncryptionAlgorithm {
-	listContentEncryptionAlgorithmOnce.Do(func() {
-		listContentEncryptionAlgorithm = make([]ContentEncryptionAlgorithm, 0, len(allContentEncryptionAlgorithms))
-		for v := range allContentEncryptionAlgorithms {
-			listContentEncryptionAlgorithm = append(listContentEncryptionAlgorithm, v)
-		}
-		sort.Slice(listContentEncryptionAlgorithm, func(i, j int) bool {
-			return string(listContentEncryptionAlgorithm[i]) < string(listContentEncryptionAlgorithm[j])
-		})
-	})
+	muContentEncryptionAlgorithms.RLock()
+	defer muContentEncryptionAlgorithms.RUnlock()
 	return listContentEncryptionAlgorithm
 }
 
@@ -65,14 +98,36 @@ func (v *ContentEncryptionAlgorithm) Accept(value interface{}) error {
 		}
 		tmp = ContentEncryptionAlgorithm(s)
 	}
-	if _, ok := allContentEncryptionAlgorithms[tmp]; !ok {
-		return fmt.Errorf(`invalid jwa.ContentEncryptionAlgorithm value`)
+
+	muContentEncryptionAlgorithms.RLock()
+	_, ok := allContentEncryptionAlgorithms[tmp]
+	muContentEncryptionAlgorithms.RUnlock()
+	if !ok {
+		if tmp == "" {
+			return fmt.Errorf(`invalid jwa.ContentEncryptionAlgorithm value: empty value`)
+		}
+		return fmt.Errorf(`invalid jwa.ContentEncryptionAlgorithm value: unknown value %q`, tmp)
 	}
 
 	*v = tmp
 	return nil
 }
 
+// UnmarshalJSON decodes a JSON string into a ContentEncryptionAlgorithm.
+// If strict mode is enabled (see `jwa.Settings()`), the value is validated
+// using Accept(). Otherwise the value is assigned as is.
+func (v *ContentEncryptionAlgorithm) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(`failed to decode jwa.ContentEncryptionAlgorithm: %w`, err)
+	}
+	if IsStrict() {
+		return v.Accept(s)
+	}
+	*v = ContentEncryptionAlgorithm(s)
+	return nil
+}
+
 // String returns the string representation of a ContentEncryptionAlgorithm
 func (v ContentEncryptionAlgorithm) String() string {
 	return string(v)
@@ -241,6 +241,13 @@ func TestContentEncryptionAlgorithm(t *testing.T) {
 			return
 		}
 	})
+	t.Run(`do not accept empty string value`, func(t *testing.T) {
+		t.Parallel()
+		var dst jwa.ContentEncryptionAlgorithm
+		if !assert.Error(t, dst.Accept(``), `accept should fail`) {
+			return
+		}
+	})
 	t.Run(`check list of elements`, func(t *testing.T) {
 		t.Parallel()
 		var expected = map[jwa.ContentEncryptionAlgorithm]struct{}{
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
 
 // EllipticCurveAlgorithm represents the algorithms used for EC keys
@@ -23,6 +25,7 @@ const (
 	X448                 EllipticCurveAlgorithm = "X448"
 )
 
+var muEllipticCurveAlgorithms sync.RWMutex
 var allEllipticCurveAlgorithms = map[EllipticCurveAlgorithm]struct{}{
 	Ed25519: {},
 	Ed448:   {},
@@ -32,21 +35,51 @@ var allEllipticCurveAlgorithms = map[EllipticCurveAlgorithm]struct{}{
 	X25519:  {},
 	X448:    {},
 }
-
-var listEllipticCurveAlgorithmOnce sync.Once
 var listEllipticCurveAlgorithm []EllipticCurveAlgorithm
 
+func init() {
+	rebuildEllipticCurveAlgorithm()
+}
+
+// RegisterEllipticCurveAlgorithm registers a new EllipticCurveAlgorithm so that values
+// that are not known to jwx can be handled by Accept() and friends.
+// Registering a value that is already known is a no-op.
+func RegisterEllipticCurveAlgorithm(v EllipticCurveAlgorithm) {
+	muEllipticCurveAlgorithms.Lock()
+	defer muEllipticCurveAlgorithms.Unlock()
+	if _, ok := allEllipticCurveAlgorithms[v]; !ok {
+		allEllipticCurveAlgorithms[v] = struct{}{}
+		rebuildEllipticCurveAlgorithm()
+	}
+}
+
+// UnregisterEllipticCurveAlgorithm removes a EllipticCurveAlgorithm from the list of known values.
+// Unregistering a value that is not known is a no-op.
+func UnregisterEllipticCurveAlgorithm(v EllipticCurveAlgorithm) {
+	muEllipticCurveAlgorithms.Lock()
+	defer muEllipticCurveAlgorithms.Unlock()
+	if _, ok := allEllipticCurveAlgorithms[v]; ok {
+		delete(allEllipticCurveAlgorithms, v)
+		rebuildEllipticCurveAlgorithm()
+	}
+}
+
+// rebuildEllipticCurveAlgorithm must be called with muEllipticCurveAlgorithms held
+func rebuildEllipticCurveAlgorithm() {
+	list := make([]EllipticCurveAlgorithm, 0, len(allEllipticCurveAlgorithms))
+	for v := range allEllipticCurveAlgorithms {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i]) < string(list[j])
+	})
+	listEllipticCurveAlgorithm = list
+}
+
 // EllipticCurveAlgorithms returns a list of all available values for EllipticCurveAlgorithm
 func EllipticCurveAlgorithms() []EllipticCurveAlgorithm {
-	listEllipticCurveAlgorithmOnce.Do(func() {
-		listEllipticCurveAlgorithm = make([]EllipticCurveAlgorithm, 0, len(allEllipticCurveAlgorithms))
-		for v := range allEllipticCurveAlgorithms {
-			listEllipticCurveAlgorithm = append(listEllipticCurveAlgorithm, v)
-		}
-		sort.Slice(listEllipticCurveAlgorithm, func(i, j int) bool {
-			return string(listEllipticCurveAlgorithm[i]) < string(listEllipticCurveAlgorithm[j])
-		})
-	})
+	muEllipticCurveAlgorithms.RLock()
+	defer muEllipticCurveAlgorithms.RUnlock()
 	return listEllipticCurveAlgorithm
 }
 
@@ -68,14 +101,36 @@ func (v *EllipticCurveAlgorithm) Accept(value interface{}) error {
 		}
 		tmp = EllipticCurveAlgorithm(s)
 	}
-	if _, ok := allEllipticCurveAlgorithms[tmp]; !ok {
-		return fmt.Errorf(`invalid jwa.EllipticCurveAlgorithm value`)
+
+	muEllipticCurveAlgorithms.RLock()
+	_, ok := allEllipticCurveAlgorithms[tmp]
+	muEllipticCurveAlgorithms.RUnlock()
+	if !ok {
+		if tmp == "" {
+			return fmt.Errorf(`invalid jwa.EllipticCurveAlgorithm value: empty value`)
+		}
+		return fmt.Errorf(`invalid jwa.EllipticCurveAlgorithm value: unknown value %q`, tmp)
 	}
 
 	*v = tmp
 	return nil
 }
 
+// UnmarshalJSON decodes a JSON string into a EllipticCurveAlgorithm.
+// If strict mode is enabled (see `jwa.Settings()`), the value is validated
+// using Accept(). Otherwise the value is assigned as is.
+func (v *EllipticCurveAlgorithm) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(`failed to decode jwa.EllipticCurveAlgorithm: %w`, err)
+	}
+	if IsStrict() {
+		return v.Accept(s)
+	}
+	*v = EllipticCurveAlgorithm(s)
+	return nil
+}
+
 // String returns the string representation of a EllipticCurveAlgorithm
 func (v EllipticCurveAlgorithm) String() string {
 	return string(v)
@@ -3,7 +3,38 @@
 // Package jwa defines the various algorithm described in https://tools.ietf.org/html/rfc7518
 package jwa
 
-import "fmt"
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+var strict uint32
+
+// Settings controls global settings that are specific to the jwa package.
+// Only the settings specified in `options` are changed.
+//
+//	jwa.Settings(jwa.WithStrict(true))
+//
+// Note that `Accept()` always validates its input, regardless of these
+// settings.
+func Settings(options ...Option) {
+	//nolint:forcetypeassert
+	for _, option := range options {
+		switch option.Ident() {
+		case identStrict{}:
+			var v uint32
+			if option.Value().(bool) {
+				v = 1
+			}
+			atomic.StoreUint32(&strict, v)
+		}
+	}
+}
+
+// IsStrict returns true if strict mode is enabled (see `jwa.WithStrict()`)
+func IsStrict() bool {
+	return atomic.LoadUint32(&strict) == 1
+}
 
 // KeyAlgorithm is a workaround for jwk.Key being able to contain different
 // types of algorithms in its `alg` field.
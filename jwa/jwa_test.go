@@ -1,9 +1,11 @@
 package jwa_test
 
 import (
+	"crypto"
 	"fmt"
 	"testing"
 
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/stretchr/testify/assert"
 )
@@ -66,3 +68,247 @@ func TestKeyAlgorithmFrom(t *testing.T) {
 		})
 	}
 }
+
+// TestRegisterAlgorithm is not run in parallel, because registering
+// values affects the lists checked by the generated tests
+func TestRegisterAlgorithm(t *testing.T) {
+	t.Run("SignatureAlgorithm", func(t *testing.T) {
+		const custom = jwa.SignatureAlgorithm(`X-CUSTOM-SIG`)
+		var dst jwa.SignatureAlgorithm
+		if !assert.Error(t, dst.Accept(custom.String()), `accept should fail before registration`) {
+			return
+		}
+
+		jwa.RegisterSignatureAlgorithm(custom)
+		defer jwa.UnregisterSignatureAlgorithm(custom)
+		if !assert.NoError(t, dst.Accept(custom.String()), `accept should succeed after registration`) {
+			return
+		}
+		if !assert.Equal(t, custom, dst, `accepted value should be equal to registered value`) {
+			return
+		}
+		if !assert.Contains(t, jwa.SignatureAlgorithms(), custom, `list should contain registered value`) {
+			return
+		}
+		if !assert.Equal(t, custom, jwa.KeyAlgorithmFrom(custom.String()), `jwa.KeyAlgorithmFrom should return registered value`) {
+			return
+		}
+
+		jwa.UnregisterSignatureAlgorithm(custom)
+		if !assert.Error(t, dst.Accept(custom.String()), `accept should fail after unregistration`) {
+			return
+		}
+		if !assert.NotContains(t, jwa.SignatureAlgorithms(), custom, `list should not contain unregistered value`) {
+			return
+		}
+	})
+	t.Run("KeyEncryptionAlgorithm", func(t *testing.T) {
+		const custom = jwa.KeyEncryptionAlgorithm(`X-CUSTOM-KW`)
+		var dst jwa.KeyEncryptionAlgorithm
+		if !assert.Error(t, dst.Accept(custom.String()), `accept should fail before registration`) {
+			return
+		}
+
+		jwa.RegisterKeyEncryptionAlgorithm(custom)
+		defer jwa.UnregisterKeyEncryptionAlgorithm(custom)
+		if !assert.NoError(t, dst.Accept(custom.String()), `accept should succeed after registration`) {
+			return
+		}
+		if !assert.Equal(t, custom, dst, `accepted value should be equal to registered value`) {
+			return
+		}
+		if !assert.Contains(t, jwa.KeyEncryptionAlgorithms(), custom, `list should contain registered value`) {
+			return
+		}
+	})
+	t.Run("ContentEncryptionAlgorithm", func(t *testing.T) {
+		const custom = jwa.ContentEncryptionAlgorithm(`X-CUSTOM-ENC`)
+		var dst jwa.ContentEncryptionAlgorithm
+		if !assert.Error(t, dst.Accept(custom.String()), `accept should fail before registration`) {
+			return
+		}
+
+		jwa.RegisterContentEncryptionAlgorithm(custom)
+		defer jwa.UnregisterContentEncryptionAlgorithm(custom)
+		if !assert.NoError(t, dst.Accept(custom.String()), `accept should succeed after registration`) {
+			return
+		}
+		if !assert.Equal(t, custom, dst, `accepted value should be equal to registered value`) {
+			return
+		}
+		if !assert.Contains(t, jwa.ContentEncryptionAlgorithms(), custom, `list should contain registered value`) {
+			return
+		}
+	})
+}
+
+// TestStrict is not run in parallel, because it changes global settings
+func TestStrict(t *testing.T) {
+	type algorithms struct {
+		Signature jwa.SignatureAlgorithm         `json:"sig"`
+		KeyEnc    jwa.KeyEncryptionAlgorithm     `json:"kenc"`
+		Enc       jwa.ContentEncryptionAlgorithm `json:"enc"`
+	}
+
+	testcases := []struct {
+		Name  string
+		Input string
+		Error bool
+	}{
+		{
+			Name:  "known values",
+			Input: `{"sig":"ES256","kenc":"RSA-OAEP","enc":"A128GCM"}`,
+		},
+		{
+			Name:  "unknown signature algorithm",
+			Input: `{"sig":"ES257"}`,
+			Error: true,
+		},
+		{
+			Name:  "empty key encryption algorithm",
+			Input: `{"kenc":""}`,
+			Error: true,
+		},
+		{
+			Name:  "unknown content encryption algorithm",
+			Input: `{"enc":"A128GCM "}`,
+			Error: true,
+		},
+		{
+			Name:  "non-string value",
+			Input: `{"sig":256}`,
+			Error: true,
+		},
+	}
+
+	for _, strict := range []bool{false, true} {
+		strict := strict
+		t.Run(fmt.Sprintf("strict=%t", strict), func(t *testing.T) {
+			jwa.Settings(jwa.WithStrict(strict))
+			defer jwa.Settings(jwa.WithStrict(false))
+			if !assert.Equal(t, strict, jwa.IsStrict(), `jwa.IsStrict should match settings`) {
+				return
+			}
+
+			for _, tc := range testcases {
+				tc := tc
+				t.Run(tc.Name, func(t *testing.T) {
+					var dst algorithms
+					err := json.Unmarshal([]byte(tc.Input), &dst)
+					// non-string values are always rejected
+					if tc.Error && (strict || tc.Name == "non-string value") {
+						assert.Error(t, err, `json.Unmarshal should fail`)
+						return
+					}
+					assert.NoError(t, err, `json.Unmarshal should succeed`)
+				})
+			}
+		})
+	}
+
+	t.Run("descriptive errors", func(t *testing.T) {
+		var dst jwa.SignatureAlgorithm
+		err := dst.Accept(`ES257`)
+		if !assert.Error(t, err, `accept should fail`) {
+			return
+		}
+		if !assert.Contains(t, err.Error(), `"ES257"`, `error should contain the value`) {
+			return
+		}
+		err = dst.Accept(``)
+		if !assert.Error(t, err, `accept should fail`) {
+			return
+		}
+		if !assert.Contains(t, err.Error(), `empty`, `error should mention the empty value`) {
+			return
+		}
+	})
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+	t.Run("SignatureAlgorithm", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Alg                jwa.SignatureAlgorithm
+			Symmetric          bool
+			RequiresPrivateKey bool
+			Hash               crypto.Hash
+			KeyTypes           []jwa.KeyType
+		}{
+			{Alg: jwa.HS384, Symmetric: true, Hash: crypto.SHA384, KeyTypes: []jwa.KeyType{jwa.OctetSeq}},
+			{Alg: jwa.RS256, RequiresPrivateKey: true, Hash: crypto.SHA256, KeyTypes: []jwa.KeyType{jwa.RSA}},
+			{Alg: jwa.PS512, RequiresPrivateKey: true, Hash: crypto.SHA512, KeyTypes: []jwa.KeyType{jwa.RSA}},
+			{Alg: jwa.ES256K, RequiresPrivateKey: true, Hash: crypto.SHA256, KeyTypes: []jwa.KeyType{jwa.EC}},
+			{Alg: jwa.EdDSA, RequiresPrivateKey: true, Hash: crypto.SHA512, KeyTypes: []jwa.KeyType{jwa.OKP}},
+			{Alg: jwa.NoSignature},
+			{Alg: jwa.SignatureAlgorithm(`X-UNKNOWN`)},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Alg.String(), func(t *testing.T) {
+				t.Parallel()
+				assert.Equal(t, tc.Symmetric, tc.Alg.IsSymmetric(), `IsSymmetric should match`)
+				assert.Equal(t, tc.RequiresPrivateKey, tc.Alg.RequiresPrivateKey(), `RequiresPrivateKey should match`)
+				h, ok := tc.Alg.HashFor()
+				assert.Equal(t, tc.Hash, h, `HashFor should match`)
+				assert.Equal(t, tc.Hash != 0, ok, `HashFor should report whether a hash is available`)
+				assert.Equal(t, tc.KeyTypes, tc.Alg.KeyTypeFor(), `KeyTypeFor should match`)
+			})
+		}
+	})
+	t.Run("KeyEncryptionAlgorithm", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Alg                jwa.KeyEncryptionAlgorithm
+			Symmetric          bool
+			RequiresPrivateKey bool
+			Hash               crypto.Hash
+			KeyTypes           []jwa.KeyType
+		}{
+			{Alg: jwa.RSA1_5, RequiresPrivateKey: true, KeyTypes: []jwa.KeyType{jwa.RSA}},
+			{Alg: jwa.RSA_OAEP, RequiresPrivateKey: true, Hash: crypto.SHA1, KeyTypes: []jwa.KeyType{jwa.RSA}},
+			{Alg: jwa.ECDH_ES_A128KW, RequiresPrivateKey: true, Hash: crypto.SHA256, KeyTypes: []jwa.KeyType{jwa.EC, jwa.OKP}},
+			{Alg: jwa.A192GCMKW, Symmetric: true, KeyTypes: []jwa.KeyType{jwa.OctetSeq}},
+			{Alg: jwa.PBES2_HS512_A256KW, Symmetric: true, Hash: crypto.SHA512, KeyTypes: []jwa.KeyType{jwa.OctetSeq}},
+			{Alg: jwa.DIRECT, Symmetric: true, KeyTypes: []jwa.KeyType{jwa.OctetSeq}},
+			{Alg: jwa.KeyEncryptionAlgorithm(`X-UNKNOWN`)},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Alg.String(), func(t *testing.T) {
+				t.Parallel()
+				assert.Equal(t, tc.Symmetric, tc.Alg.IsSymmetric(), `IsSymmetric should match`)
+				assert.Equal(t, tc.RequiresPrivateKey, tc.Alg.RequiresPrivateKey(), `RequiresPrivateKey should match`)
+				h, ok := tc.Alg.HashFor()
+				assert.Equal(t, tc.Hash, h, `HashFor should match`)
+				assert.Equal(t, tc.Hash != 0, ok, `HashFor should report whether a hash is available`)
+				assert.Equal(t, tc.KeyTypes, tc.Alg.KeyTypeFor(), `KeyTypeFor should match`)
+			})
+		}
+	})
+	t.Run("ContentEncryptionAlgorithm", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Alg       jwa.ContentEncryptionAlgorithm
+			Symmetric bool
+			Hash      crypto.Hash
+			KeyTypes  []jwa.KeyType
+		}{
+			{Alg: jwa.A192CBC_HS384, Symmetric: true, Hash: crypto.SHA384, KeyTypes: []jwa.KeyType{jwa.OctetSeq}},
+			{Alg: jwa.A256GCM, Symmetric: true, KeyTypes: []jwa.KeyType{jwa.OctetSeq}},
+			{Alg: jwa.ContentEncryptionAlgorithm(`X-UNKNOWN`)},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Alg.String(), func(t *testing.T) {
+				t.Parallel()
+				assert.Equal(t, tc.Symmetric, tc.Alg.IsSymmetric(), `IsSymmetric should match`)
+				h, ok := tc.Alg.HashFor()
+				assert.Equal(t, tc.Hash, h, `HashFor should match`)
+				assert.Equal(t, tc.Hash != 0, ok, `HashFor should report whether a hash is available`)
+				assert.Equal(t, tc.KeyTypes, tc.Alg.KeyTypeFor(), `KeyTypeFor should match`)
+			})
+		}
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
 
 // KeyEncryptionAlgorithm represents the various encryption algorithms as described in https://tools.ietf.org/html/rfc7518#section-4.1
@@ -32,6 +34,7 @@ const (
 	RSA_OAEP_256       KeyEncryptionAlgorithm = "RSA-OAEP-256"       // RSA-OAEP-SHA256
 )
 
+var muKeyEncryptionAlgorithms sync.RWMutex
 var allKeyEncryptionAlgorithms = map[KeyEncryptionAlgorithm]struct{}{
 	A128GCMKW:          {},
 	A128KW:             {},
@@ -51,21 +54,51 @@ var allKeyEncryptionAlgorithms = map[KeyEncryptionAlgorithm]struct{}{
 	RSA_OAEP:           {},
 	RSA_OAEP_256:       {},
 }
-
-var listKeyEncryptionAlgorithmOnce sync.Once
 var listKeyEncryptionAlgorithm []KeyEncryptionAlgorithm
 
+func init() {
+	rebuildKeyEncryptionAlgorithm()
+}
+
+// RegisterKeyEncryptionAlgorithm registers a new KeyEncryptionAlgorithm so that values
+// that are not known to jwx can be handled by Accept() and friends.
+// Registering a value that is already known is a no-op.
+func RegisterKeyEncryptionAlgorithm(v KeyEncryptionAlgorithm) {
+	muKeyEncryptionAlgorithms.Lock()
+	defer muKeyEncryptionAlgorithms.Unlock()
+	if _, ok := allKeyEncryptionAlgorithms[v]; !ok {
+		allKeyEncryptionAlgorithms[v] = struct{}{}
+		rebuildKeyEncryptionAlgorithm()
+	}
+}
+
+// UnregisterKeyEncryptionAlgorithm removes a KeyEncryptionAlgorithm from the list of known values.
+// Unregistering a value that is not known is a no-op.
+func UnregisterKeyEncryptionAlgorithm(v KeyEncryptionAlgorithm) {
+	muKeyEncryptionAlgorithms.Lock()
+	defer muKeyEncryptionAlgorithms.Unlock()
+	if _, ok := allKeyEncryptionAlgorithms[v]; ok {
+		delete(allKeyEncryptionAlgorithms, v)
+		rebuildKeyEncryptionAlgorithm()
+	}
+}
+
+// rebuildKeyEncryptionAlgorithm must be called with muKeyEncryptionAlgorithms held
+func rebuildKeyEncryptionAlgorithm() {
+	list := make([]KeyEncryptionAlgorithm, 0, len(allKeyEncryptionAlgorithms))
+	for v := range allKeyEncryptionAlgorithms {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i]) < string(list[j])
+	})
+	listKeyEncryptionAlgorithm = list
+}
+
 // KeyEncryptionAlgorithms returns a list of all available values for KeyEncryptionAlgorithm
 func KeyEncryptionAlgorithms() []KeyEncryptionAlgorithm {
-	listKeyEncryptionAlgorithmOnce.Do(func() {
-		listKeyEncryptionAlgorithm = make([]KeyEncryptionAlgorithm, 0, len(allKeyEncryptionAlgorithms))
-		for v := range allKeyEncryptionAlgorithms {
-			listKeyEncryptionAlgorithm = append(listKeyEncryptionAlgorithm, v)
-		}
-		sort.Slice(listKeyEncryptionAlgorithm, func(i, j int) bool {
-			return string(listKeyEncryptionAlgorithm[i]) < string(listKeyEncryptionAlgorithm[j])
-		})
-	})
+	muKeyEncryptionAlgorithms.RLock()
+	defer muKeyEncryptionAlgorithms.RUnlock()
 	return listKeyEncryptionAlgorithm
 }
 
@@ -87,14 +120,36 @@ func (v *KeyEncryptionAlgorithm) Accept(value interface{}) error {
 		}
 		tmp = KeyEncryptionAlgorithm(s)
 	}
-	if _, ok := allKeyEncryptionAlgorithms[tmp]; !ok {
-		return fmt.Errorf(`invalid jwa.KeyEncryptionAlgorithm value`)
+
+	muKeyEncryptionAlgorithms.RLock()
+	_, ok := allKeyEncryptionAlgorithms[tmp]
+	muKeyEncryptionAlgorithms.RUnlock()
+	if !ok {
+		if tmp == "" {
+			return fmt.Errorf(`invalid jwa.KeyEncryptionAlgorithm value: empty value`)
+		}
+		return fmt.Errorf(`invalid jwa.KeyEncryptionAlgorithm value: unknown value %q`, tmp)
 	}
 
 	*v = tmp
 	return nil
 }
 
+// UnmarshalJSON decodes a JSON string into a KeyEncryptionAlgorithm.
+// If strict mode is enabled (see `jwa.Settings()`), the value is validated
+// using Accept(). Otherwise the value is assigned as is.
+func (v *KeyEncryptionAlgorithm) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(`failed to decode jwa.KeyEncryptionAlgorithm: %w`, err)
+	}
+	if IsStrict() {
+		return v.Accept(s)
+	}
+	*v = KeyEncryptionAlgorithm(s)
+	return nil
+}
+
 // String returns the string representation of a KeyEncryptionAlgorithm
 func (v KeyEncryptionAlgorithm) String() string {
 	return string(v)
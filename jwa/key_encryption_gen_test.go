@@ -637,6 +637,13 @@ func TestKeyEncryptionAlgorithm(t *testing.T) {
 			return
 		}
 	})
+	t.Run(`do not accept empty string value`, func(t *testing.T) {
+		t.Parallel()
+		var dst jwa.KeyEncryptionAlgorithm
+		if !assert.Error(t, dst.Accept(``), `accept should fail`) {
+			return
+		}
+	})
 	t.Run(`check symmetric values`, func(t *testing.T) {
 		t.Parallel()
 		t.Run(`A128GCMKW`, func(t *testing.T) {
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
 
 // KeyType represents the key type ("kty") that are supported
@@ -20,27 +22,58 @@ const (
 	RSA            KeyType = "RSA" // RSA
 )
 
+var muKeyTypes sync.RWMutex
 var allKeyTypes = map[KeyType]struct{}{
 	EC:       {},
 	OKP:      {},
 	OctetSeq: {},
 	RSA:      {},
 }
-
-var listKeyTypeOnce sync.Once
 var listKeyType []KeyType
 
+func init() {
+	rebuildKeyType()
+}
+
+// RegisterKeyType registers a new KeyType so that values
+// that are not known to jwx can be handled by Accept() and friends.
+// Registering a value that is already known is a no-op.
+func RegisterKeyType(v KeyType) {
+	muKeyTypes.Lock()
+	defer muKeyTypes.Unlock()
+	if _, ok := allKeyTypes[v]; !ok {
+		allKeyTypes[v] = struct{}{}
+		rebuildKeyType()
+	}
+}
+
+// UnregisterKeyType removes a KeyType from the list of known values.
+// Unregistering a value that is not known is a no-op.
+func UnregisterKeyType(v KeyType) {
+	muKeyTypes.Lock()
+	defer muKeyTypes.Unlock()
+	if _, ok := allKeyTypes[v]; ok {
+		delete(allKeyTypes, v)
+		rebuildKeyType()
+	}
+}
+
+// rebuildKeyType must be called with muKeyTypes held
+func rebuildKeyType() {
+	list := make([]KeyType, 0, len(allKeyTypes))
+	for v := range allKeyTypes {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i]) < string(list[j])
+	})
+	listKeyType = list
+}
+
 // KeyTypes returns a list of all available values for KeyType
 func KeyTypes() []KeyType {
-	listKeyTypeOnce.Do(func() {
-		listKeyType = make([]KeyType, 0, len(allKeyTypes))
-		for v := range allKeyTypes {
-			listKeyType = append(listKeyType, v)
-		}
-		sort.Slice(listKeyType, func(i, j int) bool {
-			return string(listKeyType[i]) < string(listKeyType[j])
-		})
-	})
+	muKeyTypes.RLock()
+	defer muKeyTypes.RUnlock()
 	return listKeyType
 }
 
@@ -62,14 +95,36 @@ func (v *KeyType) Accept(value interface{}) error {
 		}
 		tmp = KeyType(s)
 	}
-	if _, ok := allKeyTypes[tmp]; !ok {
-		return fmt.Errorf(`invalid jwa.KeyType value`)
+
+	muKeyTypes.RLock()
+	_, ok := allKeyTypes[tmp]
+	muKeyTypes.RUnlock()
+	if !ok {
+		if tmp == "" {
+			return fmt.Errorf(`invalid jwa.KeyType value: empty value`)
+		}
+		return fmt.Errorf(`invalid jwa.KeyType value: unknown value %q`, tmp)
 	}
 
 	*v = tmp
 	return nil
 }
 
+// UnmarshalJSON decodes a JSON string into a KeyType.
+// If strict mode is enabled (see `jwa.Settings()`), the value is validated
+// using Accept(). Otherwise the value is assigned as is.
+func (v *KeyType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(`failed to decode jwa.KeyType: %w`, err)
+	}
+	if IsStrict() {
+		return v.Accept(s)
+	}
+	*v = KeyType(s)
+	return nil
+}
+
 // String returns the string representation of a KeyType
 func (v KeyType) String() string {
 	return string(v)
@@ -176,6 +176,13 @@ func TestKeyType(t *testing.T) {
 			return
 		}
 	})
+	t.Run(`do not accept empty string value`, func(t *testing.T) {
+		t.Parallel()
+		var dst jwa.KeyType
+		if !assert.Error(t, dst.Accept(``), `accept should fail`) {
+			return
+		}
+	})
 	t.Run(`check list of elements`, func(t *testing.T) {
 		t.Parallel()
 		var expected = map[jwa.KeyType]struct{}{
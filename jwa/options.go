@@ -0,0 +1,20 @@
+package jwa
+
+import "github.com/lestrrat-go/option"
+
+// Option describes an option that can be passed to `jwa.Settings()`.
+type Option = option.Interface
+
+type identStrict struct{}
+
+// WithStrict specifies whether jwa values decoded from JSON, and the
+// `alg` field of JWKs, should be validated.
+//
+// When enabled, decoding a value that is empty or not known to jwx
+// (see `jwa.RegisterSignatureAlgorithm()` and friends) results in an
+// error, instead of the value being carried around as an opaque string.
+//
+// The default is false.
+func WithStrict(v bool) Option {
+	return option.New(identStrict{}, v)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
 
 // SignatureAlgorithm represents the various signature algorithms as described in https://tools.ietf.org/html/rfc7518#section-3.1
@@ -30,6 +32,7 @@ const (
 	RS512       SignatureAlgorithm = "RS512" // RSASSA-PKCS-v1.5 using SHA-512
 )
 
+var muSignatureAlgorithms sync.RWMutex
 var allSignatureAlgorithms = map[SignatureAlgorithm]struct{}{
 	ES256:       {},
 	ES256K:      {},
@@ -47,21 +50,51 @@ var allSignatureAlgorithms = map[SignatureAlgorithm]struct{}{
 	RS384:       {},
 	RS512:       {},
 }
-
-var listSignatureAlgorithmOnce sync.Once
 var listSignatureAlgorithm []SignatureAlgorithm
 
+func init() {
+	rebuildSignatureAlgorithm()
+}
+
+// RegisterSignatureAlgorithm registers a new SignatureAlgorithm so that values
+// that are not known to jwx can be handled by Accept() and friends.
+// Registering a value that is already known is a no-op.
+func RegisterSignatureAlgorithm(v SignatureAlgorithm) {
+	muSignatureAlgorithms.Lock()
+	defer muSignatureAlgorithms.Unlock()
+	if _, ok := allSignatureAlgorithms[v]; !ok {
+		allSignatureAlgorithms[v] = struct{}{}
+		rebuildSignatureAlgorithm()
+	}
+}
+
+// UnregisterSignatureAlgorithm removes a SignatureAlgorithm from the list of known values.
+// Unregistering a value that is not known is a no-op.
+func UnregisterSignatureAlgorithm(v SignatureAlgorithm) {
+	muSignatureAlgorithms.Lock()
+	defer muSignatureAlgorithms.Unlock()
+	if _, ok := allSignatureAlgorithms[v]; ok {
+		delete(allSignatureAlgorithms, v)
+		rebuildSignatureAlgorithm()
+	}
+}
+
+// rebuildSignatureAlgorithm must be called with muSignatureAlgorithms held
+func rebuildSignatureAlgorithm() {
+	list := make([]SignatureAlgorithm, 0, len(allSignatureAlgorithms))
+	for v := range allSignatureAlgorithms {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i]) < string(list[j])
+	})
+	listSignatureAlgorithm = list
+}
+
 // SignatureAlgorithms returns a list of all available values for SignatureAlgorithm
 func SignatureAlgorithms() []SignatureAlgorithm {
-	listSignatureAlgorithmOnce.Do(func() {
-		listSignatureAlgorithm = make([]SignatureAlgorithm, 0, len(allSignatureAlgorithms))
-		for v := range allSignatureAlgorithms {
-			listSignatureAlgorithm = append(listSignatureAlgorithm, v)
-		}
-		sort.Slice(listSignatureAlgorithm, func(i, j int) bool {
-			return string(listSignatureAlgorithm[i]) < string(listSignatureAlgorithm[j])
-		})
-	})
+	muSignatureAlgorithms.RLock()
+	defer muSignatureAlgorithms.RUnlock()
 	return listSignatureAlgorithm
 }
 
@@ -83,14 +116,36 @@ func (v *SignatureAlgorithm) Accept(value interface{}) error {
 		}
 		tmp = SignatureAlgorithm(s)
 	}
-	if _, ok := allSignatureAlgorithms[tmp]; !ok {
-		return fmt.Errorf(`invalid jwa.SignatureAlgorithm value`)
+
+	muSignatureAlgorithms.RLock()
+	_, ok := allSignatureAlgorithms[tmp]
+	muSignatureAlgorithms.RUnlock()
+	if !ok {
+		if tmp == "" {
+			return fmt.Errorf(`invalid jwa.SignatureAlgorithm value: empty value`)
+		}
+		return fmt.Errorf(`invalid jwa.SignatureAlgorithm value: unknown value %q`, tmp)
 	}
 
 	*v = tmp
 	return nil
 }
 
+// UnmarshalJSON decodes a JSON string into a SignatureAlgorithm.
+// If strict mode is enabled (see `jwa.Settings()`), the value is validated
+// using Accept(). Otherwise the value is assigned as is.
+func (v *SignatureAlgorithm) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf(`failed to decode jwa.SignatureAlgorithm: %w`, err)
+	}
+	if IsStrict() {
+		return v.Accept(s)
+	}
+	*v = SignatureAlgorithm(s)
+	return nil
+}
+
 // String returns the string representation of a SignatureAlgorithm
 func (v SignatureAlgorithm) String() string {
 	return string(v)
@@ -9,8 +9,22 @@ import (
 	"github.com/lestrrat-go/jwx/v2/internal/pool"
 )
 
-func uncompress(plaintext []byte) ([]byte, error) {
-	return io.ReadAll(flate.NewReader(bytes.NewReader(plaintext)))
+// uncompress inflates the plaintext. If maxSize is positive, the result
+// may not be larger than maxSize bytes
+func uncompress(plaintext []byte, maxSize int) ([]byte, error) {
+	var r io.Reader = flate.NewReader(bytes.NewReader(plaintext))
+	if maxSize > 0 {
+		r = io.LimitReader(r, int64(maxSize)+1)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && len(buf) > maxSize {
+		return nil, fmt.Errorf(`decompressed payload exceeds maximum size of %d bytes`, maxSize)
+	}
+	return buf, nil
 }
 
 func compress(plaintext []byte) ([]byte, error) {
@@ -4,18 +4,21 @@
 package jwe
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"fmt"
 	"io"
+	"unicode"
 
 	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jwx/v2/internal/base64"
 	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/internal/keyconv"
 	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/observe"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwe/internal/content_crypt"
@@ -403,6 +406,7 @@ type decryptCtx struct {
 	computedAad      []byte
 	keyProviders     []KeyProvider
 	protectedHeaders Headers
+	maxDecompress    int
 }
 
 // Decrypt takes the key encryption algorithm and the corresponding
@@ -414,9 +418,12 @@ type decryptCtx struct {
 // `jwa.KeyEncryptionAlgorithm` or otherwise it will cause an error.
 //
 // `key` must be a private key. It can be either in its raw format (e.g. *rsa.PrivateKey) or a jwk.Key
-func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
+func Decrypt(buf []byte, options ...DecryptOption) (_ []byte, err error) {
 	var keyProviders []KeyProvider
 	var keyUsed interface{}
+	var maxDecompress int
+
+	ctx := context.Background()
 
 	var dst *Message
 	//nolint:forcetypeassert
@@ -428,6 +435,10 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 			keyProviders = append(keyProviders, option.Value().(KeyProvider))
 		case identKeyUsed{}:
 			keyUsed = option.Value()
+		case identMaxDecompressBufferSize{}:
+			maxDecompress = option.Value().(int)
+		case identContext{}:
+			ctx = option.Value().(context.Context)
 		case identKey{}:
 			pair := option.Value().(*withKey)
 			alg, ok := pair.alg.(jwa.KeyEncryptionAlgorithm)
@@ -441,6 +452,9 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 		}
 	}
 
+	ctx, span := observe.Start(ctx, observe.JWEDecrypt)
+	defer func() { span.End(err) }()
+
 	if len(keyProviders) < 1 {
 		return nil, fmt.Errorf(`jwe.Decrypt: no key providers have been provided (see jwe.WithKey(), jwe.WithKeySet(), and jwe.WithKeyProvider()`)
 	}
@@ -451,7 +465,6 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 	}
 
 	// Process things that are common to the message
-	ctx := context.TODO()
 	h, err := msg.protectedHeaders.Clone(ctx)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to copy protected headers: %w`, err)
@@ -460,6 +473,7 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf(`failed to merge headers for message decryption: %w`, err)
 	}
+	setSpanAttributes(span, h)
 
 	var aad []byte
 	if aadContainer := msg.authenticatedData; aadContainer != nil {
@@ -496,6 +510,7 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 	dctx.msg = msg
 	dctx.keyProviders = keyProviders
 	dctx.protectedHeaders = h
+	dctx.maxDecompress = maxDecompress
 
 	var lastError error
 	for _, recipient := range recipients {
@@ -504,6 +519,7 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 			lastError = err
 			continue
 		}
+		setSpanAttributes(span, recipient.Headers())
 		if dst != nil {
 			*dst = *msg
 			dst.rawProtectedHeaders = nil
@@ -514,6 +530,18 @@ func Decrypt(buf []byte, options ...DecryptOption) ([]byte, error) {
 	return nil, fmt.Errorf(`jwe.Decrypt: failed to decrypt any of the recipients (last error = %w)`, lastError)
 }
 
+func setSpanAttributes(span observe.Span, h Headers) {
+	if h == nil {
+		return
+	}
+	if alg := h.Algorithm(); alg != "" {
+		span.SetAttribute(observe.AttrAlgorithm, alg.String())
+	}
+	if kid := h.KeyID(); kid != "" {
+		span.SetAttribute(observe.AttrKeyID, kid)
+	}
+}
+
 func (dctx *decryptCtx) try(ctx context.Context, recipient Recipient, keyUsed interface{}) ([]byte, error) {
 	var tried int
 	var lastError error
@@ -667,7 +695,7 @@ func (dctx *decryptCtx) decryptKey(ctx context.Context, alg jwa.KeyEncryptionAlg
 	}
 
 	if h2.Compression() == jwa.Deflate {
-		buf, err := uncompress(plaintext)
+		buf, err := uncompress(plaintext, dctx.maxDecompress)
 		if err != nil {
 			return nil, fmt.Errorf(`jwe.Derypt: failed to uncompress payload: %w`, err)
 		}
@@ -703,17 +731,45 @@ func parseJSONOrCompact(buf []byte, storeProtectedHeaders bool) (*Message, error
 }
 
 // ParseString is the same as Parse, but takes a string.
-func ParseString(s string) (*Message, error) {
-	return Parse([]byte(s))
+func ParseString(s string, options ...ParseOption) (*Message, error) {
+	return Parse([]byte(s), options...)
 }
 
-// ParseReader is the same as Parse, but takes an io.Reader.
-func ParseReader(src io.Reader) (*Message, error) {
-	buf, err := io.ReadAll(src)
+// ParseReader is the same as Parse, but takes an io.Reader. Messages in
+// JSON serialization are decoded as they are read, without reading all
+// of the contents of the reader into memory first, so it can be used to
+// read messages directly from sources such as HTTP request bodies.
+func ParseReader(src io.Reader, options ...ParseOption) (*Message, error) {
+	rdr := bufio.NewReader(src)
+	for {
+		r, _, err := rdr.ReadRune()
+		if err != nil {
+			return nil, fmt.Errorf(`failed to read rune: %w`, err)
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if err := rdr.UnreadRune(); err != nil {
+			return nil, fmt.Errorf(`failed to unread rune: %w`, err)
+		}
+		if r != '{' {
+			break
+		}
+
+		m := NewMessage()
+		if err := json.NewDecoder(rdr).Decode(m); err != nil {
+			return nil, fmt.Errorf(`failed to parse JSON: %w`, err)
+		}
+		return m, nil
+	}
+
+	// The compact serialization can only be parsed once all of its
+	// parts have been read
+	buf, err := io.ReadAll(rdr)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to read from io.Reader: %w`, err)
 	}
-	return Parse(buf)
+	return Parse(buf, options...)
 }
 
 func parseJSON(buf []byte, storeProtectedHeaders bool) (*Message, error) {
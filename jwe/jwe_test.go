@@ -12,6 +12,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/lestrrat-go/jwx/v2/internal/json"
@@ -142,6 +143,29 @@ func TestParse(t *testing.T) {
 			return
 		}
 	})
+	t.Run("ParseString and ParseReader", func(t *testing.T) {
+		expected, err := jwe.Parse([]byte(s))
+		require.NoError(t, err, `jwe.Parse should succeed`)
+		buf, err := json.Marshal(expected)
+		require.NoError(t, err, `json.Marshal should succeed`)
+
+		for _, src := range []string{s, string(buf)} {
+			msg, err := jwe.ParseString(src)
+			require.NoError(t, err, `jwe.ParseString should succeed`)
+			require.Equal(t, expected, msg, `messages should match`)
+
+			// Read one byte at a time, preceded by whitespace, so that the
+			// message must be decoded as it is read
+			msg, err = jwe.ParseReader(iotest.OneByteReader(strings.NewReader("\n " + src)))
+			require.NoError(t, err, `jwe.ParseReader should succeed`)
+			require.Equal(t, expected, msg, `messages should match`)
+		}
+
+		_, err = jwe.ParseReader(strings.NewReader(`  `))
+		require.Error(t, err, `jwe.ParseReader should fail for empty input`)
+		_, err = jwe.ParseReader(strings.NewReader(`{"protected":`))
+		require.Error(t, err, `jwe.ParseReader should fail for truncated JSON`)
+	})
 }
 
 // This test parses the example found in https://tools.ietf.org/html/rfc7516#appendix-A.1,
@@ -841,3 +865,26 @@ func TestGH840(t *testing.T) {
 	_, err = jwe.Encrypt([]byte(payload), jwe.WithKey(jwa.ECDH_ES_A128KW, pubkey))
 	require.Error(t, err, `jwe.Encrypt should fail (instead of panic)`)
 }
+
+func TestMaxDecompressBufferSize(t *testing.T) {
+	key := make([]byte, 16)
+	_, err := rand.Read(key)
+	require.NoError(t, err, `rand.Read should succeed`)
+
+	// Highly compressible payload
+	payload := []byte(strings.Repeat(`a`, 1<<16))
+	encrypted, err := jwe.Encrypt(payload, jwe.WithKey(jwa.A128KW, key), jwe.WithCompress(jwa.Deflate))
+	require.NoError(t, err, `jwe.Encrypt should succeed`)
+	require.True(t, len(encrypted) < len(payload), `compressed message should be smaller than the payload`)
+
+	decrypted, err := jwe.Decrypt(encrypted, jwe.WithKey(jwa.A128KW, key))
+	require.NoError(t, err, `jwe.Decrypt should succeed without a limit`)
+	require.Equal(t, payload, decrypted, `payloads should match`)
+
+	decrypted, err = jwe.Decrypt(encrypted, jwe.WithKey(jwa.A128KW, key), jwe.WithMaxDecompressBufferSize(len(payload)))
+	require.NoError(t, err, `jwe.Decrypt should succeed when the payload is within the limit`)
+	require.Equal(t, payload, decrypted, `payloads should match`)
+
+	_, err = jwe.Decrypt(encrypted, jwe.WithKey(jwa.A128KW, key), jwe.WithMaxDecompressBufferSize(len(payload)-1))
+	require.Error(t, err, `jwe.Decrypt should fail when the payload exceeds the limit`)
+}
@@ -3,6 +3,7 @@
 package jwe
 
 import (
+	"context"
 	"io/fs"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
@@ -112,10 +113,12 @@ func (*withKeySetSuboption) withKeySetSuboption() {}
 
 type identCompress struct{}
 type identContentEncryptionAlgorithm struct{}
+type identContext struct{}
 type identFS struct{}
 type identKey struct{}
 type identKeyProvider struct{}
 type identKeyUsed struct{}
+type identMaxDecompressBufferSize struct{}
 type identMergeProtectedHeaders struct{}
 type identMessage struct{}
 type identPerRecipientHeaders struct{}
@@ -132,6 +135,10 @@ func (identContentEncryptionAlgorithm) String() string {
 	return "WithContentEncryption"
 }
 
+func (identContext) String() string {
+	return "WithContext"
+}
+
 func (identFS) String() string {
 	return "WithFS"
 }
@@ -148,6 +155,10 @@ func (identKeyUsed) String() string {
 	return "WithKeyUsed"
 }
 
+func (identMaxDecompressBufferSize) String() string {
+	return "WithMaxDecompressBufferSize"
+}
+
 func (identMergeProtectedHeaders) String() string {
 	return "WithMergeProtectedHeaders"
 }
@@ -190,6 +201,13 @@ func WithContentEncryption(v jwa.ContentEncryptionAlgorithm) EncryptOption {
 	return &encryptOption{option.New(identContentEncryptionAlgorithm{}, v)}
 }
 
+// WithContext specifies the context that is passed to the key providers,
+// and used as the parent context when observing `jwe.Decrypt()` through
+// the `observe` package. By default `context.Background()` is used.
+func WithContext(v context.Context) DecryptOption {
+	return &decryptOption{option.New(identContext{}, v)}
+}
+
 // WithFS specifies the source `fs.FS` object to read the file from.
 func WithFS(v fs.FS) ReadFileOption {
 	return &readFileOption{option.New(identFS{}, v)}
@@ -213,6 +231,16 @@ func WithKeyUsed(v interface{}) DecryptOption {
 	return &decryptOption{option.New(identKeyUsed{}, v)}
 }
 
+// WithMaxDecompressBufferSize specifies the maximum number of bytes
+// that a compressed (`"zip": "DEF"`) payload may expand to when it is
+// decompressed by `jwe.Decrypt()`. Payloads that exceed this limit
+// are rejected, which protects against decompression bombs.
+//
+// By default, or if the value is zero or negative, no limit is imposed.
+func WithMaxDecompressBufferSize(v int) DecryptOption {
+	return &decryptOption{option.New(identMaxDecompressBufferSize{}, v)}
+}
+
 // WithMergeProtectedHeaders specify that when given multiple headers
 // as options to `jwe.Encrypt`, these headers should be merged instead
 // of overwritten
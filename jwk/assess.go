@@ -0,0 +1,244 @@
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/cert"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+)
+
+// FindingSeverity describes how serious a `jwk.Finding` is
+type FindingSeverity string
+
+const (
+	SeverityInfo     FindingSeverity = "info"
+	SeverityWarning  FindingSeverity = "warning"
+	SeverityCritical FindingSeverity = "critical"
+)
+
+// FindingCode identifies the kind of a `jwk.Finding`
+type FindingCode string
+
+const (
+	FindingWeakKeySize            FindingCode = "weak_key_size"
+	FindingDeprecatedAlgorithm    FindingCode = "deprecated_algorithm"
+	FindingAlgorithmMismatch      FindingCode = "algorithm_mismatch"
+	FindingMissingKeyID           FindingCode = "missing_kid"
+	FindingMissingKeyUsage        FindingCode = "missing_use"
+	FindingInvalidCertificate     FindingCode = "invalid_x5c"
+	FindingExpiredCertificate     FindingCode = "expired_x5c"
+	FindingCertificateNotYetValid FindingCode = "not_yet_valid_x5c"
+)
+
+// Finding is a single issue found by `jwk.Assess()`
+type Finding struct {
+	Code     FindingCode     `json:"code"`
+	Severity FindingSeverity `json:"severity"`
+	Message  string          `json:"message"`
+}
+
+// Assessment is the result of `jwk.Assess()`. It describes the properties
+// of a key that are relevant to auditing, along with a list of findings.
+type Assessment struct {
+	KeyType   jwa.KeyType                `json:"kty"`
+	KeyID     string                     `json:"kid,omitempty"`
+	Algorithm string                     `json:"alg,omitempty"`
+	Curve     jwa.EllipticCurveAlgorithm `json:"crv,omitempty"`
+	// Bits is the size of the key in bits: the modulus size for RSA keys,
+	// the curve size for EC/OKP keys, and the key length for symmetric keys
+	Bits     int       `json:"bits"`
+	Private  bool      `json:"private"`
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// HasFindings returns true if the assessment contains findings with a
+// severity equal to or higher than `min`.
+func (a *Assessment) HasFindings(min FindingSeverity) bool {
+	for _, f := range a.Findings {
+		if severityRank(f.Severity) >= severityRank(min) {
+			return true
+		}
+	}
+	return false
+}
+
+func severityRank(s FindingSeverity) int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (a *Assessment) add(code FindingCode, severity FindingSeverity, format string, args ...interface{}) {
+	a.Findings = append(a.Findings, Finding{
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// minimum key sizes, in bits
+const (
+	minRSAKeySize       = 2048
+	minSymmetricKeySize = 128
+)
+
+// Assess inspects the given key and returns a structured report of its
+// strength and hygiene. The following are checked:
+//
+//   - RSA modulus sizes below 2048 bits, and symmetric keys that are too
+//     short (for `HS*` algorithms, shorter than the hash output)
+//   - deprecated algorithms (`RSA1_5`, `none`) in the `alg` field
+//   - `alg` values that cannot be used with the key type
+//   - missing `kid`, and missing `use` / `key_ops`
+//   - certificates in `x5c` that cannot be parsed, are expired, or are not yet valid
+//
+// An error is returned only if the key itself could not be inspected.
+// Issues with the key are reported as findings in the returned `jwk.Assessment`.
+func Assess(key Key) (*Assessment, error) {
+	var a Assessment
+	a.KeyType = key.KeyType()
+	a.KeyID = key.KeyID()
+	if alg := key.Algorithm(); alg != nil {
+		a.Algorithm = alg.String()
+	}
+
+	var raw interface{}
+	if err := key.Raw(&raw); err != nil {
+		return nil, fmt.Errorf(`jwk.Assess: failed to obtain raw key: %w`, err)
+	}
+
+	switch raw := raw.(type) {
+	case *rsa.PrivateKey:
+		a.Private = true
+		a.Bits = raw.N.BitLen()
+	case *rsa.PublicKey:
+		a.Bits = raw.N.BitLen()
+	case *ecdsa.PrivateKey:
+		a.Private = true
+		a.Bits = raw.Curve.Params().BitSize
+	case *ecdsa.PublicKey:
+		a.Bits = raw.Curve.Params().BitSize
+	case []byte:
+		a.Private = true
+		a.Bits = len(raw) * 8
+	default:
+		// OKP keys: Ed25519 and X25519 are both 256 bits
+		a.Bits = 256
+		if _, ok := key.(OKPPrivateKey); ok {
+			a.Private = true
+		}
+	}
+
+	switch key := key.(type) {
+	case ECDSAPrivateKey:
+		a.Curve = key.Crv()
+	case ECDSAPublicKey:
+		a.Curve = key.Crv()
+	case OKPPrivateKey:
+		a.Curve = key.Crv()
+	case OKPPublicKey:
+		a.Curve = key.Crv()
+	}
+
+	a.assessKeySize()
+	a.assessAlgorithm()
+
+	if a.KeyID == "" {
+		a.add(FindingMissingKeyID, SeverityWarning, `key does not have a "kid"`)
+	}
+	if key.KeyUsage() == "" && len(key.KeyOps()) == 0 {
+		a.add(FindingMissingKeyUsage, SeverityInfo, `key has neither "use" nor "key_ops"`)
+	}
+
+	if chain := key.X509CertChain(); chain != nil {
+		a.assessCertificates(chain, time.Now())
+	}
+	return &a, nil
+}
+
+func (a *Assessment) assessKeySize() {
+	switch a.KeyType {
+	case jwa.RSA:
+		if a.Bits < minRSAKeySize {
+			a.add(FindingWeakKeySize, SeverityCritical, `RSA modulus is %d bits, which is less than %d bits`, a.Bits, minRSAKeySize)
+		}
+	case jwa.OctetSeq:
+		min := minSymmetricKeySize
+		if alg := jwa.SignatureAlgorithm(a.Algorithm); alg.IsSymmetric() {
+			// HMAC keys should be at least as long as the hash output
+			if h, ok := alg.HashFor(); ok {
+				min = h.Size() * 8
+			}
+		}
+		if a.Bits < min {
+			a.add(FindingWeakKeySize, SeverityCritical, `symmetric key is %d bits, which is less than %d bits`, a.Bits, min)
+		}
+	}
+}
+
+func (a *Assessment) assessAlgorithm() {
+	if a.Algorithm == "" {
+		return
+	}
+
+	switch a.Algorithm {
+	case jwa.RSA1_5.String():
+		a.add(FindingDeprecatedAlgorithm, SeverityWarning, `algorithm %q is deprecated`, a.Algorithm)
+	case jwa.NoSignature.String():
+		a.add(FindingDeprecatedAlgorithm, SeverityCritical, `algorithm %q disables signature verification`, a.Algorithm)
+		return
+	}
+
+	if kty, ok := keyTypeForAlgorithm(a.Algorithm, a.Curve); ok && kty != a.KeyType {
+		a.add(FindingAlgorithmMismatch, SeverityCritical, `algorithm %q cannot be used with key type %q`, a.Algorithm, a.KeyType)
+	}
+}
+
+// keyTypeForAlgorithm returns the key type that the algorithm requires.
+// The second return value is false if the algorithm is unknown
+func keyTypeForAlgorithm(alg string, crv jwa.EllipticCurveAlgorithm) (jwa.KeyType, bool) {
+	switch {
+	case strings.HasPrefix(alg, `RS`), strings.HasPrefix(alg, `PS`): // RS*, PS*, RSA1_5, RSA-OAEP*
+		return jwa.RSA, true
+	case strings.HasPrefix(alg, `ES`):
+		return jwa.EC, true
+	case alg == jwa.EdDSA.String():
+		return jwa.OKP, true
+	case strings.HasPrefix(alg, `ECDH-ES`):
+		// X25519 keys are OKP keys
+		if crv == jwa.X25519 {
+			return jwa.OKP, true
+		}
+		return jwa.EC, true
+	case strings.HasPrefix(alg, `HS`), strings.HasPrefix(alg, `PBES2`), strings.HasSuffix(alg, `KW`), alg == jwa.DIRECT.String():
+		return jwa.OctetSeq, true
+	default:
+		return jwa.InvalidKeyType, false
+	}
+}
+
+func (a *Assessment) assessCertificates(chain *cert.Chain, now time.Time) {
+	for i := 0; i < chain.Len(); i++ {
+		der, _ := chain.Get(i)
+		c, err := cert.Parse(der)
+		if err != nil {
+			a.add(FindingInvalidCertificate, SeverityCritical, `certificate #%d in "x5c" could not be parsed: %s`, i, err)
+			continue
+		}
+
+		if now.After(c.NotAfter) {
+			a.add(FindingExpiredCertificate, SeverityCritical, `certificate #%d in "x5c" (%s) expired at %s`, i, c.Subject, c.NotAfter.Format(time.RFC3339))
+		} else if now.Before(c.NotBefore) {
+			a.add(FindingCertificateNotYetValid, SeverityWarning, `certificate #%d in "x5c" (%s) is not valid until %s`, i, c.Subject, c.NotBefore.Format(time.RFC3339))
+		}
+	}
+}
@@ -2,9 +2,11 @@ package jwk
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/lestrrat-go/httprc"
@@ -104,6 +106,8 @@ func (f PostFetchFunc) PostFetch(u string, set Set) (Set, error) {
 
 // httprc.Transofmer that transforms the response into a JWKS
 type jwksTransform struct {
+	verifier     KeySetVerifier
+	pins         *thumbprintPins
 	postFetch    PostFetcher
 	parseOptions []ParseOption
 }
@@ -118,11 +122,25 @@ func (t *jwksTransform) Transform(u string, res *http.Response) (interface{}, er
 		return nil, fmt.Errorf(`failed to read response body status: %w`, err)
 	}
 
+	if v := t.verifier; v != nil {
+		verified, err := v.VerifyKeySet(u, buf)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to verify JWK set at %q: %w`, u, err)
+		}
+		buf = verified
+	}
+
 	set, err := Parse(buf, t.parseOptions...)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse JWK set at %q: %w`, u, err)
 	}
 
+	if pins := t.pins; pins != nil {
+		if err := pins.apply(set); err != nil {
+			return nil, fmt.Errorf(`failed to verify JWK set at %q: %w`, u, err)
+		}
+	}
+
 	if pf := t.postFetch; pf != nil {
 		v, err := pf.PostFetch(u, set)
 		if err != nil {
@@ -179,7 +197,10 @@ func NewCache(ctx context.Context, options ...CacheOption) *Cache {
 func (c *Cache) Register(u string, options ...RegisterOption) error {
 	var hrropts []httprc.RegisterOption
 	var pf PostFetcher
+	var verifier KeySetVerifier
+	var pins *thumbprintPins
 	var parseOptions []ParseOption
+	var tc transportConfig
 
 	// Note: we do NOT accept Transform option
 	for _, option := range options {
@@ -191,7 +212,13 @@ func (c *Cache) Register(u string, options ...RegisterOption) error {
 		//nolint:forcetypeassert
 		switch option.Ident() {
 		case identHTTPClient{}:
-			hrropts = append(hrropts, httprc.WithHTTPClient(option.Value().(HTTPClient)))
+			tc.client = option.Value().(HTTPClient)
+		case identTLSConfig{}:
+			tc.tlsConfig = option.Value().(*tls.Config)
+		case identProxy{}:
+			tc.proxy = option.Value().(func(*http.Request) (*url.URL, error))
+		case identLocalSources{}:
+			tc.localSources = option.Value().(bool)
 		case identRefreshInterval{}:
 			hrropts = append(hrropts, httprc.WithRefreshInterval(option.Value().(time.Duration)))
 		case identMinRefreshInterval{}:
@@ -200,15 +227,29 @@ func (c *Cache) Register(u string, options ...RegisterOption) error {
 			hrropts = append(hrropts, httprc.WithWhitelist(option.Value().(httprc.Whitelist)))
 		case identPostFetcher{}:
 			pf = option.Value().(PostFetcher)
+		case identKeySetVerifier{}:
+			verifier = option.Value().(KeySetVerifier)
+		case identPinnedThumbprints{}:
+			pins = option.Value().(*thumbprintPins)
 		}
 	}
 
+	client, err := tc.httpClient()
+	if err != nil {
+		return fmt.Errorf(`jwk.Cache.Register: %w`, err)
+	}
+	if client != nil {
+		hrropts = append(hrropts, httprc.WithHTTPClient(client))
+	}
+
 	var t *jwksTransform
-	if pf == nil && len(parseOptions) == 0 {
+	if pf == nil && verifier == nil && pins == nil && len(parseOptions) == 0 {
 		t = defaultTransform
 	} else {
-		// User-supplied PostFetcher is attached to the transformer
+		// User-supplied PostFetcher, KeySetVerifier, and pins are attached to the transformer
 		t = &jwksTransform{
+			verifier:     verifier,
+			pins:         pins,
 			postFetch:    pf,
 			parseOptions: parseOptions,
 		}
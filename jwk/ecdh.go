@@ -0,0 +1,110 @@
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/internal/ecutil"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"golang.org/x/crypto/curve25519"
+)
+
+// ECDHPrivateKey is implemented by private keys that can be used
+// to perform ECDH key agreement: `jwk.ECDSAPrivateKey` and
+// `jwk.OKPPrivateKey` (X25519 only).
+//
+//	shared, err := key.(jwk.ECDHPrivateKey).DeriveECDH(peer)
+type ECDHPrivateKey interface {
+	Key
+
+	// DeriveECDH computes the raw ECDH shared secret (Z) between this
+	// private key and the given peer public key. The peer may be
+	// specified as a `jwk.Key`, or as a raw public key
+	// (`*ecdsa.PublicKey` or `x25519.PublicKey`).
+	//
+	// The peer must use the same curve as this key. Note that the return
+	// value is the raw shared secret, and must be passed through a KDF
+	// before being used as a key.
+	DeriveECDH(peer interface{}) ([]byte, error)
+}
+
+var _ ECDHPrivateKey = &ecdsaPrivateKey{}
+var _ ECDHPrivateKey = &okpPrivateKey{}
+
+// ecdhPeerKey returns the raw public key for the given peer, which
+// may be a jwk.Key or a raw key
+func ecdhPeerKey(peer interface{}) (interface{}, error) {
+	jwkKey, ok := peer.(Key)
+	if !ok {
+		return peer, nil
+	}
+
+	pubkey, err := jwkKey.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf(`failed to obtain public key of peer: %w`, err)
+	}
+
+	var raw interface{}
+	if err := pubkey.Raw(&raw); err != nil {
+		return nil, fmt.Errorf(`failed to obtain raw public key of peer: %w`, err)
+	}
+	return raw, nil
+}
+
+func (k *ecdsaPrivateKey) DeriveECDH(peer interface{}) ([]byte, error) {
+	rawPeer, err := ecdhPeerKey(peer)
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.ECDSAPrivateKey.DeriveECDH: %w`, err)
+	}
+
+	pubkey, ok := rawPeer.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf(`jwk.ECDSAPrivateKey.DeriveECDH: peer key must be an EC public key, got %T`, rawPeer)
+	}
+
+	var privkey ecdsa.PrivateKey
+	if err := k.Raw(&privkey); err != nil {
+		return nil, fmt.Errorf(`jwk.ECDSAPrivateKey.DeriveECDH: failed to obtain raw private key: %w`, err)
+	}
+
+	if pubkey.Curve != privkey.Curve {
+		return nil, fmt.Errorf(`jwk.ECDSAPrivateKey.DeriveECDH: peer key must be on the same curve as the private key`)
+	}
+	if !privkey.Curve.IsOnCurve(pubkey.X, pubkey.Y) {
+		return nil, fmt.Errorf(`jwk.ECDSAPrivateKey.DeriveECDH: peer key does not contain a point (X,Y) on the curve`)
+	}
+
+	z, _ := privkey.Curve.ScalarMult(pubkey.X, pubkey.Y, privkey.D.Bytes())
+	zBytes := ecutil.AllocECPointBuffer(z, privkey.Curve)
+	defer ecutil.ReleaseECPointBuffer(zBytes)
+	shared := make([]byte, len(zBytes))
+	copy(shared, zBytes)
+	return shared, nil
+}
+
+func (k *okpPrivateKey) DeriveECDH(peer interface{}) ([]byte, error) {
+	rawPeer, err := ecdhPeerKey(peer)
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.OKPPrivateKey.DeriveECDH: %w`, err)
+	}
+
+	pubkey, ok := rawPeer.(x25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf(`jwk.OKPPrivateKey.DeriveECDH: peer key must be an X25519 public key, got %T`, rawPeer)
+	}
+
+	var raw interface{}
+	if err := k.Raw(&raw); err != nil {
+		return nil, fmt.Errorf(`jwk.OKPPrivateKey.DeriveECDH: failed to obtain raw private key: %w`, err)
+	}
+	privkey, ok := raw.(x25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf(`jwk.OKPPrivateKey.DeriveECDH: ECDH is only supported for X25519 keys, got %T`, raw)
+	}
+
+	shared, err := curve25519.X25519(privkey.Seed(), pubkey)
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.OKPPrivateKey.DeriveECDH: failed to compute shared secret: %w`, err)
+	}
+	return shared, nil
+}
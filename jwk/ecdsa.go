@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jwx/v2/internal/base64"
 	"github.com/lestrrat-go/jwx/v2/internal/ecutil"
 	"github.com/lestrrat-go/jwx/v2/jwa"
@@ -116,7 +115,7 @@ func (k *ecdsaPublicKey) Raw(v interface{}) error {
 		return fmt.Errorf(`failed to build public key: %w`, err)
 	}
 
-	return blackmagic.AssignIfCompatible(v, pubk)
+	return assignRawKey(v, pubk)
 }
 
 func (k *ecdsaPrivateKey) Raw(v interface{}) error {
@@ -134,7 +133,7 @@ func (k *ecdsaPrivateKey) Raw(v interface{}) error {
 	key.D = &d
 	key.PublicKey = *pubk
 
-	return blackmagic.AssignIfCompatible(v, &key)
+	return assignRawKey(v, &key)
 }
 
 func makeECDSAPublicKey(v interface {
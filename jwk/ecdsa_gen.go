@@ -445,6 +445,9 @@ LOOP:
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AlgorithmKey, err)
 				}
 				alg := jwa.KeyAlgorithmFrom(s)
+				if _, ok := alg.(jwa.InvalidKeyAlgorithm); ok && jwa.IsStrict() {
+					return fmt.Errorf(`invalid value for key %s: unknown algorithm %q`, AlgorithmKey, s)
+				}
 				h.algorithm = &alg
 			case ECDSACrvKey:
 				var decoded jwa.EllipticCurveAlgorithm
@@ -1030,6 +1033,9 @@ LOOP:
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AlgorithmKey, err)
 				}
 				alg := jwa.KeyAlgorithmFrom(s)
+				if _, ok := alg.(jwa.InvalidKeyAlgorithm); ok && jwa.IsStrict() {
+					return fmt.Errorf(`invalid value for key %s: unknown algorithm %q`, AlgorithmKey, s)
+				}
 				h.algorithm = &alg
 			case ECDSACrvKey:
 				var decoded jwa.EllipticCurveAlgorithm
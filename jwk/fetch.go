@@ -2,12 +2,18 @@ package jwk
 
 import (
 	"context"
+	"crypto"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 
 	"github.com/lestrrat-go/httprc"
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/observe"
 )
 
 type Fetcher interface {
@@ -20,6 +26,23 @@ func (f FetchFunc) Fetch(ctx context.Context, u string, options ...FetchOption)
 	return f(ctx, u, options...)
 }
 
+// KeySetVerifier is an interface for objects that verify the raw payload
+// of a JWKS fetched from a remote resource, before it is parsed.
+//
+// The verifier receives the URL and the raw payload, and should return
+// the raw JSON representation of the JWKS. For example, a verifier for
+// JWKS published as a JWS would verify the signature and return the payload.
+type KeySetVerifier interface {
+	VerifyKeySet(string, []byte) ([]byte, error)
+}
+
+// KeySetVerifierFunc is a KeySetVerifier based on a function.
+type KeySetVerifierFunc func(string, []byte) ([]byte, error)
+
+func (f KeySetVerifierFunc) VerifyKeySet(u string, payload []byte) ([]byte, error) {
+	return f(u, payload)
+}
+
 var globalFetcher httprc.Fetcher
 
 func init() {
@@ -38,14 +61,24 @@ func init() {
 // Fetch fetches a JWK resource specified by a URL. The url must be
 // pointing to a resource that is supported by `net/http`.
 //
+// If `jwk.WithLocalSources(true)` is specified, `file://` and `data:`
+// URLs are also accepted. See `jwk.WithLocalSources` for details.
+//
 // If you are using the same `jwk.Set` for long periods of time during
 // the lifecycle of your program, and would like to periodically refresh the
 // contents of the object with the data at the remote resource,
 // consider using `jwk.Cache`, which automatically refreshes
 // jwk.Set objects asynchronously.
-func Fetch(ctx context.Context, u string, options ...FetchOption) (Set, error) {
+func Fetch(ctx context.Context, u string, options ...FetchOption) (_ Set, err error) {
+	ctx, span := observe.Start(ctx, observe.JWKFetch)
+	defer func() { span.End(err) }()
+	span.SetAttribute(observe.AttrURL, observedURL(u))
+
 	var hrfopts []httprc.FetchOption
 	var parseOptions []ParseOption
+	var tc transportConfig
+	var verifier KeySetVerifier
+	var pins *thumbprintPins
 	for _, option := range options {
 		if parseOpt, ok := option.(ParseOption); ok {
 			parseOptions = append(parseOptions, parseOpt)
@@ -55,12 +88,33 @@ func Fetch(ctx context.Context, u string, options ...FetchOption) (Set, error) {
 		//nolint:forcetypeassert
 		switch option.Ident() {
 		case identHTTPClient{}:
-			hrfopts = append(hrfopts, httprc.WithHTTPClient(option.Value().(HTTPClient)))
+			tc.client = option.Value().(HTTPClient)
+		case identTLSConfig{}:
+			tc.tlsConfig = option.Value().(*tls.Config)
+		case identProxy{}:
+			tc.proxy = option.Value().(func(*http.Request) (*url.URL, error))
+		case identLocalSources{}:
+			tc.localSources = option.Value().(bool)
 		case identFetchWhitelist{}:
 			hrfopts = append(hrfopts, httprc.WithWhitelist(option.Value().(httprc.Whitelist)))
+		case identKeySetVerifier{}:
+			verifier = option.Value().(KeySetVerifier)
+		case identPinnedThumbprints{}:
+			pins = option.Value().(*thumbprintPins)
 		}
 	}
 
+	client, err := tc.httpClient()
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.Fetch: %w`, err)
+	}
+	// The transport created for this call is not reused, so make sure
+	// that its idle connections do not linger around
+	defer tc.closeIdleConnections()
+	if client != nil {
+		hrfopts = append(hrfopts, httprc.WithHTTPClient(client))
+	}
+
 	res, err := globalFetcher.Fetch(ctx, u, hrfopts...)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to fetch %q: %w`, u, err)
@@ -72,5 +126,123 @@ func Fetch(ctx context.Context, u string, options ...FetchOption) (Set, error) {
 		return nil, fmt.Errorf(`failed to read response body for %q: %w`, u, err)
 	}
 
-	return Parse(buf, parseOptions...)
+	if verifier != nil {
+		verified, err := verifier.VerifyKeySet(u, buf)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to verify JWK set at %q: %w`, u, err)
+		}
+		buf = verified
+	}
+
+	set, err := Parse(buf, parseOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	if pins != nil {
+		if err := pins.apply(set); err != nil {
+			return nil, fmt.Errorf(`failed to verify JWK set at %q: %w`, u, err)
+		}
+	}
+	return set, nil
+}
+
+// observedURL returns the representation of `u` that is reported to
+// observers, without credentials or the content of `data:` URLs
+func observedURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	if parsed.Scheme == `data` {
+		return `data:`
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
+// thumbprintPins holds the thumbprints specified by `jwk.WithPinnedThumbprints()`
+type thumbprintPins struct {
+	hash        crypto.Hash
+	thumbprints map[string]struct{}
+}
+
+// apply removes the keys that do not match any of the pins from `set`
+func (p *thumbprintPins) apply(set Set) error {
+	var unpinned []Key
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Key(i)
+		tp, err := key.Thumbprint(p.hash)
+		if err != nil {
+			return fmt.Errorf(`failed to compute thumbprint of key #%d: %w`, i, err)
+		}
+		if _, ok := p.thumbprints[base64.EncodeToString(tp)]; !ok {
+			unpinned = append(unpinned, key)
+		}
+	}
+
+	if len(unpinned) == set.Len() {
+		return fmt.Errorf(`none of the keys match the pinned thumbprints`)
+	}
+
+	for _, key := range unpinned {
+		if err := set.RemoveKey(key); err != nil {
+			return fmt.Errorf(`failed to remove key that does not match the pinned thumbprints: %w`, err)
+		}
+	}
+	return nil
+}
+
+// transportConfig holds the options that control how the HTTP client
+// used for fetching JWKS is constructed
+type transportConfig struct {
+	client       HTTPClient
+	tlsConfig    *tls.Config
+	proxy        func(*http.Request) (*url.URL, error)
+	localSources bool
+
+	// transport is the transport created by httpClient(), if any
+	transport *http.Transport
+}
+
+// httpClient returns the HTTPClient to be used for fetching. If no options
+// that require a custom client were specified, the user-supplied client
+// (which may be nil) is returned as is.
+func (tc *transportConfig) httpClient() (HTTPClient, error) {
+	client := tc.client
+	if tc.tlsConfig != nil || tc.proxy != nil {
+		if client != nil {
+			return nil, fmt.Errorf(`jwk.WithHTTPClient() cannot be used together with jwk.WithTLSConfig() or jwk.WithProxy()`)
+		}
+
+		var tr *http.Transport
+		if dt, ok := http.DefaultTransport.(*http.Transport); ok {
+			tr = dt.Clone()
+		} else {
+			tr = &http.Transport{}
+		}
+
+		if tc.tlsConfig != nil {
+			tr.TLSClientConfig = tc.tlsConfig
+		}
+		if tc.proxy != nil {
+			tr.Proxy = tc.proxy
+		}
+		tc.transport = tr
+		client = &http.Client{Transport: tr}
+	}
+
+	if tc.localSources {
+		if client == nil {
+			client = http.DefaultClient
+		}
+		client = &localSourceClient{next: client}
+	}
+	return client, nil
+}
+
+func (tc *transportConfig) closeIdleConnections() {
+	if tr := tc.transport; tr != nil {
+		tr.CloseIdleConnections()
+	}
 }
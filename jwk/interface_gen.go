@@ -58,6 +58,10 @@ type Key interface {
 	// If you already know the exact type, it is recommended that you
 	// pass a pointer to the zero value of the actual key type (e.g. &rsa.PrivateKey)
 	// for efficiency.
+	//
+	// Private keys may also be materialized into their public counterparts
+	// (e.g. a private EC key into *ecdsa.PublicKey). If the argument cannot
+	// hold the key, an error describing the mismatch is returned.
 	Raw(interface{}) error
 
 	// Thumbprint returns the JWK thumbprint using the indicated
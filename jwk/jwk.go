@@ -15,7 +15,9 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"reflect"
 
+	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jwx/v2/internal/base64"
 	"github.com/lestrrat-go/jwx/v2/internal/ecutil"
 	"github.com/lestrrat-go/jwx/v2/internal/json"
@@ -233,6 +235,49 @@ func PublicRawKeyOf(v interface{}) (interface{}, error) {
 	}
 }
 
+// assignRawKey assigns the raw key `raw` to `dst`, which is the argument
+// passed to `Raw()`. If `dst` cannot hold the raw key but can hold its public
+// counterpart (e.g. a private key is materialized into a `*ecdsa.PublicKey`),
+// the public key is assigned instead.
+func assignRawKey(dst interface{}, raw interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`argument to Raw() must be a non-nil pointer (got %T)`, dst)
+	}
+
+	target := rv.Elem().Type()
+	if rawKeyAssignable(raw, target) {
+		return assignRawKeyValue(rv, raw)
+	}
+
+	if pub, err := PublicRawKeyOf(raw); err == nil && rawKeyAssignable(pub, target) {
+		return assignRawKeyValue(rv, pub)
+	}
+	return fmt.Errorf(`cannot materialize key of type %T into %T`, raw, dst)
+}
+
+// assignRawKeyValue assigns `raw` to the variable pointed to by `rv`.
+// blackmagic.AssignIfCompatible always dereferences pointers unless the
+// destination is an interface, so pointer-to-pointer destinations
+// (e.g. **rsa.PrivateKey) are handled here
+func assignRawKeyValue(rv reflect.Value, raw interface{}) error {
+	if v := reflect.ValueOf(raw); v.Type().AssignableTo(rv.Elem().Type()) {
+		rv.Elem().Set(v)
+		return nil
+	}
+	return blackmagic.AssignIfCompatible(rv.Interface(), raw)
+}
+
+// rawKeyAssignable returns true if `v` (or the value it points to)
+// can be assigned to a variable of type `target`
+func rawKeyAssignable(v interface{}, target reflect.Type) bool {
+	t := reflect.TypeOf(v)
+	if t.AssignableTo(target) {
+		return true
+	}
+	return t.Kind() == reflect.Ptr && t.Elem().AssignableTo(target)
+}
+
 const (
 	pmPrivateKey    = `PRIVATE KEY`
 	pmPublicKey     = `PUBLIC KEY`
@@ -381,12 +426,17 @@ func ParseRawKey(data []byte, rawkey interface{}) error {
 type setDecodeCtx struct {
 	json.DecodeCtx
 	ignoreParseError bool
+	keyIDFilter      map[string]struct{}
 }
 
 func (ctx *setDecodeCtx) IgnoreParseError() bool {
 	return ctx.ignoreParseError
 }
 
+func (ctx *setDecodeCtx) KeyIDFilter() map[string]struct{} {
+	return ctx.keyIDFilter
+}
+
 // ParseKey parses a single key JWK. Unlike `jwk.Parse` this method will
 // report failure if you attempt to pass a JWK set. Only use this function
 // when you know that the data is a single JWK.
@@ -500,6 +550,7 @@ func Parse(src []byte, options ...ParseOption) (Set, error) {
 	var parsePEM bool
 	var localReg *json.Registry
 	var ignoreParseError bool
+	var filter map[string]struct{}
 	for _, option := range options {
 		//nolint:forcetypeassert
 		switch option.Ident() {
@@ -507,6 +558,8 @@ func Parse(src []byte, options ...ParseOption) (Set, error) {
 			parsePEM = option.Value().(bool)
 		case identIgnoreParseError{}:
 			ignoreParseError = option.Value().(bool)
+		case identKeyIDFilter{}:
+			filter = option.Value().(map[string]struct{})
 		case identTypedField{}:
 			pair := option.Value().(typedFieldPair)
 			if localReg == nil {
@@ -525,19 +578,22 @@ func Parse(src []byte, options ...ParseOption) (Set, error) {
 			if err != nil {
 				return nil, fmt.Errorf(`failed to parse PEM encoded key: %w`, err)
 			}
+			src = bytes.TrimSpace(rest)
 			key, err := FromRaw(raw)
 			if err != nil {
 				return nil, fmt.Errorf(`failed to create jwk.Key from %T: %w`, raw, err)
 			}
+			if !keyIDAllowed(filter, key.KeyID()) {
+				continue
+			}
 			if err := s.AddKey(key); err != nil {
 				return nil, fmt.Errorf(`failed to add jwk.Key to set: %w`, err)
 			}
-			src = bytes.TrimSpace(rest)
 		}
 		return s, nil
 	}
 
-	if localReg != nil || ignoreParseError {
+	if localReg != nil || ignoreParseError || filter != nil {
 		dcKs, ok := s.(KeyWithDecodeCtx)
 		if !ok {
 			return nil, fmt.Errorf(`typed field was requested, but the key set (%T) does not support DecodeCtx`, s)
@@ -545,6 +601,7 @@ func Parse(src []byte, options ...ParseOption) (Set, error) {
 		dc := &setDecodeCtx{
 			DecodeCtx:        json.NewDecodeCtx(localReg),
 			ignoreParseError: ignoreParseError,
+			keyIDFilter:      filter,
 		}
 		dcKs.SetDecodeCtx(dc)
 		defer func() { dcKs.SetDecodeCtx(nil) }()
@@ -569,6 +626,92 @@ func ParseReader(src io.Reader, options ...ParseOption) (Set, error) {
 	return Parse(buf, options...)
 }
 
+// ParseStream parses a JWK Set from the incoming io.Reader without
+// reading the entire input into memory first. The elements of the
+// "keys" array are decoded one at a time, which keeps the peak memory
+// usage low when parsing JWK Sets containing a large number of keys.
+// Combine it with `jwk.WithKeyIDFilter()` to only keep the keys that
+// you are interested in: keys that are filtered out are not fully parsed.
+//
+// Unlike `jwk.ParseReader()`, the input must be a JWK Set (i.e. a JSON
+// object with a "keys" field). `jwk.WithPEM()` is not supported.
+func ParseStream(src io.Reader, options ...ParseOption) (Set, error) {
+	var keyOptions []ParseOption
+	var ignoreParseError bool
+	var filter map[string]struct{}
+	var localReg *json.Registry
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identPEM{}:
+			if option.Value().(bool) {
+				return nil, fmt.Errorf(`jwk.ParseStream: jwk.WithPEM is not supported`)
+			}
+		case identIgnoreParseError{}:
+			ignoreParseError = option.Value().(bool)
+		case identKeyIDFilter{}:
+			filter = option.Value().(map[string]struct{})
+		case identTypedField{}:
+			pair := option.Value().(typedFieldPair)
+			if localReg == nil {
+				localReg = json.NewRegistry()
+			}
+			localReg.Register(pair.Name, pair.Value)
+		}
+	}
+	if localReg != nil {
+		keyOptions = append(keyOptions, withLocalRegistry(localReg))
+	}
+
+	dec := json.NewDecoder(src)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.ParseStream: error reading token: %w`, err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf(`jwk.ParseStream: expected '{', but got %v`, tok)
+	}
+
+	s := &set{privateParams: make(map[string]interface{})}
+	var sawKeysField bool
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf(`jwk.ParseStream: error reading token: %w`, err)
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf(`jwk.ParseStream: expected field name, but got %v`, tok)
+		}
+
+		if name == keysKey {
+			sawKeysField = true
+			keys, err := decodeKeys(dec, keyOptions, ignoreParseError, filter)
+			if err != nil {
+				return nil, fmt.Errorf(`jwk.ParseStream: %w`, err)
+			}
+			s.keys = keys
+			continue
+		}
+
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf(`jwk.ParseStream: failed to decode value for key %q: %w`, name, err)
+		}
+		s.privateParams[name] = v
+	}
+
+	// consume the closing '}'
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf(`jwk.ParseStream: error reading token: %w`, err)
+	}
+
+	if !sawKeysField {
+		return nil, fmt.Errorf(`jwk.ParseStream: input does not contain a "keys" field (use jwk.ParseKey to parse a single key)`)
+	}
+	return s, nil
+}
+
 // ParseString parses a JWK set from the incoming string.
 func ParseString(s string, options ...ParseOption) (Set, error) {
 	return Parse([]byte(s), options...)
@@ -6,12 +6,20 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -1939,6 +1947,156 @@ func TestFetch(t *testing.T) {
 	}
 }
 
+func TestFetchTransportOptions(t *testing.T) {
+	key, err := jwxtest.GenerateRsaPublicJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(key), `set.AddKey should succeed`)
+	expected, err := json.Marshal(set)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	// Self-signed client certificate for mTLS
+	clientKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: `jwx-test-client`},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &clientKey.PublicKey, clientKey)
+	require.NoError(t, err, `x509.CreateCertificate should succeed`)
+	clientCert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, `x509.ParseCertificate should succeed`)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(expected)
+	}))
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	mtls := &tls.Config{
+		RootCAs: pool,
+		Certificates: []tls.Certificate{
+			{Certificate: [][]byte{der}, PrivateKey: clientKey},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("WithTLSConfig", func(t *testing.T) {
+		_, err := jwk.Fetch(ctx, srv.URL)
+		require.Error(t, err, `jwk.Fetch without trusting the server certificate should fail`)
+
+		_, err = jwk.Fetch(ctx, srv.URL, jwk.WithTLSConfig(&tls.Config{RootCAs: pool}))
+		require.Error(t, err, `jwk.Fetch without a client certificate should fail`)
+
+		fetched, err := jwk.Fetch(ctx, srv.URL, jwk.WithTLSConfig(mtls))
+		require.NoError(t, err, `jwk.Fetch should succeed`)
+		require.Equal(t, 1, fetched.Len(), `there should be 1 key`)
+	})
+	t.Run("WithProxy", func(t *testing.T) {
+		var called bool
+		proxy := func(*http.Request) (*url.URL, error) {
+			called = true
+			return nil, nil // direct connection
+		}
+		_, err := jwk.Fetch(ctx, srv.URL, jwk.WithTLSConfig(mtls), jwk.WithProxy(proxy))
+		require.NoError(t, err, `jwk.Fetch should succeed`)
+		require.True(t, called, `proxy function should have been called`)
+	})
+	t.Run("WithHTTPClient conflict", func(t *testing.T) {
+		_, err := jwk.Fetch(ctx, srv.URL, jwk.WithTLSConfig(mtls), jwk.WithHTTPClient(srv.Client()))
+		require.Error(t, err, `jwk.Fetch should fail`)
+
+		c := jwk.NewCache(ctx)
+		require.Error(t, c.Register(srv.URL, jwk.WithTLSConfig(mtls), jwk.WithHTTPClient(srv.Client())), `c.Register should fail`)
+	})
+	t.Run("Cache", func(t *testing.T) {
+		c := jwk.NewCache(ctx)
+		require.NoError(t, c.Register(srv.URL, jwk.WithTLSConfig(&tls.Config{RootCAs: pool})), `c.Register should succeed`)
+		_, err := c.Refresh(ctx, srv.URL)
+		require.Error(t, err, `c.Refresh without a client certificate should fail`)
+
+		c = jwk.NewCache(ctx)
+		require.NoError(t, c.Register(srv.URL, jwk.WithTLSConfig(mtls)), `c.Register should succeed`)
+		cached, err := c.Refresh(ctx, srv.URL)
+		require.NoError(t, err, `c.Refresh should succeed`)
+		require.Equal(t, 1, cached.Len(), `there should be 1 key`)
+	})
+}
+
+func TestFetchLocalSources(t *testing.T) {
+	key, err := jwxtest.GenerateRsaPublicJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(key), `set.AddKey should succeed`)
+	buf, err := json.Marshal(set)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	filename := filepath.Join(t.TempDir(), `jwks.json`)
+	require.NoError(t, os.WriteFile(filename, buf, 0600), `os.WriteFile should succeed`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testcases := []struct {
+		Name string
+		URL  string
+	}{
+		{Name: `file`, URL: `file://` + path.Join(`/`, filepath.ToSlash(filename))},
+		{Name: `data (base64)`, URL: jwk.DataURL(buf)},
+		{Name: `data (percent-encoded)`, URL: `data:application/json,` + url.PathEscape(string(buf))},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := jwk.Fetch(ctx, tc.URL)
+			require.Error(t, err, `jwk.Fetch without jwk.WithLocalSources should fail`)
+
+			fetched, err := jwk.Fetch(ctx, tc.URL, jwk.WithLocalSources(true))
+			require.NoError(t, err, `jwk.Fetch should succeed`)
+			require.Equal(t, 1, fetched.Len(), `there should be 1 key`)
+
+			c := jwk.NewCache(ctx)
+			require.NoError(t, c.Register(tc.URL, jwk.WithLocalSources(true)), `c.Register should succeed`)
+			cached, err := c.Refresh(ctx, tc.URL)
+			require.NoError(t, err, `c.Refresh should succeed`)
+			require.Equal(t, 1, cached.Len(), `there should be 1 key`)
+		})
+	}
+
+	invalid := []struct {
+		Name string
+		URL  string
+	}{
+		{Name: `relative file (opaque)`, URL: `file:jwks.json`},
+		{Name: `relative file (host)`, URL: `file://jwks.json`},
+		{Name: `file with non-local host`, URL: `file://example.com/jwks.json`},
+		{Name: `data with unescaped '?'`, URL: `data:application/json,{"keys":[{"kid":"a?b"}]}`},
+		{Name: `data with unescaped '#'`, URL: `data:application/json,{"keys":[{"kid":"a#b"}]}`},
+	}
+	for _, tc := range invalid {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := jwk.Fetch(ctx, tc.URL, jwk.WithLocalSources(true))
+			require.Error(t, err, `jwk.Fetch should fail`)
+		})
+	}
+}
+
 func TestGH567(t *testing.T) {
 	const src = `{
   "keys": [
@@ -2072,6 +2230,11 @@ func TestGH664(t *testing.T) {
 			privkey.Precomputed.CRTValues = nil
 
 			jwkPrivkey, err := jwk.FromRaw(privkey)
+			if i == 1 {
+				// a single prime is not a valid RSA private key
+				assert.ErrorContains(t, err, `invalid number of primes`, `jwk.FromRaw should fail`)
+				return
+			}
 			if !assert.NoError(t, err, `jwk.FromRaw should succeed`) {
 				return
 			}
@@ -2130,3 +2293,380 @@ func TestECDSAPEM(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCacheProvider(t *testing.T) {
+	key, err := jwxtest.GenerateRsaPublicJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(key), `set.AddKey should succeed`)
+	buf, err := json.Marshal(set)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := jwk.NewCache(ctx)
+
+	p := jwk.NewCacheProvider(c, srv.URL)
+	_, err = p.FetchKeys(ctx, jwk.ProviderHints{})
+	require.Error(t, err, `p.FetchKeys should fail for unregistered URL`)
+
+	require.NoError(t, c.Register(srv.URL), `c.Register should succeed`)
+	fetched, err := p.FetchKeys(ctx, jwk.ProviderHints{KeyID: `my-key`})
+	require.NoError(t, err, `p.FetchKeys should succeed`)
+	_, ok := fetched.LookupKeyID(`my-key`)
+	require.True(t, ok, `fetched set should contain "my-key"`)
+
+	_, err = jwk.NewCacheProvider(nil, srv.URL).FetchKeys(ctx, jwk.ProviderHints{})
+	require.Error(t, err, `p.FetchKeys should fail without a cache`)
+}
+
+func TestDeriveECDH(t *testing.T) {
+	t.Run("EC", func(t *testing.T) {
+		alice, err := jwxtest.GenerateEcdsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+		bob, err := jwxtest.GenerateEcdsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+		bobPub, err := bob.PublicKey()
+		require.NoError(t, err, `bob.PublicKey should succeed`)
+
+		z1, err := alice.(jwk.ECDHPrivateKey).DeriveECDH(bobPub)
+		require.NoError(t, err, `alice.DeriveECDH should succeed`)
+
+		var rawAlice ecdsa.PrivateKey
+		require.NoError(t, alice.Raw(&rawAlice), `alice.Raw should succeed`)
+		z2, err := bob.(jwk.ECDHPrivateKey).DeriveECDH(&rawAlice.PublicKey)
+		require.NoError(t, err, `bob.DeriveECDH should succeed`)
+		require.Equal(t, z1, z2, `shared secrets should match`)
+
+		other, err := jwxtest.GenerateEcdsaKey(jwa.P384)
+		require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+		_, err = alice.(jwk.ECDHPrivateKey).DeriveECDH(&other.PublicKey)
+		require.Error(t, err, `DeriveECDH with a different curve should fail`)
+
+		x, err := jwxtest.GenerateX25519Jwk()
+		require.NoError(t, err, `jwxtest.GenerateX25519Jwk should succeed`)
+		_, err = alice.(jwk.ECDHPrivateKey).DeriveECDH(x)
+		require.Error(t, err, `DeriveECDH with a X25519 key should fail`)
+	})
+	t.Run("X25519", func(t *testing.T) {
+		alice, err := jwxtest.GenerateX25519Jwk()
+		require.NoError(t, err, `jwxtest.GenerateX25519Jwk should succeed`)
+		bob, err := jwxtest.GenerateX25519Jwk()
+		require.NoError(t, err, `jwxtest.GenerateX25519Jwk should succeed`)
+
+		z1, err := alice.(jwk.ECDHPrivateKey).DeriveECDH(bob)
+		require.NoError(t, err, `alice.DeriveECDH should succeed`)
+		z2, err := bob.(jwk.ECDHPrivateKey).DeriveECDH(alice)
+		require.NoError(t, err, `bob.DeriveECDH should succeed`)
+		require.Equal(t, z1, z2, `shared secrets should match`)
+
+		ed, err := jwxtest.GenerateEd25519Jwk()
+		require.NoError(t, err, `jwxtest.GenerateEd25519Jwk should succeed`)
+		_, err = ed.(jwk.ECDHPrivateKey).DeriveECDH(bob)
+		require.Error(t, err, `DeriveECDH with an Ed25519 key should fail`)
+	})
+}
+
+func TestRawMaterialization(t *testing.T) {
+	rsaKey, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	ecKey, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	edKey, err := jwxtest.GenerateEd25519Jwk()
+	require.NoError(t, err, `jwxtest.GenerateEd25519Jwk should succeed`)
+	octKey, err := jwxtest.GenerateSymmetricJwk()
+	require.NoError(t, err, `jwxtest.GenerateSymmetricJwk should succeed`)
+
+	t.Run("matching types", func(t *testing.T) {
+		var rsaPriv *rsa.PrivateKey
+		require.NoError(t, rsaKey.Raw(&rsaPriv), `rsaKey.Raw should succeed`)
+		require.NotNil(t, rsaPriv)
+
+		var ecPriv ecdsa.PrivateKey
+		require.NoError(t, ecKey.Raw(&ecPriv), `ecKey.Raw should succeed`)
+
+		var edPriv ed25519.PrivateKey
+		require.NoError(t, edKey.Raw(&edPriv), `edKey.Raw should succeed`)
+
+		var octets []byte
+		require.NoError(t, octKey.Raw(&octets), `octKey.Raw should succeed`)
+		require.NotEmpty(t, octets)
+	})
+	t.Run("public counterparts of private keys", func(t *testing.T) {
+		var rsaPub *rsa.PublicKey
+		require.NoError(t, rsaKey.Raw(&rsaPub), `rsaKey.Raw should succeed`)
+		require.NotNil(t, rsaPub)
+
+		var ecPub *ecdsa.PublicKey
+		require.NoError(t, ecKey.Raw(&ecPub), `ecKey.Raw should succeed`)
+		var ecPriv ecdsa.PrivateKey
+		require.NoError(t, ecKey.Raw(&ecPriv), `ecKey.Raw should succeed`)
+		require.True(t, ecPub.Equal(&ecPriv.PublicKey), `public keys should match`)
+
+		var edPub ed25519.PublicKey
+		require.NoError(t, edKey.Raw(&edPub), `edKey.Raw should succeed`)
+		require.Len(t, edPub, ed25519.PublicKeySize)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		var ecPriv *ecdsa.PrivateKey
+		err := rsaKey.Raw(&ecPriv)
+		require.Error(t, err, `rsaKey.Raw into *ecdsa.PrivateKey should fail`)
+		require.Contains(t, err.Error(), `*rsa.PrivateKey`, `error should mention the key type`)
+
+		pub, err := ecKey.PublicKey()
+		require.NoError(t, err, `ecKey.PublicKey should succeed`)
+		require.Error(t, pub.Raw(&ecPriv), `public key should not materialize into a private key`)
+
+		var octets []byte
+		require.Error(t, ecKey.Raw(octets), `non-pointer argument should fail`)
+	})
+}
+
+func TestRSAMultiPrime(t *testing.T) {
+	//nolint:staticcheck
+	raw, err := rsa.GenerateMultiPrimeKey(rand.Reader, 3, 2048)
+	require.NoError(t, err, `rsa.GenerateMultiPrimeKey should succeed`)
+
+	key, err := jwk.FromRaw(raw)
+	require.NoError(t, err, `jwk.FromRaw should succeed`)
+
+	rsaKey, ok := key.(jwk.RSAPrivateKey)
+	require.True(t, ok, `key should be a jwk.RSAPrivateKey`)
+	require.Len(t, rsaKey.OtherPrimes(), 1, `there should be 1 other prime`)
+
+	buf, err := json.Marshal(key)
+	require.NoError(t, err, `json.Marshal should succeed`)
+	require.Contains(t, string(buf), `"oth":[{"d":"`, `JSON should contain "oth"`)
+
+	parsed, err := jwk.ParseKey(buf)
+	require.NoError(t, err, `jwk.ParseKey should succeed`)
+
+	var materialized rsa.PrivateKey
+	require.NoError(t, parsed.Raw(&materialized), `parsed.Raw should succeed`)
+	require.NoError(t, materialized.Validate(), `materialized.Validate should succeed`)
+	require.Len(t, materialized.Primes, 3, `there should be 3 primes`)
+	for i, p := range raw.Primes {
+		require.Equal(t, 0, p.Cmp(materialized.Primes[i]), `prime #%d should match`, i)
+	}
+	require.Len(t, materialized.Precomputed.CRTValues, 1, `there should be 1 CRT value`)
+	require.Equal(t, 0, raw.Precomputed.CRTValues[0].Exp.Cmp(materialized.Precomputed.CRTValues[0].Exp), `CRT exponent should match`)
+	require.Equal(t, 0, raw.Precomputed.CRTValues[0].Coeff.Cmp(materialized.Precomputed.CRTValues[0].Coeff), `CRT coefficient should match`)
+	require.Equal(t, 0, raw.Precomputed.CRTValues[0].R.Cmp(materialized.Precomputed.CRTValues[0].R), `CRT R should match`)
+
+	pubkey, err := parsed.PublicKey()
+	require.NoError(t, err, `parsed.PublicKey should succeed`)
+	_, ok = pubkey.Get(jwk.RSAOthKey)
+	require.False(t, ok, `public key should not contain "oth"`)
+
+	cloned, err := parsed.Clone()
+	require.NoError(t, err, `parsed.Clone should succeed`)
+	require.Len(t, cloned.(jwk.RSAPrivateKey).OtherPrimes(), 1, `cloned key should contain "oth"`)
+}
+
+func TestAssess(t *testing.T) {
+	codes := func(a *jwk.Assessment) []jwk.FindingCode {
+		var list []jwk.FindingCode
+		for _, f := range a.Findings {
+			list = append(list, f.Code)
+		}
+		return list
+	}
+
+	t.Run("healthy RSA key", func(t *testing.T) {
+		key, err := jwxtest.GenerateRsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+		require.NoError(t, key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+		require.NoError(t, key.Set(jwk.KeyUsageKey, jwk.ForSignature), `key.Set should succeed`)
+		require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+
+		a, err := jwk.Assess(key)
+		require.NoError(t, err, `jwk.Assess should succeed`)
+		require.Equal(t, jwa.RSA, a.KeyType)
+		require.Equal(t, 2048, a.Bits)
+		require.True(t, a.Private)
+		require.Empty(t, a.Findings, `there should be no findings`)
+	})
+	t.Run("weak RSA key with deprecated algorithm", func(t *testing.T) {
+		raw, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err, `rsa.GenerateKey should succeed`)
+		key, err := jwk.FromRaw(raw)
+		require.NoError(t, err, `jwk.FromRaw should succeed`)
+		require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RSA1_5), `key.Set should succeed`)
+
+		a, err := jwk.Assess(key)
+		require.NoError(t, err, `jwk.Assess should succeed`)
+		require.Equal(t, 1024, a.Bits)
+		require.Equal(t, []jwk.FindingCode{jwk.FindingWeakKeySize, jwk.FindingDeprecatedAlgorithm, jwk.FindingMissingKeyID, jwk.FindingMissingKeyUsage}, codes(a))
+		require.True(t, a.HasFindings(jwk.SeverityCritical))
+	})
+	t.Run("algorithm mismatch", func(t *testing.T) {
+		key, err := jwxtest.GenerateEcdsaPublicJwk()
+		require.NoError(t, err, `jwxtest.GenerateEcdsaPublicJwk should succeed`)
+		require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+
+		a, err := jwk.Assess(key)
+		require.NoError(t, err, `jwk.Assess should succeed`)
+		require.Equal(t, jwa.P521, a.Curve)
+		require.False(t, a.Private)
+		require.Contains(t, codes(a), jwk.FindingAlgorithmMismatch)
+	})
+	t.Run("short HMAC key", func(t *testing.T) {
+		key, err := jwk.FromRaw([]byte(`0123456789abcdef0123456789abcdef`))
+		require.NoError(t, err, `jwk.FromRaw should succeed`)
+		require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.HS512), `key.Set should succeed`)
+
+		a, err := jwk.Assess(key)
+		require.NoError(t, err, `jwk.Assess should succeed`)
+		require.Equal(t, 256, a.Bits)
+		require.Contains(t, codes(a), jwk.FindingWeakKeySize)
+	})
+	t.Run("expired x5c", func(t *testing.T) {
+		raw, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+		require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: `expired`},
+			NotBefore:    time.Now().Add(-48 * time.Hour),
+			NotAfter:     time.Now().Add(-24 * time.Hour),
+		}
+		encoded, err := cert.Create(rand.Reader, template, template, &raw.PublicKey, raw)
+		require.NoError(t, err, `cert.Create should succeed`)
+
+		key, err := jwk.FromRaw(&raw.PublicKey)
+		require.NoError(t, err, `jwk.FromRaw should succeed`)
+		var chain cert.Chain
+		require.NoError(t, chain.Add(encoded), `chain.Add should succeed`)
+		require.NoError(t, key.Set(jwk.X509CertChainKey, &chain), `key.Set should succeed`)
+
+		a, err := jwk.Assess(key)
+		require.NoError(t, err, `jwk.Assess should succeed`)
+		require.Contains(t, codes(a), jwk.FindingExpiredCertificate)
+	})
+}
+
+func TestParsePKCS12(t *testing.T) {
+	t.Parallel()
+
+	// signer.p12 contains an RSA key, its certificate ("jwx test signer"),
+	// and the certificate of its issuer ("jwx test CA"), protected by
+	// the password "jwx-test"
+	data, err := os.ReadFile(filepath.Join(`testdata`, `signer.p12`))
+	require.NoError(t, err, `os.ReadFile should succeed`)
+
+	t.Run("valid password", func(t *testing.T) {
+		t.Parallel()
+		key, err := jwk.ParsePKCS12(data, `jwx-test`)
+		require.NoError(t, err, `jwk.ParsePKCS12 should succeed`)
+		require.Implements(t, (*jwk.RSAPrivateKey)(nil), key)
+
+		chain := key.X509CertChain()
+		require.NotNil(t, chain, `key should have "x5c"`)
+		require.Equal(t, 2, chain.Len(), `chain should contain leaf and CA`)
+
+		var rawkey rsa.PrivateKey
+		require.NoError(t, key.Raw(&rawkey), `key.Raw should succeed`)
+
+		var subjects []string
+		for i := 0; i < chain.Len(); i++ {
+			der, _ := chain.Get(i)
+			c, err := cert.Parse(der)
+			require.NoError(t, err, `cert.Parse should succeed`)
+			if i == 0 {
+				require.True(t, rawkey.PublicKey.Equal(c.PublicKey), `first certificate should be for the private key`)
+			}
+			subjects = append(subjects, c.Subject.CommonName)
+		}
+		require.Equal(t, []string{`jwx test signer`, `jwx test CA`}, subjects)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		t.Parallel()
+		_, err := jwk.ParsePKCS12(data, `wrong`)
+		require.Error(t, err, `jwk.ParsePKCS12 should fail`)
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		t.Parallel()
+		_, err := jwk.ParsePKCS12([]byte(`not a PKCS#12 container`), `jwx-test`)
+		require.Error(t, err, `jwk.ParsePKCS12 should fail`)
+	})
+}
+
+func TestPinnedThumbprints(t *testing.T) {
+	pinned, err := jwxtest.GenerateRsaPublicJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+	injected, err := jwxtest.GenerateRsaPublicJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+	require.NoError(t, pinned.Set(jwk.KeyIDKey, `pinned`), `key.Set should succeed`)
+	require.NoError(t, injected.Set(jwk.KeyIDKey, `injected`), `key.Set should succeed`)
+
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pinned), `set.AddKey should succeed`)
+	require.NoError(t, set.AddKey(injected), `set.AddKey should succeed`)
+	served, err := json.Marshal(set)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(served)
+	}))
+	defer srv.Close()
+
+	tp, err := pinned.Thumbprint(crypto.SHA256)
+	require.NoError(t, err, `key.Thumbprint should succeed`)
+	pin := jwk.WithPinnedThumbprints(crypto.SHA256, base64.EncodeToString(tp))
+
+	check := func(t *testing.T, fetched jwk.Set) {
+		t.Helper()
+		require.Equal(t, 1, fetched.Len(), `only the pinned key should remain`)
+		_, ok := fetched.LookupKeyID(`pinned`)
+		require.True(t, ok, `pinned key should be available`)
+		_, ok = fetched.LookupKeyID(`injected`)
+		require.False(t, ok, `injected key should not be available`)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("Fetch", func(t *testing.T) {
+		fetched, err := jwk.Fetch(ctx, srv.URL, pin)
+		require.NoError(t, err, `jwk.Fetch should succeed`)
+		check(t, fetched)
+	})
+	t.Run("Fetch with no matching keys", func(t *testing.T) {
+		_, err := jwk.Fetch(ctx, srv.URL, jwk.WithPinnedThumbprints(crypto.SHA256, `bogus`))
+		require.Error(t, err, `jwk.Fetch should fail`)
+	})
+	t.Run("Cache", func(t *testing.T) {
+		c := jwk.NewCache(ctx)
+		require.NoError(t, c.Register(srv.URL, pin), `c.Register should succeed`)
+		fetched, err := c.Refresh(ctx, srv.URL)
+		require.NoError(t, err, `c.Refresh should succeed`)
+		check(t, fetched)
+	})
+}
+
+func TestStrictAlgorithm(t *testing.T) {
+	const src = `{"kty":"oct","k":"c2VjcmV0","alg":"HS999"}`
+
+	key, err := jwk.ParseKey([]byte(src))
+	require.NoError(t, err, `jwk.ParseKey should succeed`)
+	require.Equal(t, jwa.InvalidKeyAlgorithm(`HS999`), key.Algorithm(), `unknown algorithm should be kept as is`)
+
+	jwa.Settings(jwa.WithStrict(true))
+	defer jwa.Settings(jwa.WithStrict(false))
+
+	_, err = jwk.ParseKey([]byte(src))
+	require.Error(t, err, `jwk.ParseKey should fail in strict mode`)
+	require.Contains(t, err.Error(), `HS999`, `error should contain the algorithm`)
+
+	key, err = jwk.ParseKey([]byte(`{"kty":"oct","k":"c2VjcmV0","alg":"HS256"}`))
+	require.NoError(t, err, `jwk.ParseKey should succeed for known algorithms in strict mode`)
+	require.Equal(t, jwa.HS256, key.Algorithm())
+}
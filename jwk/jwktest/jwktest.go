@@ -0,0 +1,223 @@
+// Package jwktest provides utilities to generate keys for use in tests.
+//
+// The keys generated by this package are derived deterministically from
+// a seed, so that the same seed always yields the same keys, across runs,
+// machines, and Go versions. This allows test fixtures (golden files,
+// signed tokens, etc) to be generated on the fly instead of being
+// checked into the repository as PEM files.
+//
+// DO NOT USE THIS PACKAGE TO GENERATE KEYS FOR PRODUCTION USE. Anybody
+// who knows the seed can recreate the keys.
+package jwktest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const infoPrefix = `jwx/jwktest`
+
+// Generator derives keys from a seed.
+//
+// Each key is derived from an HKDF-SHA256 (RFC 5869) stream, using the
+// seed as the input keying material, no salt, and the info string
+// "jwx/jwktest:<kind>:<label>", where <kind> is one of "rsa", "ec", "okp",
+// or "oct" and <label> is the label passed to the generating method.
+// Keys with different labels are therefore independent of each other.
+//
+// The key material is read from the stream as follows, so that other
+// implementations can reproduce the same keys:
+//
+//   - RSA: each prime is searched for by reading bits/16 bytes as a big-endian
+//     integer, setting its two most significant bits and its least significant
+//     bit, and then incrementing it by 2 until `(*big.Int).ProbablyPrime(20)`
+//     succeeds. Candidates that overflow, are equal to the first prime, or
+//     for which p-1 is not coprime with e = 65537 are discarded, and a new
+//     candidate is read from the stream
+//   - EC: the private scalar is read as ceil(curve bits/8) bytes, with
+//     the excess high bits cleared. Values that are 0 or not less than
+//     the order of the curve are discarded, and new bytes are read
+//   - OKP: the 32 byte seed for `ed25519.NewKeyFromSeed()` or
+//     `x25519.NewKeyFromSeed()` is read
+//   - oct: the requested number of bytes is read
+//
+// The generated keys have their "kid" set to the label.
+type Generator struct {
+	seed []byte
+}
+
+// NewGenerator creates a new Generator that derives keys from `seed`.
+func NewGenerator(seed []byte) *Generator {
+	return &Generator{seed: append([]byte(nil), seed...)}
+}
+
+func (g *Generator) stream(kind, label string) io.Reader {
+	return hkdf.New(sha256.New, g.seed, nil, []byte(infoPrefix+`:`+kind+`:`+label))
+}
+
+func withKeyID(key jwk.Key, label string) (jwk.Key, error) {
+	if err := key.Set(jwk.KeyIDKey, label); err != nil {
+		return nil, fmt.Errorf(`failed to set "kid": %w`, err)
+	}
+	return key, nil
+}
+
+// RSA derives an RSA private key with a modulus of `bits` bits.
+// `bits` must be a multiple of 16, and at least 1024.
+func (g *Generator) RSA(label string, bits int) (jwk.Key, error) {
+	if bits < 1024 || bits%16 != 0 {
+		return nil, fmt.Errorf(`jwktest.RSA: invalid key size %d (must be a multiple of 16, and at least 1024)`, bits)
+	}
+
+	const e = 65537
+	src := g.stream(`rsa`, label)
+	half := bits / 2
+	bigE := big.NewInt(e)
+	one := big.NewInt(1)
+	two := big.NewInt(2)
+	buf := make([]byte, half/8)
+
+	var primes []*big.Int
+	for len(primes) < 2 {
+		if _, err := io.ReadFull(src, buf); err != nil {
+			return nil, fmt.Errorf(`jwktest.RSA: failed to read from key stream: %w`, err)
+		}
+		buf[0] |= 0xc0
+		buf[len(buf)-1] |= 1
+
+		p := new(big.Int).SetBytes(buf)
+		for !p.ProbablyPrime(20) {
+			p.Add(p, two)
+		}
+		if p.BitLen() != half {
+			continue
+		}
+		if len(primes) > 0 && p.Cmp(primes[0]) == 0 {
+			continue
+		}
+		pminus1 := new(big.Int).Sub(p, one)
+		if new(big.Int).GCD(nil, nil, bigE, pminus1).Cmp(one) != 0 {
+			continue
+		}
+		primes = append(primes, p)
+	}
+
+	n := new(big.Int).Mul(primes[0], primes[1])
+	phi := new(big.Int).Mul(new(big.Int).Sub(primes[0], one), new(big.Int).Sub(primes[1], one))
+	d := new(big.Int).ModInverse(bigE, phi)
+	if d == nil {
+		return nil, fmt.Errorf(`jwktest.RSA: failed to compute private exponent`)
+	}
+
+	raw := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: e},
+		D:         d,
+		Primes:    primes,
+	}
+	if err := raw.Validate(); err != nil {
+		return nil, fmt.Errorf(`jwktest.RSA: generated key is invalid: %w`, err)
+	}
+	raw.Precompute()
+
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		return nil, fmt.Errorf(`jwktest.RSA: failed to create jwk.Key: %w`, err)
+	}
+	return withKeyID(key, label)
+}
+
+// EC derives an ECDSA private key on the curve `crv`.
+func (g *Generator) EC(label string, crv jwa.EllipticCurveAlgorithm) (jwk.Key, error) {
+	curve, ok := jwk.CurveForAlgorithm(crv)
+	if !ok {
+		return nil, fmt.Errorf(`jwktest.EC: unsupported curve %q`, crv)
+	}
+
+	params := curve.Params()
+	src := g.stream(`ec`, label)
+	buf := make([]byte, (params.BitSize+7)/8)
+	// mask for the excess bits in the first byte (e.g. P-521)
+	mask := byte(0xff)
+	if excess := len(buf)*8 - params.BitSize; excess > 0 {
+		mask >>= excess
+	}
+
+	d := new(big.Int)
+	for {
+		if _, err := io.ReadFull(src, buf); err != nil {
+			return nil, fmt.Errorf(`jwktest.EC: failed to read from key stream: %w`, err)
+		}
+		buf[0] &= mask
+		d.SetBytes(buf)
+		if d.Sign() > 0 && d.Cmp(params.N) < 0 {
+			break
+		}
+	}
+
+	raw := &ecdsa.PrivateKey{D: d}
+	raw.Curve = curve
+	raw.X, raw.Y = curve.ScalarBaseMult(d.Bytes())
+
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		return nil, fmt.Errorf(`jwktest.EC: failed to create jwk.Key: %w`, err)
+	}
+	return withKeyID(key, label)
+}
+
+// OKP derives an OKP private key on the curve `crv`, which must be
+// either `jwa.Ed25519` or `jwa.X25519`.
+func (g *Generator) OKP(label string, crv jwa.EllipticCurveAlgorithm) (jwk.Key, error) {
+	seed := make([]byte, 32)
+	if _, err := io.ReadFull(g.stream(`okp`, label), seed); err != nil {
+		return nil, fmt.Errorf(`jwktest.OKP: failed to read from key stream: %w`, err)
+	}
+
+	var raw interface{}
+	switch crv {
+	case jwa.Ed25519:
+		raw = ed25519.NewKeyFromSeed(seed)
+	case jwa.X25519:
+		priv, err := x25519.NewKeyFromSeed(seed)
+		if err != nil {
+			return nil, fmt.Errorf(`jwktest.OKP: failed to create X25519 key: %w`, err)
+		}
+		raw = priv
+	default:
+		return nil, fmt.Errorf(`jwktest.OKP: unsupported curve %q`, crv)
+	}
+
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		return nil, fmt.Errorf(`jwktest.OKP: failed to create jwk.Key: %w`, err)
+	}
+	return withKeyID(key, label)
+}
+
+// Symmetric derives a symmetric key of `size` bytes.
+func (g *Generator) Symmetric(label string, size int) (jwk.Key, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf(`jwktest.Symmetric: invalid key size %d`, size)
+	}
+
+	octets := make([]byte, size)
+	if _, err := io.ReadFull(g.stream(`oct`, label), octets); err != nil {
+		return nil, fmt.Errorf(`jwktest.Symmetric: failed to read from key stream: %w`, err)
+	}
+
+	key, err := jwk.FromRaw(octets)
+	if err != nil {
+		return nil, fmt.Errorf(`jwktest.Symmetric: failed to create jwk.Key: %w`, err)
+	}
+	return withKeyID(key, label)
+}
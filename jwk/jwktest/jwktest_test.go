@@ -0,0 +1,132 @@
+package jwktest_test
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwk/jwktest"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/require"
+)
+
+func thumbprint(t *testing.T, key jwk.Key) string {
+	t.Helper()
+	tp, err := key.Thumbprint(crypto.SHA256)
+	require.NoError(t, err, `key.Thumbprint should succeed`)
+	return base64.EncodeToString(tp)
+}
+
+func TestGenerator(t *testing.T) {
+	t.Parallel()
+
+	seed := []byte(`jwx test seed`)
+	testcases := []struct {
+		Name       string
+		Generate   func(*jwktest.Generator, string) (jwk.Key, error)
+		Algorithm  jwa.SignatureAlgorithm
+		Thumbprint string
+	}{
+		{
+			Name: `RSA`,
+			Generate: func(g *jwktest.Generator, label string) (jwk.Key, error) {
+				return g.RSA(label, 2048)
+			},
+			Algorithm:  jwa.RS256,
+			Thumbprint: `mIbIc8dpILg944ipo53kadcqt6cvGzG-u069Z8gWurE`,
+		},
+		{
+			Name: `EC P-256`,
+			Generate: func(g *jwktest.Generator, label string) (jwk.Key, error) {
+				return g.EC(label, jwa.P256)
+			},
+			Algorithm:  jwa.ES256,
+			Thumbprint: `Q3QrHGNhCgtWFLauGozyta9rYy6ljG-N7jPoPZ2lFvc`,
+		},
+		{
+			Name: `EC P-521`,
+			Generate: func(g *jwktest.Generator, label string) (jwk.Key, error) {
+				return g.EC(label, jwa.P521)
+			},
+			Algorithm:  jwa.ES512,
+			Thumbprint: `b4TX2habJGxVdy4iHfd3W03s1zoTN8z98cX3PAxJaGU`,
+		},
+		{
+			Name: `Ed25519`,
+			Generate: func(g *jwktest.Generator, label string) (jwk.Key, error) {
+				return g.OKP(label, jwa.Ed25519)
+			},
+			Algorithm:  jwa.EdDSA,
+			Thumbprint: `serQh4wcTEtIjVOpIS1fSThhBLUCOYzsWvaMxUBW3Ts`,
+		},
+		{
+			Name: `X25519`,
+			Generate: func(g *jwktest.Generator, label string) (jwk.Key, error) {
+				return g.OKP(label, jwa.X25519)
+			},
+			Thumbprint: `6DKxQJoN_IW-DGWkwTYVMMWtaP3oZf6z7VE93ZaQsU0`,
+		},
+		{
+			Name: `Symmetric`,
+			Generate: func(g *jwktest.Generator, label string) (jwk.Key, error) {
+				return g.Symmetric(label, 32)
+			},
+			Algorithm:  jwa.HS256,
+			Thumbprint: `Vlr2TWbTh48Pnd9HBEBmM5K2EUNM3A83ljQZDqWeMDc`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			key, err := tc.Generate(jwktest.NewGenerator(seed), `signer`)
+			require.NoError(t, err, `key generation should succeed`)
+			require.Equal(t, `signer`, key.KeyID())
+
+			// The thumbprints are fixed, so that any change to the
+			// derivation scheme is caught
+			require.Equal(t, tc.Thumbprint, thumbprint(t, key))
+
+			again, err := tc.Generate(jwktest.NewGenerator(seed), `signer`)
+			require.NoError(t, err, `key generation should succeed`)
+			require.Equal(t, thumbprint(t, key), thumbprint(t, again), `same seed and label should yield the same key`)
+
+			other, err := tc.Generate(jwktest.NewGenerator(seed), `other`)
+			require.NoError(t, err, `key generation should succeed`)
+			require.NotEqual(t, thumbprint(t, key), thumbprint(t, other), `different labels should yield different keys`)
+
+			other, err = tc.Generate(jwktest.NewGenerator([]byte(`another seed`)), `signer`)
+			require.NoError(t, err, `key generation should succeed`)
+			require.NotEqual(t, thumbprint(t, key), thumbprint(t, other), `different seeds should yield different keys`)
+
+			if tc.Algorithm == "" {
+				return
+			}
+			signed, err := jws.Sign([]byte(`payload`), jws.WithKey(tc.Algorithm, key))
+			require.NoError(t, err, `jws.Sign should succeed`)
+			pubkey, err := jwk.PublicKeyOf(key)
+			if tc.Algorithm == jwa.HS256 {
+				pubkey = key
+				err = nil
+			}
+			require.NoError(t, err, `jwk.PublicKeyOf should succeed`)
+			_, err = jws.Verify(signed, jws.WithKey(tc.Algorithm, pubkey))
+			require.NoError(t, err, `jws.Verify should succeed`)
+		})
+	}
+	t.Run(`invalid parameters`, func(t *testing.T) {
+		t.Parallel()
+		g := jwktest.NewGenerator(seed)
+		_, err := g.RSA(`key`, 1000)
+		require.Error(t, err, `RSA with invalid size should fail`)
+		_, err = g.EC(`key`, jwa.X25519)
+		require.Error(t, err, `EC with OKP curve should fail`)
+		_, err = g.OKP(`key`, jwa.P256)
+		require.Error(t, err, `OKP with EC curve should fail`)
+		_, err = g.Symmetric(`key`, 0)
+		require.Error(t, err, `Symmetric with zero size should fail`)
+	})
+}
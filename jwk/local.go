@@ -0,0 +1,121 @@
+package jwk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DataURL creates a `data:` URL containing the given JSON encoded JWK or
+// JWKS. The resulting URL can be passed to `jwk.Fetch()` or
+// `(*jwk.Cache).Register()` along with `jwk.WithLocalSources(true)`
+func DataURL(buf []byte) string {
+	return `data:application/json;base64,` + base64.StdEncoding.EncodeToString(buf)
+}
+
+// localSourceClient is a HTTPClient that handles `file://` and `data:`
+// URLs by itself, and delegates all other requests to the next client
+type localSourceClient struct {
+	next HTTPClient
+}
+
+func (c *localSourceClient) Get(u string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to create request for %q: %w`, u, err)
+	}
+
+	switch req.URL.Scheme {
+	case `file`:
+		return c.doFile(req)
+	case `data`:
+		return c.doData(req)
+	default:
+		return c.next.Get(u)
+	}
+}
+
+func (c *localSourceClient) doFile(req *http.Request) (*http.Response, error) {
+	u := req.URL
+	// file:jwks.json, or file://jwks.json (which url.Parse treats as
+	// having "jwks.json" as the host) are relative forms
+	if u.Opaque != "" || (u.Host != "" && u.Path == "") {
+		return nil, fmt.Errorf(`relative file URLs are not supported (%q): use the absolute form file:///path/to/jwks.json`, u.String())
+	}
+	if u.Host != "" && u.Host != `localhost` {
+		return nil, fmt.Errorf(`file URLs with non-local hosts are not supported (%q)`, u.Host)
+	}
+
+	path := localFilePath(u.Path)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to open %q: %w`, path, err)
+	}
+	return localResponse(req, f), nil
+}
+
+// localFilePath converts the path portion of a file URL to a local
+// file path. On Windows, file:///C:/path/to/jwks.json has the path
+// "/C:/path/to/jwks.json", so the leading slash is removed before
+// the drive letter. UNC paths (file://server/share) are not supported.
+func localFilePath(p string) string {
+	if runtime.GOOS == `windows` && len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func (c *localSourceClient) doData(req *http.Request) (*http.Response, error) {
+	// data:[<mediatype>][;base64],<data>
+	src := req.URL.Opaque
+	if src == "" {
+		return nil, fmt.Errorf(`invalid data URL: missing contents`)
+	}
+
+	// '?' and '#' are treated as query/fragment delimiters when the URL is
+	// parsed, which would silently truncate the contents
+	if req.URL.RawQuery != "" || req.URL.ForceQuery || req.URL.Fragment != "" {
+		return nil, fmt.Errorf(`invalid data URL: "?" and "#" in contents must be percent-encoded`)
+	}
+
+	i := strings.IndexByte(src, ',')
+	if i < 0 {
+		return nil, fmt.Errorf(`invalid data URL: missing ","`)
+	}
+
+	var buf []byte
+	if meta := src[:i]; strings.HasSuffix(meta, `;base64`) {
+		decoded, err := base64.StdEncoding.DecodeString(src[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf(`failed to decode base64 contents in data URL: %w`, err)
+		}
+		buf = decoded
+	} else {
+		unescaped, err := url.PathUnescape(src[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf(`failed to unescape contents in data URL: %w`, err)
+		}
+		buf = []byte(unescaped)
+	}
+	return localResponse(req, io.NopCloser(bytes.NewReader(buf))), nil
+}
+
+func localResponse(req *http.Request, body io.ReadCloser) *http.Response {
+	return &http.Response{
+		Status:     `200 OK`,
+		StatusCode: http.StatusOK,
+		Proto:      `HTTP/1.1`,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       body,
+		Request:    req,
+	}
+}
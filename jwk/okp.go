@@ -6,7 +6,6 @@ import (
 	"crypto/ed25519"
 	"fmt"
 
-	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jwx/v2/internal/base64"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/x25519"
@@ -77,7 +76,7 @@ func (k *okpPublicKey) Raw(v interface{}) error {
 		return fmt.Errorf(`failed to build public key: %w`, err)
 	}
 
-	return blackmagic.AssignIfCompatible(v, pubk)
+	return assignRawKey(v, pubk)
 }
 
 func buildOKPPrivateKey(alg jwa.EllipticCurveAlgorithm, xbuf []byte, dbuf []byte) (interface{}, error) {
@@ -113,7 +112,7 @@ func (k *okpPrivateKey) Raw(v interface{}) error {
 		return fmt.Errorf(`failed to build public key: %w`, err)
 	}
 
-	return blackmagic.AssignIfCompatible(v, privk)
+	return assignRawKey(v, privk)
 }
 
 func makeOKPPublicKey(v interface {
@@ -1,10 +1,14 @@
 package jwk
 
 import (
+	"crypto"
+
 	"github.com/lestrrat-go/option"
 )
 
 type identTypedField struct{}
+type identKeyIDFilter struct{}
+type identPinnedThumbprints struct{}
 
 type typedFieldPair struct {
 	Name  string
@@ -36,3 +40,46 @@ func WithTypedField(name string, object interface{}) ParseOption {
 		),
 	}
 }
+
+// WithKeyIDFilter specifies that only the keys whose key IDs ("kid")
+// are listed are to be included in the JWK Set. Keys that do not have
+// a key ID are excluded as well.
+//
+// This option is applicable to `jwk.Parse()` and `jwk.ParseStream()`, as well
+// as `jwk.Fetch()` and `(*jwk.Cache).Register()`. When used with
+// `jwk.ParseStream()`, keys that are excluded are skipped without being
+// fully parsed, which is useful for loading a handful of keys out of
+// very large JWK Sets.
+func WithKeyIDFilter(kids ...string) ParseOption {
+	filter := make(map[string]struct{}, len(kids))
+	for _, kid := range kids {
+		filter[kid] = struct{}{}
+	}
+	return &parseOption{option.New(identKeyIDFilter{}, filter)}
+}
+
+// WithPinnedThumbprints pins the JWKS fetched from a remote source
+// to the keys whose thumbprints (RFC 7638), computed using `hash`,
+// are listed in `thumbprints`. The thumbprints must be encoded using
+// base64url without padding, which is the same format used in fields
+// such as `jkt` (RFC 9449).
+//
+// Keys in the fetched JWKS that do not match any of the pins are removed
+// from the resulting `jwk.Set`, so that they can never be selected for
+// verification: a compromised JWKS endpoint cannot inject keys of its
+// choosing, and messages signed with such keys fail to verify.
+// If none of the keys match, the fetch itself fails.
+//
+// This option can be passed to `jwk.Fetch()`, `(*jwk.Cache).Register()`,
+// as well as `jws.WithFetchOptions()` for use with `jws.WithVerifyAuto()`.
+// Remember to add the thumbprints of new keys before the JWKS is rotated.
+func WithPinnedThumbprints(hash crypto.Hash, thumbprints ...string) FetchOption {
+	pins := &thumbprintPins{
+		hash:        hash,
+		thumbprints: make(map[string]struct{}, len(thumbprints)),
+	}
+	for _, tp := range thumbprints {
+		pins.thumbprints[tp] = struct{}{}
+	}
+	return &fetchOption{option.New(identPinnedThumbprints{}, pins)}
+}
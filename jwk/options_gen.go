@@ -4,7 +4,10 @@ package jwk
 
 import (
 	"crypto"
+	"crypto/tls"
 	"io/fs"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/lestrrat-go/jwx/v2/internal/json"
@@ -106,12 +109,16 @@ type identFS struct{}
 type identFetchWhitelist struct{}
 type identHTTPClient struct{}
 type identIgnoreParseError struct{}
+type identKeySetVerifier struct{}
 type identLocalRegistry struct{}
+type identLocalSources struct{}
 type identMinRefreshInterval struct{}
 type identPEM struct{}
 type identPostFetcher struct{}
+type identProxy struct{}
 type identRefreshInterval struct{}
 type identRefreshWindow struct{}
+type identTLSConfig struct{}
 type identThumbprintHash struct{}
 
 func (identErrSink) String() string {
@@ -134,10 +141,18 @@ func (identIgnoreParseError) String() string {
 	return "WithIgnoreParseError"
 }
 
+func (identKeySetVerifier) String() string {
+	return "WithKeySetVerifier"
+}
+
 func (identLocalRegistry) String() string {
 	return "withLocalRegistry"
 }
 
+func (identLocalSources) String() string {
+	return "WithLocalSources"
+}
+
 func (identMinRefreshInterval) String() string {
 	return "WithMinRefreshInterval"
 }
@@ -150,6 +165,10 @@ func (identPostFetcher) String() string {
 	return "WithPostFetcher"
 }
 
+func (identProxy) String() string {
+	return "WithProxy"
+}
+
 func (identRefreshInterval) String() string {
 	return "WithRefreshInterval"
 }
@@ -158,6 +177,10 @@ func (identRefreshWindow) String() string {
 	return "WithRefreshWindow"
 }
 
+func (identTLSConfig) String() string {
+	return "WithTLSConfig"
+}
+
 func (identThumbprintHash) String() string {
 	return "WithThumbprintHash"
 }
@@ -211,11 +234,47 @@ func WithIgnoreParseError(v bool) ParseOption {
 	return &parseOption{option.New(identIgnoreParseError{}, v)}
 }
 
+// WithKeySetVerifier specifies the `jwk.KeySetVerifier` object to be used
+// to verify the raw payload fetched from the remote resource before it is
+// parsed as a JWKS. This is typically used to handle JWKS that are published
+// wrapped in a JWS, in which case you should use `jws.NewKeySetVerifier()`
+// to create the verifier.
+//
+// When used with `(*jwk.Cache).Register()`, the verification is performed
+// every time the JWKS is refreshed, before the result is stored in the cache.
+func WithKeySetVerifier(v KeySetVerifier) FetchOption {
+	return &fetchOption{option.New(identKeySetVerifier{}, v)}
+}
+
 // This option is only available for internal code. Users don't get to play with it
 func withLocalRegistry(v *json.Registry) ParseOption {
 	return &parseOption{option.New(identLocalRegistry{}, v)}
 }
 
+// WithLocalSources specifies that `file://` and `data:` URLs may be
+// used as the source of the JWKS, in addition to the URLs supported
+// by the HTTP client. This allows test or air-gapped environments
+// to use the exact same `jwk.Fetch()` / `jwk.Cache` code paths as
+// production environments that fetch JWKS over HTTPS.
+//
+// `file://` URLs must contain an absolute path to the file (e.g.
+// `file:///etc/jwks.json`, or `file:///C:/jwks.json` on Windows).
+// Relative forms such as `file://jwks.json` are rejected.
+//
+// `data:` URLs must contain either base64 encoded
+// (`data:application/json;base64,...`) or percent-encoded
+// (`data:application/json,...`) JSON. `jwk.DataURL()` can be
+// used to create such URLs.
+//
+// This option is disabled by default, as it allows reading arbitrary
+// local files. Only enable it for URLs that you control, and never
+// for URLs taken from untrusted input (e.g. the `jku` header).
+// The whitelist specified via `jwk.WithFetchWhitelist()` is still
+// applied to these URLs.
+func WithLocalSources(v bool) FetchOption {
+	return &fetchOption{option.New(identLocalSources{}, v)}
+}
+
 // WithMinRefreshInterval specifies the minimum refresh interval to be used
 // when using `jwk.Cache`. This value is ONLY used if you did not specify
 // a user-supplied static refresh interval via `WithRefreshInterval`.
@@ -252,6 +311,18 @@ func WithPostFetcher(v PostFetcher) RegisterOption {
 	return &registerOption{option.New(identPostFetcher{}, v)}
 }
 
+// WithProxy specifies the function used to determine the proxy for
+// requests made when fetching jwk.Set objects. It takes the same form
+// as the `Proxy` field in "net/http".Transport, so you can pass values
+// such as `http.ProxyURL(u)` or `http.ProxyFromEnvironment`.
+//
+// The proxy is applied to a clone of `http.DefaultTransport`.
+// It cannot be used together with `jwk.WithHTTPClient()`: if you
+// need more control, configure your own "net/http".Client instead.
+func WithProxy(v func(*http.Request) (*url.URL, error)) FetchOption {
+	return &fetchOption{option.New(identProxy{}, v)}
+}
+
 // WithRefreshInterval specifies the static interval between refreshes
 // of jwk.Set objects controlled by jwk.Cache.
 //
@@ -269,6 +340,18 @@ func WithRefreshWindow(v time.Duration) CacheOption {
 	return &cacheOption{option.New(identRefreshWindow{}, v)}
 }
 
+// WithTLSConfig specifies the "crypto/tls".Config object to be used
+// when fetching jwk.Set objects. This can be used to, for example,
+// present a client certificate to JWKS endpoints that are only
+// available over mutual TLS.
+//
+// The configuration is applied to a clone of `http.DefaultTransport`.
+// It cannot be used together with `jwk.WithHTTPClient()`: if you
+// need more control, configure your own "net/http".Client instead.
+func WithTLSConfig(v *tls.Config) FetchOption {
+	return &fetchOption{option.New(identTLSConfig{}, v)}
+}
+
 func WithThumbprintHash(v crypto.Hash) AssignKeyIDOption {
 	return &assignKeyIDOption{option.New(identThumbprintHash{}, v)}
 }
@@ -14,11 +14,15 @@ func TestOptionIdent(t *testing.T) {
 	require.Equal(t, "WithFetchWhitelist", identFetchWhitelist{}.String())
 	require.Equal(t, "WithHTTPClient", identHTTPClient{}.String())
 	require.Equal(t, "WithIgnoreParseError", identIgnoreParseError{}.String())
+	require.Equal(t, "WithKeySetVerifier", identKeySetVerifier{}.String())
 	require.Equal(t, "withLocalRegistry", identLocalRegistry{}.String())
+	require.Equal(t, "WithLocalSources", identLocalSources{}.String())
 	require.Equal(t, "WithMinRefreshInterval", identMinRefreshInterval{}.String())
 	require.Equal(t, "WithPEM", identPEM{}.String())
 	require.Equal(t, "WithPostFetcher", identPostFetcher{}.String())
+	require.Equal(t, "WithProxy", identProxy{}.String())
 	require.Equal(t, "WithRefreshInterval", identRefreshInterval{}.String())
 	require.Equal(t, "WithRefreshWindow", identRefreshWindow{}.String())
+	require.Equal(t, "WithTLSConfig", identTLSConfig{}.String())
 	require.Equal(t, "WithThumbprintHash", identThumbprintHash{}.String())
 }
@@ -0,0 +1,148 @@
+package jwk
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/cert"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// ParsePKCS12 parses a PKCS#12 container (i.e. the contents of a .p12 or
+// .pfx file) protected by `password`, and returns the private key stored
+// in it as a `jwk.Key`.
+//
+// The `x5c` field of the key is populated with the certificates found in
+// the container: the first element is the certificate for the private key,
+// followed by the certificates of its issuers, as required by RFC 7517.
+// Certificates in the container that are not part of the chain
+// for the private key are not included.
+//
+// The container must hold exactly one private key. Only the legacy
+// encryption schemes (SHA-1 with 3DES or RC2) supported by
+// `golang.org/x/crypto/pkcs12` can be used.
+func ParsePKCS12(data []byte, password string) (Key, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.ParsePKCS12: failed to decode PKCS#12 data: %w`, err)
+	}
+
+	var rawkey interface{}
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		switch block.Type {
+		case pmPrivateKey:
+			if rawkey != nil {
+				return nil, fmt.Errorf(`jwk.ParsePKCS12: PKCS#12 data contains more than one private key`)
+			}
+			rawkey, err = parsePKCS12PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf(`jwk.ParsePKCS12: %w`, err)
+			}
+		case "CERTIFICATE":
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf(`jwk.ParsePKCS12: failed to parse certificate: %w`, err)
+			}
+			certs = append(certs, c)
+		}
+	}
+
+	if rawkey == nil {
+		return nil, fmt.Errorf(`jwk.ParsePKCS12: PKCS#12 data does not contain a private key`)
+	}
+
+	key, err := FromRaw(rawkey)
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.ParsePKCS12: failed to create jwk.Key: %w`, err)
+	}
+
+	if len(certs) == 0 {
+		return key, nil
+	}
+
+	ordered, err := pkcs12CertChain(rawkey, certs)
+	if err != nil {
+		return nil, fmt.Errorf(`jwk.ParsePKCS12: %w`, err)
+	}
+
+	var chain cert.Chain
+	for _, c := range ordered {
+		encoded, err := cert.EncodeBase64(c.Raw)
+		if err != nil {
+			return nil, fmt.Errorf(`jwk.ParsePKCS12: failed to encode certificate: %w`, err)
+		}
+		if err := chain.Add(encoded); err != nil {
+			return nil, fmt.Errorf(`jwk.ParsePKCS12: failed to add certificate to chain: %w`, err)
+		}
+	}
+	if err := key.Set(X509CertChainKey, &chain); err != nil {
+		return nil, fmt.Errorf(`jwk.ParsePKCS12: failed to set %q: %w`, X509CertChainKey, err)
+	}
+	return key, nil
+}
+
+// parsePKCS12PrivateKey parses the private keys returned by pkcs12.ToPEM,
+// which are labeled as "PRIVATE KEY" but are actually encoded in PKCS#1
+// (RSA) or SEC 1 (EC) format
+func parsePKCS12PrivateKey(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse private key: %w`, err)
+	}
+	return key, nil
+}
+
+// pkcs12CertChain returns the certificate for `rawkey` followed by its
+// issuers, in order
+func pkcs12CertChain(rawkey interface{}, certs []*x509.Certificate) ([]*x509.Certificate, error) {
+	signer, ok := rawkey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf(`private key of type %T does not have a public key`, rawkey)
+	}
+	pubkey, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return nil, fmt.Errorf(`public key of type %T cannot be compared`, signer.Public())
+	}
+
+	var leaf *x509.Certificate
+	for _, c := range certs {
+		if pubkey.Equal(c.PublicKey) {
+			leaf = c
+			break
+		}
+	}
+	if leaf == nil {
+		return nil, fmt.Errorf(`PKCS#12 data does not contain a certificate for the private key`)
+	}
+
+	chain := []*x509.Certificate{leaf}
+	used := map[*x509.Certificate]struct{}{leaf: {}}
+	for current := leaf; !bytes.Equal(current.RawIssuer, current.RawSubject); {
+		var issuer *x509.Certificate
+		for _, c := range certs {
+			if _, ok := used[c]; ok {
+				continue
+			}
+			if bytes.Equal(c.RawSubject, current.RawIssuer) {
+				issuer = c
+				break
+			}
+		}
+		if issuer == nil {
+			break
+		}
+		used[issuer] = struct{}{}
+		chain = append(chain, issuer)
+		current = issuer
+	}
+	return chain, nil
+}
@@ -0,0 +1,82 @@
+package jwk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+)
+
+// ProviderHints contains information about the message being processed
+// that a `jwk.Provider` may use to narrow down the keys that it returns.
+// All fields are optional, and providers are free to ignore them.
+type ProviderHints struct {
+	// KeyID is the value of the `kid` header of the message, if any
+	KeyID string
+	// Algorithm is the value of the `alg` header of the message, if any
+	Algorithm jwa.KeyAlgorithm
+}
+
+// Provider is the common interface for objects that can supply a `jwk.Set`
+// to be used for signature verification.
+//
+// It allows static key sets, cached remote key sets, and any custom
+// key resolution logic to be passed to the verification entry points
+// (e.g. `jws.WithKeySetProvider()` and `jwt.WithKeySetProvider()`)
+// in a uniform manner.
+//
+// The `jwk.Set` returned by the provider should be treated as read-only
+// by the caller.
+type Provider interface {
+	FetchKeys(context.Context, ProviderHints) (Set, error)
+}
+
+// ProviderFunc is a `jwk.Provider` that is implemented by a single function.
+type ProviderFunc func(context.Context, ProviderHints) (Set, error)
+
+func (f ProviderFunc) FetchKeys(ctx context.Context, hints ProviderHints) (Set, error) {
+	return f(ctx, hints)
+}
+
+type staticProvider struct {
+	set Set
+}
+
+// NewStaticProvider creates a `jwk.Provider` that always returns
+// the given `jwk.Set`, regardless of the hints given.
+func NewStaticProvider(set Set) Provider {
+	return &staticProvider{set: set}
+}
+
+func (p *staticProvider) FetchKeys(_ context.Context, _ ProviderHints) (Set, error) {
+	if p.set == nil {
+		return nil, fmt.Errorf(`jwk.StaticProvider: no jwk.Set available`)
+	}
+	return p.set, nil
+}
+
+type cacheProvider struct {
+	cache *Cache
+	url   string
+}
+
+// NewCacheProvider creates a `jwk.Provider` that returns the `jwk.Set`
+// stored in the `jwk.Cache` under the given URL. The URL must have been
+// registered to the cache beforehand.
+func NewCacheProvider(cache *Cache, u string) Provider {
+	return &cacheProvider{
+		cache: cache,
+		url:   u,
+	}
+}
+
+func (p *cacheProvider) FetchKeys(ctx context.Context, _ ProviderHints) (Set, error) {
+	if p.cache == nil {
+		return nil, fmt.Errorf(`jwk.CacheProvider: no jwk.Cache available`)
+	}
+	set, err := p.cache.Get(ctx, p.url)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to fetch %q from cache: %w`, p.url, err)
+	}
+	return set, nil
+}
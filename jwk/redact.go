@@ -0,0 +1,63 @@
+package jwk
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+)
+
+const redactedValue = `[REDACTED]`
+
+// the names of the parameters that hold private or secret key material
+var sensitiveParams = map[string]struct{}{
+	RSADKey:            {},
+	RSAPKey:            {},
+	RSAQKey:            {},
+	RSADPKey:           {},
+	RSADQKey:           {},
+	RSAQIKey:           {},
+	RSAOthKey:          {},
+	SymmetricOctetsKey: {},
+}
+
+// Redact returns the parameters of `key` in their JSON representation,
+// in which the values of the parameters that hold private or secret key
+// material (e.g. "d" and "k") are replaced with the string "[REDACTED]".
+// Public parameters such as "n", "x", and "kid" are left as-is.
+func Redact(key Key) map[string]interface{} {
+	var params map[string]interface{}
+	buf, err := json.Marshal(key)
+	if err == nil {
+		err = json.Unmarshal(buf, &params)
+	}
+	if err != nil {
+		return map[string]interface{}{KeyTypeKey: key.KeyType().String()}
+	}
+
+	for name := range params {
+		if _, ok := sensitiveParams[name]; ok {
+			params[name] = redactedValue
+		}
+	}
+	return params
+}
+
+func redactedString(key Key) string {
+	buf, err := json.Marshal(Redact(key))
+	if err != nil {
+		return fmt.Sprintf(`failed to marshal redacted key: %s`, err)
+	}
+	return string(buf)
+}
+
+// The String methods return the key in JSON format, with the values of
+// private and secret key material redacted. Use `json.Marshal()` to
+// obtain the key as-is.
+
+func (k *rsaPrivateKey) String() string   { return redactedString(k) }
+func (k *rsaPublicKey) String() string    { return redactedString(k) }
+func (k *ecdsaPrivateKey) String() string { return redactedString(k) }
+func (k *ecdsaPublicKey) String() string  { return redactedString(k) }
+func (k *okpPrivateKey) String() string   { return redactedString(k) }
+func (k *okpPublicKey) String() string    { return redactedString(k) }
+func (k *symmetricKey) String() string    { return redactedString(k) }
@@ -0,0 +1,35 @@
+//go:build go1.21
+// +build go1.21
+
+package jwk
+
+import (
+	"log/slog"
+	"sort"
+)
+
+func redactedLogValue(key Key) slog.Value {
+	params := Redact(key)
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, slog.Any(name, params[name]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// The LogValue methods implement `slog.LogValuer`, so that private and
+// secret key material is redacted when keys are logged via `log/slog`.
+
+func (k *rsaPrivateKey) LogValue() slog.Value   { return redactedLogValue(k) }
+func (k *rsaPublicKey) LogValue() slog.Value    { return redactedLogValue(k) }
+func (k *ecdsaPrivateKey) LogValue() slog.Value { return redactedLogValue(k) }
+func (k *ecdsaPublicKey) LogValue() slog.Value  { return redactedLogValue(k) }
+func (k *okpPrivateKey) LogValue() slog.Value   { return redactedLogValue(k) }
+func (k *okpPublicKey) LogValue() slog.Value    { return redactedLogValue(k) }
+func (k *symmetricKey) LogValue() slog.Value    { return redactedLogValue(k) }
@@ -0,0 +1,88 @@
+package jwk_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	rsaKey, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	ecKey, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	okpKey, err := jwxtest.GenerateEd25519Jwk()
+	require.NoError(t, err, `jwxtest.GenerateEd25519Jwk should succeed`)
+	symKey, err := jwxtest.GenerateSymmetricJwk()
+	require.NoError(t, err, `jwxtest.GenerateSymmetricJwk should succeed`)
+
+	testcases := []struct {
+		Name     string
+		Key      jwk.Key
+		Redacted []string
+		Public   []string
+	}{
+		{
+			Name:     `RSA`,
+			Key:      rsaKey,
+			Redacted: []string{jwk.RSADKey, jwk.RSAPKey, jwk.RSAQKey, jwk.RSADPKey, jwk.RSADQKey, jwk.RSAQIKey},
+			Public:   []string{jwk.RSANKey, jwk.RSAEKey},
+		},
+		{
+			Name:     `EC`,
+			Key:      ecKey,
+			Redacted: []string{jwk.ECDSADKey},
+			Public:   []string{jwk.ECDSAXKey, jwk.ECDSAYKey, jwk.ECDSACrvKey},
+		},
+		{
+			Name:     `OKP`,
+			Key:      okpKey,
+			Redacted: []string{jwk.OKPDKey},
+			Public:   []string{jwk.OKPXKey, jwk.OKPCrvKey},
+		},
+		{
+			Name:     `oct`,
+			Key:      symKey,
+			Redacted: []string{jwk.SymmetricOctetsKey},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			require.NoError(t, tc.Key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+
+			full, err := json.Marshal(tc.Key)
+			require.NoError(t, err, `json.Marshal should succeed`)
+			var expected map[string]interface{}
+			require.NoError(t, json.Unmarshal(full, &expected), `json.Unmarshal should succeed`)
+
+			s := fmt.Sprintf(`%v`, tc.Key)
+			var actual map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(s), &actual), `redacted string should be valid JSON`)
+
+			require.Equal(t, `my-key`, actual[jwk.KeyIDKey])
+			for _, name := range tc.Redacted {
+				require.Equal(t, `[REDACTED]`, actual[name], `%q should be redacted`, name)
+				require.NotContains(t, s, expected[name], `%q should not be in the output`, name)
+			}
+			for _, name := range tc.Public {
+				require.Equal(t, expected[name], actual[name], `%q should not be redacted`, name)
+			}
+
+			if tc.Key.KeyType() == jwa.OctetSeq {
+				return
+			}
+
+			// public keys have nothing to redact
+			pubkey, err := jwk.PublicKeyOf(tc.Key)
+			require.NoError(t, err, `jwk.PublicKeyOf should succeed`)
+			require.NotContains(t, fmt.Sprintf(`%v`, pubkey), `[REDACTED]`)
+		})
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/internal/pool"
 )
 
@@ -24,8 +24,8 @@ func (k *rsaPrivateKey) FromRaw(rawKey *rsa.PrivateKey) error {
 
 	l := len(rawKey.Primes)
 
-	if l < 0 /* I know, I'm being paranoid */ || l > 2 {
-		return fmt.Errorf(`invalid number of primes in rsa.PrivateKey: need 0 to 2, but got %d`, len(rawKey.Primes))
+	if l < 0 /* I know, I'm being paranoid */ || l == 1 {
+		return fmt.Errorf(`invalid number of primes in rsa.PrivateKey: need 0, or 2 or more, but got %d`, len(rawKey.Primes))
 	}
 
 	if l > 0 {
@@ -55,6 +55,31 @@ func (k *rsaPrivateKey) FromRaw(rawKey *rsa.PrivateKey) error {
 		k.qi = v
 	}
 
+	// multi-prime keys: the third and subsequent primes go to "oth"
+	if l > 2 {
+		if len(rawKey.Precomputed.CRTValues) != l-2 {
+			return fmt.Errorf(`invalid rsa.PrivateKey: multi-prime keys require precomputed CRT values (call Precompute())`)
+		}
+		oth := make(RSAOtherPrimes, l-2)
+		for i, prime := range rawKey.Primes[2:] {
+			crt := rawKey.Precomputed.CRTValues[i]
+			r, err := bigIntToBytes(prime)
+			if err != nil {
+				return fmt.Errorf(`invalid rsa.PrivateKey: %w`, err)
+			}
+			d, err := bigIntToBytes(crt.Exp)
+			if err != nil {
+				return fmt.Errorf(`invalid rsa.PrivateKey: %w`, err)
+			}
+			t, err := bigIntToBytes(crt.Coeff)
+			if err != nil {
+				return fmt.Errorf(`invalid rsa.PrivateKey: %w`, err)
+			}
+			oth[i] = RSAOtherPrimeInfo{R: r, D: d, T: t}
+		}
+		k.oth = &oth
+	}
+
 	// public key part
 	n, e, err := rsaPublicKeyByteValuesFromRaw(&rawKey.PublicKey)
 	if err != nil {
@@ -147,7 +172,22 @@ func (k *rsaPrivateKey) Raw(v interface{}) error {
 	}
 	key.Precomputed.CRTValues = []rsa.CRTValue{}
 
-	return blackmagic.AssignIfCompatible(v, &key)
+	if k.oth != nil {
+		// r is the product of all the preceding primes
+		r := new(big.Int).Mul(&p, &q)
+		for _, info := range *(k.oth) {
+			prime := new(big.Int).SetBytes(info.R)
+			key.Primes = append(key.Primes, prime)
+			key.Precomputed.CRTValues = append(key.Precomputed.CRTValues, rsa.CRTValue{
+				Exp:   new(big.Int).SetBytes(info.D),
+				Coeff: new(big.Int).SetBytes(info.T),
+				R:     new(big.Int).Set(r),
+			})
+			r.Mul(r, prime)
+		}
+	}
+
+	return assignRawKey(v, &key)
 }
 
 // Raw takes the values stored in the Key object, and creates the
@@ -168,7 +208,7 @@ func (k *rsaPublicKey) Raw(v interface{}) error {
 	key.N = n
 	key.E = int(e.Int64())
 
-	return blackmagic.AssignIfCompatible(v, &key)
+	return assignRawKey(v, &key)
 }
 
 func makeRSAPublicKey(v interface {
@@ -179,7 +219,7 @@ func makeRSAPublicKey(v interface {
 	// Iterate and copy everything except for the bits that should not be in the public key
 	for _, pair := range v.makePairs() {
 		switch pair.Key {
-		case RSADKey, RSADPKey, RSADQKey, RSAPKey, RSAQKey, RSAQIKey:
+		case RSADKey, RSADPKey, RSADQKey, RSAPKey, RSAQKey, RSAQIKey, RSAOthKey:
 			continue
 		default:
 			//nolint:forcetypeassert
@@ -241,3 +281,91 @@ func rsaThumbprint(hash crypto.Hash, key *rsa.PublicKey) ([]byte, error) {
 	}
 	return h.Sum(nil), nil
 }
+
+// RSAOtherPrimeInfo represents an element of the "oth" (Other Primes Info)
+// parameter of multi-prime RSA private keys (RFC 7518 section 6.3.2.7)
+type RSAOtherPrimeInfo struct {
+	R []byte // Prime Factor
+	D []byte // Factor CRT Exponent
+	T []byte // Factor CRT Coefficient
+}
+
+// RSAOtherPrimes is the list of RSAOtherPrimeInfo stored in the "oth"
+// parameter of multi-prime RSA private keys
+type RSAOtherPrimes []RSAOtherPrimeInfo
+
+func (info RSAOtherPrimeInfo) MarshalJSON() ([]byte, error) {
+	buf := pool.GetBytesBuffer()
+	defer pool.ReleaseBytesBuffer(buf)
+
+	buf.WriteString(`{"d":"`)
+	buf.WriteString(base64.EncodeToString(info.D))
+	buf.WriteString(`","r":"`)
+	buf.WriteString(base64.EncodeToString(info.R))
+	buf.WriteString(`","t":"`)
+	buf.WriteString(base64.EncodeToString(info.T))
+	buf.WriteString(`"}`)
+
+	ret := make([]byte, buf.Len())
+	copy(ret, buf.Bytes())
+	return ret, nil
+}
+
+func (info *RSAOtherPrimeInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		R string `json:"r"`
+		D string `json:"d"`
+		T string `json:"t"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf(`failed to unmarshal other prime info: %w`, err)
+	}
+
+	var decoded RSAOtherPrimeInfo
+	for _, field := range []struct {
+		name string
+		src  string
+		dst  *[]byte
+	}{
+		{name: `r`, src: raw.R, dst: &decoded.R},
+		{name: `d`, src: raw.D, dst: &decoded.D},
+		{name: `t`, src: raw.T, dst: &decoded.T},
+	} {
+		if field.src == "" {
+			return fmt.Errorf(`required field %q is missing in other prime info`, field.name)
+		}
+		v, err := base64.DecodeString(field.src)
+		if err != nil {
+			return fmt.Errorf(`failed to decode field %q in other prime info: %w`, field.name, err)
+		}
+		*field.dst = v
+	}
+	*info = decoded
+	return nil
+}
+
+func (oth *RSAOtherPrimes) Accept(v interface{}) error {
+	switch x := v.(type) {
+	case RSAOtherPrimes:
+		*oth = x
+		return nil
+	case []RSAOtherPrimeInfo:
+		*oth = RSAOtherPrimes(x)
+		return nil
+	case []interface{}:
+		// e.g. values that were decoded without knowledge of the "oth" field.
+		// Round-trip through JSON to decode them properly
+		buf, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Errorf(`failed to marshal other primes info: %w`, err)
+		}
+		var list RSAOtherPrimes
+		if err := json.Unmarshal(buf, &list); err != nil {
+			return fmt.Errorf(`failed to unmarshal other primes info: %w`, err)
+		}
+		*oth = list
+		return nil
+	default:
+		return fmt.Errorf(`invalid value %T`, v)
+	}
+}
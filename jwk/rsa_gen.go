@@ -20,14 +20,15 @@ import (
 )
 
 const (
-	RSADKey  = "d"
-	RSADPKey = "dp"
-	RSADQKey = "dq"
-	RSAEKey  = "e"
-	RSANKey  = "n"
-	RSAPKey  = "p"
-	RSAQIKey = "qi"
-	RSAQKey  = "q"
+	RSADKey   = "d"
+	RSADPKey  = "dp"
+	RSADQKey  = "dq"
+	RSAEKey   = "e"
+	RSANKey   = "n"
+	RSAOthKey = "oth"
+	RSAPKey   = "p"
+	RSAQIKey  = "qi"
+	RSAQKey   = "q"
 )
 
 type RSAPublicKey interface {
@@ -423,6 +424,9 @@ LOOP:
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AlgorithmKey, err)
 				}
 				alg := jwa.KeyAlgorithmFrom(s)
+				if _, ok := alg.(jwa.InvalidKeyAlgorithm); ok && jwa.IsStrict() {
+					return fmt.Errorf(`invalid value for key %s: unknown algorithm %q`, AlgorithmKey, s)
+				}
 				h.algorithm = &alg
 			case RSAEKey:
 				if err := json.AssignNextBytesToken(&h.e, dec); err != nil {
@@ -565,6 +569,7 @@ type RSAPrivateKey interface {
 	DQ() []byte
 	E() []byte
 	N() []byte
+	OtherPrimes() RSAOtherPrimes
 	P() []byte
 	Q() []byte
 	QI() []byte
@@ -580,6 +585,7 @@ type rsaPrivateKey struct {
 	keyOps                 *KeyOperationList // https://tools.ietf.org/html/rfc7517#section-4.3
 	keyUsage               *string           // https://tools.ietf.org/html/rfc7517#section-4.2
 	n                      []byte
+	oth                    *RSAOtherPrimes
 	p                      []byte
 	q                      []byte
 	qi                     []byte
@@ -654,6 +660,13 @@ func (h *rsaPrivateKey) N() []byte {
 	return h.n
 }
 
+func (h *rsaPrivateKey) OtherPrimes() RSAOtherPrimes {
+	if h.oth != nil {
+		return *(h.oth)
+	}
+	return nil
+}
+
 func (h *rsaPrivateKey) P() []byte {
 	return h.p
 }
@@ -724,6 +737,9 @@ func (h *rsaPrivateKey) makePairs() []*HeaderPair {
 	if h.n != nil {
 		pairs = append(pairs, &HeaderPair{Key: RSANKey, Value: h.n})
 	}
+	if h.oth != nil {
+		pairs = append(pairs, &HeaderPair{Key: RSAOthKey, Value: *(h.oth)})
+	}
 	if h.p != nil {
 		pairs = append(pairs, &HeaderPair{Key: RSAPKey, Value: h.p})
 	}
@@ -806,6 +822,11 @@ func (h *rsaPrivateKey) Get(name string) (interface{}, bool) {
 			return nil, false
 		}
 		return h.n, true
+	case RSAOthKey:
+		if h.oth == nil {
+			return nil, false
+		}
+		return *(h.oth), true
 	case RSAPKey:
 		if h.p == nil {
 			return nil, false
@@ -928,6 +949,13 @@ func (h *rsaPrivateKey) setNoLock(name string, value interface{}) error {
 			return nil
 		}
 		return fmt.Errorf(`invalid value for %s key: %T`, RSANKey, value)
+	case RSAOthKey:
+		var acceptor RSAOtherPrimes
+		if err := acceptor.Accept(value); err != nil {
+			return fmt.Errorf(`invalid value for %s key: %w`, RSAOthKey, err)
+		}
+		h.oth = &acceptor
+		return nil
 	case RSAPKey:
 		if v, ok := value.([]byte); ok {
 			h.p = v
@@ -1001,6 +1029,8 @@ func (k *rsaPrivateKey) Remove(key string) error {
 		k.keyUsage = nil
 	case RSANKey:
 		k.n = nil
+	case RSAOthKey:
+		k.oth = nil
 	case RSAPKey:
 		k.p = nil
 	case RSAQKey:
@@ -1049,6 +1079,7 @@ func (h *rsaPrivateKey) UnmarshalJSON(buf []byte) error {
 	h.keyOps = nil
 	h.keyUsage = nil
 	h.n = nil
+	h.oth = nil
 	h.p = nil
 	h.q = nil
 	h.qi = nil
@@ -1088,6 +1119,9 @@ LOOP:
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AlgorithmKey, err)
 				}
 				alg := jwa.KeyAlgorithmFrom(s)
+				if _, ok := alg.(jwa.InvalidKeyAlgorithm); ok && jwa.IsStrict() {
+					return fmt.Errorf(`invalid value for key %s: unknown algorithm %q`, AlgorithmKey, s)
+				}
 				h.algorithm = &alg
 			case RSADKey:
 				if err := json.AssignNextBytesToken(&h.d, dec); err != nil {
@@ -1123,6 +1157,12 @@ LOOP:
 				if err := json.AssignNextBytesToken(&h.n, dec); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, RSANKey, err)
 				}
+			case RSAOthKey:
+				var decoded RSAOtherPrimes
+				if err := dec.Decode(&decoded); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, RSAOthKey, err)
+				}
+				h.oth = &decoded
 			case RSAPKey:
 				if err := json.AssignNextBytesToken(&h.p, dec); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, RSAPKey, err)
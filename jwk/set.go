@@ -200,11 +200,15 @@ func (s *set) UnmarshalJSON(data []byte) error {
 
 	var options []ParseOption
 	var ignoreParseError bool
+	var filter map[string]struct{}
 	if dc := s.dc; dc != nil {
 		if localReg := dc.Registry(); localReg != nil {
 			options = append(options, withLocalRegistry(localReg))
 		}
 		ignoreParseError = dc.IgnoreParseError()
+		if f, ok := dc.(keyIDFilterer); ok {
+			filter = f.KeyIDFilter()
+		}
 	}
 
 	var sawKeysField bool
@@ -229,21 +233,11 @@ LOOP:
 			switch tok {
 			case "keys":
 				sawKeysField = true
-				var list []json.RawMessage
-				if err := dec.Decode(&list); err != nil {
-					return fmt.Errorf(`failed to decode "keys": %w`, err)
-				}
-
-				for i, keysrc := range list {
-					key, err := ParseKey(keysrc, options...)
-					if err != nil {
-						if !ignoreParseError {
-							return fmt.Errorf(`failed to decode key #%d in "keys": %w`, i, err)
-						}
-						continue
-					}
-					s.keys = append(s.keys, key)
+				keys, err := decodeKeys(dec, options, ignoreParseError, filter)
+				if err != nil {
+					return err
 				}
+				s.keys = keys
 			default:
 				var v interface{}
 				if err := dec.Decode(&v); err != nil {
@@ -264,11 +258,82 @@ LOOP:
 		if err != nil {
 			return fmt.Errorf(`failed to parse sole key in key set`)
 		}
-		s.keys = append(s.keys, key)
+		if keyIDAllowed(filter, key.KeyID()) {
+			s.keys = append(s.keys, key)
+		}
 	}
 	return nil
 }
 
+// keyIDFilterer is implemented by DecodeCtx objects that restrict
+// the keys in a JWK Set by their key IDs
+type keyIDFilterer interface {
+	KeyIDFilter() map[string]struct{}
+}
+
+func keyIDAllowed(filter map[string]struct{}, kid string) bool {
+	if filter == nil {
+		return true
+	}
+	_, ok := filter[kid]
+	return ok
+}
+
+// decodeKeys decodes the value of the "keys" field of a JWK Set.
+// The elements of the array are decoded one at a time, so that
+// only a single key's worth of raw JSON is held in memory at once.
+// Keys whose IDs are not listed in `filter` (if non-nil) are
+// skipped without being parsed.
+func decodeKeys(dec *json.Decoder, options []ParseOption, ignoreParseError bool, filter map[string]struct{}) ([]Key, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf(`failed to decode "keys": %w`, err)
+	}
+	if tok == nil { // "keys": null
+		return nil, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf(`failed to decode "keys": expected '[', but got %v`, tok)
+	}
+
+	var keys []Key
+	for i := 0; dec.More(); i++ {
+		var keysrc json.RawMessage
+		if err := dec.Decode(&keysrc); err != nil {
+			return nil, fmt.Errorf(`failed to decode key #%d in "keys": %w`, i, err)
+		}
+
+		if filter != nil {
+			// Peek at "kid" before doing the (much more expensive) full
+			// parse. If this fails, ParseKey below reports the error
+			var hint struct {
+				KeyID string `json:"kid"`
+			}
+			if err := json.Unmarshal(keysrc, &hint); err == nil && !keyIDAllowed(filter, hint.KeyID) {
+				continue
+			}
+		}
+
+		key, err := ParseKey(keysrc, options...)
+		if err != nil {
+			if !ignoreParseError {
+				return nil, fmt.Errorf(`failed to decode key #%d in "keys": %w`, i, err)
+			}
+			continue
+		}
+		if !keyIDAllowed(filter, key.KeyID()) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	// consume the closing ']'
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf(`failed to decode "keys": %w`, err)
+	}
+	return keys, nil
+}
+
 func (s *set) LookupKeyID(kid string) (Key, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
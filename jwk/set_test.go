@@ -1,11 +1,16 @@
 package jwk_test
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSet(t *testing.T) {
@@ -70,3 +75,67 @@ func TestSet(t *testing.T) {
 		return
 	}
 }
+
+func TestParseStream(t *testing.T) {
+	const count = 100
+	set := jwk.NewSet()
+	for i := 0; i < count; i++ {
+		key, err := jwxtest.GenerateSymmetricJwk()
+		require.NoError(t, err, `jwxtest.GenerateSymmetricJwk should succeed`)
+		require.NoError(t, key.Set(jwk.KeyIDKey, fmt.Sprintf(`key-%d`, i)), `key.Set should succeed`)
+		require.NoError(t, set.AddKey(key), `set.AddKey should succeed`)
+	}
+	require.NoError(t, set.Set(`iss`, `https://example.com`), `set.Set should succeed`)
+
+	buf, err := json.Marshal(set)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	t.Run("all keys", func(t *testing.T) {
+		parsed, err := jwk.ParseStream(bytes.NewReader(buf))
+		require.NoError(t, err, `jwk.ParseStream should succeed`)
+		require.Equal(t, count, parsed.Len())
+		v, ok := parsed.Get(`iss`)
+		require.True(t, ok, `parsed.Get("iss") should succeed`)
+		require.Equal(t, `https://example.com`, v)
+	})
+	t.Run("filter by kid", func(t *testing.T) {
+		for _, parse := range []func(...jwk.ParseOption) (jwk.Set, error){
+			func(options ...jwk.ParseOption) (jwk.Set, error) {
+				return jwk.ParseStream(bytes.NewReader(buf), options...)
+			},
+			func(options ...jwk.ParseOption) (jwk.Set, error) {
+				return jwk.Parse(buf, options...)
+			},
+		} {
+			parsed, err := parse(jwk.WithKeyIDFilter(`key-3`, `key-42`, `no-such-key`))
+			require.NoError(t, err, `parsing should succeed`)
+			require.Equal(t, 2, parsed.Len())
+			_, ok := parsed.LookupKeyID(`key-3`)
+			require.True(t, ok, `key-3 should be in the set`)
+			_, ok = parsed.LookupKeyID(`key-42`)
+			require.True(t, ok, `key-42 should be in the set`)
+		}
+	})
+	t.Run("filtered keys are not parsed", func(t *testing.T) {
+		src := `{"keys":[{"kid":"bad","kty":"bogus"},{"kid":"good","kty":"oct","k":"c2VjcmV0"}]}`
+		_, err := jwk.ParseStream(strings.NewReader(src))
+		require.Error(t, err, `jwk.ParseStream should fail without a filter`)
+
+		parsed, err := jwk.ParseStream(strings.NewReader(src), jwk.WithKeyIDFilter(`good`))
+		require.NoError(t, err, `jwk.ParseStream should succeed`)
+		require.Equal(t, 1, parsed.Len())
+	})
+	t.Run("errors", func(t *testing.T) {
+		for _, src := range []string{
+			`{"kty":"oct","k":"c2VjcmV0"}`,
+			`[]`,
+			`{"keys":[{"kty":"oct","k":"c2VjcmV0"}`,
+			`{"keys":{}}`,
+		} {
+			_, err := jwk.ParseStream(strings.NewReader(src))
+			require.Error(t, err, `jwk.ParseStream should fail for %s`, src)
+		}
+		_, err := jwk.ParseStream(bytes.NewReader(buf), jwk.WithPEM(true))
+		require.Error(t, err, `jwk.ParseStream should fail with jwk.WithPEM`)
+	})
+}
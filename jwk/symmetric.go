@@ -4,7 +4,6 @@ import (
 	"crypto"
 	"fmt"
 
-	"github.com/lestrrat-go/blackmagic"
 	"github.com/lestrrat-go/jwx/v2/internal/base64"
 )
 
@@ -26,7 +25,7 @@ func (k *symmetricKey) FromRaw(rawKey []byte) error {
 func (k *symmetricKey) Raw(v interface{}) error {
 	k.mu.RLock()
 	defer k.mu.RUnlock()
-	return blackmagic.AssignIfCompatible(v, k.octets)
+	return assignRawKey(v, k.octets)
 }
 
 // Thumbprint returns the JWK thumbprint using the indicated
@@ -392,6 +392,9 @@ LOOP:
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AlgorithmKey, err)
 				}
 				alg := jwa.KeyAlgorithmFrom(s)
+				if _, ok := alg.(jwa.InvalidKeyAlgorithm); ok && jwa.IsStrict() {
+					return fmt.Errorf(`invalid value for key %s: unknown algorithm %q`, AlgorithmKey, s)
+				}
 				h.algorithm = &alg
 			case KeyIDKey:
 				if err := json.AssignNextStringToken(&h.keyID, dec); err != nil {
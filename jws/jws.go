@@ -28,6 +28,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -42,6 +43,7 @@ import (
 	"github.com/lestrrat-go/jwx/v2/internal/pool"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/observe"
 	"github.com/lestrrat-go/jwx/v2/x25519"
 )
 
@@ -238,6 +240,16 @@ func Sign(payload []byte, options ...SignOption) ([]byte, error) {
 	}
 }
 
+var errVerificationFailed = errors.New(`could not verify message using any of the signatures or keys`)
+
+// ErrVerificationFailed returns the opaque error value that is returned
+// when `jws.Verify` fails because none of the signatures in the message
+// could be verified using the provided keys. Use `errors.Is` to check
+// for it, as it may be wrapped by the caller (e.g. `jwt.Parse`).
+func ErrVerificationFailed() error {
+	return errVerificationFailed
+}
+
 var allowNoneWhitelist = jwk.WhitelistFunc(func(string) bool {
 	return false
 })
@@ -251,7 +263,15 @@ var allowNoneWhitelist = jwk.WhitelistFunc(func(string) bool {
 // `Verifier` in `verify` subpackage, and call `Verify` method on it.
 // If you need to access signatures and JOSE headers in a JWS message,
 // use `Parse` function to get `Message` object.
-func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
+//
+// Keys whose type is not compatible with the signature algorithm (for
+// example, an RSA public key that is to be used with HS256, or a []byte
+// that is to be used with RS256) are never used for verification, which
+// prevents algorithm confusion attacks.
+//
+// If none of the signatures can be verified, the returned error
+// matches `jws.ErrVerificationFailed()` when checked using `errors.Is`.
+func Verify(buf []byte, options ...VerifyOption) (_ []byte, err error) {
 	var dst *Message
 	var detachedPayload []byte
 	var keyProviders []KeyProvider
@@ -282,11 +302,16 @@ func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
 			keyUsed = option.Value()
 		case identContext{}:
 			ctx = option.Value().(context.Context)
+		case identAcceptableSkew{}:
+			return nil, fmt.Errorf(`jws.WithAcceptableSkew() may only be passed to jws.VerifyKeySet() or jws.NewKeySetVerifier()`)
 		default:
 			return nil, fmt.Errorf(`invalid jws.VerifyOption %q passed`, `With`+strings.TrimPrefix(fmt.Sprintf(`%T`, option.Ident()), `jws.ident`))
 		}
 	}
 
+	ctx, span := observe.Start(ctx, observe.JWSVerify)
+	defer func() { span.End(err) }()
+
 	if len(keyProviders) < 1 {
 		return nil, fmt.Errorf(`jws.Verify: no key providers have been provided (see jws.WithKey(), jws.WithKeySet(), jws.WithVerifyAuto(), and jws.WithKeyProvider()`)
 	}
@@ -297,6 +322,10 @@ func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
 	}
 	defer msg.clearRaw()
 
+	if len(msg.signatures) > 0 {
+		setSpanAttributes(span, msg.signatures[0].ProtectedHeaders())
+	}
+
 	if detachedPayload != nil {
 		if len(msg.payload) != 0 {
 			return nil, fmt.Errorf(`can't specify detached payload for JWS with payload`)
@@ -316,6 +345,7 @@ func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
 	verifyBuf := pool.GetBytesBuffer()
 	defer pool.ReleaseBytesBuffer(verifyBuf)
 
+	var incompatible error
 	for i, sig := range msg.signatures {
 		verifyBuf.Reset()
 
@@ -352,6 +382,14 @@ func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
 				//nolint:forcetypeassert
 				alg := pair.alg.(jwa.SignatureAlgorithm)
 				key := pair.key
+
+				// Reject keys that do not match the algorithm before
+				// any cryptographic operation takes place
+				if err := checkKeyCompatibility(alg, key); err != nil {
+					incompatible = err
+					continue
+				}
+
 				verifier, err := NewVerifier(alg)
 				if err != nil {
 					return nil, fmt.Errorf(`failed to create verifier for algorithm %q: %w`, alg, err)
@@ -367,6 +405,7 @@ func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
 					}
 				}
 
+				setSpanAttributes(span, sig.ProtectedHeaders())
 				if dst != nil {
 					*(dst) = *msg
 				}
@@ -375,7 +414,23 @@ func Verify(buf []byte, options ...VerifyOption) ([]byte, error) {
 			}
 		}
 	}
-	return nil, fmt.Errorf(`could not verify message using any of the signatures or keys`)
+
+	if incompatible != nil {
+		return nil, fmt.Errorf(`%w: %s`, errVerificationFailed, incompatible)
+	}
+	return nil, errVerificationFailed
+}
+
+func setSpanAttributes(span observe.Span, h Headers) {
+	if h == nil {
+		return
+	}
+	if alg := h.Algorithm(); alg != "" {
+		span.SetAttribute(observe.AttrAlgorithm, alg.String())
+	}
+	if kid := h.KeyID(); kid != "" {
+		span.SetAttribute(observe.AttrKeyID, kid)
+	}
 }
 
 // get the value of b64 header field.
@@ -431,17 +486,18 @@ func Parse(src []byte, _ ...ParseOption) (*Message, error) {
 	return nil, fmt.Errorf(`invalid byte sequence`)
 }
 
-// Parse parses contents from the given source and creates a jws.Message
-// struct. The input can be in either compact or full JSON serialization.
-func ParseString(src string) (*Message, error) {
-	return Parse([]byte(src))
+// ParseString is the same as Parse, but takes a string.
+func ParseString(src string, options ...ParseOption) (*Message, error) {
+	return Parse([]byte(src), options...)
 }
 
-// Parse parses contents from the given source and creates a jws.Message
-// struct. The input can be in either compact or full JSON serialization.
-func ParseReader(src io.Reader) (*Message, error) {
+// ParseReader is the same as Parse, but takes an io.Reader. The message
+// is decoded as it is read, without reading all of the contents of the
+// reader into memory first, so it can be used to read messages directly
+// from sources such as HTTP request bodies.
+func ParseReader(src io.Reader, options ...ParseOption) (*Message, error) {
 	if data, ok := readAll(src); ok {
-		return Parse(data)
+		return Parse(data, options...)
 	}
 
 	rdr := bufio.NewReader(src)
@@ -670,20 +726,22 @@ func init() {
 	rawKeyToKeyType[reflect.TypeOf(ecdsa.PublicKey{})] = jwa.EC
 	rawKeyToKeyType[reflect.TypeOf((*ecdsa.PublicKey)(nil))] = jwa.EC
 
-	addAlgorithmForKeyType(jwa.OKP, jwa.EdDSA)
-	for _, alg := range []jwa.SignatureAlgorithm{jwa.HS256, jwa.HS384, jwa.HS512} {
-		addAlgorithmForKeyType(jwa.OctetSeq, alg)
-	}
-	for _, alg := range []jwa.SignatureAlgorithm{jwa.RS256, jwa.RS384, jwa.RS512, jwa.PS256, jwa.PS384, jwa.PS512} {
-		addAlgorithmForKeyType(jwa.RSA, alg)
-	}
-	for _, alg := range []jwa.SignatureAlgorithm{jwa.ES256, jwa.ES384, jwa.ES512} {
-		addAlgorithmForKeyType(jwa.EC, alg)
+	for _, alg := range []jwa.SignatureAlgorithm{
+		jwa.EdDSA,
+		jwa.HS256, jwa.HS384, jwa.HS512,
+		jwa.RS256, jwa.RS384, jwa.RS512, jwa.PS256, jwa.PS384, jwa.PS512,
+		jwa.ES256, jwa.ES384, jwa.ES512,
+	} {
+		addAlgorithm(alg)
 	}
 }
 
-func addAlgorithmForKeyType(kty jwa.KeyType, alg jwa.SignatureAlgorithm) {
-	keyTypeToAlgorithms[kty] = append(keyTypeToAlgorithms[kty], alg)
+// addAlgorithm registers `alg` as an algorithm that can be used with the
+// key types reported by `(jwa.SignatureAlgorithm).KeyTypeFor()`
+func addAlgorithm(alg jwa.SignatureAlgorithm) {
+	for _, kty := range alg.KeyTypeFor() {
+		keyTypeToAlgorithms[kty] = append(keyTypeToAlgorithms[kty], alg)
+	}
 }
 
 // AlgorithmsForKey returns the possible signature algorithms that can
@@ -691,25 +749,57 @@ func addAlgorithmForKeyType(kty jwa.KeyType, alg jwa.SignatureAlgorithm) {
 // for verification purposes, as this is the only usage where one may need
 // dynamically figure out which method to use.
 func AlgorithmsForKey(key interface{}) ([]jwa.SignatureAlgorithm, error) {
-	var kty jwa.KeyType
+	kty, ok := keyTypeOf(key)
+	if !ok {
+		return nil, fmt.Errorf(`invalid key %T`, key)
+	}
+
+	algs, ok := keyTypeToAlgorithms[kty]
+	if !ok {
+		return nil, fmt.Errorf(`invalid key type %q`, kty)
+	}
+	return algs, nil
+}
+
+func keyTypeOf(key interface{}) (jwa.KeyType, bool) {
 	switch key := key.(type) {
 	case jwk.Key:
-		kty = key.KeyType()
+		return key.KeyType(), true
 	case rsa.PublicKey, *rsa.PublicKey, rsa.PrivateKey, *rsa.PrivateKey:
-		kty = jwa.RSA
+		return jwa.RSA, true
 	case ecdsa.PublicKey, *ecdsa.PublicKey, ecdsa.PrivateKey, *ecdsa.PrivateKey:
-		kty = jwa.EC
+		return jwa.EC, true
 	case ed25519.PublicKey, ed25519.PrivateKey, x25519.PublicKey, x25519.PrivateKey:
-		kty = jwa.OKP
+		return jwa.OKP, true
 	case []byte:
-		kty = jwa.OctetSeq
+		return jwa.OctetSeq, true
 	default:
-		return nil, fmt.Errorf(`invalid key %T`, key)
+		return jwa.InvalidKeyType, false
 	}
+}
 
-	algs, ok := keyTypeToAlgorithms[kty]
+// checkKeyCompatibility makes sure that the key can be used with the
+// signature algorithm, e.g. that a symmetric key is not used to verify
+// a signature using RS256, or that a public key is not used as the
+// secret for HS256 ("algorithm confusion").
+//
+// Algorithms and key types that we do not know about (e.g. those that
+// were added via `jws.RegisterVerifier()`) are left for the verifier to
+// deal with.
+func checkKeyCompatibility(alg jwa.SignatureAlgorithm, key interface{}) error {
+	kty, ok := keyTypeOf(key)
 	if !ok {
-		return nil, fmt.Errorf(`invalid key type %q`, kty)
+		return nil
 	}
-	return algs, nil
+
+	expected := alg.KeyTypeFor()
+	if len(expected) == 0 {
+		return nil
+	}
+	for _, candidate := range expected {
+		if candidate == kty {
+			return nil
+		}
+	}
+	return fmt.Errorf(`key of type %q (%T) cannot be used with algorithm %q`, kty, key, alg)
 }
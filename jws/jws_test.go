@@ -7,9 +7,11 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/hmac"
 	"crypto/rsa"
 	"crypto/sha512"
 	"encoding/asn1"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -1733,6 +1735,30 @@ func TestJKU(t *testing.T) {
 					}
 				},
 			},
+			{
+				Name: "Accepted by pinned thumbprints",
+				FetchOptions: func() []jwk.FetchOption {
+					tp, err := keys[1].Thumbprint(crypto.SHA256)
+					require.NoError(t, err, `key.Thumbprint should succeed`)
+					return []jwk.FetchOption{
+						jwk.WithFetchWhitelist(jwk.InsecureWhitelist{}),
+						jwk.WithPinnedThumbprints(crypto.SHA256, base64.EncodeToString(tp)),
+					}
+				},
+			},
+			{
+				// The key that signed the message is in the JWKS, but is not pinned
+				Name:  "Rejected by pinned thumbprints",
+				Error: true,
+				FetchOptions: func() []jwk.FetchOption {
+					tp, err := unusedKeys[0].Thumbprint(crypto.SHA256)
+					require.NoError(t, err, `key.Thumbprint should succeed`)
+					return []jwk.FetchOption{
+						jwk.WithFetchWhitelist(jwk.InsecureWhitelist{}),
+						jwk.WithPinnedThumbprints(crypto.SHA256, base64.EncodeToString(tp)),
+					}
+				},
+			},
 		}
 
 		for _, tc := range testcases {
@@ -1951,3 +1977,349 @@ func TestGH840(t *testing.T) {
 	_, err = jwt.Parse(signed, jwt.WithKey(jwa.ES256, pubkey))
 	require.Error(t, err, `jwt.Parse should FAIL`) // pubkey's X/Y is not on the curve
 }
+
+func TestSignedKeySet(t *testing.T) {
+	anchor, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	anchorPub, err := anchor.PublicKey()
+	require.NoError(t, err, `anchor.PublicKey should succeed`)
+
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(key), `set.AddKey should succeed`)
+
+	signed, err := jws.SignKeySet(set, jws.WithKey(jwa.ES256, anchor))
+	require.NoError(t, err, `jws.SignKeySet should succeed`)
+
+	t.Run("VerifyKeySet", func(t *testing.T) {
+		verified, err := jws.VerifyKeySet(signed, jws.WithKey(jwa.ES256, anchorPub))
+		require.NoError(t, err, `jws.VerifyKeySet should succeed`)
+		require.Equal(t, 1, verified.Len(), `there should be 1 key`)
+
+		got, _ := verified.Key(0)
+		_, hasD := got.Get(`d`)
+		require.False(t, hasD, `published key should not contain private parameters`)
+
+		msg, err := jws.Parse(signed)
+		require.NoError(t, err, `jws.Parse should succeed`)
+		require.Equal(t, jws.KeySetType, msg.Signatures()[0].ProtectedHeaders().Type(), `typ should be jwk-set+jwt`)
+	})
+	t.Run("SignKeySet with symmetric key", func(t *testing.T) {
+		oct, err := jwxtest.GenerateSymmetricJwk()
+		require.NoError(t, err, `jwxtest.GenerateSymmetricJwk should succeed`)
+		octset := jwk.NewSet()
+		require.NoError(t, octset.AddKey(oct), `octset.AddKey should succeed`)
+
+		_, err = jws.SignKeySet(octset, jws.WithKey(jwa.ES256, anchor))
+		require.Error(t, err, `jws.SignKeySet should fail`)
+	})
+	t.Run("VerifyKeySet with exp", func(t *testing.T) {
+		expset := jwk.NewSet()
+		require.NoError(t, expset.AddKey(key), `expset.AddKey should succeed`)
+		require.NoError(t, expset.Set(`exp`, time.Now().Add(-time.Hour).Unix()), `expset.Set should succeed`)
+
+		expired, err := jws.SignKeySet(expset, jws.WithKey(jwa.ES256, anchor))
+		require.NoError(t, err, `jws.SignKeySet should succeed`)
+		_, err = jws.VerifyKeySet(expired, jws.WithKey(jwa.ES256, anchorPub))
+		require.Error(t, err, `jws.VerifyKeySet should fail for expired key set`)
+
+		require.NoError(t, expset.Set(`exp`, time.Now().Add(time.Hour).Unix()), `expset.Set should succeed`)
+		fresh, err := jws.SignKeySet(expset, jws.WithKey(jwa.ES256, anchor))
+		require.NoError(t, err, `jws.SignKeySet should succeed`)
+		verified, err := jws.VerifyKeySet(fresh, jws.WithKey(jwa.ES256, anchorPub))
+		require.NoError(t, err, `jws.VerifyKeySet should succeed`)
+		_, ok := verified.Get(`exp`)
+		require.True(t, ok, `exp should be preserved`)
+	})
+	t.Run("VerifyKeySet with acceptable skew", func(t *testing.T) {
+		skewset := jwk.NewSet()
+		require.NoError(t, skewset.AddKey(key), `skewset.AddKey should succeed`)
+		require.NoError(t, skewset.Set(`iat`, time.Now().Add(30*time.Second).Unix()), `skewset.Set should succeed`)
+		require.NoError(t, skewset.Set(`exp`, time.Now().Add(-30*time.Second).Unix()), `skewset.Set should succeed`)
+
+		signed, err := jws.SignKeySet(skewset, jws.WithKey(jwa.ES256, anchor))
+		require.NoError(t, err, `jws.SignKeySet should succeed`)
+		_, err = jws.VerifyKeySet(signed, jws.WithKey(jwa.ES256, anchorPub))
+		require.Error(t, err, `jws.VerifyKeySet should fail without skew`)
+		_, err = jws.VerifyKeySet(signed, jws.WithKey(jwa.ES256, anchorPub), jws.WithAcceptableSkew(time.Minute))
+		require.NoError(t, err, `jws.VerifyKeySet should succeed within the acceptable skew`)
+		_, err = jws.VerifyKeySet(signed, jws.WithKey(jwa.ES256, anchorPub), jws.WithAcceptableSkew(-time.Minute))
+		require.Error(t, err, `jws.VerifyKeySet should fail for negative skew`)
+
+		_, err = jws.Verify(signed, jws.WithKey(jwa.ES256, anchorPub), jws.WithAcceptableSkew(time.Minute))
+		require.Error(t, err, `jws.Verify should reject jws.WithAcceptableSkew()`)
+	})
+	t.Run("VerifyKeySet with wrong typ", func(t *testing.T) {
+		payload, err := json.Marshal(set)
+		require.NoError(t, err, `json.Marshal should succeed`)
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, `JWT`), `hdrs.Set should succeed`)
+		signed, err := jws.Sign(payload, jws.WithKey(jwa.ES256, anchor, jws.WithProtectedHeaders(hdrs)))
+		require.NoError(t, err, `jws.Sign should succeed`)
+		_, err = jws.VerifyKeySet(signed, jws.WithKey(jwa.ES256, anchorPub))
+		require.Error(t, err, `jws.VerifyKeySet should fail`)
+	})
+	t.Run("VerifyKeySet with wrong anchor", func(t *testing.T) {
+		other, err := jwxtest.GenerateEcdsaPublicJwk()
+		require.NoError(t, err, `jwxtest.GenerateEcdsaPublicJwk should succeed`)
+		_, err = jws.VerifyKeySet(signed, jws.WithKey(jwa.ES256, other))
+		require.Error(t, err, `jws.VerifyKeySet should fail`)
+	})
+	t.Run("jwk.Fetch with jwk.WithKeySetVerifier", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(`Content-Type`, `application/jwk-set+jwt`)
+			w.WriteHeader(http.StatusOK)
+			w.Write(signed)
+		}))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := jwk.Fetch(ctx, srv.URL)
+		require.Error(t, err, `jwk.Fetch without verifier should fail`)
+
+		fetched, err := jwk.Fetch(ctx, srv.URL, jwk.WithKeySetVerifier(jws.NewKeySetVerifier(jws.WithKey(jwa.ES256, anchorPub))))
+		require.NoError(t, err, `jwk.Fetch should succeed`)
+		require.Equal(t, 1, fetched.Len(), `there should be 1 key`)
+
+		c := jwk.NewCache(ctx)
+		require.NoError(t, c.Register(srv.URL, jwk.WithKeySetVerifier(jws.NewKeySetVerifier(jws.WithKey(jwa.ES256, anchorPub)))), `c.Register should succeed`)
+		cached, err := c.Refresh(ctx, srv.URL)
+		require.NoError(t, err, `c.Refresh should succeed`)
+		require.Equal(t, 1, cached.Len(), `there should be 1 key`)
+	})
+}
+
+func TestVerifyWithKeySetProvider(t *testing.T) {
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+	require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+	pubkey, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pubkey), `set.AddKey should succeed`)
+
+	signed, err := jws.Sign([]byte(`Hello, World!`), jws.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jws.Sign should succeed`)
+
+	t.Run("static provider", func(t *testing.T) {
+		payload, err := jws.Verify(signed, jws.WithKeySetProvider(jwk.NewStaticProvider(set)))
+		require.NoError(t, err, `jws.Verify should succeed`)
+		require.Equal(t, []byte(`Hello, World!`), payload)
+	})
+	t.Run("hints", func(t *testing.T) {
+		var hints jwk.ProviderHints
+		p := jwk.ProviderFunc(func(_ context.Context, h jwk.ProviderHints) (jwk.Set, error) {
+			hints = h
+			return set, nil
+		})
+		_, err := jws.Verify(signed, jws.WithKeySetProvider(p))
+		require.NoError(t, err, `jws.Verify should succeed`)
+		require.Equal(t, `my-key`, hints.KeyID, `kid hint should match`)
+		require.Equal(t, jwa.RS256, hints.Algorithm, `alg hint should match`)
+	})
+	t.Run("provider error", func(t *testing.T) {
+		p := jwk.ProviderFunc(func(context.Context, jwk.ProviderHints) (jwk.Set, error) {
+			return nil, fmt.Errorf(`no keys for you`)
+		})
+		_, err := jws.Verify(signed, jws.WithKeySetProvider(p))
+		require.Error(t, err, `jws.Verify should fail`)
+	})
+	t.Run("nil provider", func(t *testing.T) {
+		_, err := jws.Verify(signed, jws.WithKeySetProvider(nil))
+		require.Error(t, err, `jws.Verify should fail`)
+	})
+}
+
+func TestVerifyKeySetAlgorithmMismatch(t *testing.T) {
+	const kid = `my-key`
+	privateKey, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	require.NoError(t, privateKey.Set(jwk.KeyIDKey, kid), `key.Set should succeed`)
+
+	signed, err := jws.Sign([]byte(`Lorem ipsum`), jws.WithKey(jwa.RS256, privateKey))
+	require.NoError(t, err, `jws.Sign should succeed`)
+
+	pubkey, err := jwk.PublicKeyOf(privateKey)
+	require.NoError(t, err, `jwk.PublicKeyOf should succeed`)
+	require.NoError(t, pubkey.Set(jwk.AlgorithmKey, jwa.RS512), `key.Set should succeed`)
+
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pubkey), `set.AddKey should succeed`)
+
+	_, err = jws.Verify(signed, jws.WithKeySet(set))
+	require.Error(t, err, `jws.Verify should fail`)
+	require.Contains(t, err.Error(), `does not match algorithm "RS512"`)
+
+	require.NoError(t, pubkey.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+	_, err = jws.Verify(signed, jws.WithKeySet(set))
+	require.NoError(t, err, `jws.Verify should succeed`)
+}
+
+func TestErrVerificationFailed(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	other, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	signed, err := jws.Sign([]byte(`Lorem ipsum`), jws.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jws.Sign should succeed`)
+
+	_, err = jws.Verify(signed, jws.WithKey(jwa.RS256, &other.PublicKey))
+	require.Error(t, err, `jws.Verify should fail`)
+	require.True(t, errors.Is(err, jws.ErrVerificationFailed()), `error should be jws.ErrVerificationFailed`)
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.SubjectKey, `jwx`), `tok.Set should succeed`)
+	signed, err = jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, &other.PublicKey))
+	require.Error(t, err, `jwt.Parse should fail`)
+	require.True(t, errors.Is(err, jws.ErrVerificationFailed()), `error from jwt.Parse should be jws.ErrVerificationFailed`)
+	require.False(t, jwt.IsValidationError(err), `verification failure should not be a validation error`)
+
+	_, err = jws.Verify(signed, jws.WithKey(jwa.RS256, &key.PublicKey))
+	require.NoError(t, err, `jws.Verify should succeed`)
+}
+
+func TestKeyCompatibility(t *testing.T) {
+	rsaKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	rsaJWK, err := jwk.FromRaw(&rsaKey.PublicKey)
+	require.NoError(t, err, `jwk.FromRaw should succeed`)
+	secret := []byte(`abracadabra-abracadabra-abracadabra`)
+
+	hmacSigned, err := jws.Sign([]byte(`Lorem ipsum`), jws.WithKey(jwa.HS256, secret))
+	require.NoError(t, err, `jws.Sign should succeed`)
+	rsaSigned, err := jws.Sign([]byte(`Lorem ipsum`), jws.WithKey(jwa.RS256, rsaKey))
+	require.NoError(t, err, `jws.Sign should succeed`)
+
+	testcases := []struct {
+		Name   string
+		Signed []byte
+		Alg    jwa.SignatureAlgorithm
+		Key    interface{}
+	}{
+		{Name: `HS256 with raw RSA public key`, Signed: hmacSigned, Alg: jwa.HS256, Key: &rsaKey.PublicKey},
+		{Name: `HS256 with RSA JWK`, Signed: hmacSigned, Alg: jwa.HS256, Key: rsaJWK},
+		{Name: `RS256 with []byte`, Signed: rsaSigned, Alg: jwa.RS256, Key: secret},
+		{Name: `ES256 with RSA public key`, Signed: rsaSigned, Alg: jwa.ES256, Key: &rsaKey.PublicKey},
+		{Name: `EdDSA with []byte`, Signed: hmacSigned, Alg: jwa.EdDSA, Key: secret},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := jws.Verify(tc.Signed, jws.WithKey(tc.Alg, tc.Key))
+			require.Error(t, err, `jws.Verify should fail`)
+			require.True(t, errors.Is(err, jws.ErrVerificationFailed()), `error should be jws.ErrVerificationFailed`)
+			require.Contains(t, err.Error(), `cannot be used with algorithm`, `error should mention the incompatible key`)
+		})
+	}
+
+	t.Run("compatible key among incompatible ones", func(t *testing.T) {
+		payload, err := jws.Verify(hmacSigned,
+			jws.WithKey(jwa.HS256, &rsaKey.PublicKey),
+			jws.WithKey(jwa.HS256, secret),
+		)
+		require.NoError(t, err, `jws.Verify should succeed`)
+		require.Equal(t, []byte(`Lorem ipsum`), payload, `payload should match`)
+	})
+	t.Run("key set", func(t *testing.T) {
+		key, err := jwk.FromRaw(&rsaKey.PublicKey)
+		require.NoError(t, err, `jwk.FromRaw should succeed`)
+		require.NoError(t, key.Set(jwk.KeyIDKey, `confused`), `key.Set should succeed`)
+		require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.HS256), `key.Set should succeed`)
+
+		set := jwk.NewSet()
+		require.NoError(t, set.AddKey(key), `set.AddKey should succeed`)
+
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.KeyIDKey, `confused`), `hdrs.Set should succeed`)
+		signed, err := jws.Sign([]byte(`Lorem ipsum`), jws.WithKey(jwa.HS256, secret, jws.WithProtectedHeaders(hdrs)))
+		require.NoError(t, err, `jws.Sign should succeed`)
+
+		_, err = jws.Verify(signed, jws.WithKeySet(set))
+		require.Error(t, err, `jws.Verify should fail`)
+		require.Contains(t, err.Error(), `cannot be used with algorithm`, `error should mention the incompatible key`)
+	})
+}
+
+type truncatedHMAC struct{}
+
+func (truncatedHMAC) mac(payload []byte, key interface{}) ([]byte, error) {
+	k, ok := key.([]byte)
+	if !ok {
+		return nil, fmt.Errorf(`invalid key type %T`, key)
+	}
+	h := hmac.New(sha512.New, k)
+	h.Write(payload)
+	return h.Sum(nil)[:32], nil
+}
+
+func (s truncatedHMAC) Sign(payload []byte, key interface{}) ([]byte, error) {
+	return s.mac(payload, key)
+}
+
+func (s truncatedHMAC) Verify(payload, signature []byte, key interface{}) error {
+	expected, err := s.mac(payload, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, signature) {
+		return fmt.Errorf(`invalid signature`)
+	}
+	return nil
+}
+
+func (truncatedHMAC) Algorithm() jwa.SignatureAlgorithm {
+	return `X-HS512-256`
+}
+
+func TestCustomAlgorithm(t *testing.T) {
+	alg := truncatedHMAC{}.Algorithm()
+	jws.RegisterSigner(alg, jws.SignerFactoryFn(func() (jws.Signer, error) {
+		return truncatedHMAC{}, nil
+	}))
+	jws.RegisterVerifier(alg, jws.VerifierFactoryFn(func() (jws.Verifier, error) {
+		return truncatedHMAC{}, nil
+	}))
+	defer jwa.UnregisterSignatureAlgorithm(alg)
+
+	key := []byte(`very secret key`)
+	signed, err := jws.Sign([]byte(examplePayload), jws.WithKey(alg, key))
+	require.NoError(t, err, `jws.Sign should succeed`)
+
+	msg, err := jws.Parse(signed)
+	require.NoError(t, err, `jws.Parse should succeed`)
+	require.Equal(t, alg, msg.Signatures()[0].ProtectedHeaders().Algorithm(), `algorithm should survive parsing`)
+
+	payload, err := jws.Verify(signed, jws.WithKey(alg, key))
+	require.NoError(t, err, `jws.Verify should succeed`)
+	require.Equal(t, []byte(examplePayload), payload)
+}
+
+func TestStrictAlgorithm(t *testing.T) {
+	key := []byte(`very secret key`)
+	signed, err := jws.Sign([]byte(examplePayload), jws.WithKey(jwa.HS256, key))
+	require.NoError(t, err, `jws.Sign should succeed`)
+
+	// replace the protected header with one using an unknown algorithm
+	parts := bytes.SplitN(signed, []byte{'.'}, 2)
+	unknown := append(base64.Encode([]byte(`{"alg":"HS999"}`)), '.')
+	unknown = append(unknown, parts[1]...)
+
+	_, err = jws.Parse(unknown)
+	require.NoError(t, err, `jws.Parse should succeed`)
+
+	jwa.Settings(jwa.WithStrict(true))
+	defer jwa.Settings(jwa.WithStrict(false))
+
+	_, err = jws.Parse(unknown)
+	require.Error(t, err, `jws.Parse should fail in strict mode`)
+	_, err = jws.Parse(signed)
+	require.NoError(t, err, `jws.Parse should succeed for known algorithms in strict mode`)
+}
@@ -15,7 +15,7 @@ import (
 //
 // `jws.Sign()` can only accept static key providers via `jws.WithKey()`,
 // while `jws.Verify()` can accept `jws.WithKey()`, `jws.WithKeySet()`,
-// `jws.WithVerifyAuto()`, and `jws.WithKeyProvider()`.
+// `jws.WithKeySetProvider()`, `jws.WithVerifyAuto()`, and `jws.WithKeyProvider()`.
 //
 // Understanding how this works is crucial to learn how this package works.
 //
@@ -109,6 +109,32 @@ type keySetProvider struct {
 	multipleKeysPerKeyID bool // true if we should attempt to match multiple keys per key ID. if false we assume that only one key exists for a given key ID
 }
 
+func newKeySetProvider(set jwk.Set, options ...WithKeySetSuboption) *keySetProvider {
+	requireKid := true
+	var useDefault, inferAlgorithm, multipleKeysPerKeyID bool
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identRequireKid{}:
+			requireKid = option.Value().(bool)
+		case identUseDefault{}:
+			useDefault = option.Value().(bool)
+		case identMultipleKeysPerKeyID{}:
+			multipleKeysPerKeyID = option.Value().(bool)
+		case identInferAlgorithmFromKey{}:
+			inferAlgorithm = option.Value().(bool)
+		}
+	}
+
+	return &keySetProvider{
+		set:                  set,
+		requireKid:           requireKid,
+		useDefault:           useDefault,
+		multipleKeysPerKeyID: multipleKeysPerKeyID,
+		inferAlgorithm:       inferAlgorithm,
+	}
+}
+
 func (kp *keySetProvider) selectKey(sink KeySink, key jwk.Key, sig *Signature, _ *Message) error {
 	if usage := key.KeyUsage(); usage != "" && usage != jwk.ForSignature.String() {
 		return nil
@@ -120,6 +146,14 @@ func (kp *keySetProvider) selectKey(sink KeySink, key jwk.Key, sig *Signature, _
 			return fmt.Errorf(`invalid signature algorithm %s: %w`, key.Algorithm(), err)
 		}
 
+		// If the message specifies an algorithm, the key must be meant for
+		// the same algorithm. Otherwise the key cannot possibly verify the
+		// message, so we report it here instead of failing later with
+		// a misleading signature verification error
+		if msgAlg := sig.ProtectedHeaders().Algorithm(); msgAlg != "" && msgAlg != alg {
+			return fmt.Errorf(`algorithm %q in the message does not match algorithm %q of key %q`, msgAlg, alg, key.KeyID())
+		}
+
 		sink.Key(alg, key)
 		return nil
 	}
@@ -210,6 +244,27 @@ func (kp *keySetProvider) FetchKeys(_ context.Context, sink KeySink, sig *Signat
 	return nil
 }
 
+// jwkProvider adapts a jwk.Provider so that it can be used as a KeyProvider
+type jwkProvider struct {
+	provider jwk.Provider
+	options  []WithKeySetSuboption
+}
+
+func (kp *jwkProvider) FetchKeys(ctx context.Context, sink KeySink, sig *Signature, msg *Message) error {
+	if kp.provider == nil {
+		return fmt.Errorf(`jws.WithKeySetProvider: no jwk.Provider available`)
+	}
+	hdrs := sig.ProtectedHeaders()
+	set, err := kp.provider.FetchKeys(ctx, jwk.ProviderHints{
+		KeyID:     hdrs.KeyID(),
+		Algorithm: hdrs.Algorithm(),
+	})
+	if err != nil {
+		return fmt.Errorf(`failed to fetch keys from jwk.Provider: %w`, err)
+	}
+	return newKeySetProvider(set, kp.options...).FetchKeys(ctx, sink, sig, msg)
+}
+
 type jkuProvider struct {
 	fetcher jwk.Fetcher
 	options []jwk.FetchOption
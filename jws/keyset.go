@@ -0,0 +1,205 @@
+package jws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// KeySetType is the value of the `typ` header used for signed JWK Sets
+const KeySetType = `jwk-set+jwt`
+
+// SignKeySet creates a signed JWK Set, i.e. a JWS message whose payload is
+// the JSON representation of the given `jwk.Set`. This format is used by
+// specifications such as OpenID Federation to publish key sets whose
+// authenticity can be verified independently of the transport.
+//
+// Only the public portion of the keys in `set` are published: private keys
+// are converted using `jwk.PublicSetOf()` before being serialized. Keys that
+// do not have a distinct public form (i.e. symmetric keys) result in an error,
+// as publishing them would disclose the secret.
+//
+// Non-key fields of `set` (e.g. `iss`, `sub`, `iat`, `exp`, set via
+// `(jwk.Set).Set()`) are copied to the payload as is.
+//
+// The options are passed to `jws.Sign()`, therefore you must specify
+// at least one key using `jws.WithKey()`. Unless a `typ` header is
+// already specified via `jws.WithProtectedHeaders()`, the protected
+// headers of each signature will have `typ` set to `jwk-set+jwt`.
+func SignKeySet(set jwk.Set, options ...SignOption) ([]byte, error) {
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Key(i)
+		if key.KeyType() == jwa.OctetSeq {
+			return nil, fmt.Errorf(`jws.SignKeySet: key #%d is a symmetric key, which cannot be published`, i)
+		}
+	}
+
+	pubset, err := jwk.PublicSetOf(set)
+	if err != nil {
+		return nil, fmt.Errorf(`jws.SignKeySet: failed to create public key set: %w`, err)
+	}
+
+	ctx := context.Background()
+	for iter := set.Iterate(ctx); iter.Next(ctx); {
+		pair := iter.Pair()
+		if err := pubset.Set(pair.Key.(string), pair.Value); err != nil {
+			return nil, fmt.Errorf(`jws.SignKeySet: failed to copy field %q: %w`, pair.Key, err)
+		}
+	}
+
+	payload, err := json.Marshal(pubset)
+	if err != nil {
+		return nil, fmt.Errorf(`jws.SignKeySet: failed to marshal key set: %w`, err)
+	}
+
+	signOptions := make([]SignOption, 0, len(options))
+	for _, option := range options {
+		if option.Ident() != (identKey{}) {
+			signOptions = append(signOptions, option)
+			continue
+		}
+
+		//nolint:forcetypeassert
+		wk := option.Value().(*withKey)
+		protected := NewHeaders()
+		if wk.protected != nil {
+			if err := wk.protected.Copy(ctx, protected); err != nil {
+				return nil, fmt.Errorf(`jws.SignKeySet: failed to copy protected headers: %w`, err)
+			}
+		}
+		if protected.Type() == "" {
+			if err := protected.Set(TypeKey, KeySetType); err != nil {
+				return nil, fmt.Errorf(`jws.SignKeySet: failed to set "typ" header: %w`, err)
+			}
+		}
+
+		suboptions := []WithKeySuboption{WithProtectedHeaders(protected)}
+		if wk.public != nil {
+			suboptions = append(suboptions, WithPublicHeaders(wk.public))
+		}
+		signOptions = append(signOptions, WithKey(wk.alg, wk.key, suboptions...))
+	}
+
+	signed, err := Sign(payload, signOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`jws.SignKeySet: failed to sign key set: %w`, err)
+	}
+	return signed, nil
+}
+
+// VerifyKeySet verifies a signed JWK Set (such as those created by
+// `jws.SignKeySet()`), and returns the `jwk.Set` contained in its payload.
+//
+// The options are passed to `jws.Verify()` as is, therefore you must specify
+// the keys of the trust anchor that signed the key set using options such as
+// `jws.WithKey()` or `jws.WithKeySet()`.
+//
+// In addition to the signature, the following are checked:
+// if the `typ` header is present it must be `jwk-set+jwt`, if the `exp`
+// field is present in the payload it must not be in the past, and if
+// the `iat` field is present it must not be in the future. Use
+// `jws.WithAcceptableSkew()` to allow for clock differences between the
+// publisher and the verifier. Key sets without `exp` never expire, so
+// publishers that care about replay of stale key sets should always
+// include it.
+//
+// Other fields such as `iss` and `sub` are NOT verified. They are available
+// via `(jwk.Set).Get()` on the returned set, should you need to check them.
+func VerifyKeySet(buf []byte, options ...VerifyOption) (jwk.Set, error) {
+	payload, err := verifyKeySet(buf, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jws.VerifyKeySet: %w`, err)
+	}
+
+	set, err := jwk.Parse(payload)
+	if err != nil {
+		return nil, fmt.Errorf(`jws.VerifyKeySet: failed to parse key set: %w`, err)
+	}
+	return set, nil
+}
+
+func verifyKeySet(buf []byte, options ...VerifyOption) ([]byte, error) {
+	var skew time.Duration
+	verifyOptions := make([]VerifyOption, 0, len(options)+1)
+	for _, option := range options {
+		if option.Ident() == (identAcceptableSkew{}) {
+			//nolint:forcetypeassert
+			skew = option.Value().(time.Duration)
+			continue
+		}
+		verifyOptions = append(verifyOptions, option)
+	}
+	if skew < 0 {
+		return nil, fmt.Errorf(`acceptable skew must not be negative (got %s)`, skew)
+	}
+
+	var msg Message
+	payload, err := Verify(buf, append(verifyOptions, WithMessage(&msg))...)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to verify signed key set: %w`, err)
+	}
+
+	for _, sig := range msg.Signatures() {
+		if h := sig.ProtectedHeaders(); h != nil {
+			if typ := h.Type(); typ != "" && typ != KeySetType {
+				return nil, fmt.Errorf(`invalid "typ" header for signed key set: %q`, typ)
+			}
+		}
+	}
+
+	var claims struct {
+		Expiration *json.Number `json:"exp"`
+		IssuedAt   *json.Number `json:"iat"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf(`failed to parse signed key set payload: %w`, err)
+	}
+
+	now := time.Now()
+	if v := claims.Expiration; v != nil {
+		exp, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf(`invalid "exp" field in signed key set: %w`, err)
+		}
+		if !now.Before(time.Unix(int64(exp), 0).Add(skew)) {
+			return nil, fmt.Errorf(`signed key set has expired`)
+		}
+	}
+	if v := claims.IssuedAt; v != nil {
+		iat, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf(`invalid "iat" field in signed key set: %w`, err)
+		}
+		if now.Add(skew).Before(time.Unix(int64(iat), 0)) {
+			return nil, fmt.Errorf(`signed key set was issued in the future`)
+		}
+	}
+	return payload, nil
+}
+
+type keySetVerifier struct {
+	options []VerifyOption
+}
+
+// NewKeySetVerifier creates a `jwk.KeySetVerifier` that verifies signed
+// JWK Sets using the given options. The result can be passed to
+// `jwk.Fetch()` or `(*jwk.Cache).Register()` via `jwk.WithKeySetVerifier()`,
+// so that signed JWK Sets are verified against the trust anchor
+// before they are used or cached:
+//
+//	anchor := jws.WithKeySet(trustAnchorKeys)
+//	c.Register(u, jwk.WithKeySetVerifier(jws.NewKeySetVerifier(anchor)))
+//
+// The same checks as `jws.VerifyKeySet()` are performed. Note that
+// `jwk.Cache` only runs the verifier when the key set is (re)fetched.
+func NewKeySetVerifier(options ...VerifyOption) jwk.KeySetVerifier {
+	return &keySetVerifier{options: options}
+}
+
+func (v *keySetVerifier) VerifyKeySet(_ string, buf []byte) ([]byte, error) {
+	return verifyKeySet(buf, v.options...)
+}
@@ -118,28 +118,20 @@ func WithKey(alg jwa.KeyAlgorithm, key interface{}, options ...WithKeySuboption)
 // The behavior can be tweaked by using the `jws.WithKeySetSuboption`
 // suboption types.
 func WithKeySet(set jwk.Set, options ...WithKeySetSuboption) VerifyOption {
-	requireKid := true
-	var useDefault, inferAlgorithm, multipleKeysPerKeyID bool
-	for _, option := range options {
-		//nolint:forcetypeassert
-		switch option.Ident() {
-		case identRequireKid{}:
-			requireKid = option.Value().(bool)
-		case identUseDefault{}:
-			useDefault = option.Value().(bool)
-		case identMultipleKeysPerKeyID{}:
-			multipleKeysPerKeyID = option.Value().(bool)
-		case identInferAlgorithmFromKey{}:
-			inferAlgorithm = option.Value().(bool)
-		}
-	}
+	return WithKeyProvider(newKeySetProvider(set, options...))
+}
 
-	return WithKeyProvider(&keySetProvider{
-		set:                  set,
-		requireKid:           requireKid,
-		useDefault:           useDefault,
-		multipleKeysPerKeyID: multipleKeysPerKeyID,
-		inferAlgorithm:       inferAlgorithm,
+// WithKeySetProvider specifies a `jwk.Provider` to obtain the JWKS (jwk.Set)
+// to use for verification. The provider is consulted for each signature
+// in the message, and is given the `kid` and `alg` values found in the
+// signature's protected headers as hints.
+//
+// Once the `jwk.Set` is obtained, keys are selected in the same manner
+// as `jws.WithKeySet()`, and the same suboptions may be specified.
+func WithKeySetProvider(p jwk.Provider, options ...WithKeySetSuboption) VerifyOption {
+	return WithKeyProvider(&jwkProvider{
+		provider: p,
+		options:  options,
 	})
 }
 
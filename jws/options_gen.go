@@ -5,6 +5,7 @@ package jws
 import (
 	"context"
 	"io/fs"
+	"time"
 
 	"github.com/lestrrat-go/option"
 )
@@ -123,6 +124,7 @@ type withKeySuboption struct {
 
 func (*withKeySuboption) withKeySuboption() {}
 
+type identAcceptableSkew struct{}
 type identContext struct{}
 type identDetached struct{}
 type identDetachedPayload struct{}
@@ -140,6 +142,10 @@ type identRequireKid struct{}
 type identSerialization struct{}
 type identUseDefault struct{}
 
+func (identAcceptableSkew) String() string {
+	return "WithAcceptableSkew"
+}
+
 func (identContext) String() string {
 	return "WithContext"
 }
@@ -204,6 +210,15 @@ func (identUseDefault) String() string {
 	return "WithUseDefault"
 }
 
+// WithAcceptableSkew specifies the duration by which the `exp` and `iat`
+// fields of a signed JWK Set may differ, to allow for clock differences
+// between the publisher and the verifier. It may only be passed to
+// `jws.VerifyKeySet()` and `jws.NewKeySetVerifier()`: `jws.Verify()`
+// returns an error if it is specified. This value must not be negative.
+func WithAcceptableSkew(v time.Duration) VerifyOption {
+	return &verifyOption{option.New(identAcceptableSkew{}, v)}
+}
+
 func WithContext(v context.Context) VerifyOption {
 	return &verifyOption{option.New(identContext{}, v)}
 }
@@ -23,7 +23,12 @@ var signerDB map[jwa.SignatureAlgorithm]SignerFactory
 // For example, if you would like to provide a custom signer for
 // jwa.EdDSA, use this function to register a `SignerFactory`
 // (probably in your `init()`)
+//
+// If `alg` is not known to the jwa package, it is registered via
+// `jwa.RegisterSignatureAlgorithm()` so that it can be parsed from
+// JWS headers and JWKs.
 func RegisterSigner(alg jwa.SignatureAlgorithm, f SignerFactory) {
+	jwa.RegisterSignatureAlgorithm(alg)
 	signerDB[alg] = f
 }
 
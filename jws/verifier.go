@@ -23,7 +23,12 @@ var verifierDB map[jwa.SignatureAlgorithm]VerifierFactory
 // For example, if you would like to provide a custom verifier for
 // jwa.EdDSA, use this function to register a `VerifierFactory`
 // (probably in your `init()`)
+//
+// If `alg` is not known to the jwa package, it is registered via
+// `jwa.RegisterSignatureAlgorithm()` so that it can be parsed from
+// JWS headers and JWKs.
 func RegisterVerifier(alg jwa.SignatureAlgorithm, f VerifierFactory) {
+	jwa.RegisterSignatureAlgorithm(alg)
 	verifierDB[alg] = f
 }
 
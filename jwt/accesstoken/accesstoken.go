@@ -0,0 +1,147 @@
+// Package accesstoken implements the JSON Web Token (JWT) Profile for
+// OAuth 2.0 Access Tokens (RFC 9068).
+//
+// Authorization servers can use `Sign()` to mint access tokens that conform
+// to the profile, and resource servers can use `Parse()` to verify and
+// validate them according to RFC 9068 Section 4.
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// Type is the value of the `typ` header of access tokens.
+// "application/at+jwt" is also accepted by `Parse()`.
+const Type = `at+jwt`
+
+// Names of the claims defined for access tokens in RFC 9068,
+// in addition to the registered JWT claims
+const (
+	ClientIDKey     = `client_id`
+	AuthTimeKey     = `auth_time`
+	ACRKey          = `acr`
+	AMRKey          = `amr`
+	ScopeKey        = `scope`
+	GroupsKey       = `groups`
+	RolesKey        = `roles`
+	EntitlementsKey = `entitlements`
+)
+
+// RequiredClaims lists the claims that must be present in access tokens
+// (RFC 9068 Section 2.2).
+var RequiredClaims = []string{
+	jwt.IssuerKey,
+	jwt.ExpirationKey,
+	jwt.AudienceKey,
+	jwt.SubjectKey,
+	ClientIDKey,
+	jwt.IssuedAtKey,
+	jwt.JwtIDKey,
+}
+
+var errInvalidType = jwt.NewValidationError(errors.New(`"typ" header is not "at+jwt"`))
+
+// ErrInvalidType returns the immutable error used when the `typ` header
+// of the JWS message is not "at+jwt" (or "application/at+jwt").
+func ErrInvalidType() jwt.ValidationError {
+	return errInvalidType
+}
+
+// the identity of jwt.WithJwsHeaders()
+var identJwsHeaders = jwt.WithJwsHeaders(nil).Ident()
+
+// Sign signs the access token `t` using `jwt.Sign()`, setting the `typ`
+// header to "at+jwt". An error is returned if any of the `RequiredClaims`
+// are missing from `t`.
+//
+// The `typ` header overrides the value in the headers specified via
+// `jwt.WithJwsHeaders()`. You should not change it using the suboptions
+// of `jwt.WithKey()`.
+func Sign(t jwt.Token, options ...jwt.SignOption) ([]byte, error) {
+	var missing []string
+	for _, name := range RequiredClaims {
+		if _, ok := t.Get(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf(`accesstoken.Sign: required claims are missing: %s`, strings.Join(missing, `, `))
+	}
+
+	hdrs := jws.NewHeaders()
+	for _, option := range options {
+		if option.Ident() != identJwsHeaders {
+			continue
+		}
+		//nolint:forcetypeassert
+		if src := option.Value().(jws.Headers); src != nil {
+			hdrs = jws.NewHeaders()
+			if err := src.Copy(context.Background(), hdrs); err != nil {
+				return nil, fmt.Errorf(`accesstoken.Sign: failed to copy headers: %w`, err)
+			}
+		}
+	}
+	if err := hdrs.Set(jws.TypeKey, Type); err != nil {
+		return nil, fmt.Errorf(`accesstoken.Sign: failed to set %q header: %w`, jws.TypeKey, err)
+	}
+
+	options = append(options[:len(options):len(options)], jwt.WithJwsHeaders(hdrs))
+	signed, err := jwt.Sign(t, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`accesstoken.Sign: %w`, err)
+	}
+	return signed, nil
+}
+
+// Parse parses the access token in `src` using `jwt.Parse()`, and
+// validates it according to RFC 9068 Section 4. In addition to the
+// checks performed by `jwt.Validate()`:
+//
+//   - the `typ` header must be "at+jwt" (or "application/at+jwt")
+//   - all of the `RequiredClaims` must be present
+//   - `iss` must be equal to `issuer`
+//   - `aud` must contain `resource`, the identifier of the resource server
+//
+// The source of the verification keys must be specified in `options`
+// (e.g. `jwt.WithKeySet()`). Further `jwt.ValidateOption`s may be passed
+// as well, but `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func Parse(src []byte, issuer, resource string, options ...jwt.ParseOption) (jwt.Token, error) {
+	if issuer == `` || resource == `` {
+		return nil, fmt.Errorf(`accesstoken.Parse: issuer and resource must be specified`)
+	}
+	for _, option := range options {
+		if parseopts.IsVerifyOrValidate(option) {
+			return nil, fmt.Errorf(`accesstoken.Parse: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+	}
+
+	options = append(options[:len(options):len(options)],
+		jwt.WithValidator(jwt.ValidatorFunc(validateType)),
+		jwt.WithRequiredClaims(RequiredClaims...),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(resource),
+	)
+	return jwt.Parse(src, options...)
+}
+
+func validateType(ctx context.Context, _ jwt.Token) jwt.ValidationError {
+	hdrs, ok := jwt.ValidationCtxHeaders(ctx)
+	if !ok {
+		return errInvalidType
+	}
+
+	// RFC 7515 Section 4.1.9: the "application/" prefix may be omitted,
+	// and media types are case-insensitive
+	typ := strings.ToLower(hdrs.Type())
+	if typ != Type && typ != `application/`+Type {
+		return errInvalidType
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package accesstoken_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/accesstoken"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessToken(t *testing.T) {
+	const issuer = `https://as.example.com`
+	const resource = `https://rs.example.com`
+
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	now := time.Now()
+	tok, err := jwt.NewBuilder().
+		Issuer(issuer).
+		Subject(`alice`).
+		Audience([]string{resource}).
+		IssuedAt(now).
+		Expiration(now.Add(time.Hour)).
+		JwtID(`abc`).
+		Claim(accesstoken.ClientIDKey, `app`).
+		Claim(accesstoken.ScopeKey, `read`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	hdrs := jws.NewHeaders()
+	require.NoError(t, hdrs.Set(jws.KeyIDKey, `my-key`), `hdrs.Set should succeed`)
+	signed, err := accesstoken.Sign(tok, jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs))
+	require.NoError(t, err, `accesstoken.Sign should succeed`)
+
+	msg, err := jws.Parse(signed)
+	require.NoError(t, err, `jws.Parse should succeed`)
+	require.Equal(t, accesstoken.Type, msg.Signatures()[0].ProtectedHeaders().Type())
+	require.Equal(t, `my-key`, msg.Signatures()[0].ProtectedHeaders().KeyID())
+	require.Equal(t, ``, hdrs.Type(), `headers passed to accesstoken.Sign should not be modified`)
+
+	t.Run("Parse", func(t *testing.T) {
+		parsed, err := accesstoken.Parse(signed, issuer, resource, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.NoError(t, err, `accesstoken.Parse should succeed`)
+		require.True(t, jwt.Equal(tok, parsed), `tokens should match`)
+
+		_, err = accesstoken.Parse(signed, issuer, `https://other.example.com`, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `error should be jwt.ErrInvalidAudience`)
+
+		_, err = accesstoken.Parse(signed, `https://other.example.com`, resource, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.True(t, errors.Is(err, jwt.ErrInvalidIssuer()), `error should be jwt.ErrInvalidIssuer`)
+
+		_, err = accesstoken.Parse(signed, issuer, resource, jwt.WithVerify(false))
+		require.Error(t, err, `jwt.WithVerify should be rejected`)
+	})
+	t.Run("Parse regular JWT", func(t *testing.T) {
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = accesstoken.Parse(signed, issuer, resource, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.True(t, errors.Is(err, accesstoken.ErrInvalidType()), `error should be accesstoken.ErrInvalidType`)
+	})
+	t.Run("application/at+jwt", func(t *testing.T) {
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, `application/AT+JWT`), `hdrs.Set should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = accesstoken.Parse(signed, issuer, resource, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.NoError(t, err, `accesstoken.Parse should succeed`)
+	})
+	t.Run("missing claims", func(t *testing.T) {
+		incomplete, err := tok.Clone()
+		require.NoError(t, err, `tok.Clone should succeed`)
+		require.NoError(t, incomplete.Remove(accesstoken.ClientIDKey), `incomplete.Remove should succeed`)
+		require.NoError(t, incomplete.Remove(jwt.JwtIDKey), `incomplete.Remove should succeed`)
+
+		_, err = accesstoken.Sign(incomplete, jwt.WithKey(jwa.RS256, key))
+		require.Error(t, err, `accesstoken.Sign should fail`)
+		require.Contains(t, err.Error(), `client_id, jti`)
+
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, accesstoken.Type), `hdrs.Set should succeed`)
+		signed, err := jwt.Sign(incomplete, jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = accesstoken.Parse(signed, issuer, resource, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `error should be jwt.ErrRequiredClaim`)
+	})
+}
@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"context"
+	"time"
+)
+
+// ClockAnomalyKind describes the kind of a `jwt.ClockAnomaly`. Its values
+// are short, fixed strings that are suitable for use as metric labels.
+type ClockAnomalyKind string
+
+const (
+	// ClockAnomalyIssuedInFuture is used when the `iat` claim is later
+	// than the current time, which indicates that the clock of the issuer
+	// is ahead of the local clock
+	ClockAnomalyIssuedInFuture ClockAnomalyKind = `issued_in_future`
+	// ClockAnomalyIssuedAfterExpiration is used when the `iat` claim is
+	// later than the `exp` claim
+	ClockAnomalyIssuedAfterExpiration ClockAnomalyKind = `issued_after_expiration`
+	// ClockAnomalyNotBeforeAfterExpiration is used when the `nbf` claim is
+	// later than the `exp` claim, so that the token is never valid
+	ClockAnomalyNotBeforeAfterExpiration ClockAnomalyKind = `not_before_after_expiration`
+)
+
+// ClockAnomaly describes an inconsistency in the time claims of a token,
+// and is passed to the function specified via `jwt.WithClockAnomalyCallback()`.
+type ClockAnomaly struct {
+	// Kind describes the inconsistency.
+	Kind ClockAnomalyKind
+	// Token is the token that was validated.
+	Token Token
+	// Offset is the size of the inconsistency: for example, how far in
+	// the future the `iat` claim is for `jwt.ClockAnomalyIssuedInFuture`,
+	// or how much later the `nbf` claim is than the `exp` claim for
+	// `jwt.ClockAnomalyNotBeforeAfterExpiration`. It is always positive.
+	Offset time.Duration
+}
+
+// clockAnomalies returns the inconsistencies in the time claims of `t`.
+// The times are truncated in the same manner as the validators do, and
+// the acceptable skew is deliberately not taken into account
+func clockAnomalies(ctx context.Context, t Token) []ClockAnomaly {
+	clock := ValidationCtxClock(ctx)      // MUST be populated
+	trunc := ValidationCtxTruncation(ctx) // MUST be populated
+
+	truncated := func(tv time.Time) time.Time {
+		if tv.IsZero() || tv.Unix() == 0 {
+			return time.Time{}
+		}
+		return tv.Truncate(trunc)
+	}
+	now := clock.Now().Truncate(trunc)
+	iat := truncated(t.IssuedAt())
+	nbf := truncated(t.NotBefore())
+	exp := truncated(t.Expiration())
+
+	var anomalies []ClockAnomaly
+	report := func(kind ClockAnomalyKind, later, earlier time.Time) {
+		if later.IsZero() || earlier.IsZero() || !later.After(earlier) {
+			return
+		}
+		anomalies = append(anomalies, ClockAnomaly{Kind: kind, Token: t, Offset: later.Sub(earlier)})
+	}
+	report(ClockAnomalyIssuedInFuture, iat, now)
+	report(ClockAnomalyIssuedAfterExpiration, iat, exp)
+	report(ClockAnomalyNotBeforeAfterExpiration, nbf, exp)
+	return anomalies
+}
@@ -0,0 +1,77 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockAnomalies(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	clock := jwt.WithClock(jwt.ClockFunc(func() time.Time { return now }))
+
+	validate := func(t *testing.T, tok jwt.Token, options ...jwt.ValidateOption) ([]jwt.ClockAnomaly, error) {
+		t.Helper()
+		var anomalies []jwt.ClockAnomaly
+		options = append(options, clock, jwt.WithClockAnomalyCallback(func(a jwt.ClockAnomaly) {
+			anomalies = append(anomalies, a)
+		}))
+		return anomalies, jwt.Validate(tok, options...)
+	}
+
+	t.Run("consistent", func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().IssuedAt(now.Add(-time.Minute)).NotBefore(now.Add(-time.Minute)).Expiration(now.Add(time.Hour)).Build()
+		require.NoError(t, err, `Build should succeed`)
+		anomalies, err := validate(t, tok)
+		require.NoError(t, err, `jwt.Validate should succeed`)
+		require.Empty(t, anomalies)
+	})
+	t.Run("iat in the future, within skew", func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().IssuedAt(now.Add(10 * time.Second)).Expiration(now.Add(time.Hour)).Build()
+		require.NoError(t, err, `Build should succeed`)
+		anomalies, err := validate(t, tok, jwt.WithAcceptableSkew(time.Minute))
+		require.NoError(t, err, `jwt.Validate should succeed within the skew`)
+		require.Len(t, anomalies, 1)
+		require.Equal(t, jwt.ClockAnomalyIssuedInFuture, anomalies[0].Kind)
+		require.Equal(t, 10*time.Second, anomalies[0].Offset)
+		require.Equal(t, tok, anomalies[0].Token)
+	})
+	t.Run("iat in the future, beyond skew", func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().IssuedAt(now.Add(time.Hour)).Build()
+		require.NoError(t, err, `Build should succeed`)
+		anomalies, err := validate(t, tok)
+		require.True(t, errors.Is(err, jwt.ErrInvalidIssuedAt()), `jwt.Validate should fail`)
+		require.Len(t, anomalies, 1, `anomalies should be reported for rejected tokens`)
+		require.Equal(t, jwt.ClockAnomalyIssuedInFuture, anomalies[0].Kind)
+	})
+	t.Run("nbf and iat after exp", func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().
+			IssuedAt(now.Add(-time.Minute)).
+			NotBefore(now.Add(-time.Minute)).
+			Expiration(now.Add(-2 * time.Minute)).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		anomalies, err := validate(t, tok, jwt.WithAcceptableSkew(time.Hour))
+		require.NoError(t, err, `jwt.Validate should succeed within the skew`)
+		require.Len(t, anomalies, 2)
+		require.Equal(t, jwt.ClockAnomalyIssuedAfterExpiration, anomalies[0].Kind)
+		require.Equal(t, time.Minute, anomalies[0].Offset)
+		require.Equal(t, jwt.ClockAnomalyNotBeforeAfterExpiration, anomalies[1].Kind)
+		require.Equal(t, time.Minute, anomalies[1].Offset)
+	})
+	t.Run("no time claims", func(t *testing.T) {
+		t.Parallel()
+		anomalies, err := validate(t, jwt.New())
+		require.NoError(t, err, `jwt.Validate should succeed`)
+		require.Empty(t, anomalies)
+	})
+}
@@ -4,6 +4,7 @@ package jwt
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -57,14 +58,20 @@ func (b *Builder) Subject(v string) *Builder {
 }
 
 // Build creates a new token based on the claims that the builder has received
-// so far. If a claim cannot be set, then the method returns a nil Token with
-// a en error as a second return value
+// so far. The values are validated against the types required by each claim
+// (e.g. `exp` must be a time value). If any of the claims cannot be set, then
+// the method returns a nil Token with an error describing all of the invalid
+// claims as a second return value
 func (b *Builder) Build() (Token, error) {
 	tok := New()
+	var errs []string
 	for _, claim := range b.claims {
 		if err := tok.Set(claim.Key.(string), claim.Value); err != nil {
-			return nil, fmt.Errorf(`failed to set claim %q: %w`, claim.Key.(string), err)
+			errs = append(errs, fmt.Sprintf(`failed to set claim %q: %s`, claim.Key.(string), err))
 		}
 	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf(`failed to build token: %s`, strings.Join(errs, `, `))
+	}
 	return tok, nil
 }
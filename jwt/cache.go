@@ -0,0 +1,130 @@
+package jwt
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+const defaultVerificationCacheCapacity = 1024
+
+// VerificationCache remembers tokens whose signatures have been verified,
+// so that the (potentially expensive) verification can be skipped when
+// the same serialized token is parsed again. It is used by passing it to
+// `jwt.Parse()` via `jwt.WithVerificationCache()`.
+//
+// Only the outcome of the verification (and decryption) is cached: the
+// claims are decoded, and the token is validated, each time it is parsed.
+// Tokens are identified by the SHA-256 digest of their serialized form.
+//
+// The cache does not know which keys a token was verified with. Therefore
+// a cache must only be shared between calls to `jwt.Parse()` that accept
+// the same keys. For example, do not use the same cache for tokens of
+// different tenants that are verified using different key sets. Call
+// `Purge()` when keys are revoked, as tokens that were verified using
+// them would otherwise be accepted until their entries expire.
+//
+// VerificationCache is safe for concurrent use.
+type VerificationCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+type verificationCacheEntry struct {
+	key     string
+	payload []byte
+	headers jws.Headers
+	expires time.Time
+}
+
+// NewVerificationCache creates a new `jwt.VerificationCache`, which holds
+// up to `capacity` tokens. When the cache is full, the least recently used
+// token is evicted. Each token is kept for at most `ttl`, or until it
+// expires (as specified by its `exp` claim), whichever comes first.
+//
+// If `capacity` is zero or negative, a default of 1024 is used.
+func NewVerificationCache(capacity int, ttl time.Duration) *VerificationCache {
+	if capacity <= 0 {
+		capacity = defaultVerificationCacheCapacity
+	}
+	return &VerificationCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Purge removes all tokens from the cache.
+func (c *VerificationCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+func verificationCacheKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return string(sum[:])
+}
+
+func (c *VerificationCache) get(key string) (*verificationCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	//nolint:forcetypeassert
+	entry := elem.Value.(*verificationCacheEntry)
+	if !time.Now().Before(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry, true
+}
+
+func (c *VerificationCache) add(key string, payload []byte, headers jws.Headers, exp time.Time) {
+	now := time.Now()
+	expires := now.Add(c.ttl)
+	if !exp.IsZero() && exp.Before(expires) {
+		expires = exp
+	}
+	if !now.Before(expires) {
+		return
+	}
+
+	entry := &verificationCacheEntry{
+		key:     key,
+		payload: payload,
+		headers: headers,
+		expires: expires,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.capacity {
+		elem := c.lru.Back()
+		c.lru.Remove(elem)
+		//nolint:forcetypeassert
+		delete(c.entries, elem.Value.(*verificationCacheEntry).key)
+	}
+}
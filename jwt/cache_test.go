@@ -0,0 +1,125 @@
+package jwt_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+type countingKeyProvider struct {
+	count int64
+	key   interface{}
+}
+
+func (kp *countingKeyProvider) FetchKeys(_ context.Context, sink jws.KeySink, _ *jws.Signature, _ *jws.Message) error {
+	atomic.AddInt64(&kp.count, 1)
+	sink.Key(jwa.RS256, kp.key)
+	return nil
+}
+
+func (kp *countingKeyProvider) Count() int64 {
+	return atomic.LoadInt64(&kp.count)
+}
+
+func TestVerificationCache(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	sign := func(t *testing.T, sub string) []byte {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Subject(sub).
+			Expiration(time.Now().Add(time.Hour)).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+
+	alice := sign(t, `alice`)
+	bob := sign(t, `bob`)
+
+	t.Run("cache hits skip verification", func(t *testing.T) {
+		kp := &countingKeyProvider{key: &key.PublicKey}
+		cache := jwt.NewVerificationCache(10, time.Minute)
+		for i := 0; i < 3; i++ {
+			tok, err := jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+			require.NoError(t, err, `jwt.Parse should succeed`)
+			require.Equal(t, `alice`, tok.Subject())
+		}
+		require.Equal(t, int64(1), kp.Count(), `token should be verified once`)
+
+		cache.Purge()
+		_, err := jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, int64(2), kp.Count(), `token should be verified again after purging`)
+	})
+	t.Run("validation is performed on cache hits", func(t *testing.T) {
+		kp := &countingKeyProvider{key: &key.PublicKey}
+		cache := jwt.NewVerificationCache(10, time.Minute)
+		_, err := jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+
+		later := jwt.ClockFunc(func() time.Time { return time.Now().Add(2 * time.Hour) })
+		_, err = jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache), jwt.WithClock(later))
+		require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `jwt.Parse should fail for expired tokens`)
+		require.Equal(t, int64(1), kp.Count(), `token should be verified once`)
+	})
+	t.Run("failed verifications are not cached", func(t *testing.T) {
+		other, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+		cache := jwt.NewVerificationCache(10, time.Minute)
+		for i := 0; i < 2; i++ {
+			_, err = jwt.Parse(alice, jwt.WithKey(jwa.RS256, &other.PublicKey), jwt.WithVerificationCache(cache))
+			require.Error(t, err, `jwt.Parse should fail`)
+		}
+
+		// Tampered tokens are different cache entries
+		kp := &countingKeyProvider{key: &key.PublicKey}
+		_, err = jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		tampered := append([]byte(nil), alice...)
+		tampered[len(tampered)-2] ^= 1
+		_, err = jwt.Parse(tampered, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.Error(t, err, `jwt.Parse should fail for tampered tokens`)
+	})
+	t.Run("eviction", func(t *testing.T) {
+		kp := &countingKeyProvider{key: &key.PublicKey}
+		cache := jwt.NewVerificationCache(1, time.Minute)
+		for _, signed := range [][]byte{alice, bob, alice} {
+			_, err := jwt.Parse(signed, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+			require.NoError(t, err, `jwt.Parse should succeed`)
+		}
+		require.Equal(t, int64(3), kp.Count(), `evicted tokens should be verified again`)
+	})
+	t.Run("ttl", func(t *testing.T) {
+		kp := &countingKeyProvider{key: &key.PublicKey}
+		cache := jwt.NewVerificationCache(10, time.Millisecond)
+		_, err := jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		time.Sleep(5 * time.Millisecond)
+		_, err = jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, int64(2), kp.Count(), `expired entries should be verified again`)
+	})
+	t.Run("no verification", func(t *testing.T) {
+		cache := jwt.NewVerificationCache(10, time.Minute)
+		_, err := jwt.ParseInsecure(alice, jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+
+		kp := &countingKeyProvider{key: &key.PublicKey}
+		_, err = jwt.Parse(alice, jwt.WithKeyProvider(kp), jwt.WithVerificationCache(cache))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, int64(1), kp.Count(), `unverified tokens should not be cached`)
+	})
+}
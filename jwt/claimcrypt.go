@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
+)
+
+// EncryptClaims replaces the values of the claims `names` in the token
+// `t` with JWE messages in compact serialization, whose payload is the
+// JSON representation of the original value. This allows a token whose
+// claims are mostly public to carry a few confidential values, which
+// can only be read by parties that hold the decryption key, even if
+// the token itself is passed through intermediaries.
+//
+// `options` are passed to `jwe.Encrypt()`, and must at least specify
+// the key to encrypt the values with:
+//
+//	err := jwt.EncryptClaims(tok, []string{`ssn`}, jwe.WithKey(jwa.RSA_OAEP, pubkey))
+//	...
+//	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, signingKey))
+//
+// Claims that are not present in the token are skipped. Only private
+// claims may be encrypted: the registered claims must remain readable,
+// as they are required to validate the token. If any of the claims
+// cannot be encrypted, an error is returned and the token is not modified.
+func EncryptClaims(t Token, names []string, options ...jwe.EncryptOption) error {
+	for _, name := range names {
+		if claims.IsRegistered(name) {
+			return fmt.Errorf(`jwt.EncryptClaims: registered claim %q cannot be encrypted`, name)
+		}
+	}
+
+	encrypted := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		v, ok := t.Get(name)
+		if !ok {
+			continue
+		}
+
+		plaintext, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf(`jwt.EncryptClaims: failed to marshal claim %q: %w`, name, err)
+		}
+		buf, err := jwe.Encrypt(plaintext, options...)
+		if err != nil {
+			return fmt.Errorf(`jwt.EncryptClaims: failed to encrypt claim %q: %w`, name, err)
+		}
+		encrypted[name] = string(buf)
+	}
+
+	if err := setClaims(t, encrypted); err != nil {
+		return fmt.Errorf(`jwt.EncryptClaims: %w`, err)
+	}
+	return nil
+}
+
+// DecryptClaim returns the decrypted value of the claim `name` in the
+// token `t`, which must have been encrypted using `jwt.EncryptClaims()`.
+// The token is not modified.
+//
+// `options` are passed to `jwe.Decrypt()`, and must at least specify
+// the key to decrypt the value with. The value is decoded from JSON in
+// the same way as private claims of a parsed token: for example, JSON
+// objects are returned as `map[string]interface{}`.
+func DecryptClaim(t Token, name string, options ...jwe.DecryptOption) (interface{}, error) {
+	v, ok := t.Get(name)
+	if !ok {
+		return nil, fmt.Errorf(`jwt.DecryptClaim: claim %q does not exist`, name)
+	}
+	return decryptClaimValue(name, v, options)
+}
+
+// DecryptClaims replaces the values of the claims `names` in the token
+// `t`, which must have been encrypted using `jwt.EncryptClaims()`, with
+// their decrypted values, so that they can be accessed as any other claim
+// afterwards (e.g. using `t.Get()`):
+//
+//	tok, err := jwt.Parse(src, jwt.WithKey(jwa.RS256, verificationKey))
+//	...
+//	err = jwt.DecryptClaims(tok, []string{`ssn`}, jwe.WithKey(jwa.RSA_OAEP, privkey))
+//
+// Claims that are not present in the token are skipped. If any of the
+// claims cannot be decrypted, an error is returned and the token is not
+// modified.
+func DecryptClaims(t Token, names []string, options ...jwe.DecryptOption) error {
+	decrypted := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		v, ok := t.Get(name)
+		if !ok {
+			continue
+		}
+		dv, err := decryptClaimValue(name, v, options)
+		if err != nil {
+			return fmt.Errorf(`jwt.DecryptClaims: %w`, err)
+		}
+		decrypted[name] = dv
+	}
+
+	if err := setClaims(t, decrypted); err != nil {
+		return fmt.Errorf(`jwt.DecryptClaims: %w`, err)
+	}
+	return nil
+}
+
+// setClaims sets the claims in `values` to the token `t`. The claims are
+// first set to a clone of `t`, so that `t` is left untouched if any of
+// them are rejected.
+func setClaims(t Token, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	clone, err := t.Clone()
+	if err != nil {
+		return fmt.Errorf(`failed to clone token: %w`, err)
+	}
+	for name, v := range values {
+		if err := clone.Set(name, v); err != nil {
+			return fmt.Errorf(`failed to set claim %q: %w`, name, err)
+		}
+	}
+	for name, v := range values {
+		if err := t.Set(name, v); err != nil {
+			return fmt.Errorf(`failed to set claim %q: %w`, name, err)
+		}
+	}
+	return nil
+}
+
+func decryptClaimValue(name string, v interface{}, options []jwe.DecryptOption) (interface{}, error) {
+	encrypted, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf(`claim %q is not encrypted: expected a string, got %T`, name, v)
+	}
+	plaintext, err := jwe.Decrypt([]byte(encrypted), options...)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to decrypt claim %q: %w`, name, err)
+	}
+	var dv interface{}
+	if err := json.Unmarshal(plaintext, &dv); err != nil {
+		return nil, fmt.Errorf(`failed to unmarshal claim %q: %w`, name, err)
+	}
+	return dv, nil
+}
@@ -0,0 +1,84 @@
+package jwt_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptClaims(t *testing.T) {
+	t.Parallel()
+
+	signingKey := []byte(`abracadabra-abracadabra-abracadabra`)
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Claim(`ssn`, `123-45-6789`).
+		Claim(`account`, map[string]interface{}{`number`: `0001`, `branch`: 42.0}).
+		Claim(`public`, `hello`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	require.NoError(t, jwt.EncryptClaims(tok, []string{`ssn`, `account`, `missing`}, jwe.WithKey(jwa.RSA_OAEP, &key.PublicKey)), `jwt.EncryptClaims should succeed`)
+	require.Error(t, jwt.EncryptClaims(tok, []string{jwt.SubjectKey}, jwe.WithKey(jwa.RSA_OAEP, &key.PublicKey)), `registered claims should be rejected`)
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, signingKey))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, signingKey))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+
+	// intermediaries can only see the encrypted values
+	v, ok := parsed.Get(`ssn`)
+	require.True(t, ok)
+	require.NotEqual(t, `123-45-6789`, v)
+	require.IsType(t, ``, v)
+	v, _ = parsed.Get(`public`)
+	require.Equal(t, `hello`, v)
+
+	ssn, err := jwt.DecryptClaim(parsed, `ssn`, jwe.WithKey(jwa.RSA_OAEP, key))
+	require.NoError(t, err, `jwt.DecryptClaim should succeed`)
+	require.Equal(t, `123-45-6789`, ssn)
+
+	_, err = jwt.DecryptClaim(parsed, `missing`, jwe.WithKey(jwa.RSA_OAEP, key))
+	require.Error(t, err, `jwt.DecryptClaim should fail for missing claims`)
+	_, err = jwt.DecryptClaim(parsed, `public`, jwe.WithKey(jwa.RSA_OAEP, key))
+	require.Error(t, err, `jwt.DecryptClaim should fail for claims that are not encrypted`)
+
+	other, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	require.Error(t, jwt.DecryptClaims(parsed, []string{`ssn`}, jwe.WithKey(jwa.RSA_OAEP, other)), `jwt.DecryptClaims should fail with the wrong key`)
+	require.Error(t, jwt.DecryptClaims(parsed, []string{`ssn`, `public`}, jwe.WithKey(jwa.RSA_OAEP, key)), `jwt.DecryptClaims should fail for claims that are not encrypted`)
+	v, _ = parsed.Get(`ssn`)
+	require.NotEqual(t, `123-45-6789`, v, `token should not be modified on failure`)
+
+	require.NoError(t, jwt.DecryptClaims(parsed, []string{`ssn`, `account`}, jwe.WithKey(jwa.RSA_OAEP, key)), `jwt.DecryptClaims should succeed`)
+	v, _ = parsed.Get(`ssn`)
+	require.Equal(t, `123-45-6789`, v)
+	v, _ = parsed.Get(`account`)
+	require.Equal(t, map[string]interface{}{`number`: `0001`, `branch`: 42.0}, v)
+}
+
+func TestEncryptClaimsFailure(t *testing.T) {
+	t.Parallel()
+
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	// email_verified must be a boolean in an openid.Token, so it cannot
+	// be replaced with its encrypted value
+	tok := openid.New()
+	require.NoError(t, tok.Set(openid.EmailKey, `alice@example.com`), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(openid.EmailVerifiedKey, true), `tok.Set should succeed`)
+
+	require.Error(t, jwt.EncryptClaims(tok, []string{openid.EmailKey, openid.EmailVerifiedKey}, jwe.WithKey(jwa.RSA_OAEP, &key.PublicKey)), `jwt.EncryptClaims should fail`)
+	require.Equal(t, `alice@example.com`, tok.Email(), `token should not be modified on failure`)
+	require.True(t, tok.EmailVerified(), `token should not be modified on failure`)
+}
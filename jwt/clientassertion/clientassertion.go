@@ -0,0 +1,212 @@
+// Package clientassertion creates JWTs that OAuth 2.0 clients use to
+// authenticate to the authorization server (RFC 7523 Section 2.2), using
+// the `private_key_jwt` and `client_secret_jwt` methods defined in
+// OpenID Connect Core 1.0 Section 9.
+//
+//	assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key)
+//	form := clientassertion.Params(assertion)
+//	form.Set(`grant_type`, `authorization_code`)
+//
+// Authorization servers can verify the assertions they receive, as well
+// as JWTs used as authorization grants, using `Verifier`.
+package clientassertion
+
+import (
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Names and values of the token request parameters used for client
+// authentication (RFC 7521 Section 4.2)
+const (
+	AssertionTypeJWTBearer = `urn:ietf:params:oauth:client-assertion-type:jwt-bearer`
+	AssertionKey           = `client_assertion`
+	AssertionTypeKey       = `client_assertion_type`
+)
+
+// DefaultLifetime is the default lifetime of assertions. Assertions are
+// used only once, immediately after they are created, so it is short.
+const DefaultLifetime = time.Minute
+
+const jtiSize = 16
+
+// PrivateKeyJWT creates an assertion for the `private_key_jwt` client
+// authentication method, signed by the client's private key `key`.
+// `key` may be a `jwk.Key` or a raw private key. `audience` is the
+// identifier of the authorization server, usually the URL of its token
+// endpoint.
+//
+// The `kid` header is set if `key` is a `jwk.Key` with a key ID.
+func PrivateKeyJWT(clientID, audience string, key interface{}, options ...Option) ([]byte, error) {
+	jwkKey, ok := key.(jwk.Key)
+	if !ok {
+		var err error
+		jwkKey, err = jwk.FromRaw(key)
+		if err != nil {
+			return nil, fmt.Errorf(`clientassertion.PrivateKeyJWT: failed to convert key: %w`, err)
+		}
+	}
+	if jwkKey.KeyType() == jwa.OctetSeq {
+		return nil, fmt.Errorf(`clientassertion.PrivateKeyJWT: use clientassertion.ClientSecretJWT() for shared secrets`)
+	}
+
+	// The algorithm specified via WithAlgorithm() takes precedence
+	var alg jwa.SignatureAlgorithm
+	for _, o := range options {
+		if o.Ident() == (identAlgorithm{}) {
+			//nolint:forcetypeassert
+			alg = o.Value().(jwa.SignatureAlgorithm)
+		}
+	}
+	if alg == `` {
+		var err error
+		alg, err = algorithmForKey(jwkKey)
+		if err != nil {
+			return nil, fmt.Errorf(`clientassertion.PrivateKeyJWT: %w`, err)
+		}
+	}
+
+	signed, err := build(clientID, audience, alg, jwkKey, options)
+	if err != nil {
+		return nil, fmt.Errorf(`clientassertion.PrivateKeyJWT: %w`, err)
+	}
+	return signed, nil
+}
+
+// ClientSecretJWT creates an assertion for the `client_secret_jwt` client
+// authentication method, signed with HMAC using the client secret `secret`.
+// `audience` is the identifier of the authorization server, usually the
+// URL of its token endpoint.
+func ClientSecretJWT(clientID, audience string, secret []byte, options ...Option) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf(`clientassertion.ClientSecretJWT: secret must not be empty`)
+	}
+
+	signed, err := build(clientID, audience, jwa.HS256, secret, options)
+	if err != nil {
+		return nil, fmt.Errorf(`clientassertion.ClientSecretJWT: %w`, err)
+	}
+	return signed, nil
+}
+
+// Params returns the token request parameters that carry `assertion`.
+func Params(assertion []byte) url.Values {
+	return url.Values{
+		AssertionTypeKey: {AssertionTypeJWTBearer},
+		AssertionKey:     {string(assertion)},
+	}
+}
+
+func build(clientID, audience string, alg jwa.SignatureAlgorithm, key interface{}, options []Option) ([]byte, error) {
+	if clientID == `` || audience == `` {
+		return nil, fmt.Errorf(`clientID and audience must be specified`)
+	}
+
+	var clock jwt.Clock = jwt.ClockFunc(time.Now)
+	var cert *x509.Certificate
+	lifetime := DefaultLifetime
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identAlgorithm{}:
+			alg = o.Value().(jwa.SignatureAlgorithm)
+		case identCertificate{}:
+			cert = o.Value().(*x509.Certificate)
+		case identClock{}:
+			clock = o.Value().(jwt.Clock)
+		case identLifetime{}:
+			lifetime = o.Value().(time.Duration)
+		}
+	}
+	if alg == jwa.NoSignature {
+		return nil, fmt.Errorf(`assertions must be signed`)
+	}
+	if lifetime <= 0 {
+		return nil, fmt.Errorf(`lifetime must be positive`)
+	}
+
+	jti := make([]byte, jtiSize)
+	if _, err := rand.Read(jti); err != nil {
+		return nil, fmt.Errorf(`failed to generate jti: %w`, err)
+	}
+
+	now := clock.Now()
+	tok, err := jwt.NewBuilder().
+		Issuer(clientID).
+		Subject(clientID).
+		Audience([]string{audience}).
+		JwtID(base64.EncodeToString(jti)).
+		IssuedAt(now).
+		Expiration(now.Add(lifetime)).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf(`failed to build assertion: %w`, err)
+	}
+
+	var signOptions []jwt.SignOption
+	if cert != nil {
+		hdrs := jws.NewHeaders()
+		//nolint:gosec
+		s1 := sha1.Sum(cert.Raw)
+		if err := hdrs.Set(jws.X509CertThumbprintKey, base64.EncodeToString(s1[:])); err != nil {
+			return nil, fmt.Errorf(`failed to set %q header: %w`, jws.X509CertThumbprintKey, err)
+		}
+		s256 := sha256.Sum256(cert.Raw)
+		if err := hdrs.Set(jws.X509CertThumbprintS256Key, base64.EncodeToString(s256[:])); err != nil {
+			return nil, fmt.Errorf(`failed to set %q header: %w`, jws.X509CertThumbprintS256Key, err)
+		}
+		signOptions = append(signOptions, jwt.WithJwsHeaders(hdrs))
+	}
+	signOptions = append(signOptions, jwt.WithKey(alg, key))
+
+	signed, err := jwt.Sign(tok, signOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to sign assertion: %w`, err)
+	}
+	return signed, nil
+}
+
+// algorithmForKey returns the `alg` field of `key`, or the default
+// signature algorithm for the type of `key` if it is not set
+func algorithmForKey(key jwk.Key) (jwa.SignatureAlgorithm, error) {
+	if v := key.Algorithm(); v != nil && v.String() != `` {
+		var alg jwa.SignatureAlgorithm
+		if err := alg.Accept(v.String()); err != nil {
+			return ``, fmt.Errorf(`invalid "alg" field of key: %w`, err)
+		}
+		return alg, nil
+	}
+
+	switch key := key.(type) {
+	case jwk.RSAPrivateKey:
+		return jwa.RS256, nil
+	case jwk.ECDSAPrivateKey:
+		switch key.Crv() {
+		case jwa.P256:
+			return jwa.ES256, nil
+		case jwa.P384:
+			return jwa.ES384, nil
+		case jwa.P521:
+			return jwa.ES512, nil
+		}
+		return ``, fmt.Errorf(`cannot determine algorithm for curve %q (use clientassertion.WithAlgorithm())`, key.Crv())
+	case jwk.OKPPrivateKey:
+		if key.Crv() == jwa.Ed25519 {
+			return jwa.EdDSA, nil
+		}
+		return ``, fmt.Errorf(`cannot determine algorithm for curve %q`, key.Crv())
+	default:
+		return ``, fmt.Errorf(`private key is required (%T)`, key)
+	}
+}
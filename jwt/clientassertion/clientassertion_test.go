@@ -0,0 +1,141 @@
+package clientassertion_test
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/clientassertion"
+	"github.com/stretchr/testify/require"
+)
+
+const clientID = `s6BhdRkqt3`
+const tokenEndpoint = `https://server.example.com/token`
+
+func checkClaims(t *testing.T, tok jwt.Token) {
+	t.Helper()
+	require.Equal(t, clientID, tok.Issuer())
+	require.Equal(t, clientID, tok.Subject())
+	require.Equal(t, []string{tokenEndpoint}, tok.Audience())
+	require.NotEmpty(t, tok.JwtID(), `jti should be set`)
+	require.Equal(t, clientassertion.DefaultLifetime, tok.Expiration().Sub(tok.IssuedAt()))
+}
+
+func TestPrivateKeyJWT(t *testing.T) {
+	t.Run("jwk.Key", func(t *testing.T) {
+		key, err := jwxtest.GenerateRsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+		require.NoError(t, key.Set(jwk.KeyIDKey, `client-key-1`), `key.Set should succeed`)
+
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		msg, err := jws.Parse(assertion)
+		require.NoError(t, err, `jws.Parse should succeed`)
+		hdrs := msg.Signatures()[0].ProtectedHeaders()
+		require.Equal(t, jwa.RS256, hdrs.Algorithm())
+		require.Equal(t, `client-key-1`, hdrs.KeyID())
+
+		pubkey, err := key.PublicKey()
+		require.NoError(t, err, `key.PublicKey should succeed`)
+		tok, err := jwt.Parse(assertion, jwt.WithKey(jwa.RS256, pubkey))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		checkClaims(t, tok)
+
+		// jti must be unique
+		other, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+		otherTok, err := jwt.Parse(other, jwt.WithKey(jwa.RS256, pubkey))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.NotEqual(t, tok.JwtID(), otherTok.JwtID())
+	})
+	t.Run("raw key", func(t *testing.T) {
+		key, err := jwxtest.GenerateEcdsaKey(jwa.P384)
+		require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+
+		created := time.Now().Add(-time.Hour)
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key,
+			clientassertion.WithClock(jwt.ClockFunc(func() time.Time { return created })),
+			clientassertion.WithLifetime(5*time.Minute),
+		)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		tok, err := jwt.Parse(assertion, jwt.WithKey(jwa.ES384, &key.PublicKey), jwt.WithValidate(false))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, created.Unix(), tok.IssuedAt().Unix())
+		require.Equal(t, 5*time.Minute, tok.Expiration().Sub(tok.IssuedAt()))
+	})
+	t.Run("certificate", func(t *testing.T) {
+		key, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: clientID},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err, `x509.CreateCertificate should succeed`)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err, `x509.ParseCertificate should succeed`)
+
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key,
+			clientassertion.WithCertificate(cert),
+			clientassertion.WithAlgorithm(jwa.PS256),
+		)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		msg, err := jws.Parse(assertion)
+		require.NoError(t, err, `jws.Parse should succeed`)
+		hdrs := msg.Signatures()[0].ProtectedHeaders()
+		require.Equal(t, jwa.PS256, hdrs.Algorithm())
+		require.NotEmpty(t, hdrs.X509CertThumbprint(), `x5t should be set`)
+		require.NotEmpty(t, hdrs.X509CertThumbprintS256(), `x5t#S256 should be set`)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		key, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+		require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+
+		_, err = clientassertion.PrivateKeyJWT(``, tokenEndpoint, key)
+		require.Error(t, err, `clientID should be required`)
+		_, err = clientassertion.PrivateKeyJWT(clientID, ``, key)
+		require.Error(t, err, `audience should be required`)
+		_, err = clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, &key.PublicKey)
+		require.Error(t, err, `public keys should be rejected`)
+		_, err = clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, []byte(`secret`))
+		require.Error(t, err, `shared secrets should be rejected`)
+		_, err = clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key, clientassertion.WithAlgorithm(jwa.NoSignature))
+		require.Error(t, err, `"none" should be rejected`)
+	})
+}
+
+func TestClientSecretJWT(t *testing.T) {
+	secret := []byte(`a-client-secret-that-is-long-enough-for-hs256`)
+
+	assertion, err := clientassertion.ClientSecretJWT(clientID, tokenEndpoint, secret)
+	require.NoError(t, err, `clientassertion.ClientSecretJWT should succeed`)
+
+	tok, err := jwt.Parse(assertion, jwt.WithKey(jwa.HS256, secret))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+	checkClaims(t, tok)
+
+	params := clientassertion.Params(assertion)
+	require.Equal(t, clientassertion.AssertionTypeJWTBearer, params.Get(clientassertion.AssertionTypeKey))
+	require.Equal(t, string(assertion), params.Get(clientassertion.AssertionKey))
+
+	assertion, err = clientassertion.ClientSecretJWT(clientID, tokenEndpoint, secret, clientassertion.WithAlgorithm(jwa.HS512))
+	require.NoError(t, err, `clientassertion.ClientSecretJWT should succeed`)
+	_, err = jwt.Parse(assertion, jwt.WithKey(jwa.HS512, secret))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+
+	_, err = clientassertion.ClientSecretJWT(clientID, tokenEndpoint, nil)
+	require.Error(t, err, `empty secrets should be rejected`)
+}
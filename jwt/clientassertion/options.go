@@ -0,0 +1,71 @@
+package clientassertion
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to
+// `clientassertion.PrivateKeyJWT()`, `clientassertion.ClientSecretJWT()`,
+// and `clientassertion.NewVerifier()`.
+type Option = option.Interface
+
+type identAlgorithm struct{}
+type identCertificate struct{}
+type identClock struct{}
+type identLifetime struct{}
+type identAudience struct{}
+type identMaxLifetime struct{}
+type identAcceptableSkew struct{}
+
+// WithAlgorithm specifies the signature algorithm. By default, the `alg`
+// field of the key is used for `clientassertion.PrivateKeyJWT()`, or the
+// algorithm is chosen based on the type of the key if it is not set.
+// `clientassertion.ClientSecretJWT()` uses HS256 by default.
+func WithAlgorithm(v jwa.SignatureAlgorithm) Option {
+	return option.New(identAlgorithm{}, v)
+}
+
+// WithCertificate specifies the X.509 certificate that corresponds to
+// the private key. The `x5t` and `x5t#S256` headers of the assertion are
+// set to its thumbprints, which some authorization servers use to look up
+// the key instead of `kid`.
+func WithCertificate(v *x509.Certificate) Option {
+	return option.New(identCertificate{}, v)
+}
+
+// WithClock specifies the clock used to compute the `iat` and `exp` claims,
+// or, for `clientassertion.NewVerifier()`, to validate them.
+func WithClock(v jwt.Clock) Option {
+	return option.New(identClock{}, v)
+}
+
+// WithLifetime specifies the lifetime of the assertion, i.e. the difference
+// between the `exp` and `iat` claims. The default is `DefaultLifetime`.
+func WithLifetime(v time.Duration) Option {
+	return option.New(identLifetime{}, v)
+}
+
+// WithAudience specifies an additional value that `Verifier` accepts in
+// the `aud` claim, such as the issuer identifier of the authorization
+// server. It may be specified multiple times.
+func WithAudience(v string) Option {
+	return option.New(identAudience{}, v)
+}
+
+// WithMaxLifetime specifies the maximum lifetime of the assertions that
+// `Verifier` accepts, i.e. the difference between the `exp` and `iat`
+// claims. The default is `DefaultMaxLifetime`.
+func WithMaxLifetime(v time.Duration) Option {
+	return option.New(identMaxLifetime{}, v)
+}
+
+// WithAcceptableSkew specifies the clock skew that `Verifier` tolerates
+// when validating the `exp`, `nbf`, and `iat` claims.
+func WithAcceptableSkew(v time.Duration) Option {
+	return option.New(identAcceptableSkew{}, v)
+}
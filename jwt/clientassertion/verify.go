@@ -0,0 +1,218 @@
+package clientassertion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// DefaultMaxLifetime is the default maximum lifetime of the assertions
+// accepted by `Verifier`.
+const DefaultMaxLifetime = 5 * time.Minute
+
+// KeyFunc returns the keys that may be used to verify assertions issued by
+// `issuer`. For client authentication, `issuer` is the client ID, and the
+// keys are those registered for the client (e.g. via its `jwks` or `jwks_uri`
+// metadata, or its client secret as a `jwk.Key` for `client_secret_jwt`).
+// For authorization grants, `issuer` is the identifier of the issuer that
+// the authorization server trusts.
+//
+// KeyFunc should return an error if `issuer` is unknown.
+type KeyFunc func(ctx context.Context, issuer string) (jwk.Set, error)
+
+// Verifier verifies the assertions received at a token endpoint, as
+// described in RFC 7523 Section 3: JWTs used for client authentication
+// (`client_assertion`), and JWTs used as authorization grants
+// (`grant_type=urn:ietf:params:oauth:grant-type:jwt-bearer`).
+//
+// An assertion is accepted only if
+//
+//   - it is signed by one of the keys returned by the `KeyFunc` for its issuer,
+//   - its `aud` claim contains the identifier of the authorization server,
+//   - it contains the `iss`, `sub`, `exp`, and `jti` claims, and has not expired,
+//   - it is valid for no longer than the maximum lifetime, and
+//   - its `jti` has not been used before.
+//
+// A Verifier may be used from multiple goroutines concurrently.
+type Verifier struct {
+	audiences   []string
+	keys        KeyFunc
+	replay      jwt.ReplayStore
+	maxLifetime time.Duration
+	skew        time.Duration
+	clock       jwt.Clock
+}
+
+// NewVerifier creates a new Verifier for the authorization server
+// identified by `audience`, usually the URL of its token endpoint.
+// The `jti` of each accepted assertion is recorded in `replay` (see
+// `jwt.NewMemoryReplayStore()`) so that assertions can only be used once.
+//
+// `WithAudience()`, `WithMaxLifetime()`, `WithAcceptableSkew()`, and
+// `WithClock()` may be specified as options.
+func NewVerifier(audience string, keys KeyFunc, replay jwt.ReplayStore, options ...Option) (*Verifier, error) {
+	if audience == `` {
+		return nil, fmt.Errorf(`clientassertion.NewVerifier: audience must be specified`)
+	}
+	if keys == nil {
+		return nil, fmt.Errorf(`clientassertion.NewVerifier: KeyFunc is required`)
+	}
+	if replay == nil {
+		return nil, fmt.Errorf(`clientassertion.NewVerifier: ReplayStore is required`)
+	}
+
+	v := &Verifier{
+		audiences:   []string{audience},
+		keys:        keys,
+		replay:      replay,
+		maxLifetime: DefaultMaxLifetime,
+		clock:       jwt.ClockFunc(time.Now),
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identAudience{}:
+			v.audiences = append(v.audiences, o.Value().(string))
+		case identMaxLifetime{}:
+			v.maxLifetime = o.Value().(time.Duration)
+		case identAcceptableSkew{}:
+			v.skew = o.Value().(time.Duration)
+		case identClock{}:
+			// a nil Clock is reported below
+			v.clock, _ = o.Value().(jwt.Clock)
+		default:
+			return nil, fmt.Errorf(`clientassertion.NewVerifier: invalid option %T`, o)
+		}
+	}
+	if v.maxLifetime <= 0 {
+		return nil, fmt.Errorf(`clientassertion.NewVerifier: maximum lifetime must be positive`)
+	}
+	if v.skew < 0 {
+		return nil, fmt.Errorf(`clientassertion.NewVerifier: skew must not be negative`)
+	}
+	if v.clock == nil {
+		return nil, fmt.Errorf(`clientassertion.NewVerifier: clock must not be nil`)
+	}
+	return v, nil
+}
+
+// VerifyClientAssertion verifies an assertion used for client
+// authentication. Its `iss` and `sub` claims must both be the client ID.
+// If `clientID` (the `client_id` request parameter) is not empty, the
+// assertion must have been issued by that client.
+//
+// The returned token identifies the authenticated client by its `sub` claim.
+func (v *Verifier) VerifyClientAssertion(ctx context.Context, assertion []byte, clientID string) (jwt.Token, error) {
+	tok, err := v.verify(ctx, assertion, clientID, true)
+	if err != nil {
+		return nil, fmt.Errorf(`clientassertion.VerifyClientAssertion: %w`, err)
+	}
+	return tok, nil
+}
+
+// VerifyParams verifies the client assertion carried by the token request
+// parameters `form`, as created by `Params()`. The `client_assertion_type`
+// parameter must be `AssertionTypeJWTBearer`, and the `client_id`
+// parameter, if present, must match the assertion.
+func (v *Verifier) VerifyParams(ctx context.Context, form url.Values) (jwt.Token, error) {
+	if typ := form.Get(AssertionTypeKey); typ != AssertionTypeJWTBearer {
+		return nil, fmt.Errorf(`clientassertion.VerifyParams: unsupported %s %q`, AssertionTypeKey, typ)
+	}
+	assertion := form.Get(AssertionKey)
+	if assertion == `` {
+		return nil, fmt.Errorf(`clientassertion.VerifyParams: %s is missing`, AssertionKey)
+	}
+
+	tok, err := v.verify(ctx, []byte(assertion), form.Get(`client_id`), true)
+	if err != nil {
+		return nil, fmt.Errorf(`clientassertion.VerifyParams: %w`, err)
+	}
+	return tok, nil
+}
+
+// VerifyAuthorizationGrant verifies an assertion used as an authorization
+// grant (RFC 7523 Section 2.1). Its `iss` claim identifies the issuer whose
+// keys are used to verify it, and its `sub` claim identifies the resource
+// owner for whom the access token is requested.
+func (v *Verifier) VerifyAuthorizationGrant(ctx context.Context, assertion []byte) (jwt.Token, error) {
+	tok, err := v.verify(ctx, assertion, ``, false)
+	if err != nil {
+		return nil, fmt.Errorf(`clientassertion.VerifyAuthorizationGrant: %w`, err)
+	}
+	return tok, nil
+}
+
+func (v *Verifier) verify(ctx context.Context, assertion []byte, clientID string, client bool) (jwt.Token, error) {
+	// The issuer is needed to look up the keys, so it must be read
+	// before the assertion is verified. It is checked again below
+	unverified, err := jwt.ParseInsecure(assertion)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse assertion: %w`, err)
+	}
+	issuer := unverified.Issuer()
+	if issuer == `` {
+		return nil, fmt.Errorf(`%q claim is missing`, jwt.IssuerKey)
+	}
+	if clientID != `` && issuer != clientID {
+		return nil, fmt.Errorf(`assertion was not issued by client %q`, clientID)
+	}
+
+	set, err := v.keys(ctx, issuer)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to get keys for %q: %w`, issuer, err)
+	}
+	if set == nil || set.Len() == 0 {
+		return nil, fmt.Errorf(`no keys registered for %q`, issuer)
+	}
+
+	options := []jwt.ParseOption{
+		jwt.WithKeySet(set, jws.WithRequireKid(false), jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithContext(ctx),
+		jwt.WithClock(v.clock),
+		jwt.WithAcceptableSkew(v.skew),
+		jwt.WithIssuer(issuer),
+		jwt.WithRequiredClaims(jwt.SubjectKey, jwt.ExpirationKey, jwt.JwtIDKey),
+		jwt.WithValidator(jwt.ValidatorFunc(v.validateAudience)),
+		jwt.WithValidator(jwt.ValidatorFunc(v.validateLifetime)),
+		jwt.WithReplayDetection(v.replay),
+	}
+	if client {
+		options = append(options, jwt.WithSubject(issuer))
+	}
+
+	tok, err := jwt.Parse(assertion, options...)
+	if err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+func (v *Verifier) validateAudience(_ context.Context, t jwt.Token) jwt.ValidationError {
+	for _, aud := range t.Audience() {
+		for _, expected := range v.audiences {
+			if aud == expected {
+				return nil
+			}
+		}
+	}
+	return jwt.ErrInvalidAudience()
+}
+
+func (v *Verifier) validateLifetime(_ context.Context, t jwt.Token) jwt.ValidationError {
+	// Without `iat`, the lifetime is measured from the time of verification,
+	// so that assertions that remain valid for too long are still rejected
+	start := t.IssuedAt()
+	if start.IsZero() {
+		start = v.clock.Now()
+	}
+	if lifetime := t.Expiration().Sub(start); lifetime > v.maxLifetime+v.skew {
+		return jwt.NewValidationError(errors.New(`assertion is valid for longer than the maximum lifetime`))
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package clientassertion_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/clientassertion"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier(t *testing.T) {
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `client-key-1`), `key.Set should succeed`)
+	pubkey, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+
+	secret := []byte(`a very long client secret that is used for HMAC`)
+	secretKey, err := jwk.FromRaw(secret)
+	require.NoError(t, err, `jwk.FromRaw should succeed`)
+
+	const secretClientID = `secret-client`
+	const grantIssuer = `https://idp.example.com`
+	keys := func(_ context.Context, issuer string) (jwk.Set, error) {
+		set := jwk.NewSet()
+		switch issuer {
+		case clientID, grantIssuer:
+			_ = set.AddKey(pubkey)
+		case secretClientID:
+			_ = set.AddKey(secretKey)
+		default:
+			return nil, fmt.Errorf(`unknown issuer %q`, issuer)
+		}
+		return set, nil
+	}
+
+	newVerifier := func(t *testing.T, options ...clientassertion.Option) *clientassertion.Verifier {
+		t.Helper()
+		v, err := clientassertion.NewVerifier(tokenEndpoint, keys, jwt.NewMemoryReplayStore(time.Hour), options...)
+		require.NoError(t, err, `clientassertion.NewVerifier should succeed`)
+		return v
+	}
+
+	sign := func(t *testing.T, b *jwt.Builder) []byte {
+		t.Helper()
+		tok, err := b.Build()
+		require.NoError(t, err, `Build should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+
+	t.Run("private_key_jwt", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		tok, err := v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.NoError(t, err, `VerifyClientAssertion should succeed`)
+		require.Equal(t, clientID, tok.Subject())
+
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.True(t, errors.Is(err, jwt.ErrTokenReplayed()), `assertions should only be accepted once`)
+	})
+	t.Run("client_secret_jwt", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion, err := clientassertion.ClientSecretJWT(secretClientID, tokenEndpoint, secret)
+		require.NoError(t, err, `clientassertion.ClientSecretJWT should succeed`)
+
+		tok, err := v.VerifyClientAssertion(context.Background(), assertion, ``)
+		require.NoError(t, err, `VerifyClientAssertion should succeed`)
+		require.Equal(t, secretClientID, tok.Subject())
+	})
+	t.Run("params", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		form := clientassertion.Params(assertion)
+		form.Set(`client_id`, `other-client`)
+		_, err = v.VerifyParams(context.Background(), form)
+		require.Error(t, err, `client_id should match the assertion`)
+
+		form.Set(`client_id`, clientID)
+		form.Set(clientassertion.AssertionTypeKey, `urn:example:other`)
+		_, err = v.VerifyParams(context.Background(), form)
+		require.Error(t, err, `unsupported assertion types should be rejected`)
+
+		form.Set(clientassertion.AssertionTypeKey, clientassertion.AssertionTypeJWTBearer)
+		_, err = v.VerifyParams(context.Background(), form)
+		require.NoError(t, err, `VerifyParams should succeed`)
+
+		_, err = v.VerifyParams(context.Background(), url.Values{clientassertion.AssertionTypeKey: {clientassertion.AssertionTypeJWTBearer}})
+		require.Error(t, err, `missing assertions should be rejected`)
+	})
+	t.Run("wrong audience", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, `https://other.example.com/token`, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `error should match jwt.ErrInvalidAudience()`)
+	})
+	t.Run("additional audience", func(t *testing.T) {
+		v := newVerifier(t, clientassertion.WithAudience(`https://server.example.com`))
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, `https://server.example.com`, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.NoError(t, err, `VerifyClientAssertion should succeed`)
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		v := newVerifier(t)
+		other, err := jwxtest.GenerateRsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, other)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.Error(t, err, `assertions signed by other keys should be rejected`)
+	})
+	t.Run("unknown client", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion, err := clientassertion.PrivateKeyJWT(`unknown`, tokenEndpoint, key)
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, ``)
+		require.Error(t, err, `assertions from unknown clients should be rejected`)
+	})
+	t.Run("subject must match client", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion := sign(t, jwt.NewBuilder().
+			Issuer(clientID).
+			Subject(`someone-else`).
+			Audience([]string{tokenEndpoint}).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Minute)).
+			JwtID(`jti-1`))
+
+		_, err := v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.Error(t, err, `sub should be the client ID`)
+	})
+	t.Run("lifetime", func(t *testing.T) {
+		v := newVerifier(t, clientassertion.WithMaxLifetime(time.Minute))
+		assertion, err := clientassertion.PrivateKeyJWT(clientID, tokenEndpoint, key, clientassertion.WithLifetime(time.Hour))
+		require.NoError(t, err, `clientassertion.PrivateKeyJWT should succeed`)
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.Error(t, err, `assertions with long lifetimes should be rejected`)
+
+		// without iat, the lifetime is measured from now
+		assertion = sign(t, jwt.NewBuilder().
+			Issuer(clientID).
+			Subject(clientID).
+			Audience([]string{tokenEndpoint}).
+			Expiration(time.Now().Add(time.Hour)).
+			JwtID(`jti-2`))
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.Error(t, err, `assertions with long lifetimes should be rejected`)
+	})
+	t.Run("required claims", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion := sign(t, jwt.NewBuilder().
+			Issuer(clientID).
+			Subject(clientID).
+			Audience([]string{tokenEndpoint}).
+			Expiration(time.Now().Add(time.Minute)))
+		_, err := v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.Error(t, err, `jti should be required`)
+
+		assertion = sign(t, jwt.NewBuilder().
+			Issuer(clientID).
+			Subject(clientID).
+			Audience([]string{tokenEndpoint}).
+			JwtID(`jti-3`))
+		_, err = v.VerifyClientAssertion(context.Background(), assertion, clientID)
+		require.Error(t, err, `exp should be required`)
+	})
+	t.Run("authorization grant", func(t *testing.T) {
+		v := newVerifier(t)
+		assertion := sign(t, jwt.NewBuilder().
+			Issuer(grantIssuer).
+			Subject(`alice`).
+			Audience([]string{tokenEndpoint}).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Minute)).
+			JwtID(`jti-4`))
+
+		tok, err := v.VerifyAuthorizationGrant(context.Background(), assertion)
+		require.NoError(t, err, `VerifyAuthorizationGrant should succeed`)
+		require.Equal(t, `alice`, tok.Subject())
+
+		_, err = v.VerifyAuthorizationGrant(context.Background(), assertion)
+		require.True(t, errors.Is(err, jwt.ErrTokenReplayed()), `grants should only be accepted once`)
+	})
+	t.Run("options", func(t *testing.T) {
+		store := jwt.NewMemoryReplayStore(time.Hour)
+		_, err := clientassertion.NewVerifier(``, keys, store)
+		require.Error(t, err, `audience should be required`)
+		_, err = clientassertion.NewVerifier(tokenEndpoint, nil, store)
+		require.Error(t, err, `KeyFunc should be required`)
+		_, err = clientassertion.NewVerifier(tokenEndpoint, keys, nil)
+		require.Error(t, err, `ReplayStore should be required`)
+		_, err = clientassertion.NewVerifier(tokenEndpoint, keys, store, clientassertion.WithMaxLifetime(0))
+		require.Error(t, err, `maximum lifetime should be positive`)
+		_, err = clientassertion.NewVerifier(tokenEndpoint, keys, store, clientassertion.WithLifetime(time.Minute))
+		require.Error(t, err, `unsupported options should be rejected`)
+	})
+}
@@ -0,0 +1,165 @@
+// Package cnf implements the `cnf` (confirmation) claim, which binds a
+// token to a key held by its presenter (RFC 7800).
+//
+// Certificate-bound access tokens (RFC 8705 Section 3) carry the SHA-256
+// thumbprint of the client's TLS certificate in the `x5t#S256` member, and
+// DPoP-bound access tokens (RFC 9449 Section 6) carry the JWK SHA-256
+// thumbprint of the client's key in the `jkt` member. Authorization servers
+// use `cnf.ForCertificate()` and `cnf.ForKey()` to create the claim, and
+// resource servers use `cnf.WithCertificate()` and `cnf.WithKey()` to
+// validate it:
+//
+//	cert, err := cnf.CertificateFromRequest(req)
+//	tok, err := jwt.Parse(src, jwt.WithKeySet(set), cnf.WithCertificate(cert))
+package cnf
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Names of the `cnf` claim and its members
+const (
+	Key                   = `cnf`
+	X509ThumbprintS256Key = `x5t#S256`
+	JWKThumbprintKey      = `jkt`
+	JWKKey                = `jwk`
+	KeyIDKey              = `kid`
+)
+
+var errConfirmationMismatch = jwt.NewValidationError(errors.New(`"cnf" claim does not match the presented key`))
+
+// ErrConfirmationMismatch returns the immutable error used when the
+// `cnf` claim does not match the certificate or key presented by the client.
+func ErrConfirmationMismatch() jwt.ValidationError {
+	return errConfirmationMismatch
+}
+
+// CertificateThumbprint returns the base64url encoded SHA-256 thumbprint
+// of the DER encoding of `cert`, as used in the `x5t#S256` member.
+func CertificateThumbprint(cert *x509.Certificate) string {
+	h := sha256.Sum256(cert.Raw)
+	return base64.EncodeToString(h[:])
+}
+
+// KeyThumbprint returns the base64url encoded JWK SHA-256 thumbprint
+// (RFC 7638) of `key`, as used in the `jkt` member. `key` may be a
+// `jwk.Key` or a raw key. Private keys are converted to their public keys.
+func KeyThumbprint(key interface{}) (string, error) {
+	pubkey, err := jwk.PublicKeyOf(key)
+	if err != nil {
+		return ``, fmt.Errorf(`failed to obtain public key: %w`, err)
+	}
+	tp, err := pubkey.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return ``, fmt.Errorf(`failed to compute thumbprint: %w`, err)
+	}
+	return base64.EncodeToString(tp), nil
+}
+
+// ForCertificate returns the value of the `cnf` claim that binds a token
+// to the client certificate `cert`.
+//
+//	tok.Set(cnf.Key, cnf.ForCertificate(cert))
+func ForCertificate(cert *x509.Certificate) map[string]interface{} {
+	return map[string]interface{}{X509ThumbprintS256Key: CertificateThumbprint(cert)}
+}
+
+// ForKey returns the value of the `cnf` claim that binds a token to
+// the public key of `key`.
+func ForKey(key interface{}) (map[string]interface{}, error) {
+	tp, err := KeyThumbprint(key)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{JWKThumbprintKey: tp}, nil
+}
+
+// CertificateFromRequest returns the certificate presented by the client
+// over mutual TLS. An error is returned if the request was not made over
+// TLS, or if the client did not present a certificate.
+//
+// If TLS is terminated by a reverse proxy, the certificate must be
+// obtained in a way specific to the proxy instead.
+func CertificateFromRequest(r *http.Request) (*x509.Certificate, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf(`cnf.CertificateFromRequest: client certificate was not presented`)
+	}
+	return r.TLS.PeerCertificates[0], nil
+}
+
+// WithCertificate returns a `jwt.ValidateOption` that requires the token
+// to be bound to the client certificate `cert` (RFC 8705 Section 3.1):
+// the `x5t#S256` member of the `cnf` claim must match the thumbprint of
+// `cert`.
+func WithCertificate(cert *x509.Certificate) jwt.ValidateOption {
+	return jwt.WithValidator(member(X509ThumbprintS256Key, CertificateThumbprint(cert)))
+}
+
+// WithKey returns a `jwt.ValidateOption` that requires the token to be
+// bound to `key` (e.g. the key of a verified DPoP proof): the `jkt` member
+// of the `cnf` claim must match the JWK SHA-256 thumbprint of `key`.
+//
+// If the thumbprint of `key` cannot be computed, validation always fails.
+func WithKey(key interface{}) jwt.ValidateOption {
+	tp, err := KeyThumbprint(key)
+	if err != nil {
+		verr := jwt.NewValidationError(err)
+		return jwt.WithValidator(jwt.ValidatorFunc(func(context.Context, jwt.Token) jwt.ValidationError {
+			return verr
+		}))
+	}
+	return jwt.WithValidator(member(JWKThumbprintKey, tp))
+}
+
+type memberValidator struct {
+	name     string
+	expected string
+}
+
+func member(name, expected string) jwt.Validator {
+	return &memberValidator{name: name, expected: expected}
+}
+
+func (v *memberValidator) Validate(_ context.Context, t jwt.Token) jwt.ValidationError {
+	claim, err := Get(t)
+	if err != nil {
+		return jwt.NewValidationError(err)
+	}
+	if claim == nil {
+		return jwt.ErrMissingRequiredClaim(Key)
+	}
+
+	actual, ok := claim[v.name].(string)
+	if !ok {
+		return jwt.NewValidationError(fmt.Errorf(`%q claim does not have a %q member`, Key, v.name))
+	}
+	if subtle.ConstantTimeCompare([]byte(actual), []byte(v.expected)) != 1 {
+		return errConfirmationMismatch
+	}
+	return nil
+}
+
+// Get returns the value of the `cnf` claim of `t`, or nil if `t` does
+// not have one.
+func Get(t jwt.Token) (map[string]interface{}, error) {
+	v, ok := t.Get(Key)
+	if !ok {
+		return nil, nil
+	}
+	claim, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`invalid %q claim (%T)`, Key, v)
+	}
+	return claim, nil
+}
@@ -0,0 +1,99 @@
+package cnf_test
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/cnf"
+	"github.com/stretchr/testify/require"
+)
+
+func newCertificate(t *testing.T, cn string) *x509.Certificate {
+	t.Helper()
+	key, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, `x509.CreateCertificate should succeed`)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, `x509.ParseCertificate should succeed`)
+	return cert
+}
+
+func TestCertificateBinding(t *testing.T) {
+	cert := newCertificate(t, `client`)
+	other := newCertificate(t, `other`)
+
+	req := httptest.NewRequest(`GET`, `https://resource.example.com/`, nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	presented, err := cnf.CertificateFromRequest(req)
+	require.NoError(t, err, `cnf.CertificateFromRequest should succeed`)
+	require.Equal(t, cert, presented)
+
+	req.TLS = nil
+	_, err = cnf.CertificateFromRequest(req)
+	require.Error(t, err, `cnf.CertificateFromRequest should fail without TLS`)
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(cnf.Key, cnf.ForCertificate(cert)), `tok.Set should succeed`)
+
+	key, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.ES256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.ES256, &key.PublicKey), cnf.WithCertificate(presented))
+	require.NoError(t, err, `jwt.Parse should succeed for the bound certificate`)
+
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.ES256, &key.PublicKey), cnf.WithCertificate(other))
+	require.True(t, errors.Is(err, cnf.ErrConfirmationMismatch()), `jwt.Parse should fail for other certificates`)
+
+	err = jwt.Validate(jwt.New(), cnf.WithCertificate(cert))
+	require.True(t, errors.Is(err, jwt.ErrMissingRequiredClaim(cnf.Key)), `jwt.Validate should fail without cnf`)
+
+	// Token bound to a key rather than a certificate
+	keyBound := jwt.New()
+	jkt, err := cnf.ForKey(key)
+	require.NoError(t, err, `cnf.ForKey should succeed`)
+	require.NoError(t, keyBound.Set(cnf.Key, jkt), `keyBound.Set should succeed`)
+	require.Error(t, jwt.Validate(keyBound, cnf.WithCertificate(cert)), `jwt.Validate should fail without x5t#S256`)
+}
+
+func TestKeyBinding(t *testing.T) {
+	key, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	other, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+
+	v, err := cnf.ForKey(key)
+	require.NoError(t, err, `cnf.ForKey should succeed`)
+	tok := jwt.New()
+	require.NoError(t, tok.Set(cnf.Key, v), `tok.Set should succeed`)
+
+	pubkey, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+	require.NoError(t, jwt.Validate(tok, cnf.WithKey(pubkey)), `jwt.Validate should succeed for the bound public key`)
+	require.NoError(t, jwt.Validate(tok, cnf.WithKey(key)), `jwt.Validate should succeed for the bound private key`)
+
+	err = jwt.Validate(tok, cnf.WithKey(other))
+	require.True(t, errors.Is(err, cnf.ErrConfirmationMismatch()), `jwt.Validate should fail for other keys`)
+
+	require.Error(t, jwt.Validate(tok, cnf.WithKey(`not a key`)), `jwt.Validate should fail for invalid keys`)
+}
@@ -0,0 +1,280 @@
+// Package cwt converts the claims of a `jwt.Token` to and from the claims
+// of a CBOR Web Token (CWT, RFC 8392), so that the same claim model can be
+// used for JWTs and CWTs.
+//
+// In a CWT, the registered claims are identified by integer keys instead
+// of names (RFC 8392 Section 4). `cwt.FromToken()` returns the claims as a
+// map whose keys are integers (for registered claims) or strings (for other
+// claims), which can be encoded using the CBOR library of your choice:
+//
+//	claims, err := cwt.FromToken(tok)
+//	...
+//	payload, err := cbor.Marshal(claims)
+//
+// `cwt.ToToken()` converts decoded claims back to a `jwt.Token`. This package
+// does not implement CBOR encoding, nor COSE signing and encryption.
+package cwt
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// The keys of the registered claims (RFC 8392 Section 3.1)
+const (
+	IssuerKey     int64 = 1
+	SubjectKey    int64 = 2
+	AudienceKey   int64 = 3
+	ExpirationKey int64 = 4
+	NotBeforeKey  int64 = 5
+	IssuedAtKey   int64 = 6
+	CWTIDKey      int64 = 7
+)
+
+type claimMapping struct {
+	byName map[string]int64
+	byKey  map[int64]string
+}
+
+func newClaimMapping(options []Option) (*claimMapping, error) {
+	m := &claimMapping{
+		byName: map[string]int64{
+			jwt.IssuerKey:     IssuerKey,
+			jwt.SubjectKey:    SubjectKey,
+			jwt.AudienceKey:   AudienceKey,
+			jwt.ExpirationKey: ExpirationKey,
+			jwt.NotBeforeKey:  NotBeforeKey,
+			jwt.IssuedAtKey:   IssuedAtKey,
+			jwt.JwtIDKey:      CWTIDKey,
+		},
+	}
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identClaimKey{}:
+			pair := option.Value().(claimKey)
+			if _, ok := m.byName[pair.name]; ok {
+				return nil, fmt.Errorf(`claim %q is already mapped`, pair.name)
+			}
+			m.byName[pair.name] = pair.key
+		}
+	}
+
+	m.byKey = make(map[int64]string, len(m.byName))
+	for name, key := range m.byName {
+		if other, ok := m.byKey[key]; ok {
+			return nil, fmt.Errorf(`key %d is used for both %q and %q`, key, name, other)
+		}
+		m.byKey[key] = name
+	}
+	return m, nil
+}
+
+// FromToken returns the claims of the token `t` as CWT claims. The
+// registered claims are converted as follows:
+//
+//   - `iss` and `sub` are text strings
+//   - `aud` is a text string. As RFC 8392 only allows a single audience,
+//     tokens with multiple audiences are converted to an array of text
+//     strings, which may not be accepted by all CWT implementations
+//   - `exp`, `nbf`, and `iat` are numbers of seconds since the epoch
+//     (int64, or float64 if they have fractional seconds)
+//   - `jti` is converted to `cti`, a byte string
+//
+// Other claims are stored under their names, and their values are left as
+// is. Use `cwt.WithClaimKey()` to map them to integer keys.
+func FromToken(t jwt.Token, options ...Option) (map[interface{}]interface{}, error) {
+	mapping, err := newClaimMapping(options)
+	if err != nil {
+		return nil, fmt.Errorf(`cwt.FromToken: %w`, err)
+	}
+
+	claims := make(map[interface{}]interface{})
+	ctx := context.Background()
+	for iter := t.Iterate(ctx); iter.Next(ctx); {
+		pair := iter.Pair()
+		//nolint:forcetypeassert
+		name := pair.Key.(string)
+
+		var key interface{} = name
+		if k, ok := mapping.byName[name]; ok {
+			key = k
+		}
+
+		value := pair.Value
+		switch name {
+		case jwt.AudienceKey:
+			if aud, ok := value.([]string); ok && len(aud) == 1 {
+				value = aud[0]
+			}
+		case jwt.ExpirationKey, jwt.NotBeforeKey, jwt.IssuedAtKey:
+			if tv, ok := value.(time.Time); ok {
+				value = fromTime(tv)
+			}
+		case jwt.JwtIDKey:
+			if s, ok := value.(string); ok {
+				value = []byte(s)
+			}
+		}
+		claims[key] = value
+	}
+	return claims, nil
+}
+
+// ToToken creates a token from the CWT claims in `claims`, as decoded by
+// a CBOR library. It reverses the conversion performed by `cwt.FromToken()`.
+//
+// Integer keys may be of any integer type. Claims with integer keys that
+// are not known (see `cwt.WithClaimKey()`) result in an error, as do
+// keys that are neither integers nor strings. The values of `exp`, `nbf`,
+// and `iat` may be numbers or `time.Time` values, and `cti` must be a byte
+// string that is valid UTF-8.
+func ToToken(claims map[interface{}]interface{}, options ...Option) (jwt.Token, error) {
+	mapping, err := newClaimMapping(options)
+	if err != nil {
+		return nil, fmt.Errorf(`cwt.ToToken: %w`, err)
+	}
+
+	tok := jwt.New()
+	for k, v := range claims {
+		var name string
+		switch k := k.(type) {
+		case string:
+			name = k
+		default:
+			key, ok := toInt64(k)
+			if !ok {
+				return nil, fmt.Errorf(`cwt.ToToken: invalid claim key %v (%T)`, k, k)
+			}
+			name, ok = mapping.byKey[key]
+			if !ok {
+				return nil, fmt.Errorf(`cwt.ToToken: unknown claim key %d`, key)
+			}
+		}
+
+		value, err := toJWTValue(name, v)
+		if err != nil {
+			return nil, fmt.Errorf(`cwt.ToToken: %w`, err)
+		}
+		if err := tok.Set(name, value); err != nil {
+			return nil, fmt.Errorf(`cwt.ToToken: failed to set %q claim: %w`, name, err)
+		}
+	}
+	return tok, nil
+}
+
+func toJWTValue(name string, v interface{}) (interface{}, error) {
+	switch name {
+	case jwt.IssuerKey, jwt.SubjectKey:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf(`%q claim must be a text string (got %T)`, name, v)
+		}
+		return s, nil
+	case jwt.AudienceKey:
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case []string:
+			return v, nil
+		case []interface{}:
+			aud := make([]string, len(v))
+			for i, e := range v {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf(`%q claim must contain text strings (got %T)`, name, e)
+				}
+				aud[i] = s
+			}
+			return aud, nil
+		default:
+			return nil, fmt.Errorf(`%q claim must be a text string (got %T)`, name, v)
+		}
+	case jwt.ExpirationKey, jwt.NotBeforeKey, jwt.IssuedAtKey:
+		t, err := toTime(v)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid %q claim: %w`, name, err)
+		}
+		return t, nil
+	case jwt.JwtIDKey:
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf(`"cti" claim must be a byte string (got %T)`, v)
+		}
+		if !utf8.Valid(b) {
+			return nil, fmt.Errorf(`"cti" claim must be valid UTF-8 to be converted to "jti"`)
+		}
+		return string(b), nil
+	default:
+		return v, nil
+	}
+}
+
+func fromTime(t time.Time) interface{} {
+	if t.Nanosecond() == 0 {
+		return t.Unix()
+	}
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case float32:
+		return floatToTime(float64(v))
+	case float64:
+		return floatToTime(v)
+	}
+	if n, ok := toInt64(v); ok {
+		return time.Unix(n, 0), nil
+	}
+	return time.Time{}, fmt.Errorf(`expected a number (got %T)`, v)
+}
+
+func floatToTime(f float64) (time.Time, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf(`invalid number %v`, f)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return uintToInt64(uint64(v))
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return uintToInt64(v)
+	default:
+		return 0, false
+	}
+}
+
+func uintToInt64(v uint64) (int64, bool) {
+	if v > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(v), true
+}
@@ -0,0 +1,136 @@
+package cwt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/cwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromToken(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1443944944, 0)
+	tok, err := jwt.NewBuilder().
+		Issuer(`coap://as.example.com`).
+		Subject(`erikw`).
+		Audience([]string{`coap://light.example.com`}).
+		Expiration(now.Add(time.Hour)).
+		NotBefore(now).
+		IssuedAt(now).
+		JwtID(`0b71`).
+		Claim(`scope`, `read`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	t.Run(`registered claims`, func(t *testing.T) {
+		t.Parallel()
+		claims, err := cwt.FromToken(tok)
+		require.NoError(t, err, `cwt.FromToken should succeed`)
+		require.Equal(t, map[interface{}]interface{}{
+			cwt.IssuerKey:     `coap://as.example.com`,
+			cwt.SubjectKey:    `erikw`,
+			cwt.AudienceKey:   `coap://light.example.com`,
+			cwt.ExpirationKey: now.Add(time.Hour).Unix(),
+			cwt.NotBeforeKey:  now.Unix(),
+			cwt.IssuedAtKey:   now.Unix(),
+			cwt.CWTIDKey:      []byte(`0b71`),
+			`scope`:           `read`,
+		}, claims)
+
+		converted, err := cwt.ToToken(claims)
+		require.NoError(t, err, `cwt.ToToken should succeed`)
+		require.True(t, jwt.Equal(tok, converted), `tokens should be equal`)
+	})
+	t.Run(`custom claim keys`, func(t *testing.T) {
+		t.Parallel()
+		claims, err := cwt.FromToken(tok, cwt.WithClaimKey(`scope`, 9))
+		require.NoError(t, err, `cwt.FromToken should succeed`)
+		require.Equal(t, `read`, claims[int64(9)])
+		require.NotContains(t, claims, `scope`)
+
+		_, err = cwt.ToToken(claims)
+		require.Error(t, err, `cwt.ToToken should fail for unknown keys`)
+
+		converted, err := cwt.ToToken(claims, cwt.WithClaimKey(`scope`, 9))
+		require.NoError(t, err, `cwt.ToToken should succeed`)
+		require.True(t, jwt.Equal(tok, converted), `tokens should be equal`)
+	})
+	t.Run(`conflicting claim keys`, func(t *testing.T) {
+		t.Parallel()
+		_, err := cwt.FromToken(tok, cwt.WithClaimKey(`scope`, cwt.IssuerKey))
+		require.Error(t, err, `cwt.FromToken should fail`)
+		_, err = cwt.FromToken(tok, cwt.WithClaimKey(jwt.IssuerKey, 9))
+		require.Error(t, err, `cwt.FromToken should fail`)
+	})
+	t.Run(`multiple audiences`, func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().Audience([]string{`a`, `b`}).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		claims, err := cwt.FromToken(tok)
+		require.NoError(t, err, `cwt.FromToken should succeed`)
+		require.Equal(t, []string{`a`, `b`}, claims[cwt.AudienceKey])
+	})
+	t.Run(`fractional seconds`, func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().IssuedAt(now.Add(500 * time.Millisecond)).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		claims, err := cwt.FromToken(tok)
+		require.NoError(t, err, `cwt.FromToken should succeed`)
+		require.Equal(t, float64(now.Unix())+0.5, claims[cwt.IssuedAtKey])
+	})
+}
+
+func TestToToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run(`decoded values`, func(t *testing.T) {
+		t.Parallel()
+		// Values as typically produced by CBOR decoders
+		tok, err := cwt.ToToken(map[interface{}]interface{}{
+			uint64(1): `coap://as.example.com`,
+			uint64(3): []interface{}{`a`, `b`},
+			uint64(4): uint64(1444064944),
+			int64(5):  1443944944.5,
+			uint64(6): time.Unix(1443944944, 0),
+			uint64(7): []byte(`0b71`),
+			`private`: []interface{}{uint64(1), `two`},
+		})
+		require.NoError(t, err, `cwt.ToToken should succeed`)
+		require.Equal(t, `coap://as.example.com`, tok.Issuer())
+		require.Equal(t, []string{`a`, `b`}, tok.Audience())
+		require.Equal(t, int64(1444064944), tok.Expiration().Unix())
+		require.True(t, time.Unix(1443944944, 5e8).Equal(tok.NotBefore()), `nbf should keep fractional seconds`)
+		require.Equal(t, int64(1443944944), tok.IssuedAt().Unix())
+		require.Equal(t, `0b71`, tok.JwtID())
+		v, ok := tok.Get(`private`)
+		require.True(t, ok, `private claim should be present`)
+		require.Equal(t, []interface{}{uint64(1), `two`}, v)
+	})
+	t.Run(`invalid claims`, func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Name   string
+			Claims map[interface{}]interface{}
+		}{
+			{Name: `unknown key`, Claims: map[interface{}]interface{}{uint64(100): `x`}},
+			{Name: `invalid key type`, Claims: map[interface{}]interface{}{1.5: `x`}},
+			{Name: `key out of range`, Claims: map[interface{}]interface{}{uint64(1 << 63): `x`}},
+			{Name: `non-string iss`, Claims: map[interface{}]interface{}{cwt.IssuerKey: 1}},
+			{Name: `non-string aud`, Claims: map[interface{}]interface{}{cwt.AudienceKey: []interface{}{1}}},
+			{Name: `non-numeric exp`, Claims: map[interface{}]interface{}{cwt.ExpirationKey: `tomorrow`}},
+			{Name: `string cti`, Claims: map[interface{}]interface{}{cwt.CWTIDKey: `0b71`}},
+			{Name: `invalid UTF-8 cti`, Claims: map[interface{}]interface{}{cwt.CWTIDKey: []byte{0xff}}},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Name, func(t *testing.T) {
+				t.Parallel()
+				_, err := cwt.ToToken(tc.Claims)
+				require.Error(t, err, `cwt.ToToken should fail`)
+			})
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package cwt
+
+import (
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `cwt.FromToken()` and
+// `cwt.ToToken()`.
+type Option = option.Interface
+
+type identClaimKey struct{}
+
+type claimKey struct {
+	name string
+	key  int64
+}
+
+// WithClaimKey maps the claim `name` to the integer `key`, such as the
+// keys registered in the IANA "CBOR Web Token (CWT) Claims" registry
+// (e.g. 9 for `scope`). This option may be specified multiple times.
+//
+// The claims registered in RFC 8392 are always mapped, and may not be
+// mapped to different keys.
+func WithClaimKey(name string, key int64) Option {
+	return option.New(identClaimKey{}, claimKey{name: name, key: key})
+}
@@ -0,0 +1,338 @@
+// Package dpop implements OAuth 2.0 Demonstrating Proof of Possession
+// (DPoP, RFC 9449).
+//
+// Clients use `dpop.NewProof()` to create the proof JWT that is sent in
+// the "DPoP" HTTP header, and servers use `dpop.Verify()` to validate it.
+// Authorization servers bind access tokens to the client's key by setting
+// the `cnf` claim to the value returned by `dpop.Confirmation()`, which
+// resource servers check using `dpop.WithBoundToken()`.
+package dpop
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/cnf"
+)
+
+// Type is the value of the `typ` header of DPoP proofs.
+const Type = `dpop+jwt`
+
+// HeaderName is the name of the HTTP header that carries the proof.
+const HeaderName = `DPoP`
+
+// Names of the claims used in DPoP proofs and DPoP-bound access tokens
+const (
+	HTTPMethodKey      = `htm`
+	HTTPURIKey         = `htu`
+	AccessTokenHashKey = `ath`
+	NonceKey           = `nonce`
+	ConfirmationKey    = `cnf`
+	JWKThumbprintKey   = `jkt`
+)
+
+const defaultMaxAge = 5 * time.Minute
+
+// Proof represents a verified DPoP proof.
+type Proof struct {
+	// Token contains the claims of the proof.
+	Token jwt.Token
+	// Key is the public key that the proof was signed with, taken from
+	// the `jwk` header.
+	Key jwk.Key
+	// Thumbprint is the JWK SHA-256 thumbprint of Key, encoded in base64url.
+	Thumbprint string
+}
+
+// Thumbprint returns the base64url encoded JWK SHA-256 thumbprint
+// (RFC 7638) of `key`, which is used as the value of the `jkt` member
+// of the `cnf` claim. It is the same as `cnf.KeyThumbprint()`.
+func Thumbprint(key jwk.Key) (string, error) {
+	return cnf.KeyThumbprint(key)
+}
+
+// Confirmation returns the value of the `cnf` claim that binds an access
+// token to `key` (RFC 9449 Section 6.1).
+//
+//	cnf, err := dpop.Confirmation(proof.Key)
+//	tok.Set(dpop.ConfirmationKey, cnf)
+//
+// It is the same as `cnf.ForKey()`.
+func Confirmation(key jwk.Key) (map[string]interface{}, error) {
+	return cnf.ForKey(key)
+}
+
+// AccessTokenHash returns the value of the `ath` claim for `accessToken`.
+func AccessTokenHash(accessToken string) string {
+	h := sha256.Sum256([]byte(accessToken))
+	return base64.EncodeToString(h[:])
+}
+
+// NewProof creates a DPoP proof for a request using the HTTP method
+// `method` to `uri`, signed using `alg` and the private key `key`.
+// `key` may be a raw key (e.g. *ecdsa.PrivateKey) or a jwk.Key.
+//
+// The public key is embedded in the `jwk` header, and a random `jti`
+// is generated for each proof. The query and fragment of `uri` are
+// not included in the `htu` claim.
+func NewProof(alg jwa.SignatureAlgorithm, key interface{}, method, uri string, options ...ProofOption) ([]byte, error) {
+	if !isAsymmetric(alg) {
+		return nil, fmt.Errorf(`dpop.NewProof: algorithm %q cannot be used for DPoP proofs`, alg)
+	}
+
+	var clock jwt.Clock = jwt.ClockFunc(time.Now)
+	var accessToken, nonce string
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identAccessToken{}:
+			accessToken = option.Value().(string)
+		case identClock{}:
+			if c := option.Value().(jwt.Clock); c != nil {
+				clock = c
+			}
+		case identNonce{}:
+			nonce = option.Value().(string)
+		}
+	}
+
+	pubkey, err := jwk.PublicKeyOf(key)
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: failed to obtain public key: %w`, err)
+	}
+
+	htu, err := normalizeURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: %w`, err)
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: failed to generate jti: %w`, err)
+	}
+
+	b := jwt.NewBuilder().
+		JwtID(base64.EncodeToString(jti)).
+		IssuedAt(clock.Now()).
+		Claim(HTTPMethodKey, method).
+		Claim(HTTPURIKey, htu)
+	if accessToken != `` {
+		b.Claim(AccessTokenHashKey, AccessTokenHash(accessToken))
+	}
+	if nonce != `` {
+		b.Claim(NonceKey, nonce)
+	}
+	tok, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: failed to build token: %w`, err)
+	}
+
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, Type); err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: failed to set %q header: %w`, jws.TypeKey, err)
+	}
+	if err := hdrs.Set(jws.JWKKey, pubkey); err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: failed to set %q header: %w`, jws.JWKKey, err)
+	}
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(alg, key), jwt.WithJwsHeaders(hdrs))
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.NewProof: %w`, err)
+	}
+	return signed, nil
+}
+
+// Verify verifies and validates the DPoP proof `src` for a request using
+// the HTTP method `method` to `uri`, as described in RFC 9449 Section 4.3.
+//
+// Verify checks that the proof is signed by the public key in its `jwk`
+// header using an asymmetric algorithm, that its `typ` header is "dpop+jwt",
+// that it contains the `jti`, `htm`, `htu`, and `iat` claims, that `htm`
+// and `htu` match the request, and that it was created recently. The
+// access token and the nonce are checked if they are specified via
+// `dpop.WithAccessToken()` and `dpop.WithNonce()`.
+func Verify(src []byte, method, uri string, options ...VerifyOption) (*Proof, error) {
+	var clock jwt.Clock
+	var boundToken jwt.Token
+	var replayStore jwt.ReplayStore
+	var accessToken, nonce string
+	maxAge := defaultMaxAge
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identAccessToken{}:
+			accessToken = option.Value().(string)
+		case identBoundToken{}:
+			boundToken = option.Value().(jwt.Token)
+		case identClock{}:
+			clock = option.Value().(jwt.Clock)
+		case identMaxAge{}:
+			maxAge = option.Value().(time.Duration)
+		case identNonce{}:
+			nonce = option.Value().(string)
+		case identReplayStore{}:
+			replayStore = option.Value().(jwt.ReplayStore)
+		}
+	}
+
+	msg, err := jws.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.Verify: failed to parse proof: %w`, err)
+	}
+	sigs := msg.Signatures()
+	if len(sigs) != 1 {
+		return nil, fmt.Errorf(`dpop.Verify: proof must have exactly one signature`)
+	}
+	hdrs := sigs[0].ProtectedHeaders()
+
+	if !strings.EqualFold(hdrs.Type(), Type) {
+		return nil, fmt.Errorf(`dpop.Verify: "typ" header must be %q`, Type)
+	}
+
+	alg := hdrs.Algorithm()
+	if !isAsymmetric(alg) {
+		return nil, fmt.Errorf(`dpop.Verify: algorithm %q cannot be used for DPoP proofs`, alg)
+	}
+
+	key := hdrs.JWK()
+	if key == nil {
+		return nil, fmt.Errorf(`dpop.Verify: "jwk" header is missing`)
+	}
+	if !isPublicKey(key) {
+		return nil, fmt.Errorf(`dpop.Verify: "jwk" header must contain a public key`)
+	}
+
+	htu, err := normalizeURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.Verify: %w`, err)
+	}
+
+	parseOptions := []jwt.ParseOption{
+		jwt.WithKey(alg, key),
+		jwt.WithRequiredClaims(jwt.JwtIDKey, HTTPMethodKey, HTTPURIKey, jwt.IssuedAtKey),
+		jwt.WithClaimValue(HTTPMethodKey, method),
+		jwt.WithValidator(htuValidator(htu)),
+		jwt.WithMaxAge(maxAge),
+	}
+	if clock != nil {
+		parseOptions = append(parseOptions, jwt.WithClock(clock))
+	}
+	if accessToken != `` {
+		parseOptions = append(parseOptions, jwt.WithClaimValue(AccessTokenHashKey, AccessTokenHash(accessToken)))
+	}
+	if nonce != `` {
+		parseOptions = append(parseOptions, jwt.WithClaimValue(NonceKey, nonce))
+	}
+	if replayStore != nil {
+		parseOptions = append(parseOptions, jwt.WithReplayDetection(replayStore))
+	}
+
+	tok, err := jwt.Parse(src, parseOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.Verify: %w`, err)
+	}
+
+	tp, err := Thumbprint(key)
+	if err != nil {
+		return nil, fmt.Errorf(`dpop.Verify: %w`, err)
+	}
+
+	if boundToken != nil {
+		validateOptions := []jwt.ValidateOption{cnf.WithKey(key)}
+		if clock != nil {
+			validateOptions = append(validateOptions, jwt.WithClock(clock))
+		}
+		if err := jwt.Validate(boundToken, validateOptions...); err != nil {
+			return nil, fmt.Errorf(`dpop.Verify: access token is not bound to the key used to sign the proof: %w`, err)
+		}
+	}
+
+	return &Proof{
+		Token:      tok,
+		Key:        key,
+		Thumbprint: tp,
+	}, nil
+}
+
+func htuValidator(htu string) jwt.Validator {
+	return jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
+		v, ok := t.Get(HTTPURIKey)
+		if !ok {
+			return jwt.NewValidationError(fmt.Errorf(`%q claim is missing`, HTTPURIKey))
+		}
+		s, ok := v.(string)
+		if !ok {
+			return jwt.NewValidationError(fmt.Errorf(`%q claim must be a string`, HTTPURIKey))
+		}
+		normalized, err := normalizeURI(s)
+		if err != nil || normalized != htu {
+			return jwt.NewValidationError(fmt.Errorf(`%q claim does not match the request URI`, HTTPURIKey))
+		}
+		return nil
+	})
+}
+
+// normalizeURI removes the query and fragment from `uri`, and applies
+// the syntax and scheme based normalizations described in RFC 3986
+// Section 6.2.2 and 6.2.3 (case of the scheme and host, default ports,
+// and empty paths), so that URIs can be compared for equality.
+func normalizeURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ``, fmt.Errorf(`failed to parse URI %q: %w`, uri, err)
+	}
+	if !u.IsAbs() || u.Host == `` {
+		return ``, fmt.Errorf(`URI %q must be absolute`, uri)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == `https` && port == `443`) || (u.Scheme == `http` && port == `80`) {
+		port = ``
+	}
+	if strings.Contains(host, `:`) {
+		host = `[` + host + `]`
+	}
+	if port != `` {
+		host += `:` + port
+	}
+	u.Host = host
+	if u.Path == `` {
+		u.Path = `/`
+	}
+	u.RawQuery = ``
+	u.ForceQuery = false
+	u.Fragment = ``
+	u.RawFragment = ``
+	u.User = nil
+	return u.String(), nil
+}
+
+func isAsymmetric(alg jwa.SignatureAlgorithm) bool {
+	switch alg {
+	case jwa.NoSignature, jwa.HS256, jwa.HS384, jwa.HS512, ``:
+		return false
+	default:
+		return true
+	}
+}
+
+func isPublicKey(key jwk.Key) bool {
+	switch key.(type) {
+	case jwk.RSAPublicKey, jwk.ECDSAPublicKey, jwk.OKPPublicKey:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,145 @@
+package dpop_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/cnf"
+	"github.com/lestrrat-go/jwx/v2/jwt/dpop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDPoP(t *testing.T) {
+	const method = `POST`
+	const uri = `https://server.example.com/token`
+
+	key, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+
+	t.Run("roundtrip", func(t *testing.T) {
+		src, err := dpop.NewProof(jwa.ES256, key, method, uri+`?foo=bar#baz`)
+		require.NoError(t, err, `dpop.NewProof should succeed`)
+
+		msg, err := jws.Parse(src)
+		require.NoError(t, err, `jws.Parse should succeed`)
+		hdrs := msg.Signatures()[0].ProtectedHeaders()
+		require.Equal(t, dpop.Type, hdrs.Type())
+		require.NotNil(t, hdrs.JWK(), `jwk header should be set`)
+		_, ok := hdrs.JWK().(jwk.ECDSAPublicKey)
+		require.True(t, ok, `jwk header should contain the public key`)
+
+		proof, err := dpop.Verify(src, method, `HTTPS://Server.Example.COM:443/token`)
+		require.NoError(t, err, `dpop.Verify should succeed`)
+		require.Equal(t, uri, proof.Token.PrivateClaims()[dpop.HTTPURIKey])
+		require.NotEmpty(t, proof.Token.JwtID(), `jti should be set`)
+
+		pubkey, err := jwk.PublicKeyOf(key)
+		require.NoError(t, err, `jwk.PublicKeyOf should succeed`)
+		tp, err := dpop.Thumbprint(pubkey)
+		require.NoError(t, err, `dpop.Thumbprint should succeed`)
+		require.Equal(t, tp, proof.Thumbprint)
+
+		_, err = dpop.Verify(src, `GET`, uri)
+		require.Error(t, err, `dpop.Verify should fail for a different method`)
+		_, err = dpop.Verify(src, method, `https://server.example.com/resource`)
+		require.Error(t, err, `dpop.Verify should fail for a different URI`)
+	})
+	t.Run("access token and nonce", func(t *testing.T) {
+		const accessToken = `Kz~8mXK1EalYznwH-LC-1fBAo.4Ljp~zsPE_NeO.gxU`
+		src, err := dpop.NewProof(jwa.ES256, key, method, uri, dpop.WithAccessToken(accessToken), dpop.WithNonce(`n0nce`))
+		require.NoError(t, err, `dpop.NewProof should succeed`)
+
+		// Example from RFC 9449 Section 7.1
+		require.Equal(t, `fUHyO2r2Z3DZ53EsNrWBb0xWXoaNy59IiKCAqksmQEo`, dpop.AccessTokenHash(accessToken))
+
+		_, err = dpop.Verify(src, method, uri, dpop.WithAccessToken(accessToken), dpop.WithNonce(`n0nce`))
+		require.NoError(t, err, `dpop.Verify should succeed`)
+
+		_, err = dpop.Verify(src, method, uri, dpop.WithAccessToken(`other`))
+		require.Error(t, err, `dpop.Verify should fail for a different access token`)
+		_, err = dpop.Verify(src, method, uri, dpop.WithNonce(`other`))
+		require.Error(t, err, `dpop.Verify should fail for a different nonce`)
+	})
+	t.Run("bound token", func(t *testing.T) {
+		src, err := dpop.NewProof(jwa.ES256, key, method, uri)
+		require.NoError(t, err, `dpop.NewProof should succeed`)
+
+		proof, err := dpop.Verify(src, method, uri)
+		require.NoError(t, err, `dpop.Verify should succeed`)
+
+		conf, err := dpop.Confirmation(proof.Key)
+		require.NoError(t, err, `dpop.Confirmation should succeed`)
+		bound := jwt.New()
+		require.NoError(t, bound.Set(dpop.ConfirmationKey, conf), `bound.Set should succeed`)
+
+		_, err = dpop.Verify(src, method, uri, dpop.WithBoundToken(bound))
+		require.NoError(t, err, `dpop.Verify should succeed`)
+
+		other, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+		require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+		src, err = dpop.NewProof(jwa.ES256, other, method, uri)
+		require.NoError(t, err, `dpop.NewProof should succeed`)
+		_, err = dpop.Verify(src, method, uri, dpop.WithBoundToken(bound))
+		require.True(t, errors.Is(err, cnf.ErrConfirmationMismatch()), `dpop.Verify should fail for proofs signed by a different key`)
+
+		_, err = dpop.Verify(src, method, uri, dpop.WithBoundToken(jwt.New()))
+		require.Error(t, err, `dpop.Verify should fail for tokens without cnf`)
+	})
+	t.Run("age", func(t *testing.T) {
+		created := time.Now().Add(-10 * time.Minute)
+		src, err := dpop.NewProof(jwa.ES256, key, method, uri, dpop.WithClock(jwt.ClockFunc(func() time.Time { return created })))
+		require.NoError(t, err, `dpop.NewProof should succeed`)
+
+		_, err = dpop.Verify(src, method, uri)
+		require.Error(t, err, `dpop.Verify should fail for old proofs`)
+
+		_, err = dpop.Verify(src, method, uri, dpop.WithMaxAge(time.Hour))
+		require.NoError(t, err, `dpop.Verify should succeed with a longer max age`)
+	})
+	t.Run("replay", func(t *testing.T) {
+		src, err := dpop.NewProof(jwa.ES256, key, method, uri)
+		require.NoError(t, err, `dpop.NewProof should succeed`)
+
+		store := jwt.NewMemoryReplayStore(10 * time.Minute)
+		_, err = dpop.Verify(src, method, uri, dpop.WithReplayStore(store))
+		require.NoError(t, err, `first dpop.Verify should succeed`)
+		_, err = dpop.Verify(src, method, uri, dpop.WithReplayStore(store))
+		require.True(t, errors.Is(err, jwt.ErrTokenReplayed()), `second dpop.Verify should fail with jwt.ErrTokenReplayed`)
+	})
+	t.Run("invalid proofs", func(t *testing.T) {
+		_, err := dpop.NewProof(jwa.HS256, []byte(`secret`), method, uri)
+		require.Error(t, err, `dpop.NewProof should fail for symmetric algorithms`)
+
+		// Regular JWT, without typ and jwk
+		tok, err := jwt.NewBuilder().
+			JwtID(`abc`).
+			IssuedAt(time.Now()).
+			Claim(dpop.HTTPMethodKey, method).
+			Claim(dpop.HTTPURIKey, uri).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.ES256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = dpop.Verify(signed, method, uri)
+		require.Error(t, err, `dpop.Verify should fail without typ`)
+
+		// Private key in the jwk header
+		privkey, err := jwk.FromRaw(key)
+		require.NoError(t, err, `jwk.FromRaw should succeed`)
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, dpop.Type), `hdrs.Set should succeed`)
+		require.NoError(t, hdrs.Set(jws.JWKKey, privkey), `hdrs.Set should succeed`)
+		signed, err = jwt.Sign(tok, jwt.WithKey(jwa.ES256, key), jwt.WithJwsHeaders(hdrs))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = dpop.Verify(signed, method, uri)
+		require.Error(t, err, `dpop.Verify should fail for private keys`)
+		require.True(t, strings.Contains(err.Error(), `public key`), `error should mention public key`)
+	})
+}
@@ -0,0 +1,107 @@
+package dpop
+
+import (
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/option"
+)
+
+type Option = option.Interface
+
+// ProofOption describes an Option that can be passed to `dpop.NewProof()`.
+type ProofOption interface {
+	Option
+	proofOption()
+}
+
+// VerifyOption describes an Option that can be passed to `dpop.Verify()`.
+type VerifyOption interface {
+	Option
+	verifyOption()
+}
+
+// ProofVerifyOption describes an Option that can be passed to either
+// `dpop.NewProof()` or `dpop.Verify()`.
+type ProofVerifyOption interface {
+	ProofOption
+	VerifyOption
+}
+
+type proofOption struct {
+	Option
+}
+
+func (*proofOption) proofOption() {}
+
+type verifyOption struct {
+	Option
+}
+
+func (*verifyOption) verifyOption() {}
+
+type proofVerifyOption struct {
+	Option
+}
+
+func (*proofVerifyOption) proofOption()  {}
+func (*proofVerifyOption) verifyOption() {}
+
+type identAccessToken struct{}
+type identBoundToken struct{}
+type identClock struct{}
+type identMaxAge struct{}
+type identNonce struct{}
+type identReplayStore struct{}
+
+// WithAccessToken specifies the access token that is presented along
+// with the proof.
+//
+// When passed to `dpop.NewProof()`, the hash of the access token is
+// included in the proof as the `ath` claim. When passed to `dpop.Verify()`,
+// the `ath` claim must be present and match the hash of the access token.
+func WithAccessToken(v string) ProofVerifyOption {
+	return &proofVerifyOption{option.New(identAccessToken{}, v)}
+}
+
+// WithBoundToken specifies the parsed access token that the proof is
+// presented with. `dpop.Verify()` checks that the key used to sign the
+// proof matches the JWK thumbprint in the `cnf` claim (`jkt` member)
+// of the access token.
+//
+// This option is usually used together with `dpop.WithAccessToken()`.
+func WithBoundToken(v jwt.Token) VerifyOption {
+	return &verifyOption{option.New(identBoundToken{}, v)}
+}
+
+// WithClock specifies the clock used to set the `iat` claim in
+// `dpop.NewProof()`, and to validate it in `dpop.Verify()`.
+func WithClock(v jwt.Clock) ProofVerifyOption {
+	return &proofVerifyOption{option.New(identClock{}, v)}
+}
+
+// WithMaxAge specifies how long proofs are accepted after they have
+// been created, based on the `iat` claim. The default is 5 minutes.
+func WithMaxAge(v time.Duration) VerifyOption {
+	return &verifyOption{option.New(identMaxAge{}, v)}
+}
+
+// WithNonce specifies the nonce provided by the server.
+//
+// When passed to `dpop.NewProof()`, it is included in the proof as the
+// `nonce` claim. When passed to `dpop.Verify()`, the `nonce` claim must
+// be present and match the given value.
+func WithNonce(v string) ProofVerifyOption {
+	return &proofVerifyOption{option.New(identNonce{}, v)}
+}
+
+// WithReplayStore specifies the `jwt.ReplayStore` used to reject proofs
+// whose `jti` has already been used. As proofs do not have an `exp`
+// claim, implementations must remember the `jti` for at least the
+// duration specified via `dpop.WithMaxAge()`.
+//
+//	store := jwt.NewMemoryReplayStore(10 * time.Minute)
+//	proof, err := dpop.Verify(src, method, uri, dpop.WithReplayStore(store))
+func WithReplayStore(v jwt.ReplayStore) VerifyOption {
+	return &verifyOption{option.New(identReplayStore{}, v)}
+}
@@ -0,0 +1,117 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/types"
+)
+
+// Equal compares two JWT tokens. Do not use `reflect.DeepEqual` or the like
+// to compare tokens as they will also compare extra detail such as
+// sync.Mutex objects used to control concurrent access, and their results
+// depend on the internal representation of the claims.
+//
+// Equal returns true if the tokens `a` and `b` contain the same claims,
+// with the same values. Values are compared as follows:
+//
+//   - Time values, such as those of `exp`, `iat`, and `nbf`, are equal if
+//     they represent the same instant, after they have been truncated to the
+//     precision used for serialization (see `jwt.WithNumericDateFormatPrecision()`).
+//     The time zone and monotonic clock reading are ignored.
+//   - The values of `aud` are equal if they contain the same audiences,
+//     regardless of their order.
+//   - Other values are equal if their JSON representations are equal.
+//     For example, an `int` value of 1 that was set using `Set()` is equal
+//     to the `float64` value of 1 that was obtained by parsing a token.
+//
+// The per-token options (see `(jwt.Token).Options()`) and the concrete
+// type of the tokens are not taken into consideration.
+//
+// If both `a` and `b` are nil, returns true
+func Equal(a, b Token) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	ctx := context.Background()
+	ma, err := a.AsMap(ctx)
+	if err != nil {
+		return false
+	}
+	mb, err := b.AsMap(ctx)
+	if err != nil {
+		return false
+	}
+
+	if len(ma) != len(mb) {
+		return false
+	}
+	for name, va := range ma {
+		vb, ok := mb[name]
+		if !ok || !claimEqual(name, va, vb) {
+			return false
+		}
+	}
+	return true
+}
+
+func claimEqual(name string, a, b interface{}) bool {
+	if name == AudienceKey {
+		if aa, ok := a.([]string); ok {
+			if ab, ok := b.([]string); ok {
+				return audienceEqual(aa, ab)
+			}
+		}
+	}
+
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return normalizeTime(ta).Equal(normalizeTime(tb))
+		}
+	}
+
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
+func audienceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sa := make([]string, len(a))
+	copy(sa, a)
+	sort.Strings(sa)
+	sb := make([]string, len(b))
+	copy(sb, b)
+	sort.Strings(sb)
+
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeTime truncates the time to the precision that is used
+// when formatting numeric dates
+func normalizeTime(t time.Time) time.Time {
+	unit := time.Second
+	for i := uint32(0); i < atomic.LoadUint32(&types.FormatPrecision); i++ {
+		unit /= 10
+	}
+	return t.Truncate(unit)
+}
@@ -0,0 +1,88 @@
+package jwt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	now := time.Now()
+
+	build := func(t *testing.T, aud []string, exp time.Time, level interface{}) jwt.Token {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Subject(`alice`).
+			Audience(aud).
+			Expiration(exp).
+			Claim(`level`, level).
+			Claim(`profile`, map[string]interface{}{`name`: `Alice`}).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		return tok
+	}
+
+	base := build(t, []string{`a`, `b`}, now, 1)
+
+	t.Run("equal", func(t *testing.T) {
+		require.True(t, jwt.Equal(nil, nil), `nil tokens should be equal`)
+		require.False(t, jwt.Equal(base, nil), `a token should not equal nil`)
+		require.True(t, base.Equal(base), `a token should equal itself`)
+
+		clone, err := base.Clone()
+		require.NoError(t, err, `base.Clone should succeed`)
+		require.True(t, base.Equal(clone), `a token should equal its clone`)
+	})
+	t.Run("audience order", func(t *testing.T) {
+		other := build(t, []string{`b`, `a`}, now, 1)
+		require.True(t, base.Equal(other), `audience order should be ignored`)
+
+		other = build(t, []string{`a`, `c`}, now, 1)
+		require.False(t, base.Equal(other), `different audiences should not be equal`)
+	})
+	t.Run("time normalization", func(t *testing.T) {
+		other := build(t, []string{`a`, `b`}, now.Truncate(time.Second).In(time.FixedZone(`X`, 3600)), 1)
+		require.True(t, base.Equal(other), `times should be compared with serialization precision`)
+
+		other = build(t, []string{`a`, `b`}, now.Add(time.Second), 1)
+		require.False(t, base.Equal(other), `different times should not be equal`)
+	})
+	t.Run("numeric representation", func(t *testing.T) {
+		other := build(t, []string{`a`, `b`}, now, float64(1))
+		require.True(t, base.Equal(other), `numbers should be compared by value`)
+
+		other = build(t, []string{`a`, `b`}, now, 2)
+		require.False(t, base.Equal(other), `different numbers should not be equal`)
+	})
+	t.Run("claim sets", func(t *testing.T) {
+		other := build(t, []string{`a`, `b`}, now, 1)
+		require.NoError(t, other.Set(`extra`, true), `other.Set should succeed`)
+		require.False(t, base.Equal(other), `tokens with extra claims should not be equal`)
+		require.False(t, other.Equal(base), `tokens with missing claims should not be equal`)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		key := []byte(`abracadabra-abracadabra-abracadabra`)
+		tok := build(t, []string{`a`}, now, 1)
+		tok.Options().Enable(jwt.FlattenAudience)
+
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidate(false))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.True(t, tok.Equal(parsed), `parsed token should equal the original`)
+		require.True(t, parsed.Equal(tok), `original token should equal the parsed token`)
+	})
+	t.Run("different token types", func(t *testing.T) {
+		idtoken := openid.New()
+		require.NoError(t, idtoken.Set(jwt.SubjectKey, `alice`), `idtoken.Set should succeed`)
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+
+		require.True(t, idtoken.Equal(tok), `tokens with the same claims should be equal`)
+		require.True(t, tok.Equal(idtoken), `tokens with the same claims should be equal`)
+	})
+}
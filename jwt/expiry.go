@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"math"
+	"time"
+)
+
+// Expired returns true if the token `t` has an `exp` claim, and the
+// current time according to `clock` is not before it. If `clock` is
+// nil, the system clock is used.
+//
+// Unlike `jwt.Validate()`, no clock skew is taken into account. Tokens
+// without an `exp` claim never expire.
+func Expired(t Token, clock Clock) bool {
+	return RemainingValidity(t, clock) <= 0
+}
+
+// RemainingValidity returns the duration until the token `t` expires
+// according to `clock`, or zero if it has already expired. If `clock` is
+// nil, the system clock is used.
+//
+// Tokens without an `exp` claim never expire, for which the maximum
+// `time.Duration` value is returned.
+func RemainingValidity(t Token, clock Clock) time.Duration {
+	exp, ok := timeClaimValue(t.Expiration())
+	if !ok {
+		return time.Duration(math.MaxInt64)
+	}
+	remaining := exp.Sub(now(clock))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// NeedsRefresh returns true if the token `t` has expired, or if less than
+// `threshold` (a fraction between 0 and 1) of its lifetime remains. For
+// example, a threshold of 0.2 reports that a token that is valid for an
+// hour needs to be refreshed 48 minutes after it was issued. If `clock`
+// is nil, the system clock is used.
+//
+// The lifetime is the duration between the `iat` claim (or the `nbf`
+// claim if `iat` is not present) and the `exp` claim. If the token does
+// not have an `exp` claim, it never needs to be refreshed. If it has an
+// `exp` claim but neither `iat` nor `nbf`, it needs to be refreshed
+// only once it has expired.
+func NeedsRefresh(t Token, threshold float64, clock Clock) bool {
+	exp, ok := timeClaimValue(t.Expiration())
+	if !ok {
+		return false
+	}
+	remaining := RemainingValidity(t, clock)
+	if remaining <= 0 {
+		return true
+	}
+
+	start, ok := timeClaimValue(t.IssuedAt())
+	if !ok {
+		if start, ok = timeClaimValue(t.NotBefore()); !ok {
+			return false
+		}
+	}
+	lifetime := exp.Sub(start)
+	return float64(remaining) <= threshold*float64(lifetime)
+}
+
+// timeClaimValue returns the value of a time based claim, treating the
+// zero value and the Unix epoch as absent, in the same manner as the
+// default validators
+func timeClaimValue(tv time.Time) (time.Time, bool) {
+	if tv.IsZero() || tv.Unix() == 0 {
+		return time.Time{}, false
+	}
+	return tv, true
+}
+
+func now(clock Clock) time.Time {
+	if clock == nil {
+		return time.Now()
+	}
+	return clock.Now()
+}
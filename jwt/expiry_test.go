@@ -0,0 +1,77 @@
+package jwt_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiry(t *testing.T) {
+	t.Parallel()
+
+	iat := time.Unix(1700000000, 0)
+	clockAt := func(d time.Duration) jwt.Clock {
+		return jwt.ClockFunc(func() time.Time { return iat.Add(d) })
+	}
+
+	tok, err := jwt.NewBuilder().
+		IssuedAt(iat).
+		Expiration(iat.Add(time.Hour)).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	t.Run(`Expired and RemainingValidity`, func(t *testing.T) {
+		t.Parallel()
+		require.False(t, tok.Expired(clockAt(59*time.Minute)), `token should not be expired`)
+		require.Equal(t, time.Minute, tok.RemainingValidity(clockAt(59*time.Minute)))
+
+		require.True(t, tok.Expired(clockAt(time.Hour)), `token should be expired at exp`)
+		require.Equal(t, time.Duration(0), tok.RemainingValidity(clockAt(2*time.Hour)))
+
+		require.True(t, jwt.Expired(tok, nil), `system clock should be used`)
+	})
+	t.Run(`NeedsRefresh`, func(t *testing.T) {
+		t.Parallel()
+		require.False(t, jwt.NeedsRefresh(tok, 0.2, clockAt(47*time.Minute)), `more than 20% remains`)
+		require.True(t, jwt.NeedsRefresh(tok, 0.2, clockAt(48*time.Minute)), `20% remains`)
+		require.True(t, jwt.NeedsRefresh(tok, 0, clockAt(time.Hour)), `expired tokens need refresh`)
+	})
+	t.Run(`nbf is used without iat`, func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().
+			NotBefore(iat).
+			Expiration(iat.Add(10 * time.Minute)).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		require.False(t, jwt.NeedsRefresh(tok, 0.5, clockAt(4*time.Minute)))
+		require.True(t, jwt.NeedsRefresh(tok, 0.5, clockAt(5*time.Minute)))
+	})
+	t.Run(`no exp`, func(t *testing.T) {
+		t.Parallel()
+		tok := jwt.New()
+		require.False(t, tok.Expired(nil), `tokens without exp never expire`)
+		require.Equal(t, time.Duration(math.MaxInt64), tok.RemainingValidity(nil))
+		require.False(t, jwt.NeedsRefresh(tok, 1, nil), `tokens without exp never need refresh`)
+	})
+	t.Run(`no iat or nbf`, func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().Expiration(iat.Add(time.Hour)).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		require.False(t, jwt.NeedsRefresh(tok, 1, clockAt(59*time.Minute)), `lifetime is unknown`)
+		require.True(t, jwt.NeedsRefresh(tok, 1, clockAt(time.Hour)), `expired tokens need refresh`)
+	})
+	t.Run(`openid and frozen tokens`, func(t *testing.T) {
+		t.Parallel()
+		idt := openid.New()
+		require.NoError(t, idt.Set(jwt.ExpirationKey, iat.Add(time.Hour)), `Set should succeed`)
+		require.Equal(t, 30*time.Minute, idt.RemainingValidity(clockAt(30*time.Minute)))
+
+		frozen, err := jwt.Freeze(tok)
+		require.NoError(t, err, `jwt.Freeze should succeed`)
+		require.True(t, frozen.Expired(clockAt(time.Hour)), `frozen token should be expired`)
+	})
+}
@@ -0,0 +1,203 @@
+// Package federation implements the entity statements and trust chains
+// of OpenID Federation 1.0.
+//
+// Entity statements are signed JWTs that carry the keys (`jwks`) and the
+// metadata of federation entities. Each entity publishes a self-signed
+// entity configuration at `ConfigurationPath`, and its superiors issue
+// subordinate statements about it, which may constrain its metadata using
+// metadata policies. `Resolver` collects these statements into a
+// `TrustChain` that ends at a trust anchor, whose keys are configured
+// out of band, and `(*TrustChain).Metadata()` applies the policies of the
+// chain to the metadata of the entity:
+//
+//	r, err := federation.NewResolver(map[string]jwk.Set{
+//	  `https://trust-anchor.example.com`: anchorKeys,
+//	})
+//	if err != nil {
+//	  ...
+//	}
+//	chain, err := r.Resolve(ctx, `https://rp.example.com`)
+//	if err != nil {
+//	  ...
+//	}
+//	md, err := chain.Metadata(federation.OpenIDRelyingParty)
+package federation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+// ConfigurationPath is the path relative to the entity identifier where
+// the entity configuration is published (OpenID Federation 1.0 Section 9)
+const ConfigurationPath = `/.well-known/openid-federation`
+
+// TypeEntityStatement is the value of the `typ` header of entity statements
+const TypeEntityStatement = `entity-statement+jwt`
+
+// ContentTypeEntityStatement is the media type of entity statements
+const ContentTypeEntityStatement = `application/entity-statement+jwt`
+
+// Entity types, which are the names of the members of the `metadata`
+// and `metadata_policy` claims
+const (
+	FederationEntity   = `federation_entity`
+	OpenIDProvider     = `openid_provider`
+	OpenIDRelyingParty = `openid_relying_party`
+	OAuthAuthServer    = `oauth_authorization_server`
+	OAuthClient        = `oauth_client`
+	OAuthResource      = `oauth_resource`
+)
+
+// FetchEndpointKey is the name of the `federation_entity` metadata
+// parameter that contains the URL of the fetch endpoint
+const FetchEndpointKey = `federation_fetch_endpoint`
+
+// EntityStatement represents an entity statement. It is an entity
+// configuration if `Issuer` and `Subject` are equal, and a subordinate
+// statement otherwise.
+type EntityStatement struct {
+	Issuer     string
+	Subject    string
+	IssuedAt   time.Time
+	Expiration time.Time
+	// JWKS contains the federation entity keys of the subject
+	JWKS jwk.Set
+	// AuthorityHints contains the entity identifiers of the superiors
+	// of the subject. It is only present in entity configurations.
+	AuthorityHints []string
+	// Metadata maps entity types to their metadata parameters
+	Metadata map[string]map[string]interface{}
+	// MetadataPolicy maps entity types to the policies that apply to the
+	// metadata of the subject and its subordinates. It is only present
+	// in subordinate statements.
+	MetadataPolicy map[string]MetadataPolicy
+	// Claims contains all claims of the statement, including the above
+	Claims map[string]interface{}
+
+	raw []byte
+}
+
+// IsConfiguration reports whether `s` is an entity configuration,
+// i.e. a statement that the entity issued about itself.
+func (s *EntityStatement) IsConfiguration() bool {
+	return s.Issuer == s.Subject
+}
+
+// Raw returns the serialized statement.
+func (s *EntityStatement) Raw() []byte {
+	return s.raw
+}
+
+// Parse parses the entity statement `buf` WITHOUT verifying its signature.
+// Use `(*EntityStatement).Verify()` to verify it, or `ParseConfiguration()`
+// to parse and verify an entity configuration.
+func Parse(buf []byte) (*EntityStatement, error) {
+	msg, err := jws.Parse(buf)
+	if err != nil {
+		return nil, fmt.Errorf(`federation.Parse: %w`, err)
+	}
+	sigs := msg.Signatures()
+	if len(sigs) != 1 {
+		return nil, fmt.Errorf(`federation.Parse: entity statements must have exactly one signature`)
+	}
+	if typ := sigs[0].ProtectedHeaders().Type(); typ != TypeEntityStatement {
+		return nil, fmt.Errorf(`federation.Parse: invalid "typ" header %q`, typ)
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(msg.Payload(), &claims); err != nil {
+		return nil, fmt.Errorf(`federation.Parse: failed to parse claims: %w`, err)
+	}
+	var raw struct {
+		Issuer         string                                       `json:"iss"`
+		Subject        string                                       `json:"sub"`
+		IssuedAt       int64                                        `json:"iat"`
+		Expiration     int64                                        `json:"exp"`
+		JWKS           json.RawMessage                              `json:"jwks"`
+		AuthorityHints []string                                     `json:"authority_hints"`
+		Metadata       map[string]map[string]interface{}            `json:"metadata"`
+		MetadataPolicy map[string]map[string]map[string]interface{} `json:"metadata_policy"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &raw); err != nil {
+		return nil, fmt.Errorf(`federation.Parse: failed to parse claims: %w`, err)
+	}
+	if raw.Issuer == `` || raw.Subject == `` {
+		return nil, fmt.Errorf(`federation.Parse: "iss" and "sub" claims are required`)
+	}
+	if raw.IssuedAt == 0 || raw.Expiration == 0 {
+		return nil, fmt.Errorf(`federation.Parse: "iat" and "exp" claims are required`)
+	}
+	if len(raw.JWKS) == 0 {
+		return nil, fmt.Errorf(`federation.Parse: "jwks" claim is required`)
+	}
+	set, err := jwk.Parse(raw.JWKS)
+	if err != nil {
+		return nil, fmt.Errorf(`federation.Parse: failed to parse "jwks" claim: %w`, err)
+	}
+
+	s := &EntityStatement{
+		Issuer:         raw.Issuer,
+		Subject:        raw.Subject,
+		IssuedAt:       time.Unix(raw.IssuedAt, 0),
+		Expiration:     time.Unix(raw.Expiration, 0),
+		JWKS:           set,
+		AuthorityHints: raw.AuthorityHints,
+		Metadata:       raw.Metadata,
+		Claims:         claims,
+		raw:            buf,
+	}
+	if len(raw.MetadataPolicy) > 0 {
+		s.MetadataPolicy = make(map[string]MetadataPolicy, len(raw.MetadataPolicy))
+		for typ, policy := range raw.MetadataPolicy {
+			mp := make(MetadataPolicy, len(policy))
+			for param, operators := range policy {
+				mp[param] = Policy(operators)
+			}
+			s.MetadataPolicy[typ] = mp
+		}
+	}
+	return s, nil
+}
+
+// Verify verifies the signature of `s` using `keys`, and checks that
+// `s` is valid at `now`, tolerating a clock skew of `skew`.
+func (s *EntityStatement) Verify(keys jwk.Set, now time.Time, skew time.Duration) error {
+	if keys == nil || keys.Len() == 0 {
+		return fmt.Errorf(`no keys to verify statement of %q about %q`, s.Issuer, s.Subject)
+	}
+	// federation entity keys are identified by their `kid`, and
+	// usually do not specify `alg`
+	if _, err := jws.Verify(s.raw, jws.WithKeySet(keys, jws.WithInferAlgorithmFromKey(true))); err != nil {
+		return fmt.Errorf(`failed to verify statement of %q about %q: %w`, s.Issuer, s.Subject, err)
+	}
+	if s.IssuedAt.After(now.Add(skew)) {
+		return fmt.Errorf(`statement of %q about %q is issued in the future`, s.Issuer, s.Subject)
+	}
+	if !now.Add(-skew).Before(s.Expiration) {
+		return fmt.Errorf(`statement of %q about %q has expired`, s.Issuer, s.Subject)
+	}
+	return nil
+}
+
+// ParseConfiguration parses the entity configuration `buf`, and verifies
+// that it is self-signed using the keys in its `jwks` claim, and that it is
+// valid at `now`. Note that this only proves the consistency of the entity
+// configuration: it must be part of a `TrustChain` to be trusted.
+func ParseConfiguration(buf []byte, now time.Time) (*EntityStatement, error) {
+	s, err := Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	if !s.IsConfiguration() {
+		return nil, fmt.Errorf(`federation.ParseConfiguration: "iss" (%q) and "sub" (%q) must be equal`, s.Issuer, s.Subject)
+	}
+	if err := s.Verify(s.JWKS, now, 0); err != nil {
+		return nil, fmt.Errorf(`federation.ParseConfiguration: %w`, err)
+	}
+	return s, nil
+}
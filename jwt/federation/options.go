@@ -0,0 +1,41 @@
+package federation
+
+import (
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `federation.NewResolver()`.
+type Option = option.Interface
+
+type identHTTPClient struct{}
+type identClock struct{}
+type identAcceptableSkew struct{}
+type identMaxPathLength struct{}
+
+// WithHTTPClient specifies the HTTP client used to fetch entity
+// statements. By default http.DefaultClient is used.
+func WithHTTPClient(v HTTPClient) Option {
+	return option.New(identHTTPClient{}, v)
+}
+
+// WithClock specifies the clock used to check whether the entity
+// statements have expired.
+func WithClock(v jwt.Clock) Option {
+	return option.New(identClock{}, v)
+}
+
+// WithAcceptableSkew specifies the clock skew that is tolerated when
+// checking the `iat` and `exp` claims of entity statements.
+func WithAcceptableSkew(v time.Duration) Option {
+	return option.New(identAcceptableSkew{}, v)
+}
+
+// WithMaxPathLength specifies the maximum number of intermediate
+// entities between the subject and the trust anchor. The default is
+// `DefaultMaxPathLength`.
+func WithMaxPathLength(v int) Option {
+	return option.New(identMaxPathLength{}, v)
+}
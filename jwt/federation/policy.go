@@ -0,0 +1,296 @@
+package federation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Metadata policy operators (OpenID Federation 1.0 Section 6.1.3)
+const (
+	OperatorValue      = `value`
+	OperatorAdd        = `add`
+	OperatorDefault    = `default`
+	OperatorOneOf      = `one_of`
+	OperatorSubsetOf   = `subset_of`
+	OperatorSupersetOf = `superset_of`
+	OperatorEssential  = `essential`
+)
+
+// operatorOrder is the order in which the operators are applied
+var operatorOrder = []string{
+	OperatorValue,
+	OperatorAdd,
+	OperatorDefault,
+	OperatorOneOf,
+	OperatorSubsetOf,
+	OperatorSupersetOf,
+	OperatorEssential,
+}
+
+// Policy maps the operators that apply to a single metadata parameter
+// to their values.
+type Policy map[string]interface{}
+
+// MetadataPolicy maps metadata parameters of an entity type to the
+// policies that apply to them.
+type MetadataPolicy map[string]Policy
+
+// MergePolicies combines the policy of a superior with that of its
+// subordinate, producing a policy that is at least as restrictive as
+// either (OpenID Federation 1.0 Section 6.1.4.1). An error is returned
+// if the policies conflict, or contain unknown operators.
+func MergePolicies(superior, subordinate MetadataPolicy) (MetadataPolicy, error) {
+	merged := make(MetadataPolicy, len(superior)+len(subordinate))
+	for param, p := range superior {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf(`federation.MergePolicies: parameter %q: %w`, param, err)
+		}
+		merged[param] = p.clone()
+	}
+	for param, p := range subordinate {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf(`federation.MergePolicies: parameter %q: %w`, param, err)
+		}
+		existing, ok := merged[param]
+		if !ok {
+			merged[param] = p.clone()
+			continue
+		}
+		combined, err := mergePolicy(existing, p)
+		if err != nil {
+			return nil, fmt.Errorf(`federation.MergePolicies: parameter %q: %w`, param, err)
+		}
+		merged[param] = combined
+	}
+	return merged, nil
+}
+
+// Apply applies `mp` to `metadata`, and returns the resulting metadata
+// (OpenID Federation 1.0 Section 6.1.4.2). `metadata` is not modified.
+// An error is returned if the metadata does not comply with the policy.
+func (mp MetadataPolicy) Apply(metadata map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		result[k] = v
+	}
+
+	params := make([]string, 0, len(mp))
+	for param := range mp {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		p := mp[param]
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf(`federation.MetadataPolicy.Apply: parameter %q: %w`, param, err)
+		}
+		if err := p.apply(param, result); err != nil {
+			return nil, fmt.Errorf(`federation.MetadataPolicy.Apply: parameter %q: %w`, param, err)
+		}
+	}
+	return result, nil
+}
+
+func (p Policy) clone() Policy {
+	c := make(Policy, len(p))
+	for k, v := range p {
+		c[k] = v
+	}
+	return c
+}
+
+func (p Policy) validate() error {
+	for op, v := range p {
+		switch op {
+		case OperatorValue, OperatorDefault:
+		case OperatorAdd, OperatorOneOf, OperatorSubsetOf, OperatorSupersetOf:
+			if _, ok := toSlice(v); !ok {
+				return fmt.Errorf(`value of operator %q must be an array`, op)
+			}
+		case OperatorEssential:
+			if _, ok := v.(bool); !ok {
+				return fmt.Errorf(`value of operator %q must be a boolean`, op)
+			}
+		default:
+			return fmt.Errorf(`unknown operator %q`, op)
+		}
+	}
+	if _, ok := p[OperatorOneOf]; ok {
+		if _, ok := p[OperatorSubsetOf]; ok {
+			return fmt.Errorf(`operators %q and %q may not be combined`, OperatorOneOf, OperatorSubsetOf)
+		}
+		if _, ok := p[OperatorSupersetOf]; ok {
+			return fmt.Errorf(`operators %q and %q may not be combined`, OperatorOneOf, OperatorSupersetOf)
+		}
+	}
+	if sub, ok := p[OperatorSubsetOf]; ok {
+		if super, ok := p[OperatorSupersetOf]; ok {
+			subs, _ := toSlice(sub)
+			supers, _ := toSlice(super)
+			if !containsAll(subs, supers) {
+				return fmt.Errorf(`values of %q must be a superset of the values of %q`, OperatorSubsetOf, OperatorSupersetOf)
+			}
+		}
+	}
+	return nil
+}
+
+func mergePolicy(superior, subordinate Policy) (Policy, error) {
+	merged := superior.clone()
+	for op, v := range subordinate {
+		existing, ok := merged[op]
+		if !ok {
+			merged[op] = v
+			continue
+		}
+		switch op {
+		case OperatorValue, OperatorDefault:
+			if !reflect.DeepEqual(existing, v) {
+				return nil, fmt.Errorf(`conflicting values for operator %q`, op)
+			}
+		case OperatorAdd, OperatorSupersetOf:
+			a, _ := toSlice(existing)
+			b, _ := toSlice(v)
+			merged[op] = union(a, b)
+		case OperatorOneOf, OperatorSubsetOf:
+			a, _ := toSlice(existing)
+			b, _ := toSlice(v)
+			values := intersection(a, b)
+			if op == OperatorOneOf && len(values) == 0 {
+				return nil, fmt.Errorf(`values of operator %q do not intersect`, op)
+			}
+			merged[op] = values
+		case OperatorEssential:
+			//nolint:forcetypeassert
+			merged[op] = existing.(bool) || v.(bool)
+		}
+	}
+	if err := merged.validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func (p Policy) apply(param string, metadata map[string]interface{}) error {
+	for _, op := range operatorOrder {
+		v, ok := p[op]
+		if !ok {
+			continue
+		}
+		current, present := metadata[param]
+		switch op {
+		case OperatorValue:
+			if v == nil {
+				delete(metadata, param)
+			} else {
+				metadata[param] = v
+			}
+		case OperatorAdd:
+			values, _ := toSlice(v)
+			if !present {
+				metadata[param] = values
+				continue
+			}
+			existing, ok := toSlice(current)
+			if !ok {
+				return fmt.Errorf(`operator %q requires an array`, op)
+			}
+			metadata[param] = union(existing, values)
+		case OperatorDefault:
+			if !present {
+				metadata[param] = v
+			}
+		case OperatorOneOf:
+			if !present {
+				continue
+			}
+			values, _ := toSlice(v)
+			if !contains(values, current) {
+				return fmt.Errorf(`value %v is not one of %v`, current, values)
+			}
+		case OperatorSubsetOf:
+			if !present {
+				continue
+			}
+			existing, ok := toSlice(current)
+			if !ok {
+				return fmt.Errorf(`operator %q requires an array`, op)
+			}
+			values, _ := toSlice(v)
+			metadata[param] = intersection(existing, values)
+		case OperatorSupersetOf:
+			if !present {
+				continue
+			}
+			existing, ok := toSlice(current)
+			if !ok {
+				return fmt.Errorf(`operator %q requires an array`, op)
+			}
+			values, _ := toSlice(v)
+			if !containsAll(existing, values) {
+				return fmt.Errorf(`values %v are not a superset of %v`, existing, values)
+			}
+		case OperatorEssential:
+			//nolint:forcetypeassert
+			if v.(bool) && !present {
+				return fmt.Errorf(`parameter is essential, but is missing`)
+			}
+		}
+	}
+	return nil
+}
+
+func toSlice(v interface{}) ([]interface{}, bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		s := make([]interface{}, len(v))
+		for i, e := range v {
+			s[i] = e
+		}
+		return s, true
+	default:
+		return nil, false
+	}
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(values, required []interface{}) bool {
+	for _, v := range required {
+		if !contains(values, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func union(a, b []interface{}) []interface{} {
+	result := append([]interface{}(nil), a...)
+	for _, v := range b {
+		if !contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func intersection(a, b []interface{}) []interface{} {
+	result := []interface{}{}
+	for _, v := range a {
+		if contains(b, v) && !contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
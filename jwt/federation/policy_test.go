@@ -0,0 +1,119 @@
+package federation_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt/federation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataPolicy(t *testing.T) {
+	t.Run("merge", func(t *testing.T) {
+		superior := federation.MetadataPolicy{
+			`scope`: {
+				federation.OperatorSubsetOf: []interface{}{`openid`, `email`, `profile`},
+			},
+			`id_token_signed_response_alg`: {
+				federation.OperatorOneOf:   []interface{}{`ES256`, `RS256`},
+				federation.OperatorDefault: `ES256`,
+			},
+			`contacts`: {
+				federation.OperatorAdd: []interface{}{`ta@example.com`},
+			},
+		}
+		subordinate := federation.MetadataPolicy{
+			`scope`: {
+				federation.OperatorSubsetOf:   []interface{}{`openid`, `email`, `address`},
+				federation.OperatorSupersetOf: []interface{}{`openid`},
+			},
+			`id_token_signed_response_alg`: {
+				federation.OperatorOneOf:     []interface{}{`ES256`, `ES384`},
+				federation.OperatorEssential: true,
+			},
+			`contacts`: {
+				federation.OperatorAdd: []interface{}{`ia@example.com`},
+			},
+		}
+		merged, err := federation.MergePolicies(superior, subordinate)
+		require.NoError(t, err, `federation.MergePolicies should succeed`)
+		require.Equal(t, federation.MetadataPolicy{
+			`scope`: {
+				federation.OperatorSubsetOf:   []interface{}{`openid`, `email`},
+				federation.OperatorSupersetOf: []interface{}{`openid`},
+			},
+			`id_token_signed_response_alg`: {
+				federation.OperatorOneOf:     []interface{}{`ES256`},
+				federation.OperatorDefault:   `ES256`,
+				federation.OperatorEssential: true,
+			},
+			`contacts`: {
+				federation.OperatorAdd: []interface{}{`ta@example.com`, `ia@example.com`},
+			},
+		}, merged)
+
+		md, err := merged.Apply(map[string]interface{}{
+			`scope`:    []interface{}{`openid`, `email`, `phone`},
+			`contacts`: []interface{}{`rp@example.com`},
+		})
+		require.NoError(t, err, `Apply should succeed`)
+		require.Equal(t, map[string]interface{}{
+			`scope`:                        []interface{}{`openid`, `email`},
+			`id_token_signed_response_alg`: `ES256`,
+			`contacts`:                     []interface{}{`rp@example.com`, `ta@example.com`, `ia@example.com`},
+		}, md)
+	})
+	t.Run("conflicts", func(t *testing.T) {
+		_, err := federation.MergePolicies(
+			federation.MetadataPolicy{`application_type`: {federation.OperatorValue: `web`}},
+			federation.MetadataPolicy{`application_type`: {federation.OperatorValue: `native`}},
+		)
+		require.Error(t, err, `conflicting values should be rejected`)
+
+		_, err = federation.MergePolicies(
+			federation.MetadataPolicy{`alg`: {federation.OperatorOneOf: []interface{}{`ES256`}}},
+			federation.MetadataPolicy{`alg`: {federation.OperatorOneOf: []interface{}{`RS256`}}},
+		)
+		require.Error(t, err, `disjoint one_of values should be rejected`)
+
+		_, err = federation.MergePolicies(
+			federation.MetadataPolicy{`alg`: {`regexp`: `^ES`}},
+			nil,
+		)
+		require.Error(t, err, `unknown operators should be rejected`)
+	})
+	t.Run("apply", func(t *testing.T) {
+		policy := federation.MetadataPolicy{
+			`token_endpoint_auth_method`: {
+				federation.OperatorOneOf:     []interface{}{`private_key_jwt`},
+				federation.OperatorEssential: true,
+			},
+			`grant_types`: {
+				federation.OperatorSupersetOf: []interface{}{`authorization_code`},
+			},
+			`logo_uri`: {
+				federation.OperatorValue: nil,
+			},
+		}
+
+		md, err := policy.Apply(map[string]interface{}{
+			`token_endpoint_auth_method`: `private_key_jwt`,
+			`grant_types`:                []interface{}{`authorization_code`, `refresh_token`},
+			`logo_uri`:                   `https://rp.example.com/logo.png`,
+		})
+		require.NoError(t, err, `Apply should succeed`)
+		_, ok := md[`logo_uri`]
+		require.False(t, ok, `null values should remove the parameter`)
+
+		_, err = policy.Apply(map[string]interface{}{
+			`token_endpoint_auth_method`: `client_secret_basic`,
+		})
+		require.Error(t, err, `values that are not one_of should be rejected`)
+		_, err = policy.Apply(map[string]interface{}{})
+		require.Error(t, err, `missing essential parameters should be rejected`)
+		_, err = policy.Apply(map[string]interface{}{
+			`token_endpoint_auth_method`: `private_key_jwt`,
+			`grant_types`:                []interface{}{`refresh_token`},
+		})
+		require.Error(t, err, `values that are not a superset should be rejected`)
+	})
+}
@@ -0,0 +1,329 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// DefaultMaxPathLength is the default maximum number of intermediate
+// entities between the subject and the trust anchor of a `TrustChain`.
+const DefaultMaxPathLength = 8
+
+// maxStatementSize is the maximum size of an entity statement that is read
+const maxStatementSize = 1 << 20
+
+// HTTPClient is the interface of the HTTP client used to fetch entity
+// statements. *http.Client satisfies this interface.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// TrustChain is a sequence of entity statements that starts with the
+// entity configuration of the subject, continues with the subordinate
+// statements issued by each superior about its subordinate, and ends
+// with the entity configuration of the trust anchor
+// (OpenID Federation 1.0 Section 4). If the subject is a trust anchor,
+// the chain consists of its entity configuration only.
+type TrustChain struct {
+	Statements []*EntityStatement
+}
+
+// Subject returns the entity configuration of the subject of the chain.
+func (c *TrustChain) Subject() *EntityStatement {
+	return c.Statements[0]
+}
+
+// TrustAnchor returns the entity identifier of the trust anchor.
+func (c *TrustChain) TrustAnchor() string {
+	return c.Statements[len(c.Statements)-1].Subject
+}
+
+// Expiration returns the time at which the chain expires, i.e. the
+// earliest expiration time of its statements.
+func (c *TrustChain) Expiration() time.Time {
+	exp := c.Statements[0].Expiration
+	for _, s := range c.Statements[1:] {
+		if s.Expiration.Before(exp) {
+			exp = s.Expiration
+		}
+	}
+	return exp
+}
+
+// Metadata returns the metadata of the subject for the entity type
+// `entityType`, after applying the metadata policies of the subordinate
+// statements in the chain. The policies are merged starting from the one
+// closest to the trust anchor. Metadata parameters in the statement of
+// the immediate superior of the subject override those of the subject.
+func (c *TrustChain) Metadata(entityType string) (map[string]interface{}, error) {
+	md, ok := c.Subject().Metadata[entityType]
+	if !ok {
+		return nil, fmt.Errorf(`federation.TrustChain.Metadata: %q does not have %q metadata`, c.Subject().Subject, entityType)
+	}
+	if len(c.Statements) < 3 {
+		return md, nil
+	}
+
+	subordinates := c.Statements[1 : len(c.Statements)-1]
+	if overrides, ok := subordinates[0].Metadata[entityType]; ok {
+		merged := make(map[string]interface{}, len(md)+len(overrides))
+		for k, v := range md {
+			merged[k] = v
+		}
+		for k, v := range overrides {
+			merged[k] = v
+		}
+		md = merged
+	}
+
+	policy := MetadataPolicy{}
+	for i := len(subordinates) - 1; i >= 0; i-- {
+		p, ok := subordinates[i].MetadataPolicy[entityType]
+		if !ok {
+			continue
+		}
+		merged, err := MergePolicies(policy, p)
+		if err != nil {
+			return nil, fmt.Errorf(`federation.TrustChain.Metadata: failed to merge policy of %q: %w`, subordinates[i].Issuer, err)
+		}
+		policy = merged
+	}
+	result, err := policy.Apply(md)
+	if err != nil {
+		return nil, fmt.Errorf(`federation.TrustChain.Metadata: %w`, err)
+	}
+	return result, nil
+}
+
+// Resolver resolves trust chains from entities to a set of trust anchors.
+// A Resolver may be used from multiple goroutines concurrently.
+type Resolver struct {
+	anchors       map[string]jwk.Set
+	client        HTTPClient
+	clock         jwt.Clock
+	skew          time.Duration
+	maxPathLength int
+}
+
+// NewResolver creates a new Resolver that trusts the trust anchors in
+// `anchors`, which maps their entity identifiers to their federation
+// entity keys, obtained out of band.
+//
+// `WithHTTPClient()`, `WithClock()`, `WithAcceptableSkew()`, and
+// `WithMaxPathLength()` may be specified as options.
+func NewResolver(anchors map[string]jwk.Set, options ...Option) (*Resolver, error) {
+	if len(anchors) == 0 {
+		return nil, fmt.Errorf(`federation.NewResolver: at least one trust anchor must be specified`)
+	}
+	r := &Resolver{
+		anchors:       make(map[string]jwk.Set, len(anchors)),
+		client:        http.DefaultClient,
+		clock:         jwt.ClockFunc(time.Now),
+		maxPathLength: DefaultMaxPathLength,
+	}
+	for id, keys := range anchors {
+		if keys == nil || keys.Len() == 0 {
+			return nil, fmt.Errorf(`federation.NewResolver: no keys specified for trust anchor %q`, id)
+		}
+		r.anchors[id] = keys
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			if v, _ := o.Value().(HTTPClient); v != nil {
+				r.client = v
+			}
+		case identClock{}:
+			r.clock = o.Value().(jwt.Clock)
+		case identAcceptableSkew{}:
+			r.skew = o.Value().(time.Duration)
+		case identMaxPathLength{}:
+			r.maxPathLength = o.Value().(int)
+			if r.maxPathLength < 0 {
+				return nil, fmt.Errorf(`federation.NewResolver: max path length must not be negative`)
+			}
+		default:
+			return nil, fmt.Errorf(`federation.NewResolver: invalid option %T`, o)
+		}
+	}
+	return r, nil
+}
+
+// Resolve resolves a trust chain from the entity `entityID` to one of the
+// trust anchors. The authority hints of each entity are tried in order,
+// and the first valid chain is returned.
+//
+// Each statement in the chain is verified: the entity configuration of
+// the subject, and of each intermediate, using the keys in the subordinate
+// statement issued by its superior, each subordinate statement using the
+// keys of its issuer, and the entity configuration of the trust anchor
+// using the keys configured for it.
+func (r *Resolver) Resolve(ctx context.Context, entityID string) (*TrustChain, error) {
+	ec, err := r.fetchConfiguration(ctx, entityID)
+	if err != nil {
+		return nil, fmt.Errorf(`federation.Resolver.Resolve: %w`, err)
+	}
+	if keys, ok := r.anchors[entityID]; ok {
+		if err := ec.Verify(keys, r.clock.Now(), r.skew); err != nil {
+			return nil, fmt.Errorf(`federation.Resolver.Resolve: %w`, err)
+		}
+		return &TrustChain{Statements: []*EntityStatement{ec}}, nil
+	}
+
+	rest, err := r.resolveSuperiors(ctx, ec, 0, map[string]struct{}{entityID: {}})
+	if err != nil {
+		return nil, fmt.Errorf(`federation.Resolver.Resolve: failed to resolve trust chain for %q: %w`, entityID, err)
+	}
+	return &TrustChain{Statements: append([]*EntityStatement{ec}, rest...)}, nil
+}
+
+// resolveSuperiors returns the part of the chain above the entity
+// whose configuration is `ec`, which has been verified to be self-signed
+func (r *Resolver) resolveSuperiors(ctx context.Context, ec *EntityStatement, depth int, visited map[string]struct{}) ([]*EntityStatement, error) {
+	if len(ec.AuthorityHints) == 0 {
+		return nil, fmt.Errorf(`%q is not a trust anchor, and has no authority hints`, ec.Subject)
+	}
+
+	var errs []string
+	for _, hint := range ec.AuthorityHints {
+		if _, ok := visited[hint]; ok {
+			errs = append(errs, fmt.Sprintf(`%s: loop detected`, hint))
+			continue
+		}
+		chain, err := r.resolveSuperior(ctx, ec, hint, depth, visited)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf(`%s: %s`, hint, err))
+			continue
+		}
+		return chain, nil
+	}
+	return nil, errors.New(strings.Join(errs, `; `))
+}
+
+func (r *Resolver) resolveSuperior(ctx context.Context, ec *EntityStatement, superiorID string, depth int, visited map[string]struct{}) ([]*EntityStatement, error) {
+	_, isAnchor := r.anchors[superiorID]
+	if !isAnchor && depth >= r.maxPathLength {
+		return nil, fmt.Errorf(`maximum path length %d exceeded`, r.maxPathLength)
+	}
+
+	superior, err := r.fetchConfiguration(ctx, superiorID)
+	if err != nil {
+		return nil, err
+	}
+	if isAnchor {
+		if err := superior.Verify(r.anchors[superiorID], r.clock.Now(), r.skew); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := r.fetchSubordinate(ctx, superior, ec.Subject)
+	if err != nil {
+		return nil, err
+	}
+	// statements are verified against the time at which they were
+	// fetched, as they may have been issued while the fetch was in flight
+	now := r.clock.Now()
+	if err := stmt.Verify(superior.JWKS, now, r.skew); err != nil {
+		return nil, err
+	}
+	// the subordinate must be using the keys that its superior vouches for
+	if err := ec.Verify(stmt.JWKS, now, r.skew); err != nil {
+		return nil, err
+	}
+
+	if isAnchor {
+		return []*EntityStatement{stmt, superior}, nil
+	}
+
+	next := make(map[string]struct{}, len(visited)+1)
+	for k := range visited {
+		next[k] = struct{}{}
+	}
+	next[superiorID] = struct{}{}
+	rest, err := r.resolveSuperiors(ctx, superior, depth+1, next)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*EntityStatement{stmt}, rest...), nil
+}
+
+func (r *Resolver) fetchConfiguration(ctx context.Context, entityID string) (*EntityStatement, error) {
+	buf, err := r.fetch(ctx, strings.TrimSuffix(entityID, `/`)+ConfigurationPath)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to fetch entity configuration of %q: %w`, entityID, err)
+	}
+	ec, err := Parse(buf)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid entity configuration of %q: %w`, entityID, err)
+	}
+	if !ec.IsConfiguration() || ec.Subject != entityID {
+		return nil, fmt.Errorf(`entity configuration of %q is issued by %q about %q`, entityID, ec.Issuer, ec.Subject)
+	}
+	if err := ec.Verify(ec.JWKS, r.clock.Now(), r.skew); err != nil {
+		return nil, fmt.Errorf(`invalid entity configuration of %q: %w`, entityID, err)
+	}
+	return ec, nil
+}
+
+func (r *Resolver) fetchSubordinate(ctx context.Context, superior *EntityStatement, subject string) (*EntityStatement, error) {
+	v, ok := superior.Metadata[FederationEntity][FetchEndpointKey]
+	if !ok {
+		return nil, fmt.Errorf(`%q does not have a fetch endpoint`, superior.Subject)
+	}
+	endpoint, ok := v.(string)
+	if !ok || endpoint == `` {
+		return nil, fmt.Errorf(`invalid fetch endpoint of %q`, superior.Subject)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid fetch endpoint of %q: %w`, superior.Subject, err)
+	}
+	q := u.Query()
+	q.Set(`sub`, subject)
+	u.RawQuery = q.Encode()
+
+	buf, err := r.fetch(ctx, u.String())
+	if err != nil {
+		return nil, fmt.Errorf(`failed to fetch statement of %q about %q: %w`, superior.Subject, subject, err)
+	}
+	stmt, err := Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Issuer != superior.Subject || stmt.Subject != subject {
+		return nil, fmt.Errorf(`statement fetched from %q is issued by %q about %q`, superior.Subject, stmt.Issuer, stmt.Subject)
+	}
+	return stmt, nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to create request: %w`, err)
+	}
+	req.Header.Set(`Accept`, ContentTypeEntityStatement)
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`unexpected status code %d`, res.StatusCode)
+	}
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxStatementSize))
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read response: %w`, err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,267 @@
+package federation_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/federation"
+	"github.com/stretchr/testify/require"
+)
+
+// issuedAt is the time at which all statements in the tests are issued.
+// Resolvers are given a fixed clock, so that the results do not depend
+// on how long it takes to create and fetch the statements.
+var issuedAt = time.Unix(1700000000, 0)
+
+var clock = jwt.ClockFunc(func() time.Time { return issuedAt.Add(time.Minute) })
+
+type entity struct {
+	id   string
+	key  jwk.Key
+	keys jwk.Set
+}
+
+func newEntity(t *testing.T, id string) *entity {
+	t.Helper()
+	key, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, id+`#key`), `key.Set should succeed`)
+	pub, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+	keys := jwk.NewSet()
+	require.NoError(t, keys.AddKey(pub), `keys.AddKey should succeed`)
+	return &entity{id: id, key: key, keys: keys}
+}
+
+// statement creates a statement issued by `e` about `sub`, whose keys are `keys`
+func (e *entity) statement(t *testing.T, sub string, keys jwk.Set, claims map[string]interface{}) []byte {
+	t.Helper()
+	payload := map[string]interface{}{
+		`iss`:  e.id,
+		`sub`:  sub,
+		`iat`:  issuedAt.Unix(),
+		`exp`:  issuedAt.Add(time.Hour).Unix(),
+		`jwks`: keys,
+	}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	buf, err := json.Marshal(payload)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	hdrs := jws.NewHeaders()
+	require.NoError(t, hdrs.Set(jws.TypeKey, federation.TypeEntityStatement), `hdrs.Set should succeed`)
+	signed, err := jws.Sign(buf, jws.WithKey(jwa.ES256, e.key, jws.WithProtectedHeaders(hdrs)))
+	require.NoError(t, err, `jws.Sign should succeed`)
+	return signed
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+
+	ta := newEntity(t, srv.URL+`/ta`)
+	intermediate := newEntity(t, srv.URL+`/intermediate`)
+	rp := newEntity(t, srv.URL+`/rp`)
+	rogue := newEntity(t, srv.URL+`/rogue`)
+	impostor := newEntity(t, srv.URL+`/rp`)
+
+	serve := func(path string, fn func(r *http.Request) []byte) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			buf := fn(r)
+			if buf == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set(`Content-Type`, federation.ContentTypeEntityStatement)
+			_, _ = w.Write(buf)
+		})
+	}
+	fetchEndpoint := func(e *entity) map[string]interface{} {
+		return map[string]interface{}{
+			federation.FederationEntity: map[string]interface{}{
+				federation.FetchEndpointKey: e.id + `/fetch`,
+			},
+		}
+	}
+
+	serve(`/ta`+federation.ConfigurationPath, func(_ *http.Request) []byte {
+		return ta.statement(t, ta.id, ta.keys, map[string]interface{}{`metadata`: fetchEndpoint(ta)})
+	})
+	serve(`/ta/fetch`, func(r *http.Request) []byte {
+		if r.URL.Query().Get(`sub`) != intermediate.id {
+			return nil
+		}
+		return ta.statement(t, intermediate.id, intermediate.keys, map[string]interface{}{
+			`metadata_policy`: map[string]interface{}{
+				federation.OpenIDRelyingParty: map[string]interface{}{
+					`grant_types`: map[string]interface{}{
+						`subset_of`: []string{`authorization_code`, `refresh_token`},
+					},
+					`token_endpoint_auth_method`: map[string]interface{}{
+						`one_of`:    []string{`private_key_jwt`, `self_signed_tls_client_auth`},
+						`essential`: true,
+					},
+				},
+			},
+		})
+	})
+	serve(`/intermediate`+federation.ConfigurationPath, func(_ *http.Request) []byte {
+		return intermediate.statement(t, intermediate.id, intermediate.keys, map[string]interface{}{
+			`metadata`:        fetchEndpoint(intermediate),
+			`authority_hints`: []string{ta.id},
+		})
+	})
+	serve(`/intermediate/fetch`, func(r *http.Request) []byte {
+		if r.URL.Query().Get(`sub`) != rp.id {
+			return nil
+		}
+		return intermediate.statement(t, rp.id, rp.keys, map[string]interface{}{
+			`metadata_policy`: map[string]interface{}{
+				federation.OpenIDRelyingParty: map[string]interface{}{
+					`contacts`: map[string]interface{}{
+						`add`: []string{`ops@intermediate.example.com`},
+					},
+					`grant_types`: map[string]interface{}{
+						`subset_of`: []string{`authorization_code`},
+					},
+				},
+			},
+		})
+	})
+	rpMetadata := map[string]interface{}{
+		`metadata`: map[string]interface{}{
+			federation.OpenIDRelyingParty: map[string]interface{}{
+				`client_name`:                `RP`,
+				`grant_types`:                []string{`authorization_code`, `refresh_token`, `implicit`},
+				`token_endpoint_auth_method`: `private_key_jwt`,
+				`contacts`:                   []string{`ops@rp.example.com`},
+			},
+		},
+		`authority_hints`: []string{rogue.id, intermediate.id},
+	}
+	serve(`/rp`+federation.ConfigurationPath, func(r *http.Request) []byte {
+		if r.Header.Get(`X-Impostor`) != `` {
+			return impostor.statement(t, rp.id, impostor.keys, rpMetadata)
+		}
+		return rp.statement(t, rp.id, rp.keys, rpMetadata)
+	})
+	// the rogue entity is not subordinate to any trust anchor
+	serve(`/rogue`+federation.ConfigurationPath, func(_ *http.Request) []byte {
+		return rogue.statement(t, rogue.id, rogue.keys, map[string]interface{}{`metadata`: fetchEndpoint(rogue)})
+	})
+	serve(`/rogue/fetch`, func(_ *http.Request) []byte {
+		return rogue.statement(t, rp.id, rp.keys, nil)
+	})
+
+	r, err := federation.NewResolver(map[string]jwk.Set{ta.id: ta.keys}, federation.WithHTTPClient(srv.Client()), federation.WithClock(clock))
+	require.NoError(t, err, `federation.NewResolver should succeed`)
+
+	t.Run("resolve", func(t *testing.T) {
+		chain, err := r.Resolve(ctx, rp.id)
+		require.NoError(t, err, `r.Resolve should succeed`)
+		require.Len(t, chain.Statements, 4)
+		require.Equal(t, rp.id, chain.Subject().Subject)
+		require.Equal(t, ta.id, chain.TrustAnchor())
+		require.True(t, chain.Expiration().After(clock.Now()))
+
+		md, err := chain.Metadata(federation.OpenIDRelyingParty)
+		require.NoError(t, err, `chain.Metadata should succeed`)
+		require.Equal(t, `RP`, md[`client_name`])
+		require.Equal(t, []interface{}{`authorization_code`}, md[`grant_types`])
+		require.Equal(t, []interface{}{`ops@rp.example.com`, `ops@intermediate.example.com`}, md[`contacts`])
+
+		_, err = chain.Metadata(federation.OpenIDProvider)
+		require.Error(t, err, `chain.Metadata should fail for missing entity types`)
+	})
+	t.Run("trust anchor", func(t *testing.T) {
+		chain, err := r.Resolve(ctx, ta.id)
+		require.NoError(t, err, `r.Resolve should succeed`)
+		require.Len(t, chain.Statements, 1)
+	})
+	t.Run("untrusted entity", func(t *testing.T) {
+		_, err := r.Resolve(ctx, rogue.id)
+		require.Error(t, err, `r.Resolve should fail`)
+	})
+	t.Run("impostor", func(t *testing.T) {
+		// a self-signed configuration using keys that the superior does not vouch for
+		cl := *srv.Client()
+		tr := cl.Transport
+		cl.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set(`X-Impostor`, `1`)
+			return tr.RoundTrip(req)
+		})
+		r, err := federation.NewResolver(map[string]jwk.Set{ta.id: ta.keys}, federation.WithHTTPClient(&cl), federation.WithClock(clock))
+		require.NoError(t, err, `federation.NewResolver should succeed`)
+		_, err = r.Resolve(ctx, rp.id)
+		require.Error(t, err, `r.Resolve should fail`)
+	})
+	t.Run("max path length", func(t *testing.T) {
+		r, err := federation.NewResolver(map[string]jwk.Set{ta.id: ta.keys},
+			federation.WithHTTPClient(srv.Client()),
+			federation.WithClock(clock),
+			federation.WithMaxPathLength(0),
+		)
+		require.NoError(t, err, `federation.NewResolver should succeed`)
+		_, err = r.Resolve(ctx, rp.id)
+		require.Error(t, err, `r.Resolve should fail`)
+		_, err = r.Resolve(ctx, intermediate.id)
+		require.NoError(t, err, `r.Resolve should succeed for direct subordinates`)
+	})
+	t.Run("issued in the future", func(t *testing.T) {
+		early := jwt.ClockFunc(func() time.Time { return issuedAt.Add(-time.Second) })
+		r, err := federation.NewResolver(map[string]jwk.Set{ta.id: ta.keys}, federation.WithHTTPClient(srv.Client()), federation.WithClock(early))
+		require.NoError(t, err, `federation.NewResolver should succeed`)
+		_, err = r.Resolve(ctx, rp.id)
+		require.Error(t, err, `r.Resolve should fail`)
+
+		r, err = federation.NewResolver(map[string]jwk.Set{ta.id: ta.keys},
+			federation.WithHTTPClient(srv.Client()),
+			federation.WithClock(early),
+			federation.WithAcceptableSkew(time.Minute),
+		)
+		require.NoError(t, err, `federation.NewResolver should succeed`)
+		_, err = r.Resolve(ctx, rp.id)
+		require.NoError(t, err, `r.Resolve should succeed within the acceptable skew`)
+	})
+	t.Run("wrong trust anchor keys", func(t *testing.T) {
+		r, err := federation.NewResolver(map[string]jwk.Set{ta.id: rogue.keys}, federation.WithHTTPClient(srv.Client()), federation.WithClock(clock))
+		require.NoError(t, err, `federation.NewResolver should succeed`)
+		_, err = r.Resolve(ctx, rp.id)
+		require.Error(t, err, `r.Resolve should fail`)
+	})
+	t.Run("ParseConfiguration", func(t *testing.T) {
+		s, err := federation.ParseConfiguration(rp.statement(t, rp.id, rp.keys, rpMetadata), clock.Now())
+		require.NoError(t, err, `federation.ParseConfiguration should succeed`)
+		require.True(t, s.IsConfiguration())
+		require.Equal(t, []string{rogue.id, intermediate.id}, s.AuthorityHints)
+
+		_, err = federation.ParseConfiguration(rp.statement(t, rp.id, rogue.keys, nil), clock.Now())
+		require.Error(t, err, `configurations that are not self-signed should be rejected`)
+		_, err = federation.ParseConfiguration(intermediate.statement(t, rp.id, rp.keys, nil), clock.Now())
+		require.Error(t, err, `subordinate statements should be rejected`)
+
+		signed, err := jws.Sign([]byte(`{}`), jws.WithKey(jwa.ES256, rp.key))
+		require.NoError(t, err, `jws.Sign should succeed`)
+		_, err = federation.Parse(signed)
+		require.True(t, err != nil && strings.Contains(err.Error(), `typ`), `statements without "typ" should be rejected`)
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,182 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/iter/mapiter"
+	"github.com/lestrrat-go/jwx/v2/internal/iter"
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
+)
+
+var errFrozenToken = errors.New(`token is frozen`)
+
+// ErrFrozenToken returns the opaque error value that is returned when
+// an attempt is made to modify a token created by `jwt.Freeze()`.
+// Use `errors.Is` to check for it.
+func ErrFrozenToken() error {
+	return errFrozenToken
+}
+
+// frozenToken is an immutable view of a token. The underlying token is
+// a private copy that is never modified, and values are copied before
+// they are handed out, so that callers cannot modify it either.
+type frozenToken struct {
+	token Token
+}
+
+// Freeze returns an immutable copy of the token `t`, which may be safely
+// shared between goroutines, for example by storing it in a request's
+// context after parsing it:
+//
+//	tok, err := jwt.Parse(src, jwt.WithKey(alg, key))
+//	...
+//	frozen, err := jwt.Freeze(tok)
+//	...
+//	ctx = context.WithValue(ctx, tokenKey{}, frozen)
+//
+// The methods of the tokens created by `jwt.New()` may be called
+// concurrently, but claim values such as maps and slices are shared
+// between the token and its callers, and therefore modifying them (or
+// the token) while another goroutine reads them is a data race.
+// Frozen tokens avoid this by returning copies of such values from
+// `Get()`, `PrivateClaims()`, `Audience()`, and the iteration methods.
+//
+// `Set()` and `Remove()` fail with an error matching
+// `jwt.ErrFrozenToken()`, and changes made to the `*TokenOptionSet`
+// returned by `Options()` have no effect. Use `Clone()` to obtain
+// a modifiable copy of a frozen token.
+//
+// Freezing a token that is already frozen returns the token itself.
+func Freeze(t Token) (Token, error) {
+	if _, ok := t.(*frozenToken); ok {
+		return t, nil
+	}
+
+	clone, err := t.Clone()
+	if err != nil {
+		return nil, fmt.Errorf(`failed to clone token: %w`, err)
+	}
+	return &frozenToken{token: clone}, nil
+}
+
+// IsFrozen returns true if the token was created by `jwt.Freeze()`.
+func IsFrozen(t Token) bool {
+	_, ok := t.(*frozenToken)
+	return ok
+}
+
+func (t *frozenToken) Audience() []string {
+	aud := t.token.Audience()
+	if aud == nil {
+		return nil
+	}
+	ret := make([]string, len(aud))
+	copy(ret, aud)
+	return ret
+}
+
+func (t *frozenToken) Expiration() time.Time {
+	return t.token.Expiration()
+}
+
+func (t *frozenToken) IssuedAt() time.Time {
+	return t.token.IssuedAt()
+}
+
+func (t *frozenToken) Issuer() string {
+	return t.token.Issuer()
+}
+
+func (t *frozenToken) JwtID() string {
+	return t.token.JwtID()
+}
+
+func (t *frozenToken) NotBefore() time.Time {
+	return t.token.NotBefore()
+}
+
+func (t *frozenToken) Subject() string {
+	return t.token.Subject()
+}
+
+func (t *frozenToken) PrivateClaims() map[string]interface{} {
+	//nolint:forcetypeassert
+	return claims.DeepCopy(t.token.PrivateClaims()).(map[string]interface{})
+}
+
+func (t *frozenToken) Get(name string) (interface{}, bool) {
+	v, ok := t.token.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return claims.DeepCopy(v), true
+}
+
+func (t *frozenToken) Set(name string, _ interface{}) error {
+	return fmt.Errorf(`failed to set %q: %w`, name, errFrozenToken)
+}
+
+func (t *frozenToken) Remove(name string) error {
+	return fmt.Errorf(`failed to remove %q: %w`, name, errFrozenToken)
+}
+
+func (t *frozenToken) Options() *TokenOptionSet {
+	options := *(t.token.Options())
+	return &options
+}
+
+// Clone returns a deep copy of the underlying token, which is not frozen.
+func (t *frozenToken) Clone() (Token, error) {
+	return t.token.Clone()
+}
+
+func (t *frozenToken) Iterate(ctx context.Context) Iterator {
+	var pairs []*ClaimPair
+	for it := t.token.Iterate(ctx); it.Next(ctx); {
+		pair := it.Pair()
+		pairs = append(pairs, &ClaimPair{Key: pair.Key, Value: claims.DeepCopy(pair.Value)})
+	}
+
+	ch := make(chan *ClaimPair, len(pairs))
+	for _, pair := range pairs {
+		ch <- pair
+	}
+	close(ch)
+	return mapiter.New(ch)
+}
+
+func (t *frozenToken) Walk(ctx context.Context, visitor Visitor) error {
+	return iter.WalkMap(ctx, t, visitor)
+}
+
+func (t *frozenToken) AsMap(ctx context.Context) (map[string]interface{}, error) {
+	return iter.AsMap(ctx, t)
+}
+
+func (t *frozenToken) Equal(other Token) bool {
+	return Equal(t, other)
+}
+
+func (t *frozenToken) Expired(clock Clock) bool {
+	return Expired(t, clock)
+}
+
+func (t *frozenToken) RemainingValidity(clock Clock) time.Duration {
+	return RemainingValidity(t, clock)
+}
+
+func (t *frozenToken) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.token)
+}
+
+func (t *frozenToken) UnmarshalJSON([]byte) error {
+	return fmt.Errorf(`failed to unmarshal token: %w`, errFrozenToken)
+}
+
+func (t *frozenToken) String() string {
+	return RedactedString(t)
+}
@@ -0,0 +1,120 @@
+package jwt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeze(t *testing.T) {
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+	exp := time.Unix(time.Now().Add(time.Hour).Unix(), 0).UTC()
+
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Audience([]string{`api`, `web`}).
+		Expiration(exp).
+		Claim(`roles`, []interface{}{`admin`}).
+		Claim(`profile`, map[string]interface{}{`name`: `Alice`}).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	frozen, err := jwt.Freeze(tok)
+	require.NoError(t, err, `jwt.Freeze should succeed`)
+	require.True(t, jwt.IsFrozen(frozen), `token should be frozen`)
+	require.False(t, jwt.IsFrozen(tok), `original token should not be frozen`)
+
+	again, err := jwt.Freeze(frozen)
+	require.NoError(t, err, `jwt.Freeze should succeed`)
+	require.True(t, again == frozen, `freezing a frozen token should return the same token`)
+
+	t.Run("accessors", func(t *testing.T) {
+		require.Equal(t, `alice`, frozen.Subject())
+		require.Equal(t, []string{`api`, `web`}, frozen.Audience())
+		require.True(t, exp.Equal(frozen.Expiration()), `exp should match`)
+
+		v, ok := frozen.Get(`profile`)
+		require.True(t, ok, `frozen.Get should succeed`)
+		require.Equal(t, map[string]interface{}{`name`: `Alice`}, v)
+
+		m, err := frozen.AsMap(context.Background())
+		require.NoError(t, err, `frozen.AsMap should succeed`)
+		require.Len(t, m, 5)
+
+		var dst struct {
+			Subject string   `json:"sub"`
+			Roles   []string `json:"roles"`
+		}
+		require.NoError(t, jwt.Decode(frozen, &dst), `jwt.Decode should succeed`)
+		require.Equal(t, `alice`, dst.Subject)
+		require.Equal(t, []string{`admin`}, dst.Roles)
+	})
+	t.Run("immutability", func(t *testing.T) {
+		err := frozen.Set(jwt.SubjectKey, `mallory`)
+		require.Error(t, err, `frozen.Set should fail`)
+		require.True(t, errors.Is(err, jwt.ErrFrozenToken()), `error should be jwt.ErrFrozenToken`)
+
+		err = frozen.Remove(jwt.SubjectKey)
+		require.True(t, errors.Is(err, jwt.ErrFrozenToken()), `error should be jwt.ErrFrozenToken`)
+
+		require.Error(t, json.Unmarshal([]byte(`{"sub":"mallory"}`), frozen), `json.Unmarshal should fail`)
+
+		// Modifying returned values does not affect the token
+		v, _ := frozen.Get(`profile`)
+		v.(map[string]interface{})[`name`] = `Mallory`
+		frozen.PrivateClaims()[`roles`] = `none`
+		frozen.Audience()[0] = `evil`
+		frozen.Options().Enable(jwt.FlattenAudience)
+
+		v, _ = frozen.Get(`profile`)
+		require.Equal(t, map[string]interface{}{`name`: `Alice`}, v)
+		v, _ = frozen.Get(`roles`)
+		require.Equal(t, []interface{}{`admin`}, v)
+		require.Equal(t, []string{`api`, `web`}, frozen.Audience())
+		require.False(t, frozen.Options().IsEnabled(jwt.FlattenAudience), `options should not change`)
+
+		// Modifying the original token does not affect the frozen token
+		require.NoError(t, tok.Set(jwt.SubjectKey, `bob`), `tok.Set should succeed`)
+		require.Equal(t, `alice`, frozen.Subject())
+	})
+	t.Run("Clone", func(t *testing.T) {
+		clone, err := frozen.Clone()
+		require.NoError(t, err, `frozen.Clone should succeed`)
+		require.False(t, jwt.IsFrozen(clone), `clone should not be frozen`)
+		require.NoError(t, clone.Set(jwt.SubjectKey, `carol`), `clone.Set should succeed`)
+		require.Equal(t, `alice`, frozen.Subject())
+	})
+	t.Run("sign and parse", func(t *testing.T) {
+		signed, err := jwt.Sign(frozen, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `alice`, parsed.Subject())
+		require.NoError(t, jwt.Validate(frozen), `jwt.Validate should succeed`)
+	})
+	t.Run("concurrent access", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					v, _ := frozen.Get(`profile`)
+					v.(map[string]interface{})[`seen`] = j
+					_ = fmt.Sprintf(`%v`, frozen)
+					_, _ = json.Marshal(frozen)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
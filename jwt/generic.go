@@ -0,0 +1,86 @@
+//go:build go1.21
+// +build go1.21
+
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
+)
+
+var errClaimNotFound = errors.New(`claim not found`)
+
+// ErrClaimNotFound returns the opaque error value that is returned by
+// `jwt.Get()` and `(jwt.Claim).Value()` when the token does not contain
+// the requested claim. Use `errors.Is` to check for it.
+func ErrClaimNotFound() error {
+	return errClaimNotFound
+}
+
+// Get returns the value of the claim `name` in the token `t` as a value
+// of type T, for example:
+//
+//	roles, err := jwt.Get[[]string](tok, `roles`)
+//	exp, err := jwt.Get[time.Time](tok, jwt.ExpirationKey)
+//
+// If the value is not of type T, it is converted using the same rules as
+// `jwt.Decode()`: time values such as `exp` may be retrieved as
+// integers or floats, in which case they are converted to Unix time, and
+// other values are converted through their JSON representation (e.g.
+// a private claim that was parsed as `[]interface{}` may be retrieved as
+// `[]string`). An error is returned if the conversion fails.
+//
+// If the claim does not exist, the returned error matches
+// `jwt.ErrClaimNotFound()` when checked using `errors.Is`.
+func Get[T any](t Token, name string) (T, error) {
+	var zero T
+	v, ok := t.Get(name)
+	if !ok {
+		return zero, fmt.Errorf(`%w: %q`, errClaimNotFound, name)
+	}
+
+	if tv, ok := v.(T); ok {
+		return tv, nil
+	}
+
+	var dst T
+	if err := claims.Convert(&dst, v); err != nil {
+		return zero, fmt.Errorf(`failed to convert claim %q to %s: %w`, name, reflect.TypeOf(&zero).Elem(), err)
+	}
+	return dst, nil
+}
+
+// Claim is a typed accessor for the claim of the given name. It is
+// useful to declare the claims that your application uses once, and
+// access them without type assertions:
+//
+//	var rolesClaim = jwt.NewClaim[[]string](`roles`)
+//
+//	roles, err := rolesClaim.Value(tok)
+type Claim[T any] struct {
+	name string
+}
+
+// NewClaim creates a new typed accessor for the claim `name`.
+func NewClaim[T any](name string) Claim[T] {
+	return Claim[T]{name: name}
+}
+
+// Name returns the name of the claim.
+func (c Claim[T]) Name() string {
+	return c.name
+}
+
+// Value returns the value of the claim in the token `t`.
+// See `jwt.Get()` for details.
+func (c Claim[T]) Value(t Token) (T, error) {
+	return Get[T](t, c.name)
+}
+
+// Set sets the value of the claim in the token `t`.
+func (c Claim[T]) Set(t Token, v T) error {
+	return t.Set(c.name, v)
+}
@@ -0,0 +1,92 @@
+//go:build go1.21
+// +build go1.21
+
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+	exp := time.Unix(time.Now().Add(time.Hour).Unix(), 0).UTC()
+
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Expiration(exp).
+		Claim(`roles`, []string{`admin`, `user`}).
+		Claim(`level`, 3).
+		Claim(`profile`, map[string]interface{}{`name`: `Alice`}).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	// Parse the token, so that private claims are decoded from JSON
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+	parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+
+	t.Run("standard claims", func(t *testing.T) {
+		sub, err := jwt.Get[string](parsed, jwt.SubjectKey)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.Equal(t, `alice`, sub)
+
+		v, err := jwt.Get[time.Time](parsed, jwt.ExpirationKey)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.True(t, exp.Equal(v), `exp should match`)
+
+		unix, err := jwt.Get[int64](parsed, jwt.ExpirationKey)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.Equal(t, exp.Unix(), unix)
+	})
+	t.Run("private claims", func(t *testing.T) {
+		roles, err := jwt.Get[[]string](parsed, `roles`)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.Equal(t, []string{`admin`, `user`}, roles)
+
+		level, err := jwt.Get[int](parsed, `level`)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.Equal(t, 3, level)
+
+		type Profile struct {
+			Name string `json:"name"`
+		}
+		profile, err := jwt.Get[Profile](parsed, `profile`)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.Equal(t, Profile{Name: `Alice`}, profile)
+
+		// Values that were set directly are returned as is
+		direct, err := jwt.Get[[]string](tok, `roles`)
+		require.NoError(t, err, `jwt.Get should succeed`)
+		require.Equal(t, []string{`admin`, `user`}, direct)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := jwt.Get[string](parsed, `missing`)
+		require.Error(t, err, `jwt.Get should fail for missing claims`)
+		require.True(t, errors.Is(err, jwt.ErrClaimNotFound()), `error should be jwt.ErrClaimNotFound`)
+
+		_, err = jwt.Get[int](parsed, jwt.SubjectKey)
+		require.Error(t, err, `jwt.Get should fail for incompatible types`)
+		require.False(t, errors.Is(err, jwt.ErrClaimNotFound()), `error should not be jwt.ErrClaimNotFound`)
+	})
+	t.Run("Claim", func(t *testing.T) {
+		rolesClaim := jwt.NewClaim[[]string](`roles`)
+		require.Equal(t, `roles`, rolesClaim.Name())
+
+		roles, err := rolesClaim.Value(parsed)
+		require.NoError(t, err, `rolesClaim.Value should succeed`)
+		require.Equal(t, []string{`admin`, `user`}, roles)
+
+		dst := jwt.New()
+		require.NoError(t, rolesClaim.Set(dst, []string{`guest`}), `rolesClaim.Set should succeed`)
+		roles, err = rolesClaim.Value(dst)
+		require.NoError(t, err, `rolesClaim.Value should succeed`)
+		require.Equal(t, []string{`guest`}, roles)
+	})
+}
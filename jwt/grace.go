@@ -0,0 +1,39 @@
+package jwt
+
+import (
+	"context"
+	"time"
+)
+
+// DegradedEvent describes a token that was accepted only because of the
+// grace period specified via `jwt.WithGracePeriod()`, and is passed to
+// the function specified via `jwt.WithDegradedCallback()`.
+type DegradedEvent struct {
+	// Token is the token that was accepted.
+	Token Token
+	// ExpiredFor is the duration since the token expired, not including
+	// the acceptable skew.
+	ExpiredFor time.Duration
+}
+
+// expiredWithinGrace checks if the token `t`, which is known to have
+// expired, is still within the grace period `grace`. If it is, the
+// duration since the token expired is returned
+func expiredWithinGrace(ctx context.Context, t Token, grace time.Duration) (time.Duration, bool) {
+	tv := t.Expiration()
+	if tv.IsZero() || tv.Unix() == 0 {
+		return 0, false
+	}
+
+	clock := ValidationCtxClock(ctx)      // MUST be populated
+	skew := ValidationCtxSkew(ctx)        // MUST be populated
+	trunc := ValidationCtxTruncation(ctx) // MUST be populated
+
+	now := clock.Now().Truncate(trunc)
+	ttv := tv.Truncate(trunc)
+
+	if !now.Before(ttv.Add(skew + grace)) {
+		return 0, false
+	}
+	return now.Sub(ttv.Add(skew)), true
+}
@@ -0,0 +1,98 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	clock := jwt.WithClock(jwt.ClockFunc(func() time.Time { return now }))
+	build := func(t *testing.T, exp time.Time) jwt.Token {
+		t.Helper()
+		tok, err := jwt.NewBuilder().Expiration(exp).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		return tok
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		t.Parallel()
+		var events []jwt.DegradedEvent
+		options := []jwt.ValidateOption{
+			clock,
+			jwt.WithAcceptableSkew(time.Minute),
+			jwt.WithGracePeriod(10 * time.Minute),
+			jwt.WithDegradedCallback(func(ev jwt.DegradedEvent) {
+				events = append(events, ev)
+			}),
+		}
+
+		require.NoError(t, jwt.Validate(build(t, now.Add(time.Hour)), options...), `valid tokens should pass`)
+		require.NoError(t, jwt.Validate(build(t, now.Add(-30*time.Second)), options...), `tokens within skew should pass`)
+		require.Empty(t, events, `tokens that are not expired should not be degraded`)
+
+		expired := build(t, now.Add(-6*time.Minute))
+		require.NoError(t, jwt.Validate(expired, options...), `tokens within grace period should pass`)
+		require.Len(t, events, 1, `tokens within grace period should be degraded`)
+		require.Equal(t, expired, events[0].Token)
+		require.Equal(t, 5*time.Minute, events[0].ExpiredFor)
+
+		err := jwt.Validate(build(t, now.Add(-12*time.Minute)), options...)
+		require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `tokens beyond grace period should fail`)
+		require.Len(t, events, 1)
+
+		// other checks must still pass for the token to be reported
+		err = jwt.Validate(expired, append(options, jwt.WithIssuer(`https://issuer.example.com`))...)
+		require.True(t, errors.Is(err, jwt.ErrInvalidIssuer()), `other checks should fail`)
+		require.Len(t, events, 1)
+
+		_, err = jwt.NewValidationPolicy(jwt.WithGracePeriod(-time.Minute))
+		require.Error(t, err, `negative grace period should be rejected`)
+	})
+	t.Run("replay within grace period", func(t *testing.T) {
+		t.Parallel()
+		current := now
+		options := []jwt.ValidateOption{
+			jwt.WithClock(jwt.ClockFunc(func() time.Time { return current })),
+			jwt.WithGracePeriod(10 * time.Minute),
+			jwt.WithReplayDetection(jwt.NewMemoryReplayStore(time.Hour)),
+		}
+		tok, err := jwt.NewBuilder().JwtID(`one-time`).Expiration(now.Add(time.Minute)).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+		require.NoError(t, jwt.Validate(tok, options...), `first use should succeed`)
+
+		// the entry must outlive exp, as the token is still accepted
+		// during the grace period
+		current = now.Add(5 * time.Minute)
+		err = jwt.Validate(tok, options...)
+		require.True(t, errors.Is(err, jwt.ErrTokenReplayed()), `replay within grace period should fail`)
+	})
+	t.Run("Parse", func(t *testing.T) {
+		t.Parallel()
+		key := []byte(`abracadabra-abracadabra-abracadabra`)
+		signed, err := jwt.Sign(build(t, now.Add(-time.Minute)), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), clock)
+		require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `expired tokens should fail without a grace period`)
+
+		var ev jwt.StatsEvent
+		_, err = jwt.Parse(signed,
+			jwt.WithKey(jwa.HS256, key),
+			clock,
+			jwt.WithGracePeriod(time.Hour),
+			jwt.WithStatsCallback(func(v jwt.StatsEvent) { ev = v }),
+		)
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.True(t, ev.Success)
+		require.True(t, ev.Degraded, `stats event should be flagged as degraded`)
+	})
+}
@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// TokenSource provides the serialized token that is attached to
+// outgoing calls. It is called for each call, so implementations
+// may sign new tokens, or refresh them as they expire.
+type TokenSource interface {
+	Token(context.Context) ([]byte, error)
+}
+
+// TokenSourceFunc is a TokenSource represented by a function.
+type TokenSourceFunc func(context.Context) ([]byte, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+// StaticToken creates a TokenSource that always returns `tok`.
+func StaticToken(tok []byte) TokenSource {
+	return TokenSourceFunc(func(context.Context) ([]byte, error) {
+		return tok, nil
+	})
+}
+
+// Credentials is a `credentials.PerRPCCredentials` that attaches a JWT
+// to each outgoing call as a bearer token in the "authorization" metadata.
+//
+//	conn, err := grpc.Dial(addr,
+//	  grpc.WithTransportCredentials(creds),
+//	  grpc.WithPerRPCCredentials(jwtgrpc.NewCredentials(src)),
+//	)
+type Credentials struct {
+	src           TokenSource
+	allowInsecure bool
+}
+
+var _ credentials.PerRPCCredentials = (*Credentials)(nil)
+
+// NewCredentials creates a new Credentials that obtains tokens from `src`.
+func NewCredentials(src TokenSource) *Credentials {
+	return &Credentials{src: src}
+}
+
+// AllowInsecure specifies whether the token may be sent over connections
+// without transport security. By default this is not allowed, as the token
+// could be intercepted and replayed.
+func (c *Credentials) AllowInsecure(v bool) *Credentials {
+	c.allowInsecure = v
+	return c
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (c *Credentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	tok, err := c.src.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt/grpc: failed to obtain token: %w`, err)
+	}
+	return map[string]string{
+		authorizationKey: bearerScheme + ` ` + string(tok),
+	}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials
+func (c *Credentials) RequireTransportSecurity() bool {
+	return !c.allowInsecure
+}
@@ -0,0 +1,198 @@
+// Package grpc provides gRPC server interceptors that authenticate calls
+// using JWTs passed as bearer tokens in the "authorization" metadata, and
+// a `credentials.PerRPCCredentials` implementation to attach such tokens
+// to outgoing calls.
+//
+// This package is a separate Go module, so that users of the
+// `github.com/lestrrat-go/jwx/v2` module do not depend on gRPC.
+//
+// As the name of this package clashes with google.golang.org/grpc, it is
+// usually imported using an alias such as `jwtgrpc`:
+//
+//	auth, err := jwtgrpc.New(
+//	  jwt.WithKeySetProvider(provider),
+//	  jwt.WithAudience(`https://api.example.com`),
+//	)
+//	if err != nil {
+//	  ...
+//	}
+//	srv := grpc.NewServer(
+//	  grpc.UnaryInterceptor(auth.UnaryServerInterceptor()),
+//	  grpc.StreamInterceptor(auth.StreamServerInterceptor()),
+//	)
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationKey = `authorization`
+const bearerScheme = `Bearer`
+
+// the identities of the options that cannot be overridden
+var (
+	identVerify   = jwt.WithVerify(true).Ident()
+	identValidate = jwt.WithValidate(true).Ident()
+)
+
+// Authenticator authenticates gRPC calls using JWTs.
+type Authenticator struct {
+	parseOptions []jwt.ParseOption
+	optional     bool
+	skip         map[string]struct{}
+}
+
+// New creates a new Authenticator.
+//
+// In addition to the options defined in this package, any `jwt.ParseOption`
+// (including `jwt.ValidateOption`) may be passed. They are passed to `jwt.Parse()`
+// as is, and therefore you must specify the source of the verification keys
+// (e.g. `jwt.WithKeySet()` or `jwt.WithKeySetProvider()`). Validation is always
+// performed, and `jwt.WithVerify()` or `jwt.WithValidate()` may not be specified.
+func New(options ...Option) (*Authenticator, error) {
+	a := &Authenticator{
+		skip: make(map[string]struct{}),
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identOptional{}:
+			a.optional = o.Value().(bool)
+		case identSkipMethods{}:
+			for _, name := range o.Value().([]string) {
+				a.skip[name] = struct{}{}
+			}
+		case identVerify, identValidate:
+			return nil, fmt.Errorf(`jwt/grpc.New: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		default:
+			po, ok := o.(jwt.ParseOption)
+			if !ok {
+				return nil, fmt.Errorf(`jwt/grpc.New: invalid option %T`, o)
+			}
+			a.parseOptions = append(a.parseOptions, po)
+		}
+	}
+	return a, nil
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// authenticates the call before invoking the handler. The token is
+// available to the handler via `FromContext()`.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// authenticates the call before invoking the handler. The token is
+// available to the handler via `FromContext()` on the stream's context.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// authenticate returns the context that should be passed to the handler.
+// The returned error is created using the status package
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if _, ok := a.skip[method]; ok {
+		return ctx, nil
+	}
+
+	src, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if src == `` {
+		if a.optional {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, `no access token was provided`)
+	}
+
+	options := append(a.parseOptions[:len(a.parseOptions):len(a.parseOptions)], jwt.WithContext(ctx))
+	tok, err := jwt.ParseString(src, options...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired()) {
+			return nil, status.Error(codes.Unauthenticated, `the access token has expired`)
+		}
+		return nil, status.Error(codes.Unauthenticated, `the access token is invalid`)
+	}
+	return NewContext(ctx, tok), nil
+}
+
+// bearerToken extracts the token from the "authorization" metadata.
+// An empty string is returned if the metadata does not exist, or if it
+// uses a different authentication scheme
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ``, nil
+	}
+
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return ``, nil
+	}
+	if len(values) > 1 {
+		return ``, status.Error(codes.InvalidArgument, `multiple authorization metadata were provided`)
+	}
+
+	v := strings.TrimSpace(values[0])
+	if len(v) < len(bearerScheme) || !strings.EqualFold(v[:len(bearerScheme)], bearerScheme) {
+		return ``, nil
+	}
+	rest := v[len(bearerScheme):]
+	if rest != `` && rest[0] != ' ' {
+		// e.g. "BearerXYZ"
+		return ``, nil
+	}
+
+	tok := strings.TrimSpace(rest)
+	if tok == `` || strings.ContainsAny(tok, " \t") {
+		return ``, status.Error(codes.InvalidArgument, `malformed authorization metadata`)
+	}
+	return tok, nil
+}
+
+type tokenKey struct{}
+
+// NewContext returns a new context that carries `tok`.
+func NewContext(ctx context.Context, tok jwt.Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, tok)
+}
+
+// FromContext returns the token stored in `ctx` by the interceptors.
+// The second return value is false if there is no token, e.g. if
+// the token is optional and the call did not carry one.
+func FromContext(ctx context.Context) (jwt.Token, bool) {
+	tok, ok := ctx.Value(tokenKey{}).(jwt.Token)
+	return tok, ok
+}
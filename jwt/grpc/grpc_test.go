@@ -0,0 +1,148 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwtgrpc "github.com/lestrrat-go/jwx/v2/jwt/grpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func TestInterceptors(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	sign := func(t *testing.T, exp time.Time) string {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Subject(`alice`).
+			Expiration(exp).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return string(signed)
+	}
+
+	auth, err := jwtgrpc.New(
+		jwt.WithKey(jwa.RS256, &key.PublicKey),
+		jwtgrpc.WithSkipMethods(`/grpc.health.v1.Health/Check`),
+	)
+	require.NoError(t, err, `jwtgrpc.New should succeed`)
+
+	unary := func(auth *jwtgrpc.Authenticator, method string, md ...string) (interface{}, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(md...))
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		return auth.UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			tok, ok := jwtgrpc.FromContext(ctx)
+			if !ok {
+				return ``, nil
+			}
+			return tok.Subject(), nil
+		})
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		res, err := unary(auth, `/test.Service/Method`, `authorization`, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.NoError(t, err, `call should succeed`)
+		require.Equal(t, `alice`, res)
+	})
+	t.Run("missing token", func(t *testing.T) {
+		_, err := unary(auth, `/test.Service/Method`)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("expired token", func(t *testing.T) {
+		_, err := unary(auth, `/test.Service/Method`, `authorization`, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("validation policy", func(t *testing.T) {
+		policy, err := jwt.NewValidationPolicy(jwt.WithRequiredClaim(jwt.SubjectKey))
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+		auth, err := jwtgrpc.New(
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			jwt.WithValidationPolicy(policy),
+		)
+		require.NoError(t, err, `jwtgrpc.New should succeed`)
+
+		res, err := unary(auth, `/test.Service/Method`, `authorization`, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.NoError(t, err, `call should succeed`)
+		require.Equal(t, `alice`, res)
+
+		_, err = unary(auth, `/test.Service/Method`, `authorization`, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("malformed metadata", func(t *testing.T) {
+		_, err := unary(auth, `/test.Service/Method`, `authorization`, `Bearer `)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("skipped method", func(t *testing.T) {
+		res, err := unary(auth, `/grpc.health.v1.Health/Check`)
+		require.NoError(t, err, `call should succeed`)
+		require.Equal(t, ``, res)
+	})
+	t.Run("optional token", func(t *testing.T) {
+		auth, err := jwtgrpc.New(jwt.WithKey(jwa.RS256, &key.PublicKey), jwtgrpc.WithOptional(true))
+		require.NoError(t, err, `jwtgrpc.New should succeed`)
+
+		_, err = unary(auth, `/test.Service/Method`)
+		require.NoError(t, err, `call without token should succeed`)
+
+		_, err = unary(auth, `/test.Service/Method`, `authorization`, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, codes.Unauthenticated, status.Code(err), `invalid tokens should be rejected`)
+	})
+	t.Run("stream", func(t *testing.T) {
+		md := metadata.Pairs(`authorization`, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		ss := &serverStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+		info := &grpc.StreamServerInfo{FullMethod: `/test.Service/Stream`}
+
+		var subject string
+		err := auth.StreamServerInterceptor()(nil, ss, info, func(_ interface{}, ss grpc.ServerStream) error {
+			tok, ok := jwtgrpc.FromContext(ss.Context())
+			require.True(t, ok, `token should be available`)
+			subject = tok.Subject()
+			return nil
+		})
+		require.NoError(t, err, `call should succeed`)
+		require.Equal(t, `alice`, subject)
+
+		ss = &serverStream{ctx: context.Background()}
+		err = auth.StreamServerInterceptor()(nil, ss, info, func(interface{}, grpc.ServerStream) error {
+			return nil
+		})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jwtgrpc.New(jwt.WithVerify(false))
+		require.Error(t, err, `jwt.WithVerify should be rejected`)
+		_, err = jwtgrpc.New(jwt.WithValidate(false))
+		require.Error(t, err, `jwt.WithValidate should be rejected`)
+	})
+}
+
+func TestCredentials(t *testing.T) {
+	creds := jwtgrpc.NewCredentials(jwtgrpc.StaticToken([]byte(`xxx.yyy.zzz`)))
+	require.True(t, creds.RequireTransportSecurity(), `transport security should be required by default`)
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err, `GetRequestMetadata should succeed`)
+	require.Equal(t, map[string]string{`authorization`: `Bearer xxx.yyy.zzz`}, md)
+
+	require.False(t, creds.AllowInsecure(true).RequireTransportSecurity())
+}
@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `New()`. Options
+// defined in the `jwt` package that implement `jwt.ParseOption` are
+// also accepted.
+type Option = option.Interface
+
+type identOptional struct{}
+type identSkipMethods struct{}
+
+// WithOptional specifies whether calls without a token are passed to
+// the handler. By default such calls are rejected. Calls with an invalid
+// token are always rejected.
+func WithOptional(v bool) Option {
+	return option.New(identOptional{}, v)
+}
+
+// WithSkipMethods specifies the full names of the methods (e.g.
+// "/grpc.health.v1.Health/Check") for which authentication is not
+// performed at all. This option may be specified multiple times.
+func WithSkipMethods(v ...string) Option {
+	return option.New(identSkipMethods{}, v)
+}
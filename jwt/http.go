@@ -23,8 +23,26 @@ func ParseHeader(hdr http.Header, name string, options ...ParseOption) (Token, e
 
 	if key == "Authorization" {
 		// Authorization header is an exception. We strip the "Bearer " from
-		// the prefix
-		v = strings.TrimSpace(strings.TrimPrefix(v, "Bearer"))
+		// the prefix. The scheme is case-insensitive (RFC 7235)
+		if len(v) > len(bearerScheme) && strings.EqualFold(v[:len(bearerScheme)], bearerScheme) {
+			v = strings.TrimSpace(v[len(bearerScheme):])
+		}
+	}
+
+	return ParseString(v, options...)
+}
+
+const bearerScheme = `Bearer `
+
+// ParseCookie parses a JWT stored in a http.Cookie.
+func ParseCookie(c *http.Cookie, options ...ParseOption) (Token, error) {
+	if c == nil {
+		return nil, fmt.Errorf(`nil cookie`)
+	}
+
+	v := strings.TrimSpace(c.Value)
+	if v == "" {
+		return nil, fmt.Errorf(`empty cookie (%s)`, c.Name)
 	}
 
 	return ParseString(v, options...)
@@ -43,8 +61,10 @@ func ParseForm(values url.Values, name string, options ...ParseOption) (Token, e
 // ParseRequest searches a http.Request object for a JWT token.
 //
 // Specifying WithHeaderKey() will tell it to search under a specific
-// header key. Specifying WithFormKey() will tell it to search under
-// a specific form field.
+// header key. Specifying WithCookieKey() will tell it to search under
+// a specific cookie. Specifying WithFormKey() will tell it to search under
+// a specific form field. Headers are searched first, then cookies, and
+// then form fields, each in the order that they were specified.
 //
 // By default, "Authorization" header will be searched.
 //
@@ -58,8 +78,12 @@ func ParseForm(values url.Values, name string, options ...ParseOption) (Token, e
 //
 //	# searches for "Authorization" AND "x-my-token"
 //	jwt.ParseRequest(req, jwt.WithHeaderKey("Authorization"), jwt.WithHeaderKey("x-my-token"))
+//
+//	# searches for "Authorization", then the "session" cookie, then the "access_token" form field
+//	jwt.ParseRequest(req, jwt.WithCookieKey("session"), jwt.WithFormKey("access_token"))
 func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 	var hdrkeys []string
+	var cookiekeys []string
 	var formkeys []string
 	var parseOptions []ParseOption
 	for _, option := range options {
@@ -67,6 +91,8 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 		switch option.Ident() {
 		case identHeaderKey{}:
 			hdrkeys = append(hdrkeys, option.Value().(string))
+		case identCookieKey{}:
+			cookiekeys = append(cookiekeys, option.Value().(string))
 		case identFormKey{}:
 			formkeys = append(formkeys, option.Value().(string))
 		default:
@@ -79,6 +105,8 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 
 	mhdrs := pool.GetKeyToErrorMap()
 	defer pool.ReleaseKeyToErrorMap(mhdrs)
+	mcookies := pool.GetKeyToErrorMap()
+	defer pool.ReleaseKeyToErrorMap(mcookies)
 	mfrms := pool.GetKeyToErrorMap()
 	defer pool.ReleaseKeyToErrorMap(mfrms)
 
@@ -97,6 +125,21 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 		return tok, nil
 	}
 
+	for _, cookiekey := range cookiekeys {
+		cookie, err := req.Cookie(cookiekey)
+		if err != nil {
+			// if non-existent, not error
+			continue
+		}
+
+		tok, err := ParseCookie(cookie, parseOptions...)
+		if err != nil {
+			mcookies[cookiekey] = err
+			continue
+		}
+		return tok, nil
+	}
+
 	if cl := req.ContentLength; cl > 0 {
 		if err := req.ParseForm(); err != nil {
 			return nil, fmt.Errorf(`failed to parse form: %w`, err)
@@ -127,6 +170,14 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 		triedHdrs.WriteString(strconv.Quote(hdrkey))
 	}
 
+	var triedCookies strings.Builder
+	for i, cookiekey := range cookiekeys {
+		if i > 0 {
+			triedCookies.WriteString(", ")
+		}
+		triedCookies.WriteString(strconv.Quote(cookiekey))
+	}
+
 	var triedForms strings.Builder
 	for i, formkey := range formkeys {
 		if i > 0 {
@@ -139,6 +190,11 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 	b.WriteString(`failed to find a valid token in any location of the request (tried: [header keys: `)
 	b.WriteString(triedHdrs.String())
 	b.WriteByte(']')
+	if triedCookies.Len() > 0 {
+		b.WriteString(", cookie keys: [")
+		b.WriteString(triedCookies.String())
+		b.WriteByte(']')
+	}
 	if triedForms.Len() > 0 {
 		b.WriteString(", form keys: [")
 		b.WriteString(triedForms.String())
@@ -147,8 +203,9 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 	b.WriteByte(')')
 
 	lmhdrs := len(mhdrs)
+	lmcookies := len(mcookies)
 	lmfrms := len(mfrms)
-	if lmhdrs > 0 || lmfrms > 0 {
+	if lmhdrs > 0 || lmcookies > 0 || lmfrms > 0 {
 		b.WriteString(". Additionally, errors were encountered during attempts to parse")
 
 		if lmhdrs > 0 {
@@ -168,6 +225,23 @@ func ParseRequest(req *http.Request, options ...ParseOption) (Token, error) {
 			b.WriteString(")")
 		}
 
+		if lmcookies > 0 {
+			count := 0
+			b.WriteString(" cookies: (")
+			for cookiekey, err := range mcookies {
+				if count > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteString("[cookie key: ")
+				b.WriteString(strconv.Quote(cookiekey))
+				b.WriteString(", error: ")
+				b.WriteString(strconv.Quote(err.Error()))
+				b.WriteString("]")
+				count++
+			}
+			b.WriteString(")")
+		}
+
 		if lmfrms > 0 {
 			count := 0
 			b.WriteString(" forms: (")
@@ -0,0 +1,55 @@
+// Package chi adapts the JWT authentication middleware in `jwt/http` to
+// the github.com/go-chi/chi router.
+//
+// chi middlewares are plain `func(http.Handler) http.Handler` values, so
+// this package does not depend on chi itself. It exists so that chi, Echo,
+// and Gin applications are configured the same way: `chi.New()` accepts the
+// same options as `jwthttp.New()`, and requests that cannot be authenticated
+// are rejected with the same responses.
+//
+//	auth, err := jwtchi.New(
+//	  jwt.WithKeySetProvider(provider),
+//	  jwt.WithAudience(`https://api.example.com`),
+//	  jwthttp.WithRealm(`example`),
+//	)
+//	if err != nil {
+//	  ...
+//	}
+//	r := chi.NewRouter()
+//	r.Use(auth)
+//	r.Get(`/`, func(w http.ResponseWriter, r *http.Request) {
+//	  tok, _ := jwtchi.FromRequest(r)
+//	  ...
+//	})
+package chi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+)
+
+// New creates a chi middleware that authenticates requests. `options`
+// are the same as those of `jwthttp.New()`.
+func New(options ...jwthttp.Option) (func(http.Handler) http.Handler, error) {
+	m, err := jwthttp.New(options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt/http/chi.New: %w`, err)
+	}
+	return Adapt(m), nil
+}
+
+// Adapt creates a chi middleware from an existing `jwthttp.Middleware`,
+// so that the same Middleware can be shared between routers.
+func Adapt(m *jwthttp.Middleware) func(http.Handler) http.Handler {
+	return m.Wrap
+}
+
+// FromRequest returns the token that the middleware stored in the context
+// of `r`. The second return value is false if there is no token, e.g. if
+// the token is optional and the request did not contain one.
+func FromRequest(r *http.Request) (jwt.Token, bool) {
+	return jwthttp.FromContext(r.Context())
+}
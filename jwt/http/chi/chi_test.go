@@ -0,0 +1,64 @@
+package chi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+	jwtchi "github.com/lestrrat-go/jwx/v2/jwt/http/chi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Expiration(time.Now().Add(time.Hour)).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	auth, err := jwtchi.New(
+		jwt.WithKey(jwa.RS256, &key.PublicKey),
+		jwthttp.WithRealm(`example`),
+	)
+	require.NoError(t, err, `jwtchi.New should succeed`)
+
+	handler := auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok, ok := jwtchi.FromRequest(r)
+		require.True(t, ok, `jwtchi.FromRequest should succeed`)
+		_, _ = w.Write([]byte(tok.Subject()))
+	}))
+	serve := func(authz string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, `/`, nil)
+		if authz != `` {
+			req.Header.Set(`Authorization`, authz)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		w := serve(`Bearer ` + string(signed))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `alice`, w.Body.String())
+	})
+	t.Run("missing token", func(t *testing.T) {
+		w := serve(``)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jwtchi.New(jwt.WithVerify(false))
+		require.Error(t, err, `jwtchi.New should fail`)
+	})
+}
@@ -0,0 +1,68 @@
+// Package echo adapts the JWT authentication middleware in `jwt/http` to
+// the github.com/labstack/echo/v4 framework.
+//
+// This package is a separate Go module, so that users of the
+// `github.com/lestrrat-go/jwx/v2` module do not depend on Echo.
+//
+// `echo.New()` accepts the same options as `jwthttp.New()`, and requests
+// that cannot be authenticated are rejected with the same responses, which
+// are written by the `jwthttp.ErrorHandler` rather than by Echo's
+// HTTPErrorHandler. As the name of this package clashes with Echo, it is
+// usually imported using an alias such as `jwtecho`:
+//
+//	auth, err := jwtecho.New(
+//	  jwt.WithKeySetProvider(provider),
+//	  jwt.WithAudience(`https://api.example.com`),
+//	  jwthttp.WithRealm(`example`),
+//	)
+//	if err != nil {
+//	  ...
+//	}
+//	e := echo.New()
+//	e.Use(auth)
+//	e.GET(`/`, func(c echo.Context) error {
+//	  tok, _ := jwtecho.FromContext(c)
+//	  ...
+//	})
+package echo
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+)
+
+// New creates an Echo middleware that authenticates requests. `options`
+// are the same as those of `jwthttp.New()`.
+func New(options ...jwthttp.Option) (echo.MiddlewareFunc, error) {
+	m, err := jwthttp.New(options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt/http/echo.New: %w`, err)
+	}
+	return Adapt(m), nil
+}
+
+// Adapt creates an Echo middleware from an existing `jwthttp.Middleware`,
+// so that the same Middleware can be shared between routers.
+func Adapt(m *jwthttp.Middleware) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r, ok := m.Authenticate(c.Response(), c.Request())
+			if !ok {
+				// the response has already been written
+				return nil
+			}
+			c.SetRequest(r)
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns the token that the middleware stored in the request
+// of `c`. The second return value is false if there is no token, e.g. if
+// the token is optional and the request did not contain one.
+func FromContext(c echo.Context) (jwt.Token, bool) {
+	return jwthttp.FromContext(c.Request().Context())
+}
@@ -0,0 +1,75 @@
+package echo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+	jwtecho "github.com/lestrrat-go/jwx/v2/jwt/http/echo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Expiration(time.Now().Add(time.Hour)).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	auth, err := jwtecho.New(
+		jwt.WithKey(jwa.RS256, &key.PublicKey),
+		jwthttp.WithRealm(`example`),
+	)
+	require.NoError(t, err, `jwtecho.New should succeed`)
+
+	e := echo.New()
+	e.Use(auth)
+	e.GET(`/`, func(c echo.Context) error {
+		tok, ok := jwtecho.FromContext(c)
+		if !ok {
+			return c.NoContent(http.StatusNoContent)
+		}
+		return c.String(http.StatusOK, tok.Subject())
+	})
+	serve := func(authz string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, `/`, nil)
+		if authz != `` {
+			req.Header.Set(`Authorization`, authz)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		w := serve(`Bearer ` + string(signed))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `alice`, w.Body.String())
+	})
+	t.Run("missing token", func(t *testing.T) {
+		w := serve(``)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example"`, w.Header().Get(`WWW-Authenticate`))
+		require.Empty(t, w.Body.String(), `Echo's error handler should not write the response`)
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		w := serve(`Bearer invalid`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Header().Get(`WWW-Authenticate`), `error="invalid_token"`)
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jwtecho.New(jwt.WithVerify(false))
+		require.Error(t, err, `jwtecho.New should fail`)
+	})
+}
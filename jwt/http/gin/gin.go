@@ -0,0 +1,65 @@
+// Package gin adapts the JWT authentication middleware in `jwt/http` to
+// the github.com/gin-gonic/gin framework.
+//
+// This package is a separate Go module, so that users of the
+// `github.com/lestrrat-go/jwx/v2` module do not depend on Gin.
+//
+// `gin.New()` accepts the same options as `jwthttp.New()`, and requests
+// that cannot be authenticated are rejected with the same responses, after
+// which the handler chain is aborted. As the name of this package clashes
+// with Gin, it is usually imported using an alias such as `jwtgin`:
+//
+//	auth, err := jwtgin.New(
+//	  jwt.WithKeySetProvider(provider),
+//	  jwt.WithAudience(`https://api.example.com`),
+//	  jwthttp.WithRealm(`example`),
+//	)
+//	if err != nil {
+//	  ...
+//	}
+//	r := gin.New()
+//	r.Use(auth)
+//	r.GET(`/`, func(c *gin.Context) {
+//	  tok, _ := jwtgin.FromContext(c)
+//	  ...
+//	})
+package gin
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+)
+
+// New creates a Gin middleware that authenticates requests. `options`
+// are the same as those of `jwthttp.New()`.
+func New(options ...jwthttp.Option) (gin.HandlerFunc, error) {
+	m, err := jwthttp.New(options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt/http/gin.New: %w`, err)
+	}
+	return Adapt(m), nil
+}
+
+// Adapt creates a Gin middleware from an existing `jwthttp.Middleware`,
+// so that the same Middleware can be shared between routers.
+func Adapt(m *jwthttp.Middleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r, ok := m.Authenticate(c.Writer, c.Request)
+		if !ok {
+			c.Abort()
+			return
+		}
+		c.Request = r
+		c.Next()
+	}
+}
+
+// FromContext returns the token that the middleware stored in the request
+// of `c`. The second return value is false if there is no token, e.g. if
+// the token is optional and the request did not contain one.
+func FromContext(c *gin.Context) (jwt.Token, bool) {
+	return jwthttp.FromContext(c.Request.Context())
+}
@@ -0,0 +1,77 @@
+package gin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+	jwtgin "github.com/lestrrat-go/jwx/v2/jwt/http/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Expiration(time.Now().Add(time.Hour)).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	auth, err := jwtgin.New(
+		jwt.WithKey(jwa.RS256, &key.PublicKey),
+		jwthttp.WithRealm(`example`),
+	)
+	require.NoError(t, err, `jwtgin.New should succeed`)
+
+	r := gin.New()
+	r.Use(auth)
+	r.GET(`/`, func(c *gin.Context) {
+		tok, ok := jwtgin.FromContext(c)
+		if !ok {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.String(http.StatusOK, tok.Subject())
+	})
+	serve := func(authz string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, `/`, nil)
+		if authz != `` {
+			req.Header.Set(`Authorization`, authz)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		w := serve(`Bearer ` + string(signed))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `alice`, w.Body.String())
+	})
+	t.Run("missing token", func(t *testing.T) {
+		w := serve(``)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		w := serve(`Bearer invalid`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Header().Get(`WWW-Authenticate`), `error="invalid_token"`)
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jwtgin.New(jwt.WithVerify(false))
+		require.Error(t, err, `jwtgin.New should fail`)
+	})
+}
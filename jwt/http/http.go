@@ -0,0 +1,309 @@
+// Package http provides a net/http middleware that authenticates requests
+// using JWTs passed as OAuth 2.0 bearer tokens (RFC 6750).
+//
+// The middleware extracts the token from the "Authorization" header,
+// verifies and validates it using `jwt.Parse()`, and stores the resulting
+// `jwt.Token` in the request context, where it can be retrieved using
+// `FromContext()`. Requests that do not carry a valid token are rejected
+// with a "401 Unauthorized" (or "400 Bad Request") response that includes
+// a `WWW-Authenticate` header as described in RFC 6750 Section 3. Tokens
+// that do not grant the scopes specified via `jwt.WithRequiredScopes()`,
+// or the roles required by validators such as `jwt.HasRoles()`, are
+// rejected with a "403 Forbidden" response. As RFC 6750 does not define
+// an error code for missing roles, `insufficient_scope` is used in both
+// cases, and only the error description tells them apart. Error handlers
+// can use `errors.Is(err, jwt.ErrInsufficientRoles())` to distinguish them.
+//
+//	mw, err := jwthttp.New(
+//	  jwt.WithKeySetProvider(provider),
+//	  jwt.WithAudience(`https://api.example.com`),
+//	  jwthttp.WithRealm(`example`),
+//	)
+//	if err != nil {
+//	  ...
+//	}
+//	http.Handle(`/`, mw.Wrap(handler))
+//
+// Adapters for popular routers, which accept the same options and render
+// errors the same way, are provided by `jwt/http/chi`, and by the separate
+// Go modules `jwt/http/echo` and `jwt/http/gin`.
+//
+// On the client side, `Transport` attaches tokens obtained from a
+// `TokenSource` to outgoing requests, refreshing them as they expire.
+//
+// Services that issue their own tokens can publish the verification keys
+// and the OpenID Provider Configuration document using the handlers
+// created by `NewJWKSHandler()` and `NewDiscoveryHandler()`.
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// Error codes defined in RFC 6750 Section 3.1
+const (
+	ErrorInvalidRequest    = `invalid_request`
+	ErrorInvalidToken      = `invalid_token`
+	ErrorInsufficientScope = `insufficient_scope`
+)
+
+const bearerScheme = `Bearer`
+
+// Error describes a failure to authenticate a request. It is passed
+// to the `ErrorHandler` to generate the response.
+type Error struct {
+	// Status is the HTTP status code of the response
+	Status int
+	// Code is one of the error codes defined in RFC 6750 Section 3.1.
+	// It is empty if the request did not contain any token.
+	Code string
+	// Description is a human-readable description of the error that is
+	// safe to be sent to the client.
+	Description string
+	// Err is the underlying error, if any. It is not sent to the client.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf(`jwt/http: %s: %s`, e.Description, e.Err)
+	}
+	return `jwt/http: ` + e.Description
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorHandler generates the response for requests that could not be
+// authenticated. `realm` is the value specified via `WithRealm()`.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, realm string, err *Error)
+
+// Middleware authenticates HTTP requests using JWTs.
+type Middleware struct {
+	parseOptions []jwt.ParseOption
+	realm        string
+	optional     bool
+	errorHandler ErrorHandler
+}
+
+// New creates a new Middleware.
+//
+// In addition to the options defined in this package, any `jwt.ParseOption`
+// (including `jwt.ValidateOption`) may be passed. They are passed to `jwt.Parse()`
+// as is, and therefore you must specify the source of the verification keys
+// (e.g. `jwt.WithKeySet()` or `jwt.WithKeySetProvider()`). Validation is always
+// performed, and `jwt.WithVerify()` or `jwt.WithValidate()` may not be specified.
+func New(options ...Option) (*Middleware, error) {
+	m := &Middleware{
+		errorHandler: WriteError,
+	}
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, fmt.Errorf(`jwt/http.New: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identRealm{}:
+			m.realm = o.Value().(string)
+		case identOptional{}:
+			m.optional = o.Value().(bool)
+		case identErrorHandler{}:
+			h := o.Value().(ErrorHandler)
+			if h == nil {
+				return nil, fmt.Errorf(`jwt/http.New: nil ErrorHandler specified`)
+			}
+			m.errorHandler = h
+		default:
+			po, ok := o.(jwt.ParseOption)
+			if !ok {
+				return nil, fmt.Errorf(`jwt/http.New: invalid option %T`, o)
+			}
+			m.parseOptions = append(m.parseOptions, po)
+		}
+	}
+	return m, nil
+}
+
+// Wrap returns a http.Handler that authenticates the request before
+// passing it to `h`. The token is available to `h` via `FromContext()`.
+func (m *Middleware) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, ok := m.Authenticate(w, r)
+		if !ok {
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Authenticate authenticates the request `r`, and is meant for adapters
+// that integrate the Middleware with routers that do not use http.Handler.
+//
+// If the request is authenticated, it returns a request whose context
+// carries the token, and true. If the token is optional and the request
+// did not contain one, `r` is returned as is. Otherwise the error response
+// is written to `w` using the ErrorHandler, and false is returned, in which
+// case the request must not be processed any further.
+func (m *Middleware) Authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	tok, err := m.authenticate(r)
+	if err != nil {
+		m.errorHandler(w, r, m.realm, err)
+		return nil, false
+	}
+	if tok != nil {
+		r = r.WithContext(NewContext(r.Context(), tok))
+	}
+	return r, true
+}
+
+// authenticate returns a nil token and a nil error if the request
+// does not contain a token, and the token is optional
+func (m *Middleware) authenticate(r *http.Request) (jwt.Token, *Error) {
+	src, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if src == `` {
+		if m.optional {
+			return nil, nil
+		}
+		return nil, &Error{
+			Status:      http.StatusUnauthorized,
+			Description: `no access token was provided`,
+		}
+	}
+
+	options := append(m.parseOptions[:len(m.parseOptions):len(m.parseOptions)], jwt.WithContext(r.Context()))
+	tok, perr := jwt.ParseString(src, options...)
+	if perr != nil {
+		if errors.Is(perr, jwt.ErrInsufficientScope()) {
+			return nil, &Error{
+				Status:      http.StatusForbidden,
+				Code:        ErrorInsufficientScope,
+				Description: `the access token does not grant the required scopes`,
+				Err:         perr,
+			}
+		}
+		// RFC 6750 has no error code for missing roles, and roles are
+		// privileges just like scopes are
+		if errors.Is(perr, jwt.ErrInsufficientRoles()) {
+			return nil, &Error{
+				Status:      http.StatusForbidden,
+				Code:        ErrorInsufficientScope,
+				Description: `the access token does not grant the required roles`,
+				Err:         perr,
+			}
+		}
+		description := `the access token is invalid`
+		if errors.Is(perr, jwt.ErrTokenExpired()) {
+			description = `the access token has expired`
+		}
+		return nil, &Error{
+			Status:      http.StatusUnauthorized,
+			Code:        ErrorInvalidToken,
+			Description: description,
+			Err:         perr,
+		}
+	}
+	return tok, nil
+}
+
+// bearerToken extracts the token from the "Authorization" header.
+// An empty string is returned if the header does not exist, or if it
+// uses a different authentication scheme
+func bearerToken(r *http.Request) (string, *Error) {
+	values := r.Header.Values(`Authorization`)
+	if len(values) == 0 {
+		return ``, nil
+	}
+	if len(values) > 1 {
+		return ``, &Error{
+			Status:      http.StatusBadRequest,
+			Code:        ErrorInvalidRequest,
+			Description: `multiple Authorization headers were provided`,
+		}
+	}
+
+	v := strings.TrimSpace(values[0])
+	if len(v) < len(bearerScheme) || !strings.EqualFold(v[:len(bearerScheme)], bearerScheme) {
+		return ``, nil
+	}
+	rest := v[len(bearerScheme):]
+	if rest != `` && rest[0] != ' ' {
+		// e.g. "BearerXYZ"
+		return ``, nil
+	}
+
+	tok := strings.TrimSpace(rest)
+	if tok == `` || strings.ContainsAny(tok, " \t") {
+		return ``, &Error{
+			Status:      http.StatusBadRequest,
+			Code:        ErrorInvalidRequest,
+			Description: `malformed Authorization header`,
+		}
+	}
+	return tok, nil
+}
+
+// WriteError is the default ErrorHandler. It writes a response with the
+// status code specified in `err` and a `WWW-Authenticate` header
+// as described in RFC 6750 Section 3. The response body is empty.
+func WriteError(w http.ResponseWriter, _ *http.Request, realm string, err *Error) {
+	var b strings.Builder
+	b.WriteString(bearerScheme)
+	var params []string
+	if realm != `` {
+		params = append(params, `realm=`+quote(realm))
+	}
+	if err.Code != `` {
+		params = append(params, `error=`+quote(err.Code))
+		if err.Description != `` {
+			params = append(params, `error_description=`+quote(err.Description))
+		}
+	}
+	if len(params) > 0 {
+		b.WriteByte(' ')
+		b.WriteString(strings.Join(params, `, `))
+	}
+
+	w.Header().Set(`WWW-Authenticate`, b.String())
+	w.WriteHeader(err.Status)
+}
+
+// quote creates a quoted-string (RFC 7230 Section 3.2.6)
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+type tokenKey struct{}
+
+// NewContext returns a new context that carries `tok`.
+func NewContext(ctx context.Context, tok jwt.Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, tok)
+}
+
+// FromContext returns the token stored in `ctx` by the Middleware.
+// The second return value is false if there is no token, e.g. if
+// the token is optional and the request did not contain one.
+func FromContext(ctx context.Context) (jwt.Token, bool) {
+	tok, ok := ctx.Value(tokenKey{}).(jwt.Token)
+	return tok, ok
+}
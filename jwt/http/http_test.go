@@ -0,0 +1,196 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	sign := func(t *testing.T, exp time.Time) string {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Subject(`alice`).
+			Audience([]string{`api`}).
+			Expiration(exp).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return string(signed)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok, ok := jwthttp.FromContext(r.Context())
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(tok.Subject()))
+	})
+
+	mw, err := jwthttp.New(
+		jwt.WithKey(jwa.RS256, &key.PublicKey),
+		jwt.WithAudience(`api`),
+		jwthttp.WithRealm(`example`),
+	)
+	require.NoError(t, err, `jwthttp.New should succeed`)
+
+	serve := func(mw *jwthttp.Middleware, authz ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, `/`, nil)
+		for _, v := range authz {
+			req.Header.Add(`Authorization`, v)
+		}
+		w := httptest.NewRecorder()
+		mw.Wrap(handler).ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		w := serve(mw, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `alice`, w.Body.String())
+	})
+	t.Run("scheme is case-insensitive", func(t *testing.T) {
+		w := serve(mw, `bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("missing token", func(t *testing.T) {
+		w := serve(mw)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("different scheme", func(t *testing.T) {
+		w := serve(mw, `Basic YWxpY2U6c2VjcmV0`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("expired token", func(t *testing.T) {
+		w := serve(mw, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example", error="invalid_token", error_description="the access token has expired"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		other, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+		signed, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.RS256, other))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		w := serve(mw, `Bearer `+string(signed))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, `Bearer realm="example", error="invalid_token", error_description="the access token is invalid"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("insufficient scope", func(t *testing.T) {
+		mw, err := jwthttp.New(
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			jwt.WithRequiredScopes(`admin`),
+			jwthttp.WithRealm(`example`),
+		)
+		require.NoError(t, err, `jwthttp.New should succeed`)
+
+		w := serve(mw, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, `Bearer realm="example", error="insufficient_scope", error_description="the access token does not grant the required scopes"`, w.Header().Get(`WWW-Authenticate`))
+	})
+	t.Run("insufficient roles", func(t *testing.T) {
+		mw, err := jwthttp.New(
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			jwt.WithValidator(jwt.HasRoles(`admin`)),
+			jwthttp.WithRealm(`example`),
+		)
+		require.NoError(t, err, `jwthttp.New should succeed`)
+
+		w := serve(mw, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, `Bearer realm="example", error="insufficient_scope", error_description="the access token does not grant the required roles"`, w.Header().Get(`WWW-Authenticate`))
+
+		var reported *jwthttp.Error
+		mw, err = jwthttp.New(
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			jwt.WithValidator(jwt.HasRoles(`admin`)),
+			jwthttp.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, realm string, err *jwthttp.Error) {
+				reported = err
+				jwthttp.WriteError(w, r, realm, err)
+			}),
+		)
+		require.NoError(t, err, `jwthttp.New should succeed`)
+
+		w = serve(mw, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.NotNil(t, reported, `error handler should be called`)
+		require.Equal(t, jwthttp.ErrorInsufficientScope, reported.Code)
+		require.True(t, errors.Is(reported, jwt.ErrInsufficientRoles()), `error should match jwt.ErrInsufficientRoles()`)
+		require.False(t, errors.Is(reported, jwt.ErrInsufficientScope()), `error should not match jwt.ErrInsufficientScope()`)
+	})
+	t.Run("malformed request", func(t *testing.T) {
+		w := serve(mw, `Bearer `)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Header().Get(`WWW-Authenticate`), `error="invalid_request"`)
+
+		signed := sign(t, time.Now().Add(time.Hour))
+		w = serve(mw, `Bearer `+signed, `Bearer `+signed)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("optional token", func(t *testing.T) {
+		mw, err := jwthttp.New(jwt.WithKey(jwa.RS256, &key.PublicKey), jwthttp.WithOptional(true))
+		require.NoError(t, err, `jwthttp.New should succeed`)
+
+		w := serve(mw)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = serve(mw, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, http.StatusUnauthorized, w.Code, `invalid tokens should be rejected`)
+	})
+	t.Run("custom error handler", func(t *testing.T) {
+		var received *jwthttp.Error
+		mw, err := jwthttp.New(
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			jwthttp.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, _ string, err *jwthttp.Error) {
+				received = err
+				w.WriteHeader(http.StatusTeapot)
+			}),
+		)
+		require.NoError(t, err, `jwthttp.New should succeed`)
+
+		w := serve(mw, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, http.StatusTeapot, w.Code)
+		require.NotNil(t, received)
+		require.Equal(t, jwthttp.ErrorInvalidToken, received.Code)
+		require.ErrorIs(t, received, jwt.ErrTokenExpired())
+	})
+	t.Run("validation policy", func(t *testing.T) {
+		policy, err := jwt.NewValidationPolicy(jwt.WithAudience(`api`))
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+		mw, err := jwthttp.New(
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			jwt.WithValidationPolicy(policy),
+		)
+		require.NoError(t, err, `jwthttp.New should succeed`)
+
+		w := serve(mw, `Bearer `+sign(t, time.Now().Add(time.Hour)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `alice`, w.Body.String())
+
+		w = serve(mw, `Bearer `+sign(t, time.Now().Add(-time.Hour)))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jwthttp.New(jwt.WithVerify(false))
+		require.Error(t, err, `jwt.WithVerify should be rejected`)
+		_, err = jwthttp.New(jwt.WithValidate(false))
+		require.Error(t, err, `jwt.WithValidate should be rejected`)
+		_, err = jwthttp.New(jwt.WithJwsHeaders(nil))
+		require.Error(t, err, `non-parse options should be rejected`)
+	})
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `New()`,
+// `NewTransport()`, `NewJWKSHandler()`, or `NewDiscoveryHandler()`. Options defined in the `jwt` package that implement
+// `jwt.ParseOption` are also accepted by `New()`.
+type Option = option.Interface
+
+type identRealm struct{}
+type identOptional struct{}
+type identErrorHandler struct{}
+type identBase struct{}
+type identRefreshBefore struct{}
+type identTransportClock struct{}
+type identMaxAge struct{}
+
+// WithRealm specifies the value of the "realm" attribute in the
+// `WWW-Authenticate` header of error responses.
+func WithRealm(v string) Option {
+	return option.New(identRealm{}, v)
+}
+
+// WithOptional specifies whether requests without a token are
+// passed to the wrapped handler. By default such requests are rejected.
+// Requests with an invalid token are always rejected.
+func WithOptional(v bool) Option {
+	return option.New(identOptional{}, v)
+}
+
+// WithErrorHandler specifies the ErrorHandler that generates the response
+// for requests that could not be authenticated. By default `WriteError()`
+// is used.
+func WithErrorHandler(v ErrorHandler) Option {
+	return option.New(identErrorHandler{}, v)
+}
+
+// WithBase specifies the http.RoundTripper that `Transport` passes the
+// requests to. By default http.DefaultTransport is used.
+func WithBase(v http.RoundTripper) Option {
+	return option.New(identBase{}, v)
+}
+
+// WithRefreshBefore specifies how long before the expiration of a token
+// `Transport` obtains a new one. By default `DefaultRefreshBefore` is used.
+func WithRefreshBefore(v time.Duration) Option {
+	return option.New(identRefreshBefore{}, v)
+}
+
+// WithTransportClock specifies the clock that `Transport` uses to
+// determine whether a token is about to expire. By default `time.Now()`
+// is used.
+func WithTransportClock(v jwt.Clock) Option {
+	return option.New(identTransportClock{}, v)
+}
+
+// WithMaxAge specifies the max-age of the `Cache-Control` header of the
+// responses of the handlers created by `NewJWKSHandler()` and
+// `NewDiscoveryHandler()`. By default no `Cache-Control` header is sent.
+func WithMaxAge(v time.Duration) Option {
+	return option.New(identMaxAge{}, v)
+}
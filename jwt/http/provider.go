@@ -0,0 +1,268 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// DiscoveryPath is the path relative to the issuer identifier where the
+// handler created by `NewDiscoveryHandler()` is expected to be mounted
+const DiscoveryPath = jwt.DiscoveryPath
+
+// ProviderMetadata describes the OpenID Provider Configuration document
+// (OpenID Connect Discovery 1.0 Section 3) served by `NewDiscoveryHandler()`.
+// Only `Issuer` and `JWKSURI` are required, so that services that merely
+// issue tokens to other services can be described as well.
+type ProviderMetadata struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                    string   `json:"token_endpoint,omitempty"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint,omitempty"`
+	ScopesSupported                  []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported           []string `json:"response_types_supported,omitempty"`
+	GrantTypesSupported              []string `json:"grant_types_supported,omitempty"`
+	SubjectTypesSupported            []string `json:"subject_types_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	ClaimsSupported                  []string `json:"claims_supported,omitempty"`
+	// Extra contains additional members of the document. They may not
+	// override the members above.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON serializes the metadata, including the members in `Extra`.
+func (md *ProviderMetadata) MarshalJSON() ([]byte, error) {
+	type plain ProviderMetadata
+	buf, err := json.Marshal((*plain)(md))
+	if err != nil {
+		return nil, fmt.Errorf(`failed to marshal provider metadata: %w`, err)
+	}
+	if len(md.Extra) == 0 {
+		return buf, nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf(`failed to unmarshal provider metadata: %w`, err)
+	}
+	for k, v := range md.Extra {
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf(`extra member %q conflicts with a standard member`, k)
+		}
+		m[k] = v
+	}
+	return json.Marshal(m)
+}
+
+// NewJWKSHandler creates an http.Handler that serves the JWKS obtained
+// from `provider`, so that other services can verify the tokens signed by
+// this service. The `jwk.Set` is obtained for each request, and therefore
+// keys that are added to or retired from a rotating key set are reflected
+// immediately. Verifiers that cache the JWKS (e.g. `jwk.Cache`) honor the
+// max-age specified via `WithMaxAge()`.
+//
+// Only the public keys are published: private keys are converted using
+// `jwk.PublicKeyOf()`, and symmetric keys are omitted.
+func NewJWKSHandler(provider jwk.Provider, options ...Option) (http.Handler, error) {
+	if provider == nil {
+		return nil, fmt.Errorf(`jwt/http.NewJWKSHandler: jwk.Provider is required`)
+	}
+	maxAge, err := parseMaxAge(`jwt/http.NewJWKSHandler`, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+		set, err := publicSet(r, provider)
+		if err != nil {
+			http.Error(w, `failed to obtain keys`, http.StatusInternalServerError)
+			return
+		}
+		buf, err := json.Marshal(set)
+		if err != nil {
+			http.Error(w, `failed to serialize keys`, http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, r, `application/jwk-set+json`, maxAge, buf)
+	}), nil
+}
+
+// NewDiscoveryHandler creates an http.Handler that serves `md` as the
+// OpenID Provider Configuration document. It is meant to be mounted at
+// `DiscoveryPath` under the issuer identifier, and is typically used
+// together with a handler created by `NewJWKSHandler()` mounted at the
+// URL in `md.JWKSURI`:
+//
+//	mux.Handle(jwthttp.DiscoveryPath, discovery)
+//	mux.Handle(`/jwks.json`, jwks)
+//
+// If `provider` is not nil and `md.IDTokenSigningAlgValuesSupported` is
+// empty, the algorithms are derived from the `alg` fields of the keys
+// obtained from `provider` for each request, so that the document does not
+// drift from the signing keys. `WithMaxAge()` may be specified as an option.
+func NewDiscoveryHandler(md *ProviderMetadata, provider jwk.Provider, options ...Option) (http.Handler, error) {
+	if md == nil {
+		return nil, fmt.Errorf(`jwt/http.NewDiscoveryHandler: ProviderMetadata is required`)
+	}
+	if md.Issuer == `` {
+		return nil, fmt.Errorf(`jwt/http.NewDiscoveryHandler: issuer is required`)
+	}
+	if md.JWKSURI == `` {
+		return nil, fmt.Errorf(`jwt/http.NewDiscoveryHandler: JWKS URI is required`)
+	}
+	maxAge, err := parseMaxAge(`jwt/http.NewDiscoveryHandler`, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// serialize the metadata once, so that later modifications by the
+	// caller (including those to its slices and to Extra) neither race
+	// with the handler nor change the document being served
+	serialized, err := json.Marshal(md)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt/http.NewDiscoveryHandler: %w`, err)
+	}
+	var members map[string]json.RawMessage
+	derive := provider != nil && len(md.IDTokenSigningAlgValuesSupported) == 0
+	if derive {
+		if err := json.Unmarshal(serialized, &members); err != nil {
+			return nil, fmt.Errorf(`jwt/http.NewDiscoveryHandler: %w`, err)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+		buf := serialized
+		if derive {
+			set, err := publicSet(r, provider)
+			if err != nil {
+				http.Error(w, `failed to obtain keys`, http.StatusInternalServerError)
+				return
+			}
+			buf, err = withSigningAlgorithms(members, signingAlgorithms(set))
+			if err != nil {
+				http.Error(w, `failed to serialize configuration`, http.StatusInternalServerError)
+				return
+			}
+		}
+		writeJSON(w, r, `application/json`, maxAge, buf)
+	}), nil
+}
+
+func parseMaxAge(method string, options []Option) (time.Duration, error) {
+	var maxAge time.Duration
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identMaxAge{}:
+			maxAge = o.Value().(time.Duration)
+			if maxAge < 0 {
+				return 0, fmt.Errorf(`%s: max-age must not be negative`, method)
+			}
+		default:
+			return 0, fmt.Errorf(`%s: invalid option %T`, method, o)
+		}
+	}
+	return maxAge, nil
+}
+
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set(`Allow`, `GET, HEAD`)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
+
+// publicSet returns the public keys in the set obtained from `provider`.
+// Symmetric keys are secrets, and are never published
+func publicSet(r *http.Request, provider jwk.Provider) (jwk.Set, error) {
+	set, err := provider.FetchKeys(r.Context(), jwk.ProviderHints{})
+	if err != nil {
+		return nil, fmt.Errorf(`failed to fetch keys: %w`, err)
+	}
+
+	public := jwk.NewSet()
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Key(i)
+		if key.KeyType() == jwa.OctetSeq {
+			continue
+		}
+		pub, err := jwk.PublicKeyOf(key)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to get public key: %w`, err)
+		}
+		if err := public.AddKey(pub); err != nil {
+			return nil, fmt.Errorf(`failed to add public key: %w`, err)
+		}
+	}
+	return public, nil
+}
+
+// withSigningAlgorithms serializes the members of the discovery document
+// along with `algs` as the value of id_token_signing_alg_values_supported.
+// `members` is not modified
+func withSigningAlgorithms(members map[string]json.RawMessage, algs []string) ([]byte, error) {
+	if len(algs) == 0 {
+		return json.Marshal(members)
+	}
+
+	buf, err := json.Marshal(algs)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]json.RawMessage, len(members)+1)
+	for k, v := range members {
+		m[k] = v
+	}
+	m[`id_token_signing_alg_values_supported`] = buf
+	return json.Marshal(m)
+}
+
+// signingAlgorithms returns the distinct algorithms of the keys in `set`
+// that may be used for signatures
+func signingAlgorithms(set jwk.Set) []string {
+	seen := make(map[string]struct{})
+	var algs []string
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Key(i)
+		if key.KeyUsage() == jwk.ForEncryption.String() {
+			continue
+		}
+		alg, ok := key.Algorithm().(jwa.SignatureAlgorithm)
+		if !ok || alg == jwa.NoSignature {
+			continue
+		}
+		if _, ok := seen[alg.String()]; ok {
+			continue
+		}
+		seen[alg.String()] = struct{}{}
+		algs = append(algs, alg.String())
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, contentType string, maxAge time.Duration, buf []byte) {
+	hdr := w.Header()
+	hdr.Set(`Content-Type`, contentType)
+	if maxAge > 0 {
+		hdr.Set(`Cache-Control`, `public, max-age=`+strconv.FormatInt(int64(maxAge/time.Second), 10))
+	}
+	http.ServeContent(w, r, ``, time.Time{}, bytes.NewReader(buf))
+}
@@ -0,0 +1,143 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderHandlers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	key, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `ec-key`), `key.Set should succeed`)
+	require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.ES256), `key.Set should succeed`)
+	secret, err := jwk.FromRaw([]byte(`very secret`))
+	require.NoError(t, err, `jwk.FromRaw should succeed`)
+	require.NoError(t, secret.Set(jwk.KeyIDKey, `hmac-key`), `secret.Set should succeed`)
+	require.NoError(t, secret.Set(jwk.AlgorithmKey, jwa.HS256), `secret.Set should succeed`)
+
+	keys := jwk.NewSet()
+	require.NoError(t, keys.AddKey(key), `keys.AddKey should succeed`)
+	require.NoError(t, keys.AddKey(secret), `keys.AddKey should succeed`)
+	provider := jwk.NewStaticProvider(keys)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+
+	jwks, err := jwthttp.NewJWKSHandler(provider, jwthttp.WithMaxAge(5*time.Minute))
+	require.NoError(t, err, `jwthttp.NewJWKSHandler should succeed`)
+	discovery, err := jwthttp.NewDiscoveryHandler(&jwthttp.ProviderMetadata{
+		Issuer:  srv.URL,
+		JWKSURI: srv.URL + `/jwks.json`,
+		Extra: map[string]interface{}{
+			`service_documentation`: `https://example.com/docs`,
+		},
+	}, provider)
+	require.NoError(t, err, `jwthttp.NewDiscoveryHandler should succeed`)
+	mux.Handle(jwthttp.DiscoveryPath, discovery)
+	mux.Handle(`/jwks.json`, jwks)
+
+	t.Run("JWKS", func(t *testing.T) {
+		res, err := srv.Client().Get(srv.URL + `/jwks.json`)
+		require.NoError(t, err, `GET should succeed`)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, `application/jwk-set+json`, res.Header.Get(`Content-Type`))
+		require.Equal(t, `public, max-age=300`, res.Header.Get(`Cache-Control`))
+
+		var set jwk.Set = jwk.NewSet()
+		require.NoError(t, json.NewDecoder(res.Body).Decode(set), `json.Decode should succeed`)
+		require.Equal(t, 1, set.Len(), `symmetric keys should not be published`)
+		pub, ok := set.LookupKeyID(`ec-key`)
+		require.True(t, ok, `set.LookupKeyID should succeed`)
+		_, isPrivate := pub.(jwk.ECDSAPrivateKey)
+		require.False(t, isPrivate, `private keys should not be published`)
+	})
+	t.Run("discovery", func(t *testing.T) {
+		res, err := srv.Client().Get(srv.URL + jwthttp.DiscoveryPath)
+		require.NoError(t, err, `GET should succeed`)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Empty(t, res.Header.Get(`Cache-Control`))
+
+		var md map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&md), `json.Decode should succeed`)
+		require.Equal(t, srv.URL, md[`issuer`])
+		require.Equal(t, srv.URL+`/jwks.json`, md[`jwks_uri`])
+		require.Equal(t, []interface{}{`ES256`}, md[`id_token_signing_alg_values_supported`])
+		require.Equal(t, `https://example.com/docs`, md[`service_documentation`])
+	})
+	t.Run("metadata is copied", func(t *testing.T) {
+		md := &jwthttp.ProviderMetadata{
+			Issuer:          srv.URL,
+			JWKSURI:         srv.URL + `/jwks.json`,
+			ScopesSupported: []string{`openid`},
+			Extra:           map[string]interface{}{`service_documentation`: `https://example.com/docs`},
+		}
+		h, err := jwthttp.NewDiscoveryHandler(md, provider)
+		require.NoError(t, err, `jwthttp.NewDiscoveryHandler should succeed`)
+
+		// modifications after the handler has been created are not served
+		md.ScopesSupported[0] = `email`
+		md.Extra[`service_documentation`] = `https://evil.example.com`
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, jwthttp.DiscoveryPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var served map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &served), `json.Unmarshal should succeed`)
+		require.Equal(t, []interface{}{`openid`}, served[`scopes_supported`])
+		require.Equal(t, `https://example.com/docs`, served[`service_documentation`])
+		require.Equal(t, []interface{}{`ES256`}, served[`id_token_signing_alg_values_supported`])
+	})
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		jwks.ServeHTTP(w, httptest.NewRequest(http.MethodPost, `/jwks.json`, nil))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+	t.Run("verify using discovery", func(t *testing.T) {
+		tok, err := jwt.NewBuilder().
+			Issuer(srv.URL).
+			Subject(`alice`).
+			Expiration(time.Now().Add(time.Hour)).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.ES256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		p, err := jwt.NewIssuerKeyProvider(jwk.NewCache(ctx), []string{srv.URL}, jwk.WithHTTPClient(srv.Client()))
+		require.NoError(t, err, `jwt.NewIssuerKeyProvider should succeed`)
+		parsed, err := jwt.Parse(signed, jwt.WithIssuerKeyProvider(p))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `alice`, parsed.Subject())
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := jwthttp.NewJWKSHandler(nil)
+		require.Error(t, err, `jwk.Provider should be required`)
+		_, err = jwthttp.NewJWKSHandler(provider, jwthttp.WithRealm(`example`))
+		require.Error(t, err, `invalid options should be rejected`)
+		_, err = jwthttp.NewDiscoveryHandler(&jwthttp.ProviderMetadata{Issuer: srv.URL}, provider)
+		require.Error(t, err, `JWKS URI should be required`)
+		_, err = jwthttp.NewDiscoveryHandler(&jwthttp.ProviderMetadata{
+			Issuer:  srv.URL,
+			JWKSURI: srv.URL + `/jwks.json`,
+			Extra:   map[string]interface{}{`issuer`: `https://evil.example.com`},
+		}, nil)
+		require.Error(t, err, `extra members should not override standard members`)
+	})
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// DefaultRefreshBefore is the default duration before the expiration of
+// a token at which `Transport` obtains a new token.
+const DefaultRefreshBefore = jwt.DefaultRefreshBefore
+
+// TokenSource provides the tokens that `Transport` attaches to requests.
+// Token is called whenever a new token is required, and may, for example,
+// sign a new token or client assertion using a private key (see
+// `jwt.NewSigningTokenSource()`), or obtain one from an authorization server.
+type TokenSource = jwt.TokenSource
+
+// TokenSourceFunc is a `TokenSource` that is implemented by a single function.
+type TokenSourceFunc = jwt.TokenSourceFunc
+
+// Transport is a http.RoundTripper that attaches a token obtained from a
+// `TokenSource` to each request as an OAuth 2.0 bearer token (RFC 6750
+// Section 2.1), and passes the request to the underlying http.RoundTripper:
+//
+//	tr, err := jwthttp.NewTransport(jwthttp.TokenSourceFunc(func(ctx context.Context) ([]byte, error) {
+//	  tok, err := jwt.NewBuilder().
+//	    Issuer(`my-service`).
+//	    Expiration(time.Now().Add(5 * time.Minute)).
+//	    Build()
+//	  ...
+//	  return jwt.Sign(tok, jwt.WithKey(jwa.ES256, key))
+//	}))
+//	...
+//	client := &http.Client{Transport: tr}
+//
+// The token is reused until shortly before it expires, as determined by
+// its (unverified) `exp` claim, using `jwt.NewReuseTokenSource()`.
+// Tokens without an `exp` claim, or that are not JWTs, are not reused. If
+// the server responds with "401 Unauthorized", the token is discarded, and
+// the request is retried once with a new token if its body can be
+// replayed.
+//
+// A Transport may be used from multiple goroutines concurrently. The
+// `TokenSource` is called from one goroutine at a time.
+type Transport struct {
+	source       TokenSource
+	base         http.RoundTripper
+	reuseOptions []jwt.TokenSourceOption
+
+	mu    sync.Mutex
+	reuse TokenSource
+}
+
+// NewTransport creates a new Transport that obtains tokens from `source`.
+// `WithBase()`, `WithRefreshBefore()`, and `WithTransportClock()` may be
+// specified as options.
+func NewTransport(source TokenSource, options ...Option) (*Transport, error) {
+	if source == nil {
+		return nil, fmt.Errorf(`jwt/http.NewTransport: TokenSource is required`)
+	}
+
+	tr := &Transport{
+		source: source,
+		base:   http.DefaultTransport,
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identBase{}:
+			if v, ok := o.Value().(http.RoundTripper); ok && v != nil {
+				tr.base = v
+			}
+		case identRefreshBefore{}:
+			tr.reuseOptions = append(tr.reuseOptions, jwt.WithRefreshBefore(o.Value().(time.Duration)))
+		case identTransportClock{}:
+			// a nil Clock is reported by jwt.NewReuseTokenSource
+			clock, _ := o.Value().(jwt.Clock)
+			tr.reuseOptions = append(tr.reuseOptions, jwt.WithTokenSourceClock(clock))
+		default:
+			return nil, fmt.Errorf(`jwt/http.NewTransport: invalid option %T`, o)
+		}
+	}
+
+	reuse, err := jwt.NewReuseTokenSource(source, tr.reuseOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt/http.NewTransport: %w`, err)
+	}
+	tr.reuse = reuse
+	return tr, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reuse, token, err := tr.current(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, fmt.Errorf(`jwt/http.Transport: failed to obtain token: %w`, err)
+	}
+
+	res, err := tr.base.RoundTrip(authorize(req, token))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	// The token may have been revoked, or the clocks may be out of sync.
+	// Discard it, and retry once if the request can be replayed
+	tr.invalidate(reuse)
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return res, nil
+	}
+
+	retry := req
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return res, nil
+		}
+		retry = req.Clone(req.Context())
+		retry.Body = body
+	}
+
+	_, token, err = tr.current(req.Context())
+	if err != nil {
+		return res, nil
+	}
+	_ = res.Body.Close()
+	return tr.base.RoundTrip(authorize(retry, token))
+}
+
+// current returns the current token, along with the `jwt.TokenSource`
+// that it was obtained from
+func (tr *Transport) current(ctx context.Context) (TokenSource, []byte, error) {
+	tr.mu.Lock()
+	reuse := tr.reuse
+	tr.mu.Unlock()
+
+	token, err := reuse.Token(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(token) == 0 {
+		return nil, nil, fmt.Errorf(`TokenSource returned an empty token`)
+	}
+	return reuse, token, nil
+}
+
+// invalidate discards the token cached by `reuse`, unless it has already
+// been replaced by another request
+func (tr *Transport) invalidate(reuse TokenSource) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.reuse != reuse {
+		return
+	}
+	// the options have been validated by NewTransport
+	if fresh, err := jwt.NewReuseTokenSource(tr.source, tr.reuseOptions...); err == nil {
+		tr.reuse = fresh
+	}
+}
+
+// authorize returns a copy of `req` with the "Authorization" header set,
+// as a http.RoundTripper must not modify the request
+func authorize(req *http.Request, token []byte) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set(`Authorization`, bearerScheme+` `+string(token))
+	return req
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
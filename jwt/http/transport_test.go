@@ -0,0 +1,123 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	jwthttp "github.com/lestrrat-go/jwx/v2/jwt/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+
+	var mu sync.Mutex
+	now := time.Now()
+	clock := jwt.ClockFunc(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	var minted int32
+	source := jwthttp.TokenSourceFunc(func(_ context.Context) ([]byte, error) {
+		n := atomic.AddInt32(&minted, 1)
+		tok, err := jwt.NewBuilder().
+			Subject(`svc`).
+			Claim(`n`, n).
+			Expiration(clock.Now().Add(5 * time.Minute)).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		return jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+	})
+
+	var reject int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authz := r.Header.Get(`Authorization`)
+		if !strings.HasPrefix(authz, `Bearer `) || atomic.AddInt32(&reject, -1) >= 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	tr, err := jwthttp.NewTransport(source, jwthttp.WithTransportClock(clock), jwthttp.WithRefreshBefore(time.Minute))
+	require.NoError(t, err, `jwthttp.NewTransport should succeed`)
+	client := &http.Client{Transport: tr}
+
+	get := func(t *testing.T, body string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		require.NoError(t, err, `http.NewRequest should succeed`)
+		res, err := client.Do(req)
+		require.NoError(t, err, `client.Do should succeed`)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		require.Empty(t, req.Header.Get(`Authorization`), `original request should not be modified`)
+		return res
+	}
+
+	t.Run("token is reused", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			res := get(t, `hello`)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			body, _ := io.ReadAll(res.Body)
+			require.Equal(t, `hello`, string(body))
+		}
+		require.Equal(t, int32(1), atomic.LoadInt32(&minted))
+	})
+	t.Run("token is refreshed before expiry", func(t *testing.T) {
+		advance(3 * time.Minute)
+		get(t, ``)
+		require.Equal(t, int32(1), atomic.LoadInt32(&minted), `token should be reused until the refresh window`)
+		advance(90 * time.Second)
+		get(t, ``)
+		require.Equal(t, int32(2), atomic.LoadInt32(&minted), `token should be refreshed within the refresh window`)
+	})
+	t.Run("retry on 401", func(t *testing.T) {
+		atomic.StoreInt32(&reject, 1)
+		res := get(t, `retried`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		require.Equal(t, `retried`, string(body), `body should be replayed`)
+		require.Equal(t, int32(3), atomic.LoadInt32(&minted), `token should be discarded`)
+
+		atomic.StoreInt32(&reject, 2)
+		res = get(t, ``)
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode, `request should only be retried once`)
+		atomic.StoreInt32(&reject, 0)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := jwthttp.NewTransport(nil)
+		require.Error(t, err, `TokenSource should be required`)
+		_, err = jwthttp.NewTransport(source, jwthttp.WithRealm(`foo`))
+		require.Error(t, err, `invalid options should be rejected`)
+		_, err = jwthttp.NewTransport(source, jwthttp.WithRefreshBefore(-time.Minute))
+		require.Error(t, err, `negative refresh durations should be rejected`)
+
+		failing, err := jwthttp.NewTransport(jwthttp.TokenSourceFunc(func(context.Context) ([]byte, error) {
+			return nil, errors.New(`boom`)
+		}))
+		require.NoError(t, err, `jwthttp.NewTransport should succeed`)
+		_, err = (&http.Client{Transport: failing}).Get(srv.URL)
+		require.Error(t, err, `requests should fail if no token can be obtained`)
+	})
+}
@@ -0,0 +1,240 @@
+// Package claims implements the mapping between JWT claims and
+// user-defined structs, for use by `(jwt.Token).Decode` and `jwt.FromStruct`,
+// as well as the copying of claim values for `(jwt.Token).Clone`
+package claims
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// fields returns the list of fields in the struct type `typ` that are
+// subject to mapping. The field names and the "omitempty" flag are taken
+// from the `json` struct tags, following the same rules as encoding/json:
+// fields tagged with "-" and unexported fields are ignored, and fields
+// of embedded structs without a tag are promoted.
+func fields(typ reflect.Type, index []int) []field {
+	var list []field
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, tagged := sf.Tag.Lookup(`json`)
+		if tag == `-` {
+			continue
+		}
+
+		parts := strings.Split(tag, `,`)
+		name := parts[0]
+		idx := append(index[:len(index):len(index)], i)
+
+		if sf.Anonymous && name == `` {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				list = append(list, fields(ft, idx)...)
+				continue
+			}
+		}
+
+		if sf.PkgPath != `` { // unexported
+			continue
+		}
+
+		if !tagged || name == `` {
+			name = sf.Name
+		}
+
+		var omitEmpty bool
+		for _, opt := range parts[1:] {
+			if opt == `omitempty` {
+				omitEmpty = true
+			}
+		}
+		list = append(list, field{name: name, index: idx, omitEmpty: omitEmpty})
+	}
+	return list
+}
+
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf(`expected a struct, got nil %T`, v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf(`expected a struct, got %T`, v)
+	}
+	return rv, nil
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but allocates nil
+// embedded struct pointers on the way. The second return value is
+// false if the field is behind a nil pointer that cannot be allocated
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !alloc || !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// Decode assigns the claims in `src` to the fields of the struct
+// pointed to by `dst`.
+//
+// Values are assigned directly if their type is assignable to the field.
+// Time values (such as "exp") can be assigned to time.Time fields, or to
+// integer and float fields, in which case they are converted to Unix time.
+// Other values are converted through their JSON representation.
+// Claims that do not have a corresponding field are ignored.
+func Decode(src map[string]interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`destination must be a non-nil pointer to a struct, got %T`, dst)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf(`destination must be a non-nil pointer to a struct, got %T`, dst)
+	}
+
+	for _, f := range fields(rv.Type(), nil) {
+		v, ok := src[f.name]
+		if !ok || v == nil {
+			continue
+		}
+
+		fv, ok := fieldByIndex(rv, f.index, true)
+		if !ok {
+			continue
+		}
+		if err := assign(fv, v); err != nil {
+			return fmt.Errorf(`failed to assign claim %q to field of type %s: %w`, f.name, fv.Type(), err)
+		}
+	}
+	return nil
+}
+
+// Convert assigns the claim value `v` to the value pointed to by `dst`,
+// using the same rules as Decode. A nil value leaves `dst` unchanged.
+func Convert(dst, v interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`destination must be a non-nil pointer, got %T`, dst)
+	}
+	if v == nil {
+		return nil
+	}
+	return assign(rv.Elem(), v)
+}
+
+func assign(fv reflect.Value, v interface{}) error {
+	ft := fv.Type()
+	vv := reflect.ValueOf(v)
+	if vv.Type().AssignableTo(ft) {
+		fv.Set(vv)
+		return nil
+	}
+
+	if t, ok := v.(time.Time); ok {
+		switch ft.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			fv.SetInt(t.Unix())
+			return nil
+		case reflect.Float32, reflect.Float64:
+			fv.SetFloat(float64(t.UnixNano()) / float64(time.Second))
+			return nil
+		}
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf(`failed to marshal value of type %T: %w`, v, err)
+	}
+	ptr := reflect.New(ft)
+	if err := json.Unmarshal(buf, ptr.Interface()); err != nil {
+		return fmt.Errorf(`failed to convert value of type %T: %w`, v, err)
+	}
+	fv.Set(ptr.Elem())
+	return nil
+}
+
+// Encode returns the fields of the struct `src` (or a pointer to it) as
+// a list of claim names and values, in field order.
+//
+// Nil pointers, interfaces, maps, and slices are omitted, as are
+// zero values of fields tagged with "omitempty". Pointers are
+// dereferenced. Zero time.Time values are always omitted, as they
+// cannot be represented as a NumericDate in a meaningful way.
+func Encode(src interface{}) ([]string, []interface{}, error) {
+	rv, err := structValue(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	var values []interface{}
+	for _, f := range fields(rv.Type(), nil) {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok {
+			continue
+		}
+
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+
+		switch fv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			if fv.IsNil() {
+				continue
+			}
+		}
+
+		if f.omitEmpty && isEmpty(fv) {
+			continue
+		}
+		if fv.Type() == timeType && fv.IsZero() {
+			continue
+		}
+
+		names = append(names, f.name)
+		values = append(values, fv.Interface())
+	}
+	return names, values, nil
+}
+
+// isEmpty is similar to the definition of "empty" used by encoding/json,
+// except that zero structs are also considered empty
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
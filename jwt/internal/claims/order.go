@@ -0,0 +1,43 @@
+package claims
+
+import (
+	"sort"
+
+	"github.com/lestrrat-go/iter/mapiter"
+)
+
+// registeredOrder is the order in which the registered claims are
+// listed in RFC 7519 Section 4.1
+var registeredOrder = map[string]int{
+	"iss": 0,
+	"sub": 1,
+	"aud": 2,
+	"exp": 3,
+	"nbf": 4,
+	"iat": 5,
+	"jti": 6,
+}
+
+// IsRegistered returns true if `name` is one of the registered claims
+// listed in RFC 7519 Section 4.1
+func IsRegistered(name string) bool {
+	_, ok := registeredOrder[name]
+	return ok
+}
+
+// SortRegisteredFirst reorders `pairs`, which must be sorted by their
+// keys, so that the registered claims come first in the order listed in
+// RFC 7519, followed by the rest of the claims in their original order.
+func SortRegisteredFirst(pairs []*mapiter.Pair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rank(pairs[i]) < rank(pairs[j])
+	})
+}
+
+func rank(pair *mapiter.Pair) int {
+	//nolint:forcetypeassert
+	if n, ok := registeredOrder[pair.Key.(string)]; ok {
+		return n
+	}
+	return len(registeredOrder)
+}
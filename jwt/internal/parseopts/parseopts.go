@@ -0,0 +1,46 @@
+// Package parseopts provides helpers for the packages that wrap
+// `jwt.Parse()`, and that always verify and validate the tokens that
+// they parse.
+package parseopts
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/option"
+)
+
+// the identities of the options that cannot be overridden
+var (
+	identVerify   = jwt.WithVerify(true).Ident()
+	identValidate = jwt.WithValidate(true).Ident()
+)
+
+// the identities of the options that control how tokens are validated
+var (
+	identContext          = jwt.WithContext(nil).Ident()
+	identValidationPolicy = jwt.WithValidationPolicy(nil).Ident()
+)
+
+// IsVerifyOrValidate returns true if `o` is either `jwt.WithVerify()`
+// or `jwt.WithValidate()`.
+func IsVerifyOrValidate(o option.Interface) bool {
+	switch o.Ident() {
+	case identVerify, identValidate:
+		return true
+	}
+	return false
+}
+
+// IsValidate returns true if `o` is `jwt.WithValidate()`.
+func IsValidate(o option.Interface) bool {
+	return o.Ident() == identValidate
+}
+
+// IsContext returns true if `o` is `jwt.WithContext()`.
+func IsContext(o option.Interface) bool {
+	return o.Ident() == identContext
+}
+
+// IsValidationPolicy returns true if `o` is `jwt.WithValidationPolicy()`.
+func IsValidationPolicy(o option.Interface) bool {
+	return o.Ident() == identValidationPolicy
+}
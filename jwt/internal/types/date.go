@@ -109,16 +109,19 @@ func parseNumericString(x string) (time.Time, error) {
 func (n *NumericDate) Accept(v interface{}) error {
 	var t time.Time
 	switch x := v.(type) {
+	// Floating point values are formatted using the shortest representation
+	// that round-trips (e.g. "1600000000.123" instead of "1600000000.122999907"),
+	// so that the fractional digits are not altered by binary rounding errors
 	case float32:
-		tv, err := parseNumericString(fmt.Sprintf(`%.9f`, x))
+		tv, err := parseNumericString(strconv.FormatFloat(float64(x), 'f', -1, 32))
 		if err != nil {
-			return fmt.Errorf(`failed to accept float32 %.9f: %w`, x, err)
+			return fmt.Errorf(`failed to accept float32 %v: %w`, x, err)
 		}
 		t = tv
 	case float64:
-		tv, err := parseNumericString(fmt.Sprintf(`%.9f`, x))
+		tv, err := parseNumericString(strconv.FormatFloat(x, 'f', -1, 64))
 		if err != nil {
-			return fmt.Errorf(`failed to accept float32 %.9f: %w`, x, err)
+			return fmt.Errorf(`failed to accept float64 %v: %w`, x, err)
 		}
 		t = tv
 	case json.Number:
@@ -173,7 +176,8 @@ func (n *NumericDate) MarshalJSON() ([]byte, error) {
 		return json.Marshal(nil)
 	}
 
-	return json.Marshal(n.String())
+	// NumericDate is a JSON number, not a string
+	return []byte(n.String()), nil
 }
 
 func (n *NumericDate) UnmarshalJSON(data []byte) error {
@@ -31,6 +31,58 @@ func TestDate(t *testing.T) {
 		}
 	})
 
+	// This test alters global behavior, and can't be ran in parallel
+	t.Run("Parse string values", func(t *testing.T) {
+		// Non-conforming issuers encode numeric dates as JSON strings,
+		// which are accepted unless the pedantic mode is enabled
+		defer jwt.Settings(jwt.WithNumericDateParsePedantic(false))
+
+		testcases := []struct {
+			Input       string
+			Expected    time.Time
+			PedanticErr bool
+		}{
+			{
+				Input:    `"1700000000"`,
+				Expected: time.Unix(1700000000, 0).UTC(),
+			},
+			{
+				Input:       `"2023-11-14T22:13:20Z"`,
+				Expected:    time.Unix(1700000000, 0).UTC(),
+				PedanticErr: true,
+			},
+			{
+				Input:       `"2023-11-15T07:13:20+09:00"`,
+				Expected:    time.Unix(1700000000, 0).UTC(),
+				PedanticErr: true,
+			},
+		}
+
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Input, func(t *testing.T) {
+				payload := []byte(`{"exp":` + tc.Input + `}`)
+
+				jwt.Settings(jwt.WithNumericDateParsePedantic(false))
+				tok, err := jwt.Parse(payload, jwt.WithVerify(false), jwt.WithValidate(false))
+				if !assert.NoError(t, err, `jwt.Parse should succeed`) {
+					return
+				}
+				if !assert.Equal(t, tc.Expected, tok.Expiration()) {
+					return
+				}
+
+				jwt.Settings(jwt.WithNumericDateParsePedantic(true))
+				_, err = jwt.Parse(payload, jwt.WithVerify(false), jwt.WithValidate(false))
+				if tc.PedanticErr {
+					assert.Error(t, err, `jwt.Parse should fail in pedantic mode`)
+				} else {
+					assert.NoError(t, err, `jwt.Parse should succeed in pedantic mode`)
+				}
+			})
+		}
+	})
+
 	// This test alters global behavior, and can't be ran in parallel
 	t.Run("Accept values", func(t *testing.T) {
 		// NumericDate allows assignment from various different Go types,
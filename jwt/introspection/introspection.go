@@ -0,0 +1,246 @@
+// Package introspection implements a client for OAuth 2.0 Token
+// Introspection (RFC 7662).
+//
+// Besides querying the introspection endpoint directly using
+// `(*Client).Introspect()`, `(*Client).Parse()` can be used to handle
+// both JWTs and opaque tokens using the same code path: JWTs are parsed
+// using `jwt.Parse()`, while other tokens are introspected and converted
+// into a `jwt.Token`.
+package introspection
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2"
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// ActiveKey is the name of the member in the introspection response
+// that indicates whether the token is active.
+const ActiveKey = `active`
+
+// Names of the members in the introspection response defined in
+// RFC 7662 Section 2.2, other than those defined for JWTs
+const (
+	ScopeKey     = `scope`
+	ClientIDKey  = `client_id`
+	UsernameKey  = `username`
+	TokenTypeKey = `token_type`
+)
+
+// maxResponseSize is the maximum size of the introspection response
+// that is read
+const maxResponseSize = 1 << 20
+
+var errInactiveToken = errors.New(`token is not active`)
+
+// ErrInactiveToken returns the opaque error value that is returned
+// when the introspection endpoint reports that the token is not active.
+// The token may have expired, been revoked, or may have never been issued
+// by the authorization server.
+func ErrInactiveToken() error {
+	return errInactiveToken
+}
+
+// HTTPClient is the interface of the HTTP client used to query
+// the introspection endpoint. *http.Client satisfies this interface.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Response represents the response from the introspection endpoint.
+type Response struct {
+	// Active reports whether the token is active.
+	Active bool
+	// Token contains the rest of the members in the response (such as
+	// "exp", "sub", and "scope") as claims. It is empty if the token is
+	// not active.
+	Token jwt.Token
+}
+
+// Client queries an introspection endpoint.
+type Client struct {
+	endpoint     string
+	httpcl       HTTPClient
+	clientID     string
+	clientSecret string
+	bearer       string
+	hint         string
+}
+
+// NewClient creates a new Client that queries the introspection
+// endpoint at `endpoint`.
+//
+// RFC 7662 requires the introspection endpoint to authenticate its
+// callers, so you will usually want to specify either
+// `WithClientCredentials()` or `WithBearerToken()`.
+func NewClient(endpoint string, options ...Option) *Client {
+	c := &Client{
+		endpoint: endpoint,
+		httpcl:   http.DefaultClient,
+	}
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identHTTPClient{}:
+			c.httpcl = option.Value().(HTTPClient)
+		case identClientCredentials{}:
+			creds := option.Value().(clientCredentials)
+			c.clientID = creds.id
+			c.clientSecret = creds.secret
+		case identBearerToken{}:
+			c.bearer = option.Value().(string)
+		case identTokenTypeHint{}:
+			c.hint = option.Value().(string)
+		}
+	}
+	return c
+}
+
+// Introspect queries the introspection endpoint about `token`.
+//
+// An error is returned only if the endpoint could not be queried, or
+// if it returned an invalid response. Inactive tokens are reported
+// via the `Active` field of the Response.
+func (c *Client) Introspect(ctx context.Context, token string) (*Response, error) {
+	form := url.Values{}
+	form.Set(`token`, token)
+	if c.hint != `` {
+		form.Set(`token_type_hint`, c.hint)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf(`introspection: failed to create request: %w`, err)
+	}
+	req.Header.Set(`Content-Type`, `application/x-www-form-urlencoded`)
+	req.Header.Set(`Accept`, `application/json`)
+	switch {
+	case c.clientID != ``:
+		// RFC 6749 Section 2.3.1 requires the credentials to be
+		// form-urlencoded before they are used for basic authentication
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+	case c.bearer != ``:
+		req.Header.Set(`Authorization`, `Bearer `+c.bearer)
+	}
+
+	res, err := c.httpcl.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(`introspection: failed to query %q: %w`, c.endpoint, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`introspection: failed to query %q: unexpected status code %d`, c.endpoint, res.StatusCode)
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf(`introspection: failed to read response: %w`, err)
+	}
+	return parseResponse(buf)
+}
+
+func parseResponse(buf []byte) (*Response, error) {
+	var active struct {
+		Active *bool `json:"active"`
+	}
+	if err := json.Unmarshal(buf, &active); err != nil {
+		return nil, fmt.Errorf(`introspection: failed to parse response: %w`, err)
+	}
+	if active.Active == nil {
+		return nil, fmt.Errorf(`introspection: invalid response: %q is missing`, ActiveKey)
+	}
+
+	tok := jwt.New()
+	if !*active.Active {
+		// RFC 7662 Section 2.2: the endpoint should not include any
+		// other information about inactive tokens
+		return &Response{Token: tok}, nil
+	}
+
+	if err := json.Unmarshal(buf, tok); err != nil {
+		return nil, fmt.Errorf(`introspection: failed to parse response: %w`, err)
+	}
+	if err := tok.Remove(ActiveKey); err != nil {
+		return nil, fmt.Errorf(`introspection: failed to remove %q: %w`, ActiveKey, err)
+	}
+	return &Response{Active: true, Token: tok}, nil
+}
+
+// Parse returns the token represented by `src`.
+//
+// If `src` is a JWT in compact serialization (i.e. JWS or JWE), it is
+// parsed by `jwt.Parse()` using `options`. Otherwise `src` is treated as
+// an opaque token, and is introspected. If the token is active, the
+// claims in the introspection response are validated using the
+// `jwt.ValidateOption`s in `options` (or the policy specified via
+// `jwt.WithValidationPolicy()`), and returned as a `jwt.Token`.
+// If the token is not active, an error that matches `ErrInactiveToken()`
+// is returned.
+//
+// Note that the introspection response is trusted as is, and therefore
+// the endpoint must be accessed over a secure channel.
+func (c *Client) Parse(ctx context.Context, src []byte, options ...jwt.ParseOption) (jwt.Token, error) {
+	src = bytes.TrimSpace(src)
+	switch jwx.GuessFormat(src) {
+	case jwx.JWS, jwx.JWE:
+		return jwt.Parse(src, options...)
+	}
+
+	res, err := c.Introspect(ctx, string(src))
+	if err != nil {
+		return nil, err
+	}
+	if !res.Active {
+		return nil, errInactiveToken
+	}
+
+	validateOptions := []jwt.ValidateOption{jwt.WithContext(ctx)}
+	var policy *jwt.ValidationPolicy
+	var hasRules bool
+	validate := true
+	for _, option := range options {
+		if vo, ok := option.(jwt.ValidateOption); ok {
+			validateOptions = append(validateOptions, vo)
+			if !parseopts.IsContext(option) {
+				hasRules = true
+			}
+			continue
+		}
+		//nolint:forcetypeassert
+		switch {
+		case parseopts.IsValidate(option):
+			validate = option.Value().(bool)
+		case parseopts.IsValidationPolicy(option):
+			policy = option.Value().(*jwt.ValidationPolicy)
+		}
+	}
+	if !validate {
+		return res.Token, nil
+	}
+
+	if policy != nil {
+		// same as jwt.Parse()
+		if hasRules {
+			return nil, fmt.Errorf(`introspection: jwt.WithValidationPolicy() may not be combined with validation options`)
+		}
+		if err := policy.Validate(res.Token); err != nil {
+			return nil, err
+		}
+		return res.Token, nil
+	}
+	if err := jwt.Validate(res.Token, validateOptions...); err != nil {
+		return nil, err
+	}
+	return res.Token, nil
+}
@@ -0,0 +1,115 @@
+package introspection_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/introspection"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != `client` || secret != `s3cr3t` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set(`Content-Type`, `application/json`)
+		switch r.PostFormValue(`token`) {
+		case `active-token`:
+			fmt.Fprintf(w, `{"active":true,"sub":"alice","aud":"api","scope":"read write","client_id":"app","exp":%d}`, exp)
+		case `expired-token`:
+			fmt.Fprintf(w, `{"active":true,"sub":"alice","exp":%d}`, time.Now().Add(-time.Hour).Unix())
+		case `bogus-response`:
+			fmt.Fprint(w, `{"sub":"alice"}`)
+		default:
+			fmt.Fprint(w, `{"active":false}`)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	cl := introspection.NewClient(srv.URL, introspection.WithClientCredentials(`client`, `s3cr3t`))
+
+	t.Run("Introspect", func(t *testing.T) {
+		res, err := cl.Introspect(ctx, `active-token`)
+		require.NoError(t, err, `Introspect should succeed`)
+		require.True(t, res.Active, `token should be active`)
+		require.Equal(t, `alice`, res.Token.Subject())
+		require.Equal(t, []string{`api`}, res.Token.Audience())
+		require.Equal(t, exp, res.Token.Expiration().Unix())
+
+		v, ok := res.Token.Get(introspection.ScopeKey)
+		require.True(t, ok, `scope should exist`)
+		require.Equal(t, `read write`, v)
+		_, ok = res.Token.Get(introspection.ActiveKey)
+		require.False(t, ok, `active should not be a claim`)
+
+		res, err = cl.Introspect(ctx, `unknown-token`)
+		require.NoError(t, err, `Introspect should succeed`)
+		require.False(t, res.Active, `token should not be active`)
+
+		_, err = cl.Introspect(ctx, `bogus-response`)
+		require.Error(t, err, `Introspect should fail for responses without "active"`)
+
+		_, err = introspection.NewClient(srv.URL).Introspect(ctx, `active-token`)
+		require.Error(t, err, `Introspect should fail without credentials`)
+	})
+	t.Run("Parse", func(t *testing.T) {
+		tok, err := cl.Parse(ctx, []byte(`active-token`), jwt.WithAudience(`api`))
+		require.NoError(t, err, `Parse should succeed`)
+		require.Equal(t, `alice`, tok.Subject())
+
+		_, err = cl.Parse(ctx, []byte(`active-token`), jwt.WithAudience(`other`))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `error should be jwt.ErrInvalidAudience`)
+
+		_, err = cl.Parse(ctx, []byte(`expired-token`))
+		require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `error should be jwt.ErrTokenExpired`)
+
+		_, err = cl.Parse(ctx, []byte(`expired-token`), jwt.WithValidate(false))
+		require.NoError(t, err, `Parse should succeed without validation`)
+
+		_, err = cl.Parse(ctx, []byte(`unknown-token`))
+		require.True(t, errors.Is(err, introspection.ErrInactiveToken()), `error should be introspection.ErrInactiveToken`)
+
+		policy, err := jwt.NewValidationPolicy(jwt.WithAudience(`other`))
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+		_, err = cl.Parse(ctx, []byte(`active-token`), jwt.WithValidationPolicy(policy))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `the policy should be applied`)
+		_, err = cl.Parse(ctx, []byte(`active-token`), jwt.WithValidationPolicy(policy), jwt.WithAudience(`api`))
+		require.Error(t, err, `jwt.WithValidationPolicy() should not be combined with validation options`)
+		_, err = cl.Parse(ctx, []byte(`active-token`), jwt.WithValidationPolicy(policy), jwt.WithValidate(false))
+		require.NoError(t, err, `the policy should be ignored without validation`)
+
+		policy, err = jwt.NewValidationPolicy(jwt.WithAudience(`api`))
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+		tok, err = cl.Parse(ctx, []byte(`active-token`), jwt.WithValidationPolicy(policy), jwt.WithContext(ctx))
+		require.NoError(t, err, `Parse should succeed`)
+		require.Equal(t, `alice`, tok.Subject())
+
+		key, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+		src := jwt.New()
+		require.NoError(t, src.Set(jwt.SubjectKey, `bob`), `src.Set should succeed`)
+		signed, err := jwt.Sign(src, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		tok, err = cl.Parse(ctx, signed, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.NoError(t, err, `Parse should succeed for JWTs`)
+		require.Equal(t, `bob`, tok.Subject())
+	})
+}
@@ -0,0 +1,43 @@
+package introspection
+
+import (
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `NewClient()`.
+type Option = option.Interface
+
+type identHTTPClient struct{}
+type identClientCredentials struct{}
+type identBearerToken struct{}
+type identTokenTypeHint struct{}
+
+type clientCredentials struct {
+	id     string
+	secret string
+}
+
+// WithHTTPClient specifies the HTTP client used to query the
+// introspection endpoint. By default http.DefaultClient is used.
+func WithHTTPClient(v HTTPClient) Option {
+	return option.New(identHTTPClient{}, v)
+}
+
+// WithClientCredentials specifies the client ID and secret that are used
+// to authenticate to the introspection endpoint via HTTP basic authentication.
+func WithClientCredentials(id, secret string) Option {
+	return option.New(identClientCredentials{}, clientCredentials{id: id, secret: secret})
+}
+
+// WithBearerToken specifies the access token that is used to authenticate
+// to the introspection endpoint. It is ignored if `WithClientCredentials()`
+// is also specified.
+func WithBearerToken(v string) Option {
+	return option.New(identBearerToken{}, v)
+}
+
+// WithTokenTypeHint specifies the value of the "token_type_hint" parameter
+// (e.g. "access_token") sent to the introspection endpoint.
+func WithTokenTypeHint(v string) Option {
+	return option.New(identTokenTypeHint{}, v)
+}
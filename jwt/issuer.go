@@ -0,0 +1,189 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+// DiscoveryPath is the path relative to the issuer identifier where
+// the OpenID Provider Configuration document is published
+// (OpenID Connect Discovery 1.0 Section 4)
+const DiscoveryPath = `/.well-known/openid-configuration`
+
+var identHTTPClient = jwk.WithHTTPClient(nil).Ident()
+
+// IssuerKeyProvider is a `jws.KeyProvider` that verifies tokens issued by
+// any of a fixed set of issuers. The `iss` claim of the token determines
+// the issuer, whose JWKS URL is obtained through OpenID Connect Discovery.
+// The JWKS itself is fetched and refreshed using a `jwk.Cache`.
+//
+// The results of the discovery are cached for the lifetime of the
+// IssuerKeyProvider, so it should be created once and reused. Use it
+// via `jwt.WithIssuerKeyProvider()`.
+type IssuerKeyProvider struct {
+	cache           *jwk.Cache
+	issuers         map[string]struct{}
+	client          jwk.HTTPClient
+	registerOptions []jwk.RegisterOption
+	keySetOptions   []jws.WithKeySetSuboption
+
+	mu       sync.RWMutex
+	jwksURIs map[string]string
+}
+
+// NewIssuerKeyProvider creates a new IssuerKeyProvider that accepts tokens
+// issued by `issuers`. Tokens whose `iss` claim is not one of `issuers`
+// are rejected before any network access takes place.
+//
+// `options` may contain `jws.WithKeySetSuboption`s, which control how keys
+// in the JWKS are matched against the token (see `jwt.WithKeySet()`), and
+// `jwk.RegisterOption`s, which are used when registering the JWKS URLs to
+// `cache`. The HTTP client specified via `jwk.WithHTTPClient()` is also
+// used to fetch the OpenID Provider Configuration documents.
+func NewIssuerKeyProvider(cache *jwk.Cache, issuers []string, options ...interface{}) (*IssuerKeyProvider, error) {
+	if cache == nil {
+		return nil, fmt.Errorf(`jwt.NewIssuerKeyProvider: jwk.Cache is required`)
+	}
+	if len(issuers) == 0 {
+		return nil, fmt.Errorf(`jwt.NewIssuerKeyProvider: at least one issuer must be specified`)
+	}
+
+	p := &IssuerKeyProvider{
+		cache:    cache,
+		issuers:  make(map[string]struct{}, len(issuers)),
+		client:   http.DefaultClient,
+		jwksURIs: make(map[string]string),
+	}
+	for _, iss := range issuers {
+		p.issuers[iss] = struct{}{}
+	}
+	for _, option := range options {
+		switch option := option.(type) {
+		case jws.WithKeySetSuboption:
+			p.keySetOptions = append(p.keySetOptions, option)
+		case jwk.RegisterOption:
+			if option.Ident() == identHTTPClient {
+				//nolint:forcetypeassert
+				p.client = option.Value().(jwk.HTTPClient)
+			}
+			p.registerOptions = append(p.registerOptions, option)
+		default:
+			return nil, fmt.Errorf(`jwt.NewIssuerKeyProvider: invalid option %T`, option)
+		}
+	}
+	return p, nil
+}
+
+// FetchKeys implements `jws.KeyProvider`.
+func (p *IssuerKeyProvider) FetchKeys(ctx context.Context, sink jws.KeySink, sig *jws.Signature, msg *jws.Message) error {
+	// The claims have not been verified yet, but the issuer is only used
+	// to choose the keys. A forged `iss` claim results in a signature
+	// that cannot be verified using the keys of that issuer
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &claims); err != nil {
+		return fmt.Errorf(`failed to parse claims: %w`, err)
+	}
+	if claims.Issuer == `` {
+		return fmt.Errorf(`%q claim is required to look up keys`, IssuerKey)
+	}
+	if _, ok := p.issuers[claims.Issuer]; !ok {
+		return fmt.Errorf(`issuer %q is not allowed`, claims.Issuer)
+	}
+
+	jwksURI, err := p.jwksURI(ctx, claims.Issuer)
+	if err != nil {
+		return err
+	}
+
+	set, err := p.cache.Get(ctx, jwksURI)
+	if err != nil {
+		return fmt.Errorf(`failed to fetch JWKS for issuer %q: %w`, claims.Issuer, err)
+	}
+
+	// jws.WithKeySet() wraps the key set provider in a jws.KeyProvider
+	//nolint:forcetypeassert
+	kp := jws.WithKeySet(set, p.keySetOptions...).Value().(jws.KeyProvider)
+	return kp.FetchKeys(ctx, sink, sig, msg)
+}
+
+// jwksURI returns the JWKS URL of `issuer`, performing the discovery
+// and registering the URL to the cache if this is the first time
+func (p *IssuerKeyProvider) jwksURI(ctx context.Context, issuer string) (string, error) {
+	p.mu.RLock()
+	u, ok := p.jwksURIs[issuer]
+	p.mu.RUnlock()
+	if ok {
+		return u, nil
+	}
+
+	u, err := p.discover(ctx, issuer)
+	if err != nil {
+		return ``, fmt.Errorf(`failed to discover JWKS URL for issuer %q: %w`, issuer, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if registered, ok := p.jwksURIs[issuer]; ok {
+		// discovered concurrently
+		return registered, nil
+	}
+	if !p.cache.IsRegistered(u) {
+		if err := p.cache.Register(u, p.registerOptions...); err != nil {
+			return ``, fmt.Errorf(`failed to register JWKS URL for issuer %q: %w`, issuer, err)
+		}
+	}
+	p.jwksURIs[issuer] = u
+	return u, nil
+}
+
+func (p *IssuerKeyProvider) discover(ctx context.Context, issuer string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, `/`)+DiscoveryPath, nil)
+	if err != nil {
+		return ``, fmt.Errorf(`failed to create request: %w`, err)
+	}
+	req.Header.Set(`Accept`, `application/json`)
+
+	// jwk.HTTPClient only requires Get(), but prefer Do() when available
+	// so that the context and headers are honored
+	var res *http.Response
+	if doer, ok := p.client.(interface {
+		Do(*http.Request) (*http.Response, error)
+	}); ok {
+		res, err = doer.Do(req)
+	} else {
+		res, err = p.client.Get(req.URL.String())
+	}
+	if err != nil {
+		return ``, fmt.Errorf(`failed to fetch configuration: %w`, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ``, fmt.Errorf(`failed to fetch configuration: unexpected status %d`, res.StatusCode)
+	}
+
+	var config struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&config); err != nil {
+		return ``, fmt.Errorf(`failed to parse configuration: %w`, err)
+	}
+
+	// OpenID Connect Discovery 1.0 Section 4.3
+	if config.Issuer != issuer {
+		return ``, fmt.Errorf(`issuer in configuration (%q) does not match`, config.Issuer)
+	}
+	if config.JWKSURI == `` {
+		return ``, fmt.Errorf(`configuration does not contain "jwks_uri"`)
+	}
+	return config.JWKSURI, nil
+}
@@ -0,0 +1,124 @@
+package jwt_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuerKeyProvider(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type idp struct {
+		key jwk.Key
+		set jwk.Set
+	}
+	idps := map[string]*idp{}
+	for _, name := range []string{`idp1`, `idp2`, `rogue`} {
+		key, err := jwxtest.GenerateRsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+		require.NoError(t, key.Set(jwk.KeyIDKey, name+`-key`), `key.Set should succeed`)
+		require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+		pubkey, err := key.PublicKey()
+		require.NoError(t, err, `key.PublicKey should succeed`)
+		set := jwk.NewSet()
+		require.NoError(t, set.AddKey(pubkey), `set.AddKey should succeed`)
+		idps[name] = &idp{key: key, set: set}
+	}
+
+	var discoveries int32
+	mux := http.NewServeMux()
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	for name, v := range idps {
+		name, v := name, v
+		issuer := srv.URL + `/` + name
+		mux.HandleFunc(`/`+name+jwt.DiscoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&discoveries, 1)
+			w.Header().Set(`Content-Type`, `application/json`)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				`issuer`:   issuer,
+				`jwks_uri`: issuer + `/jwks`,
+			})
+		})
+		mux.HandleFunc(`/`+name+`/jwks`, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			_ = json.NewEncoder(w).Encode(v.set)
+		})
+	}
+	// An issuer whose configuration claims to be somebody else
+	mux.HandleFunc(`/impostor`+jwt.DiscoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			`issuer`:   srv.URL + `/idp1`,
+			`jwks_uri`: srv.URL + `/idp1/jwks`,
+		})
+	})
+
+	sign := func(t *testing.T, signer, issuer string) []byte {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Issuer(issuer).
+			Subject(`alice`).
+			Expiration(time.Now().Add(time.Hour)).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, idps[signer].key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+
+	cache := jwk.NewCache(ctx)
+	p, err := jwt.NewIssuerKeyProvider(cache,
+		[]string{srv.URL + `/idp1`, srv.URL + `/idp2`, srv.URL + `/impostor`},
+		jwk.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err, `jwt.NewIssuerKeyProvider should succeed`)
+
+	t.Run("allowed issuers", func(t *testing.T) {
+		for _, name := range []string{`idp1`, `idp2`} {
+			for i := 0; i < 2; i++ {
+				tok, err := jwt.Parse(sign(t, name, srv.URL+`/`+name), jwt.WithIssuerKeyProvider(p))
+				require.NoError(t, err, `jwt.Parse should succeed for %s`, name)
+				require.Equal(t, srv.URL+`/`+name, tok.Issuer())
+			}
+		}
+		require.Equal(t, int32(2), atomic.LoadInt32(&discoveries), `discovery should be performed once per issuer`)
+	})
+	t.Run("disallowed issuer", func(t *testing.T) {
+		_, err := jwt.Parse(sign(t, `rogue`, srv.URL+`/rogue`), jwt.WithIssuerKeyProvider(p))
+		require.Error(t, err, `jwt.Parse should fail for disallowed issuers`)
+		require.Equal(t, int32(2), atomic.LoadInt32(&discoveries), `discovery should not be performed for disallowed issuers`)
+	})
+	t.Run("forged issuer", func(t *testing.T) {
+		// Signed by idp2, but claims to be issued by idp1
+		_, err := jwt.Parse(sign(t, `idp2`, srv.URL+`/idp1`), jwt.WithIssuerKeyProvider(p))
+		require.Error(t, err, `jwt.Parse should fail for tokens signed by another issuer`)
+	})
+	t.Run("mismatching configuration", func(t *testing.T) {
+		_, err := jwt.Parse(sign(t, `idp1`, srv.URL+`/impostor`), jwt.WithIssuerKeyProvider(p))
+		require.Error(t, err, `jwt.Parse should fail when the issuer in the configuration does not match`)
+	})
+	t.Run("missing issuer", func(t *testing.T) {
+		_, err := jwt.Parse(sign(t, `idp1`, ``), jwt.WithIssuerKeyProvider(p))
+		require.Error(t, err, `jwt.Parse should fail without iss`)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := jwt.NewIssuerKeyProvider(nil, []string{srv.URL + `/idp1`})
+		require.Error(t, err, `jwk.Cache should be required`)
+		_, err = jwt.NewIssuerKeyProvider(cache, nil)
+		require.Error(t, err, `issuers should be required`)
+		_, err = jwt.NewIssuerKeyProvider(cache, []string{srv.URL + `/idp1`}, `foo`)
+		require.Error(t, err, `invalid options should be rejected`)
+	})
+}
@@ -0,0 +1,220 @@
+// Package jar implements JWT-Secured Authorization Requests (JAR, RFC 9101).
+//
+// Clients use `jar.New()` to create a Request Object from the parameters of
+// an authorization request, and `jar.Sign()` to sign (and optionally encrypt)
+// it. Authorization servers use `jar.Parse()` to verify and validate Request
+// Objects, and `jar.Params()` to obtain the authorization request parameters.
+package jar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+	"github.com/lestrrat-go/option"
+)
+
+// Type is the value of the `typ` header of Request Objects
+// (RFC 9101 Section 10.8).
+const Type = `oauth-authz-req+jwt`
+
+// Names of the authorization request parameters that have special
+// meanings for Request Objects
+const (
+	ClientIDKey   = `client_id`
+	RequestKey    = `request`
+	RequestURIKey = `request_uri`
+)
+
+// Option describes an option that can be passed to `jar.Sign()`.
+type Option = option.Interface
+
+type identEncryption struct{}
+
+type encryption struct {
+	alg jwa.KeyEncryptionAlgorithm
+	key interface{}
+}
+
+// WithEncryption specifies that the signed Request Object must be encrypted
+// for the authorization server, using `alg` and the authorization server's
+// public key (or shared secret) `key`.
+func WithEncryption(alg jwa.KeyEncryptionAlgorithm, key interface{}) Option {
+	return option.New(identEncryption{}, encryption{alg: alg, key: key})
+}
+
+// New creates a Request Object that carries the authorization request
+// parameters in `params` as claims. `iss` and `client_id` are set to
+// `clientID`, and `aud` is set to `audience`, the issuer identifier of
+// the authorization server.
+//
+// Parameters with multiple values are not supported. The `request` and
+// `request_uri` parameters are not allowed.
+func New(clientID, audience string, params url.Values) (jwt.Token, error) {
+	if clientID == `` || audience == `` {
+		return nil, fmt.Errorf(`jar.New: clientID and audience must be specified`)
+	}
+
+	b := jwt.NewBuilder().
+		Issuer(clientID).
+		Audience([]string{audience}).
+		Claim(ClientIDKey, clientID)
+	for name, values := range params {
+		switch name {
+		case RequestKey, RequestURIKey:
+			return nil, fmt.Errorf(`jar.New: parameter %q is not allowed in request objects`, name)
+		case ClientIDKey:
+			if len(values) != 1 || values[0] != clientID {
+				return nil, fmt.Errorf(`jar.New: parameter %q does not match clientID`, name)
+			}
+			continue
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf(`jar.New: parameter %q must have exactly one value`, name)
+		}
+		b.Claim(name, values[0])
+	}
+
+	tok, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf(`jar.New: %w`, err)
+	}
+	return tok, nil
+}
+
+// Sign signs the Request Object `t` using `alg` and the client's private
+// key (or shared secret) `key`, setting the `typ` header to
+// "oauth-authz-req+jwt". If `jar.WithEncryption()` is specified, the
+// signed Request Object is then encrypted (RFC 9101 Section 4).
+func Sign(t jwt.Token, alg jwa.SignatureAlgorithm, key interface{}, options ...Option) ([]byte, error) {
+	if alg == jwa.NoSignature {
+		return nil, fmt.Errorf(`jar.Sign: request objects must be signed`)
+	}
+	if v, ok := t.Get(ClientIDKey); !ok || v == `` {
+		return nil, fmt.Errorf(`jar.Sign: %q claim is required`, ClientIDKey)
+	}
+
+	var enc *encryption
+	for _, o := range options {
+		if o.Ident() == (identEncryption{}) {
+			//nolint:forcetypeassert
+			v := o.Value().(encryption)
+			enc = &v
+		}
+	}
+
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, Type); err != nil {
+		return nil, fmt.Errorf(`jar.Sign: failed to set %q header: %w`, jws.TypeKey, err)
+	}
+	signKey := jwt.WithKey(alg, key, jws.WithProtectedHeaders(hdrs))
+
+	var serialized []byte
+	var err error
+	if enc != nil {
+		serialized, err = jwt.SignAndEncrypt(t, signKey, jwt.WithKey(enc.alg, enc.key))
+	} else {
+		serialized, err = jwt.Sign(t, signKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`jar.Sign: %w`, err)
+	}
+	return serialized, nil
+}
+
+// Parse parses and validates the Request Object `src`, as described in
+// RFC 9101 Section 6.
+//
+// `clientID` is the value of the `client_id` parameter of the authorization
+// request, and `issuer` is the issuer identifier of the authorization server.
+// The Request Object must contain a `client_id` claim that matches `clientID`,
+// and an `aud` claim that contains `issuer`. If it contains an `iss` claim,
+// it must match `clientID` as well. The `request` and `request_uri` claims
+// are not allowed.
+//
+// The keys used to verify the signature (i.e. the client's keys) must be
+// specified in `options`. Encrypted Request Objects are decrypted if the
+// authorization server's key is specified via `jwt.WithKey()` using a
+// `jwa.KeyEncryptionAlgorithm`. Further `jwt.ValidateOption`s (e.g.
+// `jwt.WithRequiredClaims(jwt.ExpirationKey, jwt.NotBeforeKey)`) may be
+// passed as well, but `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func Parse(src []byte, clientID, issuer string, options ...jwt.ParseOption) (jwt.Token, error) {
+	if clientID == `` || issuer == `` {
+		return nil, fmt.Errorf(`jar.Parse: clientID and issuer must be specified`)
+	}
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, fmt.Errorf(`jar.Parse: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+	}
+
+	options = append(options[:len(options):len(options)],
+		jwt.WithRequiredClaims(ClientIDKey, jwt.AudienceKey),
+		jwt.WithClaimValue(ClientIDKey, clientID),
+		jwt.WithAudience(issuer),
+		jwt.WithValidator(jwt.ValidatorFunc(validateRequestObject)),
+	)
+	tok, err := jwt.Parse(src, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jar.Parse: %w`, err)
+	}
+	return tok, nil
+}
+
+func validateRequestObject(_ context.Context, t jwt.Token) jwt.ValidationError {
+	for _, name := range []string{RequestKey, RequestURIKey} {
+		if _, ok := t.Get(name); ok {
+			return jwt.NewValidationError(fmt.Errorf(`%q claim is not allowed in request objects`, name))
+		}
+	}
+
+	// The client_id claim has already been checked against the request
+	if iss := t.Issuer(); iss != `` {
+		if v, _ := t.Get(ClientIDKey); iss != v {
+			return jwt.ErrInvalidIssuer()
+		}
+	}
+	return nil
+}
+
+// Params returns the authorization request parameters carried by the
+// Request Object `t`. Claims that are not strings (e.g. `claims` or
+// `max_age`) are encoded as JSON. The registered JWT claims (`iss`, `aud`,
+// `exp`, etc) are not included.
+func Params(t jwt.Token) (url.Values, error) {
+	m, err := t.AsMap(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf(`jar.Params: %w`, err)
+	}
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		switch name {
+		case jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.NotBeforeKey, jwt.IssuedAtKey, jwt.JwtIDKey:
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := url.Values{}
+	for _, name := range names {
+		switch v := m[name].(type) {
+		case string:
+			params.Set(name, v)
+		default:
+			buf, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf(`jar.Params: failed to encode %q: %w`, name, err)
+			}
+			params.Set(name, string(buf))
+		}
+	}
+	return params, nil
+}
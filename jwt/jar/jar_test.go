@@ -0,0 +1,111 @@
+package jar_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/jar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJAR(t *testing.T) {
+	const clientID = `s6BhdRkqt3`
+	const issuer = `https://server.example.com`
+
+	clientKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	serverKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	params := url.Values{}
+	params.Set(`response_type`, `code`)
+	params.Set(`redirect_uri`, `https://client.example.org/cb`)
+	params.Set(`scope`, `openid`)
+	params.Set(`state`, `af0ifjsldkj`)
+
+	verifyKey := jwt.WithKey(jwa.ES256, &clientKey.PublicKey)
+
+	t.Run("signed", func(t *testing.T) {
+		tok, err := jar.New(clientID, issuer, params)
+		require.NoError(t, err, `jar.New should succeed`)
+		require.NoError(t, tok.Set(jwt.ExpirationKey, time.Now().Add(time.Minute)), `tok.Set should succeed`)
+
+		signed, err := jar.Sign(tok, jwa.ES256, clientKey)
+		require.NoError(t, err, `jar.Sign should succeed`)
+
+		msg, err := jws.Parse(signed)
+		require.NoError(t, err, `jws.Parse should succeed`)
+		require.Equal(t, jar.Type, msg.Signatures()[0].ProtectedHeaders().Type())
+
+		parsed, err := jar.Parse(signed, clientID, issuer, verifyKey, jwt.WithRequiredClaims(jwt.ExpirationKey))
+		require.NoError(t, err, `jar.Parse should succeed`)
+
+		got, err := jar.Params(parsed)
+		require.NoError(t, err, `jar.Params should succeed`)
+		expected := url.Values{}
+		for k, v := range params {
+			expected[k] = v
+		}
+		expected.Set(jar.ClientIDKey, clientID)
+		require.Equal(t, expected, got)
+
+		_, err = jar.Parse(signed, `other`, issuer, verifyKey)
+		require.Error(t, err, `jar.Parse should fail for a different client`)
+		_, err = jar.Parse(signed, clientID, `https://other.example.com`, verifyKey)
+		require.Error(t, err, `jar.Parse should fail for a different audience`)
+		_, err = jar.Parse(signed, clientID, issuer)
+		require.Error(t, err, `jar.Parse should fail without keys`)
+		_, err = jar.Parse(signed, clientID, issuer, verifyKey, jwt.WithVerify(false))
+		require.Error(t, err, `jar.Parse should reject jwt.WithVerify`)
+	})
+	t.Run("encrypted", func(t *testing.T) {
+		tok, err := jar.New(clientID, issuer, params)
+		require.NoError(t, err, `jar.New should succeed`)
+
+		encrypted, err := jar.Sign(tok, jwa.ES256, clientKey, jar.WithEncryption(jwa.RSA_OAEP, &serverKey.PublicKey))
+		require.NoError(t, err, `jar.Sign should succeed`)
+		_, err = jwe.Parse(encrypted)
+		require.NoError(t, err, `result should be a JWE message`)
+
+		parsed, err := jar.Parse(encrypted, clientID, issuer, verifyKey, jwt.WithKey(jwa.RSA_OAEP, serverKey))
+		require.NoError(t, err, `jar.Parse should succeed`)
+		v, ok := parsed.Get(`state`)
+		require.True(t, ok, `state should exist`)
+		require.Equal(t, `af0ifjsldkj`, v)
+	})
+	t.Run("invalid request objects", func(t *testing.T) {
+		_, err := jar.New(clientID, issuer, url.Values{jar.RequestURIKey: {`https://client.example.org/request`}})
+		require.Error(t, err, `jar.New should reject request_uri`)
+		_, err = jar.New(clientID, issuer, url.Values{jar.ClientIDKey: {`other`}})
+		require.Error(t, err, `jar.New should reject a mismatching client_id`)
+		_, err = jar.New(clientID, issuer, url.Values{`scope`: {`openid`, `profile`}})
+		require.Error(t, err, `jar.New should reject multiple values`)
+
+		tok, err := jar.New(clientID, issuer, params)
+		require.NoError(t, err, `jar.New should succeed`)
+		_, err = jar.Sign(tok, jwa.NoSignature, nil)
+		require.Error(t, err, `jar.Sign should reject unsigned request objects`)
+
+		// iss that does not match client_id
+		require.NoError(t, tok.Set(jwt.IssuerKey, `other`), `tok.Set should succeed`)
+		signed, err := jar.Sign(tok, jwa.ES256, clientKey)
+		require.NoError(t, err, `jar.Sign should succeed`)
+		_, err = jar.Parse(signed, clientID, issuer, verifyKey)
+		require.Error(t, err, `jar.Parse should fail for a mismatching iss`)
+
+		// nested request objects
+		tok, err = jar.New(clientID, issuer, params)
+		require.NoError(t, err, `jar.New should succeed`)
+		require.NoError(t, tok.Set(jar.RequestKey, `eyJ...`), `tok.Set should succeed`)
+		signed, err = jar.Sign(tok, jwa.ES256, clientKey)
+		require.NoError(t, err, `jar.Sign should succeed`)
+		_, err = jar.Parse(signed, clientID, issuer, verifyKey)
+		require.Error(t, err, `jar.Parse should fail for nested request objects`)
+	})
+}
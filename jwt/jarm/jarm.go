@@ -0,0 +1,237 @@
+// Package jarm implements the JWT Secured Authorization Response Mode for
+// OAuth 2.0 (JARM), in which the authorization server returns the
+// parameters of the authorization response (e.g. `code` and `state`) as
+// claims of a signed, and optionally encrypted, JWT.
+//
+// Authorization servers use `jarm.New()` and `jarm.Sign()` to create the
+// `response` parameter. Clients use `jarm.Parse()` to decrypt, verify, and
+// validate it, and `jarm.Params()` to obtain the authorization response
+// parameters:
+//
+//	tok, err := jarm.Parse([]byte(r.FormValue(jarm.ResponseKey)), clientID, issuer,
+//	  jwt.WithKeySet(serverKeys), jwt.WithKey(jwa.RSA_OAEP, clientKey))
+//	...
+//	params, err := jarm.Params(tok)
+//	...
+//	code := params.Get(`code`)
+package jarm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+	"github.com/lestrrat-go/option"
+)
+
+// ResponseKey is the name of the authorization response parameter that
+// carries the JWT.
+const ResponseKey = `response`
+
+// Values of the `response_mode` authorization request parameter that
+// request a JWT Secured Authorization Response
+const (
+	ResponseModeJWT         = `jwt`
+	ResponseModeQueryJWT    = `query.jwt`
+	ResponseModeFragmentJWT = `fragment.jwt`
+	ResponseModeFormPostJWT = `form_post.jwt`
+)
+
+// Names of the authorization response parameters
+const (
+	CodeKey             = `code`
+	StateKey            = `state`
+	ErrorKey            = `error`
+	ErrorDescriptionKey = `error_description`
+	ErrorURIKey         = `error_uri`
+)
+
+// DefaultLifetime is the default lifetime of the responses created by
+// `jarm.New()`. Responses are used only once, immediately after they are
+// created, so it is short.
+const DefaultLifetime = 10 * time.Minute
+
+// Option describes an option that can be passed to `jarm.New()` and
+// `jarm.Sign()`.
+type Option = option.Interface
+
+type identEncryption struct{}
+type identLifetime struct{}
+
+type encryption struct {
+	alg jwa.KeyEncryptionAlgorithm
+	key interface{}
+}
+
+// WithEncryption specifies that the signed response must be encrypted for
+// the client, using `alg` and the client's public key (or shared secret) `key`.
+func WithEncryption(alg jwa.KeyEncryptionAlgorithm, key interface{}) Option {
+	return option.New(identEncryption{}, encryption{alg: alg, key: key})
+}
+
+// WithLifetime specifies the lifetime of the response, i.e. the difference
+// between the `exp` and `iat` claims. The default is `DefaultLifetime`.
+func WithLifetime(v time.Duration) Option {
+	return option.New(identLifetime{}, v)
+}
+
+// New creates a response that carries the authorization response
+// parameters in `params` as claims. `iss` is set to `issuer`, the issuer
+// identifier of the authorization server, `aud` is set to `clientID`, and
+// `exp` is set according to `jarm.WithLifetime()`.
+//
+// Parameters with multiple values are not supported, and the parameters
+// may not override the registered JWT claims.
+func New(issuer, clientID string, params url.Values, options ...Option) (jwt.Token, error) {
+	if issuer == `` || clientID == `` {
+		return nil, fmt.Errorf(`jarm.New: issuer and clientID must be specified`)
+	}
+
+	lifetime := DefaultLifetime
+	for _, o := range options {
+		if o.Ident() == (identLifetime{}) {
+			//nolint:forcetypeassert
+			lifetime = o.Value().(time.Duration)
+		}
+	}
+	if lifetime <= 0 {
+		return nil, fmt.Errorf(`jarm.New: lifetime must be positive`)
+	}
+
+	now := time.Now()
+	b := jwt.NewBuilder().
+		Issuer(issuer).
+		Audience([]string{clientID}).
+		IssuedAt(now).
+		Expiration(now.Add(lifetime))
+	for name, values := range params {
+		if claims.IsRegistered(name) {
+			return nil, fmt.Errorf(`jarm.New: parameter %q is not allowed in responses`, name)
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf(`jarm.New: parameter %q must have exactly one value`, name)
+		}
+		b.Claim(name, values[0])
+	}
+
+	tok, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf(`jarm.New: %w`, err)
+	}
+	return tok, nil
+}
+
+// Sign signs the response `t` using `alg` and the authorization server's
+// private key (or shared secret) `key`. If `jarm.WithEncryption()` is
+// specified, the signed response is then encrypted.
+func Sign(t jwt.Token, alg jwa.SignatureAlgorithm, key interface{}, options ...Option) ([]byte, error) {
+	if alg == jwa.NoSignature {
+		return nil, fmt.Errorf(`jarm.Sign: responses must be signed`)
+	}
+
+	var enc *encryption
+	for _, o := range options {
+		if o.Ident() == (identEncryption{}) {
+			//nolint:forcetypeassert
+			v := o.Value().(encryption)
+			enc = &v
+		}
+	}
+
+	var serialized []byte
+	var err error
+	if enc != nil {
+		serialized, err = jwt.SignAndEncrypt(t, jwt.WithKey(alg, key), jwt.WithKey(enc.alg, enc.key))
+	} else {
+		serialized, err = jwt.Sign(t, jwt.WithKey(alg, key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`jarm.Sign: %w`, err)
+	}
+	return serialized, nil
+}
+
+// Parse parses and validates the response `src`, the value of the
+// `response` parameter.
+//
+// `clientID` is the client's identifier, and `issuer` is the issuer
+// identifier of the authorization server that the authorization request
+// was sent to. The response must contain an `iss` claim that matches
+// `issuer`, an `aud` claim that contains `clientID`, and an `exp` claim,
+// and must not have expired.
+//
+// The keys used to verify the signature (i.e. the authorization server's
+// keys) must be specified in `options`. Encrypted responses are decrypted
+// first if the client's key is specified via `jwt.WithKey()` using a
+// `jwa.KeyEncryptionAlgorithm`. Further `jwt.ValidateOption`s (e.g.
+// `jwt.WithAcceptableSkew()`) may be passed as well, but `jwt.WithVerify()`
+// and `jwt.WithValidate()` may not.
+//
+// The `state` parameter is not checked, and must be compared with the
+// value sent in the authorization request by the caller.
+func Parse(src []byte, clientID, issuer string, options ...jwt.ParseOption) (jwt.Token, error) {
+	if clientID == `` || issuer == `` {
+		return nil, fmt.Errorf(`jarm.Parse: clientID and issuer must be specified`)
+	}
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, fmt.Errorf(`jarm.Parse: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+	}
+
+	options = append(options[:len(options):len(options)],
+		jwt.WithRequiredClaims(jwt.IssuerKey, jwt.AudienceKey, jwt.ExpirationKey),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+	)
+	tok, err := jwt.Parse(src, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jarm.Parse: %w`, err)
+	}
+	return tok, nil
+}
+
+// Params returns the authorization response parameters carried by the
+// response `t`. Claims that are not strings are encoded as JSON. The
+// registered JWT claims (`iss`, `aud`, `exp`, etc) are not included.
+//
+// If the authorization request was denied, the parameters contain
+// `error` (and optionally `error_description` and `error_uri`) instead
+// of `code`.
+func Params(t jwt.Token) (url.Values, error) {
+	m, err := t.AsMap(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf(`jarm.Params: %w`, err)
+	}
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		if claims.IsRegistered(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := url.Values{}
+	for _, name := range names {
+		switch v := m[name].(type) {
+		case string:
+			params.Set(name, v)
+		default:
+			buf, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf(`jarm.Params: failed to encode %q: %w`, name, err)
+			}
+			params.Set(name, string(buf))
+		}
+	}
+	return params, nil
+}
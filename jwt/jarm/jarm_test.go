@@ -0,0 +1,122 @@
+package jarm_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/jarm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJARM(t *testing.T) {
+	const clientID = `s6BhdRkqt3`
+	const issuer = `https://accounts.example.com`
+
+	serverKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	clientKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	params := url.Values{}
+	params.Set(jarm.CodeKey, `PyyFaux2o7Q0YfXBU32jhw.5FXSQpvr8akv9CeRDSd0QA`)
+	params.Set(jarm.StateKey, `S8NJ7uqk5fY4EjNvP_G_FtyJu6pUsvH9jsYni9dMAJw`)
+
+	verifyKey := jwt.WithKey(jwa.ES256, &serverKey.PublicKey)
+
+	t.Run("signed", func(t *testing.T) {
+		tok, err := jarm.New(issuer, clientID, params)
+		require.NoError(t, err, `jarm.New should succeed`)
+		require.Equal(t, jarm.DefaultLifetime, tok.Expiration().Sub(tok.IssuedAt()))
+
+		signed, err := jarm.Sign(tok, jwa.ES256, serverKey)
+		require.NoError(t, err, `jarm.Sign should succeed`)
+
+		parsed, err := jarm.Parse(signed, clientID, issuer, verifyKey)
+		require.NoError(t, err, `jarm.Parse should succeed`)
+
+		got, err := jarm.Params(parsed)
+		require.NoError(t, err, `jarm.Params should succeed`)
+		require.Equal(t, params, got)
+
+		_, err = jarm.Parse(signed, `other`, issuer, verifyKey)
+		require.Error(t, err, `jarm.Parse should fail for a different client`)
+		_, err = jarm.Parse(signed, clientID, `https://other.example.com`, verifyKey)
+		require.Error(t, err, `jarm.Parse should fail for a different issuer`)
+		_, err = jarm.Parse(signed, clientID, issuer)
+		require.Error(t, err, `jarm.Parse should fail without keys`)
+		_, err = jarm.Parse(signed, clientID, issuer, verifyKey, jwt.WithValidate(false))
+		require.Error(t, err, `jarm.Parse should reject jwt.WithValidate`)
+	})
+	t.Run("encrypted", func(t *testing.T) {
+		tok, err := jarm.New(issuer, clientID, params)
+		require.NoError(t, err, `jarm.New should succeed`)
+
+		encrypted, err := jarm.Sign(tok, jwa.ES256, serverKey, jarm.WithEncryption(jwa.RSA_OAEP, &clientKey.PublicKey))
+		require.NoError(t, err, `jarm.Sign should succeed`)
+		_, err = jwe.Parse(encrypted)
+		require.NoError(t, err, `result should be a JWE message`)
+
+		parsed, err := jarm.Parse(encrypted, clientID, issuer, verifyKey, jwt.WithKey(jwa.RSA_OAEP, clientKey))
+		require.NoError(t, err, `jarm.Parse should succeed`)
+		got, err := jarm.Params(parsed)
+		require.NoError(t, err, `jarm.Params should succeed`)
+		require.Equal(t, params.Get(jarm.CodeKey), got.Get(jarm.CodeKey))
+
+		_, err = jarm.Parse(encrypted, clientID, issuer, verifyKey)
+		require.Error(t, err, `jarm.Parse should fail without the decryption key`)
+	})
+	t.Run("error response", func(t *testing.T) {
+		tok, err := jarm.New(issuer, clientID, url.Values{
+			jarm.ErrorKey:            {`access_denied`},
+			jarm.ErrorDescriptionKey: {`the resource owner denied the request`},
+			jarm.StateKey:            {`S8NJ7uqk5fY4EjNvP_G_FtyJu6pUsvH9jsYni9dMAJw`},
+		})
+		require.NoError(t, err, `jarm.New should succeed`)
+		signed, err := jarm.Sign(tok, jwa.ES256, serverKey)
+		require.NoError(t, err, `jarm.Sign should succeed`)
+
+		parsed, err := jarm.Parse(signed, clientID, issuer, verifyKey)
+		require.NoError(t, err, `jarm.Parse should succeed`)
+		got, err := jarm.Params(parsed)
+		require.NoError(t, err, `jarm.Params should succeed`)
+		require.Equal(t, `access_denied`, got.Get(jarm.ErrorKey))
+		require.Empty(t, got.Get(jarm.CodeKey))
+	})
+	t.Run("invalid responses", func(t *testing.T) {
+		_, err := jarm.New(issuer, clientID, url.Values{jwt.ExpirationKey: {`0`}})
+		require.Error(t, err, `jarm.New should reject registered claims`)
+		_, err = jarm.New(issuer, clientID, url.Values{jarm.StateKey: {`a`, `b`}})
+		require.Error(t, err, `jarm.New should reject multiple values`)
+		_, err = jarm.New(issuer, clientID, params, jarm.WithLifetime(0))
+		require.Error(t, err, `jarm.New should reject non-positive lifetimes`)
+
+		tok, err := jarm.New(issuer, clientID, params)
+		require.NoError(t, err, `jarm.New should succeed`)
+		_, err = jarm.Sign(tok, jwa.NoSignature, nil)
+		require.Error(t, err, `jarm.Sign should reject unsigned responses`)
+
+		// expired
+		require.NoError(t, tok.Set(jwt.ExpirationKey, time.Now().Add(-time.Minute)), `tok.Set should succeed`)
+		signed, err := jarm.Sign(tok, jwa.ES256, serverKey)
+		require.NoError(t, err, `jarm.Sign should succeed`)
+		_, err = jarm.Parse(signed, clientID, issuer, verifyKey)
+		require.Error(t, err, `jarm.Parse should fail for expired responses`)
+
+		// without exp
+		tok, err = jwt.NewBuilder().
+			Issuer(issuer).
+			Audience([]string{clientID}).
+			Claim(jarm.CodeKey, `code`).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		signed, err = jarm.Sign(tok, jwa.ES256, serverKey)
+		require.NoError(t, err, `jarm.Sign should succeed`)
+		_, err = jarm.Parse(signed, clientID, issuer, verifyKey)
+		require.Error(t, err, `jarm.Parse should require exp`)
+	})
+}
@@ -6,15 +6,21 @@ package jwt
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"sync/atomic"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2"
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
 	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwe"
 	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
 	"github.com/lestrrat-go/jwx/v2/jwt/internal/types"
+	"github.com/lestrrat-go/jwx/v2/observe"
 )
 
 var errInvalidJWT = errors.New(`invalid JWT`)
@@ -27,9 +33,25 @@ func ErrInvalidJWT() error {
 }
 
 // Settings controls global settings that are specific to JWTs.
+// Only the settings specified in `options` are changed.
+//
+// The settings apply to all tokens and all calls to `jwt.Parse()` and
+// friends, which makes it possible to configure the serialization and
+// parsing behavior once, for example at program start-up:
+//
+//	jwt.Settings(
+//	  jwt.WithFlattenAudience(true),
+//	  jwt.WithNumericDateFormatPrecision(3),
+//	  jwt.WithStrictJSON(true),
+//	)
+//
+// Some of the settings can be overridden for individual tokens
+// (see `(jwt.Token).Options()`) or calls (e.g. `jwt.WithStrictJSON()`
+// may also be passed to `jwt.Parse()`).
 func Settings(options ...GlobalOption) {
-	var flattenAudienceBool bool
-	var parsePedantic bool
+	// Settings that are not specified are left unchanged
+	var flattenAudience, parsePedantic, strictJSON *bool
+	var unredacted *[]string
 	var parsePrecision = types.MaxPrecision + 1  // illegal value, so we can detect nothing was set
 	var formatPrecision = types.MaxPrecision + 1 // illegal value, so we can detect nothing was set
 
@@ -37,9 +59,17 @@ func Settings(options ...GlobalOption) {
 	for _, option := range options {
 		switch option.Ident() {
 		case identFlattenAudience{}:
-			flattenAudienceBool = option.Value().(bool)
+			v := option.Value().(bool)
+			flattenAudience = &v
 		case identNumericDateParsePedantic{}:
-			parsePedantic = option.Value().(bool)
+			v := option.Value().(bool)
+			parsePedantic = &v
+		case identUnredactedClaims{}:
+			v := option.Value().([]string)
+			unredacted = &v
+		case identStrictJSON{}:
+			v := option.Value().(bool)
+			strictJSON = &v
 		case identNumericDateParsePrecision{}:
 			v := option.Value().(int)
 			// only accept this value if it's in our desired range
@@ -69,28 +99,44 @@ func Settings(options ...GlobalOption) {
 		}
 	}
 
-	{
+	if parsePedantic != nil {
 		v := atomic.LoadUint32(&types.Pedantic)
-		if (v == 1) != parsePedantic {
+		if (v == 1) != *parsePedantic {
 			var newVal uint32
-			if parsePedantic {
+			if *parsePedantic {
 				newVal = 1
 			}
 			atomic.CompareAndSwapUint32(&types.Pedantic, v, newVal)
 		}
 	}
 
-	{
+	if flattenAudience != nil {
 		defaultOptionsMu.Lock()
-		if flattenAudienceBool {
+		if *flattenAudience {
 			defaultOptions.Enable(FlattenAudience)
 		} else {
 			defaultOptions.Disable(FlattenAudience)
 		}
 		defaultOptionsMu.Unlock()
 	}
+
+	if unredacted != nil {
+		setUnredactedClaims(*unredacted)
+	}
+
+	if strictJSON != nil {
+		var v uint32
+		if *strictJSON {
+			v = 1
+		}
+		atomic.StoreUint32(&defaultStrictJSON, v)
+	}
 }
 
+// defaultStrictJSON is 1 if `jwt.WithStrictJSON(true)` has been passed
+// to `jwt.Settings()`
+var defaultStrictJSON uint32
+
 var registry = json.NewRegistry()
 
 // ParseString calls Parse against a string
@@ -101,13 +147,15 @@ func ParseString(s string, options ...ParseOption) (Token, error) {
 // Parse parses the JWT token payload and creates a new `jwt.Token` object.
 // The token must be encoded in either JSON format or compact format.
 //
-// This function can only work with either raw JWT (JSON) and JWS (Compact or JSON).
-// If you need JWE support on top of it, you will need to rollout your
-// own workaround.
+// This function can work with raw JWT (JSON), JWS (Compact or JSON), and
+// JWS messages enveloped in JWE (see `jwt.SignAndEncrypt()`).
 //
-// If the token is signed and you want to verify the payload matches the signature,
-// you must pass the jwt.WithKey(alg, key) or jwt.WithKeySet(jwk.Set) option.
-// If you do not specify these parameters, no verification will be performed.
+// By default the signature of the token is always verified, and you must
+// specify the source of the verification keys using options such as
+// jwt.WithKey(alg, key) or jwt.WithKeySet(jwk.Set). If you do not specify
+// any of these, an error is returned. If you need to parse a token without
+// verifying it (e.g. for debugging, or because it has already been
+// verified elsewhere), use `jwt.ParseInsecure()`.
 //
 // During verification, if the JWS headers specify a key ID (`kid`), the
 // key used for verification must match the specified ID. If you are somehow
@@ -120,6 +168,11 @@ func ParseString(s string, options ...ParseOption) (Token, error) {
 // `WithValidate(true)` option. Validate options can also be passed to
 // `Parse`
 //
+// To guard against denial of service attacks, the size of the token, the
+// size of the decoded payloads, and the depth of nesting are limited.
+// See `jwt.WithMaxTokenSize()`, `jwt.WithMaxPayloadSize()`, and
+// `jwt.WithMaxNestingDepth()` for the defaults and how to change them.
+//
 // This function takes both ParseOption and ValidateOption types:
 // ParseOptions control the parsing behavior, and ValidateOptions are
 // passed to `Validate()` when `jwt.WithValidate` is specified.
@@ -128,16 +181,26 @@ func Parse(s []byte, options ...ParseOption) (Token, error) {
 }
 
 // ParseInsecure is exactly the same as Parse(), but it disables
-// signature verification and token validation.
+// signature verification and token validation. The returned token
+// MUST NOT be trusted unless it has been verified by other means.
 //
 // You cannot override `jwt.WithVerify()` or `jwt.WithValidate()`
-// using this function. Providing these options would result in
-// an error
+// using this function, and you cannot specify the source of the
+// verification keys (e.g. `jwt.WithKeySet()`). Providing these options
+// would result in an error. Keys for key encryption algorithms may be
+// passed via `jwt.WithKey()` to decrypt JWE enveloped tokens.
 func ParseInsecure(s []byte, options ...ParseOption) (Token, error) {
 	for _, option := range options {
+		//nolint:forcetypeassert
 		switch option.Ident() {
 		case identVerify{}, identValidate{}:
 			return nil, fmt.Errorf(`jwt.ParseInsecure: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		case identKeySet{}, identKeySetProvider{}, identVerifyAuto{}, identKeyProvider{}:
+			return nil, fmt.Errorf(`jwt.ParseInsecure: options to specify verification keys may not be specified`)
+		case identKey{}:
+			if !isKeyEncryptionKey(option.Value().(*withKey)) {
+				return nil, fmt.Errorf(`jwt.ParseInsecure: options to specify verification keys may not be specified`)
+			}
 		}
 	}
 
@@ -145,8 +208,26 @@ func ParseInsecure(s []byte, options ...ParseOption) (Token, error) {
 	return Parse(s, options...)
 }
 
-// ParseReader calls Parse against an io.Reader
+// ParseReader calls Parse against an io.Reader. No more than the maximum
+// token size (see `jwt.WithMaxTokenSize()`) is read from the reader, so it
+// can be used to read tokens directly from untrusted sources such as HTTP
+// request bodies. As the raw bytes of the token are required to verify it,
+// the token is read in its entirety before it is parsed.
 func ParseReader(src io.Reader, options ...ParseOption) (Token, error) {
+	maxTokenSize := defaultMaxTokenSize
+	for _, o := range options {
+		if o.Ident() == (identMaxTokenSize{}) {
+			//nolint:forcetypeassert
+			maxTokenSize = o.Value().(int)
+		}
+	}
+
+	if maxTokenSize > 0 {
+		// Read one extra byte, so that parseBytes can report that
+		// the token is too large
+		src = io.LimitReader(src, int64(maxTokenSize)+1)
+	}
+
 	// We're going to need the raw bytes regardless. Read it.
 	data, err := io.ReadAll(src)
 	if err != nil {
@@ -155,38 +236,77 @@ func ParseReader(src io.Reader, options ...ParseOption) (Token, error) {
 	return parseBytes(data, options...)
 }
 
+const (
+	defaultMaxTokenSize    = 1 << 20
+	defaultMaxPayloadSize  = 1 << 20
+	defaultMaxNestingDepth = 2
+)
+
 type parseCtx struct {
 	token            Token
+	headers          jws.Headers
 	validateOpts     []ValidateOption
+	policy           *ValidationPolicy
+	cache            *VerificationCache
 	verifyOpts       []jws.VerifyOption
+	decryptOpts      []jwe.DecryptOption
 	localReg         *json.Registry
+	observeCtx       context.Context
+	statsCallback    func(StatsEvent)
+	degraded         bool
+	maxPayloadSize   int
+	maxNestingDepth  int
 	pedantic         bool
+	strictJSON       bool
 	skipVerification bool
 	validate         bool
+	decoded          bool
+	malformed        bool
+	contextSet       bool
 }
 
 func parseBytes(data []byte, options ...ParseOption) (Token, error) {
 	var ctx parseCtx
+	ctx.observeCtx = context.Background()
 
 	// Validation is turned on by default. You need to specify
 	// jwt.WithValidate(false) if you want to disable it
 	ctx.validate = true
 
+	ctx.strictJSON = atomic.LoadUint32(&defaultStrictJSON) == 1
+	ctx.maxPayloadSize = defaultMaxPayloadSize
+	ctx.maxNestingDepth = defaultMaxNestingDepth
+	maxTokenSize := defaultMaxTokenSize
+
 	// Verification is required (i.e., it is assumed that the incoming
 	// data is in JWS format) unless the user explicitly asks for
 	// it to be skipped.
 	verification := true
 
 	var verifyOpts []Option
+	var decryptOpts []Option
 	for _, o := range options {
 		if v, ok := o.(ValidateOption); ok {
+			if _, ok := v.Ident().(identContext); ok {
+				//nolint:forcetypeassert
+				ctx.observeCtx = v.Value().(context.Context)
+				ctx.contextSet = true
+			}
 			ctx.validateOpts = append(ctx.validateOpts, v)
 			continue
 		}
 
 		//nolint:forcetypeassert
 		switch o.Ident() {
-		case identKey{}, identKeySet{}, identVerifyAuto{}, identKeyProvider{}:
+		case identKey{}:
+			// Keys for key encryption algorithms are used to decrypt
+			// nested (JWE enveloped) tokens
+			if isKeyEncryptionKey(o.Value().(*withKey)) {
+				decryptOpts = append(decryptOpts, o)
+				continue
+			}
+			verifyOpts = append(verifyOpts, o)
+		case identKeySet{}, identKeySetProvider{}, identVerifyAuto{}, identKeyProvider{}:
 			verifyOpts = append(verifyOpts, o)
 		case identToken{}:
 			token, ok := o.Value().(Token)
@@ -196,8 +316,22 @@ func parseBytes(data []byte, options ...ParseOption) (Token, error) {
 			ctx.token = token
 		case identPedantic{}:
 			ctx.pedantic = o.Value().(bool)
+		case identStrictJSON{}:
+			ctx.strictJSON = o.Value().(bool)
+		case identValidationPolicy{}:
+			ctx.policy = o.Value().(*ValidationPolicy)
+		case identVerificationCache{}:
+			ctx.cache = o.Value().(*VerificationCache)
+		case identMaxTokenSize{}:
+			maxTokenSize = o.Value().(int)
+		case identMaxPayloadSize{}:
+			ctx.maxPayloadSize = o.Value().(int)
+		case identMaxNestingDepth{}:
+			ctx.maxNestingDepth = o.Value().(int)
 		case identValidate{}:
 			ctx.validate = o.Value().(bool)
+		case identStatsCallback{}:
+			ctx.statsCallback = o.Value().(func(StatsEvent))
 		case identVerify{}:
 			verification = o.Value().(bool)
 		case identTypedClaim{}:
@@ -209,6 +343,28 @@ func parseBytes(data []byte, options ...ParseOption) (Token, error) {
 		}
 	}
 
+	if maxTokenSize > 0 && len(data) > maxTokenSize {
+		err := fmt.Errorf(`jwt.Parse: token exceeds maximum size of %d bytes`, maxTokenSize)
+		ctx.malformed = true
+		ctx.reportStats(nil, err)
+		return nil, err
+	}
+
+	if ctx.policy != nil {
+		for _, o := range ctx.validateOpts {
+			// jwt.WithContext() is not a validation rule: it is used as
+			// the parent of the validation context of the policy
+			if _, ok := o.Ident().(identContext); ok {
+				continue
+			}
+			return nil, fmt.Errorf(`jwt.Parse: jwt.WithValidationPolicy() may not be combined with validation options`)
+		}
+	}
+
+	if ctx.maxNestingDepth < 1 {
+		return nil, fmt.Errorf(`jwt.Parse: maximum nesting depth must be at least 1 (got %d)`, ctx.maxNestingDepth)
+	}
+
 	lvo := len(verifyOpts)
 	if lvo == 0 && verification {
 		return nil, fmt.Errorf(`jwt.Parse: no keys for verification are provided (use jwt.WithVerify(false) to explicitly skip)`)
@@ -222,6 +378,17 @@ func parseBytes(data []byte, options ...ParseOption) (Token, error) {
 		ctx.verifyOpts = converted
 	}
 
+	if len(decryptOpts) > 0 {
+		converted, err := toDecryptOptions(decryptOpts...)
+		if err != nil {
+			return nil, fmt.Errorf(`jwt.Parse: failed to convert options into jwe.DecryptOption: %w`, err)
+		}
+		if ctx.maxPayloadSize > 0 {
+			converted = append(converted, jwe.WithMaxDecompressBufferSize(ctx.maxPayloadSize))
+		}
+		ctx.decryptOpts = converted
+	}
+
 	data = bytes.TrimSpace(data)
 	return parse(&ctx, data)
 }
@@ -240,22 +407,88 @@ func verifyJWS(ctx *parseCtx, payload []byte) ([]byte, int, error) {
 		return nil, _JwsVerifySkipped, nil
 	}
 
-	verified, err := jws.Verify(payload, ctx.verifyOpts...)
+	var msg jws.Message
+	verified, err := jws.Verify(payload, append(ctx.verifyOpts, jws.WithMessage(&msg), jws.WithContext(ctx.observeCtx))...)
+	if err == nil {
+		if sigs := msg.Signatures(); len(sigs) > 0 {
+			ctx.headers = sigs[0].ProtectedHeaders()
+		}
+	}
 	return verified, _JwsVerifyDone, err
 }
 
+// checkDuplicateHeaders checks the headers of the JWS or JWE message in
+// `data` for duplicate member names. In the JSON serialization, the whole
+// message is checked as well, which includes the unprotected headers
+func checkDuplicateHeaders(data []byte) error {
+	var protected []string
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.CheckDuplicateKeys(data); err != nil {
+			return fmt.Errorf(`invalid message: %w`, err)
+		}
+
+		var msg struct {
+			Protected  string `json:"protected"`
+			Signatures []struct {
+				Protected string `json:"protected"`
+			} `json:"signatures"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf(`failed to parse message: %w`, err)
+		}
+		protected = append(protected, msg.Protected)
+		for _, sig := range msg.Signatures {
+			protected = append(protected, sig.Protected)
+		}
+	} else if i := bytes.IndexByte(data, '.'); i > 0 {
+		protected = append(protected, string(data[:i]))
+	}
+
+	for _, s := range protected {
+		if s == `` {
+			continue
+		}
+		decoded, err := base64.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf(`failed to decode protected headers: %w`, err)
+		}
+		if err := json.CheckDuplicateKeys(decoded); err != nil {
+			return fmt.Errorf(`invalid protected headers: %w`, err)
+		}
+	}
+	return nil
+}
+
+// checkPayloadSize checks the size of a decoded payload against the
+// limit specified by `jwt.WithMaxPayloadSize()`
+func (ctx *parseCtx) checkPayloadSize(payload []byte) error {
+	if ctx.maxPayloadSize > 0 && len(payload) > ctx.maxPayloadSize {
+		ctx.malformed = true
+		return fmt.Errorf(`payload exceeds maximum size of %d bytes`, ctx.maxPayloadSize)
+	}
+	return nil
+}
+
+// unwrap verifies and/or decrypts the JWS and JWE messages that the
+// token is enveloped in, and returns the JSON encoded claims.
+//
 // verify parameter exists to make sure that we don't accidentally skip
 // over verification just because alg == ""  or key == nil or something.
-func parse(ctx *parseCtx, data []byte) (Token, error) {
+func unwrap(ctx *parseCtx, data []byte) ([]byte, error) {
 	payload := data
-	const maxDecodeLevels = 2
 
 	// If cty = `JWT`, we expect this to be a nested structure
 	var expectNested bool
 
 OUTER:
-	for i := 0; i < maxDecodeLevels; i++ {
-		switch kind := jwx.GuessFormat(payload); kind {
+	for i := 0; i <= ctx.maxNestingDepth; i++ {
+		kind := jwx.GuessFormat(payload)
+		if i == ctx.maxNestingDepth && (kind == jwx.JWS || kind == jwx.JWE) {
+			ctx.malformed = true
+			return nil, fmt.Errorf(`token exceeds maximum nesting depth of %d`, ctx.maxNestingDepth)
+		}
+
+		switch kind {
 		case jwx.JWT:
 			if ctx.pedantic {
 				if expectNested {
@@ -292,6 +525,13 @@ OUTER:
 			}
 			break OUTER
 		case jwx.JWS:
+			if ctx.strictJSON {
+				if err := checkDuplicateHeaders(payload); err != nil {
+					ctx.malformed = true
+					return nil, err
+				}
+			}
+
 			// Food for thought: This is going to break if you have multiple layers of
 			// JWS enveloping using different keys. It is highly unlikely use case,
 			// but it might happen.
@@ -308,6 +548,9 @@ OUTER:
 				}
 
 				if state != _JwsVerifySkipped {
+					if err := ctx.checkPayloadSize(v); err != nil {
+						return nil, err
+					}
 					payload = v
 
 					// We only check for cty and typ if the pedantic flag is enabled
@@ -326,16 +569,99 @@ OUTER:
 			}
 
 			// No verification.
-			m, err := jws.Parse(data)
+			m, err := jws.Parse(payload)
 			if err != nil {
 				return nil, fmt.Errorf(`invalid jws message: %w`, err)
 			}
+			if err := ctx.checkPayloadSize(m.Payload()); err != nil {
+				return nil, err
+			}
 			payload = m.Payload()
+		case jwx.JWE:
+			// Nested JWTs are signed, then encrypted. Only the outermost
+			// layer may be encrypted
+			if i > 0 {
+				return nil, fmt.Errorf(`unexpected encrypted payload (layer: #%d)`, i+1)
+			}
+
+			if ctx.strictJSON {
+				if err := checkDuplicateHeaders(payload); err != nil {
+					ctx.malformed = true
+					return nil, err
+				}
+			}
+
+			if len(ctx.decryptOpts) == 0 {
+				return nil, fmt.Errorf(`jwt.Parse: token is encrypted, but no keys for decryption are provided (use jwt.WithKey() with a key encryption algorithm)`)
+			}
+
+			var msg jwe.Message
+			decrypted, err := jwe.Decrypt(payload, append(ctx.decryptOpts, jwe.WithMessage(&msg), jwe.WithContext(ctx.observeCtx))...)
+			if err != nil {
+				return nil, &decryptError{fmt.Errorf(`failed to decrypt payload: %w`, err)}
+			}
+			if err := ctx.checkPayloadSize(decrypted); err != nil {
+				return nil, err
+			}
+			payload = decrypted
+
+			// The encrypted payload MUST be the signed JWT. Plain JWTs
+			// wrapped in JWE are not accepted, as the claims would not be
+			// signed by the issuer
+			if jwx.GuessFormat(payload) != jwx.JWS {
+				return nil, fmt.Errorf(`expected signed JWT inside encrypted payload`)
+			}
+
+			if ctx.pedantic {
+				if cty := msg.ProtectedHeaders().ContentType(); cty != `JWT` {
+					return nil, fmt.Errorf(`expected "cty" header of encrypted JWT to be "JWT", got %q (pedantic)`, cty)
+				}
+			}
+			continue
 		default:
 			return nil, fmt.Errorf(`unsupported format (layer: #%d)`, i+1)
 		}
 		expectNested = false
 	}
+	return payload, nil
+}
+
+func parse(ctx *parseCtx, data []byte) (_ Token, err error) {
+	observeCtx, span := observe.Start(ctx.observeCtx, observe.JWTParse)
+	defer func() { span.End(err) }()
+	ctx.observeCtx = observeCtx
+	defer func() { ctx.reportStats(data, err) }()
+
+	// Tokens that have been verified before are looked up in the cache,
+	// if one is available. Only the result of the verification is cached:
+	// the claims are decoded and validated on each call
+	var cacheKey string
+	var payload []byte
+	if ctx.cache != nil && !ctx.skipVerification && len(ctx.verifyOpts) > 0 {
+		cacheKey = verificationCacheKey(data)
+		if entry, ok := ctx.cache.get(cacheKey); ok {
+			payload = entry.payload
+			ctx.headers = entry.headers
+		}
+	}
+
+	cached := payload != nil
+	if !cached {
+		v, err := unwrap(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		payload = v
+	}
+
+	if ctx.headers != nil {
+		if alg := ctx.headers.Algorithm(); alg != "" {
+			span.SetAttribute(observe.AttrAlgorithm, alg.String())
+		}
+		if kid := ctx.headers.KeyID(); kid != "" {
+			span.SetAttribute(observe.AttrKeyID, kid)
+		}
+	}
 
 	if ctx.token == nil {
 		ctx.token = New()
@@ -351,14 +677,50 @@ OUTER:
 		defer func() { dcToken.SetDecodeCtx(nil) }()
 	}
 
+	if ctx.strictJSON {
+		if err := json.CheckDuplicateKeys(payload); err != nil {
+			ctx.malformed = true
+			return nil, fmt.Errorf(`invalid claims: %w`, err)
+		}
+	}
+
 	if err := json.Unmarshal(payload, ctx.token); err != nil {
+		ctx.malformed = true
 		return nil, fmt.Errorf(`failed to parse token: %w`, err)
 	}
+	ctx.decoded = true
+	if iss := ctx.token.Issuer(); iss != "" {
+		span.SetAttribute(observe.AttrIssuer, iss)
+	}
 
 	if ctx.validate {
-		if err := Validate(ctx.token, ctx.validateOpts...); err != nil {
+		policy := ctx.policy
+		if policy == nil {
+			v, err := NewValidationPolicy(ctx.validateOpts...)
+			if err != nil {
+				return nil, err
+			}
+			policy = v
+		}
+
+		// Make the headers of the verified JWS message available to
+		// validators via `jwt.ValidationCtxHeaders()`
+		vctx := policy.ctx
+		if ctx.policy != nil && ctx.contextSet {
+			vctx = policy.contextFrom(ctx.observeCtx)
+		}
+		if ctx.headers != nil {
+			vctx = SetValidationCtxHeaders(vctx, ctx.headers)
+		}
+		degraded, err := policy.validate(ctx.observeCtx, vctx, ctx.token)
+		if err != nil {
 			return nil, err
 		}
+		ctx.degraded = degraded
+	}
+
+	if cacheKey != "" && !cached {
+		ctx.cache.add(cacheKey, payload, ctx.headers, ctx.token.Expiration())
 	}
 	return ctx.token, nil
 }
@@ -382,7 +744,8 @@ OUTER:
 //
 // The protected header will also automatically have the `typ` field set
 // to the literal value `JWT`, unless you provide a custom value for it
-// by jwt.WithHeaders option.
+// by the `jwt.WithJwsHeaders()` option, or by the `jws.WithProtectedHeaders()`
+// suboption to `jwt.WithKey()`.
 func Sign(t Token, options ...SignOption) ([]byte, error) {
 	var soptions []jws.SignOption
 	if l := len(options); l > 0 {
@@ -399,39 +762,68 @@ func Sign(t Token, options ...SignOption) ([]byte, error) {
 		}
 		soptions = converted
 	}
-	return NewSerializer().sign(soptions...).Serialize(t)
+	return NewSerializer().Transform(transformersFrom(options)...).sign(soptions...).Serialize(t)
 }
 
-// Equal compares two JWT tokens. Do not use `reflect.Equal` or the like
-// to compare tokens as they will also compare extra detail such as
-// sync.Mutex objects used to control concurrent access.
+// SignAndEncrypt is a convenience function to create a nested JWT, that is,
+// a token that is signed and then encrypted, serialized in compact form.
+// See https://datatracker.ietf.org/doc/html/rfc7519#section-5.2
+//
+// The keys are specified using `jwt.WithKey()`: keys specified with a
+// `jwa.SignatureAlgorithm` are used to sign the token, and keys specified
+// with a `jwa.KeyEncryptionAlgorithm` are used to encrypt the signed token.
+// Both must be provided.
+//
+//	encrypted, err := jwt.SignAndEncrypt(token,
+//	   jwt.WithKey(jwa.RS256, signingKey),
+//	   jwt.WithKey(jwa.RSA_OAEP, recipientPublicKey),
+//	)
+//
+// The JWS protected header will have `typ` set to `JWT`, and the JWE protected
+// header will have `cty` set to `JWT`. The result can be parsed by `jwt.Parse()`
+// by providing both the decryption key and the verification key.
 //
-// The comparison for values is currently done using a simple equality ("=="),
-// except for time.Time, which uses time.Equal after dropping the monotonic
-// clock and truncating the values to 1 second accuracy.
+// This is equivalent to
 //
-// if both t1 and t2 are nil, returns true
-func Equal(t1, t2 Token) bool {
-	if t1 == nil && t2 == nil {
-		return true
+//	serialized, err := jwt.NewSerializer().
+//	   Sign(jwt.WithKey(jwa.RS256, signingKey)).
+//	   Encrypt(jwt.WithKey(jwa.RSA_OAEP, recipientPublicKey)).
+//	   Serialize(token)
+func SignAndEncrypt(t Token, options ...SignEncryptParseOption) ([]byte, error) {
+	var signOpts, encryptOpts []Option
+	for _, option := range options {
+		if option.Ident() != (identKey{}) {
+			continue
+		}
+		//nolint:forcetypeassert
+		if isKeyEncryptionKey(option.Value().(*withKey)) {
+			encryptOpts = append(encryptOpts, option)
+		} else {
+			signOpts = append(signOpts, option)
+		}
 	}
 
-	// we already checked for t1 == t2 == nil, so safe to do this
-	if t1 == nil || t2 == nil {
-		return false
+	if len(signOpts) == 0 {
+		return nil, fmt.Errorf(`jwt.SignAndEncrypt: no keys for signing are provided`)
+	}
+	if len(encryptOpts) == 0 {
+		return nil, fmt.Errorf(`jwt.SignAndEncrypt: no keys for encryption are provided`)
 	}
 
-	j1, err := json.Marshal(t1)
+	soptions, err := toSignOptions(signOpts...)
 	if err != nil {
-		return false
+		return nil, fmt.Errorf(`jwt.SignAndEncrypt: failed to convert options into jws.SignOption: %w`, err)
 	}
-
-	j2, err := json.Marshal(t2)
+	eoptions, err := toEncryptOptions(encryptOpts...)
 	if err != nil {
-		return false
+		return nil, fmt.Errorf(`jwt.SignAndEncrypt: failed to convert options into jwe.EncryptOption: %w`, err)
 	}
 
-	return bytes.Equal(j1, j2)
+	serialized, err := NewSerializer().sign(soptions...).encrypt(eoptions...).Serialize(t)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt.SignAndEncrypt: %w`, err)
+	}
+	return serialized, nil
 }
 
 func (t *stdToken) Clone() (Token, error) {
@@ -441,13 +833,87 @@ func (t *stdToken) Clone() (Token, error) {
 	for _, pair := range t.makePairs() {
 		//nolint:forcetypeassert
 		key := pair.Key.(string)
-		if err := dst.Set(key, pair.Value); err != nil {
+		if err := dst.Set(key, claims.DeepCopy(pair.Value)); err != nil {
 			return nil, fmt.Errorf(`failed to set %s: %w`, key, err)
 		}
 	}
 	return dst, nil
 }
 
+func (t *stdToken) Equal(other Token) bool {
+	return Equal(t, other)
+}
+
+func (t *stdToken) Expired(clock Clock) bool {
+	return Expired(t, clock)
+}
+
+func (t *stdToken) RemainingValidity(clock Clock) time.Duration {
+	return RemainingValidity(t, clock)
+}
+
+// Decode assigns the claims in the token `t` to the fields of the struct
+// pointed to by `dst`. The claim names are taken from the `json` struct
+// tags, in the same manner as `json.Unmarshal`, for example:
+//
+//	var c MyClaims
+//	err := jwt.Decode(tok, &c)
+//
+// Any implementation of `jwt.Token`, including `openid.Token`, may be
+// passed. See `jwt.FromStruct()` for the reverse operation
+func Decode(t Token, dst interface{}) error {
+	src, err := t.AsMap(context.Background())
+	if err != nil {
+		return fmt.Errorf(`jwt.Decode: failed to retrieve claims: %w`, err)
+	}
+	if err := claims.Decode(src, dst); err != nil {
+		return fmt.Errorf(`jwt.Decode: %w`, err)
+	}
+	return nil
+}
+
+// FromStruct creates a new token from the fields of the struct `v`
+// (or a pointer to it). The claim names are taken from the `json`
+// struct tags, in the same manner as `json.Marshal`, for example:
+//
+//	type MyClaims struct {
+//	  Issuer     string    `json:"iss"`
+//	  Subject    string    `json:"sub"`
+//	  Expiration time.Time `json:"exp"`
+//	  Scope      string    `json:"scope,omitempty"`
+//	}
+//	tok, err := jwt.FromStruct(MyClaims{...})
+//
+// Values for the pre-defined claims must be of a type accepted by
+// `(jwt.Token).Set()`: for example, `exp` may be a `time.Time` or
+// a number of seconds since the Unix epoch. Nil pointers, maps, and
+// slices, as well as zero `time.Time` values, are omitted.
+//
+// Use `jwt.Decode()` to perform the reverse operation
+func FromStruct(v interface{}) (Token, error) {
+	names, values, err := claims.Encode(v)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt.FromStruct: %w`, err)
+	}
+
+	t := New()
+	for i, name := range names {
+		if err := t.Set(name, values[i]); err != nil {
+			return nil, fmt.Errorf(`jwt.FromStruct: failed to set %q: %w`, name, err)
+		}
+	}
+	return t, nil
+}
+
+// CustomDecoder is the interface for objects that decode the raw JSON
+// representation of a claim into an arbitrary Go value. It can be passed
+// to `jwt.RegisterCustomField()` and `jwt.WithTypedClaim()` in place of
+// an object of the target type.
+type CustomDecoder = json.CustomDecoder
+
+// CustomDecodeFunc is a function that implements `jwt.CustomDecoder`.
+type CustomDecodeFunc = json.CustomDecodeFunc
+
 // RegisterCustomField allows users to specify that a private field
 // be decoded as an instance of the specified type. This option has
 // a global effect.
@@ -465,6 +931,20 @@ func (t *stdToken) Clone() (Token, error) {
 //
 //	bdayif, _ := token.Get(`x-birthday`)
 //	bday := bdayif.(time.Time)
+//
+// If the JSON representation of the field cannot be decoded by
+// unmarshaling it into the specified type, register a `jwt.CustomDecoder`
+// instead, which receives the raw JSON value of the field:
+//
+//	jwt.RegisterCustomField(`x-birthday`, jwt.CustomDecodeFunc(func(data []byte) (interface{}, error) {
+//	  var s string
+//	  if err := json.Unmarshal(data, &s); err != nil {
+//	    return nil, err
+//	  }
+//	  return time.Parse(`2006-01-02`, s)
+//	}))
+//
+// Specifying a nil object removes the registration.
 func RegisterCustomField(name string, object interface{}) {
 	registry.Register(name, object)
 }
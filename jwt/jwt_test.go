@@ -120,6 +120,24 @@ func TestJWTParse(t *testing.T) {
 			return
 		}
 	})
+	t.Run("Parse (no keys)", func(t *testing.T) {
+		t.Parallel()
+		_, err := jwt.Parse(signed)
+		require.Error(t, err, `jwt.Parse without keys should fail`)
+	})
+	t.Run("ParseInsecure (disallowed options)", func(t *testing.T) {
+		t.Parallel()
+		options := []jwt.ParseOption{
+			jwt.WithVerify(true),
+			jwt.WithValidate(true),
+			jwt.WithKey(alg, &key.PublicKey),
+			jwt.WithKeySet(jwk.NewSet()),
+		}
+		for _, option := range options {
+			_, err := jwt.ParseInsecure(signed, option)
+			require.Error(t, err, `jwt.ParseInsecure should fail`)
+		}
+	})
 }
 
 func TestJWTParseVerify(t *testing.T) {
@@ -722,6 +740,70 @@ func TestSignTyp(t *testing.T) {
 	})
 }
 
+func TestSignWithJwsHeaders(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	hdrs := jws.NewHeaders()
+	require.NoError(t, hdrs.Set(jws.TypeKey, `at+jwt`), `hdrs.Set should succeed`)
+	require.NoError(t, hdrs.Set(jws.KeyIDKey, `my-key`), `hdrs.Set should succeed`)
+	require.NoError(t, hdrs.Set(jws.X509CertThumbprintKey, `dGh1bWJwcmludA`), `hdrs.Set should succeed`)
+	require.NoError(t, hdrs.Set(`x-private`, `private-value`), `hdrs.Set should succeed`)
+
+	t.Run("headers are set", func(t *testing.T) {
+		signed, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		got, err := getJWTHeaders(signed)
+		require.NoError(t, err, `getJWTHeaders should succeed`)
+		require.Equal(t, `at+jwt`, got.Type(), `typ should match`)
+		require.Equal(t, `my-key`, got.KeyID(), `kid should match`)
+		require.Equal(t, `dGh1bWJwcmludA`, got.X509CertThumbprint(), `x5t should match`)
+		v, ok := got.Get(`x-private`)
+		require.True(t, ok, `private header should exist`)
+		require.Equal(t, `private-value`, v, `private header should match`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+	})
+	t.Run("typ defaults to JWT", func(t *testing.T) {
+		kidOnly := jws.NewHeaders()
+		require.NoError(t, kidOnly.Set(jws.KeyIDKey, `my-key`), `kidOnly.Set should succeed`)
+
+		signed, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(kidOnly))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		got, err := getJWTHeaders(signed)
+		require.NoError(t, err, `getJWTHeaders should succeed`)
+		require.Equal(t, `JWT`, got.Type(), `typ should be JWT`)
+		require.Equal(t, `my-key`, got.KeyID(), `kid should match`)
+
+		_, ok := kidOnly.Get(jws.TypeKey)
+		require.False(t, ok, `original headers should not be modified`)
+	})
+	t.Run("jws.WithProtectedHeaders takes precedence", func(t *testing.T) {
+		own := jws.NewHeaders()
+		require.NoError(t, own.Set(jws.TypeKey, `secevent+jwt`), `own.Set should succeed`)
+
+		signed, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.RS256, key, jws.WithProtectedHeaders(own)), jwt.WithJwsHeaders(hdrs))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		got, err := getJWTHeaders(signed)
+		require.NoError(t, err, `getJWTHeaders should succeed`)
+		require.Equal(t, `secevent+jwt`, got.Type(), `typ should match`)
+	})
+	t.Run("Serializer", func(t *testing.T) {
+		signed, err := jwt.NewSerializer().
+			Sign(jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs)).
+			Serialize(jwt.New())
+		require.NoError(t, err, `Serialize should succeed`)
+
+		got, err := getJWTHeaders(signed)
+		require.NoError(t, err, `getJWTHeaders should succeed`)
+		require.Equal(t, `at+jwt`, got.Type(), `typ should match`)
+	})
+}
+
 func TestReadFile(t *testing.T) {
 	t.Parallel()
 
@@ -792,6 +874,63 @@ func TestCustomField(t *testing.T) {
 	})
 }
 
+func TestCustomDecoder(t *testing.T) {
+	type Transaction struct {
+		ID     string
+		Amount int64
+	}
+
+	// Decodes "id:amount" strings, which cannot be unmarshaled into
+	// Transaction directly
+	decoder := jwt.CustomDecodeFunc(func(data []byte) (interface{}, error) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		i := strings.IndexByte(s, ':')
+		if i < 0 {
+			return nil, fmt.Errorf(`invalid transaction %q`, s)
+		}
+		amount, err := strconv.ParseInt(s[i+1:], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return Transaction{ID: s[:i], Amount: amount}, nil
+	})
+
+	const src = `{"iss":"github.com/lestrrat-go/jwx","transaction":"tx-1234:500"}`
+	expected := Transaction{ID: `tx-1234`, Amount: 500}
+
+	t.Run("RegisterCustomField", func(t *testing.T) {
+		// XXX has global effect!!!
+		jwt.RegisterCustomField(`transaction`, decoder)
+		defer jwt.RegisterCustomField(`transaction`, nil)
+
+		token, err := jwt.ParseInsecure([]byte(src))
+		require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+		v, ok := token.Get(`transaction`)
+		require.True(t, ok, `token.Get should succeed`)
+		require.Equal(t, expected, v)
+
+		_, err = jwt.ParseInsecure([]byte(`{"transaction":"tx-1234"}`))
+		require.Error(t, err, `jwt.ParseInsecure should fail when the decoder fails`)
+	})
+	t.Run("WithTypedClaim", func(t *testing.T) {
+		token, err := jwt.ParseInsecure([]byte(src), jwt.WithTypedClaim(`transaction`, decoder))
+		require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+		v, ok := token.Get(`transaction`)
+		require.True(t, ok, `token.Get should succeed`)
+		require.Equal(t, expected, v)
+	})
+	t.Run("unregistered", func(t *testing.T) {
+		token, err := jwt.ParseInsecure([]byte(src))
+		require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+		v, ok := token.Get(`transaction`)
+		require.True(t, ok, `token.Get should succeed`)
+		require.Equal(t, `tx-1234:500`, v)
+	})
+}
+
 func TestParseRequest(t *testing.T) {
 	const u = "https://github.com/lestrrat-gow/jwx/jwt"
 
@@ -941,6 +1080,68 @@ func TestParseRequest(t *testing.T) {
 			},
 			Error: true,
 		},
+		{
+			Name: "Token in Authorization header with lower case scheme",
+			Request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, u, nil)
+				req.Header.Add("Authorization", "bearer "+string(signed))
+				return req
+			},
+			Parse: func(req *http.Request) (jwt.Token, error) {
+				return jwt.ParseRequest(req, jwt.WithKey(jwa.ES256, pubkey))
+			},
+		},
+		{
+			Name: "Token in session cookie (w/ option)",
+			Request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, u, nil)
+				req.AddCookie(&http.Cookie{Name: "session", Value: string(signed)})
+				return req
+			},
+			Parse: func(req *http.Request) (jwt.Token, error) {
+				return jwt.ParseRequest(req, jwt.WithCookieKey("session"), jwt.WithKey(jwa.ES256, pubkey))
+			},
+		},
+		{
+			Name: "Token in session cookie (w/o option)",
+			Request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, u, nil)
+				req.AddCookie(&http.Cookie{Name: "session", Value: string(signed)})
+				return req
+			},
+			Parse: func(req *http.Request) (jwt.Token, error) {
+				return jwt.ParseRequest(req, jwt.WithKey(jwa.ES256, pubkey))
+			},
+			Error: true,
+		},
+		{
+			Name: "Invalid token in session cookie",
+			Request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, u, nil)
+				req.AddCookie(&http.Cookie{Name: "session", Value: string(signed) + "foobarbaz"})
+				return req
+			},
+			Parse: func(req *http.Request) (jwt.Token, error) {
+				return jwt.ParseRequest(req, jwt.WithCookieKey("session"), jwt.WithKey(jwa.ES256, pubkey))
+			},
+			Error: true,
+		},
+		{
+			Name: "Token in cookie, with invalid token in header",
+			Request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, u, nil)
+				req.Header.Add("Authorization", "Bearer foobarbaz")
+				req.AddCookie(&http.Cookie{Name: "session", Value: string(signed)})
+				return req
+			},
+			Parse: func(req *http.Request) (jwt.Token, error) {
+				return jwt.ParseRequest(req,
+					jwt.WithHeaderKey("Authorization"),
+					jwt.WithCookieKey("session"),
+					jwt.WithFormKey("access_token"),
+					jwt.WithKey(jwa.ES256, pubkey))
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -1076,6 +1277,19 @@ func TestGHIssue368(t *testing.T) {
 			})
 		})
 	}
+
+	t.Run("Unrelated settings do not reset WithFlattenAudience", func(t *testing.T) {
+		defer jwt.Settings(jwt.WithFlattenAudience(false))
+		jwt.Settings(jwt.WithFlattenAudience(true))
+		jwt.Settings(jwt.WithNumericDateFormatPrecision(0))
+
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.AudienceKey, `hello`), `tok.Set should succeed`)
+
+		buf, err := json.Marshal(tok)
+		require.NoError(t, err, `json.Marshal should succeed`)
+		require.Equal(t, `{"aud":"hello"}`, string(buf), `output should match`)
+	})
 }
 
 func TestGH375(t *testing.T) {
@@ -1530,6 +1744,32 @@ func TestSerializer(t *testing.T) {
 			return
 		}
 	})
+	t.Run(`Order`, func(t *testing.T) {
+		signKey := []byte(`abracadabra`)
+		encKey := []byte(`abracadabra-abracadabra-abracada`)
+
+		serialized, err := jwt.NewSerializer().
+			Sign(jwt.WithKey(jwa.HS256, signKey)).
+			Encrypt(jwt.WithKey(jwa.A256KW, encKey)).
+			Serialize(jwt.New())
+		require.NoError(t, err, `sign-then-encrypt should succeed`)
+		_, err = jwt.Parse(serialized, jwt.WithKey(jwa.A256KW, encKey), jwt.WithKey(jwa.HS256, signKey))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+
+		_, err = jwt.NewSerializer().
+			Encrypt(jwt.WithKey(jwa.A256KW, encKey)).
+			Sign(jwt.WithKey(jwa.HS256, signKey)).
+			Serialize(jwt.New())
+		require.Error(t, err, `encrypt-then-sign should fail`)
+		require.Contains(t, err.Error(), `AllowEncryptThenSign`)
+
+		_, err = jwt.NewSerializer().
+			AllowEncryptThenSign(true).
+			Encrypt(jwt.WithKey(jwa.A256KW, encKey)).
+			Sign(jwt.WithKey(jwa.HS256, signKey)).
+			Serialize(jwt.New())
+		require.NoError(t, err, `encrypt-then-sign should succeed when explicitly allowed`)
+	})
 }
 
 func TestFractional(t *testing.T) {
@@ -1642,6 +1882,38 @@ func TestFractional(t *testing.T) {
 		}
 		jwt.Settings(jwt.WithNumericDateParsePrecision(0))
 	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		jwt.Settings(jwt.WithNumericDateParsePrecision(3), jwt.WithNumericDateFormatPrecision(3))
+		defer jwt.Settings(jwt.WithNumericDateParsePrecision(0), jwt.WithNumericDateFormatPrecision(0))
+
+		testcases := []struct {
+			Input    string
+			Expected string
+		}{
+			{Input: `1600000000.123`, Expected: `1600000000.123`},
+			{Input: `1600000000.999`, Expected: `1600000000.999`},
+			{Input: `1600000000.5`, Expected: `1600000000.500`},
+			{Input: `1600000000`, Expected: `1600000000.000`},
+			{Input: `1600000000.1239`, Expected: `1600000000.123`},
+			{Input: `1.600000000123e9`, Expected: `1600000000.123`},
+		}
+
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Input, func(t *testing.T) {
+				tok, err := jwt.Parse(
+					[]byte(`{"exp":`+tc.Input+`}`),
+					jwt.WithVerify(false),
+					jwt.WithValidate(false),
+				)
+				require.NoError(t, err, `jwt.Parse should succeed`)
+
+				serialized, err := json.Marshal(tok)
+				require.NoError(t, err, `json.Marshal should succeed`)
+				require.Equal(t, `{"exp":`+tc.Expected+`}`, string(serialized), `serialized token should match`)
+			})
+		}
+	})
 }
 
 func TestGH836(t *testing.T) {
@@ -1667,3 +1939,564 @@ func TestGH850(t *testing.T) {
 	_, err := jwt.Parse([]byte(testToken), jwt.WithVerify(false))
 	require.True(t, errors.Is(err, jwt.ErrInvalidJWT()))
 }
+
+func TestParseWithKeySetProvider(t *testing.T) {
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+	require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+
+	pubkey, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pubkey), `set.AddKey should succeed`)
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	t.Run("static provider", func(t *testing.T) {
+		parsed, err := jwt.Parse(signed, jwt.WithKeySetProvider(jwk.NewStaticProvider(set)))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `alice`, parsed.Subject(), `subject should match`)
+	})
+	t.Run("hints are passed", func(t *testing.T) {
+		var hints jwk.ProviderHints
+		p := jwk.ProviderFunc(func(_ context.Context, h jwk.ProviderHints) (jwk.Set, error) {
+			hints = h
+			return set, nil
+		})
+		_, err := jwt.Parse(signed, jwt.WithKeySetProvider(p))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `my-key`, hints.KeyID, `kid hint should match`)
+		require.Equal(t, jwa.RS256, hints.Algorithm, `alg hint should match`)
+	})
+	t.Run("provider error", func(t *testing.T) {
+		p := jwk.ProviderFunc(func(_ context.Context, _ jwk.ProviderHints) (jwk.Set, error) {
+			return nil, fmt.Errorf(`no keys for you`)
+		})
+		_, err := jwt.Parse(signed, jwt.WithKeySetProvider(p))
+		require.Error(t, err, `jwt.Parse should fail`)
+	})
+}
+
+func TestSignAndEncrypt(t *testing.T) {
+	signKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	encKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(`secret`, `confidential`), `tok.Set should succeed`)
+
+	encrypted, err := jwt.SignAndEncrypt(tok,
+		jwt.WithKey(jwa.RS256, signKey),
+		jwt.WithKey(jwa.RSA_OAEP, &encKey.PublicKey),
+	)
+	require.NoError(t, err, `jwt.SignAndEncrypt should succeed`)
+
+	msg, err := jwe.Parse(encrypted)
+	require.NoError(t, err, `jwe.Parse should succeed`)
+	require.Equal(t, `JWT`, msg.ProtectedHeaders().ContentType(), `cty should be "JWT"`)
+
+	t.Run("parse with both keys", func(t *testing.T) {
+		for _, pedantic := range []bool{false, true} {
+			parsed, err := jwt.Parse(encrypted,
+				jwt.WithKey(jwa.RSA_OAEP, encKey),
+				jwt.WithKey(jwa.RS256, &signKey.PublicKey),
+				jwt.WithPedantic(pedantic),
+			)
+			require.NoError(t, err, `jwt.Parse should succeed (pedantic = %t)`, pedantic)
+			require.Equal(t, `alice`, parsed.Subject(), `subject should match`)
+			v, ok := parsed.Get(`secret`)
+			require.True(t, ok, `secret claim should exist`)
+			require.Equal(t, `confidential`, v, `secret claim should match`)
+		}
+	})
+	t.Run("missing decryption key", func(t *testing.T) {
+		_, err := jwt.Parse(encrypted, jwt.WithKey(jwa.RS256, &signKey.PublicKey))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.Contains(t, err.Error(), `no keys for decryption`)
+	})
+	t.Run("missing verification key", func(t *testing.T) {
+		_, err := jwt.Parse(encrypted, jwt.WithKey(jwa.RSA_OAEP, encKey))
+		require.Error(t, err, `jwt.Parse should fail`)
+	})
+	t.Run("wrong verification key", func(t *testing.T) {
+		_, err := jwt.Parse(encrypted,
+			jwt.WithKey(jwa.RSA_OAEP, encKey),
+			jwt.WithKey(jwa.RS256, &encKey.PublicKey),
+		)
+		require.Error(t, err, `jwt.Parse should fail`)
+	})
+	t.Run("skip verification", func(t *testing.T) {
+		parsed, err := jwt.Parse(encrypted,
+			jwt.WithKey(jwa.RSA_OAEP, encKey),
+			jwt.WithVerify(false),
+		)
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `alice`, parsed.Subject(), `subject should match`)
+	})
+	t.Run("encrypted but not signed", func(t *testing.T) {
+		unsigned, err := jwt.NewSerializer().
+			Encrypt(jwt.WithKey(jwa.RSA_OAEP, &encKey.PublicKey)).
+			Serialize(tok)
+		require.NoError(t, err, `serializer should succeed`)
+
+		_, err = jwt.Parse(unsigned,
+			jwt.WithKey(jwa.RSA_OAEP, encKey),
+			jwt.WithKey(jwa.RS256, &signKey.PublicKey),
+		)
+		require.Error(t, err, `jwt.Parse should fail`)
+	})
+	t.Run("missing keys", func(t *testing.T) {
+		_, err := jwt.SignAndEncrypt(tok, jwt.WithKey(jwa.RS256, signKey))
+		require.Error(t, err, `jwt.SignAndEncrypt should fail without encryption keys`)
+		_, err = jwt.SignAndEncrypt(tok, jwt.WithKey(jwa.RSA_OAEP, &encKey.PublicKey))
+		require.Error(t, err, `jwt.SignAndEncrypt should fail without signing keys`)
+	})
+}
+
+func TestStructClaims(t *testing.T) {
+	type Common struct {
+		Issuer string `json:"iss"`
+	}
+	type MyClaims struct {
+		Common
+		Subject    string    `json:"sub"`
+		Audience   []string  `json:"aud,omitempty"`
+		Expiration time.Time `json:"exp"`
+		IssuedAt   int64     `json:"iat,omitempty"`
+		Scope      string    `json:"scope,omitempty"`
+		Admin      *bool     `json:"admin,omitempty"`
+		Level      int       `json:"level"`
+		Roles      []string  `json:"roles,omitempty"`
+		Ignored    string    `json:"-"`
+		unexported string
+	}
+
+	exp := time.Unix(time.Now().Add(time.Hour).Unix(), 0).UTC()
+	admin := true
+	src := MyClaims{
+		Common:     Common{Issuer: `github.com/lestrrat-go/jwx`},
+		Subject:    `alice`,
+		Audience:   []string{`users`},
+		Expiration: exp,
+		IssuedAt:   exp.Add(-time.Hour).Unix(),
+		Admin:      &admin,
+		Level:      3,
+		Roles:      []string{`reader`, `writer`},
+		Ignored:    `ignored`,
+		unexported: `unexported`,
+	}
+
+	t.Run("FromStruct", func(t *testing.T) {
+		tok, err := jwt.FromStruct(&src)
+		require.NoError(t, err, `jwt.FromStruct should succeed`)
+		require.Equal(t, `github.com/lestrrat-go/jwx`, tok.Issuer(), `iss should match`)
+		require.Equal(t, `alice`, tok.Subject(), `sub should match`)
+		require.Equal(t, []string{`users`}, tok.Audience(), `aud should match`)
+		require.True(t, exp.Equal(tok.Expiration()), `exp should match`)
+		require.True(t, exp.Add(-time.Hour).Equal(tok.IssuedAt()), `iat should match`)
+
+		_, ok := tok.Get(`scope`)
+		require.False(t, ok, `empty scope should be omitted`)
+		_, ok = tok.Get(`Ignored`)
+		require.False(t, ok, `fields tagged with "-" should be omitted`)
+		_, ok = tok.Get(`unexported`)
+		require.False(t, ok, `unexported fields should be omitted`)
+
+		v, ok := tok.Get(`admin`)
+		require.True(t, ok, `admin should exist`)
+		require.Equal(t, true, v, `admin should be dereferenced`)
+	})
+	t.Run("Decode", func(t *testing.T) {
+		tok, err := jwt.FromStruct(src)
+		require.NoError(t, err, `jwt.FromStruct should succeed`)
+
+		key := []byte(`abracadabra-abracadabra-abracadabra`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+
+		var dst MyClaims
+		require.NoError(t, jwt.Decode(parsed, &dst), `jwt.Decode should succeed`)
+
+		expected := src
+		expected.Ignored = ``
+		expected.unexported = ``
+		require.True(t, exp.Equal(dst.Expiration), `exp should match`)
+		expected.Expiration = dst.Expiration
+		require.Equal(t, expected, dst, `decoded claims should match`)
+	})
+	t.Run("Decode errors", func(t *testing.T) {
+		tok := jwt.New()
+		require.NoError(t, tok.Set(`level`, `high`), `tok.Set should succeed`)
+
+		var dst MyClaims
+		require.Error(t, jwt.Decode(tok, &dst), `decoding a string into int should fail`)
+		require.Error(t, jwt.Decode(tok, dst), `decoding into a non-pointer should fail`)
+		require.Error(t, jwt.Decode(tok, nil), `decoding into nil should fail`)
+	})
+	t.Run("FromStruct errors", func(t *testing.T) {
+		_, err := jwt.FromStruct(map[string]interface{}{`sub`: `alice`})
+		require.Error(t, err, `jwt.FromStruct should fail for non-structs`)
+
+		_, err = jwt.FromStruct(struct {
+			Expiration string `json:"exp"`
+		}{Expiration: `tomorrow`})
+		require.Error(t, err, `jwt.FromStruct should fail for invalid exp`)
+	})
+}
+
+func TestStrictJSON(t *testing.T) {
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+
+	signCompact := func(t *testing.T, hdr, payload string) []byte {
+		t.Helper()
+		signer, err := jws.NewSigner(jwa.HS256)
+		require.NoError(t, err, `jws.NewSigner should succeed`)
+
+		var buf bytes.Buffer
+		buf.WriteString(base64.RawURLEncoding.EncodeToString([]byte(hdr)))
+		buf.WriteByte('.')
+		buf.WriteString(base64.RawURLEncoding.EncodeToString([]byte(payload)))
+		signature, err := signer.Sign(buf.Bytes(), key)
+		require.NoError(t, err, `signer.Sign should succeed`)
+		buf.WriteByte('.')
+		buf.WriteString(base64.RawURLEncoding.EncodeToString(signature))
+		return buf.Bytes()
+	}
+
+	t.Run("duplicate claims", func(t *testing.T) {
+		signed := signCompact(t, `{"alg":"HS256"}`, `{"sub":"alice","sub":"mallory"}`)
+
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Parse should succeed without strict mode`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithStrictJSON(true))
+		require.Error(t, err, `jwt.Parse should fail in strict mode`)
+		require.Contains(t, err.Error(), `"sub"`, `error should name the duplicate member`)
+
+		_, err = jwt.Parse(signed, jwt.WithVerify(false), jwt.WithStrictJSON(true))
+		require.Error(t, err, `jwt.Parse should fail in strict mode without verification`)
+	})
+	t.Run("global setting", func(t *testing.T) {
+		signed := signCompact(t, `{"alg":"HS256"}`, `{"sub":"alice","sub":"mallory"}`)
+
+		jwt.Settings(jwt.WithStrictJSON(true))
+		defer jwt.Settings(jwt.WithStrictJSON(false))
+
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key))
+		require.Error(t, err, `jwt.Parse should fail when strict mode is enabled globally`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithStrictJSON(false))
+		require.NoError(t, err, `jwt.Parse should succeed when strict mode is disabled for the call`)
+	})
+	t.Run("duplicate nested claims", func(t *testing.T) {
+		signed := signCompact(t, `{"alg":"HS256"}`, `{"sub":"alice","extra":{"a":1,"a":2}}`)
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithStrictJSON(true))
+		require.Error(t, err, `jwt.Parse should fail in strict mode`)
+	})
+	t.Run("duplicate protected headers", func(t *testing.T) {
+		signed := signCompact(t, `{"alg":"HS256","kid":"a","kid":"b"}`, `{"sub":"alice"}`)
+
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Parse should succeed without strict mode`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithStrictJSON(true))
+		require.Error(t, err, `jwt.Parse should fail in strict mode`)
+		require.Contains(t, err.Error(), `"kid"`, `error should name the duplicate member`)
+	})
+	t.Run("no duplicates", func(t *testing.T) {
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(`extra`, map[string]interface{}{`a`: 1}), `tok.Set should succeed`)
+
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithStrictJSON(true))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `alice`, parsed.Subject(), `subject should match`)
+
+		signed, err = jwt.NewSerializer().
+			Sign(jwt.WithKey(jwa.HS256, key)).
+			Serialize(tok)
+		require.NoError(t, err, `serializer should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithStrictJSON(true))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+	})
+	t.Run("nested JWE", func(t *testing.T) {
+		encKey, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+		signed := signCompact(t, `{"alg":"HS256"}`, `{"sub":"alice","sub":"mallory"}`)
+		encrypted, err := jwe.Encrypt(signed, jwe.WithKey(jwa.RSA_OAEP, &encKey.PublicKey))
+		require.NoError(t, err, `jwe.Encrypt should succeed`)
+
+		_, err = jwt.Parse(encrypted,
+			jwt.WithKey(jwa.RSA_OAEP, encKey),
+			jwt.WithKey(jwa.HS256, key),
+		)
+		require.NoError(t, err, `jwt.Parse should succeed without strict mode`)
+
+		_, err = jwt.Parse(encrypted,
+			jwt.WithKey(jwa.RSA_OAEP, encKey),
+			jwt.WithKey(jwa.HS256, key),
+			jwt.WithStrictJSON(true),
+		)
+		require.Error(t, err, `jwt.Parse should fail in strict mode`)
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(`data`, strings.Repeat(`x`, 1024)), `tok.Set should succeed`)
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	t.Run("token size", func(t *testing.T) {
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithMaxTokenSize(len(signed)))
+		require.NoError(t, err, `jwt.Parse should succeed within the limit`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithMaxTokenSize(len(signed)-1))
+		require.Error(t, err, `jwt.Parse should fail when the token is too large`)
+
+		_, err = jwt.ParseReader(bytes.NewReader(signed), jwt.WithKey(jwa.HS256, key), jwt.WithMaxTokenSize(len(signed)-1))
+		require.Error(t, err, `jwt.ParseReader should fail when the token is too large`)
+
+		large := append([]byte(nil), signed...)
+		large = append(large, bytes.Repeat([]byte{' '}, 1<<20)...)
+		_, err = jwt.Parse(large, jwt.WithKey(jwa.HS256, key))
+		require.Error(t, err, `jwt.Parse should fail when the token exceeds the default limit`)
+
+		_, err = jwt.Parse(large, jwt.WithKey(jwa.HS256, key), jwt.WithMaxTokenSize(0))
+		require.NoError(t, err, `jwt.Parse should succeed when the limit is removed`)
+	})
+	t.Run("payload size", func(t *testing.T) {
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithMaxPayloadSize(1024))
+		require.Error(t, err, `jwt.Parse should fail when the payload is too large`)
+
+		_, err = jwt.Parse(signed, jwt.WithVerify(false), jwt.WithMaxPayloadSize(1024))
+		require.Error(t, err, `jwt.Parse should fail when the payload is too large`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithMaxPayloadSize(2048))
+		require.NoError(t, err, `jwt.Parse should succeed within the limit`)
+	})
+	t.Run("compressed payload size", func(t *testing.T) {
+		encKey := make([]byte, 16)
+		_, err := rand.Read(encKey)
+		require.NoError(t, err, `rand.Read should succeed`)
+
+		// The compressed token is much smaller than the decompressed one
+		encrypted, err := jwe.Encrypt(signed, jwe.WithKey(jwa.A128KW, encKey), jwe.WithCompress(jwa.Deflate))
+		require.NoError(t, err, `jwe.Encrypt should succeed`)
+
+		_, err = jwt.Parse(encrypted, jwt.WithKey(jwa.A128KW, encKey), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Parse should succeed within the limit`)
+
+		_, err = jwt.Parse(encrypted,
+			jwt.WithKey(jwa.A128KW, encKey),
+			jwt.WithKey(jwa.HS256, key),
+			jwt.WithMaxPayloadSize(len(signed)-1),
+		)
+		require.Error(t, err, `jwt.Parse should fail when the decompressed payload is too large`)
+	})
+	t.Run("nesting depth", func(t *testing.T) {
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithMaxNestingDepth(1))
+		require.NoError(t, err, `jwt.Parse should succeed within the limit`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithMaxNestingDepth(0))
+		require.Error(t, err, `jwt.Parse should fail for invalid depths`)
+
+		// JWS enveloped in JWS
+		nested, err := jws.Sign(signed, jws.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jws.Sign should succeed`)
+		_, err = jwt.Parse(nested, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Parse should succeed within the limit`)
+		_, err = jwt.Parse(nested, jwt.WithKey(jwa.HS256, key), jwt.WithMaxNestingDepth(1))
+		require.Error(t, err, `jwt.Parse should fail when the token is nested too deeply`)
+
+		nested, err = jws.Sign(nested, jws.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jws.Sign should succeed`)
+		_, err = jwt.Parse(nested, jwt.WithKey(jwa.HS256, key))
+		require.Error(t, err, `jwt.Parse should fail when the token is nested too deeply`)
+		require.Contains(t, err.Error(), `nesting depth`)
+		_, err = jwt.Parse(nested, jwt.WithKey(jwa.HS256, key), jwt.WithMaxNestingDepth(3))
+		require.NoError(t, err, `jwt.Parse should succeed within the limit`)
+	})
+}
+
+// mapToken is a minimal implementation of jwt.Token that stores its claims
+// in a map, used to check that third-party implementations of jwt.Token
+// can be signed, parsed, and validated
+type mapToken struct {
+	claims  map[string]interface{}
+	options jwt.TokenOptionSet
+}
+
+func newMapToken() *mapToken {
+	return &mapToken{claims: make(map[string]interface{})}
+}
+
+func (t *mapToken) stringClaim(name string) string {
+	s, _ := t.claims[name].(string)
+	return s
+}
+
+func (t *mapToken) timeClaim(name string) time.Time {
+	switch v := t.claims[name].(type) {
+	case time.Time:
+		return v
+	case float64:
+		return time.Unix(int64(v), 0)
+	case json.Number:
+		n, _ := v.Int64()
+		return time.Unix(n, 0)
+	}
+	return time.Time{}
+}
+
+func (t *mapToken) Audience() []string {
+	switch v := t.claims[jwt.AudienceKey].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var aud []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	}
+	return nil
+}
+
+func (t *mapToken) Expiration() time.Time { return t.timeClaim(jwt.ExpirationKey) }
+func (t *mapToken) IssuedAt() time.Time   { return t.timeClaim(jwt.IssuedAtKey) }
+func (t *mapToken) NotBefore() time.Time  { return t.timeClaim(jwt.NotBeforeKey) }
+func (t *mapToken) Issuer() string        { return t.stringClaim(jwt.IssuerKey) }
+func (t *mapToken) JwtID() string         { return t.stringClaim(jwt.JwtIDKey) }
+func (t *mapToken) Subject() string       { return t.stringClaim(jwt.SubjectKey) }
+
+func (t *mapToken) PrivateClaims() map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range t.claims {
+		switch k {
+		case jwt.AudienceKey, jwt.ExpirationKey, jwt.IssuedAtKey, jwt.IssuerKey, jwt.JwtIDKey, jwt.NotBeforeKey, jwt.SubjectKey:
+		default:
+			m[k] = v
+		}
+	}
+	return m
+}
+
+func (t *mapToken) Get(name string) (interface{}, bool) {
+	v, ok := t.claims[name]
+	return v, ok
+}
+
+func (t *mapToken) Set(name string, v interface{}) error {
+	t.claims[name] = v
+	return nil
+}
+
+func (t *mapToken) Remove(name string) error {
+	delete(t.claims, name)
+	return nil
+}
+
+func (t *mapToken) Options() *jwt.TokenOptionSet { return &t.options }
+
+func (t *mapToken) Clone() (jwt.Token, error) {
+	dst := newMapToken()
+	for k, v := range t.claims {
+		dst.claims[k] = v
+	}
+	dst.options = t.options
+	return dst, nil
+}
+
+// std converts the token to a standard token, to implement the methods
+// that are not specific to the storage of the claims
+func (t *mapToken) std() jwt.Token {
+	tok := jwt.New()
+	for k, v := range t.claims {
+		_ = tok.Set(k, v)
+	}
+	return tok
+}
+
+func (t *mapToken) Iterate(ctx context.Context) jwt.Iterator { return t.std().Iterate(ctx) }
+func (t *mapToken) Walk(ctx context.Context, v jwt.Visitor) error {
+	return t.std().Walk(ctx, v)
+}
+func (t *mapToken) AsMap(ctx context.Context) (map[string]interface{}, error) {
+	return t.std().AsMap(ctx)
+}
+func (t *mapToken) Equal(other jwt.Token) bool   { return jwt.Equal(t, other) }
+func (t *mapToken) Expired(clock jwt.Clock) bool { return jwt.Expired(t, clock) }
+func (t *mapToken) RemainingValidity(clock jwt.Clock) time.Duration {
+	return jwt.RemainingValidity(t, clock)
+}
+
+func (t *mapToken) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.claims)
+}
+
+func (t *mapToken) UnmarshalJSON(data []byte) error {
+	t.claims = make(map[string]interface{})
+	return json.Unmarshal(data, &t.claims)
+}
+
+func TestCustomTokenImplementation(t *testing.T) {
+	key := []byte(`abracadabra`)
+	now := time.Now().Truncate(time.Second)
+
+	tok := newMapToken()
+	require.NoError(t, tok.Set(jwt.IssuerKey, `https://example.com`), `Set should succeed`)
+	require.NoError(t, tok.Set(jwt.ExpirationKey, now.Add(time.Hour).Unix()), `Set should succeed`)
+	require.NoError(t, tok.Set(`role`, `admin`), `Set should succeed`)
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	parsed, err := jwt.Parse(signed,
+		jwt.WithKey(jwa.HS256, key),
+		jwt.WithToken(newMapToken()),
+		jwt.WithIssuer(`https://example.com`),
+		jwt.WithRequiredClaim(`role`),
+	)
+	require.NoError(t, err, `jwt.Parse should succeed`)
+	require.IsType(t, &mapToken{}, parsed, `parsed token should be a *mapToken`)
+	require.Equal(t, `https://example.com`, parsed.Issuer())
+	require.Equal(t, now.Add(time.Hour).Unix(), parsed.Expiration().Unix())
+	require.Equal(t, map[string]interface{}{`role`: `admin`}, parsed.PrivateClaims())
+	require.True(t, jwt.Equal(tok, parsed), `tokens should be equal`)
+
+	var claims struct {
+		Issuer string `json:"iss"`
+		Role   string `json:"role"`
+	}
+	require.NoError(t, jwt.Decode(parsed, &claims), `jwt.Decode should succeed`)
+	require.Equal(t, `admin`, claims.Role)
+
+	_, err = jwt.Parse(signed,
+		jwt.WithKey(jwa.HS256, key),
+		jwt.WithToken(newMapToken()),
+		jwt.WithClock(jwt.ClockFunc(func() time.Time { return now.Add(2 * time.Hour) })),
+	)
+	require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `jwt.Parse should fail for expired tokens`)
+}
@@ -0,0 +1,270 @@
+// Package jwttest provides helpers for testing code that produces or
+// consumes JWTs: canned keys for each signature algorithm, factories for
+// valid, expired, and not-yet-valid tokens, and assertions on claims.
+//
+//	func TestHandler(t *testing.T) {
+//	  tok := jwttest.New(t, map[string]interface{}{jwt.SubjectKey: `alice`})
+//	  signed := jwttest.Sign(t, tok, jwa.RS256)
+//	  ...
+//	  parsed, err := jwt.Parse(signed, jwt.WithKeySet(jwttest.KeySet(t, jwa.RS256)))
+//	  ...
+//	  jwttest.RequireClaim(t, parsed, jwt.SubjectKey, `alice`)
+//	}
+//
+// The helpers report failures using `t.Fatalf()`, and therefore must be
+// called from the goroutine running the test. The keys are generated
+// once per process, and must never be used outside of tests.
+package jwttest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+var keys = struct {
+	mu  sync.Mutex
+	set map[jwa.SignatureAlgorithm]jwk.Key
+}{set: make(map[jwa.SignatureAlgorithm]jwk.Key)}
+
+// Key returns the canned private key (or the shared secret, for HMAC
+// algorithms) for `alg`. The key is generated on the first call, and the
+// same key is returned for all subsequent calls with the same algorithm.
+// Its `alg` field is set to `alg`, and its `kid` field to the name of
+// the algorithm.
+//
+// Keys for all of the signature algorithms supported by `jws.Sign()`
+// are available, except for `ES256K` and `none`.
+func Key(t testing.TB, alg jwa.SignatureAlgorithm) jwk.Key {
+	t.Helper()
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if key, ok := keys.set[alg]; ok {
+		return key
+	}
+
+	raw, err := generateKey(alg)
+	if err != nil {
+		t.Fatalf(`jwttest.Key: failed to generate key for %q: %s`, alg, err)
+		return nil
+	}
+	key, err := jwk.FromRaw(raw)
+	if err != nil {
+		t.Fatalf(`jwttest.Key: failed to convert key for %q: %s`, alg, err)
+		return nil
+	}
+	if err := key.Set(jwk.AlgorithmKey, alg); err != nil {
+		t.Fatalf(`jwttest.Key: failed to set %q: %s`, jwk.AlgorithmKey, err)
+		return nil
+	}
+	if err := key.Set(jwk.KeyIDKey, alg.String()); err != nil {
+		t.Fatalf(`jwttest.Key: failed to set %q: %s`, jwk.KeyIDKey, err)
+		return nil
+	}
+	keys.set[alg] = key
+	return key
+}
+
+// PublicKey returns the key that verifies signatures created using
+// `jwttest.Key(t, alg)`. For HMAC algorithms, the shared secret is returned.
+func PublicKey(t testing.TB, alg jwa.SignatureAlgorithm) jwk.Key {
+	t.Helper()
+	key := Key(t, alg)
+	if key.KeyType() == jwa.OctetSeq {
+		return key
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf(`jwttest.PublicKey: failed to get public key for %q: %s`, alg, err)
+		return nil
+	}
+	return pub
+}
+
+// KeySet returns a `jwk.Set` containing the keys returned by
+// `jwttest.PublicKey()` for each of `algs`, which may be passed to
+// `jwt.WithKeySet()`. Duplicate algorithms are ignored.
+func KeySet(t testing.TB, algs ...jwa.SignatureAlgorithm) jwk.Set {
+	t.Helper()
+	set := jwk.NewSet()
+	seen := make(map[jwa.SignatureAlgorithm]struct{}, len(algs))
+	for _, alg := range algs {
+		if _, ok := seen[alg]; ok {
+			continue
+		}
+		seen[alg] = struct{}{}
+		if err := set.AddKey(PublicKey(t, alg)); err != nil {
+			t.Fatalf(`jwttest.KeySet: failed to add key for %q: %s`, alg, err)
+			return nil
+		}
+	}
+	return set
+}
+
+func generateKey(alg jwa.SignatureAlgorithm) (interface{}, error) {
+	switch alg {
+	case jwa.HS256, jwa.HS384, jwa.HS512:
+		secret := make([]byte, 64)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	case jwa.RS256, jwa.RS384, jwa.RS512, jwa.PS256, jwa.PS384, jwa.PS512:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case jwa.ES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwa.ES384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case jwa.ES512:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case jwa.EdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return nil, errors.New(`unsupported algorithm`)
+	}
+}
+
+// New creates a token that is currently valid, with the `iat` claim set
+// to the current time and the `exp` claim set to an hour later, along
+// with the claims in `claims`, which take precedence. Times are truncated
+// to seconds, as they are when the token is serialized.
+func New(t testing.TB, claims map[string]interface{}) jwt.Token {
+	t.Helper()
+	now := time.Now().Truncate(time.Second)
+	return build(t, now, now.Add(time.Hour), time.Time{}, claims)
+}
+
+// Expired creates a token whose `exp` claim was an hour ago (and whose
+// `iat` claim was two hours ago), along with the claims in `claims`,
+// which take precedence. Validating it fails with an error that matches
+// `jwt.ErrTokenExpired()`.
+func Expired(t testing.TB, claims map[string]interface{}) jwt.Token {
+	t.Helper()
+	now := time.Now().Truncate(time.Second)
+	return build(t, now.Add(-2*time.Hour), now.Add(-time.Hour), time.Time{}, claims)
+}
+
+// NotYetValid creates a token whose `nbf` claim is an hour from now (and
+// whose `exp` claim is two hours from now), along with the claims in
+// `claims`, which take precedence. Validating it fails with an error that
+// matches `jwt.ErrTokenNotYetValid()`.
+func NotYetValid(t testing.TB, claims map[string]interface{}) jwt.Token {
+	t.Helper()
+	now := time.Now().Truncate(time.Second)
+	return build(t, now, now.Add(2*time.Hour), now.Add(time.Hour), claims)
+}
+
+func build(t testing.TB, iat, exp, nbf time.Time, claims map[string]interface{}) jwt.Token {
+	t.Helper()
+	b := jwt.NewBuilder().IssuedAt(iat).Expiration(exp)
+	if !nbf.IsZero() {
+		b = b.NotBefore(nbf)
+	}
+	for name, v := range claims {
+		b = b.Claim(name, v)
+	}
+	tok, err := b.Build()
+	if err != nil {
+		t.Fatalf(`jwttest: failed to build token: %s`, err)
+		return nil
+	}
+	return tok
+}
+
+// Sign signs `tok` using the canned key for `alg` (see `jwttest.Key()`),
+// and returns the token in JWS compact serialization.
+func Sign(t testing.TB, tok jwt.Token, alg jwa.SignatureAlgorithm) []byte {
+	t.Helper()
+	signed, err := jwt.Sign(tok, jwt.WithKey(alg, Key(t, alg)))
+	if err != nil {
+		t.Fatalf(`jwttest.Sign: failed to sign token: %s`, err)
+		return nil
+	}
+	return signed
+}
+
+// RequireClaim fails the test if the claim `name` does not exist in `tok`,
+// or if its value is not equal to `expected`. Time values are compared
+// using `time.Time.Equal()`. Other values are considered equal if they are
+// deeply equal, or if their JSON representations are the same, so that,
+// for example, `[]string{"a"}` matches a private claim that was parsed as
+// `[]interface{}{"a"}`, and `1` matches `float64(1)`.
+func RequireClaim(t testing.TB, tok jwt.Token, name string, expected interface{}) {
+	t.Helper()
+	v, ok := tok.Get(name)
+	if !ok {
+		t.Fatalf(`jwttest.RequireClaim: claim %q does not exist`, name)
+		return
+	}
+	if !claimEqual(v, expected) {
+		t.Fatalf(`jwttest.RequireClaim: claim %q does not match: expected %#v, got %#v`, name, expected, v)
+	}
+}
+
+// RequireNoClaim fails the test if the claim `name` exists in `tok`.
+func RequireNoClaim(t testing.TB, tok jwt.Token, name string) {
+	t.Helper()
+	if v, ok := tok.Get(name); ok {
+		t.Fatalf(`jwttest.RequireNoClaim: claim %q exists (%#v)`, name, v)
+	}
+}
+
+// RequireValid fails the test if `tok` does not pass `jwt.Validate()`
+// with the given options.
+func RequireValid(t testing.TB, tok jwt.Token, options ...jwt.ValidateOption) {
+	t.Helper()
+	if err := jwt.Validate(tok, options...); err != nil {
+		t.Fatalf(`jwttest.RequireValid: token is not valid: %s`, err)
+	}
+}
+
+// RequireInvalid fails the test if `tok` passes `jwt.Validate()` with the
+// given options, or if the returned error does not match `target` (such as
+// `jwt.ErrTokenExpired()`) using `errors.Is()`. If `target` is nil, any
+// error is accepted.
+func RequireInvalid(t testing.TB, tok jwt.Token, target error, options ...jwt.ValidateOption) {
+	t.Helper()
+	err := jwt.Validate(tok, options...)
+	if err == nil {
+		t.Fatalf(`jwttest.RequireInvalid: token is valid`)
+		return
+	}
+	if target != nil && !errors.Is(err, target) {
+		t.Fatalf(`jwttest.RequireInvalid: error does not match %q: %s`, target, err)
+	}
+}
+
+func claimEqual(actual, expected interface{}) bool {
+	if at, ok := actual.(time.Time); ok {
+		if et, ok := expected.(time.Time); ok {
+			return at.Equal(et)
+		}
+	}
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+
+	abuf, err := json.Marshal(actual)
+	if err != nil {
+		return false
+	}
+	ebuf, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	return string(abuf) == string(ebuf)
+}
@@ -0,0 +1,107 @@
+package jwttest_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/jwttest"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records failures instead of stopping the test
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(string, ...interface{}) {
+	r.failed = true
+}
+
+func TestKeys(t *testing.T) {
+	algs := []jwa.SignatureAlgorithm{
+		jwa.HS256, jwa.HS384, jwa.HS512,
+		jwa.RS256, jwa.PS256,
+		jwa.ES256, jwa.ES384, jwa.ES512,
+		jwa.EdDSA,
+	}
+	for _, alg := range algs {
+		alg := alg
+		t.Run(alg.String(), func(t *testing.T) {
+			key := jwttest.Key(t, alg)
+			require.Equal(t, key, jwttest.Key(t, alg), `keys should be cached`)
+			require.Equal(t, alg.String(), key.KeyID())
+
+			signed := jwttest.Sign(t, jwttest.New(t, nil), alg)
+			_, err := jwt.Parse(signed, jwt.WithKey(alg, jwttest.PublicKey(t, alg)))
+			require.NoError(t, err, `jwt.Parse should succeed`)
+			_, err = jwt.Parse(signed, jwt.WithKeySet(jwttest.KeySet(t, jwa.HS256, alg)))
+			require.NoError(t, err, `jwt.Parse should succeed with jwttest.KeySet`)
+		})
+	}
+
+	r := &recorder{TB: t}
+	jwttest.Key(r, jwa.NoSignature)
+	require.True(t, r.failed, `unsupported algorithms should fail`)
+}
+
+func TestFactories(t *testing.T) {
+	claims := map[string]interface{}{jwt.SubjectKey: `alice`, `roles`: []string{`admin`}}
+
+	tok := jwttest.New(t, claims)
+	jwttest.RequireValid(t, tok)
+	jwttest.RequireClaim(t, tok, jwt.SubjectKey, `alice`)
+
+	jwttest.RequireInvalid(t, jwttest.Expired(t, claims), jwt.ErrTokenExpired())
+	jwttest.RequireInvalid(t, jwttest.NotYetValid(t, claims), jwt.ErrTokenNotYetValid())
+	jwttest.RequireInvalid(t, tok, jwt.ErrInvalidAudience(), jwt.WithAudience(`api`))
+	jwttest.RequireInvalid(t, tok, nil, jwt.WithAudience(`api`))
+
+	r := &recorder{TB: t}
+	jwttest.RequireInvalid(r, tok, nil)
+	require.True(t, r.failed, `valid tokens should fail jwttest.RequireInvalid`)
+
+	r = &recorder{TB: t}
+	jwttest.RequireInvalid(r, jwttest.Expired(t, nil), jwt.ErrTokenNotYetValid())
+	require.True(t, r.failed, `mismatching errors should fail jwttest.RequireInvalid`)
+
+	r = &recorder{TB: t}
+	jwttest.RequireValid(r, jwttest.Expired(t, nil))
+	require.True(t, r.failed, `expired tokens should fail jwttest.RequireValid`)
+}
+
+func TestRequireClaim(t *testing.T) {
+	tok := jwttest.New(t, map[string]interface{}{
+		jwt.SubjectKey:  `alice`,
+		jwt.AudienceKey: []string{`api`},
+		`roles`:         []string{`admin`},
+	})
+	signed := jwttest.Sign(t, tok, jwa.HS256)
+	parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, jwttest.PublicKey(t, jwa.HS256)))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+
+	jwttest.RequireClaim(t, parsed, jwt.AudienceKey, []string{`api`})
+	jwttest.RequireClaim(t, parsed, `roles`, []string{`admin`})
+	jwttest.RequireClaim(t, parsed, jwt.IssuedAtKey, tok.IssuedAt())
+	jwttest.RequireNoClaim(t, parsed, jwt.IssuerKey)
+
+	testcases := []struct {
+		Name  string
+		Check func(testing.TB)
+	}{
+		{Name: `different value`, Check: func(r testing.TB) { jwttest.RequireClaim(r, parsed, jwt.SubjectKey, `bob`) }},
+		{Name: `missing claim`, Check: func(r testing.TB) { jwttest.RequireClaim(r, parsed, jwt.IssuerKey, `alice`) }},
+		{Name: `existing claim`, Check: func(r testing.TB) { jwttest.RequireNoClaim(r, parsed, jwt.SubjectKey) }},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			tc.Check(r)
+			require.True(t, r.failed, `assertion should fail`)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package jwt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
+)
+
+// Namespace is a prefix that is prepended to the names of custom claims to
+// avoid collisions, usually a URL controlled by the issuer. For example,
+// Auth0 requires custom claims to be namespaced, as in
+// `https://example.com/roles`:
+//
+//	ns := jwt.Namespace(`https://example.com`)
+//	roles, ok := ns.Get(tok, `roles`) // reads "https://example.com/roles"
+//
+// If the namespace does not end with "/" or ":", a "/" is inserted between
+// the namespace and the claim name.
+type Namespace string
+
+// Key returns the full name of the claim `name` in the namespace.
+func (ns Namespace) Key(name string) string {
+	s := string(ns)
+	if s == `` || strings.HasSuffix(s, `/`) || strings.HasSuffix(s, `:`) {
+		return s + name
+	}
+	return s + `/` + name
+}
+
+// prefix returns the prefix shared by all claims in the namespace
+func (ns Namespace) prefix() string {
+	return ns.Key(``)
+}
+
+// Get returns the value of the claim `name` in the namespace.
+func (ns Namespace) Get(t Token, name string) (interface{}, bool) {
+	return t.Get(ns.Key(name))
+}
+
+// Set sets the value of the claim `name` in the namespace.
+func (ns Namespace) Set(t Token, name string, value interface{}) error {
+	return t.Set(ns.Key(name), value)
+}
+
+// Remove removes the claim `name` in the namespace.
+func (ns Namespace) Remove(t Token, name string) error {
+	return t.Remove(ns.Key(name))
+}
+
+// Names returns the names of the claims of `t` in the namespace, without
+// the namespace, in sorted order.
+func (ns Namespace) Names(t Token) []string {
+	prefix := ns.prefix()
+	var names []string
+	for key := range t.PrivateClaims() {
+		if name := strings.TrimPrefix(key, prefix); name != key && name != `` {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Claims returns the claims of `t` in the namespace, keyed by their names
+// without the namespace.
+func (ns Namespace) Claims(t Token) map[string]interface{} {
+	names := ns.Names(t)
+	m := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		if v, ok := ns.Get(t, name); ok {
+			m[name] = v
+		}
+	}
+	return m
+}
+
+// Decode assigns the claims of `t` in the namespace to the fields of the
+// struct pointed to by `dst`, in the same manner as `jwt.Decode()`.
+// The names in the `json` struct tags do not include the namespace:
+//
+//	type AppClaims struct {
+//	  Roles  []string `json:"roles"`
+//	  Tenant string   `json:"tenant"`
+//	}
+//
+//	var c AppClaims
+//	err := jwt.Namespace(`https://example.com`).Decode(tok, &c)
+func (ns Namespace) Decode(t Token, dst interface{}) error {
+	if err := claims.Decode(ns.Claims(t), dst); err != nil {
+		return fmt.Errorf(`jwt.Namespace.Decode: %w`, err)
+	}
+	return nil
+}
+
+// Encode sets the claims in the namespace from the fields of the struct
+// `v` (or a pointer to it), in the same manner as `jwt.FromStruct()`.
+// `t` is not modified if any of the claims cannot be set.
+func (ns Namespace) Encode(t Token, v interface{}) error {
+	names, values, err := claims.Encode(v)
+	if err != nil {
+		return fmt.Errorf(`jwt.Namespace.Encode: %w`, err)
+	}
+
+	// Validate all of the values before modifying the token
+	tmp := New()
+	for i, name := range names {
+		if err := tmp.Set(ns.Key(name), values[i]); err != nil {
+			return fmt.Errorf(`jwt.Namespace.Encode: failed to set %q: %w`, ns.Key(name), err)
+		}
+	}
+	for i, name := range names {
+		if err := t.Set(ns.Key(name), values[i]); err != nil {
+			return fmt.Errorf(`jwt.Namespace.Encode: failed to set %q: %w`, ns.Key(name), err)
+		}
+	}
+	return nil
+}
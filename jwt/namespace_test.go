@@ -0,0 +1,86 @@
+package jwt_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Key", func(t *testing.T) {
+		t.Parallel()
+		require.Equal(t, `https://example.com/roles`, jwt.Namespace(`https://example.com`).Key(`roles`))
+		require.Equal(t, `https://example.com/roles`, jwt.Namespace(`https://example.com/`).Key(`roles`))
+		require.Equal(t, `urn:example:roles`, jwt.Namespace(`urn:example:`).Key(`roles`))
+		require.Equal(t, `roles`, jwt.Namespace(``).Key(`roles`))
+	})
+	t.Run("Get/Set/Remove", func(t *testing.T) {
+		t.Parallel()
+		ns := jwt.Namespace(`https://example.com`)
+		tok := jwt.New()
+		require.NoError(t, ns.Set(tok, `tenant`, `acme`), `ns.Set should succeed`)
+
+		v, ok := tok.Get(`https://example.com/tenant`)
+		require.True(t, ok, `claim should be set with the namespace`)
+		require.Equal(t, `acme`, v)
+
+		v, ok = ns.Get(tok, `tenant`)
+		require.True(t, ok, `ns.Get should succeed`)
+		require.Equal(t, `acme`, v)
+
+		require.NoError(t, ns.Remove(tok, `tenant`), `ns.Remove should succeed`)
+		_, ok = ns.Get(tok, `tenant`)
+		require.False(t, ok, `claim should be removed`)
+	})
+	t.Run("Names/Claims", func(t *testing.T) {
+		t.Parallel()
+		ns := jwt.Namespace(`https://example.com/`)
+		tok, err := jwt.NewBuilder().
+			Subject(`alice`).
+			Claim(`https://example.com/roles`, []string{`admin`}).
+			Claim(`https://example.com/tenant`, `acme`).
+			Claim(`https://other.example.com/tenant`, `other`).
+			Claim(`tenant`, `plain`).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+
+		require.Equal(t, []string{`roles`, `tenant`}, ns.Names(tok))
+		require.Equal(t, map[string]interface{}{
+			`roles`:  []string{`admin`},
+			`tenant`: `acme`,
+		}, ns.Claims(tok))
+		require.Empty(t, jwt.Namespace(`https://unused.example.com`).Claims(tok))
+	})
+	t.Run("Decode/Encode", func(t *testing.T) {
+		t.Parallel()
+		type AppClaims struct {
+			Roles  []string `json:"roles"`
+			Tenant string   `json:"tenant"`
+			Level  int      `json:"level,omitempty"`
+		}
+
+		ns := jwt.Namespace(`https://example.com`)
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+		require.NoError(t, ns.Encode(tok, AppClaims{Roles: []string{`admin`, `editor`}, Tenant: `acme`}), `ns.Encode should succeed`)
+		_, ok := ns.Get(tok, `level`)
+		require.False(t, ok, `omitempty should be honored`)
+
+		// Round-trip through serialization, so that the values are decoded
+		// from JSON
+		buf, err := jwt.NewSerializer().Serialize(tok)
+		require.NoError(t, err, `Serialize should succeed`)
+		parsed, err := jwt.ParseInsecure(buf)
+		require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+
+		var c AppClaims
+		require.NoError(t, ns.Decode(parsed, &c), `ns.Decode should succeed`)
+		require.Equal(t, AppClaims{Roles: []string{`admin`, `editor`}, Tenant: `acme`}, c)
+
+		require.Error(t, ns.Encode(tok, `not a struct`), `ns.Encode should fail for non-structs`)
+		require.Error(t, ns.Decode(parsed, c), `ns.Decode should fail for non-pointers`)
+	})
+}
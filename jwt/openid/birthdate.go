@@ -6,6 +6,7 @@ import (
 	"io"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2/internal/json"
 )
@@ -17,6 +18,11 @@ import (
 // format is allowed. Note that depending on the underlying platform's date related function,
 // providing just year can result in varying month and day, so the implementers need to
 // take this factor into account to correctly process the dates.
+//
+// BirthdateClaim keeps track of which parts of the date were provided:
+// use `HasYear()` and `HasMonthDay()` to tell them apart, instead of
+// comparing `Year()`, `Month()` or `Day()` against 0. An empty string is
+// accepted as a birthdate with no parts.
 
 type BirthdateClaim struct {
 	year  *int
@@ -62,9 +68,12 @@ func tointptr(v int64) *int {
 	return &i
 }
 
-var birthdateRx = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+var birthdateRx = regexp.MustCompile(`^(\d{4})(?:-(\d{2})-(\d{2}))?$`)
 
 // Accepts a value read from JSON, and converts it to a BirthdateClaim.
+// The value may be in YYYY-MM-DD, 0000-MM-DD (year omitted), or YYYY
+// (year only) format, or an empty string.
+//
 // This method DOES NOT verify the correctness of a date.
 // Consumers should check for validity of dates such as Apr 31 et al
 func (b *BirthdateClaim) Accept(v interface{}) error {
@@ -87,6 +96,10 @@ func (b *BirthdateClaim) Accept(v interface{}) error {
 		}
 		return nil
 	case string:
+		if v == "" {
+			return nil
+		}
+
 		// yeah, yeah, regexp is slow. PR's welcome
 		indices := birthdateRx.FindStringSubmatchIndex(v)
 		if indices == nil {
@@ -96,27 +109,33 @@ func (b *BirthdateClaim) Accept(v interface{}) error {
 
 		// Okay, this really isn't kosher, but we're doing this for
 		// the coverage game... Because birthdateRx already checked that
-		// the string contains 3 strings with consecutive decimal values
+		// the string contains up to 3 strings with consecutive decimal values
 		// we can assume that strconv.ParseInt always succeeds.
 		// strconv.ParseInt (and strconv.ParseUint that it uses internally)
 		// only returns range errors, so we should be safe.
 		year, _ := strconv.ParseInt(v[indices[2]:indices[3]], 10, 64)
-		if year <= 0 {
+		hasMonthDay := indices[4] >= 0
+		if year < 0 || (year == 0 && !hasMonthDay) {
 			return fmt.Errorf(`failed to parse birthdate year`)
 		}
-		tmp.year = tointptr(year)
-
-		month, _ := strconv.ParseInt(v[indices[4]:indices[5]], 10, 64)
-		if month <= 0 {
-			return fmt.Errorf(`failed to parse birthdate month`)
+		// year 0000 means that the year is omitted
+		if year > 0 {
+			tmp.year = tointptr(year)
 		}
-		tmp.month = tointptr(month)
 
-		day, _ := strconv.ParseInt(v[indices[6]:indices[7]], 10, 64)
-		if day <= 0 {
-			return fmt.Errorf(`failed to parse birthdate day`)
+		if hasMonthDay {
+			month, _ := strconv.ParseInt(v[indices[4]:indices[5]], 10, 64)
+			if month <= 0 {
+				return fmt.Errorf(`failed to parse birthdate month`)
+			}
+			tmp.month = tointptr(month)
+
+			day, _ := strconv.ParseInt(v[indices[6]:indices[7]], 10, 64)
+			if day <= 0 {
+				return fmt.Errorf(`failed to parse birthdate day`)
+			}
+			tmp.day = tointptr(day)
 		}
-		tmp.day = tointptr(day)
 
 		*b = tmp
 		return nil
@@ -125,8 +144,49 @@ func (b *BirthdateClaim) Accept(v interface{}) error {
 	}
 }
 
+// HasYear returns true if the birthdate contains the year, i.e. it was
+// not specified as 0000-MM-DD.
+func (b BirthdateClaim) HasYear() bool {
+	return b.year != nil
+}
+
+// HasMonthDay returns true if the birthdate contains the month and the
+// day, i.e. it was not specified as YYYY.
+func (b BirthdateClaim) HasMonthDay() bool {
+	return b.month != nil && b.day != nil
+}
+
+// Age returns the age in full years on the date of `t`, and whether it
+// could be determined. If the year is omitted, the age cannot be determined.
+//
+// If only the year is known, the smallest possible age is returned, as if
+// the End-User was born on December 31st. This is the conservative choice
+// for age verification. Those born on February 29th are considered to
+// have their birthday on March 1st in non-leap years.
+func (b BirthdateClaim) Age(t time.Time) (int, bool) {
+	if !b.HasYear() {
+		return 0, false
+	}
+
+	month, day := 12, 31
+	if b.HasMonthDay() {
+		month, day = b.Month(), b.Day()
+	}
+
+	age := t.Year() - b.Year()
+	if tm := int(t.Month()); tm < month || (tm == month && t.Day() < day) {
+		age--
+	}
+	return age, true
+}
+
 func (b BirthdateClaim) encode(dst io.Writer) {
-	fmt.Fprintf(dst, "%04d-%02d-%02d", b.Year(), b.Month(), b.Day())
+	switch {
+	case b.HasMonthDay():
+		fmt.Fprintf(dst, "%04d-%02d-%02d", b.Year(), b.Month(), b.Day())
+	case b.HasYear():
+		fmt.Fprintf(dst, "%04d", b.Year())
+	}
 }
 
 func (b BirthdateClaim) String() string {
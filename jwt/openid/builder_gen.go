@@ -4,6 +4,7 @@ package openid
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -28,18 +29,42 @@ func (b *Builder) Claim(name string, value interface{}) *Builder {
 	return b
 }
 
+func (b *Builder) AccessTokenHash(v string) *Builder {
+	return b.Claim(AccessTokenHashKey, v)
+}
+
+func (b *Builder) ACR(v string) *Builder {
+	return b.Claim(ACRKey, v)
+}
+
 func (b *Builder) Address(v *AddressClaim) *Builder {
 	return b.Claim(AddressKey, v)
 }
 
+func (b *Builder) AMR(v []string) *Builder {
+	return b.Claim(AMRKey, v)
+}
+
 func (b *Builder) Audience(v []string) *Builder {
 	return b.Claim(AudienceKey, v)
 }
 
+func (b *Builder) AuthTime(v time.Time) *Builder {
+	return b.Claim(AuthTimeKey, v)
+}
+
+func (b *Builder) AuthorizedParty(v string) *Builder {
+	return b.Claim(AuthorizedPartyKey, v)
+}
+
 func (b *Builder) Birthdate(v *BirthdateClaim) *Builder {
 	return b.Claim(BirthdateKey, v)
 }
 
+func (b *Builder) CodeHash(v string) *Builder {
+	return b.Claim(CodeHashKey, v)
+}
+
 func (b *Builder) Email(v string) *Builder {
 	return b.Claim(EmailKey, v)
 }
@@ -92,6 +117,10 @@ func (b *Builder) Nickname(v string) *Builder {
 	return b.Claim(NicknameKey, v)
 }
 
+func (b *Builder) Nonce(v string) *Builder {
+	return b.Claim(NonceKey, v)
+}
+
 func (b *Builder) NotBefore(v time.Time) *Builder {
 	return b.Claim(NotBeforeKey, v)
 }
@@ -133,14 +162,20 @@ func (b *Builder) Zoneinfo(v string) *Builder {
 }
 
 // Build creates a new token based on the claims that the builder has received
-// so far. If a claim cannot be set, then the method returns a nil Token with
-// a en error as a second return value
+// so far. The values are validated against the types required by each claim
+// (e.g. `exp` must be a time value). If any of the claims cannot be set, then
+// the method returns a nil Token with an error describing all of the invalid
+// claims as a second return value
 func (b *Builder) Build() (Token, error) {
 	tok := New()
+	var errs []string
 	for _, claim := range b.claims {
 		if err := tok.Set(claim.Key.(string), claim.Value); err != nil {
-			return nil, fmt.Errorf(`failed to set claim %q: %w`, claim.Key.(string), err)
+			errs = append(errs, fmt.Sprintf(`failed to set claim %q: %s`, claim.Key.(string), err))
 		}
 	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf(`failed to build token: %s`, strings.Join(errs, `, `))
+	}
 	return tok, nil
 }
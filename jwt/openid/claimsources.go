@@ -0,0 +1,302 @@
+package openid
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Names of the claims that describe aggregated and distributed claims
+// (OpenID Connect Core 1.0 Section 5.6.2)
+const (
+	ClaimNamesKey   = `_claim_names`
+	ClaimSourcesKey = `_claim_sources`
+)
+
+// maxClaimSourceSize is the maximum size of the response from the
+// endpoint of a distributed claims source
+const maxClaimSourceSize = 1 << 20
+
+// ClaimSource describes a source of aggregated or distributed claims.
+type ClaimSource struct {
+	// JWT contains the JWT that carries aggregated claims. It is empty
+	// for distributed claims.
+	JWT string
+	// Endpoint is the URL from which distributed claims are retrieved.
+	// It is empty for aggregated claims.
+	Endpoint string
+	// AccessToken is the access token that is sent to Endpoint as a
+	// bearer token, if any.
+	AccessToken string
+}
+
+// IsDistributed reports whether the claims must be retrieved from
+// the endpoint of the source.
+func (src ClaimSource) IsDistributed() bool {
+	return src.JWT == `` && src.Endpoint != ``
+}
+
+// HTTPClient is the interface of the HTTP client used to retrieve
+// distributed claims. *http.Client satisfies this interface.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// ClaimSourceOptionsFunc returns the options passed to `jwt.Parse()` to
+// verify and validate the JWT obtained from the claims source `name`. The
+// options should contain the keys of the Claims Provider that signed it
+// (e.g. `jwt.WithKeySet()`), and may be chosen based on `src`.
+type ClaimSourceOptionsFunc func(name string, src ClaimSource) ([]jwt.ParseOption, error)
+
+// ClaimSources returns the aggregated and distributed claims described
+// by the `_claim_names` and `_claim_sources` claims of `t`: `names` maps
+// the name of each claim to the name of its source, and `sources` maps
+// the name of each source to its description. Both are empty if `t` does
+// not contain aggregated or distributed claims.
+func ClaimSources(t jwt.Token) (names map[string]string, sources map[string]ClaimSource, err error) {
+	names = make(map[string]string)
+	sources = make(map[string]ClaimSource)
+
+	if v, ok := t.Get(ClaimNamesKey); ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf(`openid.ClaimSources: %q must be a JSON object`, ClaimNamesKey)
+		}
+		for name, source := range m {
+			s, ok := source.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf(`openid.ClaimSources: source of claim %q must be a string`, name)
+			}
+			names[name] = s
+		}
+	}
+
+	if v, ok := t.Get(ClaimSourcesKey); ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf(`openid.ClaimSources: %q must be a JSON object`, ClaimSourcesKey)
+		}
+		for name, desc := range m {
+			fields, ok := desc.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf(`openid.ClaimSources: source %q must be a JSON object`, name)
+			}
+			var src ClaimSource
+			for key, dst := range map[string]*string{`JWT`: &src.JWT, `endpoint`: &src.Endpoint, `access_token`: &src.AccessToken} {
+				if fv, ok := fields[key]; ok {
+					s, ok := fv.(string)
+					if !ok {
+						return nil, nil, fmt.Errorf(`openid.ClaimSources: member %q of source %q must be a string`, key, name)
+					}
+					*dst = s
+				}
+			}
+			if src.JWT == `` && src.Endpoint == `` {
+				return nil, nil, fmt.Errorf(`openid.ClaimSources: source %q must contain either "JWT" or "endpoint"`, name)
+			}
+			sources[name] = src
+		}
+	}
+
+	for name, source := range names {
+		if _, ok := sources[source]; !ok {
+			return nil, nil, fmt.Errorf(`openid.ClaimSources: claim %q refers to unknown source %q`, name, source)
+		}
+	}
+	return names, sources, nil
+}
+
+// ResolveClaims materializes the aggregated and distributed claims of `t`
+// (OpenID Connect Core 1.0 Section 5.6.2), such as an ID Token or a
+// UserInfo response: the JWT of each source is verified using the options
+// returned by the function specified via `openid.WithClaimSourceOptions()`,
+// and the claims listed in `_claim_names` are copied from it to `t`.
+//
+//	err := openid.ResolveClaims(ctx, tok,
+//	  openid.WithClaimSourceOptions(func(name string, _ openid.ClaimSource) ([]jwt.ParseOption, error) {
+//	    return []jwt.ParseOption{jwt.WithKeySet(providerKeys[name])}, nil
+//	  }),
+//	  openid.WithHTTPClient(http.DefaultClient),
+//	)
+//
+// Distributed claims are retrieved from the endpoint of their source only
+// if `openid.WithHTTPClient()` is specified; otherwise they are left
+// unresolved. Resolved claims are removed from `_claim_names`, and resolved
+// sources from `_claim_sources`. Both claims are removed once all claims
+// have been resolved.
+//
+// `t` is not modified if any of the sources cannot be resolved. It is
+// the caller's responsibility to verify `t` itself before calling this
+// function.
+func ResolveClaims(ctx context.Context, t jwt.Token, options ...ResolveOption) error {
+	var optionsFunc ClaimSourceOptionsFunc
+	var client HTTPClient
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identClaimSourceOptions{}:
+			optionsFunc = o.Value().(ClaimSourceOptionsFunc)
+		case identHTTPClient{}:
+			client, _ = o.Value().(HTTPClient)
+		}
+	}
+	if optionsFunc == nil {
+		return fmt.Errorf(`openid.ResolveClaims: openid.WithClaimSourceOptions() must be specified`)
+	}
+
+	names, sources, err := ClaimSources(t)
+	if err != nil {
+		return fmt.Errorf(`openid.ResolveClaims: %w`, err)
+	}
+
+	// Group the claims by source, in a stable order
+	bySource := make(map[string][]string)
+	for name, source := range names {
+		bySource[source] = append(bySource[source], name)
+	}
+	sourceNames := make([]string, 0, len(sources))
+	for name := range sources {
+		sourceNames = append(sourceNames, name)
+	}
+	sort.Strings(sourceNames)
+
+	resolved := make(map[string]interface{})
+	for _, sourceName := range sourceNames {
+		src := sources[sourceName]
+		if len(bySource[sourceName]) == 0 {
+			// not referenced by any claim
+			delete(sources, sourceName)
+			continue
+		}
+		if src.IsDistributed() && client == nil {
+			continue
+		}
+
+		claims, err := resolveClaimSource(ctx, client, optionsFunc, sourceName, src)
+		if err != nil {
+			return fmt.Errorf(`openid.ResolveClaims: source %q: %w`, sourceName, err)
+		}
+		for _, name := range bySource[sourceName] {
+			v, ok := claims.Get(name)
+			if !ok {
+				return fmt.Errorf(`openid.ResolveClaims: source %q does not contain claim %q`, sourceName, name)
+			}
+			resolved[name] = v
+			delete(names, name)
+		}
+		delete(sources, sourceName)
+	}
+
+	// The resolved claims are applied to a clone of `t` first, so that `t`
+	// is left untouched if any of them are rejected (e.g. a value of the
+	// wrong type for one of the standard claims)
+	clone, err := t.Clone()
+	if err != nil {
+		return fmt.Errorf(`openid.ResolveClaims: failed to clone token: %w`, err)
+	}
+	if err := applyResolvedClaims(clone, resolved, names, sources); err != nil {
+		return err
+	}
+	return applyResolvedClaims(t, resolved, names, sources)
+}
+
+func applyResolvedClaims(t jwt.Token, resolved map[string]interface{}, names map[string]string, sources map[string]ClaimSource) error {
+	for name, v := range resolved {
+		if err := t.Set(name, v); err != nil {
+			return fmt.Errorf(`openid.ResolveClaims: failed to set claim %q: %w`, name, err)
+		}
+	}
+	return updateClaimSources(t, names, sources)
+}
+
+func resolveClaimSource(ctx context.Context, client HTTPClient, optionsFunc ClaimSourceOptionsFunc, name string, src ClaimSource) (jwt.Token, error) {
+	options, err := optionsFunc(name, src)
+	if err != nil {
+		return nil, err
+	}
+
+	serialized := []byte(src.JWT)
+	if src.IsDistributed() {
+		serialized, err = fetchClaimSource(ctx, client, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tok, err := jwt.Parse(serialized, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to verify claims: %w`, err)
+	}
+	return tok, nil
+}
+
+func fetchClaimSource(ctx context.Context, client HTTPClient, src ClaimSource) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.Endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to create request: %w`, err)
+	}
+	req.Header.Set(`Accept`, UserInfoJWTContentType)
+	if src.AccessToken != `` {
+		req.Header.Set(`Authorization`, `Bearer `+src.AccessToken)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to query %q: %w`, src.Endpoint, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`failed to query %q: unexpected status code %d`, src.Endpoint, res.StatusCode)
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxClaimSourceSize))
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read response: %w`, err)
+	}
+	return buf, nil
+}
+
+// updateClaimSources replaces the `_claim_names` and `_claim_sources`
+// claims of `t` with the unresolved claims and sources
+func updateClaimSources(t jwt.Token, names map[string]string, sources map[string]ClaimSource) error {
+	if len(names) == 0 {
+		for _, key := range []string{ClaimNamesKey, ClaimSourcesKey} {
+			if err := t.Remove(key); err != nil {
+				return fmt.Errorf(`openid.ResolveClaims: failed to remove claim %q: %w`, key, err)
+			}
+		}
+		return nil
+	}
+
+	namesClaim := make(map[string]interface{}, len(names))
+	for name, source := range names {
+		namesClaim[name] = source
+	}
+	sourcesClaim := make(map[string]interface{}, len(sources))
+	for name, src := range sources {
+		desc := make(map[string]interface{})
+		if src.JWT != `` {
+			desc[`JWT`] = src.JWT
+		}
+		if src.Endpoint != `` {
+			desc[`endpoint`] = src.Endpoint
+		}
+		if src.AccessToken != `` {
+			desc[`access_token`] = src.AccessToken
+		}
+		sourcesClaim[name] = desc
+	}
+
+	if err := t.Set(ClaimNamesKey, namesClaim); err != nil {
+		return fmt.Errorf(`openid.ResolveClaims: failed to set claim %q: %w`, ClaimNamesKey, err)
+	}
+	if err := t.Set(ClaimSourcesKey, sourcesClaim); err != nil {
+		return fmt.Errorf(`openid.ResolveClaims: failed to set claim %q: %w`, ClaimSourcesKey, err)
+	}
+	return nil
+}
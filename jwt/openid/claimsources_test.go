@@ -0,0 +1,191 @@
+package openid_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveClaims(t *testing.T) {
+	aggregatedKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	distributedKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	signClaims := func(t *testing.T, alg jwa.SignatureAlgorithm, key interface{}, claims map[string]interface{}) string {
+		t.Helper()
+		b := jwt.NewBuilder()
+		for k, v := range claims {
+			b.Claim(k, v)
+		}
+		tok, err := b.Build()
+		require.NoError(t, err, `Build should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(alg, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return string(signed)
+	}
+
+	aggregated := signClaims(t, jwa.ES256, aggregatedKey, map[string]interface{}{
+		openid.AddressKey:     map[string]interface{}{`country`: `US`},
+		openid.PhoneNumberKey: `+1 (310) 123-4567`,
+	})
+	distributed := signClaims(t, jwa.RS256, distributedKey, map[string]interface{}{
+		`payment_info`:     `Some_Card`,
+		`shipping_address`: `9876 Elm St.`,
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(`Authorization`) != `Bearer ksj3n283dke` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set(`Content-Type`, `application/jwt`)
+		fmt.Fprint(w, distributed)
+	}))
+	defer srv.Close()
+
+	newToken := func(t *testing.T) openid.Token {
+		t.Helper()
+		tok := openid.New()
+		require.NoError(t, tok.Set(jwt.SubjectKey, `248289761001`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.ClaimNamesKey, map[string]interface{}{
+			openid.AddressKey:     `src1`,
+			openid.PhoneNumberKey: `src1`,
+			`payment_info`:        `src2`,
+			`shipping_address`:    `src2`,
+		}), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.ClaimSourcesKey, map[string]interface{}{
+			`src1`: map[string]interface{}{`JWT`: aggregated},
+			`src2`: map[string]interface{}{`endpoint`: srv.URL, `access_token`: `ksj3n283dke`},
+		}), `tok.Set should succeed`)
+		return tok
+	}
+
+	sourceOptions := openid.WithClaimSourceOptions(func(name string, _ openid.ClaimSource) ([]jwt.ParseOption, error) {
+		switch name {
+		case `src1`:
+			return []jwt.ParseOption{jwt.WithKey(jwa.ES256, &aggregatedKey.PublicKey)}, nil
+		case `src2`:
+			return []jwt.ParseOption{jwt.WithKey(jwa.RS256, &distributedKey.PublicKey)}, nil
+		}
+		return nil, fmt.Errorf(`unknown source %q`, name)
+	})
+
+	t.Run("ClaimSources", func(t *testing.T) {
+		names, sources, err := openid.ClaimSources(newToken(t))
+		require.NoError(t, err, `openid.ClaimSources should succeed`)
+		require.Equal(t, `src2`, names[`payment_info`])
+		require.False(t, sources[`src1`].IsDistributed())
+		require.True(t, sources[`src2`].IsDistributed())
+		require.Equal(t, `ksj3n283dke`, sources[`src2`].AccessToken)
+	})
+	t.Run("aggregated and distributed", func(t *testing.T) {
+		tok := newToken(t)
+		err := openid.ResolveClaims(context.Background(), tok, sourceOptions, openid.WithHTTPClient(srv.Client()))
+		require.NoError(t, err, `openid.ResolveClaims should succeed`)
+
+		require.Equal(t, `US`, tok.Address().Country())
+		require.Equal(t, `+1 (310) 123-4567`, tok.PhoneNumber())
+		v, ok := tok.Get(`payment_info`)
+		require.True(t, ok, `payment_info should exist`)
+		require.Equal(t, `Some_Card`, v)
+
+		_, ok = tok.Get(openid.ClaimNamesKey)
+		require.False(t, ok, `_claim_names should be removed`)
+		_, ok = tok.Get(openid.ClaimSourcesKey)
+		require.False(t, ok, `_claim_sources should be removed`)
+	})
+	t.Run("aggregated only", func(t *testing.T) {
+		tok := newToken(t)
+		err := openid.ResolveClaims(context.Background(), tok, sourceOptions)
+		require.NoError(t, err, `openid.ResolveClaims should succeed`)
+
+		require.Equal(t, `+1 (310) 123-4567`, tok.PhoneNumber())
+		_, ok := tok.Get(`payment_info`)
+		require.False(t, ok, `distributed claims should not be resolved`)
+
+		names, sources, err := openid.ClaimSources(tok)
+		require.NoError(t, err, `openid.ClaimSources should succeed`)
+		require.Equal(t, map[string]string{`payment_info`: `src2`, `shipping_address`: `src2`}, names)
+		require.Len(t, sources, 1)
+		require.Equal(t, srv.URL, sources[`src2`].Endpoint)
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		tok := newToken(t)
+		err := openid.ResolveClaims(context.Background(), tok,
+			openid.WithClaimSourceOptions(func(string, openid.ClaimSource) ([]jwt.ParseOption, error) {
+				return []jwt.ParseOption{jwt.WithKey(jwa.ES256, &aggregatedKey.PublicKey)}, nil
+			}),
+			openid.WithHTTPClient(srv.Client()),
+		)
+		require.Error(t, err, `openid.ResolveClaims should fail`)
+
+		// the token is left untouched
+		require.Empty(t, tok.PhoneNumber())
+		_, ok := tok.Get(openid.ClaimSourcesKey)
+		require.True(t, ok, `_claim_sources should be kept`)
+	})
+	t.Run("missing claim", func(t *testing.T) {
+		tok := newToken(t)
+		names, _, err := openid.ClaimSources(tok)
+		require.NoError(t, err, `openid.ClaimSources should succeed`)
+		names[openid.EmailKey] = `src1`
+		m := make(map[string]interface{})
+		for k, v := range names {
+			m[k] = v
+		}
+		require.NoError(t, tok.Set(openid.ClaimNamesKey, m), `tok.Set should succeed`)
+
+		err = openid.ResolveClaims(context.Background(), tok, sourceOptions)
+		require.Error(t, err, `openid.ResolveClaims should fail when a claim is missing from its source`)
+	})
+	t.Run("invalid claim value", func(t *testing.T) {
+		// email_verified must be a boolean
+		invalid := signClaims(t, jwa.ES256, aggregatedKey, map[string]interface{}{
+			openid.EmailVerifiedKey: `yes`,
+		})
+		tok := openid.New()
+		require.NoError(t, tok.Set(openid.ClaimNamesKey, map[string]interface{}{
+			openid.PhoneNumberKey:   `src1`,
+			openid.EmailVerifiedKey: `src3`,
+		}), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.ClaimSourcesKey, map[string]interface{}{
+			`src1`: map[string]interface{}{`JWT`: aggregated},
+			`src3`: map[string]interface{}{`JWT`: invalid},
+		}), `tok.Set should succeed`)
+
+		err := openid.ResolveClaims(context.Background(), tok,
+			openid.WithClaimSourceOptions(func(string, openid.ClaimSource) ([]jwt.ParseOption, error) {
+				return []jwt.ParseOption{jwt.WithKey(jwa.ES256, &aggregatedKey.PublicKey)}, nil
+			}),
+		)
+		require.Error(t, err, `openid.ResolveClaims should fail`)
+
+		// the token is left untouched
+		require.Empty(t, tok.PhoneNumber())
+		names, _, err := openid.ClaimSources(tok)
+		require.NoError(t, err, `openid.ClaimSources should succeed`)
+		require.Len(t, names, 2)
+	})
+	t.Run("invalid descriptions", func(t *testing.T) {
+		tok := openid.New()
+		require.NoError(t, tok.Set(openid.ClaimNamesKey, map[string]interface{}{`payment_info`: `src3`}), `tok.Set should succeed`)
+		_, _, err := openid.ClaimSources(tok)
+		require.Error(t, err, `unknown sources should be rejected`)
+
+		require.NoError(t, tok.Set(openid.ClaimSourcesKey, map[string]interface{}{`src3`: map[string]interface{}{}}), `tok.Set should succeed`)
+		_, _, err = openid.ClaimSources(tok)
+		require.Error(t, err, `sources without JWT or endpoint should be rejected`)
+
+		err = openid.ResolveClaims(context.Background(), newToken(t))
+		require.Error(t, err, `openid.WithClaimSourceOptions should be required`)
+	})
+}
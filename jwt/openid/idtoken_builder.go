@@ -0,0 +1,182 @@
+package openid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// DefaultIDTokenLifetime is the default lifetime of the ID Tokens created
+// by `openid.IDTokenBuilder`, if the `exp` claim is not set explicitly.
+const DefaultIDTokenLifetime = time.Hour
+
+// MaxSubjectLength is the maximum length of the `sub` claim of an ID Token
+// (OpenID Connect Core 1.0 Section 2).
+const MaxSubjectLength = 255
+
+// IDTokenBuilder creates ID Tokens, and refuses to create tokens that
+// `openid.ValidateIDToken()` would reject for lack of mandatory claims:
+//
+//	tok, err := openid.NewIDTokenBuilder().
+//	  Issuer(`https://server.example.com`).
+//	  Subject(`24400320`).
+//	  Audience([]string{`s6BhdRkqt3`}).
+//	  Nonce(nonce).
+//	  Build()
+//
+// Unlike `openid.Builder`, Build sets the `iat` claim to the current time
+// and the `exp` claim to `iat` plus the lifetime specified via
+// `openid.WithIDTokenLifetime()` unless they are set explicitly, and
+// returns an error if
+//
+//   - any of the `iss`, `sub`, and `aud` claims is missing or empty,
+//   - the `sub` claim is longer than `openid.MaxSubjectLength`,
+//   - the `exp` claim is not after the `iat` claim, or
+//   - the `aud` claim contains multiple values, but `azp` is missing.
+type IDTokenBuilder struct {
+	builder  *Builder
+	lifetime time.Duration
+	clock    jwt.Clock
+}
+
+// NewIDTokenBuilder creates a new IDTokenBuilder.
+// `openid.WithIDTokenLifetime()` and `openid.WithIDTokenClock()` may be
+// specified as options.
+func NewIDTokenBuilder(options ...IDTokenBuilderOption) *IDTokenBuilder {
+	b := &IDTokenBuilder{
+		builder:  NewBuilder(),
+		lifetime: DefaultIDTokenLifetime,
+		clock:    jwt.ClockFunc(time.Now),
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identIDTokenLifetime{}:
+			b.lifetime = o.Value().(time.Duration)
+		case identIDTokenClock{}:
+			// a nil Clock is reported by Build()
+			b.clock, _ = o.Value().(jwt.Clock)
+		}
+	}
+	return b
+}
+
+// Claim sets the value of the claim `name` to `value`.
+func (b *IDTokenBuilder) Claim(name string, value interface{}) *IDTokenBuilder {
+	b.builder.Claim(name, value)
+	return b
+}
+
+func (b *IDTokenBuilder) Issuer(v string) *IDTokenBuilder {
+	return b.Claim(IssuerKey, v)
+}
+
+func (b *IDTokenBuilder) Subject(v string) *IDTokenBuilder {
+	return b.Claim(SubjectKey, v)
+}
+
+func (b *IDTokenBuilder) Audience(v []string) *IDTokenBuilder {
+	return b.Claim(AudienceKey, v)
+}
+
+func (b *IDTokenBuilder) Expiration(v time.Time) *IDTokenBuilder {
+	return b.Claim(ExpirationKey, v)
+}
+
+func (b *IDTokenBuilder) IssuedAt(v time.Time) *IDTokenBuilder {
+	return b.Claim(IssuedAtKey, v)
+}
+
+func (b *IDTokenBuilder) AuthTime(v time.Time) *IDTokenBuilder {
+	return b.Claim(AuthTimeKey, v)
+}
+
+func (b *IDTokenBuilder) Nonce(v string) *IDTokenBuilder {
+	return b.Claim(NonceKey, v)
+}
+
+func (b *IDTokenBuilder) ACR(v string) *IDTokenBuilder {
+	return b.Claim(ACRKey, v)
+}
+
+func (b *IDTokenBuilder) AMR(v []string) *IDTokenBuilder {
+	return b.Claim(AMRKey, v)
+}
+
+func (b *IDTokenBuilder) AuthorizedParty(v string) *IDTokenBuilder {
+	return b.Claim(AuthorizedPartyKey, v)
+}
+
+// AccessTokenHash sets the `at_hash` claim. Use `openid.HalfHash()` to
+// compute `v` from the access token.
+func (b *IDTokenBuilder) AccessTokenHash(v string) *IDTokenBuilder {
+	return b.Claim(AccessTokenHashKey, v)
+}
+
+// CodeHash sets the `c_hash` claim. Use `openid.HalfHash()` to compute
+// `v` from the authorization code.
+func (b *IDTokenBuilder) CodeHash(v string) *IDTokenBuilder {
+	return b.Claim(CodeHashKey, v)
+}
+
+// Build creates a new ID Token based on the claims that the builder has
+// received so far, after setting the default values of the `iat` and `exp`
+// claims. All missing and invalid claims are reported at once.
+func (b *IDTokenBuilder) Build() (Token, error) {
+	if b.clock == nil {
+		return nil, fmt.Errorf(`failed to build ID token: clock must not be nil`)
+	}
+	if b.lifetime <= 0 {
+		return nil, fmt.Errorf(`failed to build ID token: lifetime must be positive`)
+	}
+
+	tok, err := b.builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	iat := tok.IssuedAt()
+	if iat.IsZero() {
+		iat = b.clock.Now()
+		if err := tok.Set(IssuedAtKey, iat); err != nil {
+			return nil, fmt.Errorf(`failed to build ID token: failed to set %q: %w`, IssuedAtKey, err)
+		}
+	}
+	exp := tok.Expiration()
+	if exp.IsZero() {
+		exp = iat.Add(b.lifetime)
+		if err := tok.Set(ExpirationKey, exp); err != nil {
+			return nil, fmt.Errorf(`failed to build ID token: failed to set %q: %w`, ExpirationKey, err)
+		}
+	}
+
+	var errs []string
+	var missing []string
+	if tok.Issuer() == `` {
+		missing = append(missing, IssuerKey)
+	}
+	if tok.Subject() == `` {
+		missing = append(missing, SubjectKey)
+	}
+	if len(tok.Audience()) == 0 {
+		missing = append(missing, AudienceKey)
+	}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Sprintf(`missing required claims %q`, missing))
+	}
+	if len(tok.Subject()) > MaxSubjectLength {
+		errs = append(errs, fmt.Sprintf(`%q must not exceed %d characters`, SubjectKey, MaxSubjectLength))
+	}
+	if !exp.After(iat) {
+		errs = append(errs, fmt.Sprintf(`%q must be after %q`, ExpirationKey, IssuedAtKey))
+	}
+	if len(tok.Audience()) > 1 && tok.AuthorizedParty() == `` {
+		errs = append(errs, fmt.Sprintf(`%q is required when %q contains multiple values`, AuthorizedPartyKey, AudienceKey))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf(`failed to build ID token: %s`, strings.Join(errs, `, `))
+	}
+	return tok, nil
+}
@@ -0,0 +1,108 @@
+package openid_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDTokenBuilder(t *testing.T) {
+	const issuer = `https://server.example.com`
+	const clientID = `s6BhdRkqt3`
+	now := time.Unix(1311280970, 0)
+	clock := openid.WithIDTokenClock(jwt.ClockFunc(func() time.Time { return now }))
+
+	t.Run("defaults", func(t *testing.T) {
+		tok, err := openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID}).
+			Nonce(`n-0S6_WzA2Mj`).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		require.True(t, now.Equal(tok.IssuedAt()), `iat should be set to the current time`)
+		require.True(t, now.Add(openid.DefaultIDTokenLifetime).Equal(tok.Expiration()), `exp should be set according to the lifetime`)
+		require.Equal(t, `n-0S6_WzA2Mj`, tok.Nonce())
+
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID, openid.WithNonce(`n-0S6_WzA2Mj`), jwt.WithClock(jwt.ClockFunc(func() time.Time { return now }))), `openid.ValidateIDToken should succeed`)
+	})
+	t.Run("explicit times", func(t *testing.T) {
+		tok, err := openid.NewIDTokenBuilder(clock, openid.WithIDTokenLifetime(time.Minute)).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID}).
+			IssuedAt(now.Add(-time.Minute)).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		require.True(t, now.Add(-time.Minute).Equal(tok.IssuedAt()))
+		require.True(t, now.Equal(tok.Expiration()), `exp should be computed from the explicit iat`)
+
+		tok, err = openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID}).
+			Expiration(now.Add(5 * time.Minute)).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		require.True(t, now.Add(5*time.Minute).Equal(tok.Expiration()), `explicit exp should be kept`)
+	})
+	t.Run("missing claims", func(t *testing.T) {
+		_, err := openid.NewIDTokenBuilder(clock).Subject(`24400320`).Build()
+		require.Error(t, err, `Build should fail`)
+		require.Contains(t, err.Error(), `"iss"`)
+		require.Contains(t, err.Error(), `"aud"`)
+
+		_, err = openid.NewIDTokenBuilder(clock).Issuer(issuer).Audience([]string{clientID}).Build()
+		require.Error(t, err, `Build should fail without sub`)
+	})
+	t.Run("invalid claims", func(t *testing.T) {
+		_, err := openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(strings.Repeat(`x`, openid.MaxSubjectLength+1)).
+			Audience([]string{clientID}).
+			Build()
+		require.Error(t, err, `Build should fail for long subjects`)
+
+		_, err = openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID}).
+			Expiration(now.Add(-time.Minute)).
+			Build()
+		require.Error(t, err, `Build should fail when exp is before iat`)
+
+		_, err = openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID, `other`}).
+			Build()
+		require.Error(t, err, `Build should fail for multiple audiences without azp`)
+
+		_, err = openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID, `other`}).
+			AuthorizedParty(clientID).
+			Build()
+		require.NoError(t, err, `Build should succeed with azp`)
+
+		_, err = openid.NewIDTokenBuilder(clock).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID}).
+			Claim(openid.EmailVerifiedKey, `not a bool`).
+			Build()
+		require.Error(t, err, `Build should fail for claims with invalid values`)
+
+		_, err = openid.NewIDTokenBuilder(openid.WithIDTokenLifetime(0)).
+			Issuer(issuer).
+			Subject(`24400320`).
+			Audience([]string{clientID}).
+			Build()
+		require.Error(t, err, `Build should fail for non-positive lifetimes`)
+	})
+}
@@ -0,0 +1,109 @@
+package openid
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// LocaleSeparator separates the name of a claim from the language tag
+// of its localized variants, as in `family_name#ja-Kana-JP`
+// (OpenID Connect Core 1.0 Section 5.2).
+const LocaleSeparator = `#`
+
+// LocalizedKey returns the name of the variant of the claim `name` that
+// is localized for the BCP 47 language tag `tag`. If `tag` is empty,
+// `name` is returned as is.
+func LocalizedKey(name, tag string) string {
+	if tag == `` {
+		return name
+	}
+	return name + LocaleSeparator + tag
+}
+
+// Locales returns the language tags of the localized variants of the claim
+// `name` in `t`, in sorted order. The claim without a language tag is not
+// included.
+func Locales(t jwt.Token, name string) []string {
+	prefix := name + LocaleSeparator
+	var tags []string
+	for key := range t.PrivateClaims() {
+		if tag := strings.TrimPrefix(key, prefix); tag != key && tag != `` {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// LocalizedClaim returns the value of the variant of the claim `name` that
+// best matches the language tags in `preferred`, which are tried in order
+// of preference, along with its language tag.
+//
+// For each preferred tag, a variant with the same tag is looked up first,
+// followed by variants with less specific tags (RFC 4647 Section 3.4:
+// `ja-Kana-JP`, `ja-Kana`, then `ja`), and then any variant in the same
+// language. Tags are compared case-insensitively. If none of the variants
+// match, the claim without a language tag is returned, with an empty tag.
+func LocalizedClaim(t jwt.Token, name string, preferred ...string) (interface{}, string, bool) {
+	tags := Locales(t, name)
+	byTag := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		byTag[strings.ToLower(tag)] = tag
+	}
+
+	for _, want := range preferred {
+		if tag, ok := lookupLocale(byTag, tags, strings.ToLower(want)); ok {
+			if v, ok := t.Get(LocalizedKey(name, tag)); ok {
+				return v, tag, true
+			}
+		}
+	}
+
+	v, ok := t.Get(name)
+	return v, ``, ok
+}
+
+// LocalizedString is like `openid.LocalizedClaim()`, but returns only
+// string values.
+func LocalizedString(t jwt.Token, name string, preferred ...string) (string, bool) {
+	v, _, ok := LocalizedClaim(t, name, preferred...)
+	if !ok {
+		return ``, false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// lookupLocale returns the tag in `tags` that best matches `want`, which
+// must be in lower case. `byTag` maps the lower-cased tags to the tags.
+func lookupLocale(byTag map[string]string, tags []string, want string) (string, bool) {
+	for candidate := want; candidate != ``; {
+		if tag, ok := byTag[candidate]; ok {
+			return tag, true
+		}
+		i := strings.LastIndexByte(candidate, '-')
+		if i < 0 {
+			break
+		}
+		candidate = candidate[:i]
+		// single letter subtags (e.g. `x` in `en-x-private`) must not
+		// end the range
+		if j := strings.LastIndexByte(candidate, '-'); j >= 0 && len(candidate)-j == 2 {
+			candidate = candidate[:j]
+		}
+	}
+
+	language := want
+	if i := strings.IndexByte(want, '-'); i >= 0 {
+		language = want[:i]
+	}
+	for _, tag := range tags {
+		lower := strings.ToLower(tag)
+		if lower == language || strings.HasPrefix(lower, language+`-`) {
+			return tag, true
+		}
+	}
+	return ``, false
+}
@@ -0,0 +1,60 @@
+package openid_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizedClaims(t *testing.T) {
+	tok := openid.New()
+	for key, value := range map[string]string{
+		openid.FamilyNameKey: `Doe`,
+		openid.LocalizedKey(openid.FamilyNameKey, `ja-Kana-JP`): `ドウ`,
+		openid.LocalizedKey(openid.FamilyNameKey, `ja-Hani-JP`): `土`,
+		openid.LocalizedKey(openid.FamilyNameKey, `ko-KR`):      `도`,
+		openid.LocalizedKey(openid.GivenNameKey, `fr`):          `Jean`,
+	} {
+		require.NoError(t, tok.Set(key, value), `tok.Set should succeed`)
+	}
+
+	require.Equal(t, `family_name#ko-KR`, openid.LocalizedKey(openid.FamilyNameKey, `ko-KR`))
+	require.Equal(t, openid.FamilyNameKey, openid.LocalizedKey(openid.FamilyNameKey, ``))
+	require.Equal(t, []string{`ja-Hani-JP`, `ja-Kana-JP`, `ko-KR`}, openid.Locales(tok, openid.FamilyNameKey))
+	require.Empty(t, openid.Locales(tok, openid.EmailKey))
+
+	testcases := []struct {
+		Name      string
+		Claim     string
+		Preferred []string
+		Value     string
+		Tag       string
+		Found     bool
+	}{
+		{Name: `exact match`, Claim: openid.FamilyNameKey, Preferred: []string{`ja-Kana-JP`}, Value: `ドウ`, Tag: `ja-Kana-JP`, Found: true},
+		{Name: `case-insensitive`, Claim: openid.FamilyNameKey, Preferred: []string{`KO-kr`}, Value: `도`, Tag: `ko-KR`, Found: true},
+		{Name: `more specific preference`, Claim: openid.GivenNameKey, Preferred: []string{`fr-CA`}, Value: `Jean`, Tag: `fr`, Found: true},
+		{Name: `same language`, Claim: openid.FamilyNameKey, Preferred: []string{`ko`}, Value: `도`, Tag: `ko-KR`, Found: true},
+		{Name: `order of preference`, Claim: openid.FamilyNameKey, Preferred: []string{`de`, `ko-KR`, `ja-Kana-JP`}, Value: `도`, Tag: `ko-KR`, Found: true},
+		{Name: `fallback`, Claim: openid.FamilyNameKey, Preferred: []string{`de`}, Value: `Doe`, Found: true},
+		{Name: `no preference`, Claim: openid.FamilyNameKey, Value: `Doe`, Found: true},
+		{Name: `no fallback`, Claim: openid.GivenNameKey, Preferred: []string{`de`}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			v, tag, ok := openid.LocalizedClaim(tok, tc.Claim, tc.Preferred...)
+			require.Equal(t, tc.Found, ok)
+			if !tc.Found {
+				return
+			}
+			require.Equal(t, tc.Value, v)
+			require.Equal(t, tc.Tag, tag)
+
+			s, ok := openid.LocalizedString(tok, tc.Claim, tc.Preferred...)
+			require.True(t, ok, `openid.LocalizedString should succeed`)
+			require.Equal(t, tc.Value, s)
+		})
+	}
+}
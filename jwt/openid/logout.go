@@ -0,0 +1,173 @@
+package openid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// Names and values defined in OpenID Connect Back-Channel Logout 1.0
+const (
+	EventsKey              = `events`
+	SessionIDKey           = `sid`
+	BackChannelLogoutEvent = `http://schemas.openid.net/event/backchannel-logout`
+	LogoutTokenType        = `logout+jwt`
+)
+
+var errInvalidLogoutToken = jwt.NewValidationError(errors.New(`invalid logout token`))
+
+// ErrInvalidLogoutToken returns the immutable error used when a token does
+// not satisfy the requirements specific to logout tokens. The error
+// returned by `openid.ValidateLogoutToken()` wraps this error, and
+// describes which requirement was not satisfied.
+func ErrInvalidLogoutToken() jwt.ValidationError {
+	return errInvalidLogoutToken
+}
+
+type logoutTokenError struct {
+	msg string
+}
+
+func (err *logoutTokenError) Error() string {
+	return fmt.Sprintf(`invalid logout token: %s`, err.msg)
+}
+
+func (err *logoutTokenError) Unwrap() error {
+	return errInvalidLogoutToken
+}
+
+func invalidLogoutToken(format string, args ...interface{}) jwt.ValidationError {
+	return jwt.NewValidationError(&logoutTokenError{msg: fmt.Sprintf(format, args...)})
+}
+
+// SessionID returns the value of the `sid` claim of `t`, and whether it
+// is present.
+func SessionID(t jwt.Token) (string, bool) {
+	return stringClaim(t, SessionIDKey)
+}
+
+// Events returns the value of the `events` claim of `t`, or nil if `t`
+// does not have one. The keys of the returned map are event type
+// identifiers, such as `openid.BackChannelLogoutEvent`.
+func Events(t jwt.Token) (map[string]interface{}, error) {
+	v, ok := t.Get(EventsKey)
+	if !ok {
+		return nil, nil
+	}
+	events, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`invalid %q claim (%T)`, EventsKey, v)
+	}
+	return events, nil
+}
+
+// ValidateLogoutToken validates the logout token `t` according to OpenID
+// Connect Back-Channel Logout 1.0 Section 2.6. In addition to the checks
+// performed by `jwt.Validate()`:
+//
+//   - `iss`, `aud`, `iat`, `exp` and `jti` must be present
+//   - `iss` must be equal to `issuer`
+//   - `aud` must contain `clientID`
+//   - `sub`, `sid`, or both must be present
+//   - `events` must contain a member named `openid.BackChannelLogoutEvent`,
+//     whose value is a JSON object
+//   - `nonce` must not be present
+//   - if the `typ` header is available (i.e. when called through
+//     `openid.ParseLogoutToken()`), it must be "logout+jwt", or "JWT"
+//     (or absent) for tokens issued by OPs that do not use explicit typing
+//
+// `jwt.ValidateOption`s (e.g. `jwt.WithClock()`, `jwt.WithReplayDetection()`)
+// may be passed as well.
+func ValidateLogoutToken(t jwt.Token, issuer, clientID string, options ...jwt.ValidateOption) error {
+	validateOptions, err := logoutTokenOptions(`openid.ValidateLogoutToken`, issuer, clientID, options)
+	if err != nil {
+		return err
+	}
+	return jwt.Validate(t, validateOptions...)
+}
+
+// ParseLogoutToken parses the logout token in `src` using `jwt.Parse()`,
+// and validates it as described in `openid.ValidateLogoutToken()`. The
+// returned token is an `openid.Token`.
+//
+// The source of the verification keys must be specified in `options`
+// (e.g. `jwt.WithKeySet()`). Further `jwt.ParseOption`s may be passed as
+// well, but `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func ParseLogoutToken(src []byte, issuer, clientID string, options ...jwt.ParseOption) (Token, error) {
+	var validateOptions []jwt.ValidateOption
+	var parseOptions []jwt.ParseOption
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, fmt.Errorf(`openid.ParseLogoutToken: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+		if vo, ok := o.(jwt.ValidateOption); ok {
+			validateOptions = append(validateOptions, vo)
+			continue
+		}
+		parseOptions = append(parseOptions, o)
+	}
+
+	validateOptions, err := logoutTokenOptions(`openid.ParseLogoutToken`, issuer, clientID, validateOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	parseOptions = append(parseOptions, jwt.WithToken(New()))
+	for _, o := range validateOptions {
+		parseOptions = append(parseOptions, o)
+	}
+	tok, err := jwt.Parse(src, parseOptions...)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:forcetypeassert
+	return tok.(Token), nil
+}
+
+func logoutTokenOptions(fn, issuer, clientID string, options []jwt.ValidateOption) ([]jwt.ValidateOption, error) {
+	if issuer == `` || clientID == `` {
+		return nil, fmt.Errorf(`%s: issuer and clientID must be specified`, fn)
+	}
+	return append(options[:len(options):len(options)],
+		jwt.WithRequiredClaims(IssuerKey, AudienceKey, IssuedAtKey, ExpirationKey, JwtIDKey, EventsKey),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithValidator(jwt.ValidatorFunc(validateLogoutToken)),
+	), nil
+}
+
+func validateLogoutToken(ctx context.Context, t jwt.Token) jwt.ValidationError {
+	if hdrs, ok := jwt.ValidationCtxHeaders(ctx); ok {
+		// The "application/" prefix may be omitted, and media types are
+		// case-insensitive (RFC 7515 Section 4.1.9). Tokens without explicit
+		// typing are accepted for interoperability with older OPs
+		switch typ := strings.ToLower(hdrs.Type()); typ {
+		case ``, `jwt`, LogoutTokenType, `application/` + LogoutTokenType:
+		default:
+			return invalidLogoutToken(`"typ" header must be %q`, LogoutTokenType)
+		}
+	}
+
+	if t.Subject() == `` {
+		if _, ok := SessionID(t); !ok {
+			return invalidLogoutToken(`either %q or %q claim is required`, SubjectKey, SessionIDKey)
+		}
+	}
+
+	events, err := Events(t)
+	if err != nil {
+		return invalidLogoutToken(`%s`, err)
+	}
+	if _, ok := events[BackChannelLogoutEvent].(map[string]interface{}); !ok {
+		return invalidLogoutToken(`%q claim must contain %q`, EventsKey, BackChannelLogoutEvent)
+	}
+
+	if _, ok := t.Get(NonceKey); ok {
+		return invalidLogoutToken(`%q claim is not allowed`, NonceKey)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package openid_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogoutToken(t *testing.T) {
+	const issuer = `https://server.example.com`
+	const clientID = `s6BhdRkqt3`
+
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	build := func(t *testing.T, claims map[string]interface{}) openid.Token {
+		t.Helper()
+		tok := openid.New()
+		require.NoError(t, tok.Set(openid.IssuerKey, issuer), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.SubjectKey, `248289761001`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.AudienceKey, clientID), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.IssuedAtKey, time.Now()), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.ExpirationKey, time.Now().Add(2*time.Minute)), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.JwtIDKey, `bWJq`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.SessionIDKey, `08a5019c-17e1-4977-8f42-65a12843ea02`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.EventsKey, map[string]interface{}{
+			openid.BackChannelLogoutEvent: map[string]interface{}{},
+		}), `tok.Set should succeed`)
+		for k, v := range claims {
+			if v == nil {
+				require.NoError(t, tok.Remove(k), `tok.Remove should succeed`)
+				continue
+			}
+			require.NoError(t, tok.Set(k, v), `tok.Set should succeed`)
+		}
+		return tok
+	}
+	sign := func(t *testing.T, tok jwt.Token, typ string) []byte {
+		t.Helper()
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, typ), `hdrs.Set should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+	verifyKey := jwt.WithKey(jwa.RS256, &key.PublicKey)
+
+	t.Run("valid", func(t *testing.T) {
+		parsed, err := openid.ParseLogoutToken(sign(t, build(t, nil), openid.LogoutTokenType), issuer, clientID, verifyKey)
+		require.NoError(t, err, `openid.ParseLogoutToken should succeed`)
+		sid, ok := openid.SessionID(parsed)
+		require.True(t, ok, `sid should exist`)
+		require.Equal(t, `08a5019c-17e1-4977-8f42-65a12843ea02`, sid)
+		events, err := openid.Events(parsed)
+		require.NoError(t, err, `openid.Events should succeed`)
+		require.Contains(t, events, openid.BackChannelLogoutEvent)
+
+		// sid only
+		require.NoError(t, openid.ValidateLogoutToken(build(t, map[string]interface{}{openid.SubjectKey: nil}), issuer, clientID), `openid.ValidateLogoutToken should succeed without sub`)
+		// sub only
+		require.NoError(t, openid.ValidateLogoutToken(build(t, map[string]interface{}{openid.SessionIDKey: nil}), issuer, clientID), `openid.ValidateLogoutToken should succeed without sid`)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		testcases := []struct {
+			Name   string
+			Claims map[string]interface{}
+		}{
+			{Name: "nonce", Claims: map[string]interface{}{openid.NonceKey: `n-0S6_WzA2Mj`}},
+			{Name: "no sub or sid", Claims: map[string]interface{}{openid.SubjectKey: nil, openid.SessionIDKey: nil}},
+			{Name: "wrong event", Claims: map[string]interface{}{openid.EventsKey: map[string]interface{}{`urn:example:event`: map[string]interface{}{}}}},
+			{Name: "non-object event", Claims: map[string]interface{}{openid.EventsKey: map[string]interface{}{openid.BackChannelLogoutEvent: true}}},
+			{Name: "invalid events", Claims: map[string]interface{}{openid.EventsKey: `logout`}},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Name, func(t *testing.T) {
+				err := openid.ValidateLogoutToken(build(t, tc.Claims), issuer, clientID)
+				require.True(t, errors.Is(err, openid.ErrInvalidLogoutToken()), `openid.ValidateLogoutToken should fail with ErrInvalidLogoutToken (%s)`, err)
+			})
+		}
+
+		err := openid.ValidateLogoutToken(build(t, map[string]interface{}{openid.JwtIDKey: nil}), issuer, clientID)
+		require.True(t, errors.Is(err, jwt.ErrMissingRequiredClaim(openid.JwtIDKey)), `jti should be required`)
+		err = openid.ValidateLogoutToken(build(t, map[string]interface{}{openid.EventsKey: nil}), issuer, clientID)
+		require.Error(t, err, `events should be required`)
+
+		// An ID Token (typ "JWT" is accepted, but the nonce is not)
+		_, err = openid.ParseLogoutToken(sign(t, build(t, map[string]interface{}{openid.NonceKey: `n-0S6_WzA2Mj`}), `JWT`), issuer, clientID, verifyKey)
+		require.True(t, errors.Is(err, openid.ErrInvalidLogoutToken()), `openid.ParseLogoutToken should fail for tokens with nonce`)
+
+		_, err = openid.ParseLogoutToken(sign(t, build(t, nil), `at+jwt`), issuer, clientID, verifyKey)
+		require.True(t, errors.Is(err, openid.ErrInvalidLogoutToken()), `openid.ParseLogoutToken should fail for other token types`)
+
+		_, err = openid.ParseLogoutToken(sign(t, build(t, nil), openid.LogoutTokenType), issuer, clientID, verifyKey, jwt.WithVerify(false))
+		require.Error(t, err, `openid.ParseLogoutToken should reject jwt.WithVerify`)
+	})
+}
@@ -9,9 +9,11 @@ package openid
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
 )
 
 var registry = json.NewRegistry()
@@ -19,16 +21,29 @@ var registry = json.NewRegistry()
 func (t *stdToken) Clone() (jwt.Token, error) {
 	var dst jwt.Token = New()
 
+	dst.Options().Set(*(t.Options()))
 	for _, pair := range t.makePairs() {
 		//nolint:forcetypeassert
 		key := pair.Key.(string)
-		if err := dst.Set(key, pair.Value); err != nil {
+		if err := dst.Set(key, claims.DeepCopy(pair.Value)); err != nil {
 			return nil, fmt.Errorf(`failed to set %s: %w`, key, err)
 		}
 	}
 	return dst, nil
 }
 
+func (t *stdToken) Equal(other jwt.Token) bool {
+	return jwt.Equal(t, other)
+}
+
+func (t *stdToken) Expired(clock jwt.Clock) bool {
+	return jwt.Expired(t, clock)
+}
+
+func (t *stdToken) RemainingValidity(clock jwt.Clock) time.Duration {
+	return jwt.RemainingValidity(t, clock)
+}
+
 // RegisterCustomField allows users to specify that a private field
 // be decoded as an instance of the specified type. This option has
 // a global effect.
@@ -46,6 +61,20 @@ func (t *stdToken) Clone() (jwt.Token, error) {
 //
 //	bdayif, _ := token.Get(`x-birthday`)
 //	bday := bdayif.(time.Time)
+//
+// If the JSON representation of the field cannot be decoded by
+// unmarshaling it into the specified type, register a `jwt.CustomDecoder`
+// instead, which receives the raw JSON value of the field:
+//
+//	openid.RegisterCustomField(`x-birthday`, jwt.CustomDecodeFunc(func(data []byte) (interface{}, error) {
+//	  var s string
+//	  if err := json.Unmarshal(data, &s); err != nil {
+//	    return nil, err
+//	  }
+//	  return time.Parse(`2006-01-02`, s)
+//	}))
+//
+// Specifying a nil object removes the registration.
 func RegisterCustomField(name string, object interface{}) {
 	registry.Register(name, object)
 }
@@ -374,6 +374,62 @@ func TestOpenIDClaims(t *testing.T) {
 				assert.Equal(t, time.Unix(aLongLongTimeAgo, 0).UTC(), token.UpdatedAt())
 			},
 		},
+		{
+			Value: aLongLongTimeAgoString,
+			Key:   openid.AuthTimeKey,
+			Expected: func(v interface{}) interface{} {
+				var n types.NumericDate
+				if err := n.Accept(v); err != nil {
+					panic(err)
+				}
+				return n.Get()
+			},
+			Check: func(token openid.Token) {
+				assert.Equal(t, time.Unix(aLongLongTimeAgo, 0).UTC(), token.AuthTime())
+			},
+		},
+		{
+			Value: "n-0S6_WzA2Mj",
+			Key:   openid.NonceKey,
+			Check: func(token openid.Token) {
+				assert.Equal(t, token.Nonce(), "n-0S6_WzA2Mj")
+			},
+		},
+		{
+			Value: "urn:mace:incommon:iap:silver",
+			Key:   openid.ACRKey,
+			Check: func(token openid.Token) {
+				assert.Equal(t, token.ACR(), "urn:mace:incommon:iap:silver")
+			},
+		},
+		{
+			Value: []string{"pwd", "otp"},
+			Key:   openid.AMRKey,
+			Check: func(token openid.Token) {
+				assert.Equal(t, token.AMR(), []string{"pwd", "otp"})
+			},
+		},
+		{
+			Value: "s6BhdRkqt3",
+			Key:   openid.AuthorizedPartyKey,
+			Check: func(token openid.Token) {
+				assert.Equal(t, token.AuthorizedParty(), "s6BhdRkqt3")
+			},
+		},
+		{
+			Value: "77QmUPtjPfzWtF2AnpK9RQ",
+			Key:   openid.AccessTokenHashKey,
+			Check: func(token openid.Token) {
+				assert.Equal(t, token.AccessTokenHash(), "77QmUPtjPfzWtF2AnpK9RQ")
+			},
+		},
+		{
+			Value: "LDktKdoQak3Pk0cnXxCltA",
+			Key:   openid.CodeHashKey,
+			Check: func(token openid.Token) {
+				assert.Equal(t, token.CodeHash(), "LDktKdoQak3Pk0cnXxCltA")
+			},
+		},
 		{
 			Value: `dummy`,
 			Key:   `dummy`,
@@ -577,6 +633,22 @@ func TestBirthdateClaim(t *testing.T) {
 			},
 			{
 				Source: `"0000-01-01"`,
+				Month:  1,
+				Day:    1,
+			},
+			{
+				Source: `"1987"`,
+				Year:   1987,
+			},
+			{
+				Source: `""`,
+			},
+			{
+				Source: `"0000"`,
+				Error:  true,
+			},
+			{
+				Source: `"1987-01"`,
 				Error:  true,
 			},
 			{
@@ -646,13 +718,72 @@ func TestBirthdateClaim(t *testing.T) {
 			return
 		}
 	})
+	t.Run("partial dates", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Source      string
+			HasYear     bool
+			HasMonthDay bool
+		}{
+			{Source: `2015-11-04`, HasYear: true, HasMonthDay: true},
+			{Source: `0000-11-04`, HasMonthDay: true},
+			{Source: `2015`, HasYear: true},
+			{Source: ``},
+		}
+		for _, tc := range testcases {
+			var b openid.BirthdateClaim
+			require.NoError(t, b.Accept(tc.Source), `b.Accept should succeed`)
+			require.Equal(t, tc.HasYear, b.HasYear(), `b.HasYear should match for %q`, tc.Source)
+			require.Equal(t, tc.HasMonthDay, b.HasMonthDay(), `b.HasMonthDay should match for %q`, tc.Source)
+		}
+	})
+	t.Run("age", func(t *testing.T) {
+		t.Parallel()
+		date := func(year int, month time.Month, day int) time.Time {
+			return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+		}
+		testcases := []struct {
+			Source string
+			At     time.Time
+			Age    int
+			Error  bool
+		}{
+			{Source: `2005-06-15`, At: date(2023, time.June, 14), Age: 17},
+			{Source: `2005-06-15`, At: date(2023, time.June, 15), Age: 18},
+			{Source: `2005-06-15`, At: date(2023, time.December, 31), Age: 18},
+			{Source: `2004-02-29`, At: date(2023, time.February, 28), Age: 18},
+			{Source: `2004-02-29`, At: date(2023, time.March, 1), Age: 19},
+			{Source: `2004-02-29`, At: date(2024, time.February, 29), Age: 20},
+			{Source: `2005`, At: date(2023, time.June, 15), Age: 17},
+			{Source: `2005`, At: date(2023, time.December, 31), Age: 18},
+			{Source: `0000-06-15`, At: date(2023, time.June, 15), Error: true},
+			{Source: ``, At: date(2023, time.June, 15), Error: true},
+		}
+		for _, tc := range testcases {
+			var b openid.BirthdateClaim
+			require.NoError(t, b.Accept(tc.Source), `b.Accept should succeed`)
+			age, ok := b.Age(tc.At)
+			if tc.Error {
+				require.False(t, ok, `b.Age should fail for %q`, tc.Source)
+				continue
+			}
+			require.True(t, ok, `b.Age should succeed for %q`, tc.Source)
+			require.Equal(t, tc.Age, age, `age should match for %q at %s`, tc.Source, tc.At)
+		}
+	})
 }
 
 func TestKeys(t *testing.T) {
 	at := assert.New(t)
+	at.Equal(`at_hash`, openid.AccessTokenHashKey)
+	at.Equal(`acr`, openid.ACRKey)
 	at.Equal(`address`, openid.AddressKey)
+	at.Equal(`amr`, openid.AMRKey)
 	at.Equal(`aud`, openid.AudienceKey)
+	at.Equal(`auth_time`, openid.AuthTimeKey)
+	at.Equal(`azp`, openid.AuthorizedPartyKey)
 	at.Equal(`birthdate`, openid.BirthdateKey)
+	at.Equal(`c_hash`, openid.CodeHashKey)
 	at.Equal(`email`, openid.EmailKey)
 	at.Equal(`email_verified`, openid.EmailVerifiedKey)
 	at.Equal(`exp`, openid.ExpirationKey)
@@ -666,6 +797,7 @@ func TestKeys(t *testing.T) {
 	at.Equal(`middle_name`, openid.MiddleNameKey)
 	at.Equal(`name`, openid.NameKey)
 	at.Equal(`nickname`, openid.NicknameKey)
+	at.Equal(`nonce`, openid.NonceKey)
 	at.Equal(`nbf`, openid.NotBeforeKey)
 	at.Equal(`phone_number`, openid.PhoneNumberKey)
 	at.Equal(`phone_number_verified`, openid.PhoneNumberVerifiedKey)
@@ -0,0 +1,117 @@
+package openid
+
+import (
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/option"
+)
+
+// IDTokenOption describes an option that can be passed to
+// `openid.ValidateIDToken()` and `openid.ParseIDToken()`.
+type IDTokenOption = option.Interface
+
+type identACR struct{}
+type identAccessToken struct{}
+type identCode struct{}
+type identMaxAuthAge struct{}
+type identNonce struct{}
+type identSignatureAlgorithm struct{}
+
+// ResolveOption describes an option that can be passed to
+// `openid.ResolveClaims()` and `openid.ResolveIssuer()`.
+type ResolveOption = option.Interface
+
+type identClaimSourceOptions struct{}
+type identHTTPClient struct{}
+
+// IDTokenBuilderOption describes an option that can be passed to
+// `openid.NewIDTokenBuilder()`.
+type IDTokenBuilderOption = option.Interface
+
+type identIDTokenClock struct{}
+type identIDTokenLifetime struct{}
+
+// WithNonce specifies the value of the `nonce` parameter sent in the
+// authentication request. The `nonce` claim must be present, and must
+// match `v`.
+func WithNonce(v string) IDTokenOption {
+	return option.New(identNonce{}, v)
+}
+
+// WithAccessToken specifies the access token that was issued along with
+// the ID Token. The ID Token must contain the `at_hash` claim, which must
+// match the hash of `v`.
+func WithAccessToken(v string) IDTokenOption {
+	return option.New(identAccessToken{}, v)
+}
+
+// WithCode specifies the authorization code that was issued along with
+// the ID Token. The ID Token must contain the `c_hash` claim, which must
+// match the hash of `v`.
+func WithCode(v string) IDTokenOption {
+	return option.New(identCode{}, v)
+}
+
+// WithACR specifies the Authentication Context Class References that are
+// acceptable. The `acr` claim must be present, and must be one of `v`.
+func WithACR(v ...string) IDTokenOption {
+	return option.New(identACR{}, v)
+}
+
+// WithMaxAuthAge specifies the value of the `max_age` parameter sent in
+// the authentication request. The `auth_time` claim must be present, and
+// the End-User must have authenticated within `v` (plus the acceptable
+// skew specified via `jwt.WithAcceptableSkew()`).
+//
+// Not to be confused with `jwt.WithMaxAge()`, which limits the age of the
+// token based on the `iat` claim.
+func WithMaxAuthAge(v time.Duration) IDTokenOption {
+	return option.New(identMaxAuthAge{}, v)
+}
+
+// WithSignatureAlgorithm specifies the algorithm used to sign the ID Token,
+// which determines the hash function used for the `at_hash` and `c_hash`
+// claims. This is only required when `openid.ValidateIDToken()` is used
+// on a token that has already been parsed, and the JWS headers are not
+// available otherwise.
+func WithSignatureAlgorithm(v jwa.SignatureAlgorithm) IDTokenOption {
+	return option.New(identSignatureAlgorithm{}, v)
+}
+
+// WithAuthorizedParty validates the `azp` claim of the token against the
+// client ID `v`, as described in `openid.IsAuthorizedPartyValid()`. It can be
+// passed to `jwt.Parse()` and `jwt.Validate()`, along with `jwt.WithAudience(v)`.
+func WithAuthorizedParty(v string) jwt.ValidateOption {
+	return jwt.WithValidator(IsAuthorizedPartyValid(v))
+}
+
+// WithClaimSourceOptions specifies the function that returns the options
+// used to verify the JWT of each claims source in `openid.ResolveClaims()`.
+// It must be specified.
+func WithClaimSourceOptions(v ClaimSourceOptionsFunc) ResolveOption {
+	return option.New(identClaimSourceOptions{}, v)
+}
+
+// WithHTTPClient specifies the HTTP client used by `openid.ResolveClaims()`
+// to retrieve distributed claims, and by `openid.ResolveIssuer()` to query
+// the WebFinger endpoint. If it is not specified, `openid.ResolveClaims()`
+// does not retrieve distributed claims.
+func WithHTTPClient(v HTTPClient) ResolveOption {
+	return option.New(identHTTPClient{}, v)
+}
+
+// WithIDTokenLifetime specifies the lifetime of the ID Tokens created by
+// `openid.IDTokenBuilder`, i.e. the difference between the `exp` and `iat`
+// claims, if `exp` is not set explicitly. The default is
+// `openid.DefaultIDTokenLifetime`.
+func WithIDTokenLifetime(v time.Duration) IDTokenBuilderOption {
+	return option.New(identIDTokenLifetime{}, v)
+}
+
+// WithIDTokenClock specifies the clock used by `openid.IDTokenBuilder`
+// to compute the default values of the `iat` and `exp` claims.
+func WithIDTokenClock(v jwt.Clock) IDTokenBuilderOption {
+	return option.New(identIDTokenClock{}, v)
+}
@@ -0,0 +1,11 @@
+package openid
+
+import "github.com/lestrrat-go/jwx/v2/jwt"
+
+// String returns the claims of the token in JSON format, with the values
+// of sensitive claims redacted. See `jwt.WithUnredactedClaims()`.
+//
+// Use `json.Marshal()` to obtain the claims as-is.
+func (t *stdToken) String() string {
+	return jwt.RedactedString(t)
+}
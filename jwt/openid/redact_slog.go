@@ -0,0 +1,17 @@
+//go:build go1.21
+// +build go1.21
+
+package openid
+
+import (
+	"log/slog"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// LogValue implements `slog.LogValuer`, so that the values of sensitive
+// claims are redacted when the token is logged via `log/slog`.
+// See `jwt.WithUnredactedClaims()`.
+func (t *stdToken) LogValue() slog.Value {
+	return jwt.RedactedLogValue(t)
+}
@@ -0,0 +1,25 @@
+package openid_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	tok := openid.New()
+	require.NoError(t, tok.Set(openid.SubjectKey, `alice`), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(openid.EmailKey, `alice@example.com`), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(openid.PhoneNumberKey, `+81 90 1234 5678`), `tok.Set should succeed`)
+
+	s := fmt.Sprintf(`%v`, tok)
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &m), `redacted string should be valid JSON`)
+	require.Equal(t, `alice`, m[openid.SubjectKey])
+	require.Equal(t, `[REDACTED]`, m[openid.EmailKey])
+	require.Equal(t, `[REDACTED]`, m[openid.PhoneNumberKey])
+	require.NotContains(t, s, `alice@example.com`)
+}
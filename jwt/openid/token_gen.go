@@ -16,13 +16,20 @@ import (
 	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/internal/pool"
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
 	"github.com/lestrrat-go/jwx/v2/jwt/internal/types"
 )
 
 const (
+	AccessTokenHashKey     = "at_hash"
+	ACRKey                 = "acr"
 	AddressKey             = "address"
+	AMRKey                 = "amr"
 	AudienceKey            = "aud"
+	AuthTimeKey            = "auth_time"
+	AuthorizedPartyKey     = "azp"
 	BirthdateKey           = "birthdate"
+	CodeHashKey            = "c_hash"
 	EmailKey               = "email"
 	EmailVerifiedKey       = "email_verified"
 	ExpirationKey          = "exp"
@@ -36,6 +43,7 @@ const (
 	MiddleNameKey          = "middle_name"
 	NameKey                = "name"
 	NicknameKey            = "nickname"
+	NonceKey               = "nonce"
 	NotBeforeKey           = "nbf"
 	PhoneNumberKey         = "phone_number"
 	PhoneNumberVerifiedKey = "phone_number_verified"
@@ -50,15 +58,33 @@ const (
 
 type Token interface {
 
+	// AccessTokenHash returns the value for "at_hash" field of the token
+	AccessTokenHash() string
+
+	// ACR returns the value for "acr" field of the token
+	ACR() string
+
 	// Address returns the value for "address" field of the token
 	Address() *AddressClaim
 
+	// AMR returns the value for "amr" field of the token
+	AMR() []string
+
 	// Audience returns the value for "aud" field of the token
 	Audience() []string
 
+	// AuthTime returns the value for "auth_time" field of the token
+	AuthTime() time.Time
+
+	// AuthorizedParty returns the value for "azp" field of the token
+	AuthorizedParty() string
+
 	// Birthdate returns the value for "birthdate" field of the token
 	Birthdate() *BirthdateClaim
 
+	// CodeHash returns the value for "c_hash" field of the token
+	CodeHash() string
+
 	// Email returns the value for "email" field of the token
 	Email() string
 
@@ -98,6 +124,9 @@ type Token interface {
 	// Nickname returns the value for "nickname" field of the token
 	Nickname() string
 
+	// Nonce returns the value for "nonce" field of the token
+	Nonce() string
+
 	// NotBefore returns the value for "nbf" field of the token
 	NotBefore() time.Time
 
@@ -154,18 +183,40 @@ type Token interface {
 	// but it will not survive when the token goes through marshaling/unmarshaling
 	// such as `json.Marshal` and `json.Unmarshal`
 	Options() *jwt.TokenOptionSet
+
+	// Clone returns a deep copy of the token. Claim values such as maps,
+	// slices, and pointers are copied as well, so that the new token
+	// can be modified without affecting the original token
 	Clone() (jwt.Token, error)
 	Iterate(context.Context) Iterator
 	Walk(context.Context, Visitor) error
 	AsMap(context.Context) (map[string]interface{}, error)
+
+	// Equal returns true if the token contains the same claims as the
+	// argument. See `jwt.Equal()` for details
+	Equal(jwt.Token) bool
+
+	// Expired returns true if the token has expired according to the
+	// clock. See `jwt.Expired()` for details
+	Expired(jwt.Clock) bool
+
+	// RemainingValidity returns the duration until the token expires
+	// according to the clock. See `jwt.RemainingValidity()` for details
+	RemainingValidity(jwt.Clock) time.Duration
 }
 type stdToken struct {
 	mu                  *sync.RWMutex
 	dc                  DecodeCtx          // per-object context for decoding
 	options             jwt.TokenOptionSet // per-object option
+	accessTokenHash     *string
+	acr                 *string
 	address             *AddressClaim
+	amr                 types.StringList
 	audience            types.StringList // https://tools.ietf.org/html/rfc7519#section-4.1.3
+	authTime            *types.NumericDate
+	authorizedParty     *string
 	birthdate           *BirthdateClaim
+	codeHash            *string
 	email               *string
 	emailVerified       *bool
 	expiration          *types.NumericDate // https://tools.ietf.org/html/rfc7519#section-4.1.4
@@ -179,6 +230,7 @@ type stdToken struct {
 	middleName          *string
 	name                *string
 	nickname            *string
+	nonce               *string
 	notBefore           *types.NumericDate // https://tools.ietf.org/html/rfc7519#section-4.1.5
 	phoneNumber         *string
 	phoneNumberVerified *bool
@@ -193,7 +245,7 @@ type stdToken struct {
 }
 
 // New creates a standard token, with minimal knowledge of
-// possible claims. Standard claims include"address", "aud", "birthdate", "email", "email_verified", "exp", "family_name", "gender", "given_name", "iat", "iss", "jti", "locale", "middle_name", "name", "nickname", "nbf", "phone_number", "phone_number_verified", "picture", "preferred_username", "profile", "sub", "updated_at", "website" and "zoneinfo".
+// possible claims. Standard claims include"at_hash", "acr", "address", "amr", "aud", "auth_time", "azp", "birthdate", "c_hash", "email", "email_verified", "exp", "family_name", "gender", "given_name", "iat", "iss", "jti", "locale", "middle_name", "name", "nickname", "nonce", "nbf", "phone_number", "phone_number_verified", "picture", "preferred_username", "profile", "sub", "updated_at", "website" and "zoneinfo".
 // Convenience accessors are provided for these standard claims
 func New() Token {
 	return &stdToken{
@@ -211,24 +263,60 @@ func (t *stdToken) Get(name string) (interface{}, bool) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 	switch name {
+	case AccessTokenHashKey:
+		if t.accessTokenHash == nil {
+			return nil, false
+		}
+		v := *(t.accessTokenHash)
+		return v, true
+	case ACRKey:
+		if t.acr == nil {
+			return nil, false
+		}
+		v := *(t.acr)
+		return v, true
 	case AddressKey:
 		if t.address == nil {
 			return nil, false
 		}
 		v := t.address
 		return v, true
+	case AMRKey:
+		if t.amr == nil {
+			return nil, false
+		}
+		v := t.amr.Get()
+		return v, true
 	case AudienceKey:
 		if t.audience == nil {
 			return nil, false
 		}
 		v := t.audience.Get()
 		return v, true
+	case AuthTimeKey:
+		if t.authTime == nil {
+			return nil, false
+		}
+		v := t.authTime.Get()
+		return v, true
+	case AuthorizedPartyKey:
+		if t.authorizedParty == nil {
+			return nil, false
+		}
+		v := *(t.authorizedParty)
+		return v, true
 	case BirthdateKey:
 		if t.birthdate == nil {
 			return nil, false
 		}
 		v := t.birthdate
 		return v, true
+	case CodeHashKey:
+		if t.codeHash == nil {
+			return nil, false
+		}
+		v := *(t.codeHash)
+		return v, true
 	case EmailKey:
 		if t.email == nil {
 			return nil, false
@@ -307,6 +395,12 @@ func (t *stdToken) Get(name string) (interface{}, bool) {
 		}
 		v := *(t.nickname)
 		return v, true
+	case NonceKey:
+		if t.nonce == nil {
+			return nil, false
+		}
+		v := *(t.nonce)
+		return v, true
 	case NotBeforeKey:
 		if t.notBefore == nil {
 			return nil, false
@@ -377,12 +471,24 @@ func (t *stdToken) Remove(key string) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	switch key {
+	case AccessTokenHashKey:
+		t.accessTokenHash = nil
+	case ACRKey:
+		t.acr = nil
 	case AddressKey:
 		t.address = nil
+	case AMRKey:
+		t.amr = nil
 	case AudienceKey:
 		t.audience = nil
+	case AuthTimeKey:
+		t.authTime = nil
+	case AuthorizedPartyKey:
+		t.authorizedParty = nil
 	case BirthdateKey:
 		t.birthdate = nil
+	case CodeHashKey:
+		t.codeHash = nil
 	case EmailKey:
 		t.email = nil
 	case EmailVerifiedKey:
@@ -409,6 +515,8 @@ func (t *stdToken) Remove(key string) error {
 		t.name = nil
 	case NicknameKey:
 		t.nickname = nil
+	case NonceKey:
+		t.nonce = nil
 	case NotBeforeKey:
 		t.notBefore = nil
 	case PhoneNumberKey:
@@ -455,6 +563,18 @@ func (t *stdToken) SetDecodeCtx(v DecodeCtx) {
 
 func (t *stdToken) setNoLock(name string, value interface{}) error {
 	switch name {
+	case AccessTokenHashKey:
+		if v, ok := value.(string); ok {
+			t.accessTokenHash = &v
+			return nil
+		}
+		return fmt.Errorf(`invalid value for %s key: %T`, AccessTokenHashKey, value)
+	case ACRKey:
+		if v, ok := value.(string); ok {
+			t.acr = &v
+			return nil
+		}
+		return fmt.Errorf(`invalid value for %s key: %T`, ACRKey, value)
 	case AddressKey:
 		var acceptor AddressClaim
 		if err := acceptor.Accept(value); err != nil {
@@ -462,6 +582,13 @@ func (t *stdToken) setNoLock(name string, value interface{}) error {
 		}
 		t.address = &acceptor
 		return nil
+	case AMRKey:
+		var acceptor types.StringList
+		if err := acceptor.Accept(value); err != nil {
+			return fmt.Errorf(`invalid value for %s key: %w`, AMRKey, err)
+		}
+		t.amr = acceptor
+		return nil
 	case AudienceKey:
 		var acceptor types.StringList
 		if err := acceptor.Accept(value); err != nil {
@@ -469,6 +596,19 @@ func (t *stdToken) setNoLock(name string, value interface{}) error {
 		}
 		t.audience = acceptor
 		return nil
+	case AuthTimeKey:
+		var acceptor types.NumericDate
+		if err := acceptor.Accept(value); err != nil {
+			return fmt.Errorf(`invalid value for %s key: %w`, AuthTimeKey, err)
+		}
+		t.authTime = &acceptor
+		return nil
+	case AuthorizedPartyKey:
+		if v, ok := value.(string); ok {
+			t.authorizedParty = &v
+			return nil
+		}
+		return fmt.Errorf(`invalid value for %s key: %T`, AuthorizedPartyKey, value)
 	case BirthdateKey:
 		var acceptor BirthdateClaim
 		if err := acceptor.Accept(value); err != nil {
@@ -476,6 +616,12 @@ func (t *stdToken) setNoLock(name string, value interface{}) error {
 		}
 		t.birthdate = &acceptor
 		return nil
+	case CodeHashKey:
+		if v, ok := value.(string); ok {
+			t.codeHash = &v
+			return nil
+		}
+		return fmt.Errorf(`invalid value for %s key: %T`, CodeHashKey, value)
 	case EmailKey:
 		if v, ok := value.(string); ok {
 			t.email = &v
@@ -556,6 +702,12 @@ func (t *stdToken) setNoLock(name string, value interface{}) error {
 			return nil
 		}
 		return fmt.Errorf(`invalid value for %s key: %T`, NicknameKey, value)
+	case NonceKey:
+		if v, ok := value.(string); ok {
+			t.nonce = &v
+			return nil
+		}
+		return fmt.Errorf(`invalid value for %s key: %T`, NonceKey, value)
 	case NotBeforeKey:
 		var acceptor types.NumericDate
 		if err := acceptor.Accept(value); err != nil {
@@ -627,12 +779,39 @@ func (t *stdToken) setNoLock(name string, value interface{}) error {
 	return nil
 }
 
+func (t *stdToken) AccessTokenHash() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.accessTokenHash != nil {
+		return *(t.accessTokenHash)
+	}
+	return ""
+}
+
+func (t *stdToken) ACR() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.acr != nil {
+		return *(t.acr)
+	}
+	return ""
+}
+
 func (t *stdToken) Address() *AddressClaim {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 	return t.address
 }
 
+func (t *stdToken) AMR() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.amr != nil {
+		return t.amr.Get()
+	}
+	return nil
+}
+
 func (t *stdToken) Audience() []string {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -642,12 +821,39 @@ func (t *stdToken) Audience() []string {
 	return nil
 }
 
+func (t *stdToken) AuthTime() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.authTime != nil {
+		return t.authTime.Get()
+	}
+	return time.Time{}
+}
+
+func (t *stdToken) AuthorizedParty() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.authorizedParty != nil {
+		return *(t.authorizedParty)
+	}
+	return ""
+}
+
 func (t *stdToken) Birthdate() *BirthdateClaim {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 	return t.birthdate
 }
 
+func (t *stdToken) CodeHash() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.codeHash != nil {
+		return *(t.codeHash)
+	}
+	return ""
+}
+
 func (t *stdToken) Email() string {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -765,6 +971,15 @@ func (t *stdToken) Nickname() string {
 	return ""
 }
 
+func (t *stdToken) Nonce() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.nonce != nil {
+		return *(t.nonce)
+	}
+	return ""
+}
+
 func (t *stdToken) NotBefore() time.Time {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -865,19 +1080,43 @@ func (t *stdToken) makePairs() []*ClaimPair {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	pairs := make([]*ClaimPair, 0, 26)
+	pairs := make([]*ClaimPair, 0, 33)
+	if t.accessTokenHash != nil {
+		v := *(t.accessTokenHash)
+		pairs = append(pairs, &ClaimPair{Key: AccessTokenHashKey, Value: v})
+	}
+	if t.acr != nil {
+		v := *(t.acr)
+		pairs = append(pairs, &ClaimPair{Key: ACRKey, Value: v})
+	}
 	if t.address != nil {
 		v := t.address
 		pairs = append(pairs, &ClaimPair{Key: AddressKey, Value: v})
 	}
+	if t.amr != nil {
+		v := t.amr.Get()
+		pairs = append(pairs, &ClaimPair{Key: AMRKey, Value: v})
+	}
 	if t.audience != nil {
 		v := t.audience.Get()
 		pairs = append(pairs, &ClaimPair{Key: AudienceKey, Value: v})
 	}
+	if t.authTime != nil {
+		v := t.authTime.Get()
+		pairs = append(pairs, &ClaimPair{Key: AuthTimeKey, Value: v})
+	}
+	if t.authorizedParty != nil {
+		v := *(t.authorizedParty)
+		pairs = append(pairs, &ClaimPair{Key: AuthorizedPartyKey, Value: v})
+	}
 	if t.birthdate != nil {
 		v := t.birthdate
 		pairs = append(pairs, &ClaimPair{Key: BirthdateKey, Value: v})
 	}
+	if t.codeHash != nil {
+		v := *(t.codeHash)
+		pairs = append(pairs, &ClaimPair{Key: CodeHashKey, Value: v})
+	}
 	if t.email != nil {
 		v := *(t.email)
 		pairs = append(pairs, &ClaimPair{Key: EmailKey, Value: v})
@@ -930,6 +1169,10 @@ func (t *stdToken) makePairs() []*ClaimPair {
 		v := *(t.nickname)
 		pairs = append(pairs, &ClaimPair{Key: NicknameKey, Value: v})
 	}
+	if t.nonce != nil {
+		v := *(t.nonce)
+		pairs = append(pairs, &ClaimPair{Key: NonceKey, Value: v})
+	}
 	if t.notBefore != nil {
 		v := t.notBefore.Get()
 		pairs = append(pairs, &ClaimPair{Key: NotBeforeKey, Value: v})
@@ -982,9 +1225,15 @@ func (t *stdToken) makePairs() []*ClaimPair {
 func (t *stdToken) UnmarshalJSON(buf []byte) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	t.accessTokenHash = nil
+	t.acr = nil
 	t.address = nil
+	t.amr = nil
 	t.audience = nil
+	t.authTime = nil
+	t.authorizedParty = nil
 	t.birthdate = nil
+	t.codeHash = nil
 	t.email = nil
 	t.emailVerified = nil
 	t.expiration = nil
@@ -998,6 +1247,7 @@ func (t *stdToken) UnmarshalJSON(buf []byte) error {
 	t.middleName = nil
 	t.name = nil
 	t.nickname = nil
+	t.nonce = nil
 	t.notBefore = nil
 	t.phoneNumber = nil
 	t.phoneNumberVerified = nil
@@ -1026,24 +1276,52 @@ LOOP:
 			}
 		case string: // Objects can only have string keys
 			switch tok {
+			case AccessTokenHashKey:
+				if err := json.AssignNextStringToken(&t.accessTokenHash, dec); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, AccessTokenHashKey, err)
+				}
+			case ACRKey:
+				if err := json.AssignNextStringToken(&t.acr, dec); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, ACRKey, err)
+				}
 			case AddressKey:
 				var decoded AddressClaim
 				if err := dec.Decode(&decoded); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AddressKey, err)
 				}
 				t.address = &decoded
+			case AMRKey:
+				var decoded types.StringList
+				if err := dec.Decode(&decoded); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, AMRKey, err)
+				}
+				t.amr = decoded
 			case AudienceKey:
 				var decoded types.StringList
 				if err := dec.Decode(&decoded); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, AudienceKey, err)
 				}
 				t.audience = decoded
+			case AuthTimeKey:
+				var decoded types.NumericDate
+				if err := dec.Decode(&decoded); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, AuthTimeKey, err)
+				}
+				t.authTime = &decoded
+			case AuthorizedPartyKey:
+				if err := json.AssignNextStringToken(&t.authorizedParty, dec); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, AuthorizedPartyKey, err)
+				}
 			case BirthdateKey:
 				var decoded BirthdateClaim
 				if err := dec.Decode(&decoded); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, BirthdateKey, err)
 				}
 				t.birthdate = &decoded
+			case CodeHashKey:
+				if err := json.AssignNextStringToken(&t.codeHash, dec); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, CodeHashKey, err)
+				}
 			case EmailKey:
 				if err := json.AssignNextStringToken(&t.email, dec); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, EmailKey, err)
@@ -1102,6 +1380,10 @@ LOOP:
 				if err := json.AssignNextStringToken(&t.nickname, dec); err != nil {
 					return fmt.Errorf(`failed to decode value for key %s: %w`, NicknameKey, err)
 				}
+			case NonceKey:
+				if err := json.AssignNextStringToken(&t.nonce, dec); err != nil {
+					return fmt.Errorf(`failed to decode value for key %s: %w`, NonceKey, err)
+				}
 			case NotBeforeKey:
 				var decoded types.NumericDate
 				if err := dec.Decode(&decoded); err != nil {
@@ -1177,7 +1459,11 @@ func (t stdToken) MarshalJSON() ([]byte, error) {
 	defer pool.ReleaseBytesBuffer(buf)
 	buf.WriteByte('{')
 	enc := json.NewEncoder(buf)
-	for i, pair := range t.makePairs() {
+	pairs := t.makePairs()
+	if t.options.IsEnabled(jwt.RegisteredClaimsFirst) {
+		claims.SortRegisteredFirst(pairs)
+	}
+	for i, pair := range pairs {
 		f := pair.Key.(string)
 		if i > 0 {
 			buf.WriteByte(',')
@@ -1191,8 +1477,8 @@ func (t stdToken) MarshalJSON() ([]byte, error) {
 				return nil, fmt.Errorf(`failed to encode "aud": %w`, err)
 			}
 			continue
-		case ExpirationKey, IssuedAtKey, NotBeforeKey, UpdatedAtKey:
-			enc.Encode(pair.Value.(time.Time).Unix())
+		case AuthTimeKey, ExpirationKey, IssuedAtKey, NotBeforeKey, UpdatedAtKey:
+			buf.WriteString(types.NumericDate{Time: pair.Value.(time.Time)}.String())
 			continue
 		}
 		switch v := pair.Value.(type) {
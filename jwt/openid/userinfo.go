@@ -0,0 +1,103 @@
+package openid
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// Media types of UserInfo responses (OpenID Connect Core 1.0 Section 5.3.2)
+const (
+	UserInfoJSONContentType = `application/json`
+	UserInfoJWTContentType  = `application/jwt`
+)
+
+// ParseUserInfo parses the body of a UserInfo response. `contentType` is
+// the value of the Content-Type header of the response, which determines
+// the format of `src`:
+//
+//   - "application/json": the claims are parsed as a plain JSON object.
+//   - "application/jwt": the claims are parsed as a signed JWT, or a
+//     signed and then encrypted JWT, using `jwt.Parse()`.
+//
+// `options` are passed to `jwt.Parse()`, and should contain the keys to
+// verify (e.g. `jwt.WithKeySet()`) and decrypt (`jwt.WithKey()` with a key
+// encryption algorithm) the response. The `sub` claim must be compared with
+// that of the ID Token, which is most easily done by passing
+// `jwt.WithSubject()`. Signed responses should also be validated using
+// `jwt.WithIssuer()` and `jwt.WithAudience()`.
+//
+// `jwt.ValidateOption`s are also applied to plain JSON responses, unless
+// `jwt.WithValidate(false)` is specified. Keys are ignored for plain JSON
+// responses, so clients that registered a `userinfo_signed_response_alg`
+// should reject responses whose `contentType` is not "application/jwt"
+// before calling this function.
+//
+// Responses that are encrypted without being signed are not supported.
+func ParseUserInfo(src []byte, contentType string, options ...jwt.ParseOption) (Token, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf(`openid.ParseUserInfo: invalid content type %q: %w`, contentType, err)
+	}
+
+	switch mediaType {
+	case UserInfoJSONContentType:
+		return parseUserInfoJSON(src, options)
+	case UserInfoJWTContentType:
+		tok, err := jwt.Parse(src, append(options[:len(options):len(options)], jwt.WithToken(New()))...)
+		if err != nil {
+			return nil, fmt.Errorf(`openid.ParseUserInfo: %w`, err)
+		}
+		//nolint:forcetypeassert
+		return tok.(Token), nil
+	default:
+		return nil, fmt.Errorf(`openid.ParseUserInfo: unsupported content type %q`, mediaType)
+	}
+}
+
+func parseUserInfoJSON(src []byte, options []jwt.ParseOption) (Token, error) {
+	validate := true
+	var validateOptions []jwt.ValidateOption
+	for _, o := range options {
+		if vo, ok := o.(jwt.ValidateOption); ok {
+			validateOptions = append(validateOptions, vo)
+			continue
+		}
+		if parseopts.IsValidate(o) {
+			//nolint:forcetypeassert
+			validate = o.Value().(bool)
+		}
+	}
+
+	tok := New()
+	if err := json.Unmarshal(src, tok); err != nil {
+		return nil, fmt.Errorf(`openid.ParseUserInfo: failed to parse claims: %w`, err)
+	}
+	if validate {
+		if err := jwt.Validate(tok, validateOptions...); err != nil {
+			return nil, fmt.Errorf(`openid.ParseUserInfo: %w`, err)
+		}
+	}
+	return tok, nil
+}
+
+// ParseUserInfoResponse reads the body of the UserInfo response `res`, and
+// parses it using `openid.ParseUserInfo()`. An error is returned if the
+// status code of `res` is not 200. The body is always closed.
+func ParseUserInfoResponse(res *http.Response, options ...jwt.ParseOption) (Token, error) {
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`openid.ParseUserInfoResponse: unexpected status %d`, res.StatusCode)
+	}
+
+	src, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf(`openid.ParseUserInfoResponse: failed to read body: %w`, err)
+	}
+	return ParseUserInfo(src, res.Header.Get(`Content-Type`), options...)
+}
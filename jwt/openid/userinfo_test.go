@@ -0,0 +1,117 @@
+package openid_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserInfo(t *testing.T) {
+	const issuer = `https://server.example.com`
+	const clientID = `s6BhdRkqt3`
+	const subject = `248289761001`
+
+	signKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	encKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok := openid.New()
+	require.NoError(t, tok.Set(openid.IssuerKey, issuer), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(openid.AudienceKey, clientID), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(openid.SubjectKey, subject), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(openid.EmailKey, `janedoe@example.com`), `tok.Set should succeed`)
+
+	plain := []byte(`{"sub":"` + subject + `","email":"janedoe@example.com"}`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, signKey))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+	nested, err := jwt.SignAndEncrypt(tok, jwt.WithKey(jwa.RS256, signKey), jwt.WithKey(jwa.RSA_OAEP, encKey.PublicKey))
+	require.NoError(t, err, `jwt.SignAndEncrypt should succeed`)
+	encryptedOnly, err := jwe.Encrypt(plain, jwe.WithKey(jwa.RSA_OAEP, encKey.PublicKey))
+	require.NoError(t, err, `jwe.Encrypt should succeed`)
+
+	options := []jwt.ParseOption{
+		jwt.WithKey(jwa.RS256, signKey.PublicKey),
+		jwt.WithKey(jwa.RSA_OAEP, encKey),
+		jwt.WithSubject(subject),
+	}
+
+	testcases := []struct {
+		Name        string
+		Src         []byte
+		ContentType string
+		Options     []jwt.ParseOption
+		Error       bool
+	}{
+		{Name: `plain JSON`, Src: plain, ContentType: `application/json`},
+		{Name: `plain JSON with charset`, Src: plain, ContentType: `application/json; charset=utf-8`},
+		{Name: `signed`, Src: signed, ContentType: `application/jwt`},
+		{Name: `signed and encrypted`, Src: nested, ContentType: `application/jwt`},
+		{
+			Name:        `signed with issuer and audience`,
+			Src:         signed,
+			ContentType: `application/jwt`,
+			Options:     []jwt.ParseOption{jwt.WithIssuer(issuer), jwt.WithAudience(clientID)},
+		},
+		{Name: `encrypted only`, Src: encryptedOnly, ContentType: `application/jwt`, Error: true},
+		{Name: `JWT sent as JSON`, Src: signed, ContentType: `application/json`, Error: true},
+		{Name: `JSON sent as JWT`, Src: plain, ContentType: `application/jwt`, Error: true},
+		{Name: `unsupported content type`, Src: plain, ContentType: `text/plain`, Error: true},
+		{Name: `missing content type`, Src: plain, Error: true},
+		{
+			Name:        `subject mismatch (plain JSON)`,
+			Src:         plain,
+			ContentType: `application/json`,
+			Options:     []jwt.ParseOption{jwt.WithSubject(`someone else`)},
+			Error:       true,
+		},
+		{
+			Name:        `subject mismatch (signed)`,
+			Src:         signed,
+			ContentType: `application/jwt`,
+			Options:     []jwt.ParseOption{jwt.WithSubject(`someone else`)},
+			Error:       true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			ui, err := openid.ParseUserInfo(tc.Src, tc.ContentType, append(options[:len(options):len(options)], tc.Options...)...)
+			if tc.Error {
+				require.Error(t, err, `openid.ParseUserInfo should fail`)
+				return
+			}
+			require.NoError(t, err, `openid.ParseUserInfo should succeed`)
+			require.Equal(t, subject, ui.Subject())
+			require.Equal(t, `janedoe@example.com`, ui.Email())
+		})
+	}
+
+	t.Run("skip validation of plain JSON", func(t *testing.T) {
+		ui, err := openid.ParseUserInfo(plain, `application/json`, jwt.WithSubject(`someone else`), jwt.WithValidate(false))
+		require.NoError(t, err, `openid.ParseUserInfo should succeed`)
+		require.Equal(t, subject, ui.Subject())
+	})
+	t.Run("ParseUserInfoResponse", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set(`Content-Type`, `application/jwt`)
+		_, _ = rec.Write(nested)
+		ui, err := openid.ParseUserInfoResponse(rec.Result(), options...)
+		require.NoError(t, err, `openid.ParseUserInfoResponse should succeed`)
+		require.Equal(t, `janedoe@example.com`, ui.Email())
+
+		rec = httptest.NewRecorder()
+		rec.Header().Set(`WWW-Authenticate`, `Bearer error="invalid_token"`)
+		rec.WriteHeader(http.StatusUnauthorized)
+		_, err = openid.ParseUserInfoResponse(rec.Result(), options...)
+		require.Error(t, err, `openid.ParseUserInfoResponse should fail for error responses`)
+	})
+}
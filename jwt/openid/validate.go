@@ -0,0 +1,342 @@
+package openid
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+var errInvalidNonce = jwt.NewValidationError(errors.New(`"nonce" claim does not match`))
+var errInvalidAuthorizedParty = jwt.NewValidationError(errors.New(`"azp" claim is missing or does not match the client ID`))
+var errInvalidHash = jwt.NewValidationError(errors.New(`"at_hash" or "c_hash" claim does not match`))
+var errInvalidACR = jwt.NewValidationError(errors.New(`"acr" claim is not acceptable`))
+var errAuthTimeTooOld = jwt.NewValidationError(errors.New(`"auth_time" claim is too old`))
+
+// ErrInvalidNonce returns the immutable error used when the `nonce`
+// claim is missing or does not match the value specified via
+// `openid.WithNonce()`.
+func ErrInvalidNonce() jwt.ValidationError {
+	return errInvalidNonce
+}
+
+// ErrInvalidAuthorizedParty returns the immutable error used when the
+// `azp` claim does not match the client ID, or when it is missing from
+// an ID Token with multiple audiences.
+func ErrInvalidAuthorizedParty() jwt.ValidationError {
+	return errInvalidAuthorizedParty
+}
+
+// ErrInvalidHash returns the immutable error used when the `at_hash` or
+// `c_hash` claim does not match the access token or authorization code.
+func ErrInvalidHash() jwt.ValidationError {
+	return errInvalidHash
+}
+
+// ErrInvalidACR returns the immutable error used when the `acr` claim
+// is missing or is not one of the values specified via `openid.WithACR()`.
+func ErrInvalidACR() jwt.ValidationError {
+	return errInvalidACR
+}
+
+// ErrAuthTimeTooOld returns the immutable error used when the End-User
+// authenticated earlier than allowed by `openid.WithMaxAuthAge()`.
+func ErrAuthTimeTooOld() jwt.ValidationError {
+	return errAuthTimeTooOld
+}
+
+// HalfHash computes the value of the `at_hash` and `c_hash` claims for
+// `v`: the base64url encoding of the left-most half of the hash of `v`,
+// using the hash function of the signature algorithm `alg`.
+func HalfHash(alg jwa.SignatureAlgorithm, v string) (string, error) {
+	h, ok := alg.HashFor()
+	if !ok {
+		return ``, fmt.Errorf(`unsupported signature algorithm %q`, alg)
+	}
+
+	hh := h.New()
+	hh.Write([]byte(v))
+	sum := hh.Sum(nil)
+	return base64.EncodeToString(sum[:len(sum)/2]), nil
+}
+
+type idTokenValidator struct {
+	clientID    string
+	nonce       *string
+	accessToken *string
+	code        *string
+	acr         []string
+	maxAuthAge  time.Duration
+	alg         jwa.SignatureAlgorithm
+}
+
+// ValidateIDToken validates the ID Token `t` according to OpenID Connect
+// Core 1.0 Section 3.1.3.7. In addition to the checks performed by
+// `jwt.Validate()`:
+//
+//   - `iss`, `sub`, `aud`, `exp` and `iat` must be present
+//   - `iss` must be equal to `issuer`
+//   - `aud` must contain `clientID`
+//   - if `aud` contains multiple values, `azp` must be present
+//   - if `azp` is present, it must be equal to `clientID`
+//
+// The `nonce`, `at_hash`, `c_hash`, `acr` and `auth_time` claims are
+// validated if the corresponding options are specified. In particular,
+// `at_hash` and `c_hash` are required if `openid.WithAccessToken()` or
+// `openid.WithCode()` are specified. `jwt.ValidateOption`s
+// (e.g. `jwt.WithClock()`) may be passed as well.
+//
+// Validating `at_hash` and `c_hash` requires the signature algorithm of
+// the ID Token. Specify it using `openid.WithSignatureAlgorithm()`, or use
+// `openid.ParseIDToken()`, which obtains it from the JWS headers.
+func ValidateIDToken(t jwt.Token, issuer, clientID string, options ...IDTokenOption) error {
+	validateOptions, parseOptions, err := idTokenOptions(`openid.ValidateIDToken`, issuer, clientID, options)
+	if err != nil {
+		return err
+	}
+	if len(parseOptions) > 0 {
+		return fmt.Errorf(`openid.ValidateIDToken: invalid option %T`, parseOptions[0])
+	}
+	return jwt.Validate(t, validateOptions...)
+}
+
+// ParseIDToken parses the ID Token in `src` using `jwt.Parse()`, and
+// validates it as described in `openid.ValidateIDToken()`. The returned
+// token is an `openid.Token`.
+//
+// The source of the verification keys must be specified in `options`
+// (e.g. `jwt.WithKeySet()`). `jwt.ParseOption`s may be passed as well,
+// but `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func ParseIDToken(src []byte, issuer, clientID string, options ...IDTokenOption) (Token, error) {
+	validateOptions, parseOptions, err := idTokenOptions(`openid.ParseIDToken`, issuer, clientID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	parseOptions = append(parseOptions, jwt.WithToken(New()))
+	for _, o := range validateOptions {
+		parseOptions = append(parseOptions, o)
+	}
+	tok, err := jwt.Parse(src, parseOptions...)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:forcetypeassert
+	return tok.(Token), nil
+}
+
+func idTokenOptions(fn, issuer, clientID string, options []IDTokenOption) ([]jwt.ValidateOption, []jwt.ParseOption, error) {
+	if issuer == `` || clientID == `` {
+		return nil, nil, fmt.Errorf(`%s: issuer and clientID must be specified`, fn)
+	}
+
+	v := idTokenValidator{clientID: clientID}
+	var validateOptions []jwt.ValidateOption
+	var parseOptions []jwt.ParseOption
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, nil, fmt.Errorf(`%s: jwt.WithVerify() and jwt.WithValidate() may not be specified`, fn)
+		}
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identNonce{}:
+			s := o.Value().(string)
+			v.nonce = &s
+		case identAccessToken{}:
+			s := o.Value().(string)
+			v.accessToken = &s
+		case identCode{}:
+			s := o.Value().(string)
+			v.code = &s
+		case identACR{}:
+			v.acr = o.Value().([]string)
+		case identMaxAuthAge{}:
+			v.maxAuthAge = o.Value().(time.Duration)
+		case identSignatureAlgorithm{}:
+			v.alg = o.Value().(jwa.SignatureAlgorithm)
+		default:
+			if vo, ok := o.(jwt.ValidateOption); ok {
+				validateOptions = append(validateOptions, vo)
+				continue
+			}
+			po, ok := o.(jwt.ParseOption)
+			if !ok {
+				return nil, nil, fmt.Errorf(`%s: invalid option %T`, fn, o)
+			}
+			parseOptions = append(parseOptions, po)
+		}
+	}
+
+	validateOptions = append(validateOptions,
+		jwt.WithRequiredClaims(IssuerKey, SubjectKey, AudienceKey, ExpirationKey, IssuedAtKey),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithValidator(&v),
+	)
+	return validateOptions, parseOptions, nil
+}
+
+func (v *idTokenValidator) Validate(ctx context.Context, t jwt.Token) jwt.ValidationError {
+	if err := validateAuthorizedParty(t, v.clientID); err != nil {
+		return err
+	}
+
+	if v.nonce != nil {
+		if err := validateNonce(t, *v.nonce); err != nil {
+			return err
+		}
+	}
+
+	if err := v.validateHash(ctx, t, AccessTokenHashKey, v.accessToken); err != nil {
+		return err
+	}
+	if err := v.validateHash(ctx, t, CodeHashKey, v.code); err != nil {
+		return err
+	}
+
+	if len(v.acr) > 0 {
+		acr, _ := stringClaim(t, ACRKey)
+		var found bool
+		for _, accepted := range v.acr {
+			if acr != `` && acr == accepted {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errInvalidACR
+		}
+	}
+
+	if v.maxAuthAge > 0 {
+		authTime, ok := timeClaim(t, AuthTimeKey)
+		if !ok {
+			return jwt.ErrMissingRequiredClaim(AuthTimeKey)
+		}
+		now := jwt.ValidationCtxClock(ctx).Now()
+		if now.Sub(authTime) > v.maxAuthAge+jwt.ValidationCtxSkew(ctx) {
+			return errAuthTimeTooOld
+		}
+	}
+	return nil
+}
+
+// IsAuthorizedPartyValid returns a `jwt.Validator` that checks the `azp`
+// claim as required by OpenID Connect Core 1.0 Section 3.1.3.7: if `aud`
+// contains multiple values, `azp` must be present, and if `azp` is
+// present, it must be equal to `clientID`.
+//
+// This check is always performed by `openid.ValidateIDToken()` and
+// `openid.ParseIDToken()`. Use this validator (or `openid.WithAuthorizedParty()`)
+// when validating ID Tokens using `jwt.Parse()` or `jwt.Validate()` directly.
+func IsAuthorizedPartyValid(clientID string) jwt.Validator {
+	return jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
+		return validateAuthorizedParty(t, clientID)
+	})
+}
+
+// IsNonceValid returns a `jwt.Validator` that checks that the `nonce`
+// claim is present, and matches `nonce`, the value sent in the
+// authentication request.
+//
+// This check is performed by `openid.ValidateIDToken()` and
+// `openid.ParseIDToken()` when `openid.WithNonce()` is specified. Use this
+// validator when validating ID Tokens using `jwt.Parse()` or
+// `jwt.Validate()` directly.
+func IsNonceValid(nonce string) jwt.Validator {
+	return jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
+		return validateNonce(t, nonce)
+	})
+}
+
+func validateNonce(t jwt.Token, expected string) jwt.ValidationError {
+	nonce, ok := stringClaim(t, NonceKey)
+	if !ok || subtle.ConstantTimeCompare([]byte(nonce), []byte(expected)) != 1 {
+		return errInvalidNonce
+	}
+	return nil
+}
+
+func validateAuthorizedParty(t jwt.Token, clientID string) jwt.ValidationError {
+	azp, hasAZP := stringClaim(t, AuthorizedPartyKey)
+	if (len(t.Audience()) > 1 && !hasAZP) || (hasAZP && azp != clientID) {
+		return errInvalidAuthorizedParty
+	}
+	return nil
+}
+
+func (v *idTokenValidator) validateHash(ctx context.Context, t jwt.Token, name string, value *string) jwt.ValidationError {
+	if value == nil {
+		return nil
+	}
+	// the value was supplied to bind it to the ID Token, which
+	// cannot be done without the claim
+	actual, ok := stringClaim(t, name)
+	if !ok {
+		return jwt.ErrMissingRequiredClaim(name)
+	}
+
+	alg := v.alg
+	if alg == `` {
+		hdrs, ok := jwt.ValidationCtxHeaders(ctx)
+		if !ok {
+			return jwt.NewValidationError(fmt.Errorf(`signature algorithm is required to validate %q claim`, name))
+		}
+		alg = hdrs.Algorithm()
+	}
+
+	expected, err := HalfHash(alg, *value)
+	if err != nil {
+		return jwt.NewValidationError(fmt.Errorf(`failed to validate %q claim: %w`, name, err))
+	}
+	if subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) != 1 {
+		return errInvalidHash
+	}
+	return nil
+}
+
+func stringClaim(t jwt.Token, name string) (string, bool) {
+	v, ok := t.Get(name)
+	if !ok {
+		return ``, false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+func timeClaim(t jwt.Token, name string) (time.Time, bool) {
+	v, ok := t.Get(name)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	var sec float64
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case float64:
+		sec = v
+	case int64:
+		sec = float64(v)
+	case int:
+		sec = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		sec = f
+	default:
+		return time.Time{}, false
+	}
+	whole, frac := math.Modf(sec)
+	return time.Unix(int64(whole), int64(frac*1e9)), true
+}
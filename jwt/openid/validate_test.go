@@ -0,0 +1,184 @@
+package openid_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHalfHash(t *testing.T) {
+	// Examples from OpenID Connect Core 1.0 Appendix A.4
+	v, err := openid.HalfHash(jwa.RS256, `jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y`)
+	require.NoError(t, err, `openid.HalfHash should succeed`)
+	require.Equal(t, `77QmUPtjPfzWtF2AnpK9RQ`, v)
+
+	v, err = openid.HalfHash(jwa.RS256, `Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk`)
+	require.NoError(t, err, `openid.HalfHash should succeed`)
+	require.Equal(t, `LDktKdoQak3Pk0cnXxCltA`, v)
+
+	_, err = openid.HalfHash(jwa.NoSignature, `foo`)
+	require.Error(t, err, `openid.HalfHash should fail for "none"`)
+}
+
+func TestWithAuthorizedParty(t *testing.T) {
+	const clientID = `s6BhdRkqt3`
+
+	testcases := []struct {
+		Name     string
+		Audience []string
+		AZP      string
+		Error    bool
+	}{
+		{Name: `single audience without azp`, Audience: []string{clientID}},
+		{Name: `single audience with azp`, Audience: []string{clientID}, AZP: clientID},
+		{Name: `single audience with other azp`, Audience: []string{clientID}, AZP: `other`, Error: true},
+		{Name: `multiple audiences without azp`, Audience: []string{clientID, `other`}, Error: true},
+		{Name: `multiple audiences with azp`, Audience: []string{clientID, `other`}, AZP: clientID},
+		{Name: `multiple audiences with other azp`, Audience: []string{clientID, `other`}, AZP: `other`, Error: true},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			b := openid.NewBuilder().Audience(tc.Audience)
+			if tc.AZP != `` {
+				b = b.AuthorizedParty(tc.AZP)
+			}
+			tok, err := b.Build()
+			require.NoError(t, err, `Build should succeed`)
+
+			err = jwt.Validate(tok, jwt.WithAudience(clientID), openid.WithAuthorizedParty(clientID))
+			if tc.Error {
+				require.True(t, errors.Is(err, openid.ErrInvalidAuthorizedParty()), `jwt.Validate should fail with ErrInvalidAuthorizedParty`)
+				return
+			}
+			require.NoError(t, err, `jwt.Validate should succeed`)
+			require.Equal(t, tc.AZP, tok.AuthorizedParty())
+		})
+	}
+}
+
+func TestValidateIDToken(t *testing.T) {
+	const issuer = `https://server.example.com`
+	const clientID = `s6BhdRkqt3`
+	const accessToken = `jHkWEdUXMU1BwAsC4vtUsZwnNvTIxEl0z9K3vx5KF0Y`
+	const code = `Qcb0Orv1zh30vL1MPRsbm-diHiMwcLyZvn1arpZv-Jxf_11jnpEX3Tgfvk`
+
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	now := time.Now()
+	build := func(t *testing.T, claims map[string]interface{}) openid.Token {
+		t.Helper()
+		tok := openid.New()
+		require.NoError(t, tok.Set(openid.IssuerKey, issuer), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.SubjectKey, `24400320`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.AudienceKey, clientID), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.ExpirationKey, now.Add(time.Hour)), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(openid.IssuedAtKey, now), `tok.Set should succeed`)
+		for k, v := range claims {
+			require.NoError(t, tok.Set(k, v), `tok.Set should succeed`)
+		}
+		return tok
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		tok := build(t, nil)
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID), `openid.ValidateIDToken should succeed`)
+		require.Error(t, openid.ValidateIDToken(tok, `https://other.example.com`, clientID), `openid.ValidateIDToken should fail for a different issuer`)
+		require.Error(t, openid.ValidateIDToken(tok, issuer, `other`), `openid.ValidateIDToken should fail for a different client`)
+
+		require.NoError(t, tok.Remove(openid.SubjectKey), `tok.Remove should succeed`)
+		require.Error(t, openid.ValidateIDToken(tok, issuer, clientID), `openid.ValidateIDToken should fail without sub`)
+	})
+	t.Run("azp", func(t *testing.T) {
+		tok := build(t, map[string]interface{}{openid.AudienceKey: []string{clientID, `other`}})
+		err := openid.ValidateIDToken(tok, issuer, clientID)
+		require.True(t, errors.Is(err, openid.ErrInvalidAuthorizedParty()), `azp should be required for multiple audiences`)
+
+		require.NoError(t, tok.Set(openid.AuthorizedPartyKey, clientID), `tok.Set should succeed`)
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID), `openid.ValidateIDToken should succeed`)
+
+		tok = build(t, map[string]interface{}{openid.AuthorizedPartyKey: `other`})
+		err = openid.ValidateIDToken(tok, issuer, clientID)
+		require.True(t, errors.Is(err, openid.ErrInvalidAuthorizedParty()), `azp should match the client ID`)
+	})
+	t.Run("nonce", func(t *testing.T) {
+		tok := build(t, map[string]interface{}{openid.NonceKey: `n-0S6_WzA2Mj`})
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID, openid.WithNonce(`n-0S6_WzA2Mj`)), `openid.ValidateIDToken should succeed`)
+		err := openid.ValidateIDToken(tok, issuer, clientID, openid.WithNonce(`other`))
+		require.True(t, errors.Is(err, openid.ErrInvalidNonce()), `nonce should match`)
+
+		err = openid.ValidateIDToken(build(t, nil), issuer, clientID, openid.WithNonce(`n-0S6_WzA2Mj`))
+		require.True(t, errors.Is(err, openid.ErrInvalidNonce()), `nonce should be required`)
+
+		require.NoError(t, jwt.Validate(tok, jwt.WithValidator(openid.IsNonceValid(`n-0S6_WzA2Mj`))), `jwt.Validate should succeed`)
+		err = jwt.Validate(tok, jwt.WithValidator(openid.IsNonceValid(`other`)))
+		require.True(t, errors.Is(err, openid.ErrInvalidNonce()), `jwt.Validate should fail with ErrInvalidNonce`)
+	})
+	t.Run("at_hash and c_hash", func(t *testing.T) {
+		atHash, err := openid.HalfHash(jwa.RS256, accessToken)
+		require.NoError(t, err, `openid.HalfHash should succeed`)
+		cHash, err := openid.HalfHash(jwa.RS256, code)
+		require.NoError(t, err, `openid.HalfHash should succeed`)
+
+		tok := build(t, map[string]interface{}{
+			openid.AccessTokenHashKey: atHash,
+			openid.CodeHashKey:        cHash,
+		})
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		parsed, err := openid.ParseIDToken(signed, issuer, clientID,
+			jwt.WithKey(jwa.RS256, &key.PublicKey),
+			openid.WithAccessToken(accessToken),
+			openid.WithCode(code),
+		)
+		require.NoError(t, err, `openid.ParseIDToken should succeed`)
+		require.Equal(t, `24400320`, parsed.Subject())
+
+		_, err = openid.ParseIDToken(signed, issuer, clientID, jwt.WithKey(jwa.RS256, &key.PublicKey), openid.WithAccessToken(`other`))
+		require.True(t, errors.Is(err, openid.ErrInvalidHash()), `at_hash should match`)
+		_, err = openid.ParseIDToken(signed, issuer, clientID, jwt.WithKey(jwa.RS256, &key.PublicKey), openid.WithCode(`other`))
+		require.True(t, errors.Is(err, openid.ErrInvalidHash()), `c_hash should match`)
+		_, err = openid.ParseIDToken(signed, issuer, clientID, jwt.WithKey(jwa.RS256, &key.PublicKey), jwt.WithValidate(false))
+		require.Error(t, err, `openid.ParseIDToken should reject jwt.WithValidate`)
+
+		// Already parsed tokens require the signature algorithm
+		require.Error(t, openid.ValidateIDToken(tok, issuer, clientID, openid.WithAccessToken(accessToken)), `openid.ValidateIDToken should fail without the algorithm`)
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID, openid.WithAccessToken(accessToken), openid.WithSignatureAlgorithm(jwa.RS256)), `openid.ValidateIDToken should succeed`)
+
+		// the claims are required when the values are specified
+		tok = build(t, nil)
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID, openid.WithSignatureAlgorithm(jwa.RS256)), `openid.ValidateIDToken should succeed without at_hash`)
+		err = openid.ValidateIDToken(tok, issuer, clientID, openid.WithAccessToken(accessToken), openid.WithSignatureAlgorithm(jwa.RS256))
+		require.True(t, errors.Is(err, jwt.ErrMissingRequiredClaim(openid.AccessTokenHashKey)), `openid.ValidateIDToken should fail without at_hash`)
+		err = openid.ValidateIDToken(tok, issuer, clientID, openid.WithCode(code), openid.WithSignatureAlgorithm(jwa.RS256))
+		require.True(t, errors.Is(err, jwt.ErrMissingRequiredClaim(openid.CodeHashKey)), `openid.ValidateIDToken should fail without c_hash`)
+	})
+	t.Run("acr and auth_time", func(t *testing.T) {
+		tok := build(t, map[string]interface{}{
+			openid.ACRKey:      `urn:mace:incommon:iap:silver`,
+			openid.AuthTimeKey: now.Add(-10 * time.Minute).Unix(),
+		})
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID,
+			openid.WithACR(`urn:mace:incommon:iap:silver`, `urn:mace:incommon:iap:gold`),
+			openid.WithMaxAuthAge(time.Hour),
+		), `openid.ValidateIDToken should succeed`)
+
+		err := openid.ValidateIDToken(tok, issuer, clientID, openid.WithACR(`urn:mace:incommon:iap:gold`))
+		require.True(t, errors.Is(err, openid.ErrInvalidACR()), `acr should be acceptable`)
+
+		err = openid.ValidateIDToken(tok, issuer, clientID, openid.WithMaxAuthAge(time.Minute))
+		require.True(t, errors.Is(err, openid.ErrAuthTimeTooOld()), `auth_time should be recent`)
+		require.NoError(t, openid.ValidateIDToken(tok, issuer, clientID, openid.WithMaxAuthAge(time.Minute), jwt.WithAcceptableSkew(10*time.Minute)), `skew should be taken into account`)
+
+		err = openid.ValidateIDToken(build(t, nil), issuer, clientID, openid.WithMaxAuthAge(time.Hour))
+		require.True(t, errors.Is(err, jwt.ErrMissingRequiredClaim(openid.AuthTimeKey)), `auth_time should be required`)
+	})
+}
@@ -0,0 +1,179 @@
+package openid
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+)
+
+// WebFingerPath is the path where WebFinger resources are queried (RFC 7033 Section 10.1)
+const WebFingerPath = `/.well-known/webfinger`
+
+// IssuerRel is the link relation type of the issuer in the WebFinger
+// response (OpenID Connect Discovery 1.0 Section 2)
+const IssuerRel = `http://openid.net/specs/connect/1.0/issuer`
+
+// maxWebFingerSize is the maximum size of the WebFinger response that is read
+const maxWebFingerSize = 1 << 20
+
+// NormalizeIdentifier normalizes the identifier `input` entered by the
+// End-User, following OpenID Connect Discovery 1.0 Section 2.1, and returns
+// the resource that is queried using WebFinger, and the host (including
+// the port, if any) of the WebFinger endpoint.
+//
+// E-mail address like identifiers such as `joe@example.com` are normalized
+// into `acct:` URIs, and other identifiers into `https` URLs without the
+// fragment:
+//
+//	acct:joe@example.com      -> acct:joe@example.com, example.com
+//	joe@example.com           -> acct:joe@example.com, example.com
+//	example.com:8080          -> https://example.com:8080, example.com:8080
+//	https://example.com/joe#x -> https://example.com/joe, example.com
+func NormalizeIdentifier(input string) (resource string, host string, err error) {
+	input = strings.TrimSpace(input)
+	if input == `` {
+		return ``, ``, fmt.Errorf(`openid.NormalizeIdentifier: identifier must not be empty`)
+	}
+	if strings.HasPrefix(input, `#`) || strings.HasPrefix(input, `=`) || strings.HasPrefix(input, `@`) || strings.HasPrefix(input, `!`) {
+		return ``, ``, fmt.Errorf(`openid.NormalizeIdentifier: XRI identifiers are not supported`)
+	}
+
+	if rest, ok := cutPrefixFold(input, `acct:`); ok {
+		i := strings.LastIndexByte(rest, '@')
+		if i <= 0 || i == len(rest)-1 {
+			return ``, ``, fmt.Errorf(`openid.NormalizeIdentifier: invalid acct URI %q`, input)
+		}
+		return `acct:` + rest, rest[i+1:], nil
+	}
+
+	// Identifiers without a scheme are interpreted as
+	// [userinfo "@"] host [":" port] path-abempty ["?" query] ["#" fragment]
+	raw := input
+	if !strings.Contains(input, `://`) {
+		raw = `https://` + input
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ``, ``, fmt.Errorf(`openid.NormalizeIdentifier: failed to parse %q: %w`, input, err)
+	}
+	if u.Host == `` {
+		return ``, ``, fmt.Errorf(`openid.NormalizeIdentifier: %q does not contain a host`, input)
+	}
+
+	if raw != input && u.User != nil && u.Port() == `` && (u.Path == `` || u.Path == `/`) && u.RawQuery == `` {
+		return `acct:` + u.User.String() + `@` + u.Host, u.Host, nil
+	}
+
+	u.Fragment = ``
+	u.RawFragment = ``
+	return u.String(), u.Host, nil
+}
+
+// ResolveIssuer determines the issuer of the End-User identified by
+// `identifier` using WebFinger, as described in OpenID Connect Discovery
+// 1.0 Section 2. The identifier is normalized using
+// `openid.NormalizeIdentifier()`, and the WebFinger endpoint of its host
+// is queried over https.
+//
+// The returned issuer can be used to obtain the configuration and the keys
+// of the OpenID Provider, e.g. using `jwt.NewIssuerKeyProvider()`. As the
+// WebFinger response is not signed, the issuer must be checked against the
+// `iss` claim of the ID Tokens it issues, and the list of trusted issuers
+// should be restricted where possible.
+//
+// The HTTP client may be specified via `openid.WithHTTPClient()`. By default
+// http.DefaultClient is used.
+func ResolveIssuer(ctx context.Context, identifier string, options ...ResolveOption) (string, error) {
+	var client HTTPClient = http.DefaultClient
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			if v, _ := o.Value().(HTTPClient); v != nil {
+				client = v
+			}
+		}
+	}
+
+	resource, host, err := NormalizeIdentifier(identifier)
+	if err != nil {
+		return ``, fmt.Errorf(`openid.ResolveIssuer: %w`, err)
+	}
+
+	query := url.Values{}
+	query.Set(`resource`, resource)
+	query.Set(`rel`, IssuerRel)
+	u := url.URL{
+		Scheme:   `https`,
+		Host:     host,
+		Path:     WebFingerPath,
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return ``, fmt.Errorf(`openid.ResolveIssuer: failed to create request: %w`, err)
+	}
+	req.Header.Set(`Accept`, `application/jrd+json, application/json`)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return ``, fmt.Errorf(`openid.ResolveIssuer: failed to query %q: %w`, host, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ``, fmt.Errorf(`openid.ResolveIssuer: failed to query %q: unexpected status code %d`, host, res.StatusCode)
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxWebFingerSize))
+	if err != nil {
+		return ``, fmt.Errorf(`openid.ResolveIssuer: failed to read response: %w`, err)
+	}
+	var jrd struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(buf, &jrd); err != nil {
+		return ``, fmt.Errorf(`openid.ResolveIssuer: failed to parse response: %w`, err)
+	}
+
+	for _, link := range jrd.Links {
+		if link.Rel != IssuerRel {
+			continue
+		}
+		if err := validateIssuerURL(link.Href); err != nil {
+			return ``, fmt.Errorf(`openid.ResolveIssuer: %w`, err)
+		}
+		return link.Href, nil
+	}
+	return ``, fmt.Errorf(`openid.ResolveIssuer: response for %q does not contain an issuer`, resource)
+}
+
+// validateIssuerURL checks that `v` is a valid issuer identifier: an
+// https URL without query or fragment (OpenID Connect Discovery 1.0 Section 3)
+func validateIssuerURL(v string) error {
+	u, err := url.Parse(v)
+	if err != nil {
+		return fmt.Errorf(`invalid issuer %q: %w`, v, err)
+	}
+	if u.Scheme != `https` || u.Host == `` {
+		return fmt.Errorf(`invalid issuer %q: must be an https URL`, v)
+	}
+	if u.RawQuery != `` || u.Fragment != `` || strings.ContainsAny(v, `?#`) {
+		return fmt.Errorf(`invalid issuer %q: must not contain query or fragment`, v)
+	}
+	return nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,86 @@
+package openid_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIdentifier(t *testing.T) {
+	testcases := []struct {
+		Input    string
+		Resource string
+		Host     string
+		Error    bool
+	}{
+		// OpenID Connect Discovery 1.0 Appendix A
+		{Input: `joe@example.com`, Resource: `acct:joe@example.com`, Host: `example.com`},
+		{Input: `https://example.com/joe`, Resource: `https://example.com/joe`, Host: `example.com`},
+		{Input: `example.com:8080`, Resource: `https://example.com:8080`, Host: `example.com:8080`},
+		{Input: `acct:juliet%40capulet.example@shopping.example.com`, Resource: `acct:juliet%40capulet.example@shopping.example.com`, Host: `shopping.example.com`},
+		{Input: `https://example.com/joe#fragment`, Resource: `https://example.com/joe`, Host: `example.com`},
+		{Input: `example.com`, Resource: `https://example.com`, Host: `example.com`},
+		{Input: `example.com/joe?x=1`, Resource: `https://example.com/joe?x=1`, Host: `example.com`},
+		{Input: `joe@example.com:8080`, Resource: `https://joe@example.com:8080`, Host: `example.com:8080`},
+		{Input: ``, Error: true},
+		{Input: `=example`, Error: true},
+		{Input: `acct:example.com`, Error: true},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Input, func(t *testing.T) {
+			resource, host, err := openid.NormalizeIdentifier(tc.Input)
+			if tc.Error {
+				require.Error(t, err, `openid.NormalizeIdentifier should fail`)
+				return
+			}
+			require.NoError(t, err, `openid.NormalizeIdentifier should succeed`)
+			require.Equal(t, tc.Resource, resource)
+			require.Equal(t, tc.Host, host)
+		})
+	}
+}
+
+func TestResolveIssuer(t *testing.T) {
+	var issuer string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != openid.WebFingerPath || r.URL.Query().Get(`rel`) != openid.IssuerRel {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(`Content-Type`, `application/jrd+json`)
+		switch resource := r.URL.Query().Get(`resource`); resource {
+		case `acct:joe@` + r.Host:
+			fmt.Fprintf(w, `{"subject":%q,"links":[{"rel":"self","href":"https://example.com/joe"},{"rel":%q,"href":%q}]}`, resource, openid.IssuerRel, issuer)
+		case `acct:insecure@` + r.Host:
+			fmt.Fprintf(w, `{"subject":%q,"links":[{"rel":%q,"href":"http://example.com"}]}`, resource, openid.IssuerRel)
+		default:
+			fmt.Fprintf(w, `{"subject":%q,"links":[]}`, resource)
+		}
+	}))
+	defer srv.Close()
+	issuer = srv.URL + `/tenant`
+
+	// the identifiers contain the port of the server, and are
+	// therefore specified as acct URIs
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err, `url.Parse should succeed`)
+	ctx := context.Background()
+	client := openid.WithHTTPClient(srv.Client())
+
+	iss, err := openid.ResolveIssuer(ctx, `acct:joe@`+u.Host, client)
+	require.NoError(t, err, `openid.ResolveIssuer should succeed`)
+	require.Equal(t, issuer, iss)
+
+	_, err = openid.ResolveIssuer(ctx, `acct:insecure@`+u.Host, client)
+	require.Error(t, err, `issuers that are not https URLs should be rejected`)
+
+	_, err = openid.ResolveIssuer(ctx, `acct:unknown@`+u.Host, client)
+	require.Error(t, err, `responses without an issuer should be rejected`)
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -13,17 +14,38 @@ import (
 
 type identKey struct{}
 type identKeySet struct{}
+type identKeySetProvider struct{}
 type identTypedClaim struct{}
 type identVerifyAuto struct{}
 
 func toSignOptions(options ...Option) ([]jws.SignOption, error) {
+	var jwsHeaders jws.Headers
+	for _, option := range options {
+		if option.Ident() == (identJwsHeaders{}) {
+			//nolint:forcetypeassert
+			jwsHeaders = option.Value().(jws.Headers)
+		}
+	}
+
 	var soptions []jws.SignOption
 	for _, option := range options {
 		//nolint:forcetypeassert
 		switch option.Ident() {
+		case identSignOption{}:
+			soptions = append(soptions, option.Value().(jws.SignOption))
 		case identKey{}:
 			wk := option.Value().(*withKey) // this always succeeds
 			var wksoptions []jws.WithKeySuboption
+			if jwsHeaders != nil {
+				// Each key gets its own copy, as the headers are modified
+				// (e.g. `typ` is added) during serialization.
+				// Suboptions given to jwt.WithKey() take precedence
+				hdrs := jws.NewHeaders()
+				if err := jwsHeaders.Copy(context.Background(), hdrs); err != nil {
+					return nil, fmt.Errorf(`failed to copy headers specified via jwt.WithJwsHeaders: %w`, err)
+				}
+				wksoptions = append(wksoptions, jws.WithProtectedHeaders(hdrs))
+			}
 			for _, subopt := range wk.options {
 				wksopt, ok := subopt.(jws.WithKeySuboption)
 				if !ok {
@@ -43,6 +65,8 @@ func toEncryptOptions(options ...Option) ([]jwe.EncryptOption, error) {
 	for _, option := range options {
 		//nolint:forcetypeassert
 		switch option.Ident() {
+		case identEncryptOption{}:
+			soptions = append(soptions, option.Value().(jwe.EncryptOption))
 		case identKey{}:
 			wk := option.Value().(*withKey) // this always succeeds
 			var wksoptions []jwe.WithKeySuboption
@@ -60,6 +84,35 @@ func toEncryptOptions(options ...Option) ([]jwe.EncryptOption, error) {
 	return soptions, nil
 }
 
+func toDecryptOptions(options ...Option) ([]jwe.DecryptOption, error) {
+	var doptions []jwe.DecryptOption
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identKey{}:
+			wk := option.Value().(*withKey) // this always succeeds
+			var wksoptions []jwe.WithKeySuboption
+			for _, subopt := range wk.options {
+				wksopt, ok := subopt.(jwe.WithKeySuboption)
+				if !ok {
+					return nil, fmt.Errorf(`expected optional arguments in jwt.WithKey to be jwe.WithKeySuboption, but got %T`, subopt)
+				}
+				wksoptions = append(wksoptions, wksopt)
+			}
+
+			doptions = append(doptions, jwe.WithKey(wk.alg, wk.key, wksoptions...))
+		}
+	}
+	return doptions, nil
+}
+
+// isKeyEncryptionKey returns true if the key specified via jwt.WithKey
+// is meant to be used for JWE (as opposed to JWS)
+func isKeyEncryptionKey(wk *withKey) bool {
+	_, ok := wk.alg.(jwa.KeyEncryptionAlgorithm)
+	return ok
+}
+
 func toVerifyOptions(options ...Option) ([]jws.VerifyOption, error) {
 	var voptions []jws.VerifyOption
 	for _, option := range options {
@@ -89,6 +142,18 @@ func toVerifyOptions(options ...Option) ([]jws.VerifyOption, error) {
 			}
 
 			voptions = append(voptions, jws.WithKeySet(wks.set, wkssoptions...))
+		case identKeySetProvider{}:
+			wksp := option.Value().(*withKeySetProvider) // this always succeeds
+			var wkssoptions []jws.WithKeySetSuboption
+			for _, subopt := range wksp.options {
+				wkssopt, ok := subopt.(jws.WithKeySetSuboption)
+				if !ok {
+					return nil, fmt.Errorf(`expected optional arguments in jwt.WithKeySetProvider to be jws.WithKeySetSuboption, but got %T`, subopt)
+				}
+				wkssoptions = append(wkssoptions, wkssopt)
+			}
+
+			voptions = append(voptions, jws.WithKeySetProvider(wksp.provider, wkssoptions...))
 		case identVerifyAuto{}:
 			// this one doesn't need conversion. just get the stored option
 			voptions = append(voptions, option.Value().(jws.VerifyOption))
@@ -120,6 +185,11 @@ type withKey struct {
 // In the above example, the creation of the option via `jwt.WithKey()` will work, but
 // when `jwt.Sign()` is called, the fact that you passed JWE suboptions will be
 // detected, and it will be an error.
+//
+// When used with jwt.Parse (and its siblings), keys specified with a
+// `jwa.KeyEncryptionAlgorithm` are used to decrypt nested (signed, then
+// encrypted) tokens, while keys specified with a `jwa.SignatureAlgorithm`
+// are used to verify the signature.
 func WithKey(alg jwa.KeyAlgorithm, key interface{}, suboptions ...Option) SignEncryptParseOption {
 	return &signEncryptParseOption{option.New(identKey{}, &withKey{
 		alg:     alg,
@@ -164,6 +234,40 @@ func WithKeySet(set jwk.Set, options ...interface{}) ParseOption {
 	})}
 }
 
+type withKeySetProvider struct {
+	provider jwk.Provider
+	options  []interface{}
+}
+
+// WithKeySetProvider forces the Parse method to verify the JWT message
+// using one of the keys in the `jwk.Set` obtained from the given
+// `jwk.Provider`. This allows static key sets, cached remote key sets,
+// and custom key resolvers to be used interchangeably.
+//
+// Once the `jwk.Set` is obtained, keys are matched against the JWS
+// message in the same manner as `jwt.WithKeySet()`, and it accepts
+// the same suboptions.
+func WithKeySetProvider(p jwk.Provider, options ...interface{}) ParseOption {
+	return &parseOption{option.New(identKeySetProvider{}, &withKeySetProvider{
+		provider: p,
+		options:  options,
+	})}
+}
+
+// WithIssuerKeyProvider forces the Parse method to verify the JWT message
+// using the keys of its issuer, which are obtained through OpenID Connect
+// Discovery by the given `jwt.IssuerKeyProvider`. This allows a single
+// API to accept tokens from multiple identity providers:
+//
+//	cache := jwk.NewCache(ctx)
+//	p, err := jwt.NewIssuerKeyProvider(cache, []string{`https://idp1.example.com`, `https://idp2.example.com`})
+//	tok, err := jwt.Parse(src, jwt.WithIssuerKeyProvider(p))
+//
+// This is equivalent to `jwt.WithKeyProvider(p)`.
+func WithIssuerKeyProvider(p *IssuerKeyProvider) ParseOption {
+	return WithKeyProvider(p)
+}
+
 // WithIssuer specifies that expected issuer value. If not specified,
 // the value of issuer is not verified at all.
 func WithIssuer(s string) ValidateOption {
@@ -195,6 +299,14 @@ func WithClaimValue(name string, v interface{}) ValidateOption {
 	return WithValidator(ClaimValueIs(name, v))
 }
 
+// WithClaimsSchema specifies that the claims of the token must conform to
+// the JSON Schema `s`. See `jwt.MatchesSchema()` for details.
+//
+//	tok, err := jwt.Parse(src, jwt.WithKey(alg, key), jwt.WithClaimsSchema(schema))
+func WithClaimsSchema(s ClaimsSchema) ValidateOption {
+	return WithValidator(MatchesSchema(s))
+}
+
 type claimPair struct {
 	Name  string
 	Value interface{}
@@ -203,6 +315,7 @@ type claimPair struct {
 // WithTypedClaim allows a private claim to be parsed into the object type of
 // your choice. It works much like the RegisterCustomField, but the effect
 // is only applicable to the jwt.Parse function call which receives this option.
+// Like RegisterCustomField, `object` may be a `jwt.CustomDecoder`.
 //
 // While this can be extremely useful, this option should be used with caution:
 // There are many caveats that your entire team/user-base needs to be aware of,
@@ -234,6 +347,28 @@ func WithRequiredClaim(name string) ValidateOption {
 	return WithValidator(IsRequired(name))
 }
 
+// WithRequiredClaims specifies that all of the claims identified by
+// the given names must exist in the token. This includes standard claims
+// such as `exp`, which are otherwise only checked if they are present.
+// Only the existence of the claims is checked: the actual values
+// associated with the fields are not checked.
+//
+//	jwt.Validate(token, jwt.WithRequiredClaims(jwt.SubjectKey, jwt.ExpirationKey, "tenant", "scope"))
+//
+// The error for a missing claim matches `jwt.ErrRequiredClaim()`.
+func WithRequiredClaims(names ...string) ValidateOption {
+	return WithValidator(AreRequired(names...))
+}
+
+// WithRequiredScopes specifies that all of the given OAuth 2.0 scopes must
+// be granted to the token, either via the `scope` or the `scp` claim.
+// See `jwt.HasRequiredScopes()` for details.
+//
+//	jwt.Parse(src, jwt.WithKey(alg, key), jwt.WithRequiredScopes("read", "write"))
+func WithRequiredScopes(scopes ...string) ValidateOption {
+	return WithValidator(HasRequiredScopes(scopes...))
+}
+
 // WithMaxDelta specifies that given two claims `c1` and `c2` that represent time, the difference in
 // time.Duration must be less than equal to the value specified by `d`. If `c1` or `c2` is the
 // empty string, the current time (as computed by `time.Now` or the object passed via
@@ -270,6 +405,19 @@ func WithMinDelta(dur time.Duration, c1, c2 string) ValidateOption {
 	return WithValidator(MinDeltaIs(c1, c2, dur))
 }
 
+// WithMaxAge specifies that the token must have been issued no more than
+// `dur` ago, regardless of its `exp` claim. This allows you to enforce a
+// maximum token lifetime that is shorter than the one chosen by the issuer.
+//
+// The `iat` claim becomes required. Tokens whose `iat` is in the future
+// are rejected as well, as `iat` is always validated by `jwt.Validate()`.
+// Both checks allow for the leeway specified via `jwt.WithAcceptableSkew()`.
+//
+// This is equivalent to `jwt.WithMaxDelta(dur, "", jwt.IssuedAtKey)`.
+func WithMaxAge(dur time.Duration) ValidateOption {
+	return WithMaxDelta(dur, "", IssuedAtKey)
+}
+
 // WithVerifyAuto specifies that the JWS verification should be attempted
 // by using the data available in the JWS message. Currently only verification
 // method available is to use the keys available in the JWKS URL pointed
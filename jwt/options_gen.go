@@ -38,6 +38,26 @@ type globalOption struct {
 
 func (*globalOption) globalOption() {}
 
+// GlobalParseOption describes an Option that can be passed to `Settings()`
+// to change the default for all calls to `jwt.Parse()`, as well as to
+// `jwt.Parse()` itself to override the default for a single call.
+type GlobalParseOption interface {
+	Option
+	globalOption()
+	parseOption()
+	readFileOption()
+}
+
+type globalParseOption struct {
+	Option
+}
+
+func (*globalParseOption) globalOption() {}
+
+func (*globalParseOption) parseOption() {}
+
+func (*globalParseOption) readFileOption() {}
+
 // ParseOption describes an Option that can be passed to `jwt.Parse()`.
 // ParseOption also implements ReadFileOption, therefore it may be
 // safely pass them to `jwt.ReadFile()`
@@ -102,6 +122,19 @@ type signOption struct {
 
 func (*signOption) signOption() {}
 
+// TokenSourceOption describes an Option that can be passed to
+// `jwt.NewReuseTokenSource()` or `jwt.NewSigningTokenSource()`.
+type TokenSourceOption interface {
+	Option
+	tokenSourceOption()
+}
+
+type tokenSourceOption struct {
+	Option
+}
+
+func (*tokenSourceOption) tokenSourceOption() {}
+
 // ValidateOption describes an Option that can be passed to Validate().
 // ValidateOption also implements ParseOption, therefore it may be
 // safely passed to `Parse()` (and thus `jwt.ReadFile()`)
@@ -123,37 +156,74 @@ func (*validateOption) readFileOption() {}
 func (*validateOption) validateOption() {}
 
 type identAcceptableSkew struct{}
+type identAggregateErrors struct{}
 type identClock struct{}
+type identClockAnomalyCallback struct{}
 type identContext struct{}
+type identCookieKey struct{}
+type identDegradedCallback struct{}
 type identEncryptOption struct{}
 type identFS struct{}
 type identFlattenAudience struct{}
 type identFormKey struct{}
+type identGracePeriod struct{}
 type identHeaderKey struct{}
+type identJwsHeaders struct{}
 type identKeyProvider struct{}
+type identMaxNestingDepth struct{}
+type identMaxPayloadSize struct{}
+type identMaxTokenSize struct{}
 type identNumericDateFormatPrecision struct{}
 type identNumericDateParsePedantic struct{}
 type identNumericDateParsePrecision struct{}
 type identPedantic struct{}
+type identRefreshBefore struct{}
+type identReplayDetection struct{}
+type identRequireExpiration struct{}
+type identRequireIssuedAt struct{}
 type identSignOption struct{}
+type identStatsCallback struct{}
+type identStrictJSON struct{}
 type identToken struct{}
+type identTokenLifetime struct{}
+type identTokenSourceClock struct{}
+type identTransformer struct{}
 type identTruncation struct{}
+type identUnredactedClaims struct{}
 type identValidate struct{}
+type identValidationPolicy struct{}
 type identValidator struct{}
+type identVerificationCache struct{}
 type identVerify struct{}
 
 func (identAcceptableSkew) String() string {
 	return "WithAcceptableSkew"
 }
 
+func (identAggregateErrors) String() string {
+	return "WithAggregateErrors"
+}
+
 func (identClock) String() string {
 	return "WithClock"
 }
 
+func (identClockAnomalyCallback) String() string {
+	return "WithClockAnomalyCallback"
+}
+
 func (identContext) String() string {
 	return "WithContext"
 }
 
+func (identCookieKey) String() string {
+	return "WithCookieKey"
+}
+
+func (identDegradedCallback) String() string {
+	return "WithDegradedCallback"
+}
+
 func (identEncryptOption) String() string {
 	return "WithEncryptOption"
 }
@@ -170,14 +240,34 @@ func (identFormKey) String() string {
 	return "WithFormKey"
 }
 
+func (identGracePeriod) String() string {
+	return "WithGracePeriod"
+}
+
 func (identHeaderKey) String() string {
 	return "WithHeaderKey"
 }
 
+func (identJwsHeaders) String() string {
+	return "WithJwsHeaders"
+}
+
 func (identKeyProvider) String() string {
 	return "WithKeyProvider"
 }
 
+func (identMaxNestingDepth) String() string {
+	return "WithMaxNestingDepth"
+}
+
+func (identMaxPayloadSize) String() string {
+	return "WithMaxPayloadSize"
+}
+
+func (identMaxTokenSize) String() string {
+	return "WithMaxTokenSize"
+}
+
 func (identNumericDateFormatPrecision) String() string {
 	return "WithNumericDateFormatPrecision"
 }
@@ -194,45 +284,134 @@ func (identPedantic) String() string {
 	return "WithPedantic"
 }
 
+func (identRefreshBefore) String() string {
+	return "WithRefreshBefore"
+}
+
+func (identReplayDetection) String() string {
+	return "WithReplayDetection"
+}
+
+func (identRequireExpiration) String() string {
+	return "WithRequireExpiration"
+}
+
+func (identRequireIssuedAt) String() string {
+	return "WithRequireIssuedAt"
+}
+
 func (identSignOption) String() string {
 	return "WithSignOption"
 }
 
+func (identStatsCallback) String() string {
+	return "WithStatsCallback"
+}
+
+func (identStrictJSON) String() string {
+	return "WithStrictJSON"
+}
+
 func (identToken) String() string {
 	return "WithToken"
 }
 
+func (identTokenLifetime) String() string {
+	return "WithTokenLifetime"
+}
+
+func (identTokenSourceClock) String() string {
+	return "WithTokenSourceClock"
+}
+
+func (identTransformer) String() string {
+	return "WithTransformer"
+}
+
 func (identTruncation) String() string {
 	return "WithTruncation"
 }
 
+func (identUnredactedClaims) String() string {
+	return "WithUnredactedClaims"
+}
+
 func (identValidate) String() string {
 	return "WithValidate"
 }
 
+func (identValidationPolicy) String() string {
+	return "WithValidationPolicy"
+}
+
 func (identValidator) String() string {
 	return "WithValidator"
 }
 
+func (identVerificationCache) String() string {
+	return "WithVerificationCache"
+}
+
 func (identVerify) String() string {
 	return "WithVerify"
 }
 
-// WithAcceptableSkew specifies the duration in which exp and nbf
-// claims may differ by. This value should be positive
+// WithAcceptableSkew specifies the duration in which exp, nbf, and iat
+// claims may differ by, to allow for clock differences between
+// the issuer and the validator. This value must not be negative:
+// `jwt.Validate()` returns an error if it is.
 func WithAcceptableSkew(v time.Duration) ValidateOption {
 	return &validateOption{option.New(identAcceptableSkew{}, v)}
 }
 
+// WithAggregateErrors specifies whether `jwt.Validate()` should run all of
+// the checks and report every failure, instead of stopping at the first one.
+// This is useful for diagnostics, for example when logging why a token
+// issued by a third party was rejected.
+//
+// When more than one check fails, the returned error is of type
+// `jwt.ValidationErrors`, which contains each of the errors. Comparisons
+// using `errors.Is()` and `errors.As()` succeed if they succeed for any
+// of the contained errors. When only one check fails, that error is
+// returned as is.
+func WithAggregateErrors(v bool) ValidateOption {
+	return &validateOption{option.New(identAggregateErrors{}, v)}
+}
+
 // WithClock specifies the `Clock` to be used when verifying
-// exp and nbf claims.
+// exp, nbf, and iat claims. By default `time.Now()` is used.
+//
+// This option can also be passed to `jwt.Parse()` when validation
+// is enabled, which allows tests to freeze or advance time instead
+// of sleeping to cross expiry boundaries.
 func WithClock(v Clock) ValidateOption {
 	return &validateOption{option.New(identClock{}, v)}
 }
 
+// WithClockAnomalyCallback specifies a function that is called for each
+// inconsistency found in the time claims of a token, such as an `iat`
+// claim in the future, or an `nbf` claim that is later than the `exp`
+// claim (see `jwt.ClockAnomalyKind`).
+//
+// Anomalies are reported even if the token is accepted because they are
+// within the acceptable skew, and before the validation of the token
+// completes, so that misconfigured issuer clocks can be detected before
+// they cause tokens to be rejected:
+//
+//	jwt.WithClockAnomalyCallback(func(a jwt.ClockAnomaly) {
+//	  log.Printf("clock anomaly %s (%s) in token from %q", a.Kind, a.Offset, a.Token.Issuer())
+//	})
+func WithClockAnomalyCallback(v func(ClockAnomaly)) ValidateOption {
+	return &validateOption{option.New(identClockAnomalyCallback{}, v)}
+}
+
 // WithContext allows you to specify a context.Context object to be used
 // with `jwt.Validate()` option.
 //
+// When passed to `jwt.Parse()`, the context is also used as the parent
+// context of the operations reported to the `observe` package, and is
+// passed to the key providers that are used for verification.
+//
 // Please be aware that in the next major release of this library,
 // `jwt.Validate()`'s signature will change to include an explicit
 // `context.Context` object.
@@ -240,6 +419,23 @@ func WithContext(v context.Context) ValidateOption {
 	return &validateOption{option.New(identContext{}, v)}
 }
 
+// WithCookieKey is used to specify cookie keys to search for tokens.
+//
+// While the type system allows this option to be passed to `jwt.Parse()` directly,
+// doing so will have no effect. Only use it for HTTP request parsing functions
+func WithCookieKey(v string) ParseOption {
+	return &parseOption{option.New(identCookieKey{}, v)}
+}
+
+// WithDegradedCallback specifies a function that is called when a token
+// is accepted only because of the grace period specified via
+// `jwt.WithGracePeriod()`. The function is called after all other checks
+// have passed, and may be used to log or count such tokens, or to flag
+// the request as degraded.
+func WithDegradedCallback(v func(DegradedEvent)) ValidateOption {
+	return &validateOption{option.New(identDegradedCallback{}, v)}
+}
+
 // WithEncryptOption provides an escape hatch for cases where extra options to
 // `(jws.Serializer).Encrypt()` must be specified when usng `jwt.Sign()`. Normally you do not
 // need to use this.
@@ -252,9 +448,12 @@ func WithFS(v fs.FS) ReadFileOption {
 	return &readFileOption{option.New(identFS{}, v)}
 }
 
-// WithFlattenAudience specifies the the `jwt.FlattenAudience` option on
-// every token defaults to enabled. You can still disable this on a per-object
-// basis using the `jwt.Options().Disable(jwt.FlattenAudience)` method call.
+// WithFlattenAudience specifies if the `jwt.FlattenAudience` option on
+// every token defaults to enabled. When enabled, the `aud` claim is
+// serialized as a single string instead of an array when it contains
+// exactly one value. You can still change this on a per-object
+// basis using the `(jwt.Token).Options().Enable(jwt.FlattenAudience)` and
+// `(jwt.Token).Options().Disable(jwt.FlattenAudience)` method calls.
 //
 // See the documentation for `jwt.TokenOptionSet`, `(jwt.Token).Options`, and
 // `jwt.FlattenAudience` for more details
@@ -262,7 +461,7 @@ func WithFlattenAudience(v bool) GlobalOption {
 	return &globalOption{option.New(identFlattenAudience{}, v)}
 }
 
-// WithFormKey is used to specify header keys to search for tokens.
+// WithFormKey is used to specify form keys to search for tokens.
 //
 // While the type system allows this option to be passed to jwt.Parse() directly,
 // doing so will have no effect. Only use it for HTTP request parsing functions
@@ -270,6 +469,23 @@ func WithFormKey(v string) ParseOption {
 	return &parseOption{option.New(identFormKey{}, v)}
 }
 
+// WithGracePeriod specifies a grace period during which tokens that have
+// expired are still accepted, for use in degraded operation such as
+// during an outage of the issuer, when clients are unable to obtain new
+// tokens. The grace period is applied in addition to the acceptable skew
+// (see `jwt.WithAcceptableSkew()`), and must not be negative.
+//
+// Tokens that are accepted only because of the grace period are flagged
+// as degraded: they are reported to the function specified via
+// `jwt.WithDegradedCallback()`, and `jwt.WithStatsCallback()` reports them
+// with `Degraded` set to true. Since this weakens the `exp` check, it should
+// only be enabled temporarily, for example behind a feature flag.
+//
+//	tok, err := jwt.Parse(src, jwt.WithKey(alg, key), jwt.WithGracePeriod(10*time.Minute))
+func WithGracePeriod(v time.Duration) ValidateOption {
+	return &validateOption{option.New(identGracePeriod{}, v)}
+}
+
 // WithHeaderKey is used to specify header keys to search for tokens.
 //
 // While the type system allows this option to be passed to `jwt.Parse()` directly,
@@ -278,6 +494,24 @@ func WithHeaderKey(v string) ParseOption {
 	return &parseOption{option.New(identHeaderKey{}, v)}
 }
 
+// WithJwsHeaders specifies the protected headers of the JWS message that
+// encloses the token, such as `kid`, `typ` (e.g. `at+jwt` or `secevent+jwt`),
+// `x5t`, and private headers. The headers are used for each of the keys
+// specified via `jwt.WithKey()`, and `typ` is only set to `JWT` if it is
+// not present in the given headers.
+//
+//	hdrs := jws.NewHeaders()
+//	hdrs.Set(jws.TypeKey, `at+jwt`)
+//	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, key), jwt.WithJwsHeaders(hdrs))
+//
+// If `jws.WithProtectedHeaders()` is passed to `jwt.WithKey()` as well, the
+// headers specified in `jwt.WithKey()` are used for that key instead.
+//
+// The given headers are copied, and are not modified by `jwt.Sign()`.
+func WithJwsHeaders(v jws.Headers) SignOption {
+	return &signOption{option.New(identJwsHeaders{}, v)}
+}
+
 // WithKeyProvider allows users to specify an object to provide keys to
 // sign/verify tokens using arbitrary code. Please read the documentation
 // for `jws.KeyProvider` in the `jws` package for details on how this works.
@@ -285,6 +519,38 @@ func WithKeyProvider(v jws.KeyProvider) ParseOption {
 	return &parseOption{option.New(identKeyProvider{}, v)}
 }
 
+// WithMaxNestingDepth specifies the maximum number of JWS and JWE messages
+// that the token may be enveloped in. For example, a signed token has
+// a depth of 1, and a token that is signed and then encrypted has a depth
+// of 2. Tokens that are nested more deeply are rejected.
+//
+// The default is 2. The value must be at least 1.
+func WithMaxNestingDepth(v int) ParseOption {
+	return &parseOption{option.New(identMaxNestingDepth{}, v)}
+}
+
+// WithMaxPayloadSize specifies the maximum size in bytes of the payload of
+// each JWS or JWE message that the token is enveloped in, after it has been
+// decoded, decrypted, and decompressed. This also limits the size of the
+// JSON object that contains the claims.
+//
+// The default is 1MiB. Specifying a value that is zero or negative
+// removes the limit.
+func WithMaxPayloadSize(v int) ParseOption {
+	return &parseOption{option.New(identMaxPayloadSize{}, v)}
+}
+
+// WithMaxTokenSize specifies the maximum length in bytes of the serialized
+// token that `jwt.Parse()` and friends accept. Larger tokens are rejected
+// before any decoding takes place. When reading from an `io.Reader` using
+// `jwt.ParseReader()`, no more than this many bytes are read.
+//
+// The default is 1MiB. Specifying a value that is zero or negative
+// removes the limit.
+func WithMaxTokenSize(v int) ParseOption {
+	return &parseOption{option.New(identMaxTokenSize{}, v)}
+}
+
 // WithNumericDateFormatPrecision sets the precision up to which the
 // library uses to format fractional dates found in the numeric date
 // fields. Default is 0 (second, no fractionals), max is 9 (nanosecond)
@@ -318,6 +584,57 @@ func WithPedantic(v bool) ParseOption {
 	return &parseOption{option.New(identPedantic{}, v)}
 }
 
+// WithRefreshBefore specifies how long before the expiration of a token
+// a `jwt.TokenSource` created by `jwt.NewReuseTokenSource()` or
+// `jwt.NewSigningTokenSource()` obtains a new one. By default
+// `jwt.DefaultRefreshBefore` is used.
+func WithRefreshBefore(v time.Duration) TokenSourceOption {
+	return &tokenSourceOption{option.New(identRefreshBefore{}, v)}
+}
+
+// WithReplayDetection specifies that each token may only be accepted
+// once, which is required for one-time-use tokens such as DPoP proofs,
+// logout tokens, or magic links. Tokens are identified by their `jti`
+// claim, which becomes required.
+//
+// The `jti` is recorded in the given `jwt.ReplayStore` after all other
+// checks have passed, and a token whose `jti` has already been recorded
+// is rejected with an error that matches `jwt.ErrTokenReplayed()`.
+//
+//	store := jwt.NewMemoryReplayStore(time.Hour)
+//	tok, err := jwt.Parse(src, jwt.WithKey(alg, key), jwt.WithReplayDetection(store))
+func WithReplayDetection(v ReplayStore) ValidateOption {
+	return &validateOption{option.New(identReplayDetection{}, v)}
+}
+
+// WithRequireExpiration specifies whether tokens must contain the `exp`
+// claim. RFC 8725 Section 3.10 recommends that tokens expire, so it is
+// advisable to enable this in a `jwt.ValidationPolicy` that is shared by
+// all of the validations in a program:
+//
+//	policy, err := jwt.NewValidationPolicy(
+//	  jwt.WithRequireExpiration(true),
+//	  jwt.WithRequireIssuedAt(true),
+//	  ...
+//	)
+//
+// Tokens without the `exp` claim are then rejected with an error that
+// matches `jwt.ErrRequiredClaim()`. As with other options, the last
+// value wins, so tokens that are not meant to expire (e.g. tokens used
+// between internal services) can be accepted by appending
+// `jwt.WithRequireExpiration(false)` to the shared options. The default
+// is false.
+func WithRequireExpiration(v bool) ValidateOption {
+	return &validateOption{option.New(identRequireExpiration{}, v)}
+}
+
+// WithRequireIssuedAt specifies whether tokens must contain the `iat`
+// claim, in the same manner as `jwt.WithRequireExpiration()`. The default
+// is false.
+func WithRequireIssuedAt(v bool) ValidateOption {
+	return &validateOption{option.New(identRequireIssuedAt{}, v)}
+}
+
 // WithSignOption provides an escape hatch for cases where extra options to
 // `jws.Sign()` must be specified when usng `jwt.Sign()`. Normally you do not
 // need to use this.
@@ -325,12 +642,68 @@ func WithSignOption(v jws.SignOption) SignOption {
 	return &signOption{option.New(identSignOption{}, v)}
 }
 
+// WithStatsCallback specifies a function that is called with the outcome
+// of each call to `jwt.Parse()`, whether it succeeds or fails. This can
+// be used to collect metrics such as the rate of verification failures
+// per issuer, without depending on a particular metrics library:
+//
+//	jwt.WithStatsCallback(func(ev jwt.StatsEvent) {
+//			outcome := `success`
+//			if !ev.Success {
+//					outcome = string(ev.Reason)
+//			}
+//			counter.WithLabelValues(ev.Issuer, outcome).Inc()
+//	})
+//
+// The function is called synchronously, and therefore should return
+// quickly. See `jwt.StatsEvent` for the details that are reported.
+func WithStatsCallback(v func(StatsEvent)) ParseOption {
+	return &parseOption{option.New(identStatsCallback{}, v)}
+}
+
+// WithStrictJSON specifies that JWTs containing duplicate JSON member names
+// must be rejected. This applies to the claims, as well as to the headers
+// of the JWS and JWE messages that the token is enveloped in.
+//
+// Parsers disagree on which of the duplicate members takes effect, which
+// allows an attacker to make different components interpret the same token
+// differently. This option is disabled by default, as it slows down parsing.
+//
+// When passed to `jwt.Settings()`, the default for all calls to `jwt.Parse()`
+// and friends is changed. Passing it to `jwt.Parse()` overrides the default.
+func WithStrictJSON(v bool) GlobalParseOption {
+	return &globalParseOption{option.New(identStrictJSON{}, v)}
+}
+
 // WithToken specifies the token instance where the result JWT is stored
 // when parsing JWT tokensthat is used when parsing
 func WithToken(v Token) ParseOption {
 	return &parseOption{option.New(identToken{}, v)}
 }
 
+// WithTokenLifetime specifies the lifetime of the tokens created by
+// `jwt.NewSigningTokenSource()`, which is used to compute their `exp`
+// claim. By default `jwt.DefaultTokenLifetime` is used.
+func WithTokenLifetime(v time.Duration) TokenSourceOption {
+	return &tokenSourceOption{option.New(identTokenLifetime{}, v)}
+}
+
+// WithTokenSourceClock specifies the clock used by `jwt.NewReuseTokenSource()`
+// to determine whether a token is about to expire, and by
+// `jwt.NewSigningTokenSource()` to compute the `iat` and `exp` claims.
+// By default `time.Now()` is used.
+func WithTokenSourceClock(v Clock) TokenSourceOption {
+	return &tokenSourceOption{option.New(identTokenSourceClock{}, v)}
+}
+
+// WithTransformer specifies a `jwt.Transformer` that is applied to a copy
+// of the token just before it is signed by `jwt.Sign()` or
+// `(jwt.Serializer).Sign()`. It may be specified multiple times, and the
+// transformers are applied in order. See `(jwt.Serializer).Transform()`.
+func WithTransformer(v Transformer) SignOption {
+	return &signOption{option.New(identTransformer{}, v)}
+}
+
 // WithTruncation speficies the amount that should be used when
 // truncating time values used during time-based validation routines.
 // By default time values are truncated down to second accuracy.
@@ -340,6 +713,18 @@ func WithTruncation(v time.Duration) ValidateOption {
 	return &validateOption{option.New(identTruncation{}, v)}
 }
 
+// WithUnredactedClaims specifies the claims whose values are shown when
+// tokens are formatted via `String()` (e.g. using `fmt.Printf("%v", token)`)
+// or logged via `log/slog`. The values of all other claims are replaced
+// with "[REDACTED]". By default, only the registered claims `iss`, `sub`,
+// `aud`, `exp`, `nbf`, `iat`, and `jti` are shown.
+//
+// The names replace the current settings. Specifying no names redacts
+// the values of all claims.
+func WithUnredactedClaims(v ...string) GlobalOption {
+	return &globalOption{option.New(identUnredactedClaims{}, v)}
+}
+
 // WithValidate is passed to `Parse()` method to denote that the
 // validation of the JWT token should be performed (or not) after
 // a successful parsing of the incoming payload.
@@ -352,21 +737,51 @@ func WithValidate(v bool) ParseOption {
 	return &parseOption{option.New(identValidate{}, v)}
 }
 
+// WithValidationPolicy specifies the `jwt.ValidationPolicy` to validate
+// the token with, instead of creating one from the validation options
+// passed to `jwt.Parse()` on each call. It may not be combined with
+// validation options such as `jwt.WithAudience()`, with the exception
+// of `jwt.WithContext()`, which is used as the parent of the context
+// that the policy validates the token with.
+//
+// The policy is ignored if `jwt.WithValidate(false)` is specified.
+func WithValidationPolicy(v *ValidationPolicy) ParseOption {
+	return &parseOption{option.New(identValidationPolicy{}, v)}
+}
+
 // WithValidator validates the token with the given Validator.
+// Validators are run after the standard checks (`exp`, `nbf`, `iat`),
+// in the order that they were specified, and validation stops at
+// the first error.
 //
 // For example, in order to validate tokens that are only valid during August, you would write
 //
-//	validator := jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) error {
+//	validator := jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
 //		if time.Now().Month() != 8 {
-//			return fmt.Errorf(`tokens are only valid during August!`)
+//			return jwt.NewValidationError(fmt.Errorf(`tokens are only valid during August!`))
 //		}
 //		return nil
 //	})
 //	err := jwt.Validate(token, jwt.WithValidator(validator))
+//
+// When the token is verified and validated by `jwt.Parse()`, the protected
+// headers of the JWS message are available to validators via
+// `jwt.ValidationCtxHeaders()`.
 func WithValidator(v Validator) ValidateOption {
 	return &validateOption{option.New(identValidator{}, v)}
 }
 
+// WithVerificationCache specifies a `jwt.VerificationCache` to look up
+// tokens that have already been verified, in which case the signature
+// verification (and decryption) is skipped. Tokens that are successfully
+// parsed are added to the cache. Validation is performed regardless.
+//
+// Read the documentation for `jwt.VerificationCache` for the conditions
+// under which a cache may be shared between calls.
+func WithVerificationCache(v *VerificationCache) ParseOption {
+	return &parseOption{option.New(identVerificationCache{}, v)}
+}
+
 // WithVerify is passed to `Parse()` method to denote that the
 // signature verification should be performed after a successful
 // deserialization of the incoming payload.
@@ -10,22 +10,43 @@ import (
 
 func TestOptionIdent(t *testing.T) {
 	require.Equal(t, "WithAcceptableSkew", identAcceptableSkew{}.String())
+	require.Equal(t, "WithAggregateErrors", identAggregateErrors{}.String())
 	require.Equal(t, "WithClock", identClock{}.String())
+	require.Equal(t, "WithClockAnomalyCallback", identClockAnomalyCallback{}.String())
 	require.Equal(t, "WithContext", identContext{}.String())
+	require.Equal(t, "WithCookieKey", identCookieKey{}.String())
+	require.Equal(t, "WithDegradedCallback", identDegradedCallback{}.String())
 	require.Equal(t, "WithEncryptOption", identEncryptOption{}.String())
 	require.Equal(t, "WithFS", identFS{}.String())
 	require.Equal(t, "WithFlattenAudience", identFlattenAudience{}.String())
 	require.Equal(t, "WithFormKey", identFormKey{}.String())
+	require.Equal(t, "WithGracePeriod", identGracePeriod{}.String())
 	require.Equal(t, "WithHeaderKey", identHeaderKey{}.String())
+	require.Equal(t, "WithJwsHeaders", identJwsHeaders{}.String())
 	require.Equal(t, "WithKeyProvider", identKeyProvider{}.String())
+	require.Equal(t, "WithMaxNestingDepth", identMaxNestingDepth{}.String())
+	require.Equal(t, "WithMaxPayloadSize", identMaxPayloadSize{}.String())
+	require.Equal(t, "WithMaxTokenSize", identMaxTokenSize{}.String())
 	require.Equal(t, "WithNumericDateFormatPrecision", identNumericDateFormatPrecision{}.String())
 	require.Equal(t, "WithNumericDateParsePedantic", identNumericDateParsePedantic{}.String())
 	require.Equal(t, "WithNumericDateParsePrecision", identNumericDateParsePrecision{}.String())
 	require.Equal(t, "WithPedantic", identPedantic{}.String())
+	require.Equal(t, "WithRefreshBefore", identRefreshBefore{}.String())
+	require.Equal(t, "WithReplayDetection", identReplayDetection{}.String())
+	require.Equal(t, "WithRequireExpiration", identRequireExpiration{}.String())
+	require.Equal(t, "WithRequireIssuedAt", identRequireIssuedAt{}.String())
 	require.Equal(t, "WithSignOption", identSignOption{}.String())
+	require.Equal(t, "WithStatsCallback", identStatsCallback{}.String())
+	require.Equal(t, "WithStrictJSON", identStrictJSON{}.String())
 	require.Equal(t, "WithToken", identToken{}.String())
+	require.Equal(t, "WithTokenLifetime", identTokenLifetime{}.String())
+	require.Equal(t, "WithTokenSourceClock", identTokenSourceClock{}.String())
+	require.Equal(t, "WithTransformer", identTransformer{}.String())
 	require.Equal(t, "WithTruncation", identTruncation{}.String())
+	require.Equal(t, "WithUnredactedClaims", identUnredactedClaims{}.String())
 	require.Equal(t, "WithValidate", identValidate{}.String())
+	require.Equal(t, "WithValidationPolicy", identValidationPolicy{}.String())
 	require.Equal(t, "WithValidator", identValidator{}.String())
+	require.Equal(t, "WithVerificationCache", identVerificationCache{}.String())
 	require.Equal(t, "WithVerify", identVerify{}.String())
 }
@@ -0,0 +1,219 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/observe"
+)
+
+// ValidationPolicy is a reusable set of validation rules, created from
+// the same options that are accepted by `jwt.Validate()`. Creating the
+// policy once (e.g. at program start-up) and applying it to each token
+// avoids processing the options on every call:
+//
+//	policy, err := jwt.NewValidationPolicy(
+//	  jwt.WithIssuer(`https://issuer.example.com`),
+//	  jwt.WithAudience(`my-api`),
+//	  jwt.WithAcceptableSkew(30*time.Second),
+//	  jwt.WithRequiredClaim(jwt.ExpirationKey),
+//	)
+//	...
+//	// for each request
+//	tok, err := jwt.Parse(src, jwt.WithKeySet(set), jwt.WithValidationPolicy(policy))
+//
+// A ValidationPolicy cannot be modified after it has been created, and
+// may be used from multiple goroutines concurrently, provided that the
+// `jwt.Validator`, `jwt.Clock`, and `jwt.ReplayStore` objects passed to
+// `jwt.NewValidationPolicy()` are safe for concurrent use as well.
+type ValidationPolicy struct {
+	ctx         context.Context
+	clock       Clock
+	skew        time.Duration
+	trunc       time.Duration
+	replayStore ReplayStore
+	aggregate   bool
+	validators  []Validator
+	grace       time.Duration
+	onDegraded  func(DegradedEvent)
+	onAnomaly   func(ClockAnomaly)
+}
+
+// NewValidationPolicy creates a new ValidationPolicy from the given
+// options. See `jwt.Validate()` for the checks that are performed.
+//
+// An error is returned if the options are invalid, for example if the
+// acceptable skew is negative.
+func NewValidationPolicy(options ...ValidateOption) (*ValidationPolicy, error) {
+	ctx := context.Background()
+	trunc := time.Second
+
+	var clock Clock = ClockFunc(time.Now)
+	var skew time.Duration
+	var replayStore ReplayStore
+	var aggregate bool
+	var grace time.Duration
+	var onDegraded func(DegradedEvent)
+	var onAnomaly func(ClockAnomaly)
+	var requireExp, requireIat bool
+	var validators = []Validator{
+		IsIssuedAtValid(),
+		IsExpirationValid(),
+		IsNbfValid(),
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identClock{}:
+			// a nil Clock is reported below
+			clock, _ = o.Value().(Clock)
+		case identAcceptableSkew{}:
+			skew = o.Value().(time.Duration)
+		case identTruncation{}:
+			trunc = o.Value().(time.Duration)
+		case identContext{}:
+			ctx = o.Value().(context.Context)
+		case identAggregateErrors{}:
+			aggregate = o.Value().(bool)
+		case identReplayDetection{}:
+			replayStore, _ = o.Value().(ReplayStore)
+		case identGracePeriod{}:
+			grace = o.Value().(time.Duration)
+		case identDegradedCallback{}:
+			onDegraded = o.Value().(func(DegradedEvent))
+		case identClockAnomalyCallback{}:
+			onAnomaly = o.Value().(func(ClockAnomaly))
+		case identRequireExpiration{}:
+			requireExp = o.Value().(bool)
+		case identRequireIssuedAt{}:
+			requireIat = o.Value().(bool)
+		case identValidator{}:
+			v := o.Value().(Validator)
+			switch v := v.(type) {
+			case areRequired:
+				for _, name := range v {
+					validators = append(validators, IsRequired(name))
+				}
+				continue
+			case *isInTimeRange:
+				if v.c1 != "" {
+					if err := isSupportedTimeClaim(v.c1); err != nil {
+						return nil, err
+					}
+					validators = append(validators, IsRequired(v.c1))
+				}
+				if v.c2 != "" {
+					if err := isSupportedTimeClaim(v.c2); err != nil {
+						return nil, err
+					}
+					validators = append(validators, IsRequired(v.c2))
+				}
+			}
+			validators = append(validators, v)
+		}
+	}
+
+	if requireExp {
+		validators = append(validators, IsRequired(ExpirationKey))
+	}
+	if requireIat {
+		validators = append(validators, IsRequired(IssuedAtKey))
+	}
+
+	if clock == nil {
+		return nil, NewValidationError(fmt.Errorf(`clock must not be nil`))
+	}
+	if skew < 0 {
+		return nil, NewValidationError(fmt.Errorf(`acceptable skew must not be negative (got %s)`, skew))
+	}
+	if grace < 0 {
+		return nil, NewValidationError(fmt.Errorf(`grace period must not be negative (got %s)`, grace))
+	}
+
+	p := &ValidationPolicy{
+		clock:       clock,
+		skew:        skew,
+		trunc:       trunc,
+		replayStore: replayStore,
+		aggregate:   aggregate,
+		validators:  validators,
+		grace:       grace,
+		onDegraded:  onDegraded,
+		onAnomaly:   onAnomaly,
+	}
+	p.ctx = p.contextFrom(ctx)
+	return p, nil
+}
+
+// contextFrom returns a context derived from `parent` that carries the
+// clock, skew, and truncation settings of the policy. It is used when
+// a context is specified per call, e.g. via `jwt.WithContext()` in
+// `jwt.Parse()`
+func (p *ValidationPolicy) contextFrom(parent context.Context) context.Context {
+	ctx := SetValidationCtxSkew(parent, p.skew)
+	ctx = SetValidationCtxClock(ctx, p.clock)
+	return SetValidationCtxTruncation(ctx, p.trunc)
+}
+
+// Validate validates the token `t` against the policy.
+func (p *ValidationPolicy) Validate(t Token) error {
+	_, err := p.validate(p.ctx, p.ctx, t)
+	return err
+}
+
+// validate validates the token using `ctx`, which must be derived
+// from `p.ctx`. `parent` is the parent context of the observed operation.
+// `degraded` is true if the token was accepted only because of the
+// grace period
+func (p *ValidationPolicy) validate(parent, ctx context.Context, t Token) (degraded bool, err error) {
+	_, span := observe.Start(parent, observe.JWTValidate)
+	defer func() { span.End(err) }()
+	if iss := t.Issuer(); iss != "" {
+		span.SetAttribute(observe.AttrIssuer, iss)
+	}
+
+	if p.onAnomaly != nil {
+		for _, a := range clockAnomalies(ctx, t) {
+			p.onAnomaly(a)
+		}
+	}
+
+	var expiredFor time.Duration
+	var errs ValidationErrors
+	for _, v := range p.validators {
+		if err := v.Validate(ctx, t); err != nil {
+			if p.grace > 0 && errors.Is(err, errTokenExpired) {
+				if d, ok := expiredWithinGrace(ctx, t, p.grace); ok {
+					expiredFor = d
+					degraded = true
+					continue
+				}
+			}
+			if !p.aggregate {
+				return false, err
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+	case 1:
+		return false, errs[0]
+	default:
+		return false, errs
+	}
+
+	if p.replayStore != nil {
+		if err := checkReplay(ctx, p.replayStore, t, p.grace); err != nil {
+			return false, err
+		}
+	}
+
+	if degraded && p.onDegraded != nil {
+		p.onDegraded(DegradedEvent{Token: t, ExpiredFor: expiredFor})
+	}
+	return degraded, nil
+}
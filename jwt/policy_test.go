@@ -0,0 +1,167 @@
+package jwt_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationPolicy(t *testing.T) {
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+	now := time.Now()
+
+	policy, err := jwt.NewValidationPolicy(
+		jwt.WithIssuer(`https://issuer.example.com`),
+		jwt.WithAudience(`my-api`),
+		jwt.WithAcceptableSkew(time.Minute),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+	)
+	require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+
+	build := func(t *testing.T, aud string, exp time.Time) jwt.Token {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Issuer(`https://issuer.example.com`).
+			Audience([]string{aud}).
+			Expiration(exp).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		return tok
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		require.NoError(t, policy.Validate(build(t, `my-api`, now.Add(time.Hour))), `valid token should pass`)
+		require.NoError(t, policy.Validate(build(t, `my-api`, now.Add(-30*time.Second))), `token within skew should pass`)
+
+		err := policy.Validate(build(t, `my-api`, now.Add(-time.Hour)))
+		require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `expired token should fail`)
+
+		err = policy.Validate(build(t, `other`, now.Add(time.Hour)))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `token for another audience should fail`)
+
+		noexp := build(t, `my-api`, now.Add(time.Hour))
+		require.NoError(t, noexp.Remove(jwt.ExpirationKey), `noexp.Remove should succeed`)
+		err = policy.Validate(noexp)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `token without required claims should fail`)
+	})
+	t.Run("Parse", func(t *testing.T) {
+		signed, err := jwt.Sign(build(t, `my-api`, now.Add(time.Hour)), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(policy))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+
+		signed, err = jwt.Sign(build(t, `other`, now.Add(time.Hour)), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(policy))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `jwt.Parse should fail`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(policy), jwt.WithValidate(false))
+		require.NoError(t, err, `jwt.Parse should succeed without validation`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(policy), jwt.WithAudience(`other`))
+		require.Error(t, err, `jwt.Parse should fail when combined with validation options`)
+	})
+	t.Run("Parse with context", func(t *testing.T) {
+		type ctxKey struct{}
+		var got interface{}
+		var skew time.Duration
+		ctxPolicy, err := jwt.NewValidationPolicy(
+			jwt.WithAcceptableSkew(time.Minute),
+			jwt.WithValidator(jwt.ValidatorFunc(func(ctx context.Context, _ jwt.Token) jwt.ValidationError {
+				got = ctx.Value(ctxKey{})
+				skew = jwt.ValidationCtxSkew(ctx)
+				return nil
+			})),
+		)
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+
+		signed, err := jwt.Sign(build(t, `my-api`, time.Now().Add(time.Hour)), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		ctx := context.WithValue(context.Background(), ctxKey{}, `request`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(ctxPolicy), jwt.WithContext(ctx))
+		require.NoError(t, err, `jwt.Parse should succeed with jwt.WithContext`)
+		require.Equal(t, `request`, got, `validators should receive the context passed to jwt.Parse`)
+		require.Equal(t, time.Minute, skew, `the settings of the policy should be preserved`)
+	})
+	t.Run("Headers", func(t *testing.T) {
+		typPolicy, err := jwt.NewValidationPolicy(jwt.WithValidator(jwt.ValidatorFunc(func(ctx context.Context, _ jwt.Token) jwt.ValidationError {
+			hdrs, ok := jwt.ValidationCtxHeaders(ctx)
+			if !ok || hdrs.Type() != `at+jwt` {
+				return jwt.NewValidationError(errors.New(`expected typ "at+jwt"`))
+			}
+			return nil
+		})))
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, `at+jwt`), `hdrs.Set should succeed`)
+		signed, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.HS256, key, jws.WithProtectedHeaders(hdrs)))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(typPolicy))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+
+		signed, err = jwt.Sign(jwt.New(), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidationPolicy(typPolicy))
+		require.Error(t, err, `jwt.Parse should fail`)
+	})
+	t.Run("RequireExpiration", func(t *testing.T) {
+		shared := []jwt.ValidateOption{jwt.WithRequireExpiration(true), jwt.WithRequireIssuedAt(true)}
+		strict, err := jwt.NewValidationPolicy(shared...)
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+
+		tok, err := jwt.NewBuilder().IssuedAt(now).Expiration(now.Add(time.Hour)).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		require.NoError(t, strict.Validate(tok), `token with exp and iat should pass`)
+
+		noiat, err := jwt.NewBuilder().Expiration(now.Add(time.Hour)).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		err = strict.Validate(noiat)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `token without iat should fail`)
+
+		noexp, err := jwt.NewBuilder().IssuedAt(now).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		err = strict.Validate(noexp)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `token without exp should fail`)
+		require.NoError(t, jwt.Validate(noexp), `exp should not be required by default`)
+
+		optOut, err := jwt.NewValidationPolicy(append(shared, jwt.WithRequireExpiration(false))...)
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+		require.NoError(t, optOut.Validate(noexp), `token without exp should pass after opting out`)
+		require.Error(t, optOut.Validate(noiat), `iat should still be required after opting out of exp`)
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := jwt.NewValidationPolicy(jwt.WithAcceptableSkew(-time.Second))
+		require.Error(t, err, `negative skew should be rejected`)
+		_, err = jwt.NewValidationPolicy(jwt.WithClock(nil))
+		require.Error(t, err, `nil clock should be rejected`)
+	})
+	t.Run("concurrent use", func(t *testing.T) {
+		valid := build(t, `my-api`, now.Add(time.Hour))
+		expired := build(t, `my-api`, now.Add(-time.Hour))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if err := policy.Validate(valid); err != nil {
+						t.Errorf(`valid token should pass: %s`, err)
+					}
+					if err := policy.Validate(expired); err == nil {
+						t.Errorf(`expired token should fail`)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
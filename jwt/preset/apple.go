@@ -0,0 +1,185 @@
+package preset
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+)
+
+// Issuer and JWKS URL of Sign in with Apple
+const (
+	AppleIssuer  = `https://appleid.apple.com`
+	AppleJWKSURL = `https://appleid.apple.com/auth/keys`
+)
+
+// Names of the private claims in Sign in with Apple identity tokens
+const (
+	AppleIsPrivateEmailKey = `is_private_email`
+	AppleNonceSupportedKey = `nonce_supported`
+	AppleRealUserStatusKey = `real_user_status`
+	AppleTransferSubKey    = `transfer_sub`
+)
+
+// AppleRealUserStatus is the value of the `real_user_status` claim, which
+// indicates whether the user appears to be a real person.
+type AppleRealUserStatus int
+
+const (
+	// AppleRealUserStatusUnsupported is used on platforms that do not
+	// support the detection (i.e. other than iOS 14 and later).
+	AppleRealUserStatusUnsupported AppleRealUserStatus = iota
+	// AppleRealUserStatusUnknown is used when the system could not
+	// determine whether the user is a real person.
+	AppleRealUserStatusUnknown
+	// AppleRealUserStatusLikelyReal is used when the user is likely to
+	// be a real person.
+	AppleRealUserStatusLikelyReal
+)
+
+func (s AppleRealUserStatus) String() string {
+	switch s {
+	case AppleRealUserStatusUnsupported:
+		return `Unsupported`
+	case AppleRealUserStatusUnknown:
+		return `Unknown`
+	case AppleRealUserStatusLikelyReal:
+		return `LikelyReal`
+	default:
+		return fmt.Sprintf(`AppleRealUserStatus(%d)`, int(s))
+	}
+}
+
+// AppleClaims contains the claims of Sign in with Apple identity tokens
+// that describe the user, converted to their proper types. Apple encodes
+// some boolean values as the strings "true" and "false".
+type AppleClaims struct {
+	Email          string
+	EmailVerified  bool
+	IsPrivateEmail bool
+	// NonceSupported reports whether the platform supports the `nonce`
+	// claim. If it is false, the `nonce` claim cannot be checked.
+	NonceSupported bool
+	RealUserStatus AppleRealUserStatus
+	// TransferSub is the identifier used to transfer the user to another
+	// team, which is only present during app transfers.
+	TransferSub string
+}
+
+// AppleClaimsOf returns the claims of `t` that describe the user. Claims
+// that are missing are left as their zero values.
+func AppleClaimsOf(t jwt.Token) (*AppleClaims, error) {
+	var claims AppleClaims
+	var err error
+	if claims.Email, err = appleString(t, openid.EmailKey); err != nil {
+		return nil, fmt.Errorf(`preset.AppleClaimsOf: %w`, err)
+	}
+	if claims.TransferSub, err = appleString(t, AppleTransferSubKey); err != nil {
+		return nil, fmt.Errorf(`preset.AppleClaimsOf: %w`, err)
+	}
+	for _, b := range []struct {
+		name string
+		dst  *bool
+	}{
+		{name: openid.EmailVerifiedKey, dst: &claims.EmailVerified},
+		{name: AppleIsPrivateEmailKey, dst: &claims.IsPrivateEmail},
+		{name: AppleNonceSupportedKey, dst: &claims.NonceSupported},
+	} {
+		if *b.dst, err = appleBool(t, b.name); err != nil {
+			return nil, fmt.Errorf(`preset.AppleClaimsOf: %w`, err)
+		}
+	}
+	if v, ok := t.Get(AppleRealUserStatusKey); ok {
+		n, ok := v.(float64)
+		if !ok || n != float64(int(n)) {
+			return nil, fmt.Errorf(`preset.AppleClaimsOf: %q claim must be an integer`, AppleRealUserStatusKey)
+		}
+		claims.RealUserStatus = AppleRealUserStatus(n)
+	}
+	return &claims, nil
+}
+
+func appleString(t jwt.Token, name string) (string, error) {
+	v, ok := t.Get(name)
+	if !ok {
+		return ``, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return ``, fmt.Errorf(`%q claim must be a string`, name)
+	}
+	return s, nil
+}
+
+func appleBool(t jwt.Token, name string) (bool, error) {
+	v, ok := t.Get(name)
+	if !ok {
+		return false, nil
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf(`%q claim must be a boolean: %w`, name, err)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf(`%q claim must be a boolean`, name)
+	}
+}
+
+// Apple creates a Preset for the identity tokens issued by Sign in with
+// Apple to the client `clientID`, which is the bundle ID of the app or
+// the Services ID of the website. The keys are fetched from
+// `preset.AppleJWKSURL` and refreshed using `cache`.
+//
+// Tokens must be issued by `preset.AppleIssuer` for `clientID`, and must
+// have the `sub`, `exp`, and `iat` claims. The claims that describe the
+// user can be obtained using `preset.AppleClaimsOf()`.
+//
+// The `nonce` claim differs for each authentication request, so it is
+// checked by passing `jwt.WithValidator(openid.IsNonceValid(nonce))` to
+// `(preset.Preset).Parse()`, where `nonce` is the value sent to Apple
+// (apps usually send the SHA-256 hash of a random value).
+//
+// Unlike other OpenID Connect presets, the tokens are not parsed into
+// `openid.Token`s, as Apple encodes `email_verified` as a string.
+func Apple(cache *jwk.Cache, clientID string, options ...Option) (*Preset, error) {
+	if clientID == `` {
+		return nil, fmt.Errorf(`preset.Apple: clientID must be specified`)
+	}
+
+	jwksURL := AppleJWKSURL
+	var client jwk.HTTPClient
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			client = o.Value().(jwk.HTTPClient)
+		case identJWKSURL{}:
+			jwksURL = o.Value().(string)
+		default:
+			return nil, fmt.Errorf(`preset.Apple: invalid option %T`, o)
+		}
+	}
+
+	keys, err := newKeySetProvider(cache, jwksURL, client)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Apple: %w`, err)
+	}
+
+	p, err := New(`apple`,
+		jwt.WithKeySetProvider(keys),
+		jwt.WithRequiredClaims(jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.IssuedAtKey),
+		jwt.WithIssuer(AppleIssuer),
+		jwt.WithAudience(clientID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Apple: %w`, err)
+	}
+	return p, nil
+}
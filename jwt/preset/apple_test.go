@@ -0,0 +1,79 @@
+package preset_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/lestrrat-go/jwx/v2/jwt/preset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApple(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iss := newTestIssuer(t)
+	cache := jwk.NewCache(ctx)
+	const clientID = `com.example.app`
+
+	// Claims as they appear in identity tokens issued by Apple
+	const claims = `{
+		"iss": "https://appleid.apple.com",
+		"aud": "com.example.app",
+		"sub": "001234.0a1b2c3d4e5f.0123",
+		"nonce": "4b227777d4dd1fc61c6f884f48641d02",
+		"nonce_supported": true,
+		"email": "x7k2@privaterelay.appleid.com",
+		"email_verified": "true",
+		"is_private_email": "true",
+		"real_user_status": 2
+	}`
+	build := func(t *testing.T, aud string) jwt.Token {
+		t.Helper()
+		tok := jwt.New()
+		require.NoError(t, json.Unmarshal([]byte(claims), tok), `json.Unmarshal should succeed`)
+		require.NoError(t, tok.Set(jwt.AudienceKey, aud), `Set should succeed`)
+		require.NoError(t, tok.Set(jwt.IssuedAtKey, time.Now()), `Set should succeed`)
+		require.NoError(t, tok.Set(jwt.ExpirationKey, time.Now().Add(10*time.Minute)), `Set should succeed`)
+		return tok
+	}
+
+	p, err := preset.Apple(cache, clientID,
+		preset.WithHTTPClient(iss.srv.Client()),
+		preset.WithJWKSURL(iss.srv.URL+`/jwks`),
+	)
+	require.NoError(t, err, `preset.Apple should succeed`)
+
+	signed := iss.sign(t, build(t, clientID))
+	tok, err := p.Parse(signed, jwt.WithValidator(openid.IsNonceValid(`4b227777d4dd1fc61c6f884f48641d02`)))
+	require.NoError(t, err, `p.Parse should succeed`)
+
+	apple, err := preset.AppleClaimsOf(tok)
+	require.NoError(t, err, `preset.AppleClaimsOf should succeed`)
+	require.Equal(t, &preset.AppleClaims{
+		Email:          `x7k2@privaterelay.appleid.com`,
+		EmailVerified:  true,
+		IsPrivateEmail: true,
+		NonceSupported: true,
+		RealUserStatus: preset.AppleRealUserStatusLikelyReal,
+	}, apple)
+	require.Equal(t, `LikelyReal`, apple.RealUserStatus.String())
+
+	_, err = p.Parse(signed, jwt.WithValidator(openid.IsNonceValid(`other`)))
+	require.True(t, errors.Is(err, openid.ErrInvalidNonce()), `nonce should be checked`)
+	_, err = p.Parse(iss.sign(t, build(t, `com.example.other`)))
+	require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `tokens for other clients should be rejected`)
+
+	invalid := build(t, clientID)
+	require.NoError(t, invalid.Set(preset.AppleIsPrivateEmailKey, `maybe`), `Set should succeed`)
+	_, err = preset.AppleClaimsOf(invalid)
+	require.Error(t, err, `invalid boolean values should be rejected`)
+}
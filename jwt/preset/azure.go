@@ -0,0 +1,110 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Issuer template and JWKS URL of the Microsoft identity platform
+// (Azure AD) v2.0 endpoints
+const (
+	// AzureADTenantPlaceholder is replaced by the tenant ID in
+	// `preset.AzureADIssuerTemplate`, as in the OpenID Provider
+	// Configuration of the `common` endpoint.
+	AzureADTenantPlaceholder = `{tenantid}`
+	AzureADIssuerTemplate    = `https://login.microsoftonline.com/` + AzureADTenantPlaceholder + `/v2.0`
+	AzureADJWKSURL           = `https://login.microsoftonline.com/common/discovery/v2.0/keys`
+)
+
+// AzureADTenantIDKey is the name of the claim that contains the ID of the
+// tenant that the user signed in to.
+const AzureADTenantIDKey = `tid`
+
+// AzureAD creates a Preset for the tokens issued by the Microsoft identity
+// platform v2.0 endpoints to the application `clientID`, for applications
+// that accept users of multiple tenants.
+//
+// The issuer of such tokens differs for each tenant, so instead of
+// disabling the issuer validation, the tokens must have a `tid` claim, and
+// the `iss` claim must be `preset.AzureADIssuerTemplate` with the tenant
+// ID replaced by the value of the `tid` claim. The tenants can be further
+// restricted using `preset.WithTenant()`. Tokens must also be issued for
+// `clientID`, and must have the `sub`, `exp`, and `iat` claims.
+//
+// The keys of all tenants are published at `preset.AzureADJWKSURL`, which
+// is registered to `cache` only once, so presets that are created with the
+// same `cache` (e.g. with different tenant allowlists) share the keys.
+func AzureAD(cache *jwk.Cache, clientID string, options ...Option) (*Preset, error) {
+	if clientID == `` {
+		return nil, fmt.Errorf(`preset.AzureAD: clientID must be specified`)
+	}
+
+	jwksURL := AzureADJWKSURL
+	var client jwk.HTTPClient
+	v := &azureADIssuerValidator{template: AzureADIssuerTemplate}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			client = o.Value().(jwk.HTTPClient)
+		case identJWKSURL{}:
+			jwksURL = o.Value().(string)
+		case identIssuerTemplate{}:
+			v.template = o.Value().(string)
+		case identTenant{}:
+			for _, tid := range o.Value().([]string) {
+				if v.tenants == nil {
+					v.tenants = make(map[string]struct{})
+				}
+				v.tenants[tid] = struct{}{}
+			}
+		default:
+			return nil, fmt.Errorf(`preset.AzureAD: invalid option %T`, o)
+		}
+	}
+	if !strings.Contains(v.template, AzureADTenantPlaceholder) {
+		return nil, fmt.Errorf(`preset.AzureAD: issuer template must contain %q`, AzureADTenantPlaceholder)
+	}
+
+	keys, err := newKeySetProvider(cache, jwksURL, client)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.AzureAD: %w`, err)
+	}
+
+	p, err := New(`azuread`,
+		jwt.WithKeySetProvider(keys),
+		jwt.WithRequiredClaims(jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.IssuedAtKey, AzureADTenantIDKey),
+		jwt.WithAudience(clientID),
+		jwt.WithValidator(v),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.AzureAD: %w`, err)
+	}
+	return p, nil
+}
+
+type azureADIssuerValidator struct {
+	template string
+	tenants  map[string]struct{}
+}
+
+func (v *azureADIssuerValidator) Validate(_ context.Context, t jwt.Token) jwt.ValidationError {
+	raw, _ := t.Get(AzureADTenantIDKey)
+	tid, ok := raw.(string)
+	if !ok || tid == `` {
+		return jwt.NewValidationError(fmt.Errorf(`%q claim must be a non-empty string`, AzureADTenantIDKey))
+	}
+	if t.Issuer() != strings.Replace(v.template, AzureADTenantPlaceholder, tid, 1) {
+		return jwt.ErrInvalidIssuer()
+	}
+	if v.tenants != nil {
+		if _, ok := v.tenants[tid]; !ok {
+			return jwt.NewValidationError(fmt.Errorf(`tenant %q is not allowed`, tid))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package preset_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/preset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureAD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iss := newTestIssuer(t)
+	cache := jwk.NewCache(ctx)
+	const clientID = `6731de76-14a6-49ae-97bc-6eba6914391e`
+	const contoso = `72f988bf-86f1-41af-91ab-2d7cd011db47`
+	const fabrikam = `9188040d-6c67-4c5b-b112-36a304b66dad`
+
+	issuerOf := func(tid string) string {
+		return strings.Replace(preset.AzureADIssuerTemplate, preset.AzureADTenantPlaceholder, tid, 1)
+	}
+	build := func(t *testing.T, issuer, tid string) []byte {
+		t.Helper()
+		b := jwt.NewBuilder().
+			Issuer(issuer).
+			Audience([]string{clientID}).
+			Subject(`AAAAAAAAAAAAAAAAAAAAAIkzqFVrSaSaFHy782bbtaQ`).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Hour))
+		if tid != `` {
+			b.Claim(preset.AzureADTenantIDKey, tid)
+		}
+		tok, err := b.Build()
+		require.NoError(t, err, `Build should succeed`)
+		return iss.sign(t, tok)
+	}
+	newPreset := func(t *testing.T, options ...preset.Option) *preset.Preset {
+		t.Helper()
+		options = append(options, preset.WithHTTPClient(iss.srv.Client()), preset.WithJWKSURL(iss.srv.URL+`/jwks`))
+		p, err := preset.AzureAD(cache, clientID, options...)
+		require.NoError(t, err, `preset.AzureAD should succeed`)
+		return p
+	}
+
+	t.Run("any tenant", func(t *testing.T) {
+		p := newPreset(t)
+		for _, tid := range []string{contoso, fabrikam} {
+			tok, err := p.Parse(build(t, issuerOf(tid), tid))
+			require.NoError(t, err, `p.Parse should succeed for %s`, tid)
+			require.Equal(t, issuerOf(tid), tok.Issuer())
+		}
+	})
+	t.Run("mismatching tenant", func(t *testing.T) {
+		p := newPreset(t)
+		_, err := p.Parse(build(t, issuerOf(contoso), fabrikam))
+		require.True(t, errors.Is(err, jwt.ErrInvalidIssuer()), `iss should match tid`)
+		_, err = p.Parse(build(t, issuerOf(contoso), ``))
+		require.Error(t, err, `tid should be required`)
+		_, err = p.Parse(build(t, `https://sts.windows.net/`+contoso+`/`, contoso))
+		require.True(t, errors.Is(err, jwt.ErrInvalidIssuer()), `other issuers should be rejected`)
+	})
+	t.Run("allowed tenants", func(t *testing.T) {
+		p := newPreset(t, preset.WithTenant(contoso))
+		_, err := p.Parse(build(t, issuerOf(contoso), contoso))
+		require.NoError(t, err, `allowed tenants should be accepted`)
+		_, err = p.Parse(build(t, issuerOf(fabrikam), fabrikam))
+		require.Error(t, err, `other tenants should be rejected`)
+	})
+	t.Run("issuer template", func(t *testing.T) {
+		const template = `https://login.microsoftonline.us/{tenantid}/v2.0`
+		p := newPreset(t, preset.WithIssuerTemplate(template))
+		_, err := p.Parse(build(t, strings.Replace(template, preset.AzureADTenantPlaceholder, contoso, 1), contoso))
+		require.NoError(t, err, `tokens of the template should be accepted`)
+		_, err = p.Parse(build(t, issuerOf(contoso), contoso))
+		require.Error(t, err, `tokens of the default template should be rejected`)
+
+		_, err = preset.AzureAD(cache, clientID, preset.WithIssuerTemplate(`https://login.microsoftonline.us/v2.0`))
+		require.Error(t, err, `templates without the placeholder should be rejected`)
+	})
+}
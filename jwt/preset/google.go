@@ -0,0 +1,199 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+)
+
+// Issuers and JWKS URLs of Google Sign-In and Firebase Authentication
+const (
+	// GoogleIssuer is the issuer of Google ID Tokens. Tokens issued with
+	// `GoogleIssuerHost` (i.e. without the scheme) are accepted as well.
+	GoogleIssuer     = `https://accounts.google.com`
+	GoogleIssuerHost = `accounts.google.com`
+	GoogleJWKSURL    = `https://www.googleapis.com/oauth2/v3/certs`
+
+	// FirebaseIssuerPrefix is followed by the project ID in the issuer
+	// of Firebase ID Tokens.
+	FirebaseIssuerPrefix = `https://securetoken.google.com/`
+	FirebaseJWKSURL      = `https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com`
+)
+
+// Names of the private claims in Google and Firebase ID Tokens
+const (
+	HostedDomainKey = `hd`
+	FirebaseKey     = `firebase`
+)
+
+// FirebaseMaxUIDLength is the maximum length of the user ID in the `sub`
+// claim of Firebase ID Tokens.
+const FirebaseMaxUIDLength = 128
+
+// FirebaseClaims is the content of the `firebase` claim of Firebase ID
+// Tokens.
+type FirebaseClaims struct {
+	// SignInProvider is the provider that the user signed in with, such
+	// as `password`, `google.com`, or `anonymous`.
+	SignInProvider string `json:"sign_in_provider"`
+	// Identities maps the providers linked to the user (e.g. `email` or
+	// `google.com`) to the identifiers of the user at those providers.
+	Identities map[string][]string `json:"identities,omitempty"`
+	// Tenant is the ID of the tenant that the user belongs to, when
+	// multi-tenancy is used.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// FirebaseClaimsOf returns the `firebase` claim of `t`.
+func FirebaseClaimsOf(t jwt.Token) (*FirebaseClaims, error) {
+	var claims FirebaseClaims
+	if err := decodeClaim(t, FirebaseKey, &claims); err != nil {
+		return nil, fmt.Errorf(`preset.FirebaseClaimsOf: %w`, err)
+	}
+	return &claims, nil
+}
+
+// Google creates a Preset for the ID Tokens issued by Google Sign-In to
+// the OAuth 2.0 client `clientID`. The keys are fetched from
+// `preset.GoogleJWKSURL` and refreshed using `cache`.
+//
+// Tokens must be issued by `preset.GoogleIssuer` (or
+// `preset.GoogleIssuerHost`) for `clientID`, and must have the `sub`,
+// `exp`, and `iat` claims. The `azp` claim is not checked, as tokens
+// obtained by Android apps on behalf of a web backend carry the client ID
+// of the app in `azp`. Google Workspace domains can be restricted using
+// `preset.WithHostedDomain()`.
+//
+// The tokens returned by `(preset.Preset).Parse()` are `openid.Token`s.
+func Google(cache *jwk.Cache, clientID string, options ...Option) (*Preset, error) {
+	if clientID == `` {
+		return nil, fmt.Errorf(`preset.Google: clientID must be specified`)
+	}
+
+	jwksURL := GoogleJWKSURL
+	var client jwk.HTTPClient
+	var domains map[string]struct{}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			client = o.Value().(jwk.HTTPClient)
+		case identJWKSURL{}:
+			jwksURL = o.Value().(string)
+		case identHostedDomain{}:
+			for _, domain := range o.Value().([]string) {
+				if domains == nil {
+					domains = make(map[string]struct{})
+				}
+				domains[domain] = struct{}{}
+			}
+		default:
+			return nil, fmt.Errorf(`preset.Google: invalid option %T`, o)
+		}
+	}
+
+	keys, err := newKeySetProvider(cache, jwksURL, client)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Google: %w`, err)
+	}
+
+	parseOptions := []jwt.ParseOption{
+		jwt.WithKeySetProvider(keys),
+		jwt.WithRequiredClaims(jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.IssuedAtKey),
+		jwt.WithValidator(jwt.ValidatorFunc(validateGoogleIssuer)),
+		jwt.WithAudience(clientID),
+	}
+	if domains != nil {
+		parseOptions = append(parseOptions, jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
+			hd, _ := t.Get(HostedDomainKey)
+			if s, ok := hd.(string); ok {
+				if _, ok := domains[s]; ok {
+					return nil
+				}
+			}
+			return jwt.NewValidationError(fmt.Errorf(`%q claim does not contain an allowed domain`, HostedDomainKey))
+		})))
+	}
+
+	p, err := New(`google`, parseOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Google: %w`, err)
+	}
+	p.token = newOpenIDToken
+	return p, nil
+}
+
+func validateGoogleIssuer(_ context.Context, t jwt.Token) jwt.ValidationError {
+	switch t.Issuer() {
+	case GoogleIssuer, GoogleIssuerHost:
+		return nil
+	default:
+		return jwt.ErrInvalidIssuer()
+	}
+}
+
+// Firebase creates a Preset for the ID Tokens issued by Firebase
+// Authentication for the project `projectID`. The keys are fetched from
+// `preset.FirebaseJWKSURL` and refreshed using `cache`.
+//
+// As described in the Firebase documentation, tokens must be issued by
+// `preset.FirebaseIssuerPrefix` followed by `projectID`, for `projectID`,
+// must have the `exp` and `iat` claims, an `auth_time` claim in the past,
+// and a `sub` claim (the user ID) of at most 128 characters. The
+// `firebase` claim can be obtained using `preset.FirebaseClaimsOf()`.
+//
+// The tokens returned by `(preset.Preset).Parse()` are `openid.Token`s.
+// Revoked tokens are not detected, as doing so requires querying Firebase.
+func Firebase(cache *jwk.Cache, projectID string, options ...Option) (*Preset, error) {
+	if projectID == `` {
+		return nil, fmt.Errorf(`preset.Firebase: projectID must be specified`)
+	}
+
+	jwksURL := FirebaseJWKSURL
+	var client jwk.HTTPClient
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			client = o.Value().(jwk.HTTPClient)
+		case identJWKSURL{}:
+			jwksURL = o.Value().(string)
+		default:
+			return nil, fmt.Errorf(`preset.Firebase: invalid option %T`, o)
+		}
+	}
+
+	keys, err := newKeySetProvider(cache, jwksURL, client)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Firebase: %w`, err)
+	}
+
+	p, err := New(`firebase`,
+		jwt.WithKeySetProvider(keys),
+		jwt.WithRequiredClaims(jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.IssuedAtKey, openid.AuthTimeKey),
+		jwt.WithIssuer(FirebaseIssuerPrefix+projectID),
+		jwt.WithAudience(projectID),
+		jwt.WithValidator(jwt.ValidatorFunc(validateFirebaseToken)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Firebase: %w`, err)
+	}
+	p.token = newOpenIDToken
+	return p, nil
+}
+
+func validateFirebaseToken(ctx context.Context, t jwt.Token) jwt.ValidationError {
+	if sub := t.Subject(); sub == `` || len(sub) > FirebaseMaxUIDLength {
+		return jwt.NewValidationError(fmt.Errorf(`%q claim must be a non-empty string of at most %d characters`, jwt.SubjectKey, FirebaseMaxUIDLength))
+	}
+	if ot, ok := t.(openid.Token); ok {
+		now := jwt.ValidationCtxClock(ctx).Now()
+		if ot.AuthTime().After(now.Add(jwt.ValidationCtxSkew(ctx))) {
+			return jwt.NewValidationError(fmt.Errorf(`%q claim must be in the past`, openid.AuthTimeKey))
+		}
+	}
+	return nil
+}
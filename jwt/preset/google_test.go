@@ -0,0 +1,148 @@
+package preset_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+	"github.com/lestrrat-go/jwx/v2/jwt/preset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogle(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iss := newTestIssuer(t)
+	cache := jwk.NewCache(ctx)
+	const clientID = `1234567890-abc.apps.googleusercontent.com`
+
+	build := func(t *testing.T, issuer string, claims map[string]interface{}) jwt.Token {
+		t.Helper()
+		b := jwt.NewBuilder().
+			Issuer(issuer).
+			Audience([]string{clientID}).
+			Subject(`110169484474386276334`).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Hour))
+		for name, value := range claims {
+			b.Claim(name, value)
+		}
+		tok, err := b.Build()
+		require.NoError(t, err, `Build should succeed`)
+		return tok
+	}
+
+	p, err := preset.Google(cache, clientID,
+		preset.WithHTTPClient(iss.srv.Client()),
+		preset.WithJWKSURL(iss.srv.URL+`/jwks`),
+	)
+	require.NoError(t, err, `preset.Google should succeed`)
+	require.Equal(t, `google`, p.Name())
+
+	t.Run("issuers", func(t *testing.T) {
+		for _, issuer := range []string{preset.GoogleIssuer, preset.GoogleIssuerHost} {
+			tok, err := p.Parse(iss.sign(t, build(t, issuer, map[string]interface{}{openid.EmailKey: `alice@example.com`})))
+			require.NoError(t, err, `p.Parse should succeed for %s`, issuer)
+			ot, ok := tok.(openid.Token)
+			require.True(t, ok, `tokens should be openid.Tokens`)
+			require.Equal(t, `alice@example.com`, ot.Email())
+		}
+		_, err := p.Parse(iss.sign(t, build(t, `https://accounts.example.com`, nil)))
+		require.True(t, errors.Is(err, jwt.ErrInvalidIssuer()), `other issuers should be rejected`)
+	})
+	t.Run("key rotation", func(t *testing.T) {
+		_, err := p.Parse(iss.sign(t, build(t, preset.GoogleIssuer, nil)))
+		require.NoError(t, err, `p.Parse should succeed`)
+		iss.rotate(t)
+		_, err = p.Parse(iss.sign(t, build(t, preset.GoogleIssuer, nil)))
+		require.NoError(t, err, `p.Parse should succeed after the keys are rotated`)
+	})
+	t.Run("hosted domain", func(t *testing.T) {
+		restricted, err := preset.Google(cache, clientID,
+			preset.WithHTTPClient(iss.srv.Client()),
+			preset.WithJWKSURL(iss.srv.URL+`/jwks`),
+			preset.WithHostedDomain(`example.com`),
+		)
+		require.NoError(t, err, `preset.Google should succeed`)
+		_, err = restricted.Parse(iss.sign(t, build(t, preset.GoogleIssuer, map[string]interface{}{preset.HostedDomainKey: `example.com`})))
+		require.NoError(t, err, `tokens of allowed domains should be accepted`)
+		_, err = restricted.Parse(iss.sign(t, build(t, preset.GoogleIssuer, map[string]interface{}{preset.HostedDomainKey: `example.org`})))
+		require.Error(t, err, `tokens of other domains should be rejected`)
+		_, err = restricted.Parse(iss.sign(t, build(t, preset.GoogleIssuer, nil)))
+		require.Error(t, err, `tokens of consumer accounts should be rejected`)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := preset.Google(cache, ``)
+		require.Error(t, err, `clientID should be required`)
+		_, err = preset.Google(nil, clientID)
+		require.Error(t, err, `jwk.Cache should be required`)
+		_, err = preset.Google(cache, clientID, preset.WithNamespace(`default`))
+		require.Error(t, err, `options of other presets should be rejected`)
+	})
+}
+
+func TestFirebase(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iss := newTestIssuer(t)
+	cache := jwk.NewCache(ctx)
+	const projectID = `my-project`
+
+	build := func(t *testing.T, sub string, authTime time.Time) jwt.Token {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Issuer(preset.FirebaseIssuerPrefix+projectID).
+			Audience([]string{projectID}).
+			Subject(sub).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Hour)).
+			Claim(openid.AuthTimeKey, authTime.Unix()).
+			Claim(preset.FirebaseKey, map[string]interface{}{
+				`sign_in_provider`: `google.com`,
+				`identities`: map[string]interface{}{
+					`google.com`: []string{`110169484474386276334`},
+					`email`:      []string{`alice@example.com`},
+				},
+			}).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		return tok
+	}
+
+	p, err := preset.Firebase(cache, projectID,
+		preset.WithHTTPClient(iss.srv.Client()),
+		preset.WithJWKSURL(iss.srv.URL+`/jwks`),
+	)
+	require.NoError(t, err, `preset.Firebase should succeed`)
+
+	tok, err := p.Parse(iss.sign(t, build(t, `uid-1`, time.Now().Add(-time.Minute))))
+	require.NoError(t, err, `p.Parse should succeed`)
+	claims, err := preset.FirebaseClaimsOf(tok)
+	require.NoError(t, err, `preset.FirebaseClaimsOf should succeed`)
+	require.Equal(t, `google.com`, claims.SignInProvider)
+	require.Equal(t, []string{`alice@example.com`}, claims.Identities[`email`])
+
+	_, err = p.Parse(iss.sign(t, build(t, `uid-1`, time.Now().Add(time.Hour))))
+	require.Error(t, err, `auth_time in the future should be rejected`)
+	_, err = p.Parse(iss.sign(t, build(t, strings.Repeat(`x`, preset.FirebaseMaxUIDLength+1), time.Now())))
+	require.Error(t, err, `sub longer than the maximum should be rejected`)
+
+	other, err := preset.Firebase(cache, `other-project`,
+		preset.WithHTTPClient(iss.srv.Client()),
+		preset.WithJWKSURL(iss.srv.URL+`/jwks`),
+	)
+	require.NoError(t, err, `preset.Firebase should succeed`)
+	_, err = other.Parse(iss.sign(t, build(t, `uid-1`, time.Now())))
+	require.Error(t, err, `tokens of other projects should be rejected`)
+}
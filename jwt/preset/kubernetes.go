@@ -0,0 +1,141 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// KubernetesClaimKey is the name of the private claim that describes the
+// service account (and the objects the token is bound to) in Kubernetes
+// service account tokens.
+const KubernetesClaimKey = `kubernetes.io`
+
+// KubernetesSubjectPrefix is the prefix of the `sub` claim of Kubernetes
+// service account tokens, which is followed by `<namespace>:<name>`.
+const KubernetesSubjectPrefix = `system:serviceaccount:`
+
+// KubernetesObject identifies a Kubernetes object.
+type KubernetesObject struct {
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+}
+
+// KubernetesClaims is the content of the `kubernetes.io` claim of
+// Kubernetes service account tokens. `Pod`, `Secret`, and `Node` are
+// only set for tokens that are bound to such objects.
+type KubernetesClaims struct {
+	Namespace      string            `json:"namespace"`
+	ServiceAccount KubernetesObject  `json:"serviceaccount"`
+	Pod            *KubernetesObject `json:"pod,omitempty"`
+	Secret         *KubernetesObject `json:"secret,omitempty"`
+	Node           *KubernetesObject `json:"node,omitempty"`
+	// WarnAfter is the time (in seconds since the epoch) after which
+	// the use of an automatically extended token is logged by the
+	// API server.
+	WarnAfter int64 `json:"warnafter,omitempty"`
+}
+
+// KubernetesClaimsOf returns the `kubernetes.io` claim of `t`.
+func KubernetesClaimsOf(t jwt.Token) (*KubernetesClaims, error) {
+	var claims KubernetesClaims
+	if err := decodeClaim(t, KubernetesClaimKey, &claims); err != nil {
+		return nil, fmt.Errorf(`preset.KubernetesClaimsOf: %w`, err)
+	}
+	return &claims, nil
+}
+
+// Kubernetes creates a Preset for the projected service account tokens
+// issued by the Kubernetes cluster whose service account issuer is
+// `issuer` (the `--service-account-issuer` flag of the API server).
+//
+// The JWKS of the cluster is located through OpenID Connect Discovery,
+// and is fetched and refreshed using `cache`. The discovery endpoints
+// of the API server must be reachable by the client given via
+// `preset.WithHTTPClient()`, which may need to be configured with the
+// cluster CA and credentials.
+//
+// Tokens must be issued by `issuer` for `audience`, must have an `exp`
+// claim, and must have a `kubernetes.io` claim that names the service
+// account in the namespace that the `sub` claim refers to. The tokens
+// can be further restricted using `preset.WithNamespace()` and
+// `preset.WithServiceAccount()`.
+//
+// Legacy service account tokens, which are stored in secrets and do
+// not expire, are not accepted.
+func Kubernetes(cache *jwk.Cache, issuer, audience string, options ...Option) (*Preset, error) {
+	if issuer == `` || audience == `` {
+		return nil, fmt.Errorf(`preset.Kubernetes: issuer and audience must be specified`)
+	}
+
+	var keyOptions []interface{}
+	v := &kubernetesValidator{}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			keyOptions = append(keyOptions, jwk.WithHTTPClient(o.Value().(jwk.HTTPClient)))
+		case identNamespace{}:
+			for _, ns := range o.Value().([]string) {
+				if v.namespaces == nil {
+					v.namespaces = make(map[string]struct{})
+				}
+				v.namespaces[ns] = struct{}{}
+			}
+		case identServiceAccount{}:
+			sa := o.Value().(serviceAccount)
+			if v.serviceAccounts == nil {
+				v.serviceAccounts = make(map[serviceAccount]struct{})
+			}
+			v.serviceAccounts[sa] = struct{}{}
+		default:
+			return nil, fmt.Errorf(`preset.Kubernetes: invalid option %T`, o)
+		}
+	}
+
+	keys, err := jwt.NewIssuerKeyProvider(cache, []string{issuer}, keyOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Kubernetes: %w`, err)
+	}
+
+	return New(`kubernetes`,
+		jwt.WithIssuerKeyProvider(keys),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithRequireExpiration(true),
+		jwt.WithValidator(v),
+	)
+}
+
+type kubernetesValidator struct {
+	namespaces      map[string]struct{}
+	serviceAccounts map[serviceAccount]struct{}
+}
+
+func (v *kubernetesValidator) Validate(_ context.Context, t jwt.Token) jwt.ValidationError {
+	claims, err := KubernetesClaimsOf(t)
+	if err != nil {
+		return jwt.NewValidationError(err)
+	}
+	if claims.Namespace == `` || claims.ServiceAccount.Name == `` {
+		return jwt.NewValidationError(fmt.Errorf(`%q claim must contain the namespace and the service account`, KubernetesClaimKey))
+	}
+	if sub := KubernetesSubjectPrefix + claims.Namespace + `:` + claims.ServiceAccount.Name; t.Subject() != sub {
+		return jwt.NewValidationError(fmt.Errorf(`%q claim does not match %q claim`, jwt.SubjectKey, KubernetesClaimKey))
+	}
+	if v.namespaces != nil {
+		if _, ok := v.namespaces[claims.Namespace]; !ok {
+			return jwt.NewValidationError(fmt.Errorf(`namespace %q is not allowed`, claims.Namespace))
+		}
+	}
+	if v.serviceAccounts != nil {
+		sa := serviceAccount{namespace: claims.Namespace, name: claims.ServiceAccount.Name}
+		if _, ok := v.serviceAccounts[sa]; !ok {
+			return jwt.NewValidationError(fmt.Errorf(`service account %q is not allowed`, strings.TrimPrefix(t.Subject(), KubernetesSubjectPrefix)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package preset_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/preset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetes(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iss := newTestIssuer(t)
+	cache := jwk.NewCache(ctx)
+
+	build := func(t *testing.T, aud, sub string, claim interface{}) jwt.Token {
+		t.Helper()
+		b := jwt.NewBuilder().
+			Issuer(iss.srv.URL).
+			Audience([]string{aud}).
+			Subject(sub).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Hour))
+		if claim != nil {
+			b.Claim(preset.KubernetesClaimKey, claim)
+		}
+		tok, err := b.Build()
+		require.NoError(t, err, `Build should succeed`)
+		return tok
+	}
+	claim := map[string]interface{}{
+		`namespace`: `default`,
+		`pod`: map[string]interface{}{
+			`name`: `app-7d9c`,
+			`uid`:  `0a7f3c1e-7d2f-4f5e-9f36-1b3a53c5e0aa`,
+		},
+		`serviceaccount`: map[string]interface{}{
+			`name`: `app`,
+			`uid`:  `d4a3c7b5-5a4e-4bd7-9a4f-1d0f3a0e1b2c`,
+		},
+	}
+	const sub = `system:serviceaccount:default:app`
+
+	p, err := preset.Kubernetes(cache, iss.srv.URL, `vault`, preset.WithHTTPClient(iss.srv.Client()))
+	require.NoError(t, err, `preset.Kubernetes should succeed`)
+
+	t.Run("valid token", func(t *testing.T) {
+		tok, err := p.Parse(iss.sign(t, build(t, `vault`, sub, claim)))
+		require.NoError(t, err, `p.Parse should succeed`)
+
+		claims, err := preset.KubernetesClaimsOf(tok)
+		require.NoError(t, err, `preset.KubernetesClaimsOf should succeed`)
+		require.Equal(t, `default`, claims.Namespace)
+		require.Equal(t, `app`, claims.ServiceAccount.Name)
+		require.NotNil(t, claims.Pod)
+		require.Equal(t, `app-7d9c`, claims.Pod.Name)
+		require.Nil(t, claims.Node)
+	})
+	t.Run("invalid tokens", func(t *testing.T) {
+		_, err := p.Parse(iss.sign(t, build(t, `other`, sub, claim)))
+		require.Error(t, err, `tokens for other audiences should be rejected`)
+		_, err = p.Parse(iss.sign(t, build(t, `vault`, sub, nil)))
+		require.Error(t, err, `tokens without the kubernetes.io claim should be rejected`)
+		_, err = p.Parse(iss.sign(t, build(t, `vault`, `system:serviceaccount:kube-system:app`, claim)))
+		require.Error(t, err, `tokens whose sub does not match should be rejected`)
+		_, err = p.Parse(iss.sign(t, build(t, `vault`, sub, map[string]interface{}{`namespace`: `default`})))
+		require.Error(t, err, `tokens without the service account should be rejected`)
+
+		noexp := build(t, `vault`, sub, claim)
+		require.NoError(t, noexp.Remove(jwt.ExpirationKey), `Remove should succeed`)
+		_, err = p.Parse(iss.sign(t, noexp))
+		require.Error(t, err, `tokens without exp should be rejected`)
+	})
+	t.Run("restrictions", func(t *testing.T) {
+		restricted, err := preset.Kubernetes(cache, iss.srv.URL, `vault`,
+			preset.WithHTTPClient(iss.srv.Client()),
+			preset.WithNamespace(`kube-system`),
+		)
+		require.NoError(t, err, `preset.Kubernetes should succeed`)
+		_, err = restricted.Parse(iss.sign(t, build(t, `vault`, sub, claim)))
+		require.Error(t, err, `tokens in other namespaces should be rejected`)
+
+		restricted, err = preset.Kubernetes(cache, iss.srv.URL, `vault`,
+			preset.WithHTTPClient(iss.srv.Client()),
+			preset.WithServiceAccount(`default`, `other`),
+			preset.WithServiceAccount(`default`, `app`),
+		)
+		require.NoError(t, err, `preset.Kubernetes should succeed`)
+		_, err = restricted.Parse(iss.sign(t, build(t, `vault`, sub, claim)))
+		require.NoError(t, err, `allowed service accounts should be accepted`)
+
+		restricted, err = preset.Kubernetes(cache, iss.srv.URL, `vault`,
+			preset.WithHTTPClient(iss.srv.Client()),
+			preset.WithServiceAccount(`default`, `other`),
+		)
+		require.NoError(t, err, `preset.Kubernetes should succeed`)
+		_, err = restricted.Parse(iss.sign(t, build(t, `vault`, sub, claim)))
+		require.Error(t, err, `other service accounts should be rejected`)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := preset.Kubernetes(cache, ``, `vault`)
+		require.Error(t, err, `issuer should be required`)
+		_, err = preset.Kubernetes(cache, iss.srv.URL, ``)
+		require.Error(t, err, `audience should be required`)
+		_, err = preset.Kubernetes(nil, iss.srv.URL, `vault`)
+		require.Error(t, err, `jwk.Cache should be required`)
+	})
+}
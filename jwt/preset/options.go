@@ -0,0 +1,71 @@
+package preset
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to the functions that
+// create presets, such as `preset.Kubernetes()`.
+type Option = option.Interface
+
+type identHTTPClient struct{}
+type identHostedDomain struct{}
+type identIssuerTemplate struct{}
+type identJWKSURL struct{}
+type identNamespace struct{}
+type identServiceAccount struct{}
+type identTenant struct{}
+
+type serviceAccount struct {
+	namespace string
+	name      string
+}
+
+// WithHTTPClient specifies the HTTP client that is used to fetch the
+// OpenID Provider Configuration documents and the JWKS of the issuer.
+// By default, `http.DefaultClient` is used.
+func WithHTTPClient(v jwk.HTTPClient) Option {
+	return option.New(identHTTPClient{}, v)
+}
+
+// WithJWKSURL overrides the URL of the JWKS of the issuer, for example to
+// go through a proxy or to use a local server in tests. It is only
+// accepted by presets whose JWKS is located at a fixed URL, such as
+// `preset.Google()`.
+func WithJWKSURL(v string) Option {
+	return option.New(identJWKSURL{}, v)
+}
+
+// WithHostedDomain restricts `preset.Google()` to tokens of Google
+// Workspace accounts in the domains `v`, which are compared against the
+// `hd` claim. It may be specified multiple times.
+func WithHostedDomain(v ...string) Option {
+	return option.New(identHostedDomain{}, v)
+}
+
+// WithNamespace restricts `preset.Kubernetes()` to tokens of service
+// accounts in the namespaces `v`. It may be specified multiple times.
+func WithNamespace(v ...string) Option {
+	return option.New(identNamespace{}, v)
+}
+
+// WithServiceAccount restricts `preset.Kubernetes()` to tokens of the
+// service account `name` in the namespace `namespace`. It may be
+// specified multiple times to allow multiple service accounts.
+func WithServiceAccount(namespace, name string) Option {
+	return option.New(identServiceAccount{}, serviceAccount{namespace: namespace, name: name})
+}
+
+// WithTenant restricts `preset.AzureAD()` to tokens of the tenants whose
+// IDs are `v`. It may be specified multiple times.
+func WithTenant(v ...string) Option {
+	return option.New(identTenant{}, v)
+}
+
+// WithIssuerTemplate overrides the issuer template of `preset.AzureAD()`,
+// for example to accept tokens issued by national clouds. `v` must contain
+// `preset.AzureADTenantPlaceholder`.
+func WithIssuerTemplate(v string) Option {
+	return option.New(identIssuerTemplate{}, v)
+}
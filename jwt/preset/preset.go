@@ -0,0 +1,190 @@
+// Package preset provides ready-made configurations for verifying and
+// validating tokens issued by well-known token issuers, such as the
+// service account tokens of Kubernetes clusters.
+//
+// Each configuration is represented by a `preset.Preset`, which bundles
+// the key source (e.g. the JWKS of the issuer, fetched and refreshed
+// through a `jwk.Cache`) and the validation rules that the tokens must
+// satisfy. Presets should be created once and reused:
+//
+//	p, err := preset.Kubernetes(cache, issuer, audience)
+//	...
+//	// for each token
+//	tok, err := p.Parse(src)
+package preset
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
+)
+
+// keyRotationRefreshInterval is the minimum interval between the
+// refreshes of a JWKS that are triggered by unknown key IDs
+const keyRotationRefreshInterval = time.Minute
+
+// Preset is a named set of `jwt.ParseOption`s that verify and validate
+// the tokens issued by a particular token issuer. A Preset is safe for
+// concurrent use.
+type Preset struct {
+	name    string
+	options []jwt.ParseOption
+	// token creates the token that each message is parsed into, as
+	// tokens passed via jwt.WithToken() may not be shared
+	token func() jwt.Token
+	roles func(jwt.Token) []string
+}
+
+// New creates a Preset named `name`, which parses tokens using `options`.
+// `options` should contain the key source (e.g. `jwt.WithKeyProvider()`)
+// and the `jwt.ValidateOption`s that the tokens must satisfy.
+// `jwt.WithVerify()` and `jwt.WithValidate()` may not be specified.
+func New(name string, options ...jwt.ParseOption) (*Preset, error) {
+	if name == `` {
+		return nil, fmt.Errorf(`preset.New: name must be specified`)
+	}
+	if err := checkOptions(options); err != nil {
+		return nil, fmt.Errorf(`preset.New: %w`, err)
+	}
+	return &Preset{
+		name:    name,
+		options: append([]jwt.ParseOption(nil), options...),
+	}, nil
+}
+
+func checkOptions(options []jwt.ParseOption) error {
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return fmt.Errorf(`jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the Preset.
+func (p *Preset) Name() string {
+	return p.name
+}
+
+// Roles returns the roles, groups, or permissions of the subject of `t`,
+// which must have been parsed using the Preset, in the form used by the
+// token issuer. nil is returned if the Preset does not know where the
+// token issuer stores them (see `preset.Provider`).
+func (p *Preset) Roles(t jwt.Token) []string {
+	if p.roles == nil {
+		return nil
+	}
+	return p.roles(t)
+}
+
+// Options returns a copy of the `jwt.ParseOption`s of the Preset, so
+// that they can be combined with other options when calling
+// `jwt.Parse()` directly.
+func (p *Preset) Options() []jwt.ParseOption {
+	return append([]jwt.ParseOption(nil), p.options...)
+}
+
+// Parse parses `src`, and verifies and validates it according to the
+// Preset. Additional `options` (e.g. `jwt.WithContext()`,
+// `jwt.WithClock()`, or validators for application specific claims) are
+// applied in addition to the options of the Preset, but
+// `jwt.WithVerify()` and `jwt.WithValidate()` may not be specified.
+func (p *Preset) Parse(src []byte, options ...jwt.ParseOption) (jwt.Token, error) {
+	if err := checkOptions(options); err != nil {
+		return nil, fmt.Errorf(`preset.Parse: %w`, err)
+	}
+	parseOptions := p.Options()
+	if p.token != nil {
+		parseOptions = append(parseOptions, jwt.WithToken(p.token()))
+	}
+	tok, err := jwt.Parse(src, append(parseOptions, options...)...)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.Parse: %s: %w`, p.name, err)
+	}
+	return tok, nil
+}
+
+// newOpenIDToken is used by presets for OpenID Connect providers, whose
+// tokens are parsed into openid.Tokens
+func newOpenIDToken() jwt.Token {
+	return openid.New()
+}
+
+// decodeClaim decodes the private claim `name` of `t` into `dst`
+func decodeClaim(t jwt.Token, name string, dst interface{}) error {
+	v, ok := t.Get(name)
+	if !ok {
+		return fmt.Errorf(`%q claim not found`, name)
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf(`failed to encode %q claim: %w`, name, err)
+	}
+	if err := json.Unmarshal(buf, dst); err != nil {
+		return fmt.Errorf(`failed to decode %q claim: %w`, name, err)
+	}
+	return nil
+}
+
+// keySetProvider is a `jwk.Provider` that returns the JWKS stored in a
+// `jwk.Cache`. Issuers that rotate their keys frequently may start using
+// a new key before the cached JWKS expires, so the JWKS is refreshed when
+// it does not contain the key ID of the message. Such refreshes are
+// rate limited, as the key ID is chosen by whoever created the message
+type keySetProvider struct {
+	cache *jwk.Cache
+	url   string
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+}
+
+// newKeySetProvider registers `u` to `cache` unless it has already been
+// registered, and returns a keySetProvider for it
+func newKeySetProvider(cache *jwk.Cache, u string, client jwk.HTTPClient) (*keySetProvider, error) {
+	if cache == nil {
+		return nil, fmt.Errorf(`jwk.Cache is required`)
+	}
+	if !cache.IsRegistered(u) {
+		var options []jwk.RegisterOption
+		if client != nil {
+			options = append(options, jwk.WithHTTPClient(client))
+		}
+		if err := cache.Register(u, options...); err != nil {
+			return nil, fmt.Errorf(`failed to register %q: %w`, u, err)
+		}
+	}
+	return &keySetProvider{cache: cache, url: u}, nil
+}
+
+func (p *keySetProvider) FetchKeys(ctx context.Context, hints jwk.ProviderHints) (jwk.Set, error) {
+	set, err := p.cache.Get(ctx, p.url)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to fetch %q: %w`, p.url, err)
+	}
+	if hints.KeyID == `` {
+		return set, nil
+	}
+	if _, ok := set.LookupKeyID(hints.KeyID); ok {
+		return set, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if time.Since(p.lastRefresh) < keyRotationRefreshInterval {
+		return set, nil
+	}
+	p.lastRefresh = time.Now()
+	refreshed, err := p.cache.Refresh(ctx, p.url)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to refresh %q: %w`, p.url, err)
+	}
+	return refreshed, nil
+}
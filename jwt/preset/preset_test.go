@@ -0,0 +1,117 @@
+package preset_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/preset"
+	"github.com/stretchr/testify/require"
+)
+
+// testIssuer is an issuer that publishes its OpenID Provider
+// Configuration and JWKS over HTTPS
+type testIssuer struct {
+	srv *httptest.Server
+	mux *http.ServeMux
+
+	mu   sync.RWMutex
+	keys int
+	key  jwk.Key
+	set  jwk.Set
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	iss := &testIssuer{mux: http.NewServeMux()}
+	iss.rotate(t)
+
+	srv := httptest.NewTLSServer(iss.mux)
+	t.Cleanup(srv.Close)
+	iss.srv = srv
+	iss.serveDiscovery(``)
+	iss.mux.HandleFunc(`/jwks`, func(w http.ResponseWriter, _ *http.Request) {
+		iss.mu.RLock()
+		defer iss.mu.RUnlock()
+		w.Header().Set(`Content-Type`, `application/json`)
+		_ = json.NewEncoder(w).Encode(iss.set)
+	})
+	return iss
+}
+
+// serveDiscovery publishes the OpenID Provider Configuration for the
+// issuer `iss.srv.URL + path`
+func (iss *testIssuer) serveDiscovery(path string) {
+	issuer := iss.srv.URL + path
+	iss.mux.HandleFunc(path+jwt.DiscoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(`Content-Type`, `application/json`)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			`issuer`:   issuer,
+			`jwks_uri`: iss.srv.URL + `/jwks`,
+		})
+	})
+}
+
+// rotate replaces the signing key, and publishes only the new key
+func (iss *testIssuer) rotate(t *testing.T) {
+	t.Helper()
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.keys++
+	require.NoError(t, key.Set(jwk.KeyIDKey, fmt.Sprintf(`test-key-%d`, iss.keys)), `key.Set should succeed`)
+	require.NoError(t, key.Set(jwk.AlgorithmKey, jwa.RS256), `key.Set should succeed`)
+	pubkey, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pubkey), `set.AddKey should succeed`)
+	iss.key = key
+	iss.set = set
+}
+
+func (iss *testIssuer) sign(t *testing.T, tok jwt.Token) []byte {
+	t.Helper()
+	iss.mu.RLock()
+	defer iss.mu.RUnlock()
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, iss.key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+	return signed
+}
+
+func TestPreset(t *testing.T) {
+	t.Parallel()
+
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+	p, err := preset.New(`test`, jwt.WithKey(jwa.HS256, key), jwt.WithIssuer(`https://issuer.example.com`))
+	require.NoError(t, err, `preset.New should succeed`)
+	require.Equal(t, `test`, p.Name())
+	require.Len(t, p.Options(), 2)
+
+	tok, err := jwt.NewBuilder().Issuer(`https://issuer.example.com`).Subject(`alice`).Build()
+	require.NoError(t, err, `Build should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	parsed, err := p.Parse(signed)
+	require.NoError(t, err, `p.Parse should succeed`)
+	require.Equal(t, `alice`, parsed.Subject())
+
+	_, err = p.Parse(signed, jwt.WithSubject(`bob`))
+	require.Error(t, err, `additional validation options should be applied`)
+	_, err = p.Parse(signed, jwt.WithValidate(false))
+	require.Error(t, err, `jwt.WithValidate() should be rejected`)
+
+	_, err = preset.New(`test`, jwt.WithVerify(false))
+	require.Error(t, err, `jwt.WithVerify() should be rejected`)
+	_, err = preset.New(``)
+	require.Error(t, err, `name should be required`)
+}
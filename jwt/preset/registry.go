@@ -0,0 +1,275 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Names of the providers that are registered by default
+const (
+	Auth0    = `auth0`
+	Okta     = `okta`
+	Cognito  = `cognito`
+	Keycloak = `keycloak`
+)
+
+// Provider describes an identity provider that hosts many issuers (e.g.
+// one per tenant, realm, or user pool) at predictable URLs, and publishes
+// their keys through OpenID Connect Discovery. Providers are registered
+// using `preset.RegisterProvider()`, and presets are created for a
+// particular issuer using `preset.FromProvider()`.
+type Provider struct {
+	// IssuerTemplate is the issuer identifier, in which placeholders of
+	// the form `{name}` are replaced by the parameters passed to
+	// `preset.FromProvider()`, such as
+	// `https://cognito-idp.{region}.amazonaws.com/{userPoolID}`.
+	IssuerTemplate string
+	// Audience returns the option that checks that the token was issued
+	// for `audience`, for providers that do not always put it in the `aud`
+	// claim. If nil, `jwt.WithAudience()` is used.
+	Audience func(audience string) jwt.ValidateOption
+	// Options are applied in addition to the issuer and audience checks.
+	Options []jwt.ValidateOption
+	// Roles returns the roles, groups, or permissions of the subject of
+	// a token, as returned by `(preset.Preset).Roles()`. It may be nil.
+	Roles func(jwt.Token) []string
+}
+
+var providers = struct {
+	mu   sync.RWMutex
+	data map[string]Provider
+}{
+	data: map[string]Provider{
+		Auth0: {
+			IssuerTemplate: `https://{domain}/`,
+			Options:        []jwt.ValidateOption{jwt.WithRequireExpiration(true)},
+			Roles:          stringsClaim(`permissions`),
+		},
+		Okta: {
+			IssuerTemplate: `https://{domain}/oauth2/{authorizationServer}`,
+			Options:        []jwt.ValidateOption{jwt.WithRequireExpiration(true)},
+			Roles:          stringsClaim(`groups`),
+		},
+		Cognito: {
+			IssuerTemplate: `https://cognito-idp.{region}.amazonaws.com/{userPoolID}`,
+			Audience:       cognitoAudience,
+			Options: []jwt.ValidateOption{
+				jwt.WithRequireExpiration(true),
+				jwt.WithRequiredClaim(cognitoTokenUseKey),
+			},
+			Roles: stringsClaim(`cognito:groups`),
+		},
+		Keycloak: {
+			IssuerTemplate: `https://{host}/realms/{realm}`,
+			Audience:       keycloakAudience,
+			Options:        []jwt.ValidateOption{jwt.WithRequireExpiration(true)},
+			Roles:          keycloakRealmRoles,
+		},
+	},
+}
+
+var placeholderRx = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// RegisterProvider registers the Provider `p` under `name`, replacing any
+// Provider registered under the same name, including the ones that are
+// registered by default. It is usually called from `init()`.
+func RegisterProvider(name string, p Provider) error {
+	if name == `` {
+		return fmt.Errorf(`preset.RegisterProvider: name must be specified`)
+	}
+	if !placeholderRx.MatchString(p.IssuerTemplate) {
+		return fmt.Errorf(`preset.RegisterProvider: issuer template of %q must contain at least one placeholder`, name)
+	}
+	providers.mu.Lock()
+	defer providers.mu.Unlock()
+	providers.data[name] = p
+	return nil
+}
+
+// LookupProvider returns the Provider registered under `name`. It can be
+// used to register a variant of a Provider, for example one with a custom
+// domain:
+//
+//	p, _ := preset.LookupProvider(preset.Okta)
+//	p.IssuerTemplate = `https://login.example.com/oauth2/{authorizationServer}`
+//	preset.RegisterProvider(`example-okta`, p)
+func LookupProvider(name string) (Provider, bool) {
+	providers.mu.RLock()
+	defer providers.mu.RUnlock()
+	p, ok := providers.data[name]
+	return p, ok
+}
+
+// Providers returns the names of the registered Providers, in sorted
+// order.
+func Providers() []string {
+	providers.mu.RLock()
+	defer providers.mu.RUnlock()
+	names := make([]string, 0, len(providers.data))
+	for name := range providers.data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromProvider creates a Preset for the issuer of the Provider registered
+// under `name`, whose issuer identifier is obtained by replacing the
+// placeholders in its issuer template with `params`. For example:
+//
+//	p, err := preset.FromProvider(cache, preset.Cognito, clientID, map[string]string{
+//	  `region`:     `us-east-1`,
+//	  `userPoolID`: `us-east-1_Abc123`,
+//	})
+//
+// The JWKS of the issuer is located through OpenID Connect Discovery, and
+// is fetched and refreshed using `cache`. Tokens must be issued by the
+// issuer for `audience`, and must satisfy the options of the Provider.
+// Of the `preset.Option`s, only `preset.WithHTTPClient()` is accepted.
+func FromProvider(cache *jwk.Cache, name, audience string, params map[string]string, options ...Option) (*Preset, error) {
+	p, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf(`preset.FromProvider: provider %q is not registered`, name)
+	}
+	if audience == `` {
+		return nil, fmt.Errorf(`preset.FromProvider: audience must be specified`)
+	}
+
+	var missing []string
+	issuer := placeholderRx.ReplaceAllStringFunc(p.IssuerTemplate, func(placeholder string) string {
+		param := strings.Trim(placeholder, `{}`)
+		v, ok := params[param]
+		if !ok || v == `` {
+			missing = append(missing, param)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf(`preset.FromProvider: parameters %q are required for provider %q`, missing, name)
+	}
+
+	var keyOptions []interface{}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			keyOptions = append(keyOptions, jwk.WithHTTPClient(o.Value().(jwk.HTTPClient)))
+		default:
+			return nil, fmt.Errorf(`preset.FromProvider: invalid option %T`, o)
+		}
+	}
+
+	keys, err := jwt.NewIssuerKeyProvider(cache, []string{issuer}, keyOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.FromProvider: %w`, err)
+	}
+
+	audienceOption := jwt.WithAudience(audience)
+	if p.Audience != nil {
+		audienceOption = p.Audience(audience)
+	}
+	parseOptions := []jwt.ParseOption{
+		jwt.WithIssuerKeyProvider(keys),
+		jwt.WithIssuer(issuer),
+		audienceOption,
+	}
+	for _, o := range p.Options {
+		parseOptions = append(parseOptions, o)
+	}
+
+	result, err := New(name, parseOptions...)
+	if err != nil {
+		return nil, fmt.Errorf(`preset.FromProvider: %w`, err)
+	}
+	result.roles = p.Roles
+	return result, nil
+}
+
+// stringsClaim returns a function that returns the claim `name` as a list
+// of strings
+func stringsClaim(name string) func(jwt.Token) []string {
+	return func(t jwt.Token) []string {
+		v, _ := t.Get(name)
+		return toStrings(v)
+	}
+}
+
+func toStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+// Claims of Amazon Cognito tokens
+const (
+	cognitoTokenUseKey = `token_use`
+	cognitoClientIDKey = `client_id`
+)
+
+// cognitoAudience checks the `aud` claim of ID Tokens, and the `client_id`
+// claim of access tokens, which do not have an `aud` claim
+func cognitoAudience(audience string) jwt.ValidateOption {
+	return jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
+		use, _ := t.Get(cognitoTokenUseKey)
+		switch use {
+		case `id`:
+			for _, aud := range t.Audience() {
+				if aud == audience {
+					return nil
+				}
+			}
+		case `access`:
+			if clientID, _ := t.Get(cognitoClientIDKey); clientID == audience {
+				return nil
+			}
+		default:
+			return jwt.NewValidationError(fmt.Errorf(`%q claim must be "id" or "access"`, cognitoTokenUseKey))
+		}
+		return jwt.ErrInvalidAudience()
+	}))
+}
+
+// keycloakAudience accepts tokens whose `aud` claim contains `audience`,
+// or whose `azp` claim is `audience`, as Keycloak does not include the
+// client in the `aud` claim of access tokens by default
+func keycloakAudience(audience string) jwt.ValidateOption {
+	return jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, t jwt.Token) jwt.ValidationError {
+		for _, aud := range t.Audience() {
+			if aud == audience {
+				return nil
+			}
+		}
+		if azp, _ := t.Get(`azp`); azp == audience {
+			return nil
+		}
+		return jwt.ErrInvalidAudience()
+	}))
+}
+
+// keycloakRealmRoles returns the realm roles in the `realm_access` claim
+func keycloakRealmRoles(t jwt.Token) []string {
+	v, _ := t.Get(`realm_access`)
+	access, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return toStrings(access[`roles`])
+}
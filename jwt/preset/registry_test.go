@@ -0,0 +1,104 @@
+package preset_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/preset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	iss := newTestIssuer(t)
+	host := strings.TrimPrefix(iss.srv.URL, `https://`)
+	cache := jwk.NewCache(ctx)
+
+	build := func(t *testing.T, issuer string, claims map[string]interface{}) []byte {
+		t.Helper()
+		b := jwt.NewBuilder().
+			Issuer(issuer).
+			Subject(`alice`).
+			Expiration(time.Now().Add(time.Hour))
+		for name, value := range claims {
+			b.Claim(name, value)
+		}
+		tok, err := b.Build()
+		require.NoError(t, err, `Build should succeed`)
+		return iss.sign(t, tok)
+	}
+
+	require.Subset(t, preset.Providers(), []string{preset.Auth0, preset.Cognito, preset.Keycloak, preset.Okta})
+
+	t.Run("Keycloak", func(t *testing.T) {
+		iss.serveDiscovery(`/realms/test`)
+		p, err := preset.FromProvider(cache, preset.Keycloak, `my-app`,
+			map[string]string{`host`: host, `realm`: `test`},
+			preset.WithHTTPClient(iss.srv.Client()),
+		)
+		require.NoError(t, err, `preset.FromProvider should succeed`)
+		require.Equal(t, preset.Keycloak, p.Name())
+
+		issuer := iss.srv.URL + `/realms/test`
+		tok, err := p.Parse(build(t, issuer, map[string]interface{}{
+			jwt.AudienceKey: `account`,
+			`azp`:           `my-app`,
+			`realm_access`:  map[string]interface{}{`roles`: []string{`admin`, `user`}},
+		}))
+		require.NoError(t, err, `p.Parse should succeed for access tokens issued to the client`)
+		require.Equal(t, []string{`admin`, `user`}, p.Roles(tok))
+
+		_, err = p.Parse(build(t, issuer, map[string]interface{}{jwt.AudienceKey: `account`, `azp`: `other`}))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `tokens for other clients should be rejected`)
+		_, err = p.Parse(build(t, iss.srv.URL+`/realms/other`, map[string]interface{}{`azp`: `my-app`}))
+		require.Error(t, err, `tokens of other realms should be rejected`)
+	})
+	t.Run("custom provider", func(t *testing.T) {
+		cognito, ok := preset.LookupProvider(preset.Cognito)
+		require.True(t, ok, `preset.LookupProvider should succeed`)
+		cognito.IssuerTemplate = `https://{host}/pools/{userPoolID}`
+		require.NoError(t, preset.RegisterProvider(`test-cognito`, cognito), `preset.RegisterProvider should succeed`)
+		require.Contains(t, preset.Providers(), `test-cognito`)
+
+		iss.serveDiscovery(`/pools/pool-1`)
+		p, err := preset.FromProvider(cache, `test-cognito`, `client-1`,
+			map[string]string{`host`: host, `userPoolID`: `pool-1`},
+			preset.WithHTTPClient(iss.srv.Client()),
+		)
+		require.NoError(t, err, `preset.FromProvider should succeed`)
+
+		issuer := iss.srv.URL + `/pools/pool-1`
+		tok, err := p.Parse(build(t, issuer, map[string]interface{}{
+			`token_use`:      `access`,
+			`client_id`:      `client-1`,
+			`cognito:groups`: []string{`editors`},
+		}))
+		require.NoError(t, err, `p.Parse should succeed for access tokens`)
+		require.Equal(t, []string{`editors`}, p.Roles(tok))
+
+		_, err = p.Parse(build(t, issuer, map[string]interface{}{`token_use`: `id`, jwt.AudienceKey: `client-1`}))
+		require.NoError(t, err, `p.Parse should succeed for ID tokens`)
+		_, err = p.Parse(build(t, issuer, map[string]interface{}{`token_use`: `access`, `client_id`: `client-2`}))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `tokens for other clients should be rejected`)
+		_, err = p.Parse(build(t, issuer, map[string]interface{}{`client_id`: `client-1`}))
+		require.Error(t, err, `tokens without token_use should be rejected`)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := preset.FromProvider(cache, `unknown`, `my-app`, nil)
+		require.Error(t, err, `unknown providers should be rejected`)
+		_, err = preset.FromProvider(cache, preset.Okta, `my-app`, map[string]string{`domain`: `example.okta.com`})
+		require.Error(t, err, `missing parameters should be rejected`)
+		_, err = preset.FromProvider(cache, preset.Okta, ``, map[string]string{`domain`: `example.okta.com`, `authorizationServer`: `default`})
+		require.Error(t, err, `audience should be required`)
+		require.Error(t, preset.RegisterProvider(`static`, preset.Provider{IssuerTemplate: `https://example.com`}), `templates without placeholders should be rejected`)
+	})
+}
@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+)
+
+const redactedValue = `[REDACTED]`
+
+var unredactedClaimsMu sync.RWMutex
+var unredactedClaims = map[string]struct{}{
+	IssuerKey:     {},
+	SubjectKey:    {},
+	AudienceKey:   {},
+	ExpirationKey: {},
+	NotBeforeKey:  {},
+	IssuedAtKey:   {},
+	JwtIDKey:      {},
+}
+
+func setUnredactedClaims(names []string) {
+	m := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		m[name] = struct{}{}
+	}
+	unredactedClaimsMu.Lock()
+	unredactedClaims = m
+	unredactedClaimsMu.Unlock()
+}
+
+// Redact returns the claims in `t` as a map, in which the values of the
+// claims other than those specified via `jwt.WithUnredactedClaims()` are
+// replaced with the string "[REDACTED]".
+//
+// This is used by the `String()` and `LogValue()` methods of the tokens
+// in this module, and can be used to implement them for other token types.
+func Redact(t Token) map[string]interface{} {
+	claims, err := t.AsMap(context.Background())
+	if err != nil {
+		return map[string]interface{}{}
+	}
+
+	unredactedClaimsMu.RLock()
+	defer unredactedClaimsMu.RUnlock()
+	for name := range claims {
+		if _, ok := unredactedClaims[name]; !ok {
+			claims[name] = redactedValue
+		}
+	}
+	return claims
+}
+
+// RedactedString returns the claims in `t`, redacted as described in
+// `jwt.Redact()`, in JSON format.
+func RedactedString(t Token) string {
+	buf, err := json.Marshal(Redact(t))
+	if err != nil {
+		return fmt.Sprintf(`failed to marshal redacted claims: %s`, err)
+	}
+	return string(buf)
+}
+
+// String returns the claims of the token in JSON format, with the values
+// of sensitive claims redacted. See `jwt.WithUnredactedClaims()`.
+//
+// Use `json.Marshal()` to obtain the claims as-is.
+func (t *stdToken) String() string {
+	return RedactedString(t)
+}
@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package jwt
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// RedactedLogValue returns the claims in `t`, redacted as described in
+// `jwt.Redact()`, as a `slog.Value`.
+func RedactedLogValue(t Token) slog.Value {
+	claims := Redact(t)
+	names := make([]string, 0, len(claims))
+	for name := range claims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, slog.Any(name, claims[name]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements `slog.LogValuer`, so that the values of sensitive
+// claims are redacted when the token is logged via `log/slog`.
+// See `jwt.WithUnredactedClaims()`.
+func (t *stdToken) LogValue() slog.Value {
+	return RedactedLogValue(t)
+}
+
+// LogValue implements `slog.LogValuer`. See `(*stdToken).LogValue()`.
+func (t *frozenToken) LogValue() slog.Value {
+	return RedactedLogValue(t)
+}
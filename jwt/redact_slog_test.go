@@ -0,0 +1,31 @@
+//go:build go1.21
+// +build go1.21
+
+package jwt_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactLogValue(t *testing.T) {
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Claim(`email`, `alice@example.com`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info(`parsed`, `token`, tok)
+
+	out := buf.String()
+	require.True(t, strings.Contains(out, `token.sub=alice`), `sub should be logged: %s`, out)
+	require.True(t, strings.Contains(out, `token.email=[REDACTED]`), `email should be redacted: %s`, out)
+	require.False(t, strings.Contains(out, `alice@example.com`), `email should not be logged: %s`, out)
+}
@@ -0,0 +1,61 @@
+package jwt_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	tok, err := jwt.NewBuilder().
+		Issuer(`https://example.com`).
+		Subject(`alice`).
+		Expiration(time.Unix(1700000000, 0)).
+		Claim(`email`, `alice@example.com`).
+		Claim(`refresh_token`, `tGzv3JOkF0XG5Qx2TlKWIA`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	decode := func(t *testing.T, s string) map[string]interface{} {
+		t.Helper()
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(s), &m), `redacted string should be valid JSON`)
+		return m
+	}
+
+	t.Run("default", func(t *testing.T) {
+		for _, s := range []string{fmt.Sprintf(`%v`, tok), fmt.Sprintf(`%s`, tok), tok.(fmt.Stringer).String()} {
+			m := decode(t, s)
+			require.Equal(t, `https://example.com`, m[jwt.IssuerKey])
+			require.Equal(t, `alice`, m[jwt.SubjectKey])
+			require.Contains(t, m, jwt.ExpirationKey)
+			require.Equal(t, `[REDACTED]`, m[`email`])
+			require.Equal(t, `[REDACTED]`, m[`refresh_token`])
+			require.NotContains(t, s, `tGzv3JOkF0XG5Qx2TlKWIA`)
+		}
+
+		// the original values are intact
+		v, ok := tok.Get(`email`)
+		require.True(t, ok, `tok.Get should succeed`)
+		require.Equal(t, `alice@example.com`, v)
+	})
+	t.Run("WithUnredactedClaims", func(t *testing.T) {
+		defer jwt.Settings(jwt.WithUnredactedClaims(jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.NotBeforeKey, jwt.IssuedAtKey, jwt.JwtIDKey))
+
+		jwt.Settings(jwt.WithUnredactedClaims(jwt.IssuerKey, `email`))
+		m := decode(t, fmt.Sprintf(`%v`, tok))
+		require.Equal(t, `https://example.com`, m[jwt.IssuerKey])
+		require.Equal(t, `alice@example.com`, m[`email`])
+		require.Equal(t, `[REDACTED]`, m[jwt.SubjectKey])
+		require.Equal(t, `[REDACTED]`, m[`refresh_token`])
+
+		jwt.Settings(jwt.WithUnredactedClaims())
+		for name, v := range jwt.Redact(tok) {
+			require.Equal(t, `[REDACTED]`, v, `%q should be redacted`, name)
+		}
+	})
+}
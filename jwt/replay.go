@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReplayStore records the `jti` values of tokens that have already been
+// accepted, so that they can be rejected when they are presented again.
+// It is used by `jwt.WithReplayDetection()`.
+//
+// Implementations must be safe for concurrent use. `jwt.NewMemoryReplayStore()`
+// provides an in-memory implementation. Implementations backed by shared
+// storage (e.g. Redis) are required when tokens are validated by multiple
+// processes: they should record `jti` atomically (e.g. using SET with NX
+// and an expiration) and report whether it was already present.
+type ReplayStore interface {
+	// Seen records `jti` as used until `exp`, and reports whether it had
+	// already been recorded. Entries may be discarded once `exp` has passed,
+	// as tokens with the same `jti` would be rejected as expired anyway.
+	//
+	// `exp` is the zero time.Time if the token does not expire. In this case
+	// implementations should remember `jti` for as long as reasonably possible.
+	Seen(ctx context.Context, jti string, exp time.Time) (bool, error)
+}
+
+// MemoryReplayStore is an in-memory `jwt.ReplayStore`. Entries are kept
+// until the expiration of the token they were recorded for, and are
+// periodically removed afterwards. Since the store is local to the process,
+// it is only suitable when all tokens are validated by a single process.
+type MemoryReplayStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryReplayStore creates a new `jwt.MemoryReplayStore`. `ttl` is
+// the duration for which the `jti` of tokens that do not have an `exp`
+// claim are remembered.
+func NewMemoryReplayStore(ttl time.Duration) *MemoryReplayStore {
+	return &MemoryReplayStore{
+		ttl:     ttl,
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Seen implements `jwt.ReplayStore`. When called during validation, the
+// `jwt.Clock` associated with the validation context is used as the
+// current time.
+func (s *MemoryReplayStore) Seen(ctx context.Context, jti string, exp time.Time) (bool, error) {
+	now := time.Now()
+	if clock, ok := ctx.Value(identValidationCtxClock{}).(Clock); ok && clock != nil {
+		now = clock.Now()
+	}
+
+	if exp.IsZero() {
+		exp = now.Add(s.ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !now.Before(s.nextSweep) {
+		for k, v := range s.entries {
+			if !now.Before(v) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(time.Minute)
+	}
+
+	if v, ok := s.entries[jti]; ok && now.Before(v) {
+		return true, nil
+	}
+	s.entries[jti] = exp
+	return false, nil
+}
+
+// Len returns the number of entries currently held in the store,
+// including those that have expired but have not been removed yet.
+func (s *MemoryReplayStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// checkReplay is executed after all other validators have succeeded,
+// so that tokens that are rejected for other reasons are not recorded
+func checkReplay(ctx context.Context, store ReplayStore, t Token, grace time.Duration) ValidationError {
+	jti := t.JwtID()
+	if jti == "" {
+		return &missingRequiredClaimError{claim: JwtIDKey}
+	}
+
+	// Tokens are accepted until exp + skew + grace, so the jti must be
+	// remembered at least as long
+	exp := t.Expiration()
+	if !exp.IsZero() {
+		exp = exp.Add(ValidationCtxSkew(ctx) + grace)
+	}
+
+	seen, err := store.Seen(ctx, jti, exp)
+	if err != nil {
+		return NewValidationError(fmt.Errorf(`failed to check "jti" for replay: %w`, err))
+	}
+	if seen {
+		return ErrTokenReplayed()
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the claims that are commonly used to convey roles and
+// permissions granted to the subject of a token
+const (
+	// RolesKey is the name of the claim containing an array of roles,
+	// as used by Azure AD and many other identity providers
+	RolesKey = "roles"
+	// PermissionsKey is the name of the claim containing an array of
+	// permissions, as used by Auth0 when RBAC is enabled
+	PermissionsKey = "permissions"
+	// RealmAccessKey is the name of the claim used by Keycloak to convey
+	// realm roles, in the form of `{"roles": [...]}`
+	RealmAccessKey = "realm_access"
+	// ResourceAccessKey is the name of the claim used by Keycloak to convey
+	// client roles, in the form of `{"<client>": {"roles": [...]}}`
+	ResourceAccessKey = "resource_access"
+)
+
+var errInsufficientRoles = NewValidationError(errors.New(`required roles are not granted`))
+
+// ErrInsufficientRoles returns the immutable error used when the token
+// does not grant the roles or permissions required by validators such as
+// `jwt.HasRoles()` and `jwt.HasPermissions()`.
+//
+// The return value should only be used for comparison using `errors.Is()`
+func ErrInsufficientRoles() ValidationError {
+	return errInsufficientRoles
+}
+
+// Grants is a set of roles or permissions granted to a token. Use
+// functions such as `jwt.RolesOf()` or `jwt.PermissionsOf()` to obtain
+// the grants of a token.
+type Grants map[string]struct{}
+
+// NewGrants creates a set of grants containing `names`.
+func NewGrants(names ...string) Grants {
+	g := make(Grants, len(names))
+	for _, name := range names {
+		g[name] = struct{}{}
+	}
+	return g
+}
+
+// Has returns true if `name` is in the set.
+func (g Grants) Has(name string) bool {
+	_, ok := g[name]
+	return ok
+}
+
+// HasAll returns true if all of `names` are in the set.
+func (g Grants) HasAll(names ...string) bool {
+	for _, name := range names {
+		if !g.Has(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny returns true if at least one of `names` is in the set.
+// It returns false if `names` is empty.
+func (g Grants) HasAny(names ...string) bool {
+	for _, name := range names {
+		if g.Has(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the grants in the set, sorted in lexicographic order.
+func (g Grants) List() []string {
+	list := make([]string, 0, len(g))
+	for name := range g {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// RolesOf returns the roles granted to the token `t` via the `roles`
+// claim. The value of the claim must be an array of strings. An empty set
+// is returned if the claim is not present.
+func RolesOf(t Token) (Grants, error) {
+	return grantsOf(t, RolesKey)
+}
+
+// PermissionsOf returns the permissions granted to the token `t` via
+// the `permissions` claim. The value of the claim must be an array of
+// strings. An empty set is returned if the claim is not present.
+func PermissionsOf(t Token) (Grants, error) {
+	return grantsOf(t, PermissionsKey)
+}
+
+// RealmRolesOf returns the Keycloak realm roles granted to the token `t`,
+// taken from `realm_access.roles`. An empty set is returned if the claim
+// is not present.
+func RealmRolesOf(t Token) (Grants, error) {
+	v, ok := t.Get(RealmAccessKey)
+	if !ok {
+		return make(Grants), nil
+	}
+	g, err := nestedRoles(v)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid value for %q claim: %w`, RealmAccessKey, err)
+	}
+	return g, nil
+}
+
+// ResourceRolesOf returns the Keycloak client roles granted to the token
+// `t` for the client `resource`, taken from `resource_access.<resource>.roles`.
+// An empty set is returned if the claim or the client is not present.
+func ResourceRolesOf(t Token, resource string) (Grants, error) {
+	v, ok := t.Get(ResourceAccessKey)
+	if !ok {
+		return make(Grants), nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`invalid value for %q claim: expected an object, got %T`, ResourceAccessKey, v)
+	}
+	access, ok := m[resource]
+	if !ok {
+		return make(Grants), nil
+	}
+	g, err := nestedRoles(access)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid value for %q claim (client %q): %w`, ResourceAccessKey, resource, err)
+	}
+	return g, nil
+}
+
+func grantsOf(t Token, name string) (Grants, error) {
+	v, ok := t.Get(name)
+	if !ok {
+		return make(Grants), nil
+	}
+	g, err := grantsFromValue(v)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid value for %q claim: %w`, name, err)
+	}
+	return g, nil
+}
+
+// nestedRoles extracts the roles from an object of the form `{"roles": [...]}`
+func nestedRoles(v interface{}) (Grants, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`expected an object, got %T`, v)
+	}
+	roles, ok := m[RolesKey]
+	if !ok {
+		return make(Grants), nil
+	}
+	return grantsFromValue(roles)
+}
+
+func grantsFromValue(v interface{}) (Grants, error) {
+	switch v := v.(type) {
+	case []string:
+		return NewGrants(v...), nil
+	case []interface{}:
+		g := make(Grants, len(v))
+		for _, e := range v {
+			name, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf(`expected an array of strings, got %T element`, e)
+			}
+			g[name] = struct{}{}
+		}
+		return g, nil
+	default:
+		return nil, fmt.Errorf(`expected an array of strings, got %T`, v)
+	}
+}
+
+// HasRoles returns a Validator that checks that all of `roles` are
+// granted to the token via the `roles` claim. If any of them are missing,
+// the returned error matches `jwt.ErrInsufficientRoles()`.
+func HasRoles(roles ...string) Validator {
+	return &grantsValidator{extract: RolesOf, names: roles}
+}
+
+// HasAnyRole returns a Validator that checks that at least one of `roles`
+// is granted to the token via the `roles` claim. If none of them are
+// granted, the returned error matches `jwt.ErrInsufficientRoles()`.
+func HasAnyRole(roles ...string) Validator {
+	return &grantsValidator{extract: RolesOf, names: roles, anyOf: true}
+}
+
+// HasPermissions returns a Validator that checks that all of `permissions`
+// are granted to the token via the `permissions` claim. If any of them are
+// missing, the returned error matches `jwt.ErrInsufficientRoles()`.
+func HasPermissions(permissions ...string) Validator {
+	return &grantsValidator{extract: PermissionsOf, names: permissions}
+}
+
+// HasRealmRoles returns a Validator that checks that all of `roles` are
+// granted to the token as Keycloak realm roles (see `jwt.RealmRolesOf()`).
+// If any of them are missing, the returned error matches
+// `jwt.ErrInsufficientRoles()`.
+func HasRealmRoles(roles ...string) Validator {
+	return &grantsValidator{extract: RealmRolesOf, names: roles}
+}
+
+// HasResourceRoles returns a Validator that checks that all of `roles`
+// are granted to the token as Keycloak client roles for the client
+// `resource` (see `jwt.ResourceRolesOf()`). If any of them are missing,
+// the returned error matches `jwt.ErrInsufficientRoles()`.
+func HasResourceRoles(resource string, roles ...string) Validator {
+	return &grantsValidator{
+		extract: func(t Token) (Grants, error) {
+			return ResourceRolesOf(t, resource)
+		},
+		names: roles,
+	}
+}
+
+type grantsValidator struct {
+	extract func(Token) (Grants, error)
+	names   []string
+	anyOf   bool
+}
+
+func (gv *grantsValidator) Validate(_ context.Context, t Token) ValidationError {
+	granted, err := gv.extract(t)
+	if err != nil {
+		return NewValidationError(err)
+	}
+
+	if gv.anyOf {
+		if !granted.HasAny(gv.names...) {
+			return NewValidationError(fmt.Errorf(`%w: none of %s`, errInsufficientRoles, strings.Join(gv.names, ` `)))
+		}
+		return nil
+	}
+
+	var missing []string
+	for _, name := range gv.names {
+		if !granted.Has(name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return NewValidationError(fmt.Errorf(`%w: %s`, errInsufficientRoles, strings.Join(missing, ` `)))
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+const keycloakClaims = `{
+  "sub": "alice",
+  "realm_access": {"roles": ["offline_access", "user"]},
+  "resource_access": {
+    "account": {"roles": ["manage-account", "view-profile"]},
+    "api": {"roles": ["reader"]}
+  },
+  "roles": ["editor", "viewer"],
+  "permissions": ["read:articles", "write:articles"]
+}`
+
+func TestRoles(t *testing.T) {
+	t.Parallel()
+
+	tok, err := jwt.ParseInsecure([]byte(keycloakClaims))
+	require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+
+	t.Run("accessors", func(t *testing.T) {
+		t.Parallel()
+		roles, err := jwt.RolesOf(tok)
+		require.NoError(t, err, `jwt.RolesOf should succeed`)
+		require.Equal(t, []string{`editor`, `viewer`}, roles.List())
+
+		perms, err := jwt.PermissionsOf(tok)
+		require.NoError(t, err, `jwt.PermissionsOf should succeed`)
+		require.True(t, perms.HasAll(`read:articles`, `write:articles`))
+
+		realm, err := jwt.RealmRolesOf(tok)
+		require.NoError(t, err, `jwt.RealmRolesOf should succeed`)
+		require.Equal(t, []string{`offline_access`, `user`}, realm.List())
+
+		account, err := jwt.ResourceRolesOf(tok, `account`)
+		require.NoError(t, err, `jwt.ResourceRolesOf should succeed`)
+		require.Equal(t, []string{`manage-account`, `view-profile`}, account.List())
+
+		missing, err := jwt.ResourceRolesOf(tok, `unknown`)
+		require.NoError(t, err, `jwt.ResourceRolesOf should succeed for unknown clients`)
+		require.Empty(t, missing)
+
+		empty, err := jwt.RolesOf(jwt.New())
+		require.NoError(t, err, `jwt.RolesOf should succeed without the claim`)
+		require.Empty(t, empty)
+	})
+	t.Run("Grants", func(t *testing.T) {
+		t.Parallel()
+		g := jwt.NewGrants(`a`, `b`)
+		require.True(t, g.Has(`a`))
+		require.True(t, g.HasAll(`a`, `b`))
+		require.False(t, g.HasAll(`a`, `c`))
+		require.True(t, g.HasAny(`c`, `b`))
+		require.False(t, g.HasAny(`c`))
+		require.False(t, g.HasAny(), `empty list should never be satisfied`)
+	})
+	t.Run("invalid values", func(t *testing.T) {
+		t.Parallel()
+		bad := jwt.New()
+		require.NoError(t, bad.Set(jwt.RolesKey, `admin`), `bad.Set should succeed`)
+		require.NoError(t, bad.Set(jwt.RealmAccessKey, []interface{}{`admin`}), `bad.Set should succeed`)
+		require.NoError(t, bad.Set(jwt.ResourceAccessKey, map[string]interface{}{`api`: map[string]interface{}{`roles`: []interface{}{1}}}), `bad.Set should succeed`)
+
+		_, err := jwt.RolesOf(bad)
+		require.Error(t, err, `jwt.RolesOf should fail`)
+		_, err = jwt.RealmRolesOf(bad)
+		require.Error(t, err, `jwt.RealmRolesOf should fail`)
+		_, err = jwt.ResourceRolesOf(bad, `api`)
+		require.Error(t, err, `jwt.ResourceRolesOf should fail`)
+
+		err = jwt.Validate(bad, jwt.WithValidator(jwt.HasRoles(`admin`)))
+		require.Error(t, err, `jwt.Validate should fail`)
+		require.False(t, errors.Is(err, jwt.ErrInsufficientRoles()), `error should not match jwt.ErrInsufficientRoles`)
+	})
+	t.Run("validators", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Name      string
+			Validator jwt.Validator
+			Error     bool
+		}{
+			{Name: `HasRoles`, Validator: jwt.HasRoles(`editor`, `viewer`)},
+			{Name: `HasRoles (missing)`, Validator: jwt.HasRoles(`editor`, `admin`), Error: true},
+			{Name: `HasAnyRole`, Validator: jwt.HasAnyRole(`admin`, `viewer`)},
+			{Name: `HasAnyRole (missing)`, Validator: jwt.HasAnyRole(`admin`), Error: true},
+			{Name: `HasPermissions`, Validator: jwt.HasPermissions(`read:articles`)},
+			{Name: `HasPermissions (missing)`, Validator: jwt.HasPermissions(`delete:articles`), Error: true},
+			{Name: `HasRealmRoles`, Validator: jwt.HasRealmRoles(`user`)},
+			{Name: `HasRealmRoles (missing)`, Validator: jwt.HasRealmRoles(`admin`), Error: true},
+			{Name: `HasResourceRoles`, Validator: jwt.HasResourceRoles(`api`, `reader`)},
+			{Name: `HasResourceRoles (wrong client)`, Validator: jwt.HasResourceRoles(`account`, `reader`), Error: true},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Name, func(t *testing.T) {
+				t.Parallel()
+				err := jwt.Validate(tok, jwt.WithValidator(tc.Validator))
+				if !tc.Error {
+					require.NoError(t, err, `jwt.Validate should succeed`)
+					return
+				}
+				require.Error(t, err, `jwt.Validate should fail`)
+				require.True(t, errors.Is(err, jwt.ErrInsufficientRoles()), `error should match jwt.ErrInsufficientRoles`)
+				require.True(t, jwt.IsValidationError(err), `error should be a validation error`)
+			})
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the claims that contain the OAuth 2.0 scopes granted to a token
+const (
+	// ScopeKey is the name of the claim defined in RFC 8693 Section 4.2,
+	// whose value is a space-delimited list of scopes
+	ScopeKey = "scope"
+	// ScpKey is the name of the claim used by some authorization servers
+	// instead of `scope`, whose value is usually an array of scopes
+	ScpKey = "scp"
+)
+
+var errInsufficientScope = NewValidationError(errors.New(`required scopes are not granted`))
+
+// ErrInsufficientScope returns the immutable error used when the token
+// does not grant the scopes specified via `jwt.WithRequiredScopes()`.
+//
+// The return value should only be used for comparison using `errors.Is()`
+func ErrInsufficientScope() ValidationError {
+	return errInsufficientScope
+}
+
+// Scopes is the set of OAuth 2.0 scopes granted to a token. Use
+// `jwt.ScopesOf()` to obtain the scopes of a token.
+type Scopes map[string]struct{}
+
+// NewScopes creates a set of scopes containing `scopes`.
+func NewScopes(scopes ...string) Scopes {
+	s := make(Scopes, len(scopes))
+	for _, scope := range scopes {
+		s[scope] = struct{}{}
+	}
+	return s
+}
+
+// ParseScopes parses the space-delimited list of scopes in `v`, as used
+// by the `scope` claim and the `scope` parameter of OAuth 2.0.
+func ParseScopes(v string) Scopes {
+	return NewScopes(strings.Fields(v)...)
+}
+
+// ScopesOf returns the scopes granted to the token `t`, taken from both
+// the `scope` and `scp` claims. Each claim may either be a space-delimited
+// string, or an array of strings. An empty set is returned if neither of
+// the claims is present, and an error is returned if they are of any
+// other type.
+func ScopesOf(t Token) (Scopes, error) {
+	s := make(Scopes)
+	for _, name := range []string{ScopeKey, ScpKey} {
+		v, ok := t.Get(name)
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			for _, scope := range strings.Fields(v) {
+				s[scope] = struct{}{}
+			}
+		case []string:
+			for _, scope := range v {
+				s[scope] = struct{}{}
+			}
+		case []interface{}:
+			for _, e := range v {
+				scope, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf(`invalid value for %q claim: expected an array of strings, got %T element`, name, e)
+				}
+				s[scope] = struct{}{}
+			}
+		default:
+			return nil, fmt.Errorf(`invalid value for %q claim: expected a string or an array of strings, got %T`, name, v)
+		}
+	}
+	return s, nil
+}
+
+// HasScope returns true if `scope` is in the set.
+func (s Scopes) HasScope(scope string) bool {
+	_, ok := s[scope]
+	return ok
+}
+
+// HasScopes returns true if all of `scopes` are in the set.
+func (s Scopes) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !s.HasScope(scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// List returns the scopes in the set, sorted in lexicographic order.
+func (s Scopes) List() []string {
+	list := make([]string, 0, len(s))
+	for scope := range s {
+		list = append(list, scope)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// String returns the space-delimited list of the scopes in the set,
+// which can be used as the value of the `scope` claim.
+func (s Scopes) String() string {
+	return strings.Join(s.List(), ` `)
+}
+
+// HasRequiredScopes returns a Validator that checks that all of `scopes`
+// are granted to the token, as determined by `jwt.ScopesOf()`. If any of
+// them are missing, the returned error matches `jwt.ErrInsufficientScope()`.
+func HasRequiredScopes(scopes ...string) Validator {
+	return ValidatorFunc(func(_ context.Context, t Token) ValidationError {
+		granted, err := ScopesOf(t)
+		if err != nil {
+			return NewValidationError(err)
+		}
+		var missing []string
+		for _, scope := range scopes {
+			if !granted.HasScope(scope) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) > 0 {
+			return NewValidationError(fmt.Errorf(`%w: %s`, errInsufficientScope, strings.Join(missing, ` `)))
+		}
+		return nil
+	})
+}
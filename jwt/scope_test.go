@@ -0,0 +1,77 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ScopesOf", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			Name     string
+			Claims   map[string]interface{}
+			Expected []string
+			Error    bool
+		}{
+			{Name: `no claims`, Expected: []string{}},
+			{Name: `scope string`, Claims: map[string]interface{}{jwt.ScopeKey: `read  write`}, Expected: []string{`read`, `write`}},
+			{Name: `scp array`, Claims: map[string]interface{}{jwt.ScpKey: []interface{}{`read`, `write`}}, Expected: []string{`read`, `write`}},
+			{Name: `scp string`, Claims: map[string]interface{}{jwt.ScpKey: `read`}, Expected: []string{`read`}},
+			{Name: `both claims`, Claims: map[string]interface{}{jwt.ScopeKey: `read`, jwt.ScpKey: []string{`write`}}, Expected: []string{`read`, `write`}},
+			{Name: `invalid type`, Claims: map[string]interface{}{jwt.ScopeKey: 1}, Error: true},
+			{Name: `invalid element`, Claims: map[string]interface{}{jwt.ScpKey: []interface{}{`read`, 1}}, Error: true},
+		}
+		for _, tc := range testcases {
+			tc := tc
+			t.Run(tc.Name, func(t *testing.T) {
+				t.Parallel()
+				tok := jwt.New()
+				for k, v := range tc.Claims {
+					require.NoError(t, tok.Set(k, v), `tok.Set should succeed`)
+				}
+				scopes, err := jwt.ScopesOf(tok)
+				if tc.Error {
+					require.Error(t, err, `jwt.ScopesOf should fail`)
+					return
+				}
+				require.NoError(t, err, `jwt.ScopesOf should succeed`)
+				require.Equal(t, tc.Expected, scopes.List())
+			})
+		}
+	})
+	t.Run("Scopes", func(t *testing.T) {
+		t.Parallel()
+		scopes := jwt.ParseScopes(` write read `)
+		require.True(t, scopes.HasScope(`read`))
+		require.False(t, scopes.HasScope(`admin`))
+		require.True(t, scopes.HasScopes(`read`, `write`))
+		require.False(t, scopes.HasScopes(`read`, `admin`))
+		require.True(t, scopes.HasScopes(), `empty list should always be satisfied`)
+		require.Equal(t, `read write`, scopes.String())
+		require.Equal(t, scopes, jwt.NewScopes(`read`, `write`))
+	})
+	t.Run("WithRequiredScopes", func(t *testing.T) {
+		t.Parallel()
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.ScopeKey, `read write`), `tok.Set should succeed`)
+
+		require.NoError(t, jwt.Validate(tok, jwt.WithRequiredScopes(`read`, `write`)), `jwt.Validate should succeed`)
+
+		err := jwt.Validate(tok, jwt.WithRequiredScopes(`read`, `admin`))
+		require.Error(t, err, `jwt.Validate should fail`)
+		require.True(t, errors.Is(err, jwt.ErrInsufficientScope()), `error should match jwt.ErrInsufficientScope`)
+		require.True(t, jwt.IsValidationError(err), `error should be a validation error`)
+		require.Contains(t, err.Error(), `admin`)
+
+		require.NoError(t, tok.Set(jwt.ScopeKey, 1), `tok.Set should succeed`)
+		err = jwt.Validate(tok, jwt.WithRequiredScopes(`read`))
+		require.Error(t, err, `jwt.Validate should fail for invalid claims`)
+		require.False(t, errors.Is(err, jwt.ErrInsufficientScope()), `error should not match jwt.ErrInsufficientScope`)
+	})
+}
@@ -0,0 +1,334 @@
+// Package sdjwt implements Selective Disclosure for JWTs (SD-JWT), as
+// described in draft-ietf-oauth-selective-disclosure-jwt.
+//
+// An SD-JWT consists of a JWT signed by the issuer, in which some of the
+// claims have been replaced by their digests, followed by the disclosures
+// that contain the actual claims, separated by "~":
+//
+//	<Issuer-signed JWT>~<Disclosure 1>~<Disclosure 2>~...~<Disclosure N>~<optional KB-JWT>
+//
+// Issuers create SD-JWTs using `sdjwt.Issue()`. Holders parse them using
+// `sdjwt.Parse()`, select the disclosures that they want to reveal, and
+// optionally add a Key Binding JWT using `(*sdjwt.SDJWT).Present()`.
+// Verifiers use `sdjwt.Verify()` to verify the SD-JWT and to reconstruct
+// the token containing the disclosed claims.
+package sdjwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Names of the claims and values defined by SD-JWT
+const (
+	DigestsKey         = `_sd`
+	DigestAlgorithmKey = `_sd_alg`
+	ArrayElementKey    = `...`
+	SDHashKey          = `sd_hash`
+	NonceKey           = `nonce`
+	ConfirmationKey    = `cnf`
+	ConfirmationJWKKey = `jwk`
+	DigestAlgorithm    = `sha-256`
+	KeyBindingType     = `kb+jwt`
+)
+
+const (
+	separator           = '~'
+	saltSize            = 16
+	disclosureArraySize = 3
+)
+
+// Disclosure is a selectively disclosable claim.
+type Disclosure struct {
+	salt    string
+	name    string
+	value   interface{}
+	encoded string
+}
+
+// NewDisclosure creates a new Disclosure for the claim `name` with
+// the value `value`, using a random salt.
+func NewDisclosure(name string, value interface{}) (*Disclosure, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf(`failed to generate salt: %w`, err)
+	}
+
+	d := &Disclosure{
+		salt:  base64.EncodeToString(salt),
+		name:  name,
+		value: value,
+	}
+	buf, err := json.Marshal([]interface{}{d.salt, d.name, d.value})
+	if err != nil {
+		return nil, fmt.Errorf(`failed to encode disclosure for %q: %w`, name, err)
+	}
+	d.encoded = base64.EncodeToString(buf)
+	return d, nil
+}
+
+// ParseDisclosure parses an encoded Disclosure. Both disclosures of
+// object properties and of array elements are supported. For the latter,
+// `Name()` returns the empty string.
+func ParseDisclosure(src string) (*Disclosure, error) {
+	buf, err := base64.DecodeString(src)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to decode disclosure: %w`, err)
+	}
+
+	var list []interface{}
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, fmt.Errorf(`failed to parse disclosure: %w`, err)
+	}
+
+	d := &Disclosure{encoded: src}
+	switch len(list) {
+	case disclosureArraySize:
+		name, ok := list[1].(string)
+		if !ok {
+			return nil, fmt.Errorf(`invalid disclosure: claim name must be a string`)
+		}
+		switch name {
+		case DigestsKey, ArrayElementKey:
+			return nil, fmt.Errorf(`invalid disclosure: claim name must not be %q`, name)
+		}
+		d.name = name
+		d.value = list[2]
+	case disclosureArraySize - 1:
+		d.value = list[1]
+	default:
+		return nil, fmt.Errorf(`invalid disclosure: expected an array of 2 or 3 elements, got %d`, len(list))
+	}
+
+	salt, ok := list[0].(string)
+	if !ok {
+		return nil, fmt.Errorf(`invalid disclosure: salt must be a string`)
+	}
+	d.salt = salt
+	return d, nil
+}
+
+// Name returns the name of the claim. It is empty for array elements.
+func (d *Disclosure) Name() string {
+	return d.name
+}
+
+// Value returns the value of the claim.
+func (d *Disclosure) Value() interface{} {
+	return d.value
+}
+
+// Salt returns the salt.
+func (d *Disclosure) Salt() string {
+	return d.salt
+}
+
+// Digest returns the base64url encoded SHA-256 digest of the Disclosure,
+// which is included in the `_sd` claim of the issuer-signed JWT.
+func (d *Disclosure) Digest() string {
+	return digest([]byte(d.encoded))
+}
+
+// String returns the encoded Disclosure.
+func (d *Disclosure) String() string {
+	return d.encoded
+}
+
+func digest(src []byte) string {
+	h := sha256.Sum256(src)
+	return base64.EncodeToString(h[:])
+}
+
+// Issue creates an SD-JWT from `t`, in which the claims in `names` are
+// selectively disclosable. The rest of the claims are always disclosed.
+// The token is signed using `jwt.Sign()` with `options`.
+//
+// To allow holders to add a Key Binding JWT, set the `cnf` claim of `t`
+// using `sdjwt.Confirmation()` before calling this function.
+//
+// The returned SD-JWT contains all of the disclosures, and does not
+// have a Key Binding JWT.
+func Issue(t jwt.Token, names []string, options ...jwt.SignOption) (*SDJWT, error) {
+	tok, err := t.Clone()
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Issue: failed to clone token: %w`, err)
+	}
+
+	if _, ok := tok.Get(DigestsKey); ok {
+		return nil, fmt.Errorf(`sdjwt.Issue: token must not contain %q`, DigestsKey)
+	}
+
+	disclosures := make([]*Disclosure, 0, len(names))
+	digests := make([]string, 0, len(names))
+	for _, name := range names {
+		v, ok := tok.Get(name)
+		if !ok {
+			return nil, fmt.Errorf(`sdjwt.Issue: claim %q does not exist`, name)
+		}
+		switch name {
+		case jwt.IssuerKey, jwt.ExpirationKey, jwt.NotBeforeKey, ConfirmationKey:
+			// These claims are needed to validate the SD-JWT itself
+			return nil, fmt.Errorf(`sdjwt.Issue: claim %q cannot be selectively disclosable`, name)
+		}
+
+		d, err := NewDisclosure(name, v)
+		if err != nil {
+			return nil, fmt.Errorf(`sdjwt.Issue: %w`, err)
+		}
+		if err := tok.Remove(name); err != nil {
+			return nil, fmt.Errorf(`sdjwt.Issue: failed to remove claim %q: %w`, name, err)
+		}
+		disclosures = append(disclosures, d)
+		digests = append(digests, d.Digest())
+	}
+
+	// The order of the digests must not reveal the order of the claims
+	sort.Strings(digests)
+	if err := tok.Set(DigestsKey, digests); err != nil {
+		return nil, fmt.Errorf(`sdjwt.Issue: failed to set %q: %w`, DigestsKey, err)
+	}
+	if err := tok.Set(DigestAlgorithmKey, DigestAlgorithm); err != nil {
+		return nil, fmt.Errorf(`sdjwt.Issue: failed to set %q: %w`, DigestAlgorithmKey, err)
+	}
+
+	signed, err := jwt.Sign(tok, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Issue: %w`, err)
+	}
+	return &SDJWT{jwt: signed, disclosures: disclosures}, nil
+}
+
+// Confirmation returns the value of the `cnf` claim that binds an SD-JWT
+// to the holder's public key `key`, so that the holder can add a Key
+// Binding JWT when presenting it.
+func Confirmation(key interface{}) (map[string]interface{}, error) {
+	pubkey, err := jwk.PublicKeyOf(key)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to obtain public key: %w`, err)
+	}
+	return map[string]interface{}{ConfirmationJWKKey: pubkey}, nil
+}
+
+// SDJWT represents an SD-JWT, consisting of the issuer-signed JWT, the
+// disclosures, and optionally the Key Binding JWT.
+type SDJWT struct {
+	jwt         []byte
+	disclosures []*Disclosure
+	keyBinding  []byte
+}
+
+// Parse parses an SD-JWT in compact serialization. The signatures are not
+// verified. Use `sdjwt.Verify()` to verify SD-JWTs.
+func Parse(src []byte) (*SDJWT, error) {
+	src = bytes.TrimSpace(src)
+	parts := bytes.Split(src, []byte{separator})
+	if len(parts) < 2 {
+		return nil, fmt.Errorf(`sdjwt.Parse: invalid SD-JWT: separator %q not found`, separator)
+	}
+	if len(parts[0]) == 0 {
+		return nil, fmt.Errorf(`sdjwt.Parse: invalid SD-JWT: issuer-signed JWT is empty`)
+	}
+
+	s := &SDJWT{jwt: parts[0]}
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		d, err := ParseDisclosure(string(part))
+		if err != nil {
+			return nil, fmt.Errorf(`sdjwt.Parse: %w`, err)
+		}
+		s.disclosures = append(s.disclosures, d)
+	}
+	if len(parts[last]) > 0 {
+		s.keyBinding = parts[last]
+	}
+	return s, nil
+}
+
+// JWT returns the issuer-signed JWT.
+func (s *SDJWT) JWT() []byte {
+	return s.jwt
+}
+
+// Disclosures returns the disclosures.
+func (s *SDJWT) Disclosures() []*Disclosure {
+	return s.disclosures
+}
+
+// KeyBinding returns the Key Binding JWT, or nil if there is none.
+func (s *SDJWT) KeyBinding() []byte {
+	return s.keyBinding
+}
+
+// Select returns a new SD-JWT that only contains the disclosures for the
+// claims in `names`. The Key Binding JWT is not copied, as it would be
+// invalidated by the change.
+func (s *SDJWT) Select(names ...string) *SDJWT {
+	selected := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		selected[name] = struct{}{}
+	}
+
+	dst := &SDJWT{jwt: s.jwt}
+	for _, d := range s.disclosures {
+		if _, ok := selected[d.name]; ok && d.name != `` {
+			dst.disclosures = append(dst.disclosures, d)
+		}
+	}
+	return dst
+}
+
+// Serialize returns the SD-JWT in compact serialization.
+func (s *SDJWT) Serialize() []byte {
+	buf := s.serializeWithoutKeyBinding()
+	return append(buf, s.keyBinding...)
+}
+
+func (s *SDJWT) serializeWithoutKeyBinding() []byte {
+	var buf bytes.Buffer
+	buf.Write(s.jwt)
+	buf.WriteByte(separator)
+	for _, d := range s.disclosures {
+		buf.WriteString(d.encoded)
+		buf.WriteByte(separator)
+	}
+	return buf.Bytes()
+}
+
+// Present returns the SD-JWT with a Key Binding JWT, signed by the
+// holder's private key `key` using `alg`. `audience` is the identifier
+// of the verifier, and `nonce` is the value provided by the verifier
+// to ensure the freshness of the presentation.
+func (s *SDJWT) Present(alg jwa.SignatureAlgorithm, key interface{}, audience, nonce string) ([]byte, error) {
+	presented := s.serializeWithoutKeyBinding()
+
+	tok, err := jwt.NewBuilder().
+		IssuedAt(time.Now()).
+		Audience([]string{audience}).
+		Claim(NonceKey, nonce).
+		Claim(SDHashKey, digest(presented)).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Present: failed to build key binding JWT: %w`, err)
+	}
+
+	hdrs := jws.NewHeaders()
+	if err := hdrs.Set(jws.TypeKey, KeyBindingType); err != nil {
+		return nil, fmt.Errorf(`sdjwt.Present: failed to set %q header: %w`, jws.TypeKey, err)
+	}
+	kb, err := jwt.Sign(tok, jwt.WithKey(alg, key), jwt.WithJwsHeaders(hdrs))
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Present: failed to sign key binding JWT: %w`, err)
+	}
+	return append(presented, kb...), nil
+}
@@ -0,0 +1,149 @@
+package sdjwt_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/sdjwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDJWT(t *testing.T) {
+	issuerKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+	holderKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+
+	cnf, err := sdjwt.Confirmation(holderKey)
+	require.NoError(t, err, `sdjwt.Confirmation should succeed`)
+
+	tok, err := jwt.NewBuilder().
+		Issuer(`https://issuer.example.com`).
+		Subject(`alice`).
+		Expiration(time.Now().Add(time.Hour)).
+		Claim(`given_name`, `Alice`).
+		Claim(`family_name`, `Smith`).
+		Claim(`address`, map[string]interface{}{`country`: `JP`}).
+		Claim(sdjwt.ConfirmationKey, cnf).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	issued, err := sdjwt.Issue(tok, []string{`given_name`, `family_name`, `address`}, jwt.WithKey(jwa.ES256, issuerKey))
+	require.NoError(t, err, `sdjwt.Issue should succeed`)
+	require.Len(t, issued.Disclosures(), 3)
+
+	verifyKey := jwt.WithKey(jwa.ES256, &issuerKey.PublicKey)
+
+	t.Run("issued", func(t *testing.T) {
+		issuerSigned, err := jwt.Parse(issued.JWT(), verifyKey)
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		_, ok := issuerSigned.Get(`given_name`)
+		require.False(t, ok, `disclosable claims should not be in the issuer-signed JWT`)
+		_, ok = issuerSigned.Get(sdjwt.DigestsKey)
+		require.True(t, ok, `_sd should be in the issuer-signed JWT`)
+
+		parsed, err := sdjwt.Parse(issued.Serialize())
+		require.NoError(t, err, `sdjwt.Parse should succeed`)
+		require.Len(t, parsed.Disclosures(), 3)
+		require.Nil(t, parsed.KeyBinding())
+
+		verified, err := sdjwt.Verify(issued.Serialize(), verifyKey)
+		require.NoError(t, err, `sdjwt.Verify should succeed`)
+		require.Equal(t, `alice`, verified.Subject())
+		v, ok := verified.Get(`given_name`)
+		require.True(t, ok, `given_name should be disclosed`)
+		require.Equal(t, `Alice`, v)
+		v, ok = verified.Get(`address`)
+		require.True(t, ok, `address should be disclosed`)
+		require.Equal(t, map[string]interface{}{`country`: `JP`}, v)
+		_, ok = verified.Get(sdjwt.DigestsKey)
+		require.False(t, ok, `_sd should be removed`)
+		_, ok = verified.Get(sdjwt.DigestAlgorithmKey)
+		require.False(t, ok, `_sd_alg should be removed`)
+	})
+	t.Run("presentation", func(t *testing.T) {
+		parsed, err := sdjwt.Parse(issued.Serialize())
+		require.NoError(t, err, `sdjwt.Parse should succeed`)
+
+		presented, err := parsed.Select(`given_name`).Present(jwa.ES256, holderKey, `https://verifier.example.com`, `n0nce`)
+		require.NoError(t, err, `Present should succeed`)
+
+		verified, err := sdjwt.Verify(presented, verifyKey, sdjwt.WithKeyBinding(`https://verifier.example.com`, `n0nce`, time.Minute))
+		require.NoError(t, err, `sdjwt.Verify should succeed`)
+		_, ok := verified.Get(`given_name`)
+		require.True(t, ok, `given_name should be disclosed`)
+		_, ok = verified.Get(`family_name`)
+		require.False(t, ok, `family_name should not be disclosed`)
+
+		_, err = sdjwt.Verify(presented, verifyKey, sdjwt.WithKeyBinding(`https://verifier.example.com`, `other`, time.Minute))
+		require.Error(t, err, `sdjwt.Verify should fail for a different nonce`)
+		_, err = sdjwt.Verify(presented, verifyKey, sdjwt.WithKeyBinding(`https://other.example.com`, `n0nce`, time.Minute))
+		require.Error(t, err, `sdjwt.Verify should fail for a different audience`)
+
+		// Adding a disclosure after the fact invalidates the key binding JWT
+		family := issued.Disclosures()[1].String()
+		tampered := bytes.Replace(presented, []byte(`~`), []byte(`~`+family+`~`), 1)
+		_, err = sdjwt.Verify(tampered, verifyKey)
+		require.Error(t, err, `sdjwt.Verify should fail for tampered presentations`)
+
+		_, err = sdjwt.Verify(parsed.Select(`given_name`).Serialize(), verifyKey, sdjwt.WithKeyBinding(`https://verifier.example.com`, `n0nce`, time.Minute))
+		require.Error(t, err, `sdjwt.Verify should fail without key binding JWT`)
+
+		otherKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+		require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+		presented, err = parsed.Present(jwa.ES256, otherKey, `https://verifier.example.com`, `n0nce`)
+		require.NoError(t, err, `Present should succeed`)
+		_, err = sdjwt.Verify(presented, verifyKey)
+		require.Error(t, err, `sdjwt.Verify should fail for key binding JWTs signed by other keys`)
+	})
+	t.Run("invalid disclosures", func(t *testing.T) {
+		unrelated, err := sdjwt.NewDisclosure(`admin`, true)
+		require.NoError(t, err, `sdjwt.NewDisclosure should succeed`)
+
+		src := append(issued.Serialize(), []byte(unrelated.String()+`~`)...)
+		_, err = sdjwt.Verify(src, verifyKey)
+		require.Error(t, err, `sdjwt.Verify should fail for unreferenced disclosures`)
+
+		d := issued.Disclosures()[0].String()
+		src = append(issued.Serialize(), []byte(d+`~`)...)
+		_, err = sdjwt.Verify(src, verifyKey)
+		require.Error(t, err, `sdjwt.Verify should fail for duplicate disclosures`)
+	})
+	t.Run("array elements", func(t *testing.T) {
+		d1, err := sdjwt.ParseDisclosure(`WyJsa2x4RjVqTVlsR1RQVW92TU5JdkNBIiwgIkRFIl0`)
+		require.NoError(t, err, `sdjwt.ParseDisclosure should succeed`)
+		require.Equal(t, ``, d1.Name())
+		require.Equal(t, `DE`, d1.Value())
+
+		d2, err := sdjwt.ParseDisclosure(`WyJuUHVvUW5rUkZxM0JJZUFtN0FuWEZBIiwgIkZSIl0`)
+		require.NoError(t, err, `sdjwt.ParseDisclosure should succeed`)
+
+		tok, err := jwt.NewBuilder().
+			Subject(`alice`).
+			Claim(`nationalities`, []interface{}{
+				map[string]interface{}{`...`: d1.Digest()},
+				map[string]interface{}{`...`: d2.Digest()},
+			}).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.ES256, issuerKey))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		src := append(signed, []byte(`~`+d1.String()+`~`)...)
+		verified, err := sdjwt.Verify(src, verifyKey)
+		require.NoError(t, err, `sdjwt.Verify should succeed`)
+		v, ok := verified.Get(`nationalities`)
+		require.True(t, ok, `nationalities should exist`)
+		require.Equal(t, []interface{}{`DE`}, v)
+	})
+	t.Run("non-disclosable claims", func(t *testing.T) {
+		_, err := sdjwt.Issue(tok, []string{jwt.ExpirationKey}, jwt.WithKey(jwa.ES256, issuerKey))
+		require.Error(t, err, `exp should not be disclosable`)
+		_, err = sdjwt.Issue(tok, []string{`nonexistent`}, jwt.WithKey(jwa.ES256, issuerKey))
+		require.Error(t, err, `nonexistent claims should be rejected`)
+	})
+}
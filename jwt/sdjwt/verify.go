@@ -0,0 +1,315 @@
+package sdjwt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `sdjwt.Verify()`.
+// Options defined in the `jwt` package that implement `jwt.ParseOption`
+// are also accepted.
+type Option = option.Interface
+
+type identKeyBinding struct{}
+
+type keyBinding struct {
+	audience string
+	nonce    string
+	maxAge   time.Duration
+}
+
+// WithKeyBinding specifies that the SD-JWT must be presented with a
+// Key Binding JWT, whose `aud` and `nonce` claims must match `audience`
+// and `nonce`, and which must have been created within `maxAge`.
+func WithKeyBinding(audience, nonce string, maxAge time.Duration) Option {
+	return option.New(identKeyBinding{}, keyBinding{audience: audience, nonce: nonce, maxAge: maxAge})
+}
+
+// Verify verifies the SD-JWT in `src`, and returns a token that contains
+// the claims in the issuer-signed JWT, with the digests replaced by the
+// claims in the disclosures.
+//
+// The source of the keys used to verify the issuer-signed JWT must be
+// specified in `options` (e.g. `jwt.WithKeySet()`), and the reconstructed
+// token is validated using the `jwt.ValidateOption`s in `options`.
+// `jwt.WithVerify()` and `jwt.WithValidate()` may not be specified.
+//
+// An error is returned if any of the disclosures is not referenced by
+// the issuer-signed JWT, or if a digest is referenced more than once.
+//
+// If the SD-JWT is presented with a Key Binding JWT, it is verified using
+// the key in the `cnf` claim. Use `sdjwt.WithKeyBinding()` to require
+// a Key Binding JWT, and to validate its `aud` and `nonce` claims.
+func Verify(src []byte, options ...Option) (jwt.Token, error) {
+	var kb *keyBinding
+	var parseOptions []jwt.ParseOption
+	var validateOptions []jwt.ValidateOption
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, fmt.Errorf(`sdjwt.Verify: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+		switch o.Ident() {
+		case identKeyBinding{}:
+			//nolint:forcetypeassert
+			v := o.Value().(keyBinding)
+			kb = &v
+		default:
+			if vo, ok := o.(jwt.ValidateOption); ok {
+				validateOptions = append(validateOptions, vo)
+				continue
+			}
+			po, ok := o.(jwt.ParseOption)
+			if !ok {
+				return nil, fmt.Errorf(`sdjwt.Verify: invalid option %T`, o)
+			}
+			parseOptions = append(parseOptions, po)
+		}
+	}
+
+	s, err := Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: %w`, err)
+	}
+
+	// Verify the signature of the issuer-signed JWT. The claims are
+	// validated after the disclosures have been applied
+	parseOptions = append(parseOptions, jwt.WithValidate(false))
+	if _, err := jwt.Parse(s.jwt, parseOptions...); err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: failed to verify issuer-signed JWT: %w`, err)
+	}
+
+	msg, err := jws.Parse(s.jwt)
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: failed to parse issuer-signed JWT: %w`, err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(msg.Payload(), &claims); err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: failed to parse claims: %w`, err)
+	}
+
+	if v, ok := claims[DigestAlgorithmKey]; ok && v != DigestAlgorithm {
+		return nil, fmt.Errorf(`sdjwt.Verify: unsupported digest algorithm %v`, v)
+	}
+	delete(claims, DigestAlgorithmKey)
+
+	r := reconstructor{
+		disclosures: make(map[string]*Disclosure, len(s.disclosures)),
+		seen:        make(map[string]struct{}),
+	}
+	for _, d := range s.disclosures {
+		dgst := d.Digest()
+		if _, ok := r.disclosures[dgst]; ok {
+			return nil, fmt.Errorf(`sdjwt.Verify: duplicate disclosure`)
+		}
+		r.disclosures[dgst] = d
+	}
+
+	reconstructed, err := r.object(claims)
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: %w`, err)
+	}
+	for dgst := range r.disclosures {
+		if _, ok := r.seen[dgst]; !ok {
+			return nil, fmt.Errorf(`sdjwt.Verify: some disclosures are not referenced by the issuer-signed JWT`)
+		}
+	}
+
+	buf, err := json.Marshal(reconstructed)
+	if err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: failed to encode claims: %w`, err)
+	}
+	tok := jwt.New()
+	if err := json.Unmarshal(buf, tok); err != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: failed to decode claims: %w`, err)
+	}
+
+	if s.keyBinding != nil {
+		if err := verifyKeyBinding(s, tok, kb); err != nil {
+			return nil, fmt.Errorf(`sdjwt.Verify: %w`, err)
+		}
+	} else if kb != nil {
+		return nil, fmt.Errorf(`sdjwt.Verify: key binding JWT is required`)
+	}
+
+	if err := jwt.Validate(tok, validateOptions...); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+type reconstructor struct {
+	disclosures map[string]*Disclosure
+	seen        map[string]struct{}
+}
+
+// use marks the disclosure for digest `dgst` as used, and returns it.
+// nil is returned if there is no such disclosure (e.g. it is a decoy
+// digest, or it was not disclosed by the holder)
+func (r *reconstructor) use(dgst string) (*Disclosure, error) {
+	if _, ok := r.seen[dgst]; ok {
+		return nil, fmt.Errorf(`digest %q is referenced more than once`, dgst)
+	}
+	r.seen[dgst] = struct{}{}
+	return r.disclosures[dgst], nil
+}
+
+func (r *reconstructor) value(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return r.object(v)
+	case []interface{}:
+		return r.array(v)
+	default:
+		return v, nil
+	}
+}
+
+func (r *reconstructor) object(src map[string]interface{}) (map[string]interface{}, error) {
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		if k == DigestsKey {
+			continue
+		}
+		processed, err := r.value(v)
+		if err != nil {
+			return nil, err
+		}
+		dst[k] = processed
+	}
+
+	raw, ok := src[DigestsKey]
+	if !ok {
+		return dst, nil
+	}
+	digests, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`%q must be an array`, DigestsKey)
+	}
+	for _, rawdgst := range digests {
+		dgst, ok := rawdgst.(string)
+		if !ok {
+			return nil, fmt.Errorf(`%q must be an array of strings`, DigestsKey)
+		}
+		d, err := r.use(dgst)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		if d.name == `` {
+			return nil, fmt.Errorf(`disclosure for an array element is referenced from %q`, DigestsKey)
+		}
+		if _, ok := dst[d.name]; ok {
+			return nil, fmt.Errorf(`disclosed claim %q already exists`, d.name)
+		}
+		processed, err := r.value(d.value)
+		if err != nil {
+			return nil, err
+		}
+		dst[d.name] = processed
+	}
+	return dst, nil
+}
+
+func (r *reconstructor) array(src []interface{}) ([]interface{}, error) {
+	dst := make([]interface{}, 0, len(src))
+	for _, elem := range src {
+		if m, ok := elem.(map[string]interface{}); ok && len(m) == 1 {
+			if rawdgst, ok := m[ArrayElementKey]; ok {
+				dgst, ok := rawdgst.(string)
+				if !ok {
+					return nil, fmt.Errorf(`%q must be a string`, ArrayElementKey)
+				}
+				d, err := r.use(dgst)
+				if err != nil {
+					return nil, err
+				}
+				if d == nil {
+					// not disclosed: remove the element
+					continue
+				}
+				if d.name != `` {
+					return nil, fmt.Errorf(`disclosure for an object property is referenced from an array`)
+				}
+				elem = d.value
+			}
+		}
+		processed, err := r.value(elem)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, processed)
+	}
+	return dst, nil
+}
+
+func verifyKeyBinding(s *SDJWT, tok jwt.Token, kb *keyBinding) error {
+	key, err := holderKey(tok)
+	if err != nil {
+		return err
+	}
+
+	msg, err := jws.Parse(s.keyBinding)
+	if err != nil {
+		return fmt.Errorf(`failed to parse key binding JWT: %w`, err)
+	}
+	sigs := msg.Signatures()
+	if len(sigs) != 1 {
+		return fmt.Errorf(`key binding JWT must have exactly one signature`)
+	}
+	hdrs := sigs[0].ProtectedHeaders()
+	if !strings.EqualFold(hdrs.Type(), KeyBindingType) {
+		return fmt.Errorf(`"typ" header of key binding JWT must be %q`, KeyBindingType)
+	}
+
+	options := []jwt.ParseOption{
+		jwt.WithKey(hdrs.Algorithm(), key),
+		jwt.WithRequiredClaims(jwt.IssuedAtKey, jwt.AudienceKey, NonceKey, SDHashKey),
+		jwt.WithClaimValue(SDHashKey, digest(s.serializeWithoutKeyBinding())),
+	}
+	if kb != nil {
+		options = append(options,
+			jwt.WithAudience(kb.audience),
+			jwt.WithClaimValue(NonceKey, kb.nonce),
+			jwt.WithMaxAge(kb.maxAge),
+		)
+	}
+	if _, err := jwt.Parse(s.keyBinding, options...); err != nil {
+		return fmt.Errorf(`failed to verify key binding JWT: %w`, err)
+	}
+	return nil
+}
+
+// holderKey extracts the public key of the holder from the `cnf` claim
+func holderKey(tok jwt.Token) (jwk.Key, error) {
+	v, ok := tok.Get(ConfirmationKey)
+	if !ok {
+		return nil, fmt.Errorf(`%q claim is required to verify the key binding JWT`, ConfirmationKey)
+	}
+	cnf, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(`invalid %q claim (%T)`, ConfirmationKey, v)
+	}
+	rawkey, ok := cnf[ConfirmationJWKKey]
+	if !ok {
+		return nil, fmt.Errorf(`%q claim does not have a %q member`, ConfirmationKey, ConfirmationJWKKey)
+	}
+	buf, err := json.Marshal(rawkey)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to encode holder key: %w`, err)
+	}
+	key, err := jwk.ParseKey(buf)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse holder key: %w`, err)
+	}
+	return key, nil
+}
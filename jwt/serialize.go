@@ -51,9 +51,9 @@ func (e errStep) Serialize(_ SerializeCtx, _ interface{}) (interface{}, error) {
 // For example, to marshal the token into JSON, then apply JWS and JWE
 // in that order, you would do:
 //
-//	serialized, err := jwt.NewSerialer().
-//	   Sign(jwa.RS256, key).
-//	   Encrypt(jwa.RSA_OAEP, key.PublicKey).
+//	serialized, err := jwt.NewSerializer().
+//	   Sign(jwt.WithKey(jwa.RS256, key)).
+//	   Encrypt(jwt.WithKey(jwa.RSA_OAEP, key.PublicKey)).
 //	   Serialize(token)
 //
 // The `jwt.Sign()` function is equivalent to
@@ -61,8 +61,17 @@ func (e errStep) Serialize(_ SerializeCtx, _ interface{}) (interface{}, error) {
 //	serialized, err := jwt.NewSerializer().
 //	   Sign(...args...).
 //	   Serialize(token)
+//
+// The order of the steps is checked when `Serialize()` is called: signing
+// the result of an encryption step (i.e. encrypt-then-sign) is refused,
+// because the signature would not cover the claims themselves, and
+// parties that can only verify the signature cannot tell what they
+// are vouching for. Use `AllowEncryptThenSign(true)` if you really
+// need this order.
 type Serializer struct {
-	steps []SerializeStep
+	steps                []SerializeStep
+	transformers         []Transformer
+	allowEncryptThenSign bool
 }
 
 // NewSerializer creates a new empty serializer.
@@ -70,9 +79,18 @@ func NewSerializer() *Serializer {
 	return &Serializer{}
 }
 
-// Reset clears all of the registered steps.
+// Reset clears all of the registered steps and transformers.
 func (s *Serializer) Reset() *Serializer {
 	s.steps = nil
+	s.transformers = nil
+	return s
+}
+
+// AllowEncryptThenSign specifies whether the serializer may sign the
+// result of an encryption step. By default this is not allowed, and
+// `Serialize()` returns an error.
+func (s *Serializer) AllowEncryptThenSign(v bool) *Serializer {
+	s.allowEncryptThenSign = v
 	return s
 }
 
@@ -176,7 +194,7 @@ func (s *Serializer) Sign(options ...SignOption) *Serializer {
 		}
 		soptions = converted
 	}
-	return s.sign(soptions...)
+	return s.Transform(transformersFrom(options)...).sign(soptions...)
 }
 
 func (s *Serializer) sign(options ...jws.SignOption) *Serializer {
@@ -236,13 +254,43 @@ func (s *Serializer) encrypt(options ...jwe.EncryptOption) *Serializer {
 	})
 }
 
+// checkOrder makes sure that the built-in steps are specified in
+// a sensible order. Custom steps are not checked
+func (s *Serializer) checkOrder() error {
+	if s.allowEncryptThenSign {
+		return nil
+	}
+
+	var encrypted bool
+	for i, step := range s.steps {
+		switch step.(type) {
+		case *jweSerializer:
+			encrypted = true
+		case *jwsSerializer:
+			if encrypted {
+				return fmt.Errorf(`step #%d signs an encrypted payload (use AllowEncryptThenSign(true) to allow encrypt-then-sign)`, i+2)
+			}
+		}
+	}
+	return nil
+}
+
 func (s *Serializer) Serialize(t Token) ([]byte, error) {
+	if err := s.checkOrder(); err != nil {
+		return nil, fmt.Errorf(`invalid serialization order: %w`, err)
+	}
+
 	steps := make([]SerializeStep, len(s.steps)+1)
 	steps[0] = jsonSerializer{}
 	for i, step := range s.steps {
 		steps[i+1] = step
 	}
 
+	t, err := s.transform(t)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to transform token: %w`, err)
+	}
+
 	var ctx serializeCtx
 	ctx.nested = len(s.steps) > 1
 	var payload interface{} = t
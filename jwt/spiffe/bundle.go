@@ -0,0 +1,231 @@
+package spiffe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// DefaultRefreshHint is the interval at which bundles are fetched from
+// bundle endpoints, when the bundle does not specify `spiffe_refresh_hint`.
+const DefaultRefreshHint = 5 * time.Minute
+
+// Values of the `use` parameter of the keys in SPIFFE bundles
+const (
+	UseJWTSVID  = `jwt-svid`
+	UseX509SVID = `x509-svid`
+)
+
+// Bundle contains the JWT authorities (the keys that JWT-SVIDs are signed
+// with) of a trust domain.
+type Bundle struct {
+	// TrustDomain is the trust domain that the bundle belongs to.
+	TrustDomain string
+	// Keys contains the JWT authorities.
+	Keys jwk.Set
+	// Sequence is the value of `spiffe_sequence`, if any.
+	Sequence uint64
+	// RefreshHint is the value of `spiffe_refresh_hint`, if any.
+	RefreshHint time.Duration
+}
+
+// ParseBundle parses the bundle of the trust domain `trustDomain`. `data`
+// is either a SPIFFE bundle, as served by bundle endpoints, or a plain
+// JWKS, as provided by the Workload API.
+//
+// Keys whose `use` parameter is `jwt-svid`, or that do not have a `use`
+// parameter, are treated as JWT authorities. Other keys (e.g. the X.509
+// authorities, whose `use` is `x509-svid`) are ignored. JWT authorities
+// must have a key ID.
+func ParseBundle(trustDomain string, data []byte) (*Bundle, error) {
+	if err := validateTrustDomain(trustDomain); err != nil {
+		return nil, fmt.Errorf(`spiffe.ParseBundle: %w`, err)
+	}
+
+	var raw struct {
+		Keys        []map[string]interface{} `json:"keys"`
+		Sequence    uint64                   `json:"spiffe_sequence"`
+		RefreshHint int64                    `json:"spiffe_refresh_hint"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf(`spiffe.ParseBundle: failed to parse bundle: %w`, err)
+	}
+	if raw.RefreshHint < 0 {
+		return nil, fmt.Errorf(`spiffe.ParseBundle: "spiffe_refresh_hint" must not be negative`)
+	}
+
+	set := jwk.NewSet()
+	for i, m := range raw.Keys {
+		if use, ok := m[jwk.KeyUsageKey]; ok {
+			if use != UseJWTSVID {
+				continue
+			}
+			// "jwt-svid" is not a valid value for jwk.Key
+			delete(m, jwk.KeyUsageKey)
+		}
+		buf, err := json.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf(`spiffe.ParseBundle: failed to encode key #%d: %w`, i+1, err)
+		}
+		key, err := jwk.ParseKey(buf)
+		if err != nil {
+			return nil, fmt.Errorf(`spiffe.ParseBundle: failed to parse key #%d: %w`, i+1, err)
+		}
+		if key.KeyID() == `` {
+			return nil, fmt.Errorf(`spiffe.ParseBundle: key #%d does not have a key ID`, i+1)
+		}
+		if err := set.AddKey(key); err != nil {
+			return nil, fmt.Errorf(`spiffe.ParseBundle: failed to add key #%d: %w`, i+1, err)
+		}
+	}
+
+	return &Bundle{
+		TrustDomain: trustDomain,
+		Keys:        set,
+		Sequence:    raw.Sequence,
+		RefreshHint: time.Duration(raw.RefreshHint) * time.Second,
+	}, nil
+}
+
+// BundleSource provides the bundles of trust domains to `spiffe.ParseSVID()`.
+type BundleSource interface {
+	// FetchBundle returns the bundle of the trust domain `trustDomain`,
+	// or an error if the bundle is not available.
+	FetchBundle(ctx context.Context, trustDomain string) (*Bundle, error)
+}
+
+// BundleSourceFunc is a BundleSource that is implemented by a single
+// function.
+type BundleSourceFunc func(context.Context, string) (*Bundle, error)
+
+func (f BundleSourceFunc) FetchBundle(ctx context.Context, trustDomain string) (*Bundle, error) {
+	return f(ctx, trustDomain)
+}
+
+// NewStaticBundleSource creates a BundleSource that provides `bundles`,
+// for example those obtained from the Workload API. If multiple bundles
+// belong to the same trust domain, the last one is used.
+func NewStaticBundleSource(bundles ...*Bundle) BundleSource {
+	m := make(map[string]*Bundle, len(bundles))
+	for _, b := range bundles {
+		m[b.TrustDomain] = b
+	}
+	return BundleSourceFunc(func(_ context.Context, trustDomain string) (*Bundle, error) {
+		b, ok := m[trustDomain]
+		if !ok {
+			return nil, fmt.Errorf(`no bundle for trust domain %q`, trustDomain)
+		}
+		return b, nil
+	})
+}
+
+// HTTPClient is the interface of the HTTP client used to fetch bundles
+// from bundle endpoints. `*http.Client` satisfies it.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// BundleEndpoint is a BundleSource that fetches the bundle of a single
+// trust domain from its SPIFFE bundle endpoint. The bundle is cached, and
+// fetched again after the interval specified in the bundle by
+// `spiffe_refresh_hint` (or `spiffe.DefaultRefreshHint`). If the bundle
+// cannot be fetched again, the previous bundle continues to be used.
+//
+// BundleEndpoint should be created once and reused.
+type BundleEndpoint struct {
+	trustDomain string
+	url         string
+	client      HTTPClient
+
+	mu      sync.Mutex
+	bundle  *Bundle
+	fetched time.Time
+}
+
+// NewBundleEndpoint creates a BundleEndpoint that fetches the bundle of
+// `trustDomain` from `url`. For endpoints that use the `https_web`
+// profile, the default HTTP client suffices. For endpoints that use the
+// `https_spiffe` profile, specify an HTTP client that authenticates the
+// server using its X.509-SVID via `spiffe.WithHTTPClient()`.
+func NewBundleEndpoint(trustDomain, url string, options ...Option) (*BundleEndpoint, error) {
+	if err := validateTrustDomain(trustDomain); err != nil {
+		return nil, fmt.Errorf(`spiffe.NewBundleEndpoint: %w`, err)
+	}
+	if url == `` {
+		return nil, fmt.Errorf(`spiffe.NewBundleEndpoint: url must be specified`)
+	}
+
+	e := &BundleEndpoint{
+		trustDomain: trustDomain,
+		url:         url,
+		client:      http.DefaultClient,
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identHTTPClient{}:
+			e.client = o.Value().(HTTPClient)
+		default:
+			return nil, fmt.Errorf(`spiffe.NewBundleEndpoint: invalid option %T`, o)
+		}
+	}
+	return e, nil
+}
+
+// FetchBundle implements BundleSource.
+func (e *BundleEndpoint) FetchBundle(ctx context.Context, trustDomain string) (*Bundle, error) {
+	if trustDomain != e.trustDomain {
+		return nil, fmt.Errorf(`no bundle for trust domain %q`, trustDomain)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.bundle != nil {
+		hint := e.bundle.RefreshHint
+		if hint == 0 {
+			hint = DefaultRefreshHint
+		}
+		if time.Since(e.fetched) < hint {
+			return e.bundle, nil
+		}
+	}
+
+	bundle, err := e.fetch(ctx)
+	if err != nil {
+		if e.bundle != nil {
+			return e.bundle, nil
+		}
+		return nil, err
+	}
+	e.bundle = bundle
+	e.fetched = time.Now()
+	return bundle, nil
+}
+
+func (e *BundleEndpoint) fetch(ctx context.Context) (*Bundle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to create request: %w`, err)
+	}
+	req.Header.Set(`Accept`, `application/json`)
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to fetch bundle: %w`, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`failed to fetch bundle: unexpected status %d`, res.StatusCode)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read bundle: %w`, err)
+	}
+	return ParseBundle(e.trustDomain, data)
+}
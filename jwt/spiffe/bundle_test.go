@@ -0,0 +1,96 @@
+package spiffe_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt/spiffe"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthority creates a JWT authority, and returns its private key and
+// its public key as a key in a SPIFFE bundle
+func newAuthority(t *testing.T, kid string) (jwk.Key, map[string]interface{}) {
+	t.Helper()
+	key, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, kid), `key.Set should succeed`)
+	pubkey, err := key.PublicKey()
+	require.NoError(t, err, `key.PublicKey should succeed`)
+
+	buf, err := json.Marshal(pubkey)
+	require.NoError(t, err, `json.Marshal should succeed`)
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf, &m), `json.Unmarshal should succeed`)
+	m[jwk.KeyUsageKey] = spiffe.UseJWTSVID
+	return key, m
+}
+
+func TestParseBundle(t *testing.T) {
+	t.Parallel()
+
+	_, authority := newAuthority(t, `jwt-1`)
+	_, x509 := newAuthority(t, `x509-1`)
+	x509[jwk.KeyUsageKey] = spiffe.UseX509SVID
+
+	data, err := json.Marshal(map[string]interface{}{
+		`keys`:                []interface{}{authority, x509},
+		`spiffe_sequence`:     42,
+		`spiffe_refresh_hint`: 300,
+	})
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	bundle, err := spiffe.ParseBundle(`example.org`, data)
+	require.NoError(t, err, `spiffe.ParseBundle should succeed`)
+	require.Equal(t, `example.org`, bundle.TrustDomain)
+	require.Equal(t, uint64(42), bundle.Sequence)
+	require.Equal(t, 5*time.Minute, bundle.RefreshHint)
+	require.Equal(t, 1, bundle.Keys.Len(), `X.509 authorities should be ignored`)
+	_, ok := bundle.Keys.LookupKeyID(`jwt-1`)
+	require.True(t, ok, `JWT authorities should be included`)
+
+	delete(authority, jwk.KeyIDKey)
+	data, err = json.Marshal(map[string]interface{}{`keys`: []interface{}{authority}})
+	require.NoError(t, err, `json.Marshal should succeed`)
+	_, err = spiffe.ParseBundle(`example.org`, data)
+	require.Error(t, err, `JWT authorities without key IDs should be rejected`)
+
+	_, err = spiffe.ParseBundle(`Example.org`, []byte(`{"keys":[]}`))
+	require.Error(t, err, `invalid trust domains should be rejected`)
+}
+
+func TestBundleEndpoint(t *testing.T) {
+	t.Parallel()
+
+	_, authority := newAuthority(t, `jwt-1`)
+	var requests int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set(`Content-Type`, `application/json`)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{`keys`: []interface{}{authority}})
+	}))
+	defer srv.Close()
+
+	e, err := spiffe.NewBundleEndpoint(`example.org`, srv.URL, spiffe.WithHTTPClient(srv.Client()))
+	require.NoError(t, err, `spiffe.NewBundleEndpoint should succeed`)
+
+	for i := 0; i < 2; i++ {
+		bundle, err := e.FetchBundle(context.Background(), `example.org`)
+		require.NoError(t, err, `e.FetchBundle should succeed`)
+		require.Equal(t, 1, bundle.Keys.Len())
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests), `bundle should be cached`)
+
+	_, err = e.FetchBundle(context.Background(), `other.org`)
+	require.Error(t, err, `bundles of other trust domains should not be available`)
+
+	_, err = spiffe.NewBundleEndpoint(`example.org`, ``)
+	require.Error(t, err, `url should be required`)
+}
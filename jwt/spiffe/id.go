@@ -0,0 +1,125 @@
+// Package spiffe implements the validation of JWT-SVIDs, the JWT form of
+// the SPIFFE Verifiable Identity Document.
+//
+// `spiffe.ParseID()` parses SPIFFE IDs (e.g. `spiffe://example.org/backend`),
+// `spiffe.ParseBundle()` parses the JWT authorities of a trust domain
+// (as served by a SPIFFE bundle endpoint, or provided by the Workload API),
+// and `spiffe.ParseSVID()` verifies and validates a JWT-SVID using the
+// bundle of the trust domain of its subject.
+package spiffe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scheme is the URI scheme of SPIFFE IDs.
+const Scheme = `spiffe`
+
+const (
+	idPrefix             = Scheme + `://`
+	maxIDLength          = 2048
+	maxTrustDomainLength = 255
+)
+
+// ID is a SPIFFE ID, which consists of a trust domain and an optional
+// path. The zero value is not a valid ID.
+type ID struct {
+	trustDomain string
+	path        string
+}
+
+// ParseID parses the SPIFFE ID `s`, as described in the SPIFFE ID
+// specification: the scheme must be `spiffe`, the trust domain may only
+// contain lowercase letters, digits, dots, dashes, and underscores, and
+// the path segments may only contain letters, digits, dots, dashes, and
+// underscores, and may be neither empty, `.`, nor `..`. Query strings,
+// fragments, ports, and user info are not allowed.
+func ParseID(s string) (ID, error) {
+	if len(s) > maxIDLength {
+		return ID{}, fmt.Errorf(`spiffe.ParseID: ID must be at most %d bytes long`, maxIDLength)
+	}
+	if !strings.HasPrefix(s, idPrefix) {
+		return ID{}, fmt.Errorf(`spiffe.ParseID: ID must start with %q`, idPrefix)
+	}
+
+	rest := s[len(idPrefix):]
+	td, path := rest, ``
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		td, path = rest[:i], rest[i:]
+	}
+	if err := validateTrustDomain(td); err != nil {
+		return ID{}, fmt.Errorf(`spiffe.ParseID: %w`, err)
+	}
+	if err := validatePath(path); err != nil {
+		return ID{}, fmt.Errorf(`spiffe.ParseID: %w`, err)
+	}
+	return ID{trustDomain: td, path: path}, nil
+}
+
+func validateTrustDomain(td string) error {
+	if td == `` {
+		return fmt.Errorf(`trust domain is missing`)
+	}
+	if len(td) > maxTrustDomainLength {
+		return fmt.Errorf(`trust domain must be at most %d bytes long`, maxTrustDomainLength)
+	}
+	for i := 0; i < len(td); i++ {
+		c := td[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			return fmt.Errorf(`trust domain contains an invalid character %q`, c)
+		}
+	}
+	return nil
+}
+
+func validatePath(path string) error {
+	if path == `` {
+		return nil
+	}
+	for _, segment := range strings.Split(path[1:], `/`) {
+		switch segment {
+		case ``:
+			return fmt.Errorf(`path contains an empty segment`)
+		case `.`, `..`:
+			return fmt.Errorf(`path contains a relative segment %q`, segment)
+		}
+		for i := 0; i < len(segment); i++ {
+			c := segment[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+				return fmt.Errorf(`path contains an invalid character %q`, c)
+			}
+		}
+	}
+	return nil
+}
+
+// TrustDomain returns the trust domain of the ID, e.g. `example.org`.
+func (id ID) TrustDomain() string {
+	return id.trustDomain
+}
+
+// Path returns the path of the ID, e.g. `/backend`. It is empty for
+// the IDs of trust domains.
+func (id ID) Path() string {
+	return id.path
+}
+
+// MemberOf returns true if the ID belongs to the trust domain `td`.
+func (id ID) MemberOf(td string) bool {
+	return id.trustDomain != `` && id.trustDomain == td
+}
+
+// IsZero returns true if the ID is the zero value.
+func (id ID) IsZero() bool {
+	return id.trustDomain == ``
+}
+
+// String returns the ID in its URI form. It returns an empty string for
+// the zero value.
+func (id ID) String() string {
+	if id.IsZero() {
+		return ``
+	}
+	return idPrefix + id.trustDomain + id.path
+}
@@ -0,0 +1,67 @@
+package spiffe_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/jwt/spiffe"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid IDs", func(t *testing.T) {
+		t.Parallel()
+		testcases := []struct {
+			ID          string
+			TrustDomain string
+			Path        string
+		}{
+			{ID: `spiffe://example.org`, TrustDomain: `example.org`},
+			{ID: `spiffe://example.org/backend`, TrustDomain: `example.org`, Path: `/backend`},
+			{ID: `spiffe://prod_1.example-org/ns/default/sa/Web.API`, TrustDomain: `prod_1.example-org`, Path: `/ns/default/sa/Web.API`},
+		}
+		for _, tc := range testcases {
+			id, err := spiffe.ParseID(tc.ID)
+			require.NoError(t, err, `spiffe.ParseID should succeed for %s`, tc.ID)
+			require.Equal(t, tc.TrustDomain, id.TrustDomain())
+			require.Equal(t, tc.Path, id.Path())
+			require.Equal(t, tc.ID, id.String())
+			require.True(t, id.MemberOf(tc.TrustDomain))
+			require.False(t, id.MemberOf(`other.org`))
+		}
+	})
+	t.Run("invalid IDs", func(t *testing.T) {
+		t.Parallel()
+		for _, s := range []string{
+			``,
+			`https://example.org/backend`,
+			`SPIFFE://example.org/backend`,
+			`spiffe://`,
+			`spiffe:///backend`,
+			`spiffe://Example.org/backend`,
+			`spiffe://example.org:8443/backend`,
+			`spiffe://user@example.org/backend`,
+			`spiffe://example.org/`,
+			`spiffe://example.org//backend`,
+			`spiffe://example.org/backend/`,
+			`spiffe://example.org/./backend`,
+			`spiffe://example.org/../backend`,
+			`spiffe://example.org/backend?x=1`,
+			`spiffe://example.org/backend#x`,
+			`spiffe://example.org/back%20end`,
+			`spiffe://example.org/` + strings.Repeat(`a`, 2048),
+		} {
+			_, err := spiffe.ParseID(s)
+			require.Error(t, err, `spiffe.ParseID should fail for %q`, s)
+		}
+	})
+	t.Run("zero value", func(t *testing.T) {
+		t.Parallel()
+		var id spiffe.ID
+		require.True(t, id.IsZero())
+		require.Empty(t, id.String())
+		require.False(t, id.MemberOf(``))
+	})
+}
@@ -0,0 +1,16 @@
+package spiffe
+
+import (
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `spiffe.NewBundleEndpoint()`.
+type Option = option.Interface
+
+type identHTTPClient struct{}
+
+// WithHTTPClient specifies the HTTP client that is used to fetch bundles.
+// By default, `http.DefaultClient` is used.
+func WithHTTPClient(v HTTPClient) Option {
+	return option.New(identHTTPClient{}, v)
+}
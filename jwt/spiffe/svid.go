@@ -0,0 +1,132 @@
+package spiffe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// the algorithms that JWT-SVIDs may be signed with (JWT-SVID Section 3)
+var svidAlgorithms = map[jwa.SignatureAlgorithm]struct{}{
+	jwa.RS256: {},
+	jwa.RS384: {},
+	jwa.RS512: {},
+	jwa.ES256: {},
+	jwa.ES384: {},
+	jwa.ES512: {},
+	jwa.PS256: {},
+	jwa.PS384: {},
+	jwa.PS512: {},
+}
+
+// SVID is a validated JWT-SVID.
+type SVID struct {
+	// ID is the SPIFFE ID of the workload, taken from the `sub` claim.
+	ID ID
+	// Audience contains the values of the `aud` claim.
+	Audience []string
+	// Expiration is the value of the `exp` claim.
+	Expiration time.Time
+	// Token is the parsed token, which contains all claims.
+	Token jwt.Token
+}
+
+// ParseSVID parses the JWT-SVID `src`, and verifies and validates it as
+// described in the JWT-SVID specification:
+//
+//   - the `alg` header must be one of the RSA, ECDSA, or RSA-PSS algorithms
+//   - the `typ` header, if present, must be `JWT` or `JOSE`
+//   - the `sub` claim must be a SPIFFE ID, and the signature must be
+//     verified using the bundle of its trust domain, obtained from `bundles`
+//   - the `aud` claim must contain `audience`
+//   - the `exp` claim must be present, and the token must not have expired
+//
+// Further `jwt.ParseOption`s (e.g. `jwt.WithContext()`, which is passed to
+// `bundles`, or `jwt.WithAcceptableSkew()`) may be passed as well, but
+// `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func ParseSVID(src []byte, bundles BundleSource, audience string, options ...jwt.ParseOption) (*SVID, error) {
+	if bundles == nil || audience == `` {
+		return nil, fmt.Errorf(`spiffe.ParseSVID: bundles and audience must be specified`)
+	}
+	for _, o := range options {
+		if parseopts.IsVerifyOrValidate(o) {
+			return nil, fmt.Errorf(`spiffe.ParseSVID: jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+	}
+
+	options = append(options[:len(options):len(options)],
+		jwt.WithKeyProvider(&svidKeyProvider{bundles: bundles}),
+		jwt.WithRequiredClaims(jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey),
+		jwt.WithAudience(audience),
+	)
+	tok, err := jwt.Parse(src, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`spiffe.ParseSVID: %w`, err)
+	}
+
+	// the ID has already been validated when looking up the keys
+	id, err := ParseID(tok.Subject())
+	if err != nil {
+		return nil, fmt.Errorf(`spiffe.ParseSVID: invalid %q claim: %w`, jwt.SubjectKey, err)
+	}
+	return &SVID{
+		ID:         id,
+		Audience:   tok.Audience(),
+		Expiration: tok.Expiration(),
+		Token:      tok,
+	}, nil
+}
+
+// svidKeyProvider is a jws.KeyProvider that provides the keys in the
+// bundle of the trust domain of the subject of the JWT-SVID
+type svidKeyProvider struct {
+	bundles BundleSource
+}
+
+func (p *svidKeyProvider) FetchKeys(ctx context.Context, sink jws.KeySink, sig *jws.Signature, msg *jws.Message) error {
+	hdrs := sig.ProtectedHeaders()
+	if _, ok := svidAlgorithms[hdrs.Algorithm()]; !ok {
+		return fmt.Errorf(`algorithm %q is not allowed for JWT-SVIDs`, hdrs.Algorithm())
+	}
+	switch typ := hdrs.Type(); typ {
+	case ``, `JWT`, `JOSE`:
+	default:
+		return fmt.Errorf(`"typ" header %q is not allowed for JWT-SVIDs`, typ)
+	}
+
+	// The claims have not been verified yet, but the subject is only used
+	// to choose the bundle. A forged `sub` claim results in a signature
+	// that cannot be verified using the keys of that trust domain
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &claims); err != nil {
+		return fmt.Errorf(`failed to parse claims: %w`, err)
+	}
+	id, err := ParseID(claims.Subject)
+	if err != nil {
+		return fmt.Errorf(`invalid %q claim: %w`, jwt.SubjectKey, err)
+	}
+
+	bundle, err := p.bundles.FetchBundle(ctx, id.TrustDomain())
+	if err != nil {
+		return fmt.Errorf(`failed to fetch bundle for trust domain %q: %w`, id.TrustDomain(), err)
+	}
+	if bundle.TrustDomain != id.TrustDomain() {
+		return fmt.Errorf(`bundle for trust domain %q was returned for %q`, bundle.TrustDomain, id.TrustDomain())
+	}
+
+	// JWT-SVIDs are not required to have a key ID
+	//nolint:forcetypeassert
+	kp := jws.WithKeySet(bundle.Keys,
+		jws.WithRequireKid(hdrs.KeyID() != ``),
+		jws.WithInferAlgorithmFromKey(true),
+	).Value().(jws.KeyProvider)
+	return kp.FetchKeys(ctx, sink, sig, msg)
+}
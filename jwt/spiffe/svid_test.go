@@ -0,0 +1,94 @@
+package spiffe_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/spiffe"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSVID(t *testing.T) {
+	t.Parallel()
+
+	key, authority := newAuthority(t, `jwt-1`)
+	otherKey, otherAuthority := newAuthority(t, `jwt-1`)
+	bundleOf := func(td string, authority map[string]interface{}) *spiffe.Bundle {
+		data, err := json.Marshal(map[string]interface{}{`keys`: []interface{}{authority}})
+		require.NoError(t, err, `json.Marshal should succeed`)
+		bundle, err := spiffe.ParseBundle(td, data)
+		require.NoError(t, err, `spiffe.ParseBundle should succeed`)
+		return bundle
+	}
+	bundles := spiffe.NewStaticBundleSource(
+		bundleOf(`example.org`, authority),
+		bundleOf(`other.org`, otherAuthority),
+	)
+
+	sign := func(t *testing.T, key interface{}, sub string, options ...jwt.Option) []byte {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Subject(sub).
+			Audience([]string{`spiffe://example.org/backend`}).
+			Expiration(time.Now().Add(5 * time.Minute)).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.ES256, key, options...))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+
+	t.Run("valid SVID", func(t *testing.T) {
+		t.Parallel()
+		svid, err := spiffe.ParseSVID(sign(t, key, `spiffe://example.org/frontend`), bundles, `spiffe://example.org/backend`)
+		require.NoError(t, err, `spiffe.ParseSVID should succeed`)
+		require.Equal(t, `spiffe://example.org/frontend`, svid.ID.String())
+		require.Equal(t, `/frontend`, svid.ID.Path())
+		require.Equal(t, []string{`spiffe://example.org/backend`}, svid.Audience)
+		require.False(t, svid.Expiration.IsZero())
+	})
+	t.Run("without kid", func(t *testing.T) {
+		t.Parallel()
+		nokid, err := key.Clone()
+		require.NoError(t, err, `key.Clone should succeed`)
+		require.NoError(t, nokid.Remove(`kid`), `Remove should succeed`)
+		_, err = spiffe.ParseSVID(sign(t, nokid, `spiffe://example.org/frontend`), bundles, `spiffe://example.org/backend`)
+		require.NoError(t, err, `spiffe.ParseSVID should succeed without kid`)
+	})
+	t.Run("invalid SVIDs", func(t *testing.T) {
+		t.Parallel()
+		_, err := spiffe.ParseSVID(sign(t, key, `spiffe://example.org/frontend`), bundles, `spiffe://example.org/other`)
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `other audiences should be rejected`)
+		_, err = spiffe.ParseSVID(sign(t, key, `frontend`), bundles, `spiffe://example.org/backend`)
+		require.Error(t, err, `subjects that are not SPIFFE IDs should be rejected`)
+		_, err = spiffe.ParseSVID(sign(t, otherKey, `spiffe://example.org/frontend`), bundles, `spiffe://example.org/backend`)
+		require.Error(t, err, `SVIDs signed by other trust domains should be rejected`)
+		_, err = spiffe.ParseSVID(sign(t, key, `spiffe://unknown.org/frontend`), bundles, `spiffe://example.org/backend`)
+		require.Error(t, err, `SVIDs of unknown trust domains should be rejected`)
+
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(jws.TypeKey, `at+jwt`), `Set should succeed`)
+		_, err = spiffe.ParseSVID(sign(t, key, `spiffe://example.org/frontend`, jws.WithProtectedHeaders(hdrs)), bundles, `spiffe://example.org/backend`)
+		require.Error(t, err, `other typ headers should be rejected`)
+
+		hmac, err := jwt.Sign(jwt.New(), jwt.WithKey(jwa.HS256, []byte(`abracadabra-abracadabra-abracadabra`)))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		_, err = spiffe.ParseSVID(hmac, bundles, `spiffe://example.org/backend`)
+		require.Error(t, err, `symmetric algorithms should be rejected`)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		t.Parallel()
+		signed := sign(t, key, `spiffe://example.org/frontend`)
+		_, err := spiffe.ParseSVID(signed, nil, `spiffe://example.org/backend`)
+		require.Error(t, err, `bundles should be required`)
+		_, err = spiffe.ParseSVID(signed, bundles, ``)
+		require.Error(t, err, `audience should be required`)
+		_, err = spiffe.ParseSVID(signed, bundles, `spiffe://example.org/backend`, jwt.WithValidate(false))
+		require.Error(t, err, `jwt.WithValidate() should be rejected`)
+	})
+}
@@ -0,0 +1,166 @@
+package jwt
+
+import (
+	"errors"
+
+	"github.com/lestrrat-go/jwx/v2"
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+// StatsReason describes why a token was rejected by `jwt.Parse()`. Its
+// values are short, fixed strings that are suitable for use as metric labels.
+type StatsReason string
+
+const (
+	// StatsReasonMalformed is used when the token could not be parsed,
+	// for example because it is not a valid JWS message, it is too large,
+	// or its claims are not a valid JSON object
+	StatsReasonMalformed StatsReason = `malformed`
+	// StatsReasonSignature is used when none of the signatures could be
+	// verified with the provided keys (see `jws.ErrVerificationFailed()`)
+	StatsReasonSignature StatsReason = `signature`
+	// StatsReasonDecryption is used when a JWE enveloped token could not
+	// be decrypted
+	StatsReasonDecryption StatsReason = `decryption`
+	// StatsReasonExpired is used when the `exp` claim is not satisfied
+	StatsReasonExpired StatsReason = `expired`
+	// StatsReasonNotYetValid is used when the `nbf` claim is not satisfied
+	StatsReasonNotYetValid StatsReason = `not_yet_valid`
+	// StatsReasonIssuedAt is used when the `iat` claim is not satisfied
+	StatsReasonIssuedAt StatsReason = `issued_at`
+	// StatsReasonIssuer is used when the `iss` claim is not satisfied
+	StatsReasonIssuer StatsReason = `issuer`
+	// StatsReasonAudience is used when the `aud` claim is not satisfied
+	StatsReasonAudience StatsReason = `audience`
+	// StatsReasonRequiredClaim is used when a required claim is missing
+	StatsReasonRequiredClaim StatsReason = `required_claim`
+	// StatsReasonReplayed is used when the token has already been used
+	// (see `jwt.WithReplayDetection()`)
+	StatsReasonReplayed StatsReason = `replayed`
+	// StatsReasonValidation is used when the token failed other validation
+	// checks, such as those specified using `jwt.WithValidator()`
+	StatsReasonValidation StatsReason = `validation`
+	// StatsReasonOther is used when the token was rejected for any other
+	// reason, for example because the verification keys could not be fetched
+	StatsReasonOther StatsReason = `other`
+)
+
+// StatsEvent describes the outcome of a call to `jwt.Parse()`, and is
+// passed to the function specified using `jwt.WithStatsCallback()`.
+type StatsEvent struct {
+	// Success is true if `jwt.Parse()` returned a token.
+	Success bool
+	// Verified is true if the signature of the token was verified.
+	// It is false if verification was skipped using `jwt.WithVerify(false)`,
+	// or if the token was rejected before or during verification.
+	Verified bool
+	// Reason describes why the token was rejected. It is empty on success.
+	Reason StatsReason
+	// Degraded is true if the token was accepted only because of the
+	// grace period specified via `jwt.WithGracePeriod()`.
+	Degraded bool
+	// Err is the error returned by `jwt.Parse()`, if any.
+	Err error
+	// Algorithm and KeyID are the `alg` and `kid` headers of the JWS
+	// message, and Issuer is the `iss` claim of the token. Any of these
+	// may be empty if it is not present, or if the token could not be
+	// parsed far enough to determine it.
+	//
+	// If Verified is false, these values are taken from the unverified
+	// token, and may have been chosen by an attacker. As such, they should
+	// be checked against a list of known values before being used, e.g. as
+	// metric labels, to avoid an unbounded number of label values.
+	Algorithm jwa.SignatureAlgorithm
+	KeyID     string
+	Issuer    string
+}
+
+// decryptError is returned when a JWE enveloped token could not be decrypted
+type decryptError struct {
+	error
+}
+
+func (err *decryptError) Unwrap() error {
+	return err.error
+}
+
+// reportStats calls the stats callback, if any, with the outcome of parsing
+// `data`. `data` may be nil if it should not be inspected
+func (ctx *parseCtx) reportStats(data []byte, err error) {
+	if ctx.statsCallback == nil {
+		return
+	}
+
+	ev := StatsEvent{
+		Success: err == nil,
+		// the headers are only available after the signature was verified
+		Verified: ctx.headers != nil,
+		Degraded: err == nil && ctx.degraded,
+		Err:      err,
+	}
+	if err != nil {
+		ev.Reason = ctx.statsReason(err)
+	}
+
+	headers := ctx.headers
+	if ctx.decoded {
+		ev.Issuer = ctx.token.Issuer()
+	}
+	if (headers == nil || !ctx.decoded) && jwx.GuessFormat(data) == jwx.JWS {
+		if msg, err := jws.Parse(data); err == nil {
+			if headers == nil {
+				if sigs := msg.Signatures(); len(sigs) > 0 {
+					headers = sigs[0].ProtectedHeaders()
+				}
+			}
+			if !ctx.decoded {
+				var claims struct {
+					Issuer string `json:"iss"`
+				}
+				if err := json.Unmarshal(msg.Payload(), &claims); err == nil {
+					ev.Issuer = claims.Issuer
+				}
+			}
+		}
+	}
+	if headers != nil {
+		ev.Algorithm = headers.Algorithm()
+		ev.KeyID = headers.KeyID()
+	}
+
+	ctx.statsCallback(ev)
+}
+
+func (ctx *parseCtx) statsReason(err error) StatsReason {
+	var derr *decryptError
+	switch {
+	case errors.Is(err, jws.ErrVerificationFailed()):
+		return StatsReasonSignature
+	case errors.As(err, &derr):
+		return StatsReasonDecryption
+	case ctx.malformed, errors.Is(err, errInvalidJWT):
+		return StatsReasonMalformed
+	case errors.Is(err, ErrTokenExpired()):
+		return StatsReasonExpired
+	case errors.Is(err, ErrTokenNotYetValid()):
+		return StatsReasonNotYetValid
+	case errors.Is(err, ErrInvalidIssuedAt()):
+		return StatsReasonIssuedAt
+	case errors.Is(err, ErrInvalidIssuer()):
+		return StatsReasonIssuer
+	case errors.Is(err, ErrInvalidAudience()):
+		return StatsReasonAudience
+	case errors.Is(err, ErrRequiredClaim()):
+		return StatsReasonRequiredClaim
+	case errors.Is(err, ErrTokenReplayed()):
+		return StatsReasonReplayed
+	}
+
+	var verr ValidationError
+	if errors.As(err, &verr) {
+		return StatsReasonValidation
+	}
+	return StatsReasonOther
+}
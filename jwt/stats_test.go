@@ -0,0 +1,125 @@
+package jwt_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsCallback(t *testing.T) {
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+	pubkey, err := jwk.PublicKeyOf(key)
+	require.NoError(t, err, `jwk.PublicKeyOf should succeed`)
+	wrongkey, err := jwxtest.GenerateRsaPublicJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+
+	sign := func(t *testing.T, exp time.Time) []byte {
+		t.Helper()
+		tok, err := jwt.NewBuilder().
+			Issuer(`https://issuer.example.com`).
+			Expiration(exp).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+	valid := sign(t, time.Now().Add(time.Hour))
+	expired := sign(t, time.Now().Add(-time.Hour))
+
+	parse := func(t *testing.T, src []byte, options ...jwt.ParseOption) (jwt.StatsEvent, error) {
+		t.Helper()
+		var events []jwt.StatsEvent
+		options = append(options, jwt.WithStatsCallback(func(ev jwt.StatsEvent) {
+			events = append(events, ev)
+		}))
+		_, err := jwt.Parse(src, options...)
+		require.Len(t, events, 1, `callback should be called exactly once`)
+		require.Equal(t, err, events[0].Err, `event should contain the error`)
+		return events[0], err
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ev, err := parse(t, valid, jwt.WithKey(jwa.RS256, pubkey))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, jwt.StatsEvent{
+			Success:   true,
+			Verified:  true,
+			Algorithm: jwa.RS256,
+			KeyID:     `my-key`,
+			Issuer:    `https://issuer.example.com`,
+		}, ev)
+	})
+	t.Run("verification skipped", func(t *testing.T) {
+		ev, err := parse(t, valid, jwt.WithVerify(false))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.True(t, ev.Success, `event should report success`)
+		require.False(t, ev.Verified, `event should report that the token was not verified`)
+		require.Equal(t, `https://issuer.example.com`, ev.Issuer)
+		require.Equal(t, `my-key`, ev.KeyID)
+	})
+	t.Run("invalid signature", func(t *testing.T) {
+		ev, err := parse(t, valid, jwt.WithKey(jwa.RS256, wrongkey))
+		require.True(t, errors.Is(err, jws.ErrVerificationFailed()), `jwt.Parse should fail`)
+		require.False(t, ev.Success, `event should report failure`)
+		require.False(t, ev.Verified, `event should report that the token was not verified`)
+		require.Equal(t, jwt.StatsReasonSignature, ev.Reason)
+		require.Equal(t, jwa.RS256, ev.Algorithm, `unverified algorithm should be reported`)
+		require.Equal(t, `https://issuer.example.com`, ev.Issuer, `unverified issuer should be reported`)
+	})
+	t.Run("expired", func(t *testing.T) {
+		ev, err := parse(t, expired, jwt.WithKey(jwa.RS256, pubkey))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.True(t, ev.Verified, `event should report that the token was verified`)
+		require.Equal(t, jwt.StatsReasonExpired, ev.Reason)
+		require.Equal(t, `https://issuer.example.com`, ev.Issuer)
+	})
+	t.Run("issuer", func(t *testing.T) {
+		ev, err := parse(t, valid, jwt.WithKey(jwa.RS256, pubkey), jwt.WithIssuer(`https://other.example.com`))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.Equal(t, jwt.StatsReasonIssuer, ev.Reason)
+	})
+	t.Run("custom validator", func(t *testing.T) {
+		ev, err := parse(t, valid, jwt.WithKey(jwa.RS256, pubkey), jwt.WithValidator(jwt.ValidatorFunc(func(_ context.Context, _ jwt.Token) jwt.ValidationError {
+			return jwt.NewValidationError(errors.New(`rejected`))
+		})))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.Equal(t, jwt.StatsReasonValidation, ev.Reason)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		ev, err := parse(t, []byte(`not a token`), jwt.WithKey(jwa.RS256, pubkey))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.Equal(t, jwt.StatsReasonMalformed, ev.Reason)
+		require.Empty(t, ev.Issuer, `issuer should not be known`)
+
+		ev, err = parse(t, bytes.Repeat([]byte{'a'}, 100), jwt.WithKey(jwa.RS256, pubkey), jwt.WithMaxTokenSize(10))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.Equal(t, jwt.StatsReasonMalformed, ev.Reason)
+	})
+	t.Run("decryption", func(t *testing.T) {
+		enckey, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+		wrongenckey, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+		tok, err := jwt.NewBuilder().Issuer(`https://issuer.example.com`).Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		encrypted, err := jwt.SignAndEncrypt(tok, jwt.WithKey(jwa.RS256, key), jwt.WithKey(jwa.RSA_OAEP, &enckey.PublicKey))
+		require.NoError(t, err, `jwt.SignAndEncrypt should succeed`)
+
+		ev, err := parse(t, encrypted, jwt.WithKey(jwa.RS256, pubkey), jwt.WithKey(jwa.RSA_OAEP, wrongenckey))
+		require.Error(t, err, `jwt.Parse should fail`)
+		require.Equal(t, jwt.StatsReasonDecryption, ev.Reason)
+	})
+}
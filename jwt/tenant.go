@@ -0,0 +1,171 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errUnknownTenant = errors.New(`tenant is not allowed`)
+
+// ErrUnknownTenant returns the opaque error value that is returned when
+// a `jwt.TenantDispatcher` receives a token for a tenant that has not
+// been registered. Use `errors.Is` to check for it.
+func ErrUnknownTenant() error {
+	return errUnknownTenant
+}
+
+// TenantDispatcher parses tokens issued for multiple tenants, each with
+// its own verification keys and validation rules. The tenant is
+// determined by the value of a claim in the token (usually `iss`), and
+// only tokens for tenants that have been registered are accepted:
+//
+//	d := jwt.NewTenantDispatcher(jwt.IssuerKey)
+//	d.Register(`https://idp1.example.com`, jwt.WithKeySet(set1), jwt.WithAudience(`my-api`))
+//	d.Register(`https://idp2.example.com`, jwt.WithKeyProvider(kp2), jwt.WithAcceptableSkew(time.Minute))
+//	...
+//	// for each request
+//	tok, err := d.Parse(src)
+//
+// The claim is read from the unverified token only to choose the options
+// to parse it with. The token is then verified and validated using the
+// options of that tenant, after which the claim of the verified token is
+// required to have the same value, so a token whose claim has been
+// tampered with is rejected. As the claim is not checked through a
+// validation option, tenants may also be registered using
+// `jwt.WithValidationPolicy()`.
+// Because the claim must be readable before the token is verified,
+// only signed (JWS) tokens are supported.
+//
+// A TenantDispatcher may be used from multiple goroutines concurrently,
+// including while tenants are being registered or removed.
+type TenantDispatcher struct {
+	claim string
+
+	mu      sync.RWMutex
+	tenants map[string][]ParseOption
+}
+
+// NewTenantDispatcher creates a new TenantDispatcher that routes tokens
+// using the value of the claim `claim`, which must be a string. If
+// `claim` is empty, the `iss` claim is used.
+func NewTenantDispatcher(claim string) *TenantDispatcher {
+	if claim == `` {
+		claim = IssuerKey
+	}
+	return &TenantDispatcher{
+		claim:   claim,
+		tenants: make(map[string][]ParseOption),
+	}
+}
+
+// Register adds `tenant` to the set of allowed tenants. Tokens for
+// `tenant` are parsed using `options`, which should include the options
+// to specify verification keys (such as `jwt.WithKeySet()` or
+// `jwt.WithKeyProvider()`), as well as any validation options such as
+// `jwt.WithAudience()`, `jwt.WithAcceptableSkew()`, and
+// `jwt.WithRequiredClaims()`.
+//
+// An error is returned if `tenant` is empty or has already been registered.
+func (d *TenantDispatcher) Register(tenant string, options ...ParseOption) error {
+	if tenant == `` {
+		return fmt.Errorf(`jwt.TenantDispatcher.Register: tenant must not be empty`)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.tenants[tenant]; ok {
+		return fmt.Errorf(`jwt.TenantDispatcher.Register: tenant %q is already registered`, tenant)
+	}
+
+	d.tenants[tenant] = append([]ParseOption(nil), options...)
+	return nil
+}
+
+// Remove removes `tenant` from the set of allowed tenants. It returns
+// false if `tenant` was not registered.
+func (d *TenantDispatcher) Remove(tenant string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.tenants[tenant]; !ok {
+		return false
+	}
+	delete(d.tenants, tenant)
+	return true
+}
+
+// Tenants returns the list of registered tenants, in no particular order.
+func (d *TenantDispatcher) Tenants() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	list := make([]string, 0, len(d.tenants))
+	for tenant := range d.tenants {
+		list = append(list, tenant)
+	}
+	return list
+}
+
+// Parse parses and verifies the token in `src` using the options
+// registered for its tenant. `options` are applied after those of the
+// tenant, and may be used to specify per-request options such as
+// `jwt.WithContext()`.
+//
+// If the token is for a tenant that has not been registered, the
+// returned error matches `jwt.ErrUnknownTenant()`.
+func (d *TenantDispatcher) Parse(src []byte, options ...ParseOption) (Token, error) {
+	tenant, err := d.tenantOf(src)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	registered, ok := d.tenants[tenant]
+	d.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf(`jwt.TenantDispatcher.Parse: %w: %q`, errUnknownTenant, tenant)
+	}
+
+	list := make([]ParseOption, 0, len(registered)+len(options))
+	list = append(list, registered...)
+	list = append(list, options...)
+	tok, err := Parse(src, list...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the claim that was used to choose the tenant must also hold
+	// after verification
+	var v Validator
+	if d.claim == IssuerKey {
+		v = issuerClaimValueIs(tenant)
+	} else {
+		v = ClaimValueIs(d.claim, tenant)
+	}
+	if err := v.Validate(context.Background(), tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+// ParseString is the same as Parse, but accepts a string.
+func (d *TenantDispatcher) ParseString(src string, options ...ParseOption) (Token, error) {
+	return d.Parse([]byte(src), options...)
+}
+
+// tenantOf extracts the (unverified) value of the tenant claim from `src`
+func (d *TenantDispatcher) tenantOf(src []byte) (string, error) {
+	unverified, err := ParseInsecure(src)
+	if err != nil {
+		return ``, fmt.Errorf(`jwt.TenantDispatcher.Parse: failed to parse token: %w`, err)
+	}
+	v, ok := unverified.Get(d.claim)
+	if !ok {
+		return ``, fmt.Errorf(`jwt.TenantDispatcher.Parse: %q claim is required to determine the tenant`, d.claim)
+	}
+	tenant, ok := v.(string)
+	if !ok || tenant == `` {
+		return ``, fmt.Errorf(`jwt.TenantDispatcher.Parse: %q claim must be a non-empty string`, d.claim)
+	}
+	return tenant, nil
+}
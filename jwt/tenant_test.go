@@ -0,0 +1,103 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantDispatcher(t *testing.T) {
+	t.Parallel()
+
+	key1 := []byte(`abracadabra-abracadabra-abracadabra-1`)
+	key2 := []byte(`abracadabra-abracadabra-abracadabra-2`)
+	const idp1 = `https://idp1.example.com`
+	const idp2 = `https://idp2.example.com`
+
+	sign := func(t *testing.T, claims map[string]interface{}, key []byte) []byte {
+		t.Helper()
+		tok := jwt.New()
+		for k, v := range claims {
+			require.NoError(t, tok.Set(k, v), `tok.Set should succeed`)
+		}
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+
+	t.Run("issuer", func(t *testing.T) {
+		t.Parallel()
+		d := jwt.NewTenantDispatcher(``)
+		require.NoError(t, d.Register(idp1, jwt.WithKey(jwa.HS256, key1), jwt.WithAudience(`api`)), `d.Register should succeed`)
+		require.NoError(t, d.Register(idp2, jwt.WithKey(jwa.HS256, key2)), `d.Register should succeed`)
+		require.Error(t, d.Register(idp1), `duplicate tenants should be rejected`)
+		require.Error(t, d.Register(``), `empty tenants should be rejected`)
+		require.ElementsMatch(t, []string{idp1, idp2}, d.Tenants())
+
+		tok, err := d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp1, jwt.AudienceKey: `api`}, key1))
+		require.NoError(t, err, `d.Parse should succeed`)
+		require.Equal(t, idp1, tok.Issuer())
+
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp2}, key2))
+		require.NoError(t, err, `d.Parse should succeed`)
+
+		// per-tenant validation rules
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp1, jwt.AudienceKey: `other`}, key1))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `audience of idp1 should be enforced`)
+
+		// keys of another tenant
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp2}, key1))
+		require.Error(t, err, `tokens signed with the keys of another tenant should be rejected`)
+
+		// per-call options
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp2, jwt.ExpirationKey: time.Now().Add(-time.Minute)}, key2), jwt.WithAcceptableSkew(time.Hour))
+		require.NoError(t, err, `per-call options should be applied`)
+
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: `https://evil.example.com`}, key1))
+		require.True(t, errors.Is(err, jwt.ErrUnknownTenant()), `unknown issuers should be rejected`)
+
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.SubjectKey: `alice`}, key1))
+		require.Error(t, err, `tokens without the claim should be rejected`)
+
+		require.True(t, d.Remove(idp2), `d.Remove should succeed`)
+		require.False(t, d.Remove(idp2), `d.Remove should fail for unknown tenants`)
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp2}, key2))
+		require.True(t, errors.Is(err, jwt.ErrUnknownTenant()), `removed tenants should be rejected`)
+	})
+	t.Run("validation policy", func(t *testing.T) {
+		t.Parallel()
+		policy, err := jwt.NewValidationPolicy(jwt.WithAudience(`api`))
+		require.NoError(t, err, `jwt.NewValidationPolicy should succeed`)
+
+		d := jwt.NewTenantDispatcher(``)
+		require.NoError(t, d.Register(idp1, jwt.WithKey(jwa.HS256, key1), jwt.WithValidationPolicy(policy)), `d.Register should succeed`)
+
+		tok, err := d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp1, jwt.AudienceKey: `api`}, key1))
+		require.NoError(t, err, `d.Parse should succeed`)
+		require.Equal(t, idp1, tok.Issuer())
+
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp1, jwt.AudienceKey: `other`}, key1))
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `the policy of idp1 should be enforced`)
+
+		_, err = d.Parse(sign(t, map[string]interface{}{jwt.IssuerKey: idp1, jwt.AudienceKey: `api`}, key2))
+		require.Error(t, err, `tokens signed with other keys should be rejected`)
+	})
+	t.Run("tenant claim", func(t *testing.T) {
+		t.Parallel()
+		d := jwt.NewTenantDispatcher(`tid`)
+		require.NoError(t, d.Register(`tenant-a`, jwt.WithKey(jwa.HS256, key1)), `d.Register should succeed`)
+
+		tok, err := d.ParseString(string(sign(t, map[string]interface{}{`tid`: `tenant-a`}, key1)))
+		require.NoError(t, err, `d.ParseString should succeed`)
+		v, ok := tok.Get(`tid`)
+		require.True(t, ok)
+		require.Equal(t, `tenant-a`, v)
+
+		_, err = d.Parse(sign(t, map[string]interface{}{`tid`: 1}, key1))
+		require.Error(t, err, `non-string tenant claims should be rejected`)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/lestrrat-go/jwx/v2/internal/iter"
 	"github.com/lestrrat-go/jwx/v2/internal/json"
 	"github.com/lestrrat-go/jwx/v2/internal/pool"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/claims"
 	"github.com/lestrrat-go/jwx/v2/jwt/internal/types"
 )
 
@@ -89,10 +90,26 @@ type Token interface {
 	// but it will not survive when the token goes through marshaling/unmarshaling
 	// such as `json.Marshal` and `json.Unmarshal`
 	Options() *TokenOptionSet
+
+	// Clone returns a deep copy of the token. Claim values such as maps,
+	// slices, and pointers are copied as well, so that the new token
+	// can be modified without affecting the original token
 	Clone() (Token, error)
 	Iterate(context.Context) Iterator
 	Walk(context.Context, Visitor) error
 	AsMap(context.Context) (map[string]interface{}, error)
+
+	// Equal returns true if the token contains the same claims as the
+	// argument. See `jwt.Equal()` for details
+	Equal(Token) bool
+
+	// Expired returns true if the token has expired according to the
+	// clock. See `jwt.Expired()` for details
+	Expired(Clock) bool
+
+	// RemainingValidity returns the duration until the token expires
+	// according to the clock. See `jwt.RemainingValidity()` for details
+	RemainingValidity(Clock) time.Duration
 }
 type stdToken struct {
 	mu            *sync.RWMutex
@@ -478,7 +495,11 @@ func (t stdToken) MarshalJSON() ([]byte, error) {
 	defer pool.ReleaseBytesBuffer(buf)
 	buf.WriteByte('{')
 	enc := json.NewEncoder(buf)
-	for i, pair := range t.makePairs() {
+	pairs := t.makePairs()
+	if t.options.IsEnabled(RegisteredClaimsFirst) {
+		claims.SortRegisteredFirst(pairs)
+	}
+	for i, pair := range pairs {
 		f := pair.Key.(string)
 		if i > 0 {
 			buf.WriteByte(',')
@@ -493,7 +514,7 @@ func (t stdToken) MarshalJSON() ([]byte, error) {
 			}
 			continue
 		case ExpirationKey, IssuedAtKey, NotBeforeKey:
-			enc.Encode(pair.Value.(time.Time).Unix())
+			buf.WriteString(types.NumericDate{Time: pair.Value.(time.Time)}.String())
 			continue
 		}
 		switch v := pair.Value.(type) {
@@ -26,8 +26,24 @@ const (
 	// strings when serialized to JSON.
 	FlattenAudience TokenOption = 1 << iota
 
+	// RegisteredClaimsFirst option controls the order of the claims when the
+	// token is serialized to JSON. By default, all claims are sorted by their
+	// names. If this option is enabled, the claims registered in RFC 7519
+	// ("iss", "sub", "aud", "exp", "nbf", "iat", and "jti") come first, in
+	// the order listed in the RFC, followed by the rest of the claims sorted
+	// by their names.
+	//
+	// In either case, the serialized form of a token does not depend on the
+	// order in which the claims were set, so that it can be compared against
+	// golden files or used as a test vector.
+	RegisteredClaimsFirst
+
 	// MaxPerTokenOption is a marker to denote the last value that an option can take.
 	// This value has no meaning other than to be used as a marker.
+	//
+	// New options must be appended immediately before this marker, so that the
+	// values of the existing options do not change. Re-run `go generate` after
+	// adding an option to update token_options_gen.go.
 	MaxPerTokenOption
 )
 
@@ -9,17 +9,27 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[FlattenAudience-1]
-	_ = x[MaxPerTokenOption-2]
+	_ = x[RegisteredClaimsFirst-2]
+	_ = x[MaxPerTokenOption-4]
 }
 
-const _TokenOption_name = "FlattenAudienceMaxPerTokenOption"
+const (
+	_TokenOption_name_0 = "FlattenAudienceRegisteredClaimsFirst"
+	_TokenOption_name_1 = "MaxPerTokenOption"
+)
 
-var _TokenOption_index = [...]uint8{0, 15, 32}
+var (
+	_TokenOption_index_0 = [...]uint8{0, 15, 36}
+)
 
 func (i TokenOption) String() string {
-	i -= 1
-	if i >= TokenOption(len(_TokenOption_index)-1) {
-		return "TokenOption(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _TokenOption_name_0[_TokenOption_index_0[i]:_TokenOption_index_0[i+1]]
+	case i == 4:
+		return _TokenOption_name_1
+	default:
+		return "TokenOption(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _TokenOption_name[_TokenOption_index[i]:_TokenOption_index[i+1]]
 }
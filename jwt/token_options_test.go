@@ -1,9 +1,12 @@
 package jwt_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/openid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,4 +44,33 @@ func TestTokenOptions(t *testing.T) {
 		opt2.Clear()
 		require.False(t, opt2.IsEnabled(jwt.FlattenAudience), `option FlattenAudience should be false`)
 	})
+	t.Run("RegisteredClaimsFirst", func(t *testing.T) {
+		tok, err := jwt.NewBuilder().
+			Claim(`zzz`, 1).
+			JwtID(`id`).
+			IssuedAt(time.Unix(1700000000, 0)).
+			Claim(`aaa`, map[string]interface{}{`y`: 1, `x`: 2}).
+			Subject(`alice`).
+			Issuer(`https://example.com`).
+			Audience([]string{`bob`}).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+		buf, err := json.Marshal(tok)
+		require.NoError(t, err, `json.Marshal should succeed`)
+		require.Equal(t, `{"aaa":{"x":2,"y":1},"aud":["bob"],"iat":1700000000,"iss":"https://example.com","jti":"id","sub":"alice","zzz":1}`, string(buf))
+
+		tok.Options().Enable(jwt.RegisteredClaimsFirst)
+		buf, err = json.Marshal(tok)
+		require.NoError(t, err, `json.Marshal should succeed`)
+		require.Equal(t, `{"iss":"https://example.com","sub":"alice","aud":["bob"],"iat":1700000000,"jti":"id","aaa":{"x":2,"y":1},"zzz":1}`, string(buf))
+
+		idt := openid.New()
+		idt.Options().Enable(jwt.RegisteredClaimsFirst)
+		require.NoError(t, idt.Set(openid.EmailKey, `alice@example.com`), `Set should succeed`)
+		require.NoError(t, idt.Set(openid.SubjectKey, `alice`), `Set should succeed`)
+		buf, err = json.Marshal(idt)
+		require.NoError(t, err, `json.Marshal should succeed`)
+		require.Equal(t, `{"sub":"alice","email":"alice@example.com"}`, string(buf))
+	})
 }
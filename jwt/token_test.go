@@ -10,6 +10,7 @@ import (
 
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -286,3 +287,77 @@ func TestToken(t *testing.T) {
 		}
 	})
 }
+
+func TestBuilder(t *testing.T) {
+	t.Run("valid claims", func(t *testing.T) {
+		exp := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+		tok, err := jwt.NewBuilder().
+			Issuer(`github.com/lestrrat-go/jwx`).
+			Subject(`alice`).
+			Audience([]string{`users`}).
+			Expiration(exp).
+			Claim(`scope`, `read`).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+		require.Equal(t, `github.com/lestrrat-go/jwx`, tok.Issuer(), `iss should match`)
+		require.Equal(t, `alice`, tok.Subject(), `sub should match`)
+		require.Equal(t, []string{`users`}, tok.Audience(), `aud should match`)
+		require.True(t, exp.Equal(tok.Expiration()), `exp should match`)
+
+		v, ok := tok.Get(`scope`)
+		require.True(t, ok, `scope should exist`)
+		require.Equal(t, `read`, v, `scope should match`)
+	})
+	t.Run("invalid claims", func(t *testing.T) {
+		_, err := jwt.NewBuilder().
+			Subject(`alice`).
+			Claim(jwt.ExpirationKey, []string{`tomorrow`}).
+			Claim(jwt.IssuerKey, 1).
+			Build()
+		require.Error(t, err, `Build should fail`)
+		require.Contains(t, err.Error(), `"exp"`, `error should mention "exp"`)
+		require.Contains(t, err.Error(), `"iss"`, `error should mention "iss"`)
+	})
+}
+
+func TestClone(t *testing.T) {
+	tok, err := jwt.NewBuilder().
+		Subject(`alice`).
+		Audience([]string{`users`, `admins`}).
+		Claim(`scopes`, []interface{}{`read`, `write`}).
+		Claim(`ext`, map[string]interface{}{
+			`tenant`: `acme`,
+			`groups`: []string{`a`, `b`},
+		}).
+		Build()
+	require.NoError(t, err, `Build should succeed`)
+	tok.Options().Enable(jwt.FlattenAudience)
+
+	cloned, err := tok.Clone()
+	require.NoError(t, err, `tok.Clone should succeed`)
+	require.True(t, jwt.Equal(tok, cloned), `tokens should be equal`)
+	require.True(t, cloned.Options().IsEnabled(jwt.FlattenAudience), `options should be copied`)
+
+	// Modify the values in the clone in place
+	cloned.Audience()[0] = `guests`
+
+	v, _ := cloned.Get(`scopes`)
+	v.([]interface{})[0] = `delete`
+
+	v, _ = cloned.Get(`ext`)
+	ext := v.(map[string]interface{})
+	ext[`tenant`] = `evil`
+	ext[`groups`].([]string)[0] = `root`
+
+	// ... and make sure that the original token is not affected
+	require.Equal(t, []string{`users`, `admins`}, tok.Audience(), `aud should not change`)
+
+	v, _ = tok.Get(`scopes`)
+	require.Equal(t, []interface{}{`read`, `write`}, v, `scopes should not change`)
+
+	v, _ = tok.Get(`ext`)
+	require.Equal(t, map[string]interface{}{
+		`tenant`: `acme`,
+		`groups`: []string{`a`, `b`},
+	}, v, `ext should not change`)
+}
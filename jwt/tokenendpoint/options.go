@@ -0,0 +1,72 @@
+package tokenendpoint
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/clientassertion"
+	"github.com/lestrrat-go/option"
+)
+
+// Option describes an option that can be passed to `NewClient()`.
+type Option = option.Interface
+
+type identHTTPClient struct{}
+type identPrivateKey struct{}
+type identClientSecret struct{}
+type identClientSecretJWT struct{}
+type identAssertionAudience struct{}
+type identAccessTokenParseOptions struct{}
+type identIDTokenParseOptions struct{}
+
+type privateKey struct {
+	key     interface{}
+	options []clientassertion.Option
+}
+
+// WithHTTPClient specifies the HTTP client used to query the token
+// endpoint. By default http.DefaultClient is used.
+func WithHTTPClient(v HTTPClient) Option {
+	return option.New(identHTTPClient{}, v)
+}
+
+// WithPrivateKey specifies that the client authenticates using the
+// `private_key_jwt` method, with assertions signed by `key` (see
+// `clientassertion.PrivateKeyJWT()`). `options` are passed to
+// `clientassertion.PrivateKeyJWT()` as is.
+func WithPrivateKey(key interface{}, options ...clientassertion.Option) Option {
+	return option.New(identPrivateKey{}, privateKey{key: key, options: options})
+}
+
+// WithClientSecret specifies that the client authenticates using the
+// `client_secret_basic` method, i.e. HTTP basic authentication.
+func WithClientSecret(v string) Option {
+	return option.New(identClientSecret{}, v)
+}
+
+// WithClientSecretJWT specifies that the client authenticates using the
+// `client_secret_jwt` method, with assertions signed using HMAC with
+// the client secret `v` (see `clientassertion.ClientSecretJWT()`).
+func WithClientSecretJWT(v []byte) Option {
+	return option.New(identClientSecretJWT{}, v)
+}
+
+// WithAssertionAudience specifies the `aud` claim of the client
+// authentication assertions. By default the URL of the token endpoint
+// is used.
+func WithAssertionAudience(v string) Option {
+	return option.New(identAssertionAudience{}, v)
+}
+
+// WithAccessTokenParseOptions specifies that access tokens are JWTs, and
+// are parsed using `jwt.Parse()` with `options`. The result is stored in
+// the `ParsedAccessToken` field of the Response.
+func WithAccessTokenParseOptions(options ...jwt.ParseOption) Option {
+	return option.New(identAccessTokenParseOptions{}, options)
+}
+
+// WithIDTokenParseOptions specifies that ID tokens in the response are
+// parsed using `jwt.Parse()` with `options`. The result is stored in the
+// `ParsedIDToken` field of the Response. The `aud` claim is required to
+// contain the client ID (OpenID Connect Core 1.0 Section 3.1.3.7).
+func WithIDTokenParseOptions(options ...jwt.ParseOption) Option {
+	return option.New(identIDTokenParseOptions{}, options)
+}
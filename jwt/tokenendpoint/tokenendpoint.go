@@ -0,0 +1,377 @@
+// Package tokenendpoint implements a client for the OAuth 2.0 token
+// endpoint (RFC 6749 Section 3.2) that uses JWT assertions.
+//
+// The client authenticates using the `private_key_jwt` or
+// `client_secret_jwt` methods, with assertions created by the
+// `jwt/clientassertion` package, and can use locally minted JWTs as
+// authorization grants (RFC 7523 Section 2.1):
+//
+//	cl, err := tokenendpoint.NewClient(tokenURL, clientID,
+//	  tokenendpoint.WithPrivateKey(key),
+//	  tokenendpoint.WithAccessTokenParseOptions(jwt.WithKeySetProvider(provider)),
+//	)
+//	if err != nil {
+//	  ...
+//	}
+//	res, err := cl.ClientCredentials(ctx, `read`, `write`)
+//	if err != nil {
+//	  ...
+//	}
+//	// res.AccessToken and res.ParsedAccessToken are available
+//
+// Errors returned by the token endpoint (RFC 6749 Section 5.2) are
+// reported as `*tokenendpoint.Error`.
+package tokenendpoint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/clientassertion"
+)
+
+// Grant types
+const (
+	GrantTypeClientCredentials = `client_credentials`
+	GrantTypeJWTBearer         = `urn:ietf:params:oauth:grant-type:jwt-bearer`
+)
+
+// Names of the parameters in the token request and response
+// (RFC 6749 Sections 4.4 and 5.1, OpenID Connect Core 1.0 Section 3.1.3.3)
+const (
+	GrantTypeKey    = `grant_type`
+	AssertionKey    = `assertion`
+	ScopeKey        = `scope`
+	ClientIDKey     = `client_id`
+	AccessTokenKey  = `access_token`
+	TokenTypeKey    = `token_type`
+	ExpiresInKey    = `expires_in`
+	RefreshTokenKey = `refresh_token`
+	IDTokenKey      = `id_token`
+)
+
+// maxResponseSize is the maximum size of the token response that is read
+const maxResponseSize = 1 << 20
+
+// HTTPClient is the interface of the HTTP client used to query
+// the token endpoint. *http.Client satisfies this interface.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Error represents an error response from the token endpoint
+// (RFC 6749 Section 5.2).
+type Error struct {
+	// Status is the HTTP status code of the response
+	Status int
+	// Code is the value of the "error" member, e.g. "invalid_client"
+	Code        string
+	Description string
+	URI         string
+}
+
+func (e *Error) Error() string {
+	if e.Description != `` {
+		return fmt.Sprintf(`tokenendpoint: %s: %s`, e.Code, e.Description)
+	}
+	return `tokenendpoint: ` + e.Code
+}
+
+// Response represents a successful response from the token endpoint.
+type Response struct {
+	AccessToken  string
+	TokenType    string
+	ExpiresIn    time.Duration
+	RefreshToken string
+	// Scope is the scope of the access token. It is empty if the
+	// authorization server granted the requested scope as is.
+	Scope   string
+	IDToken string
+	// ParsedAccessToken is the parsed access token. It is nil unless
+	// `WithAccessTokenParseOptions()` is specified.
+	ParsedAccessToken jwt.Token
+	// ParsedIDToken is the parsed ID token. It is nil unless
+	// `WithIDTokenParseOptions()` is specified, and the response
+	// contained an ID token.
+	ParsedIDToken jwt.Token
+	// Extra contains the members of the response other than the above
+	Extra map[string]interface{}
+}
+
+// Client queries a token endpoint.
+type Client struct {
+	endpoint          string
+	clientID          string
+	httpcl            HTTPClient
+	secret            string
+	assertion         func() ([]byte, error)
+	accessTokenParse  []jwt.ParseOption
+	idTokenParse      []jwt.ParseOption
+	parseAccessTokens bool
+	parseIDTokens     bool
+}
+
+// NewClient creates a new Client that queries the token endpoint at
+// `endpoint` on behalf of the client `clientID`.
+//
+// The client authentication method is specified by one of
+// `WithPrivateKey()`, `WithClientSecretJWT()`, or `WithClientSecret()`.
+// If none is specified, the client is treated as a public client, and
+// only identifies itself using the `client_id` parameter.
+func NewClient(endpoint, clientID string, options ...Option) (*Client, error) {
+	if endpoint == `` || clientID == `` {
+		return nil, fmt.Errorf(`tokenendpoint.NewClient: endpoint and client ID must be specified`)
+	}
+
+	c := &Client{
+		endpoint: endpoint,
+		clientID: clientID,
+		httpcl:   http.DefaultClient,
+	}
+	audience := endpoint
+	var pk *privateKey
+	var hmacSecret []byte
+	var methods int
+	for _, option := range options {
+		//nolint:forcetypeassert
+		switch option.Ident() {
+		case identHTTPClient{}:
+			c.httpcl = option.Value().(HTTPClient)
+		case identPrivateKey{}:
+			v := option.Value().(privateKey)
+			pk = &v
+			methods++
+		case identClientSecretJWT{}:
+			hmacSecret = option.Value().([]byte)
+			methods++
+		case identClientSecret{}:
+			c.secret = option.Value().(string)
+			methods++
+		case identAssertionAudience{}:
+			audience = option.Value().(string)
+		case identAccessTokenParseOptions{}:
+			c.parseAccessTokens = true
+			c.accessTokenParse = option.Value().([]jwt.ParseOption)
+		case identIDTokenParseOptions{}:
+			c.parseIDTokens = true
+			c.idTokenParse = option.Value().([]jwt.ParseOption)
+		default:
+			return nil, fmt.Errorf(`tokenendpoint.NewClient: invalid option %T`, option)
+		}
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf(`tokenendpoint.NewClient: only one client authentication method may be specified`)
+	}
+
+	switch {
+	case pk != nil:
+		c.assertion = func() ([]byte, error) {
+			return clientassertion.PrivateKeyJWT(clientID, audience, pk.key, pk.options...)
+		}
+		// fail early if the key cannot be used
+		if _, err := c.assertion(); err != nil {
+			return nil, fmt.Errorf(`tokenendpoint.NewClient: %w`, err)
+		}
+	case hmacSecret != nil:
+		c.assertion = func() ([]byte, error) {
+			return clientassertion.ClientSecretJWT(clientID, audience, hmacSecret)
+		}
+		if _, err := c.assertion(); err != nil {
+			return nil, fmt.Errorf(`tokenendpoint.NewClient: %w`, err)
+		}
+	}
+	return c, nil
+}
+
+// ClientCredentials requests an access token using the client
+// credentials grant (RFC 6749 Section 4.4), i.e. on behalf of the
+// client itself.
+func (c *Client) ClientCredentials(ctx context.Context, scopes ...string) (*Response, error) {
+	form := url.Values{}
+	form.Set(GrantTypeKey, GrantTypeClientCredentials)
+	setScopes(form, scopes)
+	return c.Exchange(ctx, form)
+}
+
+// JWTBearer requests an access token using the signed JWT `assertion`
+// as an authorization grant (RFC 7523 Section 2.1). The assertion is
+// usually created using `jwt.Sign()`, with the subject for whom the
+// access token is requested in the `sub` claim.
+func (c *Client) JWTBearer(ctx context.Context, assertion []byte, scopes ...string) (*Response, error) {
+	if len(assertion) == 0 {
+		return nil, fmt.Errorf(`tokenendpoint: assertion must not be empty`)
+	}
+	form := url.Values{}
+	form.Set(GrantTypeKey, GrantTypeJWTBearer)
+	form.Set(AssertionKey, string(assertion))
+	setScopes(form, scopes)
+	return c.Exchange(ctx, form)
+}
+
+// Exchange sends a token request with the parameters in `form`, to
+// which the client authentication parameters are added, and parses the
+// response. It can be used for grant types that do not have a dedicated
+// method.
+func (c *Client) Exchange(ctx context.Context, form url.Values) (*Response, error) {
+	params := make(url.Values, len(form)+3)
+	for k, v := range form {
+		params[k] = v
+	}
+	if c.assertion != nil {
+		assertion, err := c.assertion()
+		if err != nil {
+			return nil, fmt.Errorf(`tokenendpoint: failed to create client assertion: %w`, err)
+		}
+		for k, v := range clientassertion.Params(assertion) {
+			params[k] = v
+		}
+	}
+	if c.secret == `` {
+		params.Set(ClientIDKey, c.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf(`tokenendpoint: failed to create request: %w`, err)
+	}
+	req.Header.Set(`Content-Type`, `application/x-www-form-urlencoded`)
+	req.Header.Set(`Accept`, `application/json`)
+	if c.secret != `` {
+		// RFC 6749 Section 2.3.1 requires the credentials to be
+		// form-urlencoded before they are used for basic authentication
+		req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.secret))
+	}
+
+	res, err := c.httpcl.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(`tokenendpoint: failed to query %q: %w`, c.endpoint, err)
+	}
+	defer res.Body.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf(`tokenendpoint: failed to read response: %w`, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, parseError(res.StatusCode, buf)
+	}
+	return c.parseResponse(buf)
+}
+
+// TokenSource returns a `jwt.TokenSource` that provides access tokens
+// obtained using the client credentials grant, for example to be used
+// with `jwthttp.NewTransport()`. A new access token is requested on
+// each call, so wrap it using `jwt.NewReuseTokenSource()` if the access
+// tokens are JWTs that may be reused until they expire.
+func (c *Client) TokenSource(scopes ...string) jwt.TokenSource {
+	return jwt.TokenSourceFunc(func(ctx context.Context) ([]byte, error) {
+		res, err := c.ClientCredentials(ctx, scopes...)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(res.AccessToken), nil
+	})
+}
+
+func setScopes(form url.Values, scopes []string) {
+	if len(scopes) > 0 {
+		form.Set(ScopeKey, strings.Join(scopes, ` `))
+	}
+}
+
+func parseError(status int, buf []byte) error {
+	var v struct {
+		Code        string `json:"error"`
+		Description string `json:"error_description"`
+		URI         string `json:"error_uri"`
+	}
+	if err := json.Unmarshal(buf, &v); err != nil || v.Code == `` {
+		return fmt.Errorf(`tokenendpoint: unexpected status code %d`, status)
+	}
+	return &Error{
+		Status:      status,
+		Code:        v.Code,
+		Description: v.Description,
+		URI:         v.URI,
+	}
+}
+
+func (c *Client) parseResponse(buf []byte) (*Response, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf(`tokenendpoint: failed to parse response: %w`, err)
+	}
+
+	var res Response
+	for k, v := range m {
+		switch k {
+		case AccessTokenKey, TokenTypeKey, RefreshTokenKey, ScopeKey, IDTokenKey:
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf(`tokenendpoint: invalid response: %q must be a string`, k)
+			}
+			switch k {
+			case AccessTokenKey:
+				res.AccessToken = s
+			case TokenTypeKey:
+				res.TokenType = s
+			case RefreshTokenKey:
+				res.RefreshToken = s
+			case ScopeKey:
+				res.Scope = s
+			case IDTokenKey:
+				res.IDToken = s
+			}
+		case ExpiresInKey:
+			var n float64
+			switch v := v.(type) {
+			case float64:
+				n = v
+			case json.Number:
+				f, err := v.Float64()
+				if err != nil {
+					return nil, fmt.Errorf(`tokenendpoint: invalid response: %q must be a number: %w`, k, err)
+				}
+				n = f
+			default:
+				return nil, fmt.Errorf(`tokenendpoint: invalid response: %q must be a number`, k)
+			}
+			res.ExpiresIn = time.Duration(n) * time.Second
+		default:
+			if res.Extra == nil {
+				res.Extra = make(map[string]interface{})
+			}
+			res.Extra[k] = v
+		}
+	}
+	if res.AccessToken == `` {
+		return nil, fmt.Errorf(`tokenendpoint: invalid response: %q is missing`, AccessTokenKey)
+	}
+	if res.TokenType == `` {
+		return nil, fmt.Errorf(`tokenendpoint: invalid response: %q is missing`, TokenTypeKey)
+	}
+
+	if c.parseAccessTokens {
+		tok, err := jwt.ParseString(res.AccessToken, c.accessTokenParse...)
+		if err != nil {
+			return nil, fmt.Errorf(`tokenendpoint: failed to parse access token: %w`, err)
+		}
+		res.ParsedAccessToken = tok
+	}
+	if c.parseIDTokens && res.IDToken != `` {
+		options := append(append([]jwt.ParseOption(nil), c.idTokenParse...), jwt.WithAudience(c.clientID))
+		tok, err := jwt.ParseString(res.IDToken, options...)
+		if err != nil {
+			return nil, fmt.Errorf(`tokenendpoint: failed to parse ID token: %w`, err)
+		}
+		res.ParsedIDToken = tok
+	}
+	return &res, nil
+}
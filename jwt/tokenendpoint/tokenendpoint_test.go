@@ -0,0 +1,185 @@
+package tokenendpoint_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/clientassertion"
+	"github.com/lestrrat-go/jwx/v2/jwt/tokenendpoint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	clientKey, err := jwxtest.GenerateEcdsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+	clientPub, err := clientKey.PublicKey()
+	require.NoError(t, err, `clientKey.PublicKey should succeed`)
+	clientKeys := jwk.NewSet()
+	require.NoError(t, clientKeys.AddKey(clientPub), `clientKeys.AddKey should succeed`)
+
+	serverKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	var srv *httptest.Server
+	var verifier *clientassertion.Verifier
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError := func(status int, code string) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":%q,"error_description":"rejected"}`, code)
+		}
+		if err := r.ParseForm(); err != nil {
+			writeError(http.StatusBadRequest, `invalid_request`)
+			return
+		}
+		client, err := verifier.VerifyParams(r.Context(), r.PostForm)
+		if err != nil {
+			writeError(http.StatusUnauthorized, `invalid_client`)
+			return
+		}
+
+		sub := client.Subject()
+		switch r.PostForm.Get(tokenendpoint.GrantTypeKey) {
+		case tokenendpoint.GrantTypeClientCredentials:
+		case tokenendpoint.GrantTypeJWTBearer:
+			grant, err := verifier.VerifyAuthorizationGrant(r.Context(), []byte(r.PostForm.Get(tokenendpoint.AssertionKey)))
+			if err != nil {
+				writeError(http.StatusBadRequest, `invalid_grant`)
+				return
+			}
+			sub = grant.Subject()
+		default:
+			writeError(http.StatusBadRequest, `unsupported_grant_type`)
+			return
+		}
+
+		tok, err := jwt.NewBuilder().
+			Issuer(srv.URL).
+			Subject(sub).
+			// always issued to "client", so that other clients receive
+			// ID tokens with a mismatching audience
+			Audience([]string{`client`}).
+			Expiration(time.Now().Add(time.Hour)).
+			Claim(`scope`, r.PostForm.Get(tokenendpoint.ScopeKey)).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, serverKey))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		w.Header().Set(`Content-Type`, `application/json`)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			`access_token`: string(signed),
+			`token_type`:   `Bearer`,
+			`expires_in`:   3600,
+			`id_token`:     string(signed),
+			`issued_at`:    `now`,
+		})
+	}))
+	defer srv.Close()
+
+	verifier, err = clientassertion.NewVerifier(srv.URL, func(_ context.Context, issuer string) (jwk.Set, error) {
+		switch issuer {
+		case `client`, `other`, `trusted-issuer`:
+		default:
+			return nil, fmt.Errorf(`unknown issuer %q`, issuer)
+		}
+		return clientKeys, nil
+	}, jwt.NewMemoryReplayStore(time.Hour))
+	require.NoError(t, err, `clientassertion.NewVerifier should succeed`)
+
+	cl, err := tokenendpoint.NewClient(srv.URL, `client`,
+		tokenendpoint.WithPrivateKey(clientKey),
+		tokenendpoint.WithAccessTokenParseOptions(jwt.WithKey(jwa.RS256, &serverKey.PublicKey)),
+		tokenendpoint.WithIDTokenParseOptions(jwt.WithKey(jwa.RS256, &serverKey.PublicKey)),
+	)
+	require.NoError(t, err, `tokenendpoint.NewClient should succeed`)
+
+	t.Run("ClientCredentials", func(t *testing.T) {
+		res, err := cl.ClientCredentials(ctx, `read`, `write`)
+		require.NoError(t, err, `ClientCredentials should succeed`)
+		require.Equal(t, `Bearer`, res.TokenType)
+		require.Equal(t, time.Hour, res.ExpiresIn)
+		require.Equal(t, `client`, res.ParsedAccessToken.Subject())
+		require.Equal(t, `client`, res.ParsedIDToken.Subject())
+		require.Equal(t, map[string]interface{}{`issued_at`: `now`}, res.Extra)
+
+		scope, ok := res.ParsedAccessToken.Get(`scope`)
+		require.True(t, ok, `scope should exist`)
+		require.Equal(t, `read write`, scope)
+	})
+	t.Run("JWTBearer", func(t *testing.T) {
+		grant, err := jwt.NewBuilder().
+			Issuer(`trusted-issuer`).
+			Subject(`alice`).
+			Audience([]string{srv.URL}).
+			JwtID(`grant-1`).
+			IssuedAt(time.Now()).
+			Expiration(time.Now().Add(time.Minute)).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+		signed, err := jwt.Sign(grant, jwt.WithKey(jwa.ES256, clientKey))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		res, err := cl.JWTBearer(ctx, signed)
+		require.NoError(t, err, `JWTBearer should succeed`)
+		require.Equal(t, `alice`, res.ParsedAccessToken.Subject())
+
+		_, err = cl.JWTBearer(ctx, signed)
+		var terr *tokenendpoint.Error
+		require.True(t, errors.As(err, &terr), `error should be *tokenendpoint.Error`)
+		require.Equal(t, `invalid_grant`, terr.Code, `assertions should not be replayed`)
+		require.Equal(t, http.StatusBadRequest, terr.Status)
+	})
+	t.Run("TokenSource", func(t *testing.T) {
+		tok, err := cl.TokenSource().Token(ctx)
+		require.NoError(t, err, `Token should succeed`)
+		parsed, err := jwt.Parse(tok, jwt.WithKey(jwa.RS256, &serverKey.PublicKey))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `client`, parsed.Subject())
+	})
+	t.Run("invalid client", func(t *testing.T) {
+		other, err := jwxtest.GenerateEcdsaJwk()
+		require.NoError(t, err, `jwxtest.GenerateEcdsaJwk should succeed`)
+		cl, err := tokenendpoint.NewClient(srv.URL, `client`, tokenendpoint.WithPrivateKey(other))
+		require.NoError(t, err, `tokenendpoint.NewClient should succeed`)
+
+		_, err = cl.ClientCredentials(ctx)
+		var terr *tokenendpoint.Error
+		require.True(t, errors.As(err, &terr), `error should be *tokenendpoint.Error`)
+		require.Equal(t, `invalid_client`, terr.Code)
+	})
+	t.Run("invalid ID token audience", func(t *testing.T) {
+		// the ID token is issued to "client", not "other"
+		cl, err := tokenendpoint.NewClient(srv.URL, `other`,
+			tokenendpoint.WithPrivateKey(clientKey),
+			tokenendpoint.WithAssertionAudience(srv.URL),
+			tokenendpoint.WithIDTokenParseOptions(jwt.WithKey(jwa.RS256, &serverKey.PublicKey)),
+		)
+		require.NoError(t, err, `tokenendpoint.NewClient should succeed`)
+		_, err = cl.ClientCredentials(ctx)
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `error should be jwt.ErrInvalidAudience`)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := tokenendpoint.NewClient(``, `client`)
+		require.Error(t, err, `endpoint should be required`)
+		_, err = tokenendpoint.NewClient(srv.URL, `client`,
+			tokenendpoint.WithPrivateKey(clientKey),
+			tokenendpoint.WithClientSecret(`secret`),
+		)
+		require.Error(t, err, `multiple authentication methods should be rejected`)
+		_, err = tokenendpoint.NewClient(srv.URL, `client`, tokenendpoint.WithPrivateKey(clientPub))
+		require.Error(t, err, `public keys should be rejected`)
+	})
+}
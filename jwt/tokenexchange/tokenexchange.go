@@ -0,0 +1,262 @@
+// Package tokenexchange implements the JWT claims defined for OAuth 2.0
+// Token Exchange (RFC 8693): `act` (actor) and `may_act` (authorized actor).
+//
+// Use `tokenexchange.ActorOf()` and `tokenexchange.MayActOf()` to read the
+// claims, `tokenexchange.CanAct()` to check whether a party is authorized to
+// act on behalf of the subject, and `tokenexchange.Delegate()` to build the
+// claims of a delegation token.
+package tokenexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Names of the claims defined in RFC 8693 Section 4
+const (
+	ActorKey    = `act`
+	MayActKey   = `may_act`
+	ScopeKey    = `scope`
+	ClientIDKey = `client_id`
+)
+
+// maxActorChainLen limits the nesting of `act` claims
+const maxActorChainLen = 32
+
+var errActorNotAllowed = jwt.NewValidationError(errors.New(`actor is not authorized to act on behalf of the subject`))
+
+// ErrActorNotAllowed returns the immutable error used when the `may_act`
+// claim of the subject token does not authorize the actor.
+func ErrActorNotAllowed() jwt.ValidationError {
+	return errActorNotAllowed
+}
+
+// Actor represents the value of the `act` or `may_act` claim. Subject
+// and Issuer identify the party, and Actor is the prior actor in the
+// delegation chain, if any. Other claims that identify the party
+// (e.g. `client_id`) are stored in Claims.
+//
+// RFC 8693 does not define nested actors for `may_act`, so Actor is
+// always nil for well-formed `may_act` claims.
+type Actor struct {
+	Subject string
+	Issuer  string
+	Actor   *Actor
+	Claims  map[string]interface{}
+}
+
+// Chain returns the delegation chain starting from `a`, i.e. the current
+// actor first, followed by the prior actors.
+func (a *Actor) Chain() []*Actor {
+	var chain []*Actor
+	for cur := a; cur != nil; cur = cur.Actor {
+		chain = append(chain, cur)
+	}
+	return chain
+}
+
+// Matches returns true if `a` identifies the party with the subject
+// `sub` issued by `iss`. If the issuer of `a` is not specified, only the
+// subject is compared.
+func (a *Actor) Matches(sub, iss string) bool {
+	if a.Subject != sub {
+		return false
+	}
+	return a.Issuer == `` || a.Issuer == iss
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a *Actor) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(a.Claims)+3)
+	for k, v := range a.Claims {
+		m[k] = v
+	}
+	m[jwt.SubjectKey] = a.Subject
+	if a.Issuer != `` {
+		m[jwt.IssuerKey] = a.Issuer
+	}
+	if a.Actor != nil {
+		m[ActorKey] = a.Actor
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Actor) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	parsed, err := actorFromMap(m, 0)
+	if err != nil {
+		return err
+	}
+	*a = *parsed
+	return nil
+}
+
+func actorFromMap(m map[string]interface{}, depth int) (*Actor, error) {
+	if depth >= maxActorChainLen {
+		return nil, fmt.Errorf(`actor chain is too long`)
+	}
+
+	var a Actor
+	for k, v := range m {
+		switch k {
+		case jwt.SubjectKey:
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf(`%q must be a string`, jwt.SubjectKey)
+			}
+			a.Subject = s
+		case jwt.IssuerKey:
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf(`%q must be a string`, jwt.IssuerKey)
+			}
+			a.Issuer = s
+		case ActorKey:
+			nested, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(`nested %q must be a JSON object`, ActorKey)
+			}
+			prior, err := actorFromMap(nested, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			a.Actor = prior
+		default:
+			if a.Claims == nil {
+				a.Claims = make(map[string]interface{})
+			}
+			a.Claims[k] = v
+		}
+	}
+	if a.Subject == `` {
+		return nil, fmt.Errorf(`%q is required`, jwt.SubjectKey)
+	}
+	return &a, nil
+}
+
+// ActorOf returns the value of the `act` claim of `t`, or nil if `t`
+// does not have one.
+func ActorOf(t jwt.Token) (*Actor, error) {
+	a, err := actorClaim(t, ActorKey)
+	if err != nil {
+		return nil, fmt.Errorf(`tokenexchange.ActorOf: %w`, err)
+	}
+	return a, nil
+}
+
+// MayActOf returns the value of the `may_act` claim of `t`, or nil if `t`
+// does not have one.
+func MayActOf(t jwt.Token) (*Actor, error) {
+	a, err := actorClaim(t, MayActKey)
+	if err != nil {
+		return nil, fmt.Errorf(`tokenexchange.MayActOf: %w`, err)
+	}
+	return a, nil
+}
+
+func actorClaim(t jwt.Token, name string) (*Actor, error) {
+	v, ok := t.Get(name)
+	if !ok {
+		return nil, nil
+	}
+
+	switch v := v.(type) {
+	case *Actor:
+		return v, nil
+	case map[string]interface{}:
+		a, err := actorFromMap(v, 0)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid %q claim: %w`, name, err)
+		}
+		return a, nil
+	default:
+		return nil, fmt.Errorf(`invalid %q claim (%T)`, name, v)
+	}
+}
+
+// Validator returns a `jwt.Validator` that checks that the `act` and
+// `may_act` claims, if present, are well-formed: each of them (and each
+// actor in the delegation chain) must be a JSON object with a `sub` claim.
+func Validator() jwt.Validator {
+	return jwt.ValidatorFunc(validateClaims)
+}
+
+func validateClaims(_ context.Context, t jwt.Token) jwt.ValidationError {
+	for _, name := range []string{ActorKey, MayActKey} {
+		if _, err := actorClaim(t, name); err != nil {
+			return jwt.NewValidationError(err)
+		}
+	}
+	return nil
+}
+
+// CanAct checks whether the party identified by `actor` (typically the
+// actor token of a token exchange request) may act on behalf of the
+// subject of `subject`, based on the `may_act` claim of `subject`
+// (RFC 8693 Section 4.4). `tokenexchange.ErrActorNotAllowed()` is returned
+// if `subject` does not have a `may_act` claim, or if it does not match
+// the `sub` and `iss` claims of `actor`.
+func CanAct(subject, actor jwt.Token) error {
+	mayAct, err := MayActOf(subject)
+	if err != nil {
+		return fmt.Errorf(`tokenexchange.CanAct: %w`, err)
+	}
+	if mayAct == nil || !mayAct.Matches(actor.Subject(), actor.Issuer()) {
+		return errActorNotAllowed
+	}
+	return nil
+}
+
+// Delegate returns a new token that has the claims of `subject`, with the
+// `act` claim identifying the party in `actor` as the current actor. If
+// `subject` already has an `act` claim, it becomes the prior actor in the
+// delegation chain (RFC 8693 Section 4.1). The `may_act` claim is removed.
+//
+// Delegate does not perform any authorization checks. Use
+// `tokenexchange.CanAct()` to apply the `may_act` claim if necessary.
+// The returned token should have its `iss`, `aud`, `exp` and other claims
+// updated before being signed.
+func Delegate(subject, actor jwt.Token) (jwt.Token, error) {
+	if actor.Subject() == `` {
+		return nil, fmt.Errorf(`tokenexchange.Delegate: actor token must have a %q claim`, jwt.SubjectKey)
+	}
+
+	prior, err := ActorOf(subject)
+	if err != nil {
+		return nil, fmt.Errorf(`tokenexchange.Delegate: %w`, err)
+	}
+	if prior != nil && len(prior.Chain()) >= maxActorChainLen {
+		return nil, fmt.Errorf(`tokenexchange.Delegate: actor chain is too long`)
+	}
+
+	act := &Actor{
+		Subject: actor.Subject(),
+		Issuer:  actor.Issuer(),
+		Actor:   prior,
+	}
+	if v, ok := actor.Get(ClientIDKey); ok {
+		act.Claims = map[string]interface{}{ClientIDKey: v}
+	}
+
+	tok, err := subject.Clone()
+	if err != nil {
+		return nil, fmt.Errorf(`tokenexchange.Delegate: failed to clone token: %w`, err)
+	}
+	if err := tok.Set(ActorKey, act); err != nil {
+		return nil, fmt.Errorf(`tokenexchange.Delegate: failed to set %q claim: %w`, ActorKey, err)
+	}
+	if _, ok := tok.Get(MayActKey); ok {
+		if err := tok.Remove(MayActKey); err != nil {
+			return nil, fmt.Errorf(`tokenexchange.Delegate: failed to remove %q claim: %w`, MayActKey, err)
+		}
+	}
+	return tok, nil
+}
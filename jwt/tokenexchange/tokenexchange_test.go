@@ -0,0 +1,123 @@
+package tokenexchange_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/tokenexchange"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActor(t *testing.T) {
+	// Example from RFC 8693 Section 4.1
+	const src = `{
+  "aud":"https://consumer.example.com",
+  "iss":"https://issuer.example.com",
+  "exp":1443904177,
+  "nbf":1443904077,
+  "sub":"user@example.com",
+  "act":
+  {
+    "sub":"https://service16.example.com",
+    "act":
+    {
+      "sub":"https://service77.example.com"
+    }
+  }
+}`
+
+	tok, err := jwt.ParseInsecure([]byte(src))
+	require.NoError(t, err, `jwt.ParseInsecure should succeed`)
+
+	act, err := tokenexchange.ActorOf(tok)
+	require.NoError(t, err, `tokenexchange.ActorOf should succeed`)
+	require.NotNil(t, act, `act should exist`)
+	chain := act.Chain()
+	require.Len(t, chain, 2)
+	require.Equal(t, `https://service16.example.com`, chain[0].Subject)
+	require.Equal(t, `https://service77.example.com`, chain[1].Subject)
+
+	mayAct, err := tokenexchange.MayActOf(tok)
+	require.NoError(t, err, `tokenexchange.MayActOf should succeed`)
+	require.Nil(t, mayAct, `may_act should not exist`)
+
+	buf, err := json.Marshal(act)
+	require.NoError(t, err, `json.Marshal should succeed`)
+	var decoded tokenexchange.Actor
+	require.NoError(t, json.Unmarshal(buf, &decoded), `json.Unmarshal should succeed`)
+	require.Equal(t, act, &decoded)
+
+	t.Run("invalid claims", func(t *testing.T) {
+		for _, v := range []interface{}{
+			`service16`,
+			map[string]interface{}{`iss`: `https://issuer.example.com`},
+			map[string]interface{}{`sub`: `service16`, `act`: `service77`},
+			map[string]interface{}{`sub`: `service16`, `act`: map[string]interface{}{`client_id`: `s6BhdRkqt3`}},
+		} {
+			tok := jwt.New()
+			require.NoError(t, tok.Set(tokenexchange.ActorKey, v), `tok.Set should succeed`)
+			_, err := tokenexchange.ActorOf(tok)
+			require.Error(t, err, `tokenexchange.ActorOf should fail for %v`, v)
+			require.Error(t, jwt.Validate(tok, jwt.WithValidator(tokenexchange.Validator())), `jwt.Validate should fail for %v`, v)
+		}
+	})
+}
+
+func TestDelegation(t *testing.T) {
+	key, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+
+	subject, err := jwt.NewBuilder().
+		Issuer(`https://issuer.example.com`).
+		Subject(`user@example.com`).
+		Claim(tokenexchange.MayActKey, &tokenexchange.Actor{Subject: `admin@example.com`}).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	actor, err := jwt.NewBuilder().
+		Issuer(`https://issuer.example.com`).
+		Subject(`admin@example.com`).
+		Claim(tokenexchange.ClientIDKey, `s6BhdRkqt3`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	other, err := jwt.NewBuilder().Subject(`mallory@example.com`).Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+	require.NoError(t, tokenexchange.CanAct(subject, actor), `admin should be allowed to act`)
+	err = tokenexchange.CanAct(subject, other)
+	require.True(t, errors.Is(err, tokenexchange.ErrActorNotAllowed()), `mallory should not be allowed to act`)
+	err = tokenexchange.CanAct(actor, subject)
+	require.True(t, errors.Is(err, tokenexchange.ErrActorNotAllowed()), `tokens without may_act should not allow any actor`)
+
+	delegated, err := tokenexchange.Delegate(subject, actor)
+	require.NoError(t, err, `tokenexchange.Delegate should succeed`)
+	_, ok := delegated.Get(tokenexchange.MayActKey)
+	require.False(t, ok, `may_act should be removed`)
+
+	// Delegate again, so that the previous actor becomes the prior actor
+	service, err := jwt.NewBuilder().Subject(`https://service.example.com`).Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+	delegated, err = tokenexchange.Delegate(delegated, service)
+	require.NoError(t, err, `tokenexchange.Delegate should succeed`)
+
+	// Roundtrip through signing and parsing
+	signed, err := jwt.Sign(delegated, jwt.WithKey(jwa.ES256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+	parsed, err := jwt.Parse(signed, jwt.WithKey(jwa.ES256, &key.PublicKey), jwt.WithValidator(tokenexchange.Validator()))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+	require.Equal(t, `user@example.com`, parsed.Subject())
+
+	act, err := tokenexchange.ActorOf(parsed)
+	require.NoError(t, err, `tokenexchange.ActorOf should succeed`)
+	chain := act.Chain()
+	require.Len(t, chain, 2)
+	require.Equal(t, `https://service.example.com`, chain[0].Subject)
+	require.Equal(t, `admin@example.com`, chain[1].Subject)
+	require.Equal(t, `https://issuer.example.com`, chain[1].Issuer)
+	require.Equal(t, map[string]interface{}{tokenexchange.ClientIDKey: `s6BhdRkqt3`}, chain[1].Claims)
+}
@@ -0,0 +1,207 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+)
+
+// DefaultTokenLifetime is the default lifetime of the tokens created by
+// `jwt.NewSigningTokenSource()`.
+const DefaultTokenLifetime = 5 * time.Minute
+
+// DefaultRefreshBefore is the default duration before the expiration of
+// a token at which a `jwt.TokenSource` created by `jwt.NewReuseTokenSource()`
+// obtains a new token.
+const DefaultRefreshBefore = 30 * time.Second
+
+const tokenSourceJtiSize = 16
+
+// TokenSource provides serialized tokens, for example to be attached to
+// outgoing requests. Implementations may create a new token on each call,
+// or return a cached token until it is about to expire.
+type TokenSource interface {
+	Token(ctx context.Context) ([]byte, error)
+}
+
+// TokenSourceFunc is a `jwt.TokenSource` that is implemented by a single
+// function.
+type TokenSourceFunc func(ctx context.Context) ([]byte, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+type reuseTokenSource struct {
+	source        TokenSource
+	refreshBefore time.Duration
+	clock         Clock
+
+	mu      sync.Mutex
+	token   []byte
+	expires time.Time
+}
+
+// NewReuseTokenSource creates a `jwt.TokenSource` that returns the token
+// obtained from `source` until shortly before it expires, as determined
+// by its (unverified) `exp` claim. Tokens without an `exp` claim, or that
+// cannot be parsed, are not reused.
+//
+// When a new token is required, only one call to `source` is made at a
+// time, and concurrent callers wait for its result instead of obtaining
+// tokens of their own.
+//
+// `jwt.WithRefreshBefore()` and `jwt.WithTokenSourceClock()` may be
+// specified as options.
+func NewReuseTokenSource(source TokenSource, options ...TokenSourceOption) (TokenSource, error) {
+	if source == nil {
+		return nil, fmt.Errorf(`jwt.NewReuseTokenSource: TokenSource is required`)
+	}
+
+	ts := &reuseTokenSource{
+		source:        source,
+		refreshBefore: DefaultRefreshBefore,
+		clock:         ClockFunc(time.Now),
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identRefreshBefore{}:
+			ts.refreshBefore = o.Value().(time.Duration)
+		case identTokenSourceClock{}:
+			// a nil Clock is reported below
+			ts.clock, _ = o.Value().(Clock)
+		}
+	}
+	if ts.refreshBefore < 0 {
+		return nil, fmt.Errorf(`jwt.NewReuseTokenSource: refresh duration must not be negative`)
+	}
+	if ts.clock == nil {
+		return nil, fmt.Errorf(`jwt.NewReuseTokenSource: clock must not be nil`)
+	}
+	return ts, nil
+}
+
+func (ts *reuseTokenSource) Token(ctx context.Context) ([]byte, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != nil && ts.clock.Now().Add(ts.refreshBefore).Before(ts.expires) {
+		return ts.token, nil
+	}
+
+	token, err := ts.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.token = nil
+	if tok, err := ParseInsecure(token); err == nil && !tok.Expiration().IsZero() {
+		ts.token = token
+		ts.expires = tok.Expiration()
+	}
+	return token, nil
+}
+
+type signingTokenSource struct {
+	template Token
+	alg      jwa.SignatureAlgorithm
+	key      interface{}
+	lifetime time.Duration
+	clock    Clock
+}
+
+// NewSigningTokenSource creates a `jwt.TokenSource` that creates
+// short-lived tokens signed by `key`, for example to authenticate calls
+// between services:
+//
+//	template, err := jwt.NewBuilder().
+//	  Issuer(`my-service`).
+//	  Subject(`my-service`).
+//	  Audience([]string{`other-service`}).
+//	  Build()
+//	...
+//	src, err := jwt.NewSigningTokenSource(template, jwa.ES256, key)
+//	...
+//	// for each request
+//	token, err := src.Token(ctx)
+//
+// Each token is a copy of `template` (which may be nil) whose `iat`,
+// `exp`, and `jti` claims are set when it is created. The `exp` claim is
+// set according to `jwt.WithTokenLifetime()`. Tokens are reused until
+// shortly before they expire, as with `jwt.NewReuseTokenSource()`, whose
+// options are also accepted.
+//
+// `template` must not be modified after it has been passed to this function.
+func NewSigningTokenSource(template Token, alg jwa.SignatureAlgorithm, key interface{}, options ...TokenSourceOption) (TokenSource, error) {
+	if template == nil {
+		template = New()
+	}
+	if alg == jwa.NoSignature {
+		return nil, fmt.Errorf(`jwt.NewSigningTokenSource: tokens must be signed`)
+	}
+	if key == nil {
+		return nil, fmt.Errorf(`jwt.NewSigningTokenSource: key is required`)
+	}
+
+	ts := &signingTokenSource{
+		template: template,
+		alg:      alg,
+		key:      key,
+		lifetime: DefaultTokenLifetime,
+		clock:    ClockFunc(time.Now),
+	}
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identTokenLifetime{}:
+			ts.lifetime = o.Value().(time.Duration)
+		case identTokenSourceClock{}:
+			// a nil Clock is reported by NewReuseTokenSource
+			ts.clock, _ = o.Value().(Clock)
+		}
+	}
+	if ts.lifetime <= 0 {
+		return nil, fmt.Errorf(`jwt.NewSigningTokenSource: lifetime must be positive`)
+	}
+
+	reuse, err := NewReuseTokenSource(ts, options...)
+	if err != nil {
+		return nil, fmt.Errorf(`jwt.NewSigningTokenSource: %w`, err)
+	}
+	return reuse, nil
+}
+
+func (ts *signingTokenSource) Token(_ context.Context) ([]byte, error) {
+	tok, err := ts.template.Clone()
+	if err != nil {
+		return nil, fmt.Errorf(`failed to copy template: %w`, err)
+	}
+
+	jti := make([]byte, tokenSourceJtiSize)
+	if _, err := rand.Read(jti); err != nil {
+		return nil, fmt.Errorf(`failed to generate jti: %w`, err)
+	}
+
+	now := ts.clock.Now()
+	if err := tok.Set(IssuedAtKey, now); err != nil {
+		return nil, fmt.Errorf(`failed to set %q claim: %w`, IssuedAtKey, err)
+	}
+	if err := tok.Set(ExpirationKey, now.Add(ts.lifetime)); err != nil {
+		return nil, fmt.Errorf(`failed to set %q claim: %w`, ExpirationKey, err)
+	}
+	if err := tok.Set(JwtIDKey, base64.EncodeToString(jti)); err != nil {
+		return nil, fmt.Errorf(`failed to set %q claim: %w`, JwtIDKey, err)
+	}
+
+	signed, err := Sign(tok, WithKey(ts.alg, ts.key))
+	if err != nil {
+		return nil, fmt.Errorf(`failed to sign token: %w`, err)
+	}
+	return signed, nil
+}
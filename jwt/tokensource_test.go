@@ -0,0 +1,134 @@
+package jwt_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenSource(t *testing.T) {
+	t.Parallel()
+
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+
+	t.Run("NewSigningTokenSource", func(t *testing.T) {
+		t.Parallel()
+		var mu sync.Mutex
+		now := time.Unix(1700000000, 0)
+		clock := jwt.ClockFunc(func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		})
+
+		template, err := jwt.NewBuilder().
+			Issuer(`svc-a`).
+			Audience([]string{`svc-b`}).
+			Build()
+		require.NoError(t, err, `jwt.NewBuilder should succeed`)
+
+		src, err := jwt.NewSigningTokenSource(template, jwa.HS256, key,
+			jwt.WithTokenLifetime(time.Minute),
+			jwt.WithRefreshBefore(10*time.Second),
+			jwt.WithTokenSourceClock(clock),
+		)
+		require.NoError(t, err, `jwt.NewSigningTokenSource should succeed`)
+
+		signed, err := src.Token(context.Background())
+		require.NoError(t, err, `src.Token should succeed`)
+
+		tok, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithClock(clock), jwt.WithAudience(`svc-b`))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		require.Equal(t, `svc-a`, tok.Issuer())
+		require.True(t, now.Equal(tok.IssuedAt()), `iat should be set`)
+		require.True(t, now.Add(time.Minute).Equal(tok.Expiration()), `exp should be set`)
+		require.NotEmpty(t, tok.JwtID())
+		_, ok := template.Get(jwt.ExpirationKey)
+		require.False(t, ok, `template should not be modified`)
+
+		again, err := src.Token(context.Background())
+		require.NoError(t, err, `src.Token should succeed`)
+		require.Equal(t, signed, again, `token should be reused`)
+
+		mu.Lock()
+		now = now.Add(55 * time.Second)
+		mu.Unlock()
+		refreshed, err := src.Token(context.Background())
+		require.NoError(t, err, `src.Token should succeed`)
+		require.NotEqual(t, signed, refreshed, `token should be refreshed before it expires`)
+
+		_, err = jwt.NewSigningTokenSource(nil, jwa.NoSignature, key)
+		require.Error(t, err, `unsigned tokens should be rejected`)
+		_, err = jwt.NewSigningTokenSource(nil, jwa.HS256, key, jwt.WithTokenLifetime(0))
+		require.Error(t, err, `non-positive lifetime should be rejected`)
+		_, err = jwt.NewSigningTokenSource(nil, jwa.HS256, nil)
+		require.Error(t, err, `key should be required`)
+	})
+	t.Run("NewReuseTokenSource", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		release := make(chan struct{})
+		inner := jwt.TokenSourceFunc(func(_ context.Context) ([]byte, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			tok, err := jwt.NewBuilder().Expiration(time.Now().Add(time.Hour)).Build()
+			if err != nil {
+				return nil, err
+			}
+			return jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+		})
+		src, err := jwt.NewReuseTokenSource(inner)
+		require.NoError(t, err, `jwt.NewReuseTokenSource should succeed`)
+
+		// concurrent callers share a single refresh
+		var wg sync.WaitGroup
+		results := make([][]byte, 8)
+		for i := range results {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = src.Token(context.Background())
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls), `inner source should be called once`)
+		for _, v := range results {
+			require.Equal(t, results[0], v)
+		}
+
+		// tokens without exp are not reused
+		var n int32
+		noexp, err := jwt.NewReuseTokenSource(jwt.TokenSourceFunc(func(context.Context) ([]byte, error) {
+			atomic.AddInt32(&n, 1)
+			return []byte(`opaque`), nil
+		}))
+		require.NoError(t, err, `jwt.NewReuseTokenSource should succeed`)
+		for i := 0; i < 2; i++ {
+			_, err := noexp.Token(context.Background())
+			require.NoError(t, err, `noexp.Token should succeed`)
+		}
+		require.Equal(t, int32(2), atomic.LoadInt32(&n))
+
+		failing, err := jwt.NewReuseTokenSource(jwt.TokenSourceFunc(func(context.Context) ([]byte, error) {
+			return nil, errors.New(`boom`)
+		}))
+		require.NoError(t, err, `jwt.NewReuseTokenSource should succeed`)
+		_, err = failing.Token(context.Background())
+		require.Error(t, err, `errors should be propagated`)
+
+		_, err = jwt.NewReuseTokenSource(nil)
+		require.Error(t, err, `source should be required`)
+		_, err = jwt.NewReuseTokenSource(inner, jwt.WithTokenSourceClock(nil))
+		require.Error(t, err, `nil clock should be rejected`)
+	})
+}
@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/base64"
+)
+
+const transformJtiSize = 16
+
+// Transformer modifies a token just before it is serialized, for example
+// to enforce an issuance policy. Transformers are specified via
+// `jwt.WithTransformer()` or `(jwt.Serializer).Transform()`.
+//
+// Transformers operate on a copy of the token that is being serialized,
+// so the token passed to `jwt.Sign()` is never modified. If a Transformer
+// returns an error, the token is not serialized.
+type Transformer interface {
+	Transform(Token) error
+}
+
+// TransformerFunc is a `jwt.Transformer` that is implemented by a single
+// function.
+type TransformerFunc func(Token) error
+
+func (f TransformerFunc) Transform(t Token) error {
+	return f(t)
+}
+
+// Transform adds transformers that are applied, in order, to a copy of
+// the token before it is serialized. A Serializer with transformers can
+// be created once and shared, to centralize the issuance policy:
+//
+//	serializer := jwt.NewSerializer().
+//	  Transform(jwt.InjectJwtID(), jwt.ClampExpiration(time.Hour), jwt.RemoveClaims(`password`)).
+//	  Sign(jwt.WithKey(jwa.ES256, key))
+//	...
+//	// for each token
+//	serialized, err := serializer.Serialize(tok)
+func (s *Serializer) Transform(transformers ...Transformer) *Serializer {
+	s.transformers = append(s.transformers, transformers...)
+	return s
+}
+
+// transform applies the transformers to a copy of `t`. `t` itself is
+// returned if there are no transformers
+func (s *Serializer) transform(t Token) (Token, error) {
+	if len(s.transformers) == 0 {
+		return t, nil
+	}
+
+	clone, err := t.Clone()
+	if err != nil {
+		return nil, fmt.Errorf(`failed to copy token: %w`, err)
+	}
+	for i, tr := range s.transformers {
+		if err := tr.Transform(clone); err != nil {
+			return nil, fmt.Errorf(`transformer #%d failed: %w`, i+1, err)
+		}
+	}
+	return clone, nil
+}
+
+func transformersFrom(options []SignOption) []Transformer {
+	var transformers []Transformer
+	for _, option := range options {
+		if option.Ident() == (identTransformer{}) {
+			if tr, ok := option.Value().(Transformer); ok && tr != nil {
+				transformers = append(transformers, tr)
+			}
+		}
+	}
+	return transformers
+}
+
+// InjectJwtID returns a `jwt.Transformer` that sets the `jti` claim to a
+// random value, unless it is already set.
+func InjectJwtID() Transformer {
+	return TransformerFunc(func(t Token) error {
+		if t.JwtID() != "" {
+			return nil
+		}
+		jti := make([]byte, transformJtiSize)
+		if _, err := rand.Read(jti); err != nil {
+			return fmt.Errorf(`failed to generate jti: %w`, err)
+		}
+		return t.Set(JwtIDKey, base64.EncodeToString(jti))
+	})
+}
+
+// ClampExpiration returns a `jwt.Transformer` that limits the lifetime of
+// the token to `limit`: if the `exp` claim is missing, or is later than `limit`
+// after the `iat` claim (or the current time, if `iat` is not set), it is
+// set to that time.
+func ClampExpiration(limit time.Duration) Transformer {
+	return TransformerFunc(func(t Token) error {
+		if limit <= 0 {
+			return fmt.Errorf(`maximum lifetime must be positive`)
+		}
+		start := t.IssuedAt()
+		if start.IsZero() {
+			start = time.Now()
+		}
+		latest := start.Add(limit)
+		if exp := t.Expiration(); !exp.IsZero() && !exp.After(latest) {
+			return nil
+		}
+		return t.Set(ExpirationKey, latest)
+	})
+}
+
+// RemoveClaims returns a `jwt.Transformer` that removes the claims
+// `names` from the token, for example to make sure that sensitive
+// claims are never issued.
+func RemoveClaims(names ...string) Transformer {
+	return TransformerFunc(func(t Token) error {
+		for _, name := range names {
+			if err := t.Remove(name); err != nil {
+				return fmt.Errorf(`failed to remove claim %q: %w`, name, err)
+			}
+		}
+		return nil
+	})
+}
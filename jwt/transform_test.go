@@ -0,0 +1,100 @@
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformers(t *testing.T) {
+	t.Parallel()
+
+	key := []byte(`abracadabra-abracadabra-abracadabra`)
+	iat := time.Now().Truncate(time.Second)
+
+	parse := func(t *testing.T, signed []byte) jwt.Token {
+		t.Helper()
+		tok, err := jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithValidate(false))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		return tok
+	}
+
+	t.Run("jwt.Sign", func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().
+			Subject(`alice`).
+			IssuedAt(iat).
+			Expiration(iat.Add(24*time.Hour)).
+			Claim(`password`, `hunter2`).
+			Build()
+		require.NoError(t, err, `Build should succeed`)
+
+		signed, err := jwt.Sign(tok,
+			jwt.WithKey(jwa.HS256, key),
+			jwt.WithTransformer(jwt.InjectJwtID()),
+			jwt.WithTransformer(jwt.ClampExpiration(time.Hour)),
+			jwt.WithTransformer(jwt.RemoveClaims(`password`)),
+		)
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		parsed := parse(t, signed)
+		require.NotEmpty(t, parsed.JwtID(), `jti should be injected`)
+		require.True(t, iat.Add(time.Hour).Equal(parsed.Expiration()), `exp should be clamped`)
+		_, ok := parsed.Get(`password`)
+		require.False(t, ok, `password should be removed`)
+
+		// the original token is not modified
+		require.Empty(t, tok.JwtID())
+		require.True(t, iat.Add(24*time.Hour).Equal(tok.Expiration()))
+		_, ok = tok.Get(`password`)
+		require.True(t, ok, `original token should be intact`)
+	})
+	t.Run("Serializer", func(t *testing.T) {
+		t.Parallel()
+		var order []string
+		record := func(name string) jwt.Transformer {
+			return jwt.TransformerFunc(func(jwt.Token) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+		serializer := jwt.NewSerializer().
+			Transform(record(`first`), jwt.InjectJwtID()).
+			Sign(jwt.WithKey(jwa.HS256, key), jwt.WithTransformer(record(`second`)))
+
+		tok, err := jwt.NewBuilder().JwtID(`fixed`).IssuedAt(iat).Build()
+		require.NoError(t, err, `Build should succeed`)
+		signed, err := serializer.Serialize(tok)
+		require.NoError(t, err, `Serialize should succeed`)
+		require.Equal(t, []string{`first`, `second`}, order)
+
+		parsed := parse(t, signed)
+		require.Equal(t, `fixed`, parsed.JwtID(), `existing jti should be kept`)
+	})
+	t.Run("ClampExpiration", func(t *testing.T) {
+		t.Parallel()
+		tok, err := jwt.NewBuilder().IssuedAt(iat).Expiration(iat.Add(time.Minute)).Build()
+		require.NoError(t, err, `Build should succeed`)
+		require.NoError(t, jwt.ClampExpiration(time.Hour).Transform(tok), `Transform should succeed`)
+		require.True(t, iat.Add(time.Minute).Equal(tok.Expiration()), `shorter exp should be kept`)
+
+		tok = jwt.New()
+		require.NoError(t, jwt.ClampExpiration(time.Hour).Transform(tok), `Transform should succeed`)
+		require.False(t, tok.Expiration().IsZero(), `missing exp should be set`)
+
+		require.Error(t, jwt.ClampExpiration(0).Transform(tok), `non-positive maximum should be rejected`)
+	})
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		errDenied := errors.New(`denied`)
+		_, err := jwt.Sign(jwt.New(),
+			jwt.WithKey(jwa.HS256, key),
+			jwt.WithTransformer(jwt.TransformerFunc(func(jwt.Token) error { return errDenied })),
+		)
+		require.True(t, errors.Is(err, errDenied), `errors from transformers should be returned`)
+	})
+}
@@ -1,15 +1,28 @@
 package jwt
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jws"
 )
 
+// Clock is the source of the current time used when validating
+// time based claims (`exp`, `nbf`, and `iat`). Use `jwt.WithClock()`
+// to specify a Clock, for example to freeze time in tests, or to use
+// a time source that is synchronized with the token issuer.
 type Clock interface {
 	Now() time.Time
 }
+
+// ClockFunc is a `jwt.Clock` that is implemented by a single function,
+// such as `time.Now`
 type ClockFunc func() time.Time
 
 func (f ClockFunc) Now() time.Time {
@@ -40,61 +53,33 @@ func timeClaim(t Token, clock Clock, c string) time.Time {
 
 // Validate makes sure that the essential claims stand.
 //
+// The following time based claims are always checked against the
+// current time (as reported by the `jwt.Clock` specified via `jwt.WithClock()`,
+// or `time.Now()` by default), if they are present in the token:
+//
+//   - `exp`: the current time must be before the expiration time
+//   - `nbf`: the current time must be at or after the "not before" time
+//   - `iat`: the current time must be at or after the issued at time
+//
+// Clock differences between the issuer and the validator can be tolerated
+// by specifying a leeway using `jwt.WithAcceptableSkew()`, which applies to
+// all of the above checks. The leeway must not be negative.
+//
+// Claims that are not present in the token are not checked: use
+// `jwt.WithRequiredClaim()` if, for example, `exp` must be present.
+//
+// By default the error for the first check that fails is returned. Use
+// `jwt.WithAggregateErrors(true)` to run all of the checks and report
+// every failure.
+//
 // See the various `WithXXX` functions for optional parameters
 // that can control the behavior of this method.
 func Validate(t Token, options ...ValidateOption) error {
-	ctx := context.Background()
-	trunc := time.Second
-
-	var clock Clock = ClockFunc(time.Now)
-	var skew time.Duration
-	var validators = []Validator{
-		IsIssuedAtValid(),
-		IsExpirationValid(),
-		IsNbfValid(),
-	}
-	for _, o := range options {
-		//nolint:forcetypeassert
-		switch o.Ident() {
-		case identClock{}:
-			clock = o.Value().(Clock)
-		case identAcceptableSkew{}:
-			skew = o.Value().(time.Duration)
-		case identTruncation{}:
-			trunc = o.Value().(time.Duration)
-		case identContext{}:
-			ctx = o.Value().(context.Context)
-		case identValidator{}:
-			v := o.Value().(Validator)
-			switch v := v.(type) {
-			case *isInTimeRange:
-				if v.c1 != "" {
-					if err := isSupportedTimeClaim(v.c1); err != nil {
-						return err
-					}
-					validators = append(validators, IsRequired(v.c1))
-				}
-				if v.c2 != "" {
-					if err := isSupportedTimeClaim(v.c2); err != nil {
-						return err
-					}
-					validators = append(validators, IsRequired(v.c2))
-				}
-			}
-			validators = append(validators, v)
-		}
-	}
-
-	ctx = SetValidationCtxSkew(ctx, skew)
-	ctx = SetValidationCtxClock(ctx, clock)
-	ctx = SetValidationCtxTruncation(ctx, trunc)
-	for _, v := range validators {
-		if err := v.Validate(ctx, t); err != nil {
-			return err
-		}
+	policy, err := NewValidationPolicy(options...)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return policy.Validate(t)
 }
 
 type isInTimeRange struct {
@@ -133,23 +118,78 @@ func (iitr *isInTimeRange) Validate(ctx context.Context, t Token) ValidationErro
 	t2 := timeClaim(t, clock, iitr.c2)
 	if iitr.less { // t1 - t2 <= iitr.dur
 		// t1 - t2 < iitr.dur + skew
-		if t1.Sub(t2) > iitr.dur+skew {
-			return NewValidationError(fmt.Errorf(`iitr between %s and %s exceeds %s (skew %s)`, iitr.c1, iitr.c2, iitr.dur, skew))
+		if d := t1.Sub(t2); d > iitr.dur+skew {
+			return NewValidationError(fmt.Errorf(`difference between %s and %s (%s) exceeds %s (skew %s)`, timeClaimName(iitr.c1), timeClaimName(iitr.c2), d, iitr.dur, skew))
 		}
 	} else {
-		if t1.Sub(t2) < iitr.dur-skew {
-			return NewValidationError(fmt.Errorf(`iitr between %s and %s is less than %s (skew %s)`, iitr.c1, iitr.c2, iitr.dur, skew))
+		if d := t1.Sub(t2); d < iitr.dur-skew {
+			return NewValidationError(fmt.Errorf(`difference between %s and %s (%s) is less than %s (skew %s)`, timeClaimName(iitr.c1), timeClaimName(iitr.c2), d, iitr.dur, skew))
 		}
 	}
 	return nil
 }
 
+// timeClaimName returns the name of the time claim for use in error messages
+func timeClaimName(c string) string {
+	if c == "" {
+		return `current time`
+	}
+	return strconv.Quote(c)
+}
+
 type ValidationError interface {
 	error
 	isValidationError()
 	Unwrap() error
 }
 
+// ValidationErrors is the error returned by `jwt.Validate()` when more than
+// one check fails and `jwt.WithAggregateErrors(true)` is specified.
+//
+// `errors.Is()` and `errors.As()` succeed if they succeed for any of the
+// errors in the list.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, `%d validation errors: `, len(errs))
+	for i, err := range errs {
+		if i > 0 {
+			buf.WriteString(`; `)
+		}
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+func (ValidationErrors) isValidationError() {}
+
+// Unwrap returns the first error in the list
+func (errs ValidationErrors) Unwrap() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+func (errs ValidationErrors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (errs ValidationErrors) As(target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func NewValidationError(err error) ValidationError {
 	return &validationError{error: err}
 }
@@ -225,6 +265,7 @@ func (err *invalidIssuerError) Error() string {
 var errTokenExpired = NewValidationError(fmt.Errorf(`"exp" not satisfied`))
 var errInvalidIssuedAt = NewValidationError(fmt.Errorf(`"iat" not satisfied`))
 var errTokenNotYetValid = NewValidationError(fmt.Errorf(`"nbf" not satisfied`))
+var errTokenReplayed = NewValidationError(fmt.Errorf(`"jti" has already been used`))
 var errInvalidAudience = &invalidAudienceError{}
 var errInvalidIssuer = &invalidIssuerError{}
 var errRequiredClaim = &missingRequiredClaimError{}
@@ -253,6 +294,14 @@ func ErrTokenNotYetValid() ValidationError {
 	return errTokenNotYetValid
 }
 
+// ErrTokenReplayed returns the immutable error used when a token whose `jti`
+// has already been recorded is rejected by `jwt.WithReplayDetection()`
+//
+// The return value should only be used for comparison using `errors.Is()`
+func ErrTokenReplayed() ValidationError {
+	return errTokenReplayed
+}
+
 // ErrInvalidAudience returns the immutable error used when `aud` claim
 // is not satisfied
 //
@@ -305,6 +354,7 @@ func (vf ValidatorFunc) Validate(ctx context.Context, tok Token) ValidationError
 type identValidationCtxClock struct{}
 type identValidationCtxSkew struct{}
 type identValidationCtxTruncation struct{}
+type identValidationCtxHeaders struct{}
 
 func SetValidationCtxClock(ctx context.Context, cl Clock) context.Context {
 	return context.WithValue(ctx, identValidationCtxClock{}, cl)
@@ -336,6 +386,26 @@ func ValidationCtxTruncation(ctx context.Context) time.Duration {
 	return ctx.Value(identValidationCtxTruncation{}).(time.Duration)
 }
 
+// SetValidationCtxHeaders associates the protected headers of the JWS
+// message that the token was extracted from with the validation context.
+// `jwt.Parse()` does this automatically when it verifies a signed token.
+func SetValidationCtxHeaders(ctx context.Context, h jws.Headers) context.Context {
+	return context.WithValue(ctx, identValidationCtxHeaders{}, h)
+}
+
+// ValidationCtxHeaders returns the protected headers of the JWS message
+// that the token being validated was extracted from. This allows validators
+// to implement rules that involve both the headers and the claims, such as
+// "the tenant claim must match the tenant header".
+//
+// The headers are only available when the token was verified by `jwt.Parse()`
+// (i.e. they are not available when calling `jwt.Validate()` directly, or when
+// verification is disabled), and the second return value is false otherwise.
+func ValidationCtxHeaders(ctx context.Context) (jws.Headers, bool) {
+	h, ok := ctx.Value(identValidationCtxHeaders{}).(jws.Headers)
+	return h, ok && h != nil
+}
+
 // IsExpirationValid is one of the default validators that will be executed.
 // It does not need to be specified by users, but it exists as an
 // exported field so that you can check what it does.
@@ -451,11 +521,11 @@ func ClaimContainsString(name, value string) Validator {
 // IsValidationError returns true if the error is a validation error
 func IsValidationError(err error) bool {
 	switch err {
-	case errTokenExpired, errTokenNotYetValid, errInvalidIssuedAt:
+	case errTokenExpired, errTokenNotYetValid, errInvalidIssuedAt, errTokenReplayed:
 		return true
 	default:
 		switch err.(type) {
-		case *validationError, *invalidAudienceError, *invalidIssuerError, *missingRequiredClaimError:
+		case *validationError, *invalidAudienceError, *invalidIssuerError, *missingRequiredClaimError, ValidationErrors:
 			return true
 		default:
 			return false
@@ -545,6 +615,25 @@ func IsRequired(name string) Validator {
 	return isRequired(name)
 }
 
+// AreRequired creates a Validator that checks if all of the claims in
+// `names` exist in the token. When the validator is passed to `jwt.Validate()`
+// via `jwt.WithValidator()` (or `jwt.WithRequiredClaims()`), each claim
+// is checked by a separate `jwt.IsRequired()` validator.
+func AreRequired(names ...string) Validator {
+	return areRequired(names)
+}
+
+type areRequired []string
+
+func (ar areRequired) Validate(ctx context.Context, t Token) ValidationError {
+	for _, name := range ar {
+		if err := isRequired(name).Validate(ctx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type isRequired string
 
 func (ir isRequired) Validate(_ context.Context, t Token) ValidationError {
@@ -555,3 +644,53 @@ func (ir isRequired) Validate(_ context.Context, t Token) ValidationError {
 	}
 	return nil
 }
+
+// ClaimsSchema is a compiled JSON Schema that the claims of a token can be
+// validated against. This package does not implement JSON Schema itself:
+// use the JSON Schema library of your choice, and wrap it if necessary.
+// For example, `*jsonschema.Schema` from github.com/santhosh-tekuri/jsonschema
+// satisfies this interface as is.
+//
+// `Validate` receives the claims as decoded by `encoding/json` into an
+// `interface{}`, with numbers represented as `json.Number`, and must return
+// an error if they do not conform to the schema.
+type ClaimsSchema interface {
+	Validate(interface{}) error
+}
+
+// MatchesSchema creates a Validator that checks if the claims of the token
+// conform to the JSON Schema `s`.
+//
+// The claims are validated in their serialized form, as produced by
+// `json.Marshal(token)`. This means that time based claims such as `exp`
+// are numbers, and that `aud` is an array of strings, unless the token
+// has the `jwt.FlattenAudience` option enabled.
+//
+// The error returned by the schema is wrapped, and can be examined using
+// `errors.As()`.
+func MatchesSchema(s ClaimsSchema) Validator {
+	return &matchesSchema{schema: s}
+}
+
+type matchesSchema struct {
+	schema ClaimsSchema
+}
+
+func (ms *matchesSchema) Validate(_ context.Context, t Token) ValidationError {
+	buf, err := json.Marshal(t)
+	if err != nil {
+		return NewValidationError(fmt.Errorf(`failed to serialize claims: %w`, err))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var claims interface{}
+	if err := dec.Decode(&claims); err != nil {
+		return NewValidationError(fmt.Errorf(`failed to decode claims: %w`, err))
+	}
+
+	if err := ms.schema.Validate(claims); err != nil {
+		return NewValidationError(fmt.Errorf(`claims do not match schema: %w`, err))
+	}
+	return nil
+}
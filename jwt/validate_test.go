@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -567,3 +570,392 @@ func TestClaimValidator(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTimeClaims(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	clock := jwt.ClockFunc(func() time.Time { return now })
+	const skew = 2 * time.Minute
+
+	testcases := []struct {
+		Name  string
+		Claim string
+		Value time.Time
+		Skew  time.Duration
+		Error error
+	}{
+		{Name: "exp in the future", Claim: jwt.ExpirationKey, Value: now.Add(time.Second)},
+		{Name: "exp now", Claim: jwt.ExpirationKey, Value: now, Error: jwt.ErrTokenExpired()},
+		{Name: "exp within skew", Claim: jwt.ExpirationKey, Value: now.Add(-time.Minute), Skew: skew},
+		{Name: "exp beyond skew", Claim: jwt.ExpirationKey, Value: now.Add(-3 * time.Minute), Skew: skew, Error: jwt.ErrTokenExpired()},
+		{Name: "nbf now", Claim: jwt.NotBeforeKey, Value: now},
+		{Name: "nbf in the future", Claim: jwt.NotBeforeKey, Value: now.Add(time.Second), Error: jwt.ErrTokenNotYetValid()},
+		{Name: "nbf within skew", Claim: jwt.NotBeforeKey, Value: now.Add(time.Minute), Skew: skew},
+		{Name: "nbf beyond skew", Claim: jwt.NotBeforeKey, Value: now.Add(3 * time.Minute), Skew: skew, Error: jwt.ErrTokenNotYetValid()},
+		{Name: "iat now", Claim: jwt.IssuedAtKey, Value: now},
+		{Name: "iat in the future", Claim: jwt.IssuedAtKey, Value: now.Add(time.Second), Error: jwt.ErrInvalidIssuedAt()},
+		{Name: "iat within skew", Claim: jwt.IssuedAtKey, Value: now.Add(time.Minute), Skew: skew},
+		{Name: "iat beyond skew", Claim: jwt.IssuedAtKey, Value: now.Add(3 * time.Minute), Skew: skew, Error: jwt.ErrInvalidIssuedAt()},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			tok := jwt.New()
+			require.NoError(t, tok.Set(tc.Claim, tc.Value), `tok.Set should succeed`)
+
+			err := jwt.Validate(tok, jwt.WithClock(clock), jwt.WithAcceptableSkew(tc.Skew))
+			if tc.Error != nil {
+				require.ErrorIs(t, err, tc.Error)
+				return
+			}
+			require.NoError(t, err, `jwt.Validate should succeed`)
+		})
+	}
+
+	t.Run("negative skew", func(t *testing.T) {
+		t.Parallel()
+		err := jwt.Validate(jwt.New(), jwt.WithAcceptableSkew(-1*time.Second))
+		require.Error(t, err, `jwt.Validate should fail`)
+	})
+}
+
+func TestValidateClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	clock := jwt.ClockFunc(func() time.Time { return now })
+
+	tok, err := jwt.NewBuilder().
+		IssuedAt(now).
+		Expiration(now.Add(time.Hour)).
+		Build()
+	require.NoError(t, err, `jwt.Builder should succeed`)
+	buf, err := json.Marshal(tok)
+	require.NoError(t, err, `json.Marshal should succeed`)
+
+	_, err = jwt.Parse(buf, jwt.WithVerify(false), jwt.WithValidate(true), jwt.WithClock(clock))
+	require.NoError(t, err, `jwt.Parse should succeed before expiry`)
+
+	// Advance the clock past the expiration time, without sleeping
+	later := jwt.ClockFunc(func() time.Time { return now.Add(2 * time.Hour) })
+	_, err = jwt.Parse(buf, jwt.WithVerify(false), jwt.WithValidate(true), jwt.WithClock(later))
+	require.ErrorIs(t, err, jwt.ErrTokenExpired())
+
+	require.Error(t, jwt.Validate(tok, jwt.WithClock(nil)), `jwt.Validate with nil clock should fail`)
+}
+
+func TestValidatorWithHeaders(t *testing.T) {
+	t.Parallel()
+
+	const tenantKey = `tenant`
+	key, err := jwxtest.GenerateSymmetricJwk()
+	require.NoError(t, err, `jwxtest.GenerateSymmetricJwk should succeed`)
+
+	// "tenant" claim must match the "tenant" header
+	errTenantMismatch := errors.New(`tenant mismatch`)
+	var calls int
+	validator := jwt.ValidatorFunc(func(ctx context.Context, tok jwt.Token) jwt.ValidationError {
+		calls++
+		hdrs, ok := jwt.ValidationCtxHeaders(ctx)
+		if !ok {
+			return jwt.NewValidationError(errors.New(`headers not available`))
+		}
+		claim, _ := tok.Get(tenantKey)
+		header, _ := hdrs.Get(tenantKey)
+		if claim == nil || claim != header {
+			return jwt.NewValidationError(errTenantMismatch)
+		}
+		return nil
+	})
+
+	sign := func(t *testing.T, claim, header string) []byte {
+		t.Helper()
+		tok, err := jwt.NewBuilder().Claim(tenantKey, claim).Build()
+		require.NoError(t, err, `jwt.Builder should succeed`)
+		hdrs := jws.NewHeaders()
+		require.NoError(t, hdrs.Set(tenantKey, header), `hdrs.Set should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key, jws.WithProtectedHeaders(hdrs)))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+		return signed
+	}
+
+	t.Run("match", func(t *testing.T) {
+		_, err := jwt.Parse(sign(t, `acme`, `acme`), jwt.WithKey(jwa.HS256, key), jwt.WithValidator(validator))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		_, err := jwt.Parse(sign(t, `acme`, `evil`), jwt.WithKey(jwa.HS256, key), jwt.WithValidator(validator))
+		require.ErrorIs(t, err, errTenantMismatch)
+	})
+	t.Run("user supplied context", func(t *testing.T) {
+		type ctxKey struct{}
+		ctx := context.WithValue(context.Background(), ctxKey{}, `value`)
+		v := jwt.ValidatorFunc(func(ctx context.Context, tok jwt.Token) jwt.ValidationError {
+			if ctx.Value(ctxKey{}) != `value` {
+				return jwt.NewValidationError(errors.New(`user context lost`))
+			}
+			return validator(ctx, tok)
+		})
+		_, err := jwt.Parse(sign(t, `acme`, `acme`), jwt.WithKey(jwa.HS256, key), jwt.WithContext(ctx), jwt.WithValidator(v))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+	})
+	t.Run("jwt.Validate", func(t *testing.T) {
+		tok, err := jwt.NewBuilder().Claim(tenantKey, `acme`).Build()
+		require.NoError(t, err, `jwt.Builder should succeed`)
+		require.Error(t, jwt.Validate(tok, jwt.WithValidator(validator)), `headers should not be available`)
+	})
+	require.Equal(t, 4, calls)
+}
+
+func TestReplayDetection(t *testing.T) {
+	now := time.Unix(aLongLongTimeAgo, 0).UTC()
+	clock := jwt.ClockFunc(func() time.Time { return now })
+
+	newToken := func(jti string, exp time.Time) jwt.Token {
+		tok := jwt.New()
+		if jti != "" {
+			require.NoError(t, tok.Set(jwt.JwtIDKey, jti), `tok.Set should succeed`)
+		}
+		if !exp.IsZero() {
+			require.NoError(t, tok.Set(jwt.ExpirationKey, exp), `tok.Set should succeed`)
+		}
+		return tok
+	}
+
+	t.Run("token can only be used once", func(t *testing.T) {
+		store := jwt.NewMemoryReplayStore(time.Hour)
+		tok := newToken(`one-time`, now.Add(time.Minute))
+
+		require.NoError(t, jwt.Validate(tok, jwt.WithClock(clock), jwt.WithReplayDetection(store)), `first use should succeed`)
+		err := jwt.Validate(tok, jwt.WithClock(clock), jwt.WithReplayDetection(store))
+		require.Error(t, err, `second use should fail`)
+		require.True(t, errors.Is(err, jwt.ErrTokenReplayed()), `error should be ErrTokenReplayed`)
+		require.True(t, jwt.IsValidationError(err), `error should be a validation error`)
+
+		require.NoError(t, jwt.Validate(newToken(`another`, now.Add(time.Minute)), jwt.WithClock(clock), jwt.WithReplayDetection(store)), `different jti should succeed`)
+	})
+	t.Run("jti is required", func(t *testing.T) {
+		store := jwt.NewMemoryReplayStore(time.Hour)
+		err := jwt.Validate(newToken(``, now.Add(time.Minute)), jwt.WithClock(clock), jwt.WithReplayDetection(store))
+		require.Error(t, err, `validation should fail`)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `error should be ErrRequiredClaim`)
+	})
+	t.Run("invalid tokens are not recorded", func(t *testing.T) {
+		store := jwt.NewMemoryReplayStore(time.Hour)
+		expired := newToken(`expired`, now.Add(-time.Minute))
+		require.Error(t, jwt.Validate(expired, jwt.WithClock(clock), jwt.WithReplayDetection(store)), `expired token should fail`)
+		require.Equal(t, 0, store.Len(), `store should be empty`)
+	})
+	t.Run("entries expire", func(t *testing.T) {
+		store := jwt.NewMemoryReplayStore(time.Hour)
+		ctx := jwt.SetValidationCtxClock(context.Background(), clock)
+
+		seen, err := store.Seen(ctx, `with-exp`, now.Add(time.Minute))
+		require.NoError(t, err, `store.Seen should succeed`)
+		require.False(t, seen, `jti should not have been seen`)
+		seen, err = store.Seen(ctx, `without-exp`, time.Time{})
+		require.NoError(t, err, `store.Seen should succeed`)
+		require.False(t, seen, `jti should not have been seen`)
+
+		later := jwt.SetValidationCtxClock(ctx, jwt.ClockFunc(func() time.Time { return now.Add(2 * time.Minute) }))
+		seen, err = store.Seen(later, `with-exp`, now.Add(time.Minute))
+		require.NoError(t, err, `store.Seen should succeed`)
+		require.False(t, seen, `expired entry should be forgotten`)
+		seen, err = store.Seen(later, `without-exp`, time.Time{})
+		require.NoError(t, err, `store.Seen should succeed`)
+		require.True(t, seen, `entry without exp should be kept for the ttl`)
+	})
+	t.Run("jwt.Parse", func(t *testing.T) {
+		store := jwt.NewMemoryReplayStore(time.Hour)
+		key := []byte(`abracadabra`)
+		signed, err := jwt.Sign(newToken(`parse`, now.Add(time.Minute)), jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithClock(clock), jwt.WithReplayDetection(store))
+		require.NoError(t, err, `first jwt.Parse should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithClock(clock), jwt.WithReplayDetection(store))
+		require.True(t, errors.Is(err, jwt.ErrTokenReplayed()), `second jwt.Parse should fail with ErrTokenReplayed`)
+	})
+}
+
+func TestValidateMaxAge(t *testing.T) {
+	now := time.Unix(aLongLongTimeAgo, 0).UTC()
+	clock := jwt.ClockFunc(func() time.Time { return now })
+
+	testcases := []struct {
+		Name     string
+		IssuedAt time.Time
+		Skew     time.Duration
+		Error    bool
+	}{
+		{Name: "issued just now", IssuedAt: now},
+		{Name: "issued within max age", IssuedAt: now.Add(-9 * time.Minute)},
+		{Name: "issued exactly max age ago", IssuedAt: now.Add(-10 * time.Minute)},
+		{Name: "issued too long ago", IssuedAt: now.Add(-11 * time.Minute), Error: true},
+		{Name: "issued too long ago, within skew", IssuedAt: now.Add(-11 * time.Minute), Skew: 2 * time.Minute},
+		{Name: "issued in the future", IssuedAt: now.Add(time.Minute), Error: true},
+		{Name: "issued in the future, within skew", IssuedAt: now.Add(time.Minute), Skew: 2 * time.Minute},
+		{Name: "iat is missing", Error: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			tok := jwt.New()
+			// a long-lived exp must not matter
+			require.NoError(t, tok.Set(jwt.ExpirationKey, now.Add(24*time.Hour)), `tok.Set should succeed`)
+			if !tc.IssuedAt.IsZero() {
+				require.NoError(t, tok.Set(jwt.IssuedAtKey, tc.IssuedAt), `tok.Set should succeed`)
+			}
+
+			err := jwt.Validate(tok, jwt.WithClock(clock), jwt.WithAcceptableSkew(tc.Skew), jwt.WithMaxAge(10*time.Minute))
+			if tc.Error {
+				require.Error(t, err, `jwt.Validate should fail`)
+				require.True(t, jwt.IsValidationError(err), `error should be a validation error`)
+				return
+			}
+			require.NoError(t, err, `jwt.Validate should succeed`)
+		})
+	}
+}
+
+func TestValidateRequiredClaims(t *testing.T) {
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.SubjectKey, `alice`), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(jwt.ExpirationKey, time.Now().Add(time.Hour)), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(`tenant`, `acme`), `tok.Set should succeed`)
+
+	t.Run("all claims present", func(t *testing.T) {
+		require.NoError(t, jwt.Validate(tok, jwt.WithRequiredClaims(jwt.SubjectKey, jwt.ExpirationKey, `tenant`)), `jwt.Validate should succeed`)
+	})
+	t.Run("empty list", func(t *testing.T) {
+		require.NoError(t, jwt.Validate(tok, jwt.WithRequiredClaims()), `jwt.Validate should succeed`)
+	})
+	t.Run("missing private claim", func(t *testing.T) {
+		err := jwt.Validate(tok, jwt.WithRequiredClaims(jwt.SubjectKey, `scope`))
+		require.Error(t, err, `jwt.Validate should fail`)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `error should be ErrRequiredClaim`)
+		require.Contains(t, err.Error(), `"scope"`, `error should mention the missing claim`)
+	})
+	t.Run("missing standard claim", func(t *testing.T) {
+		err := jwt.Validate(tok, jwt.WithRequiredClaims(jwt.IssuedAtKey))
+		require.Error(t, err, `jwt.Validate should fail`)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `error should be ErrRequiredClaim`)
+		require.Contains(t, err.Error(), `"iat"`, `error should mention the missing claim`)
+	})
+	t.Run("AreRequired used directly", func(t *testing.T) {
+		err := jwt.AreRequired(`tenant`, `scope`).Validate(context.Background(), tok)
+		require.Error(t, err, `Validate should fail`)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `error should be ErrRequiredClaim`)
+	})
+}
+
+// tenantSchema is a stand-in for a compiled JSON Schema, which requires
+// a string "tenant" claim and a numeric "exp" claim
+type tenantSchema struct{}
+
+type schemaError struct {
+	path string
+}
+
+func (err *schemaError) Error() string {
+	return `schema violation at ` + err.path
+}
+
+func (tenantSchema) Validate(v interface{}) error {
+	claims, ok := v.(map[string]interface{})
+	if !ok {
+		return &schemaError{path: `/`}
+	}
+	if _, ok := claims[`tenant`].(string); !ok {
+		return &schemaError{path: `/tenant`}
+	}
+	if _, ok := claims[jwt.ExpirationKey].(json.Number); !ok {
+		return &schemaError{path: `/exp`}
+	}
+	return nil
+}
+
+func TestValidateClaimsSchema(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.ExpirationKey, time.Now().Add(time.Hour)), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(`tenant`, `acme`), `tok.Set should succeed`)
+	require.NoError(t, jwt.Validate(tok, jwt.WithClaimsSchema(tenantSchema{})), `jwt.Validate should succeed`)
+
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, &key.PublicKey), jwt.WithClaimsSchema(tenantSchema{}))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+
+	require.NoError(t, tok.Set(`tenant`, 42), `tok.Set should succeed`)
+	err = jwt.Validate(tok, jwt.WithClaimsSchema(tenantSchema{}))
+	require.Error(t, err, `jwt.Validate should fail`)
+	var verr jwt.ValidationError
+	require.True(t, errors.As(err, &verr), `error should be a ValidationError`)
+	var serr *schemaError
+	require.True(t, errors.As(err, &serr), `error from the schema should be wrapped`)
+	require.Equal(t, `/tenant`, serr.path)
+
+	signed, err = jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, &key.PublicKey), jwt.WithClaimsSchema(tenantSchema{}))
+	require.Error(t, err, `jwt.Parse should fail`)
+}
+
+func TestValidateAggregateErrors(t *testing.T) {
+	now := time.Unix(aLongLongTimeAgo, 0).UTC()
+	clock := jwt.ClockFunc(func() time.Time { return now })
+
+	tok := jwt.New()
+	require.NoError(t, tok.Set(jwt.ExpirationKey, now.Add(-time.Hour)), `tok.Set should succeed`)
+	require.NoError(t, tok.Set(jwt.AudienceKey, `someone-else`), `tok.Set should succeed`)
+
+	options := []jwt.ValidateOption{
+		jwt.WithClock(clock),
+		jwt.WithAudience(`me`),
+		jwt.WithRequiredClaim(`tenant`),
+	}
+
+	t.Run("default", func(t *testing.T) {
+		err := jwt.Validate(tok, options...)
+		require.Error(t, err, `jwt.Validate should fail`)
+		var errs jwt.ValidationErrors
+		require.False(t, errors.As(err, &errs), `error should not be ValidationErrors`)
+	})
+	t.Run("aggregate", func(t *testing.T) {
+		err := jwt.Validate(tok, append(options, jwt.WithAggregateErrors(true))...)
+		require.Error(t, err, `jwt.Validate should fail`)
+		require.True(t, jwt.IsValidationError(err), `error should be a validation error`)
+
+		var errs jwt.ValidationErrors
+		require.True(t, errors.As(err, &errs), `error should be ValidationErrors`)
+		require.Len(t, errs, 3, `there should be 3 errors`)
+
+		require.True(t, errors.Is(err, jwt.ErrTokenExpired()), `error should match ErrTokenExpired`)
+		require.True(t, errors.Is(err, jwt.ErrInvalidAudience()), `error should match ErrInvalidAudience`)
+		require.True(t, errors.Is(err, jwt.ErrRequiredClaim()), `error should match ErrRequiredClaim`)
+		require.False(t, errors.Is(err, jwt.ErrTokenNotYetValid()), `error should not match ErrTokenNotYetValid`)
+		require.Contains(t, err.Error(), `3 validation errors`)
+	})
+	t.Run("single failure", func(t *testing.T) {
+		err := jwt.Validate(tok, jwt.WithClock(clock), jwt.WithAggregateErrors(true))
+		require.Error(t, err, `jwt.Validate should fail`)
+		require.Equal(t, jwt.ErrTokenExpired(), err, `a single error should be returned as is`)
+	})
+	t.Run("jwt.Parse", func(t *testing.T) {
+		key := []byte(`abracadabra`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.HS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.HS256, key), jwt.WithClock(clock), jwt.WithAudience(`me`), jwt.WithAggregateErrors(true))
+		var errs jwt.ValidationErrors
+		require.True(t, errors.As(err, &errs), `error should be ValidationErrors`)
+		require.Len(t, errs, 2, `there should be 2 errors`)
+	})
+}
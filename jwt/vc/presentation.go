@@ -0,0 +1,227 @@
+package vc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// Presentation represents a verifiable presentation.
+//
+// Each of the VerifiableCredential values is either a credential in the
+// JWT encoding (a string, which can be parsed using `vc.ParseCredential()`),
+// or a credential in another encoding (a JSON object). Properties that are
+// not defined here (e.g. `proof`) are stored in Properties.
+type Presentation struct {
+	Context              []interface{}
+	ID                   string
+	Type                 []string
+	Holder               string
+	VerifiableCredential []interface{}
+	Properties           map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *Presentation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.toMap())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Presentation) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	parsed, err := presentationFromMap(m)
+	if err != nil {
+		return err
+	}
+	*p = *parsed
+	return nil
+}
+
+func (p *Presentation) toMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(p.Properties)+5)
+	for k, v := range p.Properties {
+		m[k] = v
+	}
+	if len(p.Context) > 0 {
+		m[contextKey] = p.Context
+	}
+	if p.ID != `` {
+		m[idKey] = p.ID
+	}
+	if len(p.Type) > 0 {
+		m[typeKey] = p.Type
+	}
+	if p.Holder != `` {
+		m[holderKey] = p.Holder
+	}
+	if len(p.VerifiableCredential) > 0 {
+		m[verifiableCredentialKey] = p.VerifiableCredential
+	}
+	return m
+}
+
+func presentationFromMap(m map[string]interface{}) (*Presentation, error) {
+	var p Presentation
+	for k, v := range m {
+		var err error
+		switch k {
+		case contextKey:
+			p.Context, err = parseContext(v)
+		case idKey:
+			p.ID, err = parseString(k, v)
+		case typeKey:
+			p.Type, err = parseType(v)
+		case holderKey:
+			p.Holder, err = parseString(k, v)
+		case verifiableCredentialKey:
+			switch v := v.(type) {
+			case string, map[string]interface{}:
+				p.VerifiableCredential = []interface{}{v}
+			case []interface{}:
+				for _, cred := range v {
+					switch cred.(type) {
+					case string, map[string]interface{}:
+					default:
+						return nil, fmt.Errorf(`%q must contain strings or JSON objects`, verifiableCredentialKey)
+					}
+				}
+				p.VerifiableCredential = v
+			default:
+				err = fmt.Errorf(`%q must be a string, a JSON object, or an array`, verifiableCredentialKey)
+			}
+		default:
+			if p.Properties == nil {
+				p.Properties = make(map[string]interface{})
+			}
+			p.Properties[k] = v
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// NewPresentationToken creates a token that represents the presentation
+// `p`. The `iss` and `jti` claims are set from the holder and the `id` of
+// `p`, which are then omitted from the `vp` claim.
+//
+// If the `@context` or `type` properties are empty, they are set to
+// `vc.ContextV1` and `vc.TypePresentation`, respectively.
+//
+// The verifier is usually identified using the `aud` claim, and replay
+// attacks are prevented using the `nonce` claim (or `jti`). These claims
+// should be set on the returned token before it is signed using `jwt.Sign()`
+// with the key of the holder.
+func NewPresentationToken(p *Presentation) (jwt.Token, error) {
+	tmp := *p
+	if len(tmp.Context) == 0 {
+		tmp.Context = []interface{}{ContextV1}
+	}
+	if len(tmp.Type) == 0 {
+		tmp.Type = []string{TypePresentation}
+	}
+	if err := checkPresentation(&tmp); err != nil {
+		return nil, fmt.Errorf(`vc.NewPresentationToken: %w`, err)
+	}
+
+	claims := make(map[string]interface{})
+	if tmp.Holder != `` {
+		claims[jwt.IssuerKey] = tmp.Holder
+	}
+	if tmp.ID != `` {
+		claims[jwt.JwtIDKey] = tmp.ID
+	}
+
+	// The properties that are represented by JWT claims are omitted
+	tmp.Holder = ``
+	tmp.ID = ``
+	claims[PresentationKey] = tmp.toMap()
+
+	tok := jwt.New()
+	for k, v := range claims {
+		if err := tok.Set(k, v); err != nil {
+			return nil, fmt.Errorf(`vc.NewPresentationToken: failed to set %q claim: %w`, k, err)
+		}
+	}
+	return tok, nil
+}
+
+// PresentationFromToken returns the presentation represented by the token
+// `t`, by combining the `vp` claim with the `iss` and `jti` claims. An error
+// is returned if `t` does not have a `vp` claim, or if a JWT claim and the
+// corresponding property of the presentation are both present, but have
+// different values.
+//
+// The presentation is not validated. Use `vc.IsValidPresentation()` to do so.
+func PresentationFromToken(t jwt.Token) (*Presentation, error) {
+	m, err := objectClaim(t, PresentationKey)
+	if err != nil {
+		return nil, fmt.Errorf(`vc.PresentationFromToken: %w`, err)
+	}
+	p, err := presentationFromMap(m)
+	if err != nil {
+		return nil, fmt.Errorf(`vc.PresentationFromToken: invalid %q claim: %w`, PresentationKey, err)
+	}
+
+	if err := mergeString(&p.Holder, t.Issuer(), jwt.IssuerKey, holderKey); err != nil {
+		return nil, fmt.Errorf(`vc.PresentationFromToken: %w`, err)
+	}
+	if err := mergeString(&p.ID, t.JwtID(), jwt.JwtIDKey, idKey); err != nil {
+		return nil, fmt.Errorf(`vc.PresentationFromToken: %w`, err)
+	}
+	return p, nil
+}
+
+// IsValidPresentation returns a `jwt.Validator` that checks that the token
+// represents a valid verifiable presentation: the `vp` claim must be
+// present and consistent with the JWT claims (see `vc.PresentationFromToken()`),
+// the first `@context` must be `vc.ContextV1`, and the `type` must include
+// `vc.TypePresentation`.
+//
+// The credentials contained in the presentation are not verified. Use
+// `vc.ParseCredential()` to verify each of them, and check that their
+// subjects match the holder as required by your application.
+func IsValidPresentation() jwt.Validator {
+	return jwt.ValidatorFunc(validatePresentation)
+}
+
+func validatePresentation(_ context.Context, t jwt.Token) jwt.ValidationError {
+	p, err := PresentationFromToken(t)
+	if err != nil {
+		return jwt.NewValidationError(err)
+	}
+	if err := checkPresentation(p); err != nil {
+		return jwt.NewValidationError(fmt.Errorf(`invalid verifiable presentation: %w`, err))
+	}
+	return nil
+}
+
+// checkPresentation checks the `@context` and `type` of the presentation
+func checkPresentation(p *Presentation) error {
+	if err := checkContext(p.Context); err != nil {
+		return err
+	}
+	return checkType(p.Type, TypePresentation)
+}
+
+// ParsePresentation parses the token in `src` using `jwt.Parse()`,
+// validates it using `vc.IsValidPresentation()`, and returns the
+// presentation. Pass `jwt.WithAudience()` to check that the presentation
+// is intended for you.
+//
+// The source of the verification keys (i.e. the keys of the holder) must
+// be specified in `options`. Further `jwt.ValidateOption`s may be passed
+// as well, but `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func ParsePresentation(src []byte, options ...jwt.ParseOption) (*Presentation, error) {
+	tok, err := parse(src, IsValidPresentation(), options)
+	if err != nil {
+		return nil, fmt.Errorf(`vc.ParsePresentation: %w`, err)
+	}
+	return PresentationFromToken(tok)
+}
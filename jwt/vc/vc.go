@@ -0,0 +1,544 @@
+// Package vc implements the JWT encoding of W3C Verifiable Credentials
+// and Verifiable Presentations, as described in Section 6.3.1 of the
+// Verifiable Credentials Data Model v1.1 (https://www.w3.org/TR/vc-data-model/).
+//
+// In this encoding, the credential (or presentation) is stored in the
+// `vc` (or `vp`) claim, and some of its properties are represented by
+// registered JWT claims instead:
+//
+//	issuer                  <-> iss
+//	issuanceDate            <-> nbf
+//	expirationDate          <-> exp
+//	id                      <-> jti
+//	credentialSubject.id    <-> sub
+//	holder (presentations)  <-> iss
+//	id (presentations)      <-> jti
+//
+// `vc.NewCredentialToken()` and `vc.NewPresentationToken()` apply this
+// mapping to create tokens that can be signed using `jwt.Sign()`, and
+// `vc.CredentialFromToken()` and `vc.PresentationFromToken()` reverse it.
+// Properties that are present both as a JWT claim and in the `vc` (or `vp`)
+// claim must have the same value, or an error is returned.
+//
+// `vc.ParseCredential()` and `vc.ParsePresentation()` verify and validate
+// tokens in one go.
+package vc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/json"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/internal/parseopts"
+)
+
+// Names of the claims that hold credentials and presentations
+const (
+	CredentialKey   = `vc`
+	PresentationKey = `vp`
+)
+
+// ContextV1 is the base context of the Verifiable Credentials Data Model
+// v1.1, which must be the first value of the `@context` property.
+const ContextV1 = `https://www.w3.org/2018/credentials/v1`
+
+// The types that credentials and presentations must have
+const (
+	TypeCredential   = `VerifiableCredential`
+	TypePresentation = `VerifiablePresentation`
+)
+
+// Names of the properties of credentials and presentations
+const (
+	contextKey              = `@context`
+	idKey                   = `id`
+	typeKey                 = `type`
+	issuerKey               = `issuer`
+	issuanceDateKey         = `issuanceDate`
+	expirationDateKey       = `expirationDate`
+	credentialSubjectKey    = `credentialSubject`
+	holderKey               = `holder`
+	verifiableCredentialKey = `verifiableCredential`
+)
+
+// Credential represents a verifiable credential.
+//
+// The issuer is identified by Issuer. If IssuerProperties is not empty,
+// the issuer is represented as a JSON object that contains those properties
+// in addition to its `id`. Each of the CredentialSubject values is a JSON
+// object that describes one subject, and is identified by its `id` property.
+// Properties that are not defined here (e.g. `credentialStatus`) are stored
+// in Properties.
+type Credential struct {
+	Context           []interface{}
+	ID                string
+	Type              []string
+	Issuer            string
+	IssuerProperties  map[string]interface{}
+	IssuanceDate      time.Time
+	ExpirationDate    time.Time
+	CredentialSubject []map[string]interface{}
+	Properties        map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *Credential) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toMap())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Credential) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	parsed, err := credentialFromMap(m)
+	if err != nil {
+		return err
+	}
+	*c = *parsed
+	return nil
+}
+
+func (c *Credential) toMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(c.Properties)+8)
+	for k, v := range c.Properties {
+		m[k] = v
+	}
+	if len(c.Context) > 0 {
+		m[contextKey] = c.Context
+	}
+	if c.ID != `` {
+		m[idKey] = c.ID
+	}
+	if len(c.Type) > 0 {
+		m[typeKey] = c.Type
+	}
+	if len(c.IssuerProperties) > 0 {
+		issuer := make(map[string]interface{}, len(c.IssuerProperties)+1)
+		for k, v := range c.IssuerProperties {
+			issuer[k] = v
+		}
+		if c.Issuer != `` {
+			issuer[idKey] = c.Issuer
+		}
+		m[issuerKey] = issuer
+	} else if c.Issuer != `` {
+		m[issuerKey] = c.Issuer
+	}
+	if !c.IssuanceDate.IsZero() {
+		m[issuanceDateKey] = formatDate(c.IssuanceDate)
+	}
+	if !c.ExpirationDate.IsZero() {
+		m[expirationDateKey] = formatDate(c.ExpirationDate)
+	}
+	switch len(c.CredentialSubject) {
+	case 0:
+	case 1:
+		m[credentialSubjectKey] = c.CredentialSubject[0]
+	default:
+		m[credentialSubjectKey] = c.CredentialSubject
+	}
+	return m
+}
+
+func credentialFromMap(m map[string]interface{}) (*Credential, error) {
+	var c Credential
+	for k, v := range m {
+		var err error
+		switch k {
+		case contextKey:
+			c.Context, err = parseContext(v)
+		case idKey:
+			c.ID, err = parseString(k, v)
+		case typeKey:
+			c.Type, err = parseType(v)
+		case issuerKey:
+			switch v := v.(type) {
+			case string:
+				c.Issuer = v
+			case map[string]interface{}:
+				for ik, iv := range v {
+					if ik == idKey {
+						if c.Issuer, err = parseString(`issuer.id`, iv); err != nil {
+							return nil, err
+						}
+						continue
+					}
+					if c.IssuerProperties == nil {
+						c.IssuerProperties = make(map[string]interface{})
+					}
+					c.IssuerProperties[ik] = iv
+				}
+			default:
+				err = fmt.Errorf(`%q must be a string or a JSON object`, issuerKey)
+			}
+		case issuanceDateKey:
+			c.IssuanceDate, err = parseDate(k, v)
+		case expirationDateKey:
+			c.ExpirationDate, err = parseDate(k, v)
+		case credentialSubjectKey:
+			c.CredentialSubject, err = parseSubjects(v)
+		default:
+			if c.Properties == nil {
+				c.Properties = make(map[string]interface{})
+			}
+			c.Properties[k] = v
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// NewCredentialToken creates a token that represents the credential `c`.
+// The `iss`, `nbf`, `exp`, `jti`, and `sub` claims are set from the
+// corresponding properties of `c`, which are then omitted from the `vc`
+// claim. `sub` is only set if `c` has exactly one subject.
+//
+// If the `@context` or `type` properties are empty, they are set to
+// `vc.ContextV1` and `vc.TypeCredential`, respectively. The issuer, the
+// issuance date, and at least one subject are required.
+//
+// Other claims, such as `aud` or `iat`, may be set on the returned token
+// before it is signed using `jwt.Sign()`.
+func NewCredentialToken(c *Credential) (jwt.Token, error) {
+	if c.Issuer == `` {
+		return nil, fmt.Errorf(`vc.NewCredentialToken: issuer is required`)
+	}
+	if c.IssuanceDate.IsZero() {
+		return nil, fmt.Errorf(`vc.NewCredentialToken: issuance date is required`)
+	}
+	if len(c.CredentialSubject) == 0 {
+		return nil, fmt.Errorf(`vc.NewCredentialToken: at least one credential subject is required`)
+	}
+
+	tmp := *c
+	if len(tmp.Context) == 0 {
+		tmp.Context = []interface{}{ContextV1}
+	}
+	if len(tmp.Type) == 0 {
+		tmp.Type = []string{TypeCredential}
+	}
+	if err := checkCredential(&tmp); err != nil {
+		return nil, fmt.Errorf(`vc.NewCredentialToken: %w`, err)
+	}
+
+	tok := jwt.New()
+	claims := map[string]interface{}{
+		jwt.IssuerKey:    tmp.Issuer,
+		jwt.NotBeforeKey: tmp.IssuanceDate,
+	}
+	if !tmp.ExpirationDate.IsZero() {
+		claims[jwt.ExpirationKey] = tmp.ExpirationDate
+	}
+	if tmp.ID != `` {
+		claims[jwt.JwtIDKey] = tmp.ID
+	}
+
+	// The properties that are represented by JWT claims are omitted
+	tmp.ID = ``
+	tmp.IssuanceDate = time.Time{}
+	tmp.ExpirationDate = time.Time{}
+	if len(tmp.IssuerProperties) == 0 {
+		tmp.Issuer = ``
+	}
+	if len(tmp.CredentialSubject) == 1 {
+		if id, ok := tmp.CredentialSubject[0][idKey].(string); ok && id != `` {
+			claims[jwt.SubjectKey] = id
+			subject := make(map[string]interface{}, len(tmp.CredentialSubject[0]))
+			for k, v := range tmp.CredentialSubject[0] {
+				if k != idKey {
+					subject[k] = v
+				}
+			}
+			tmp.CredentialSubject = []map[string]interface{}{subject}
+		}
+	}
+	vc := tmp.toMap()
+	// the issuer's id is represented by `iss`
+	if issuer, ok := vc[issuerKey].(map[string]interface{}); ok {
+		delete(issuer, idKey)
+	}
+	claims[CredentialKey] = vc
+
+	for k, v := range claims {
+		if err := tok.Set(k, v); err != nil {
+			return nil, fmt.Errorf(`vc.NewCredentialToken: failed to set %q claim: %w`, k, err)
+		}
+	}
+	return tok, nil
+}
+
+// CredentialFromToken returns the credential represented by the token `t`,
+// by combining the `vc` claim with the `iss`, `nbf`, `exp`, `jti`, and
+// `sub` claims. An error is returned if `t` does not have a `vc` claim,
+// or if a JWT claim and the corresponding property of the credential are
+// both present, but have different values.
+//
+// The credential is not validated. Use `vc.IsValidCredential()` to do so.
+func CredentialFromToken(t jwt.Token) (*Credential, error) {
+	m, err := objectClaim(t, CredentialKey)
+	if err != nil {
+		return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+	}
+	c, err := credentialFromMap(m)
+	if err != nil {
+		return nil, fmt.Errorf(`vc.CredentialFromToken: invalid %q claim: %w`, CredentialKey, err)
+	}
+
+	if err := mergeString(&c.Issuer, t.Issuer(), jwt.IssuerKey, `issuer`); err != nil {
+		return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+	}
+	if err := mergeString(&c.ID, t.JwtID(), jwt.JwtIDKey, idKey); err != nil {
+		return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+	}
+	if err := mergeDate(&c.IssuanceDate, t.NotBefore(), jwt.NotBeforeKey, issuanceDateKey); err != nil {
+		return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+	}
+	if err := mergeDate(&c.ExpirationDate, t.Expiration(), jwt.ExpirationKey, expirationDateKey); err != nil {
+		return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+	}
+
+	if sub := t.Subject(); sub != `` {
+		if len(c.CredentialSubject) != 1 {
+			return nil, fmt.Errorf(`vc.CredentialFromToken: %q claim requires exactly one credential subject (got %d)`, jwt.SubjectKey, len(c.CredentialSubject))
+		}
+		subject := make(map[string]interface{}, len(c.CredentialSubject[0])+1)
+		for k, v := range c.CredentialSubject[0] {
+			subject[k] = v
+		}
+		var id string
+		if v, ok := subject[idKey]; ok {
+			if id, err = parseString(`credentialSubject.id`, v); err != nil {
+				return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+			}
+		}
+		if err := mergeString(&id, sub, jwt.SubjectKey, `credentialSubject.id`); err != nil {
+			return nil, fmt.Errorf(`vc.CredentialFromToken: %w`, err)
+		}
+		subject[idKey] = id
+		c.CredentialSubject = []map[string]interface{}{subject}
+	}
+	return c, nil
+}
+
+// IsValidCredential returns a `jwt.Validator` that checks that the token
+// represents a valid verifiable credential: the `vc` claim must be present
+// and consistent with the JWT claims (see `vc.CredentialFromToken()`), the
+// first `@context` must be `vc.ContextV1`, the `type` must include
+// `vc.TypeCredential`, and the issuer, the issuance date, and at least one
+// credential subject must be present.
+//
+// The expiration date and the issuance date are represented by the `exp`
+// and `nbf` claims, and are therefore validated by `jwt.Validate()`.
+func IsValidCredential() jwt.Validator {
+	return jwt.ValidatorFunc(validateCredential)
+}
+
+func validateCredential(_ context.Context, t jwt.Token) jwt.ValidationError {
+	c, err := CredentialFromToken(t)
+	if err != nil {
+		return jwt.NewValidationError(err)
+	}
+	if err := checkCredential(c); err != nil {
+		return jwt.NewValidationError(fmt.Errorf(`invalid verifiable credential: %w`, err))
+	}
+	if c.Issuer == `` {
+		return jwt.NewValidationError(fmt.Errorf(`invalid verifiable credential: issuer is required`))
+	}
+	if c.IssuanceDate.IsZero() {
+		return jwt.NewValidationError(fmt.Errorf(`invalid verifiable credential: issuance date is required`))
+	}
+	if len(c.CredentialSubject) == 0 {
+		return jwt.NewValidationError(fmt.Errorf(`invalid verifiable credential: at least one credential subject is required`))
+	}
+	return nil
+}
+
+// checkCredential checks the `@context` and `type` of the credential
+func checkCredential(c *Credential) error {
+	if err := checkContext(c.Context); err != nil {
+		return err
+	}
+	return checkType(c.Type, TypeCredential)
+}
+
+// ParseCredential parses the token in `src` using `jwt.Parse()`, validates
+// it using `vc.IsValidCredential()`, and returns the credential.
+//
+// The source of the verification keys must be specified in `options`
+// (e.g. `jwt.WithKeySet()`). Further `jwt.ValidateOption`s may be passed
+// as well, but `jwt.WithVerify()` and `jwt.WithValidate()` may not.
+func ParseCredential(src []byte, options ...jwt.ParseOption) (*Credential, error) {
+	tok, err := parse(src, IsValidCredential(), options)
+	if err != nil {
+		return nil, fmt.Errorf(`vc.ParseCredential: %w`, err)
+	}
+	return CredentialFromToken(tok)
+}
+
+func parse(src []byte, v jwt.Validator, options []jwt.ParseOption) (jwt.Token, error) {
+	for _, option := range options {
+		if parseopts.IsVerifyOrValidate(option) {
+			return nil, fmt.Errorf(`jwt.WithVerify() and jwt.WithValidate() may not be specified`)
+		}
+	}
+
+	options = append(options[:len(options):len(options)], jwt.WithValidator(v))
+	return jwt.Parse(src, options...)
+}
+
+// objectClaim returns the value of the claim `name` as a JSON object
+func objectClaim(t jwt.Token, name string) (map[string]interface{}, error) {
+	v, ok := t.Get(name)
+	if !ok {
+		return nil, fmt.Errorf(`%q claim is required`, name)
+	}
+	// The value is normalized by serializing it, as it may contain Go
+	// types such as []string if the token has not been parsed, or may
+	// be e.g. a *Credential that was set using `Set()`
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to serialize %q claim: %w`, name, err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf(`%q claim must be a JSON object: %w`, name, err)
+	}
+	return m, nil
+}
+
+// mergeString sets `*dst` to the value of the JWT claim `claim`, unless
+// it is empty. An error is returned if both have different values
+func mergeString(dst *string, claim, claimName, propName string) error {
+	if claim == `` {
+		return nil
+	}
+	if *dst != `` && *dst != claim {
+		return fmt.Errorf(`%q claim (%q) does not match %q property (%q)`, claimName, claim, propName, *dst)
+	}
+	*dst = claim
+	return nil
+}
+
+// mergeDate is like mergeString, but for dates. As JWT claims only
+// have a precision of a second, dates are compared at that precision
+func mergeDate(dst *time.Time, claim time.Time, claimName, propName string) error {
+	if claim.IsZero() {
+		return nil
+	}
+	if !dst.IsZero() && !dst.Truncate(time.Second).Equal(claim.Truncate(time.Second)) {
+		return fmt.Errorf(`%q claim (%s) does not match %q property (%s)`, claimName, formatDate(claim), propName, formatDate(*dst))
+	}
+	*dst = claim
+	return nil
+}
+
+func checkContext(contexts []interface{}) error {
+	if len(contexts) == 0 {
+		return fmt.Errorf(`%q is required`, contextKey)
+	}
+	if s, ok := contexts[0].(string); !ok || s != ContextV1 {
+		return fmt.Errorf(`first %q must be %q`, contextKey, ContextV1)
+	}
+	return nil
+}
+
+func checkType(types []string, required string) error {
+	for _, typ := range types {
+		if typ == required {
+			return nil
+		}
+	}
+	return fmt.Errorf(`%q must include %q`, typeKey, required)
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseDate(name string, v interface{}) (time.Time, error) {
+	s, err := parseString(name, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(`%q must be a date-time: %w`, name, err)
+	}
+	return t, nil
+}
+
+func parseString(name string, v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return ``, fmt.Errorf(`%q must be a string`, name)
+	}
+	return s, nil
+}
+
+// parseContext parses the `@context` property, whose values are either
+// URLs or JSON objects
+func parseContext(v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case string, map[string]interface{}:
+		return []interface{}{v}, nil
+	case []interface{}:
+		for _, ctx := range v {
+			switch ctx.(type) {
+			case string, map[string]interface{}:
+			default:
+				return nil, fmt.Errorf(`%q must contain strings or JSON objects`, contextKey)
+			}
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf(`%q must be a string or an array`, contextKey)
+	}
+}
+
+// parseType parses the `type` property, which is either a string or
+// an array of strings
+func parseType(v interface{}) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		types := make([]string, len(v))
+		for i, typ := range v {
+			s, ok := typ.(string)
+			if !ok {
+				return nil, fmt.Errorf(`%q must contain strings`, typeKey)
+			}
+			types[i] = s
+		}
+		return types, nil
+	default:
+		return nil, fmt.Errorf(`%q must be a string or an array of strings`, typeKey)
+	}
+}
+
+// parseSubjects parses the `credentialSubject` property, which is either
+// a JSON object or an array of JSON objects
+func parseSubjects(v interface{}) ([]map[string]interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, nil
+	case []interface{}:
+		subjects := make([]map[string]interface{}, len(v))
+		for i, subject := range v {
+			m, ok := subject.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(`%q must contain JSON objects`, credentialSubjectKey)
+			}
+			subjects[i] = m
+		}
+		return subjects, nil
+	default:
+		return nil, fmt.Errorf(`%q must be a JSON object or an array of JSON objects`, credentialSubjectKey)
+	}
+}
@@ -0,0 +1,232 @@
+package vc_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/jwt/vc"
+	"github.com/stretchr/testify/require"
+)
+
+func newCredential() *vc.Credential {
+	return &vc.Credential{
+		ID:             `http://example.edu/credentials/3732`,
+		Type:           []string{vc.TypeCredential, `UniversityDegreeCredential`},
+		Issuer:         `https://example.edu/issuers/14`,
+		IssuanceDate:   time.Date(2010, 1, 1, 19, 23, 24, 0, time.UTC),
+		ExpirationDate: time.Now().Add(time.Hour).Truncate(time.Second).UTC(),
+		CredentialSubject: []map[string]interface{}{
+			{
+				`id`: `did:example:ebfeb1f712ebc6f1c276e12ec21`,
+				`degree`: map[string]interface{}{
+					`type`: `BachelorDegree`,
+					`name`: `Bachelor of Science and Arts`,
+				},
+			},
+		},
+	}
+}
+
+func TestCredential(t *testing.T) {
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+
+	t.Run("mapping", func(t *testing.T) {
+		c := newCredential()
+		tok, err := vc.NewCredentialToken(c)
+		require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+
+		require.Equal(t, c.Issuer, tok.Issuer())
+		require.Equal(t, c.ID, tok.JwtID())
+		require.Equal(t, `did:example:ebfeb1f712ebc6f1c276e12ec21`, tok.Subject())
+		require.True(t, c.IssuanceDate.Equal(tok.NotBefore()), `nbf should be the issuance date`)
+		require.True(t, c.ExpirationDate.Equal(tok.Expiration()), `exp should be the expiration date`)
+
+		buf, err := json.Marshal(tok)
+		require.NoError(t, err, `json.Marshal should succeed`)
+		var claims struct {
+			VC map[string]interface{} `json:"vc"`
+		}
+		require.NoError(t, json.Unmarshal(buf, &claims), `json.Unmarshal should succeed`)
+		require.Equal(t, map[string]interface{}{
+			`@context`: []interface{}{vc.ContextV1},
+			`type`:     []interface{}{vc.TypeCredential, `UniversityDegreeCredential`},
+			`credentialSubject`: map[string]interface{}{
+				`degree`: map[string]interface{}{
+					`type`: `BachelorDegree`,
+					`name`: `Bachelor of Science and Arts`,
+				},
+			},
+		}, claims.VC, `properties represented by JWT claims should be omitted`)
+
+		require.Equal(t, newCredential().CredentialSubject, c.CredentialSubject, `credential should not be modified`)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		c := newCredential()
+		c.IssuerProperties = map[string]interface{}{`name`: `Example University`}
+		c.Properties = map[string]interface{}{
+			`credentialStatus`: map[string]interface{}{`id`: `https://example.edu/status/24`, `type`: `CredentialStatusList2017`},
+		}
+		tok, err := vc.NewCredentialToken(c)
+		require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		parsed, err := vc.ParseCredential(signed, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.NoError(t, err, `vc.ParseCredential should succeed`)
+
+		c.Context = []interface{}{vc.ContextV1}
+		require.Equal(t, c, parsed, `parsed credential should match`)
+	})
+	t.Run("multiple subjects", func(t *testing.T) {
+		c := newCredential()
+		c.CredentialSubject = append(c.CredentialSubject, map[string]interface{}{`id`: `did:example:other`})
+		tok, err := vc.NewCredentialToken(c)
+		require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+		require.Empty(t, tok.Subject(), `sub should not be set`)
+
+		parsed, err := vc.CredentialFromToken(tok)
+		require.NoError(t, err, `vc.CredentialFromToken should succeed`)
+		require.Equal(t, c.CredentialSubject, parsed.CredentialSubject, `subjects should be kept in the vc claim`)
+	})
+	t.Run("conflicting claims", func(t *testing.T) {
+		tok, err := vc.NewCredentialToken(newCredential())
+		require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+
+		v, _ := tok.Get(vc.CredentialKey)
+		//nolint:forcetypeassert
+		v.(map[string]interface{})[`issuer`] = `https://evil.example.com`
+		_, err = vc.CredentialFromToken(tok)
+		require.Error(t, err, `vc.CredentialFromToken should fail`)
+		require.Contains(t, err.Error(), `"iss" claim`)
+
+		tok, err = vc.NewCredentialToken(newCredential())
+		require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+		require.NoError(t, tok.Set(jwt.NotBeforeKey, time.Now()), `tok.Set should succeed`)
+		v, _ = tok.Get(vc.CredentialKey)
+		//nolint:forcetypeassert
+		v.(map[string]interface{})[`issuanceDate`] = `2010-01-01T19:23:24Z`
+		_, err = vc.CredentialFromToken(tok)
+		require.Error(t, err, `vc.CredentialFromToken should fail`)
+		require.Contains(t, err.Error(), `"nbf" claim`)
+	})
+	t.Run("validation", func(t *testing.T) {
+		_, err := vc.NewCredentialToken(&vc.Credential{Issuer: `https://example.edu`})
+		require.Error(t, err, `vc.NewCredentialToken should fail without issuance date`)
+
+		c := newCredential()
+		c.Type = []string{`UniversityDegreeCredential`}
+		_, err = vc.NewCredentialToken(c)
+		require.Error(t, err, `vc.NewCredentialToken should fail without VerifiableCredential type`)
+
+		tok := jwt.New()
+		require.NoError(t, tok.Set(jwt.IssuerKey, `https://example.edu`), `tok.Set should succeed`)
+		require.NoError(t, tok.Set(jwt.NotBeforeKey, time.Now().Add(-time.Hour)), `tok.Set should succeed`)
+		require.Error(t, jwt.Validate(tok, jwt.WithValidator(vc.IsValidCredential())), `missing vc claim should be rejected`)
+
+		require.NoError(t, tok.Set(vc.CredentialKey, map[string]interface{}{
+			`@context`:          []interface{}{`https://example.com/context`, vc.ContextV1},
+			`type`:              []interface{}{vc.TypeCredential},
+			`credentialSubject`: map[string]interface{}{`name`: `alice`},
+		}), `tok.Set should succeed`)
+		err = jwt.Validate(tok, jwt.WithValidator(vc.IsValidCredential()))
+		require.Error(t, err, `wrong context should be rejected`)
+		require.Contains(t, err.Error(), `@context`)
+
+		require.NoError(t, tok.Set(vc.CredentialKey, map[string]interface{}{
+			`@context`:          vc.ContextV1,
+			`type`:              vc.TypeCredential,
+			`credentialSubject`: map[string]interface{}{`name`: `alice`},
+		}), `tok.Set should succeed`)
+		require.NoError(t, jwt.Validate(tok, jwt.WithValidator(vc.IsValidCredential())), `valid credential should be accepted`)
+	})
+	t.Run("expired", func(t *testing.T) {
+		c := newCredential()
+		c.ExpirationDate = time.Now().Add(-time.Hour)
+		tok, err := vc.NewCredentialToken(c)
+		require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+		signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+		require.NoError(t, err, `jwt.Sign should succeed`)
+
+		_, err = vc.ParseCredential(signed, jwt.WithKey(jwa.RS256, &key.PublicKey))
+		require.Error(t, err, `vc.ParseCredential should fail`)
+
+		_, err = vc.ParseCredential(signed, jwt.WithKey(jwa.RS256, &key.PublicKey), jwt.WithValidate(false))
+		require.Error(t, err, `jwt.WithValidate should not be accepted`)
+	})
+}
+
+func TestPresentation(t *testing.T) {
+	issuerKey, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	holderKey, err := jwxtest.GenerateEcdsaKey(jwa.P256)
+	require.NoError(t, err, `jwxtest.GenerateEcdsaKey should succeed`)
+
+	ctok, err := vc.NewCredentialToken(newCredential())
+	require.NoError(t, err, `vc.NewCredentialToken should succeed`)
+	credential, err := jwt.Sign(ctok, jwt.WithKey(jwa.RS256, issuerKey))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	p := &vc.Presentation{
+		ID:                   `urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5`,
+		Holder:               `did:example:ebfeb1f712ebc6f1c276e12ec21`,
+		VerifiableCredential: []interface{}{string(credential)},
+	}
+	ptok, err := vc.NewPresentationToken(p)
+	require.NoError(t, err, `vc.NewPresentationToken should succeed`)
+	require.Equal(t, p.Holder, ptok.Issuer(), `iss should be the holder`)
+	require.Equal(t, p.ID, ptok.JwtID(), `jti should be the id`)
+	require.NoError(t, ptok.Set(jwt.AudienceKey, `did:example:verifier`), `ptok.Set should succeed`)
+	require.NoError(t, ptok.Set(`nonce`, `343s$FSFDa-`), `ptok.Set should succeed`)
+
+	signed, err := jwt.Sign(ptok, jwt.WithKey(jwa.ES256, holderKey))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	_, err = vc.ParsePresentation(signed, jwt.WithKey(jwa.ES256, &holderKey.PublicKey), jwt.WithAudience(`did:example:other`))
+	require.Error(t, err, `vc.ParsePresentation should fail for another audience`)
+
+	parsed, err := vc.ParsePresentation(signed, jwt.WithKey(jwa.ES256, &holderKey.PublicKey), jwt.WithAudience(`did:example:verifier`))
+	require.NoError(t, err, `vc.ParsePresentation should succeed`)
+	require.Equal(t, p.Holder, parsed.Holder)
+	require.Equal(t, p.ID, parsed.ID)
+	require.Equal(t, []string{vc.TypePresentation}, parsed.Type)
+	require.Len(t, parsed.VerifiableCredential, 1)
+
+	embedded, ok := parsed.VerifiableCredential[0].(string)
+	require.True(t, ok, `embedded credential should be a string`)
+	c, err := vc.ParseCredential([]byte(embedded), jwt.WithKey(jwa.RS256, &issuerKey.PublicKey))
+	require.NoError(t, err, `vc.ParseCredential should succeed`)
+	require.Equal(t, p.Holder, c.CredentialSubject[0][`id`], `subject of the credential should be the holder`)
+
+	t.Run("invalid type", func(t *testing.T) {
+		_, err := vc.NewPresentationToken(&vc.Presentation{Type: []string{vc.TypeCredential}})
+		require.Error(t, err, `vc.NewPresentationToken should fail`)
+	})
+}
+
+func TestCredentialJSON(t *testing.T) {
+	const src = `{
+  "@context": ["https://www.w3.org/2018/credentials/v1", {"ex": "https://example.com/vocab#"}],
+  "id": "http://example.edu/credentials/1872",
+  "type": ["VerifiableCredential", "AlumniCredential"],
+  "issuer": {"id": "https://example.edu/issuers/565049", "name": "Example University"},
+  "issuanceDate": "2010-01-01T19:23:24Z",
+  "credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21", "alumniOf": "Example University"},
+  "proof": {"type": "RsaSignature2018"}
+}`
+	var c vc.Credential
+	require.NoError(t, json.Unmarshal([]byte(src), &c), `json.Unmarshal should succeed`)
+	require.Equal(t, `https://example.edu/issuers/565049`, c.Issuer)
+	require.Equal(t, map[string]interface{}{`name`: `Example University`}, c.IssuerProperties)
+	require.Equal(t, time.Date(2010, 1, 1, 19, 23, 24, 0, time.UTC), c.IssuanceDate)
+	require.Len(t, c.CredentialSubject, 1)
+	require.Contains(t, c.Properties, `proof`)
+
+	buf, err := json.Marshal(&c)
+	require.NoError(t, err, `json.Marshal should succeed`)
+	require.JSONEq(t, src, string(buf), `serialized credential should match`)
+}
@@ -0,0 +1,128 @@
+// Package observe provides hooks to observe the operations performed by
+// this module, such as verifying signatures and fetching JWK sets, for
+// tracing and metrics purposes.
+//
+// An `observe.Observer` is registered globally using `observe.SetObserver()`.
+// It is notified when one of the following operations starts, and the
+// returned `observe.Span` is notified when the operation ends:
+//
+//	jwt.Parse (and its variants), jwt.Validate, jws.Verify, jwe.Decrypt, jwk.Fetch
+//
+// Operations that are performed as part of another operation (for example
+// the signature verification performed by `jwt.Parse()`) are started
+// using the context returned by the observer for the enclosing operation,
+// so that spans can be nested. The context passed to the outermost
+// operation is taken from `jwt.WithContext()`, `jws.WithContext()`,
+// `jwe.WithContext()`, or the argument of `jwk.Fetch()`, respectively.
+//
+// This package does not depend on any tracing or metrics library. An
+// implementation for OpenTelemetry is available in the separate
+// `github.com/lestrrat-go/jwx/v2/observe/otel` module.
+package observe
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Operation identifies the operation being observed. Its value is the
+// name of the function that performs it, such as "jws.Verify".
+type Operation string
+
+// The operations that are observed
+const (
+	JWTParse    Operation = `jwt.Parse`
+	JWTValidate Operation = `jwt.Validate`
+	JWSVerify   Operation = `jws.Verify`
+	JWEDecrypt  Operation = `jwe.Decrypt`
+	JWKFetch    Operation = `jwk.Fetch`
+)
+
+// Keys of the attributes that are set on spans. Attributes are only set
+// when their values are known, e.g. `AttrKeyID` is not set for a JWS
+// message without a "kid" header.
+const (
+	// AttrAlgorithm is the signature or key encryption algorithm
+	AttrAlgorithm = `jwx.alg`
+	// AttrKeyID is the "kid" header of the JWS or JWE message
+	AttrKeyID = `jwx.kid`
+	// AttrIssuer is the "iss" claim of the JWT
+	AttrIssuer = `jwx.iss`
+	// AttrURL is the URL of the JWK set that is fetched
+	AttrURL = `jwx.url`
+)
+
+// Observer is notified of the start of operations.
+//
+// Implementations must be safe for concurrent use, and should return
+// quickly, as they are called synchronously.
+type Observer interface {
+	// Start is called when the operation `op` starts. The returned context
+	// is used as the parent context of nested operations.
+	Start(ctx context.Context, op Operation) (context.Context, Span)
+}
+
+// ObserverFunc is an Observer based on a function.
+type ObserverFunc func(context.Context, Operation) (context.Context, Span)
+
+func (f ObserverFunc) Start(ctx context.Context, op Operation) (context.Context, Span) {
+	return f(ctx, op)
+}
+
+// Span represents a single execution of an operation.
+type Span interface {
+	// SetAttribute sets an attribute of the operation. See the Attr*
+	// constants for the keys that are used.
+	SetAttribute(key, value string)
+	// End is called exactly once, when the operation ends. `err` is
+	// the error returned by the operation, if any.
+	End(err error)
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, string) {}
+func (nopSpan) End(error)                   {}
+
+// atomic.Value cannot store nil, nor values of different concrete types
+type observerHolder struct {
+	observer Observer
+}
+
+var globalObserver atomic.Value
+
+func init() {
+	globalObserver.Store(observerHolder{})
+}
+
+// SetObserver registers `o` as the observer for all operations.
+// Passing nil disables observation, which is the default.
+func SetObserver(o Observer) {
+	globalObserver.Store(observerHolder{observer: o})
+}
+
+// Start notifies the registered observer that the operation `op` has
+// started. If no observer is registered, `ctx` and a Span that does
+// nothing are returned.
+//
+// This function is used by the packages in this module, and there is
+// usually no need to call it directly.
+func Start(ctx context.Context, op Operation) (context.Context, Span) {
+	//nolint:forcetypeassert
+	o := globalObserver.Load().(observerHolder).observer
+	if o == nil {
+		return ctx, nopSpan{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	nctx, span := o.Start(ctx, op)
+	if nctx == nil {
+		nctx = ctx
+	}
+	if span == nil {
+		span = nopSpan{}
+	}
+	return nctx, span
+}
@@ -0,0 +1,189 @@
+package observe_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/observe"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	op     observe.Operation
+	parent observe.Operation
+	attrs  map[string]string
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttribute(key, value string) {
+	s.attrs[key] = value
+}
+
+func (s *recordedSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type spanKey struct{}
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recorder) Start(ctx context.Context, op observe.Operation) (context.Context, observe.Span) {
+	span := &recordedSpan{op: op, attrs: make(map[string]string)}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		span.parent = parent.op
+	}
+
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (r *recorder) find(op observe.Operation) *recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, span := range r.spans {
+		if span.op == op {
+			return span
+		}
+	}
+	return nil
+}
+
+func setup(t *testing.T) *recorder {
+	t.Helper()
+	r := &recorder{}
+	observe.SetObserver(r)
+	t.Cleanup(func() { observe.SetObserver(nil) })
+	return r
+}
+
+func TestObserve(t *testing.T) {
+	key, err := jwxtest.GenerateRsaJwk()
+	require.NoError(t, err, `jwxtest.GenerateRsaJwk should succeed`)
+	require.NoError(t, key.Set(jwk.KeyIDKey, `my-key`), `key.Set should succeed`)
+	pubkey, err := jwk.PublicKeyOf(key)
+	require.NoError(t, err, `jwk.PublicKeyOf should succeed`)
+
+	tok, err := jwt.NewBuilder().
+		Issuer(`https://issuer.example.com`).
+		Subject(`alice`).
+		Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	t.Run("no observer", func(t *testing.T) {
+		ctx := context.Background()
+		nctx, span := observe.Start(ctx, observe.JWSVerify)
+		require.Equal(t, ctx, nctx, `context should be returned as is`)
+		span.SetAttribute(observe.AttrKeyID, `foo`)
+		span.End(nil)
+	})
+	t.Run("jwt.Parse", func(t *testing.T) {
+		r := setup(t)
+
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.RS256, pubkey), jwt.WithIssuer(`https://issuer.example.com`))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+
+		parse := r.find(observe.JWTParse)
+		require.NotNil(t, parse, `jwt.Parse should be observed`)
+		require.True(t, parse.ended, `span should be ended`)
+		require.NoError(t, parse.err, `span should not report an error`)
+		require.Equal(t, map[string]string{
+			observe.AttrAlgorithm: `RS256`,
+			observe.AttrKeyID:     `my-key`,
+			observe.AttrIssuer:    `https://issuer.example.com`,
+		}, parse.attrs)
+
+		verify := r.find(observe.JWSVerify)
+		require.NotNil(t, verify, `jws.Verify should be observed`)
+		require.Equal(t, observe.JWTParse, verify.parent, `jws.Verify should be nested in jwt.Parse`)
+		require.Equal(t, `RS256`, verify.attrs[observe.AttrAlgorithm])
+		require.Equal(t, `my-key`, verify.attrs[observe.AttrKeyID])
+
+		validate := r.find(observe.JWTValidate)
+		require.NotNil(t, validate, `jwt.Validate should be observed`)
+		require.Equal(t, observe.JWTParse, validate.parent, `jwt.Validate should be nested in jwt.Parse`)
+		require.Equal(t, `https://issuer.example.com`, validate.attrs[observe.AttrIssuer])
+	})
+	t.Run("parent context", func(t *testing.T) {
+		r := setup(t)
+
+		ctx, root := r.Start(context.Background(), `root`)
+		_, err := jwt.Parse(signed, jwt.WithKey(jwa.RS256, pubkey), jwt.WithContext(ctx))
+		require.NoError(t, err, `jwt.Parse should succeed`)
+		root.End(nil)
+
+		parse := r.find(observe.JWTParse)
+		require.NotNil(t, parse, `jwt.Parse should be observed`)
+		require.Equal(t, observe.Operation(`root`), parse.parent, `jwt.Parse should be nested in the context passed via jwt.WithContext`)
+	})
+	t.Run("failures", func(t *testing.T) {
+		r := setup(t)
+
+		wrongkey, err := jwxtest.GenerateRsaPublicJwk()
+		require.NoError(t, err, `jwxtest.GenerateRsaPublicJwk should succeed`)
+		_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, wrongkey))
+		require.Error(t, err, `jwt.Parse should fail`)
+
+		parse := r.find(observe.JWTParse)
+		require.NotNil(t, parse, `jwt.Parse should be observed`)
+		require.Equal(t, err, parse.err, `span should report the error`)
+
+		verify := r.find(observe.JWSVerify)
+		require.NotNil(t, verify, `jws.Verify should be observed`)
+		require.True(t, errors.Is(verify.err, jws.ErrVerificationFailed()), `span should report the error`)
+		require.Equal(t, `my-key`, verify.attrs[observe.AttrKeyID], `attributes should be set for failures`)
+		require.Nil(t, r.find(observe.JWTValidate), `jwt.Validate should not be observed`)
+	})
+	t.Run("jwe.Decrypt", func(t *testing.T) {
+		r := setup(t)
+
+		enckey, err := jwxtest.GenerateRsaKey()
+		require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+		encrypted, err := jwe.Encrypt([]byte(`Lorem ipsum`), jwe.WithKey(jwa.RSA_OAEP, &enckey.PublicKey))
+		require.NoError(t, err, `jwe.Encrypt should succeed`)
+
+		ctx, root := r.Start(context.Background(), `root`)
+		_, err = jwe.Decrypt(encrypted, jwe.WithKey(jwa.RSA_OAEP, enckey), jwe.WithContext(ctx))
+		require.NoError(t, err, `jwe.Decrypt should succeed`)
+		root.End(nil)
+
+		decrypt := r.find(observe.JWEDecrypt)
+		require.NotNil(t, decrypt, `jwe.Decrypt should be observed`)
+		require.Equal(t, observe.Operation(`root`), decrypt.parent, `jwe.Decrypt should be nested in the context passed via jwe.WithContext`)
+		require.Equal(t, `RSA-OAEP`, decrypt.attrs[observe.AttrAlgorithm])
+	})
+	t.Run("jwk.Fetch", func(t *testing.T) {
+		r := setup(t)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		_, err := jwk.Fetch(context.Background(), `http://user:secret@`+srv.Listener.Addr().String()+`/jwks.json`)
+		require.Error(t, err, `jwk.Fetch should fail`)
+
+		fetch := r.find(observe.JWKFetch)
+		require.NotNil(t, fetch, `jwk.Fetch should be observed`)
+		require.Equal(t, err, fetch.err, `span should report the error`)
+		require.Equal(t, srv.URL+`/jwks.json`, fetch.attrs[observe.AttrURL], `credentials should be removed from the URL`)
+	})
+}
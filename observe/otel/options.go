@@ -0,0 +1,25 @@
+package otel
+
+import (
+	"github.com/lestrrat-go/option"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option describes an option that can be passed to `New()`.
+type Option = option.Interface
+
+type identMeterProvider struct{}
+type identTracerProvider struct{}
+
+// WithMeterProvider specifies the provider of the meter used to record
+// metrics. By default the global provider is used.
+func WithMeterProvider(v metric.MeterProvider) Option {
+	return option.New(identMeterProvider{}, v)
+}
+
+// WithTracerProvider specifies the provider of the tracer used to create
+// spans. By default the global provider is used.
+func WithTracerProvider(v trace.TracerProvider) Option {
+	return option.New(identTracerProvider{}, v)
+}
@@ -0,0 +1,138 @@
+// Package otel provides an `observe.Observer` that reports the operations
+// performed by the `github.com/lestrrat-go/jwx/v2` module to OpenTelemetry.
+//
+// Each operation creates a span named after the operation (e.g.
+// "jws.Verify"), carrying the attributes described in the `observe`
+// package. Failed operations record the error on the span and set its
+// status. In addition, the following metrics are recorded, with the
+// operation in the "jwx.operation" attribute, and the algorithm in the
+// "jwx.alg" attribute, if known:
+//
+//	jwx.operations          (counter) number of operations
+//	jwx.operation.failures  (counter) number of operations that failed
+//	jwx.operation.duration  (histogram, seconds) duration of operations
+//
+// This package is a separate Go module, so that users of the
+// `github.com/lestrrat-go/jwx/v2` module do not depend on OpenTelemetry.
+//
+// As the name of this package clashes with go.opentelemetry.io/otel, it is
+// usually imported using an alias such as `jwxotel`:
+//
+//	o, err := jwxotel.New()
+//	if err != nil {
+//	  ...
+//	}
+//	observe.SetObserver(o)
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/observe"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = `github.com/lestrrat-go/jwx/v2/observe/otel`
+
+const operationKey = attribute.Key(`jwx.operation`)
+
+// Observer reports operations to OpenTelemetry.
+type Observer struct {
+	tracer     trace.Tracer
+	operations metric.Int64Counter
+	failures   metric.Int64Counter
+	duration   metric.Float64Histogram
+}
+
+// New creates a new Observer. Register it using `observe.SetObserver()`.
+func New(options ...Option) (*Observer, error) {
+	tp := otelglobal.GetTracerProvider()
+	mp := otelglobal.GetMeterProvider()
+	for _, o := range options {
+		//nolint:forcetypeassert
+		switch o.Ident() {
+		case identTracerProvider{}:
+			tp = o.Value().(trace.TracerProvider)
+		case identMeterProvider{}:
+			mp = o.Value().(metric.MeterProvider)
+		}
+	}
+
+	meter := mp.Meter(instrumentationName)
+	operations, err := meter.Int64Counter(`jwx.operations`,
+		metric.WithDescription(`Number of operations performed`))
+	if err != nil {
+		return nil, fmt.Errorf(`observe/otel.New: failed to create counter: %w`, err)
+	}
+	failures, err := meter.Int64Counter(`jwx.operation.failures`,
+		metric.WithDescription(`Number of operations that failed`))
+	if err != nil {
+		return nil, fmt.Errorf(`observe/otel.New: failed to create counter: %w`, err)
+	}
+	duration, err := meter.Float64Histogram(`jwx.operation.duration`,
+		metric.WithDescription(`Duration of operations`),
+		metric.WithUnit(`s`))
+	if err != nil {
+		return nil, fmt.Errorf(`observe/otel.New: failed to create histogram: %w`, err)
+	}
+
+	return &Observer{
+		tracer:     tp.Tracer(instrumentationName),
+		operations: operations,
+		failures:   failures,
+		duration:   duration,
+	}, nil
+}
+
+// Start starts a span for the operation `op`. It implements `observe.Observer`.
+func (o *Observer) Start(ctx context.Context, op observe.Operation) (context.Context, observe.Span) {
+	ctx, s := o.tracer.Start(ctx, string(op), trace.WithSpanKind(trace.SpanKindInternal))
+	return ctx, &span{
+		observer: o,
+		ctx:      ctx,
+		op:       op,
+		span:     s,
+		start:    time.Now(),
+	}
+}
+
+type span struct {
+	observer *Observer
+	ctx      context.Context
+	op       observe.Operation
+	span     trace.Span
+	start    time.Time
+	alg      string
+}
+
+func (s *span) SetAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+	// Only the algorithm is used as a metric attribute, as the
+	// cardinality of the other attributes is unbounded
+	if key == observe.AttrAlgorithm {
+		s.alg = value
+	}
+}
+
+func (s *span) End(err error) {
+	attrs := []attribute.KeyValue{operationKey.String(string(s.op))}
+	if s.alg != "" {
+		attrs = append(attrs, attribute.String(observe.AttrAlgorithm, s.alg))
+	}
+	set := metric.WithAttributes(attrs...)
+
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+		s.observer.failures.Add(s.ctx, 1, set)
+	}
+	s.observer.operations.Add(s.ctx, 1, set)
+	s.observer.duration.Record(s.ctx, time.Since(s.start).Seconds(), set)
+	s.span.End()
+}
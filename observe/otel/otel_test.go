@@ -0,0 +1,82 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/v2/internal/jwxtest"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/lestrrat-go/jwx/v2/observe"
+	jwxotel "github.com/lestrrat-go/jwx/v2/observe/otel"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObserver(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	o, err := jwxotel.New(jwxotel.WithTracerProvider(tp), jwxotel.WithMeterProvider(mp))
+	require.NoError(t, err, `jwxotel.New should succeed`)
+	observe.SetObserver(o)
+	defer observe.SetObserver(nil)
+
+	key, err := jwxtest.GenerateRsaKey()
+	require.NoError(t, err, `jwxtest.GenerateRsaKey should succeed`)
+	tok, err := jwt.NewBuilder().Issuer(`https://issuer.example.com`).Build()
+	require.NoError(t, err, `jwt.NewBuilder should succeed`)
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.RS256, key))
+	require.NoError(t, err, `jwt.Sign should succeed`)
+
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, &key.PublicKey))
+	require.NoError(t, err, `jwt.Parse should succeed`)
+	_, err = jwt.Parse(signed, jwt.WithKey(jwa.RS256, &key.PublicKey), jwt.WithIssuer(`https://other.example.com`))
+	require.Error(t, err, `jwt.Parse should fail`)
+
+	ended := spans.Ended()
+	byName := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range ended {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+	require.Len(t, byName[string(observe.JWTParse)], 2, `jwt.Parse should create spans`)
+	require.Len(t, byName[string(observe.JWSVerify)], 2, `jws.Verify should create spans`)
+	require.Len(t, byName[string(observe.JWTValidate)], 2, `jwt.Validate should create spans`)
+
+	parse := byName[string(observe.JWTParse)][0]
+	require.Contains(t, parse.Attributes(), attribute.String(observe.AttrAlgorithm, `RS256`))
+	require.Contains(t, parse.Attributes(), attribute.String(observe.AttrIssuer, `https://issuer.example.com`))
+	require.Equal(t, codes.Unset, parse.Status().Code, `successful span should not have an error status`)
+	require.Equal(t, codes.Error, byName[string(observe.JWTParse)][1].Status().Code, `failed span should have an error status`)
+
+	verify := byName[string(observe.JWSVerify)][0]
+	require.Equal(t, parse.SpanContext().SpanID(), verify.Parent().SpanID(), `jws.Verify should be a child of jwt.Parse`)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm), `reader.Collect should succeed`)
+
+	counts := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				op, _ := dp.Attributes.Value(`jwx.operation`)
+				counts[m.Name+` `+op.AsString()] += dp.Value
+			}
+		}
+	}
+	require.Equal(t, int64(2), counts[`jwx.operations jwt.Parse`])
+	require.Equal(t, int64(1), counts[`jwx.operation.failures jwt.Parse`])
+	require.Equal(t, int64(1), counts[`jwx.operation.failures jwt.Validate`])
+	require.Zero(t, counts[`jwx.operation.failures jws.Verify`])
+}
@@ -349,6 +349,16 @@ type typ struct {
 	elements []element
 }
 
+// acceptsEmpty returns true if the empty string is a valid value for the type
+func (t typ) acceptsEmpty() bool {
+	for _, e := range t.elements {
+		if e.value == `` && !e.invalid {
+			return true
+		}
+	}
+	return false
+}
+
 type element struct {
 	name    string
 	value   string
@@ -381,6 +391,9 @@ func (t typ) Generate() error {
 	o.LL("import (")
 	pkgs := []string{
 		"fmt",
+		"sort",
+		"sync",
+		"github.com/lestrrat-go/jwx/v2/internal/json",
 	}
 	for _, pkg := range pkgs {
 		o.L("%s", strconv.Quote(pkg))
@@ -400,6 +413,7 @@ func (t typ) Generate() error {
 	}
 	o.L(")") // end const
 
+	o.LL("var mu%[1]ss sync.RWMutex", t.name)
 	o.L("var all%[1]ss = map[%[1]s]struct{} {", t.name)
 	for _, e := range t.elements {
 		if !e.invalid {
@@ -407,20 +421,51 @@ func (t typ) Generate() error {
 		}
 	}
 	o.L("}")
-
-	o.LL("var list%sOnce sync.Once", t.name)
 	o.L("var list%[1]s []%[1]s", t.name)
-	o.LL("// %[1]ss returns a list of all available values for %[1]s", t.name)
-	o.L("func %[1]ss() []%[1]s {", t.name)
-	o.L("list%sOnce.Do(func() {", t.name)
-	o.L("list%[1]s = make([]%[1]s, 0, len(all%[1]ss))", t.name)
+
+	o.LL("func init() {")
+	o.L("rebuild%s()", t.name)
+	o.L("}")
+
+	o.LL("// Register%[1]s registers a new %[1]s so that values", t.name)
+	o.L("// that are not known to jwx can be handled by Accept() and friends.")
+	o.L("// Registering a value that is already known is a no-op.")
+	o.L("func Register%[1]s(v %[1]s) {", t.name)
+	o.L("mu%ss.Lock()", t.name)
+	o.L("defer mu%ss.Unlock()", t.name)
+	o.L("if _, ok := all%[1]ss[v]; !ok {", t.name)
+	o.L("all%[1]ss[v] = struct{}{}", t.name)
+	o.L("rebuild%s()", t.name)
+	o.L("}")
+	o.L("}")
+
+	o.LL("// Unregister%[1]s removes a %[1]s from the list of known values.", t.name)
+	o.L("// Unregistering a value that is not known is a no-op.")
+	o.L("func Unregister%[1]s(v %[1]s) {", t.name)
+	o.L("mu%ss.Lock()", t.name)
+	o.L("defer mu%ss.Unlock()", t.name)
+	o.L("if _, ok := all%[1]ss[v]; ok {", t.name)
+	o.L("delete(all%[1]ss, v)", t.name)
+	o.L("rebuild%s()", t.name)
+	o.L("}")
+	o.L("}")
+
+	o.LL("// rebuild%[1]s must be called with mu%[1]ss held", t.name)
+	o.L("func rebuild%s() {", t.name)
+	o.L("list := make([]%[1]s, 0, len(all%[1]ss))", t.name)
 	o.L("for v := range all%ss {", t.name)
-	o.L("list%[1]s = append(list%[1]s, v)", t.name)
+	o.L("list = append(list, v)")
 	o.L("}")
-	o.L("sort.Slice(list%s, func(i, j int) bool {", t.name)
-	o.L("return string(list%[1]s[i]) < string(list%[1]s[j])", t.name)
-	o.L("})")
+	o.L("sort.Slice(list, func(i, j int) bool {")
+	o.L("return string(list[i]) < string(list[j])")
 	o.L("})")
+	o.L("list%s = list", t.name)
+	o.L("}")
+
+	o.LL("// %[1]ss returns a list of all available values for %[1]s", t.name)
+	o.L("func %[1]ss() []%[1]s {", t.name)
+	o.L("mu%ss.RLock()", t.name)
+	o.L("defer mu%ss.RUnlock()", t.name)
 	o.L("return list%s", t.name)
 	o.L("}")
 
@@ -443,14 +488,37 @@ func (t typ) Generate() error {
 	o.L("tmp = %s(s)", t.name)
 	o.L("}")
 
-	o.L("if _, ok := all%ss[tmp]; !ok {", t.name)
-	o.L("return fmt.Errorf(`invalid jwa.%s value`)", t.name)
+	o.LL("mu%ss.RLock()", t.name)
+	o.L("_, ok := all%ss[tmp]", t.name)
+	o.L("mu%ss.RUnlock()", t.name)
+	o.L("if !ok {")
+	if !t.acceptsEmpty() {
+		o.L("if tmp == \"\" {")
+		o.L("return fmt.Errorf(`invalid jwa.%s value: empty value`)", t.name)
+		o.L("}")
+	}
+	o.L("return fmt.Errorf(`invalid jwa.%s value: unknown value %%q`, tmp)", t.name)
 	o.L("}")
 
 	o.LL("*v = tmp")
 	o.L("return nil")
 	o.L("}") // func (v *%s) Accept(v interface{})
 
+	o.LL("// UnmarshalJSON decodes a JSON string into a %s.", t.name)
+	o.L("// If strict mode is enabled (see `jwa.Settings()`), the value is validated")
+	o.L("// using Accept(). Otherwise the value is assigned as is.")
+	o.L("func (v *%s) UnmarshalJSON(data []byte) error {", t.name)
+	o.L("var s string")
+	o.L("if err := json.Unmarshal(data, &s); err != nil {")
+	o.L("return fmt.Errorf(`failed to decode jwa.%s: %%w`, err)", t.name)
+	o.L("}")
+	o.L("if IsStrict() {")
+	o.L("return v.Accept(s)")
+	o.L("}")
+	o.L("*v = %s(s)", t.name)
+	o.L("return nil")
+	o.L("}")
+
 	o.LL("// String returns the string representation of a %s", t.name)
 	o.L("func (v %s) String() string {", t.name)
 	o.L("return string(v)")
@@ -587,6 +655,16 @@ func (t typ) GenerateTest() error {
 	o.L("}")
 	o.L("})")
 
+	if !t.acceptsEmpty() {
+		o.L("t.Run(`do not accept empty string value`, func(t *testing.T) {")
+		o.L("t.Parallel()")
+		o.L("var dst jwa.%s", t.name)
+		o.L("if !assert.Error(t, dst.Accept(``), `accept should fail`) {")
+		o.L("return")
+		o.L("}")
+		o.L("})")
+	}
+
 	if t.name == "KeyEncryptionAlgorithm" {
 		o.L("t.Run(`check symmetric values`, func(t *testing.T) {")
 		o.L("t.Parallel()")
@@ -465,6 +465,9 @@ func generateObject(o *codegen.Output, kt *KeyType, obj *codegen.Object) error {
 			o.L("return fmt.Errorf(`failed to decode value for key %%s: %%w`, %sKey, err)", f.Name(true))
 			o.L("}")
 			o.L("alg := jwa.KeyAlgorithmFrom(s)")
+			o.L("if _, ok := alg.(jwa.InvalidKeyAlgorithm); ok && jwa.IsStrict() {")
+			o.L("return fmt.Errorf(`invalid value for key %%s: unknown algorithm %%q`, %sKey, s)", f.Name(true))
+			o.L("}")
 			o.L("h.%s = &alg", f.Name(false))
 		} else if f.Type() == "[]byte" {
 			name := f.Name(true)
@@ -648,6 +651,9 @@ func generateGenericHeaders(fields codegen.FieldList) error {
 	o.L("//\n// If you already know the exact type, it is recommended that you")
 	o.L("// pass a pointer to the zero value of the actual key type (e.g. &rsa.PrivateKey)")
 	o.L("// for efficiency.")
+	o.L("//\n// Private keys may also be materialized into their public counterparts")
+	o.L("// (e.g. a private EC key into *ecdsa.PublicKey). If the argument cannot")
+	o.L("// hold the key, an error describing the mismatch is returned.")
 	o.L("Raw(interface{}) error")
 	o.LL("// Thumbprint returns the JWK thumbprint using the indicated")
 	o.L("// hashing algorithm, according to RFC 7638")
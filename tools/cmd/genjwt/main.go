@@ -166,10 +166,26 @@ func generateToken(obj *codegen.Object) error {
 	o.L("// but it will not survive when the token goes through marshaling/unmarshaling")
 	o.L("// such as `json.Marshal` and `json.Unmarshal`")
 	o.L("Options() *%sTokenOptionSet", pkgPrefix)
+
+	o.LL("// Clone returns a deep copy of the token. Claim values such as maps,")
+	o.L("// slices, and pointers are copied as well, so that the new token")
+	o.L("// can be modified without affecting the original token")
 	o.L("Clone() (%sToken, error)", pkgPrefix)
 	o.L("Iterate(context.Context) Iterator")
 	o.L("Walk(context.Context, Visitor) error")
 	o.L("AsMap(context.Context) (map[string]interface{}, error)")
+
+	o.LL("// Equal returns true if the token contains the same claims as the")
+	o.L("// argument. See `jwt.Equal()` for details")
+	o.L("Equal(%sToken) bool", pkgPrefix)
+
+	o.LL("// Expired returns true if the token has expired according to the")
+	o.L("// clock. See `jwt.Expired()` for details")
+	o.L("Expired(%sClock) bool", pkgPrefix)
+
+	o.LL("// RemainingValidity returns the duration until the token expires")
+	o.L("// according to the clock. See `jwt.RemainingValidity()` for details")
+	o.L("RemainingValidity(%sClock) time.Duration", pkgPrefix)
 	o.L("}")
 
 	o.L("type %s struct {", obj.Name(false))
@@ -494,7 +510,11 @@ func generateToken(obj *codegen.Object) error {
 	o.L("defer pool.ReleaseBytesBuffer(buf)")
 	o.L("buf.WriteByte('{')")
 	o.L("enc := json.NewEncoder(buf)")
-	o.L("for i, pair := range t.makePairs() {")
+	o.L("pairs := t.makePairs()")
+	o.L("if t.options.IsEnabled(%sRegisteredClaimsFirst) {", pkgPrefix)
+	o.L("claims.SortRegisteredFirst(pairs)")
+	o.L("}")
+	o.L("for i, pair := range pairs {")
 	o.L("f := pair.Key.(string)")
 	o.L("if i > 0 {")
 	o.L("buf.WriteByte(',')")
@@ -519,7 +539,7 @@ func generateToken(obj *codegen.Object) error {
 			}
 		}
 		o.R(":")
-		o.L("enc.Encode(pair.Value.(time.Time).Unix())")
+		o.L("buf.WriteString(types.NumericDate{Time: pair.Value.(time.Time)}.String())")
 		o.L("continue")
 	}
 	o.L("}")
@@ -616,14 +636,20 @@ func genBuilder(obj *codegen.Object) error {
 	}
 
 	o.LL("// Build creates a new token based on the claims that the builder has received")
-	o.L("// so far. If a claim cannot be set, then the method returns a nil Token with")
-	o.L("// a en error as a second return value")
+	o.L("// so far. The values are validated against the types required by each claim")
+	o.L("// (e.g. `exp` must be a time value). If any of the claims cannot be set, then")
+	o.L("// the method returns a nil Token with an error describing all of the invalid")
+	o.L("// claims as a second return value")
 	o.L("func (b *Builder) Build() (Token, error) {")
 	o.L("tok := New()")
+	o.L("var errs []string")
 	o.L("for _, claim := range b.claims {")
 	o.L("if err := tok.Set(claim.Key.(string), claim.Value); err != nil {")
-	o.L("return nil, fmt.Errorf(`failed to set claim %%q: %%w`, claim.Key.(string), err)")
+	o.L("errs = append(errs, fmt.Sprintf(`failed to set claim %%q: %%s`, claim.Key.(string), err))")
+	o.L("}")
 	o.L("}")
+	o.L("if len(errs) > 0 {")
+	o.L("return nil, fmt.Errorf(`failed to build token: %%s`, strings.Join(errs, `, `))")
 	o.L("}")
 	o.L("return tok, nil")
 	o.L("}")